@@ -121,16 +121,53 @@ func (b *batch) age() time.Duration {
 	return time.Since(b.createdAt)
 }
 
-// encode the batch as snappy-compressed push request, and returns
-// the encoded bytes and the number of encoded entries
-func (b *batch) encode() ([]byte, int, error) {
-	req, entriesCount := b.createPushRequest()
+// encodePushRequest encodes the push request as snappy-compressed protobuf.
+func encodePushRequest(req *logproto.PushRequest) ([]byte, error) {
 	buf, err := proto.Marshal(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	buf = snappy.Encode(nil, buf)
-	return buf, entriesCount, nil
+	return snappy.Encode(nil, buf), nil
+}
+
+// retryRequest returns the push request made of the entries of req that Loki
+// rejected and flagged as retryable in the partial success, along with the
+// number of entries rejected for good. Only the rejected entries of a stream
+// are sent again, the whole stream being sent again only when none of its
+// entries were accepted. The rejected entries of a partially accepted stream
+// are dropped when Loki does not tell which ones they are.
+func retryRequest(req *logproto.PushRequest, partial *logproto.PushPartialSuccess) (*logproto.PushRequest, int) {
+	retry := &logproto.PushRequest{}
+	dropped := 0
+	for _, res := range partial.Streams {
+		if res.Index < 0 || int(res.Index) >= len(req.Streams) {
+			continue
+		}
+		if !res.Retryable {
+			dropped += int(res.RejectedEntries)
+			continue
+		}
+
+		stream := req.Streams[res.Index]
+		if res.AcceptedEntries == 0 {
+			retry.Streams = append(retry.Streams, stream)
+			continue
+		}
+		if len(res.RejectedOffsets) == 0 {
+			dropped += int(res.RejectedEntries)
+			continue
+		}
+
+		entries := make([]logproto.Entry, 0, len(res.RejectedOffsets))
+		for _, o := range res.RejectedOffsets {
+			if o >= 0 && int(o) < len(stream.Entries) {
+				entries = append(entries, stream.Entries[o])
+			}
+		}
+		stream.Entries = entries
+		retry.Streams = append(retry.Streams, stream)
+	}
+	return retry, dropped
 }
 
 // creates push request and returns it, together with number of entries
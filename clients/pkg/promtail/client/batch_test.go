@@ -128,7 +128,8 @@ func TestBatch_encode(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			t.Parallel()
 
-			_, entriesCount, err := testData.inputBatch.encode()
+			req, entriesCount := testData.inputBatch.createPushRequest()
+			_, err := encodePushRequest(req)
 			require.NoError(t, err)
 			assert.Equal(t, testData.expectedEntriesCount, entriesCount)
 		})
@@ -5,11 +5,13 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,6 +24,7 @@ import (
 
 	"github.com/grafana/loki/v3/clients/pkg/promtail/api"
 
+	"github.com/grafana/loki/v3/pkg/logproto"
 	lokiutil "github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/build"
 )
@@ -29,6 +32,13 @@ import (
 const (
 	contentType  = "application/x-protobuf"
 	maxErrMsgLen = 1024
+	// maxPartialSuccessLen bounds the size of the push responses listing the
+	// streams rejected by Loki.
+	maxPartialSuccessLen = 1 << 20
+
+	// acceptPartialSuccessHeader asks Loki to report the streams it rejected
+	// instead of failing the whole push request.
+	acceptPartialSuccessHeader = "X-Loki-Accept-Partial-Success"
 
 	// Label reserved to override the tenant ID while processing
 	// pipeline stages
@@ -379,7 +389,8 @@ func batchIsRateLimited(status int) bool {
 }
 
 func (c *client) sendBatch(tenantID string, batch *batch) {
-	buf, entriesCount, err := batch.encode()
+	req, entriesCount := batch.createPushRequest()
+	buf, err := encodePushRequest(req)
 	if err != nil {
 		level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
 		return
@@ -388,14 +399,47 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 	c.metrics.encodedBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
 
 	backoff := backoff.New(c.ctx, c.cfg.BackoffConfig)
-	var status int
+	var (
+		status  int
+		partial *logproto.PushPartialSuccess
+	)
 	for {
 		start := time.Now()
 		// send uses `timeout` internally, so `context.Background` is good enough.
-		status, err = c.send(context.Background(), tenantID, buf)
+		status, partial, err = c.send(context.Background(), tenantID, buf)
 
 		c.metrics.requestDuration.WithLabelValues(strconv.Itoa(status), c.cfg.URL.Host).Observe(time.Since(start).Seconds())
 
+		// Loki accepted only part of the batch: account for the accepted entries
+		// and the ones rejected for good, then retry the retryable streams only.
+		if err == nil && partial != nil {
+			retry, dropped := retryRequest(req, partial)
+			c.metrics.sentEntries.WithLabelValues(c.cfg.URL.Host).Add(float64(entriesCount - int(partial.RejectedEntries)))
+			if dropped > 0 {
+				level.Warn(c.logger).Log("msg", "server rejected part of the batch", "tenant", tenantID, "dropped_entries", dropped)
+				c.metrics.droppedEntries.WithLabelValues(c.cfg.URL.Host, tenantID, ReasonGeneric).Add(float64(dropped))
+			}
+			if len(retry.Streams) == 0 {
+				c.metrics.sentBytes.WithLabelValues(c.cfg.URL.Host).Add(bufBytes)
+				return
+			}
+
+			req = retry
+			buf, err = encodePushRequest(req)
+			if err != nil {
+				level.Error(c.logger).Log("msg", "error encoding batch", "error", err)
+				return
+			}
+			bufBytes = float64(len(buf))
+			entriesCount = 0
+			for _, s := range req.Streams {
+				entriesCount += len(s.Entries)
+			}
+			// Retryable rejections are rate limits, retry them as such.
+			status = http.StatusTooManyRequests
+			err = fmt.Errorf("server rejected entries of %d streams", len(req.Streams))
+		}
+
 		// Immediately drop rate limited batches to avoid HOL blocking for other tenants not experiencing throttling
 		if c.cfg.DropRateLimitedBatches && batchIsRateLimited(status) {
 			level.Warn(c.logger).Log("msg", "dropping batch due to rate limiting applied at ingester")
@@ -439,15 +483,18 @@ func (c *client) sendBatch(tenantID string, batch *batch) {
 	}
 }
 
-func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, error) {
+// send pushes the encoded batch. If Loki accepted only part of it, the
+// returned partial success lists the rejected streams.
+func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, *logproto.PushPartialSuccess, error) {
 	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.URL.String(), bytes.NewReader(buf))
 	if err != nil {
-		return -1, err
+		return -1, nil, err
 	}
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(acceptPartialSuccessHeader, "true")
 
 	// If the tenant ID is not empty promtail is running in multi-tenant mode, so
 	// we should send it to Loki
@@ -468,10 +515,19 @@ func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, er
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return -1, err
+		return -1, nil, err
 	}
 	defer lokiutil.LogError("closing response body", resp.Body.Close)
 
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var pushResp logproto.PushResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxPartialSuccessLen)).Decode(&pushResp); err != nil {
+			level.Warn(c.logger).Log("msg", "failed to decode push response", "error", err)
+			return resp.StatusCode, nil, nil
+		}
+		return resp.StatusCode, pushResp.PartialSuccess, nil
+	}
+
 	if resp.StatusCode/100 != 2 {
 		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
 		line := ""
@@ -480,7 +536,7 @@ func (c *client) send(ctx context.Context, tenantID string, buf []byte) (int, er
 		}
 		err = fmt.Errorf("server returned HTTP status %s (%d): %s", resp.Status, resp.StatusCode, line)
 	}
-	return resp.StatusCode, err
+	return resp.StatusCode, nil, err
 }
 
 func (c *client) getTenantID(labels model.LabelSet) string {
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/golang/snappy"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/client_golang/prometheus"
@@ -746,3 +749,155 @@ func Test_Tripperware(t *testing.T) {
 	c.Stop()
 	require.True(t, called)
 }
+
+func TestClient_PartialSuccess(t *testing.T) {
+	url, err := url.Parse("http://foo.com")
+	require.NoError(t, err)
+
+	var received []logproto.PushRequest
+	reg := prometheus.NewRegistry()
+	c, err := NewWithTripperware(NewMetrics(reg), Config{
+		URL:           flagext.URLValue{URL: url},
+		BatchWait:     time.Hour,
+		BatchSize:     1 << 20,
+		BackoffConfig: backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetries: 3},
+		Timeout:       time.Second,
+	}, 0, 0, false, log.NewNopLogger(), func(_ http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			require.Equal(t, "true", r.Header.Get(acceptPartialSuccessHeader))
+
+			compressed, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			buf, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			var req logproto.PushRequest
+			require.NoError(t, req.Unmarshal(buf))
+			received = append(received, req)
+
+			if len(received) > 1 {
+				return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+
+			// The first push is accepted for the stream {app="ok"} only.
+			partial := &logproto.PushPartialSuccess{}
+			for i, s := range req.Streams {
+				switch s.Labels {
+				case `{app="limited"}`:
+					partial.Streams = append(partial.Streams, logproto.StreamPushResult{Index: int32(i), Labels: s.Labels, RejectedEntries: 2, Reason: "rate_limited", Retryable: true})
+				case `{app="invalid"}`:
+					partial.Streams = append(partial.Streams, logproto.StreamPushResult{Index: int32(i), Labels: s.Labels, RejectedEntries: 1, Reason: "line_too_long"})
+				default:
+					continue
+				}
+				partial.RejectedEntries += partial.Streams[len(partial.Streams)-1].RejectedEntries
+			}
+			body, err := json.Marshal(logproto.PushResponse{PartialSuccess: partial})
+			require.NoError(t, err)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil
+		})
+	})
+	require.NoError(t, err)
+
+	for i, app := range []string{"ok", "limited", "limited", "invalid"} {
+		c.Chan() <- api.Entry{
+			Labels: model.LabelSet{"app": model.LabelValue(app)},
+			Entry:  logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: "line"},
+		}
+	}
+	c.Stop()
+
+	require.Len(t, received, 2)
+	require.Len(t, received[0].Streams, 3)
+	require.Len(t, received[1].Streams, 1)
+	require.Equal(t, `{app="limited"}`, received[1].Streams[0].Labels)
+	require.Len(t, received[1].Streams[0].Entries, 2)
+
+	expected := strings.Replace(`
+		# HELP promtail_sent_entries_total Number of log entries sent to the ingester.
+		# TYPE promtail_sent_entries_total counter
+		promtail_sent_entries_total{host="foo.com"} 3
+		# HELP promtail_dropped_entries_total Number of log entries dropped because failed to be sent to the ingester after all retries.
+		# TYPE promtail_dropped_entries_total counter
+		promtail_dropped_entries_total{host="foo.com",reason="ingester_error",tenant=""} 1
+		promtail_dropped_entries_total{host="foo.com",reason="line_too_long",tenant=""} 0
+		promtail_dropped_entries_total{host="foo.com",reason="rate_limited",tenant=""} 0
+		promtail_dropped_entries_total{host="foo.com",reason="stream_limited",tenant=""} 0
+	`, "\t\t", "", -1)
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "promtail_sent_entries_total", "promtail_dropped_entries_total"))
+}
+
+func TestClient_PartialSuccess_PartiallyAcceptedStream(t *testing.T) {
+	url, err := url.Parse("http://foo.com")
+	require.NoError(t, err)
+
+	var received []logproto.PushRequest
+	reg := prometheus.NewRegistry()
+	c, err := NewWithTripperware(NewMetrics(reg), Config{
+		URL:           flagext.URLValue{URL: url},
+		BatchWait:     time.Hour,
+		BatchSize:     1 << 20,
+		BackoffConfig: backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetries: 3},
+		Timeout:       time.Second,
+	}, 0, 0, false, log.NewNopLogger(), func(_ http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			compressed, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			buf, err := snappy.Decode(nil, compressed)
+			require.NoError(t, err)
+			var req logproto.PushRequest
+			require.NoError(t, req.Unmarshal(buf))
+			received = append(received, req)
+
+			if len(received) > 1 {
+				return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+
+			// The first push is rate limited for the second and fourth entries only.
+			partial := &logproto.PushPartialSuccess{
+				RejectedEntries: 2,
+				Streams: []logproto.StreamPushResult{{
+					Labels:          req.Streams[0].Labels,
+					AcceptedEntries: 2,
+					RejectedEntries: 2,
+					RejectedOffsets: []int32{1, 3},
+					Reason:          "rate_limited",
+					Retryable:       true,
+				}},
+			}
+			body, err := json.Marshal(logproto.PushResponse{PartialSuccess: partial})
+			require.NoError(t, err)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			}, nil
+		})
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		c.Chan() <- api.Entry{
+			Labels: model.LabelSet{"app": "limited"},
+			Entry:  logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: "line"},
+		}
+	}
+	c.Stop()
+
+	// Only the rejected entries are sent again.
+	require.Len(t, received, 2)
+	require.Len(t, received[1].Streams, 1)
+	require.Len(t, received[1].Streams[0].Entries, 2)
+	require.Equal(t, int64(1), received[1].Streams[0].Entries[0].Timestamp.Unix())
+	require.Equal(t, int64(3), received[1].Streams[0].Entries[1].Timestamp.Unix())
+
+	expected := strings.Replace(`
+		# HELP promtail_sent_entries_total Number of log entries sent to the ingester.
+		# TYPE promtail_sent_entries_total counter
+		promtail_sent_entries_total{host="foo.com"} 4
+	`, "\t\t", "", -1)
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "promtail_sent_entries_total"))
+}
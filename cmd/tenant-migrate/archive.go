@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const (
+	archiveVersion = 1
+
+	manifestFileName = "manifest.json"
+	indexDir         = "index"
+	chunksDir        = "chunks"
+
+	sliceExtension   = ".tsdb"
+	summaryExtension = ".json"
+)
+
+// manifest describes an archive. It is written once the export is complete.
+//
+// An archive is a directory holding, for each exported index table, a single tenant TSDB index with the series of
+// the tenant and their chunks overlapping the exported time range, named after the table number, and the chunks
+// referenced by these indexes. Chunks are stored as encoded in the object store, so their structured metadata is
+// kept as is.
+type manifest struct {
+	Version   int        `json:"version"`
+	Tenant    string     `json:"tenant"`
+	From      model.Time `json:"from"`
+	Through   model.Time `json:"through"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Tables are the numbers of the exported index tables, which all cover 24h.
+	Tables []tableSummary `json:"tables"`
+	// Checksums are the SHA256 checksums of the files of the archive, by path relative to the archive directory.
+	Checksums map[string]string `json:"checksums"`
+}
+
+// tableSummary is written next to the index of a table once all its chunks are exported.
+type tableSummary struct {
+	Table     int64             `json:"table"`
+	Series    int               `json:"series"`
+	Chunks    int               `json:"chunks"`
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
+func slicePath(table int64) string {
+	return filepath.Join(indexDir, strconv.FormatInt(table, 10)+sliceExtension)
+}
+
+func summaryPath(table int64) string {
+	return filepath.Join(indexDir, strconv.FormatInt(table, 10)+summaryExtension)
+}
+
+// chunkPath returns the path of a chunk in the archive. The archive only holds the chunks of a tenant, and the chunks of
+// a stream with different storage classes also have different checksums.
+func chunkPath(ref logproto.ChunkRef) string {
+	return filepath.Join(chunksDir, strconv.FormatUint(ref.Fingerprint, 16), fmt.Sprintf("%x-%x-%x", int64(ref.From), int64(ref.Through), ref.Checksum))
+}
+
+// writeFile writes the content of r to the path relative to the archive directory and returns its checksum.
+// The file is only visible at its path once fully written.
+func writeFile(dir, path string, r io.Reader) (string, error) {
+	dst := filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(f.Name(), dst); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeJSONFile(dir, path string, v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeFile(dir, path, strings.NewReader(string(data)))
+}
+
+func readJSONFile(dir, path string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// readVerifiedFile reads a file of the archive and verifies its checksum against the manifest.
+func readVerifiedFile(dir, path string, m *manifest) ([]byte, error) {
+	expected, ok := m.Checksums[filepath.ToSlash(path)]
+	if !ok {
+		return nil, fmt.Errorf("file %s is not part of the archive", path)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != expected {
+		return nil, fmt.Errorf("checksum mismatch for file %s: expected %s, got %s", path, expected, got)
+	}
+	return data, nil
+}
+
+// writeManifest writes the manifest of an archive from the summaries of its exported tables.
+func writeManifest(dir, tenant string, from, through model.Time, tables []int64) (*manifest, error) {
+	m := &manifest{
+		Version:   archiveVersion,
+		Tenant:    tenant,
+		From:      from,
+		Through:   through,
+		CreatedAt: time.Now().UTC(),
+		Checksums: map[string]string{},
+	}
+
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	for _, table := range tables {
+		var summary tableSummary
+		if err := readJSONFile(dir, summaryPath(table), &summary); err != nil {
+			return nil, fmt.Errorf("failed to read summary of table %d: %w", table, err)
+		}
+		for path, checksum := range summary.Checksums {
+			m.Checksums[path] = checksum
+		}
+		summary.Checksums = nil
+		m.Tables = append(m.Tables, summary)
+	}
+
+	if _, err := writeJSONFile(dir, manifestFileName, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func readManifest(dir string) (*manifest, error) {
+	var m manifest
+	if err := readJSONFile(dir, manifestFileName, &m); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if m.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", m.Version)
+	}
+	return &m, nil
+}
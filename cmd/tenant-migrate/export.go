@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	shipperstorage "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	tsdbindex "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+type exporter struct {
+	schemaCfg     config.SchemaConfig
+	storageCfg    storage.Config
+	clientMetrics storage.ClientMetrics
+
+	tenant        string
+	from, through model.Time
+	archiveDir    string
+	workingDir    string
+	parallel      int
+}
+
+func newExporter(schemaCfg config.SchemaConfig, storageCfg storage.Config, clientMetrics storage.ClientMetrics, tenant string, from, through model.Time, archiveDir, workingDir string, parallel int) *exporter {
+	return &exporter{
+		schemaCfg:     schemaCfg,
+		storageCfg:    storageCfg,
+		clientMetrics: clientMetrics,
+		tenant:        tenant,
+		from:          from,
+		through:       through,
+		archiveDir:    archiveDir,
+		workingDir:    workingDir,
+		parallel:      parallel,
+	}
+}
+
+// series is a series of an index table with the chunks overlapping the exported time range.
+type series struct {
+	labels labels.Labels
+	fp     model.Fingerprint
+	chunks map[tsdbindex.ChunkMeta]struct{}
+}
+
+// run exports the index tables overlapping the time range one at a time and writes the manifest of the archive.
+// The tables exported by a previous run, which have a summary, are not exported again.
+func (e *exporter) run(ctx context.Context) (*manifest, error) {
+	clients, err := newPeriodClients(e.schemaCfg, e.storageCfg, e.clientMetrics)
+	if err != nil {
+		return nil, err
+	}
+	defer stopPeriodClients(clients)
+
+	return e.export(ctx, clients)
+}
+
+func (e *exporter) export(ctx context.Context, clients map[config.DayTime]*periodClients) (*manifest, error) {
+	if err := os.MkdirAll(e.archiveDir, 0o750); err != nil {
+		return nil, err
+	}
+
+	period := int64(config.ObjectStorageIndexRequiredPeriod / time.Millisecond)
+	var tables []int64
+	for table := int64(e.from) / period; table <= int64(e.through)/period; table++ {
+		tableFrom, _ := tableRange(table)
+		periodCfg, err := e.schemaCfg.SchemaForTime(tableFrom)
+		if err != nil {
+			return nil, err
+		}
+		if periodCfg.IndexType != types.TSDBType {
+			level.Warn(util_log.Logger).Log("msg", "skipping table of schema period which does not use tsdb", "table", table, "schema_start", periodCfg.From, "index_type", periodCfg.IndexType)
+			continue
+		}
+		tables = append(tables, table)
+
+		if _, err := os.Stat(filepath.Join(e.archiveDir, summaryPath(table))); err == nil {
+			level.Info(util_log.Logger).Log("msg", "skipping table exported by a previous run", "table", table)
+			continue
+		}
+		if err := e.exportTable(ctx, clients, periodCfg, table); err != nil {
+			return nil, fmt.Errorf("failed to export table %d: %w", table, err)
+		}
+	}
+
+	return writeManifest(e.archiveDir, e.tenant, e.from, e.through, tables)
+}
+
+// exportTable writes the index of the tenant in a table and its chunks to the archive, then the summary of the table,
+// which marks it as exported.
+func (e *exporter) exportTable(ctx context.Context, clients map[config.DayTime]*periodClients, periodCfg config.PeriodConfig, table int64) error {
+	tableFrom, _ := tableRange(table)
+	tableName := periodCfg.IndexTables.TableFor(tableFrom)
+	indexClient := clients[periodCfg.From].indexStorageClient
+
+	dir, err := os.MkdirTemp(e.workingDir, tableName)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// the index of the tenant is in the multi-tenant files of the table until the compactor compacts them into per tenant files.
+	allSeries := map[string]*series{}
+	commonFiles, tenants, err := indexClient.ListFiles(ctx, tableName, true)
+	if err != nil {
+		return err
+	}
+	for _, file := range commonFiles {
+		path, err := download(dir, file.Name, func() (io.ReadCloser, error) {
+			return indexClient.GetFile(ctx, tableName, file.Name)
+		})
+		if err != nil {
+			return err
+		}
+		if err := e.readIndex(ctx, path, allSeries, labels.MustNewMatcher(labels.MatchEqual, tsdb.TenantLabel, e.tenant)); err != nil {
+			return fmt.Errorf("failed to read index %s: %w", file.Name, err)
+		}
+	}
+	for _, tenant := range tenants {
+		if tenant != e.tenant {
+			continue
+		}
+
+		files, err := indexClient.ListUserFiles(ctx, tableName, tenant, true)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			path, err := download(filepath.Join(dir, tenant), file.Name, func() (io.ReadCloser, error) {
+				return indexClient.GetUserFile(ctx, tableName, tenant, file.Name)
+			})
+			if err != nil {
+				return err
+			}
+			if err := e.readIndex(ctx, path, allSeries, labels.MustNewMatcher(labels.MatchEqual, "", "")); err != nil {
+				return fmt.Errorf("failed to read index %s: %w", file.Name, err)
+			}
+		}
+	}
+
+	summary := tableSummary{Table: table, Checksums: map[string]string{}}
+	if len(allSeries) > 0 {
+		if err := e.exportChunks(ctx, clients, allSeries, &summary); err != nil {
+			return err
+		}
+		if err := e.writeSlice(ctx, dir, allSeries, &summary); err != nil {
+			return err
+		}
+	}
+
+	if _, err := writeJSONFile(e.archiveDir, summaryPath(table), summary); err != nil {
+		return err
+	}
+	level.Info(util_log.Logger).Log("msg", "exported table", "table", table, "series", summary.Series, "chunks", summary.Chunks)
+	return nil
+}
+
+// readIndex adds the series of an index file matching the matcher to allSeries, with their chunks overlapping the
+// exported time range.
+func (e *exporter) readIndex(ctx context.Context, path string, allSeries map[string]*series, matcher *labels.Matcher) error {
+	idx, err := tsdb.OpenShippableTSDB(path)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	return idx.(*tsdb.TSDBFile).Index.(*tsdb.TSDBIndex).ForSeries(ctx, "", nil, e.from, e.through,
+		func(ls labels.Labels, fp model.Fingerprint, chks []tsdbindex.ChunkMeta) (stop bool) {
+			// the structured metadata series are left out, the imported chunks are just never pruned by them.
+			if len(chks) == 0 || ls.Has(tsdb.StructuredMetadataLabel) {
+				return false
+			}
+
+			ls = labels.NewBuilder(ls.Copy()).Del(tsdb.TenantLabel).Labels()
+			key := ls.String()
+			s, ok := allSeries[key]
+			if !ok {
+				s = &series{labels: ls, fp: fp, chunks: map[tsdbindex.ChunkMeta]struct{}{}}
+				allSeries[key] = s
+			}
+			for _, chk := range chks {
+				s.chunks[chk] = struct{}{}
+			}
+			return false
+		},
+		matcher,
+	)
+}
+
+// exportChunks copies the chunks of the series from the object store to the archive. The chunks already in the archive,
+// exported by a previous run or with another table, are not copied again.
+func (e *exporter) exportChunks(ctx context.Context, clients map[config.DayTime]*periodClients, allSeries map[string]*series, summary *tableSummary) error {
+	var refs []logproto.ChunkRef
+	for _, s := range allSeries {
+		storageClass := s.labels.Get(tsdb.StorageClassLabel)
+		for chk := range s.chunks {
+			refs = append(refs, logproto.ChunkRef{
+				Fingerprint:  uint64(s.fp),
+				UserID:       e.tenant,
+				From:         chk.From(),
+				Through:      chk.Through(),
+				Checksum:     chk.Checksum,
+				StorageClass: storageClass,
+			})
+		}
+	}
+
+	var mtx sync.Mutex
+	return concurrency.ForEachJob(ctx, len(refs), e.parallel, func(ctx context.Context, i int) error {
+		ref := refs[i]
+		path := chunkPath(ref)
+		checksum, err := fileChecksum(filepath.Join(e.archiveDir, path))
+		if os.IsNotExist(err) {
+			checksum, err = e.exportChunk(ctx, clients, ref)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export chunk %s: %w", e.schemaCfg.ExternalKey(ref), err)
+		}
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		summary.Checksums[filepath.ToSlash(path)] = checksum
+		summary.Chunks++
+		return nil
+	})
+}
+
+// exportChunk copies a chunk as encoded in the object store, which is the object store of the schema period of its start.
+func (e *exporter) exportChunk(ctx context.Context, clients map[config.DayTime]*periodClients, ref logproto.ChunkRef) (string, error) {
+	periodCfg, err := e.schemaCfg.SchemaForTime(ref.From)
+	if err != nil {
+		return "", err
+	}
+	c := clients[periodCfg.From]
+
+	key := e.schemaCfg.ExternalKey(ref)
+	if c.keyEncoder != nil {
+		key = c.keyEncoder(e.schemaCfg, chunk.Chunk{ChunkRef: ref})
+	}
+	r, _, err := c.objectClient.GetObject(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	return writeFile(e.archiveDir, chunkPath(ref), r)
+}
+
+// writeSlice builds the index of the tenant in the table, without the tenant label of the multi-tenant files.
+func (e *exporter) writeSlice(ctx context.Context, dir string, allSeries map[string]*series, summary *tableSummary) error {
+	b := tsdb.NewBuilder(tsdbindex.FormatV3)
+	for _, s := range allSeries {
+		chks := make(tsdbindex.ChunkMetas, 0, len(s.chunks))
+		for chk := range s.chunks {
+			chks = append(chks, chk)
+		}
+		b.AddSeries(s.labels, s.fp, chks)
+	}
+	summary.Series = len(allSeries)
+
+	buildDir := filepath.Join(dir, "slice")
+	if err := os.MkdirAll(buildDir, 0o750); err != nil {
+		return err
+	}
+	id, err := b.Build(ctx, buildDir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tsdb.NewPrefixedIdentifier(tsdb.SingleTenantTSDBIdentifier{
+			TS:       time.Now(),
+			From:     from,
+			Through:  through,
+			Checksum: checksum,
+		}, buildDir, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(id.Path())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	path := slicePath(summary.Table)
+	checksum, err := writeFile(e.archiveDir, path, f)
+	if err != nil {
+		return err
+	}
+	summary.Checksums[filepath.ToSlash(path)] = checksum
+	return nil
+}
+
+func download(dir, fileName string, getReader func() (io.ReadCloser, error)) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	decompress := shipperstorage.IsCompressedFile(fileName)
+	dst := filepath.Join(dir, strings.TrimSuffix(fileName, ".gz"))
+	err := shipperstorage.DownloadFileFromStorage(dst, decompress, false, shipperstorage.LoggerWithFilename(util_log.Logger, fileName), getReader)
+	return dst, err
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	tsdbindex "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	stateFileName = "import-state.json"
+
+	// nameLabel and logsValue are the metric name of the chunks flushed by the ingesters.
+	nameLabel = "__name__"
+	logsValue = "logs"
+)
+
+func defaultStateFile(archiveDir string) string {
+	return filepath.Join(archiveDir, stateFileName)
+}
+
+// importState records the tables of the archive already imported, which are skipped when the import is resumed.
+type importState struct {
+	Tenant string  `json:"tenant"`
+	Tables []int64 `json:"tables"`
+}
+
+type importStats struct {
+	Tables        int
+	Series        int
+	Chunks        int
+	DroppedSeries int
+}
+
+type importer struct {
+	schemaCfg     config.SchemaConfig
+	storageCfg    storage.Config
+	clientMetrics storage.ClientMetrics
+
+	tenant         string
+	relabelConfigs []*relabel.Config
+	archiveDir     string
+	stateFile      string
+	workingDir     string
+	parallel       int
+}
+
+func newImporter(schemaCfg config.SchemaConfig, storageCfg storage.Config, clientMetrics storage.ClientMetrics, tenant string, relabelConfigs []*relabel.Config, archiveDir, stateFile, workingDir string, parallel int) *importer {
+	return &importer{
+		schemaCfg:      schemaCfg,
+		storageCfg:     storageCfg,
+		clientMetrics:  clientMetrics,
+		tenant:         tenant,
+		relabelConfigs: relabelConfigs,
+		archiveDir:     archiveDir,
+		stateFile:      stateFile,
+		workingDir:     workingDir,
+		parallel:       parallel,
+	}
+}
+
+func loadRelabelConfigs(path string) ([]*relabel.Config, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var relabelConfigs []*relabel.Config
+	if err := yaml.UnmarshalStrict(data, &relabelConfigs); err != nil {
+		return nil, err
+	}
+	for _, c := range relabelConfigs {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return relabelConfigs, nil
+}
+
+// importedSeries is a series of an archived index table rewritten for the destination.
+type importedSeries struct {
+	labels       labels.Labels
+	storageClass string
+	fp           model.Fingerprint
+	// sourceFp is the fingerprint of the chunks of the series in the archive.
+	sourceFp model.Fingerprint
+	chunks   tsdbindex.ChunkMetas
+}
+
+// run imports the tables of the archive not imported yet one at a time, and records each imported table in the state file.
+func (i *importer) run(ctx context.Context) (importStats, error) {
+	clients, err := newPeriodClients(i.schemaCfg, i.storageCfg, i.clientMetrics)
+	if err != nil {
+		return importStats{}, err
+	}
+	defer stopPeriodClients(clients)
+
+	return i.importArchive(ctx, clients)
+}
+
+func (i *importer) importArchive(ctx context.Context, clients map[config.DayTime]*periodClients) (importStats, error) {
+	var stats importStats
+	m, err := readManifest(i.archiveDir)
+	if err != nil {
+		return stats, err
+	}
+	if i.tenant == "" {
+		i.tenant = m.Tenant
+	}
+
+	state := importState{Tenant: i.tenant}
+	if err := readJSONFile(filepath.Dir(i.stateFile), filepath.Base(i.stateFile), &state); err != nil && !os.IsNotExist(err) {
+		return stats, fmt.Errorf("failed to read import state: %w", err)
+	}
+	if state.Tenant != i.tenant {
+		return stats, fmt.Errorf("the import state %s is for tenant %s", i.stateFile, state.Tenant)
+	}
+	imported := map[int64]struct{}{}
+	for _, table := range state.Tables {
+		imported[table] = struct{}{}
+	}
+
+	for _, t := range m.Tables {
+		if _, ok := imported[t.Table]; ok {
+			level.Info(util_log.Logger).Log("msg", "skipping table imported by a previous run", "table", t.Table)
+			continue
+		}
+
+		if t.Series > 0 {
+			if err := i.importTable(ctx, clients, m, t.Table, &stats); err != nil {
+				return stats, fmt.Errorf("failed to import table %d: %w", t.Table, err)
+			}
+		}
+
+		state.Tables = append(state.Tables, t.Table)
+		if _, err := writeJSONFile(filepath.Dir(i.stateFile), filepath.Base(i.stateFile), state); err != nil {
+			return stats, fmt.Errorf("failed to write import state: %w", err)
+		}
+		stats.Tables++
+	}
+	return stats, nil
+}
+
+// importTable writes the chunks of an archived index table to the object store, re-encoded for the destination tenant and
+// labels, then uploads the index of the tenant for the table. The chunk keys and the index file name only depend on the
+// archive, so a table imported again after a failure overwrites the objects written by the failed attempt.
+func (i *importer) importTable(ctx context.Context, clients map[config.DayTime]*periodClients, m *manifest, table int64, stats *importStats) error {
+	tableFrom, _ := tableRange(table)
+	periodCfg, err := i.schemaCfg.SchemaForTime(tableFrom)
+	if err != nil {
+		return err
+	}
+	if periodCfg.IndexType != types.TSDBType {
+		return fmt.Errorf("the schema period %s of the table does not use tsdb", periodCfg.From)
+	}
+	format, err := periodCfg.TSDBFormat()
+	if err != nil {
+		return err
+	}
+
+	allSeries, dropped, err := i.readSlice(ctx, m, table)
+	if err != nil {
+		return err
+	}
+
+	type job struct {
+		series *importedSeries
+		idx    int
+	}
+	var jobs []job
+	for _, s := range allSeries {
+		for idx := range s.chunks {
+			jobs = append(jobs, job{series: s, idx: idx})
+		}
+	}
+	err = concurrency.ForEachJob(ctx, len(jobs), i.parallel, func(ctx context.Context, j int) error {
+		return i.importChunk(ctx, clients, m, jobs[j].series, jobs[j].idx)
+	})
+	if err != nil {
+		return err
+	}
+
+	b := tsdb.NewBuilder(format)
+	for _, s := range allSeries {
+		ls := s.labels
+		if s.storageClass != "" {
+			ls = labels.NewBuilder(ls).Set(tsdb.StorageClassLabel, s.storageClass).Labels()
+		}
+		b.AddSeries(ls, s.fp, s.chunks)
+	}
+
+	dir, err := os.MkdirTemp(i.workingDir, strconv.FormatInt(table, 10))
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	id, err := b.Build(ctx, dir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tsdb.NewPrefixedIdentifier(tsdb.SingleTenantTSDBIdentifier{
+			TS:       m.CreatedAt,
+			From:     from,
+			Through:  through,
+			Checksum: checksum,
+		}, dir, "")
+	})
+	if err != nil {
+		return err
+	}
+	tableName := periodCfg.IndexTables.TableFor(tableFrom)
+	if err := uploadIndex(ctx, clients[periodCfg.From], tableName, i.tenant, id); err != nil {
+		return fmt.Errorf("failed to upload index: %w", err)
+	}
+
+	stats.Series += len(allSeries)
+	stats.Chunks += len(jobs)
+	stats.DroppedSeries += dropped
+	level.Info(util_log.Logger).Log("msg", "imported table", "table", table, "table_name", tableName, "series", len(allSeries), "chunks", len(jobs), "dropped_series", dropped)
+	return nil
+}
+
+// readSlice reads the series of an archived index table and rewrites their labels with the relabel configs. It returns
+// the series to import and the number of series dropped by the relabel configs.
+func (i *importer) readSlice(ctx context.Context, m *manifest, table int64) (map[string]*importedSeries, int, error) {
+	data, err := readVerifiedFile(i.archiveDir, slicePath(table), m)
+	if err != nil {
+		return nil, 0, err
+	}
+	reader, err := tsdbindex.NewReader(tsdbindex.RealByteSlice(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	idx := tsdb.NewTSDBIndex(reader)
+	defer idx.Close()
+
+	allSeries := map[string]*importedSeries{}
+	dropped := 0
+	err = idx.ForSeries(ctx, "", nil, model.Earliest, model.Latest,
+		func(ls labels.Labels, fp model.Fingerprint, chks []tsdbindex.ChunkMeta) (stop bool) {
+			storageClass := ls.Get(tsdb.StorageClassLabel)
+			stream := labels.NewBuilder(ls.Copy()).Del(tsdb.StorageClassLabel).Labels()
+
+			// the chunks of a stream keep their fingerprint unless the labels are rewritten. The fingerprint of the rewritten
+			// streams only depends on their labels, since several streams may be rewritten to the same labels.
+			rewritten, newFp := stream, fp
+			if len(i.relabelConfigs) > 0 {
+				var keep bool
+				rewritten, keep = relabel.Process(stream, i.relabelConfigs...)
+				if !keep || rewritten.IsEmpty() {
+					dropped++
+					return false
+				}
+				newFp = model.Fingerprint(rewritten.Hash())
+			}
+
+			// the chunks are read by the fingerprint of their source stream, so the streams rewritten to the same labels
+			// are only merged into a single series by the index builder.
+			key := fmt.Sprintf("%s/%s/%d", rewritten, storageClass, fp)
+			s, ok := allSeries[key]
+			if !ok {
+				s = &importedSeries{labels: rewritten, storageClass: storageClass, fp: newFp, sourceFp: fp}
+				allSeries[key] = s
+			}
+			s.chunks = append(s.chunks, chks...)
+			return false
+		},
+		labels.MustNewMatcher(labels.MatchEqual, "", ""),
+	)
+	return allSeries, dropped, err
+}
+
+// importChunk decodes a chunk of the archive and writes it as a chunk of the destination tenant and series to the object
+// store of the schema period of its start, then replaces its checksum in the series.
+func (i *importer) importChunk(ctx context.Context, clients map[config.DayTime]*periodClients, m *manifest, s *importedSeries, idx int) error {
+	meta := s.chunks[idx]
+	ref := logproto.ChunkRef{
+		Fingerprint:  uint64(s.sourceFp),
+		UserID:       m.Tenant,
+		From:         meta.From(),
+		Through:      meta.Through(),
+		Checksum:     meta.Checksum,
+		StorageClass: s.storageClass,
+	}
+
+	data, err := readVerifiedFile(i.archiveDir, chunkPath(ref), m)
+	if err != nil {
+		return err
+	}
+	src := chunk.Chunk{ChunkRef: ref}
+	if err := src.Decode(chunk.NewDecodeContext(), data); err != nil {
+		return fmt.Errorf("failed to decode chunk %s: %w", chunkPath(ref), err)
+	}
+
+	metric := labels.NewBuilder(s.labels).Set(nameLabel, logsValue).Labels()
+	dst := chunk.NewChunk(i.tenant, s.fp, metric, src.Data, src.From, src.Through)
+	dst.StorageClass = s.storageClass
+	if err := dst.Encode(); err != nil {
+		return err
+	}
+
+	periodCfg, err := i.schemaCfg.SchemaForTime(dst.From)
+	if err != nil {
+		return err
+	}
+	if err := clients[periodCfg.From].chunkClient.PutChunks(ctx, []chunk.Chunk{dst}); err != nil {
+		return err
+	}
+
+	s.chunks[idx].Checksum = dst.Checksum
+	return nil
+}
+
+// uploadIndex uploads a built index as a compressed index file of the tenant in the table.
+func uploadIndex(ctx context.Context, c *periodClients, tableName, tenant string, id tsdb.Identifier) error {
+	src, err := os.Open(id.Path())
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := os.CreateTemp(filepath.Dir(id.Path()), id.Name()+".gz")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	compressedWriter := chunkenc.Gzip.GetWriter(f)
+	defer chunkenc.Gzip.PutWriter(compressedWriter)
+	if _, err := io.Copy(compressedWriter, src); err != nil {
+		return err
+	}
+	if err := compressedWriter.Close(); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+
+	return c.indexStorageClient.PutUserFile(ctx, tableName, tenant, id.Name()+".gz", f)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/loki"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	shipperstorage "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/util/cfg"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	modeExport = "export"
+	modeImport = "import"
+)
+
+// tenant-migrate exports the data of a tenant for a time range from the TSDB indexes and the chunks of a Loki cluster to
+// a portable archive, and imports an archive into another cluster, optionally renaming the tenant and rewriting the
+// labels of its streams. Both modes resume where they stopped when run again after a failure.
+func main() {
+	mode := flag.String("mode", "", "Either export, to export the data of a tenant to an archive, or import, to import an archive")
+	configFile := flag.String("config.file", "", "Config of the cluster the data is exported from or imported into")
+	archiveDir := flag.String("archive", "", "Directory of the archive")
+	tenant := flag.String("tenant", "fake", "Tenant to export, default is `fake` for single tenant Loki")
+	from := flag.String("from", "", "Start Time RFC339Nano 2006-01-02T15:04:05.999999999Z07:00 of the exported data")
+	to := flag.String("to", "", "End Time RFC339Nano 2006-01-02T15:04:05.999999999Z07:00 of the exported data")
+	destTenant := flag.String("dest.tenant", "", "Tenant the archive is imported as, default is the exported tenant")
+	relabelConfigFile := flag.String("relabel-config-file", "", "Optional file of Prometheus relabel configs applied to the labels of the imported streams. The streams dropped by the relabel configs are not imported")
+	stateFile := flag.String("state-file", "", "File recording the imported tables, default is import-state.json in the archive directory")
+	workingDir := flag.String("working-dir", os.TempDir(), "Directory the index files are downloaded to and built in")
+	parallel := flag.Int("parallel", 8, "How many chunks to copy in parallel")
+	flag.Parse()
+
+	if *archiveDir == "" {
+		log.Fatalln("-archive is required")
+	}
+	lokiCfg, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalln("Failed to load config:", err)
+	}
+	clientMetrics := storage.NewClientMetrics()
+	defer clientMetrics.Unregister()
+
+	ctx := context.Background()
+	switch *mode {
+	case modeExport:
+		fromTime, err := time.Parse(time.RFC3339Nano, *from)
+		if err != nil {
+			log.Fatalln("Failed to parse -from:", err)
+		}
+		toTime, err := time.Parse(time.RFC3339Nano, *to)
+		if err != nil {
+			log.Fatalln("Failed to parse -to:", err)
+		}
+		if !toTime.After(fromTime) {
+			log.Fatalln("-to must be after -from")
+		}
+
+		e := newExporter(lokiCfg.SchemaConfig, lokiCfg.StorageConfig, clientMetrics, *tenant, model.TimeFromUnixNano(fromTime.UnixNano()), model.TimeFromUnixNano(toTime.UnixNano()), *archiveDir, *workingDir, *parallel)
+		m, err := e.run(ctx)
+		if err != nil {
+			log.Fatalln("Failed to export tenant:", err)
+		}
+		log.Printf("Exported %d tables and %d files of tenant %s to %s\n", len(m.Tables), len(m.Checksums), m.Tenant, *archiveDir)
+	case modeImport:
+		relabelConfigs, err := loadRelabelConfigs(*relabelConfigFile)
+		if err != nil {
+			log.Fatalln("Failed to load relabel configs:", err)
+		}
+		if *stateFile == "" {
+			*stateFile = defaultStateFile(*archiveDir)
+		}
+
+		i := newImporter(lokiCfg.SchemaConfig, lokiCfg.StorageConfig, clientMetrics, *destTenant, relabelConfigs, *archiveDir, *stateFile, *workingDir, *parallel)
+		stats, err := i.run(ctx)
+		if err != nil {
+			log.Fatalln("Failed to import archive:", err)
+		}
+		log.Printf("Imported %d tables, %d series and %d chunks, dropped %d series by relabeling\n", stats.Tables, stats.Series, stats.Chunks, stats.DroppedSeries)
+	default:
+		fmt.Fprintf(os.Stderr, "-mode must be either %s or %s\n", modeExport, modeImport)
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func loadConfig(configFile string) (loki.Config, error) {
+	var c loki.ConfigWrapper
+	args := []string{"-config.file=" + configFile}
+	if err := cfg.DynamicUnmarshal(&c, args, flag.NewFlagSet("config-file-loader", flag.ContinueOnError)); err != nil {
+		return loki.Config{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return loki.Config{}, err
+	}
+
+	util_log.InitLogger(&c.Server, prometheus.NewRegistry(), false)
+	return c.Config, nil
+}
+
+// periodClients are the clients of the object store of a schema period.
+type periodClients struct {
+	periodCfg          config.PeriodConfig
+	objectClient       client.ObjectClient
+	chunkClient        client.Client
+	indexStorageClient shipperstorage.Client
+	// keyEncoder is set for the filesystem object store, which encodes the chunk keys.
+	keyEncoder client.KeyEncoder
+}
+
+// newPeriodClients returns the clients of the schema periods, by start of the period.
+func newPeriodClients(schemaCfg config.SchemaConfig, storageCfg storage.Config, clientMetrics storage.ClientMetrics) (map[config.DayTime]*periodClients, error) {
+	clients := map[config.DayTime]*periodClients{}
+	for _, periodCfg := range schemaCfg.Configs {
+		objectClient, err := storage.NewObjectClient(periodCfg.ObjectType, storageCfg, clientMetrics)
+		if err != nil {
+			stopPeriodClients(clients)
+			return nil, fmt.Errorf("failed to create object client for schema period %s: %w", periodCfg.From, err)
+		}
+		clients[periodCfg.From] = newClients(schemaCfg, periodCfg, objectClient)
+	}
+	return clients, nil
+}
+
+func newClients(schemaCfg config.SchemaConfig, periodCfg config.PeriodConfig, objectClient client.ObjectClient) *periodClients {
+	keyEncoder := client.KeyEncoderFor(objectClient)
+
+	return &periodClients{
+		periodCfg:          periodCfg,
+		objectClient:       objectClient,
+		chunkClient:        client.NewClient(objectClient, keyEncoder, schemaCfg),
+		indexStorageClient: shipperstorage.NewIndexStorageClient(objectClient, periodCfg.IndexTables.PathPrefix),
+		keyEncoder:         keyEncoder,
+	}
+}
+
+func stopPeriodClients(clients map[config.DayTime]*periodClients) {
+	for _, c := range clients {
+		c.objectClient.Stop()
+	}
+}
+
+// tableRange returns the time range covered by an index table of 24h.
+func tableRange(table int64) (model.Time, model.Time) {
+	period := int64(config.ObjectStorageIndexRequiredPeriod / time.Millisecond)
+	return model.Time(table * period), model.Time((table+1)*period - 1)
+}
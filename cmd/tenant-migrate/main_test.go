@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	tsdbindex "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+)
+
+const indexPrefix = "tsdb_prefix_"
+
+func newTestSchemaConfig(from model.Time) config.SchemaConfig {
+	return config.SchemaConfig{Configs: []config.PeriodConfig{{
+		From:       config.DayTime{Time: from},
+		IndexType:  "tsdb",
+		ObjectType: "filesystem",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexPrefix,
+				Period: 24 * time.Hour,
+			}},
+	}}}
+}
+
+func newTestChunk(t *testing.T, tenant string, stream labels.Labels, from model.Time, line string) chunk.Chunk {
+	chk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, chk.Append(&logproto.Entry{
+			Timestamp: from.Add(time.Duration(i) * time.Second).Time(),
+			Line:      fmt.Sprintf("%s %d", line, i),
+		}))
+	}
+	require.NoError(t, chk.Close())
+
+	metric := labels.NewBuilder(stream).Set(nameLabel, logsValue).Labels()
+	c := chunk.NewChunk(tenant, model.Fingerprint(stream.Hash()), metric, chunkenc.NewFacade(chk, 0, 0), from, from.Add(9*time.Second))
+	require.NoError(t, c.Encode())
+	return c
+}
+
+// putIndex uploads an index of the chunks to the table, as a multi-tenant file when tenant is empty.
+func putIndex(t *testing.T, c *periodClients, tableName, tenant string, chunks ...chunk.Chunk) {
+	b := tsdb.NewBuilder(tsdbindex.FormatV3)
+	for _, c := range chunks {
+		ls := labels.NewBuilder(c.Metric).Del(nameLabel)
+		if tenant == "" {
+			ls.Set(tsdb.TenantLabel, c.UserID)
+		}
+		b.AddSeries(ls.Labels(), model.Fingerprint(c.Fingerprint), []tsdbindex.ChunkMeta{{
+			Checksum: c.Checksum,
+			MinTime:  int64(c.From),
+			MaxTime:  int64(c.Through),
+			KB:       1,
+			Entries:  10,
+		}})
+	}
+
+	dir := t.TempDir()
+	id, err := b.Build(context.Background(), dir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tsdb.NewPrefixedIdentifier(tsdb.SingleTenantTSDBIdentifier{TS: time.Now(), From: from, Through: through, Checksum: checksum}, dir, "")
+	})
+	require.NoError(t, err)
+	f, err := os.Open(id.Path())
+	require.NoError(t, err)
+	defer f.Close()
+
+	if tenant == "" {
+		require.NoError(t, c.indexStorageClient.PutFile(context.Background(), tableName, id.Name(), f))
+		return
+	}
+	require.NoError(t, c.indexStorageClient.PutUserFile(context.Background(), tableName, tenant, id.Name(), f))
+}
+
+func readLines(t *testing.T, c chunk.Chunk) []string {
+	it, err := c.Data.(*chunkenc.Facade).LokiChunk().Iterator(context.Background(), c.From.Time(), c.Through.Time().Add(time.Nanosecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(c.Metric))
+	require.NoError(t, err)
+	defer it.Close()
+
+	var lines []string
+	for it.Next() {
+		lines = append(lines, it.Entry().Line)
+	}
+	require.NoError(t, it.Error())
+	return lines
+}
+
+func TestExportImport(t *testing.T) {
+	now := model.Now()
+	tableNum := int64(now.Add(-3*24*time.Hour)) / int64(24*time.Hour/time.Millisecond)
+	tableFrom, _ := tableRange(tableNum)
+	schemaCfg := newTestSchemaConfig(now.Add(-10 * 24 * time.Hour))
+
+	newStore := func(schemaCfg config.SchemaConfig) *periodClients {
+		objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+		require.NoError(t, err)
+		return newClients(schemaCfg, schemaCfg.Configs[0], objectClient)
+	}
+	source := newStore(schemaCfg)
+
+	var (
+		foo   = labels.FromStrings("app", "foo", "env", "prod")
+		bar   = labels.FromStrings("app", "bar", "env", "prod")
+		debug = labels.FromStrings("app", "foo", "env", "debug")
+		// the chunks of the first table are still in a multi-tenant file, with a chunk of another tenant.
+		fooChunk   = newTestChunk(t, "user1", foo, tableFrom.Add(time.Hour), "foo")
+		otherChunk = newTestChunk(t, "user3", foo, tableFrom.Add(time.Hour), "other")
+		// the chunks of the second table are compacted in a file of the tenant.
+		barChunk   = newTestChunk(t, "user1", bar, tableFrom.Add(25*time.Hour), "bar")
+		debugChunk = newTestChunk(t, "user1", debug, tableFrom.Add(26*time.Hour), "debug")
+		// the chunks after the exported range are not exported.
+		lateChunk = newTestChunk(t, "user1", bar, tableFrom.Add(30*time.Hour), "late")
+	)
+	allChunks := []chunk.Chunk{fooChunk, otherChunk, barChunk, debugChunk, lateChunk}
+	require.NoError(t, source.chunkClient.PutChunks(context.Background(), allChunks))
+	putIndex(t, source, indexPrefix+fmt.Sprint(tableNum), "", fooChunk, otherChunk)
+	putIndex(t, source, indexPrefix+fmt.Sprint(tableNum+1), "user1", barChunk, debugChunk, lateChunk)
+
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	export := func() *manifest {
+		e := newExporter(schemaCfg, storage.Config{}, storage.ClientMetrics{}, "user1", tableFrom, tableFrom.Add(27*time.Hour), archiveDir, t.TempDir(), 2)
+		clients := map[config.DayTime]*periodClients{schemaCfg.Configs[0].From: source}
+		m, err := e.export(context.Background(), clients)
+		require.NoError(t, err)
+		return m
+	}
+	m := export()
+	require.Equal(t, "user1", m.Tenant)
+	require.Equal(t, []tableSummary{{Table: tableNum, Series: 1, Chunks: 1}, {Table: tableNum + 1, Series: 2, Chunks: 2}}, m.Tables)
+	// the indexes of the tables and the exported chunks.
+	require.Len(t, m.Checksums, 5)
+
+	// the tables of an archive are not exported again.
+	require.NoError(t, os.Remove(filepath.Join(archiveDir, chunkPath(fooChunk.ChunkRef))))
+	m = export()
+	require.Len(t, m.Checksums, 5)
+	_, err := os.Stat(filepath.Join(archiveDir, chunkPath(fooChunk.ChunkRef)))
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, os.RemoveAll(archiveDir))
+	m = export()
+
+	// the archive is imported as another tenant, without the debug streams and with the app label renamed.
+	relabelConfigFile := filepath.Join(t.TempDir(), "relabel.yaml")
+	require.NoError(t, os.WriteFile(relabelConfigFile, []byte(`
+- source_labels: [env]
+  regex: debug
+  action: drop
+- source_labels: [app]
+  target_label: service
+- regex: app
+  action: labeldrop
+`), 0o640))
+	relabelConfigs, err := loadRelabelConfigs(relabelConfigFile)
+	require.NoError(t, err)
+
+	destSchemaCfg := newTestSchemaConfig(now.Add(-20 * 24 * time.Hour))
+	dest := newStore(destSchemaCfg)
+	stateFile := filepath.Join(t.TempDir(), stateFileName)
+	importArchive := func() (importStats, error) {
+		i := newImporter(destSchemaCfg, storage.Config{}, storage.ClientMetrics{}, "user2", relabelConfigs, archiveDir, stateFile, t.TempDir(), 2)
+		clients := map[config.DayTime]*periodClients{destSchemaCfg.Configs[0].From: dest}
+		return i.importArchive(context.Background(), clients)
+	}
+	stats, err := importArchive()
+	require.NoError(t, err)
+	require.Equal(t, importStats{Tables: 2, Series: 2, Chunks: 2, DroppedSeries: 1}, stats)
+
+	// the imported tables are not imported again.
+	stats, err = importArchive()
+	require.NoError(t, err)
+	require.Equal(t, importStats{}, stats)
+
+	// a table imported again overwrites the index and chunks of the previous import.
+	require.NoError(t, os.Remove(stateFile))
+	_, err = importArchive()
+	require.NoError(t, err)
+
+	imported := map[string][]string{}
+	for _, table := range []int64{tableNum, tableNum + 1} {
+		tableName := indexPrefix + fmt.Sprint(table)
+		files, err := dest.indexStorageClient.ListUserFiles(context.Background(), tableName, "user2", true)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		path, err := download(t.TempDir(), files[0].Name, func() (io.ReadCloser, error) {
+			return dest.indexStorageClient.GetUserFile(context.Background(), tableName, "user2", files[0].Name)
+		})
+		require.NoError(t, err)
+		idx, err := tsdb.OpenShippableTSDB(path)
+		require.NoError(t, err)
+
+		var refs []chunk.Chunk
+		err = idx.(*tsdb.TSDBFile).Index.(*tsdb.TSDBIndex).ForSeries(context.Background(), "", nil, model.Earliest, model.Latest,
+			func(ls labels.Labels, fp model.Fingerprint, chks []tsdbindex.ChunkMeta) (stop bool) {
+				require.Equal(t, model.Fingerprint(ls.Hash()), fp)
+				for _, chk := range chks {
+					refs = append(refs, chunk.Chunk{ChunkRef: logproto.ChunkRef{UserID: "user2", Fingerprint: uint64(fp), From: chk.From(), Through: chk.Through(), Checksum: chk.Checksum}})
+				}
+				return false
+			},
+			labels.MustNewMatcher(labels.MatchEqual, "", ""),
+		)
+		require.NoError(t, err)
+		require.NoError(t, idx.Close())
+
+		chunks, err := dest.chunkClient.GetChunks(context.Background(), refs)
+		require.NoError(t, err)
+		for _, c := range chunks {
+			stream := labels.NewBuilder(c.Metric).Del(nameLabel).Labels().String()
+			imported[stream] = append(imported[stream], readLines(t, c)[0])
+		}
+	}
+	require.Equal(t, map[string][]string{
+		`{env="prod", service="foo"}`: {"foo 0"},
+		`{env="prod", service="bar"}`: {"bar 0"},
+	}, imported)
+
+	// the files of the archive are verified against the checksums of the manifest.
+	require.NoError(t, os.Remove(stateFile))
+	require.NoError(t, os.WriteFile(filepath.Join(archiveDir, chunkPath(barChunk.ChunkRef)), []byte("corrupt"), 0o640))
+	_, err = importArchive()
+	require.ErrorContains(t, err, "checksum mismatch")
+}
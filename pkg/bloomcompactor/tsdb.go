@@ -135,11 +135,21 @@ func NewTSDBSeriesIter(ctx context.Context, user string, f sharding.ForSeries, b
 		user,
 		bounds,
 		0, math.MaxInt64,
-		func(_ labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) (stop bool) {
+		func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) (stop bool) {
 			select {
 			case <-ctx.Done():
 				return true
 			default:
+				// The chunks of the series with a storage class can't be loaded from the chunk refs of the blooms,
+				// which don't record it. They are left out of the blooms, so they are never filtered out.
+				if ls.Has(tsdb.StorageClassLabel) {
+					return false
+				}
+				// The structured metadata series repeat the chunks of their stream.
+				if ls.Has(tsdb.StructuredMetadataLabel) {
+					return false
+				}
+
 				res := &v1.Series{
 					Fingerprint: fp,
 					Chunks:      make(v1.ChunkRefs, 0, len(chks)),
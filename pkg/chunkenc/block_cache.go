@@ -0,0 +1,54 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// BlockCache caches the decompressed content of chunk blocks, keyed by the hash of their
+// compressed content. The cached content is shared by the iterators and must not be modified.
+type BlockCache interface {
+	Get(key uint64) ([]byte, bool)
+	Put(key uint64, b []byte)
+}
+
+type blockCacheCtxKey struct{}
+
+// WithBlockCache returns a context making the block iterators of the chunks reuse the
+// decompressed blocks of the cache, and add the blocks they decompress to it.
+func WithBlockCache(ctx context.Context, cache BlockCache) context.Context {
+	return context.WithValue(ctx, blockCacheCtxKey{}, cache)
+}
+
+func blockCacheFromContext(ctx context.Context) BlockCache {
+	cache, _ := ctx.Value(blockCacheCtxKey{}).(BlockCache)
+	return cache
+}
+
+// decompressCached returns the decompressed content of b from the cache, or decompresses
+// and caches it.
+func decompressCached(cache BlockCache, stats *stats.Context, pool ReaderPool, b []byte) ([]byte, error) {
+	key := xxhash.Sum64(b)
+	if decompressed, ok := cache.Get(key); ok {
+		stats.AddIngesterBlockCacheHits(1)
+		return decompressed, nil
+	}
+
+	reader, err := pool.GetReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer pool.PutReader(reader)
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	cache.Put(key, decompressed)
+	return decompressed, nil
+}
@@ -0,0 +1,65 @@
+package chunkenc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc/testdata"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+type mapBlockCache map[uint64][]byte
+
+func (c mapBlockCache) Get(key uint64) ([]byte, bool) {
+	b, ok := c[key]
+	return b, ok
+}
+
+func (c mapBlockCache) Put(key uint64, b []byte) {
+	c[key] = b
+}
+
+func TestMemChunk_BlockCache(t *testing.T) {
+	for _, f := range allPossibleFormats {
+		f := f
+		t.Run(fmt.Sprintf("%v-%v", f.chunkFormat, f.headBlockFmt), func(t *testing.T) {
+			chk := NewMemChunk(f.chunkFormat, EncSnappy, f.headBlockFmt, testBlockSize, testTargetSize)
+			var expected []string
+			for i := 0; i < 1000; i++ {
+				e := &logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: testdata.LogString(int64(i))}
+				require.NoError(t, chk.Append(e))
+				expected = append(expected, e.Line)
+			}
+			require.NoError(t, chk.Close())
+			require.Greater(t, chk.BlockCount(), 1)
+
+			cache := mapBlockCache{}
+			query := func() ([]string, int64) {
+				sts, ctx := stats.NewContext(WithBlockCache(context.Background(), cache))
+				it, err := chk.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+				require.NoError(t, err)
+				var lines []string
+				for it.Next() {
+					lines = append(lines, it.Entry().Line)
+				}
+				require.NoError(t, it.Close())
+				return lines, sts.Ingester().TotalBlockCacheHits
+			}
+
+			lines, hits := query()
+			require.Equal(t, expected, lines)
+			require.Zero(t, hits)
+			require.NotEmpty(t, cache)
+
+			lines, hits = query()
+			require.Equal(t, expected, lines)
+			require.Equal(t, int64(len(cache)), hits)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package chunkenc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// headBucketWidth is the width of the time windows the entries of BucketedHeadBlockFmt
+// head blocks are bucketed by.
+const headBucketWidth = int64(time.Minute)
+
+// headBuckets stores the entries of BucketedHeadBlockFmt head blocks.
+// The range tree of the unordered head blocks pays an O(log(n)) insert, with allocations,
+// for every entry, which is costly for streams with a lot of jitter. Instead, entries are
+// bucketed by fixed time windows and kept ordered within each window: entries are usually
+// either the most recent ones or close to them, so they are appended or inserted near the end
+// of their window. Windows also let the chunk cut blocks which don't overlap, see MemChunk.cutClosedBuckets.
+type headBuckets struct {
+	buckets []*headBucket // ordered by start
+}
+
+// headBucket holds the entries of a time window, ordered by timestamp.
+type headBucket struct {
+	start   int64
+	entries []nsEntries
+	lines   int // number of entries
+	size    int // size of uncompressed bytes.
+}
+
+func newBucketedHeadBlock(symbolizer *symbolizer) *unorderedHeadBlock {
+	return &unorderedHeadBlock{
+		format:     BucketedHeadBlockFmt,
+		symbolizer: symbolizer,
+		buckets:    &headBuckets{},
+	}
+}
+
+func bucketStart(ts int64) int64 {
+	start := ts - ts%headBucketWidth
+	if start > ts {
+		// negative timestamps
+		start -= headBucketWidth
+	}
+	return start
+}
+
+// bucket returns the bucket of the window of the given timestamp, creating it if needed.
+func (b *headBuckets) bucket(ts int64) *headBucket {
+	start := bucketStart(ts)
+	n := len(b.buckets)
+	if n > 0 && b.buckets[n-1].start == start {
+		return b.buckets[n-1]
+	}
+	i := sort.Search(n, func(i int) bool { return b.buckets[i].start >= start })
+	if i < n && b.buckets[i].start == start {
+		return b.buckets[i]
+	}
+	bucket := &headBucket{start: start}
+	b.buckets = append(b.buckets, nil)
+	copy(b.buckets[i+1:], b.buckets[i:])
+	b.buckets[i] = bucket
+	return bucket
+}
+
+// add adds an entry to its window. It returns false if the entry is a duplicate
+// of an entry with the same timestamp and content.
+func (b *headBuckets) add(ts int64, line string, structuredMetadata labels.Labels, symbolizer *symbolizer) bool {
+	bucket := b.bucket(ts)
+	e := bucket.at(ts)
+	for _, et := range e.entries {
+		if et.line == line {
+			return false
+		}
+	}
+	e.entries = append(e.entries, nsEntry{line, symbolizer.Add(structuredMetadata)})
+	bucket.lines++
+	bucket.size += entrySize(line, structuredMetadata)
+	return true
+}
+
+// at returns the entries of the bucket at the given timestamp, creating them if needed.
+func (b *headBucket) at(ts int64) *nsEntries {
+	n := len(b.entries)
+	if n == 0 || b.entries[n-1].ts < ts {
+		b.entries = append(b.entries, nsEntries{ts: ts})
+		return &b.entries[n]
+	}
+	i := sort.Search(n, func(i int) bool { return b.entries[i].ts >= ts })
+	if b.entries[i].ts != ts {
+		b.entries = append(b.entries, nsEntries{})
+		copy(b.entries[i+1:], b.entries[i:])
+		b.entries[i] = nsEntries{ts: ts}
+	}
+	return &b.entries[i]
+}
+
+// query returns the entries within [mint, maxt), as one ordered span per window.
+func (b *headBuckets) query(mint, maxt int64) [][]nsEntries {
+	var spans [][]nsEntries
+	for _, bucket := range b.buckets {
+		if bucket.start >= maxt {
+			break
+		}
+		if bucket.start+headBucketWidth <= mint {
+			continue
+		}
+		entries := bucket.entries
+		from := sort.Search(len(entries), func(i int) bool { return entries[i].ts >= mint })
+		through := sort.Search(len(entries), func(i int) bool { return entries[i].ts >= maxt })
+		if from < through {
+			spans = append(spans, entries[from:through])
+		}
+	}
+	return spans
+}
+
+// cutClosedBuckets moves all the windows of the head block but the most recent one to a new
+// head block, which is returned. It returns nil and leaves the head block untouched when it has
+// a single window or when the uncompressed size of the other windows is below minSize.
+func (hb *unorderedHeadBlock) cutClosedBuckets(minSize int) *unorderedHeadBlock {
+	buckets := hb.buckets.buckets
+	if len(buckets) < 2 {
+		return nil
+	}
+	closed, last := buckets[:len(buckets)-1], buckets[len(buckets)-1]
+	if hb.size-last.size < minSize {
+		return nil
+	}
+
+	out := newBucketedHeadBlock(hb.symbolizer)
+	out.buckets.buckets = closed
+	out.lines = hb.lines - last.lines
+	out.size = hb.size - last.size
+	out.mint = closed[0].entries[0].ts
+	out.maxt = closed[len(closed)-1].entries[len(closed[len(closed)-1].entries)-1].ts
+
+	hb.buckets.buckets = []*headBucket{last}
+	hb.lines = last.lines
+	hb.size = last.size
+	hb.mint = last.entries[0].ts
+	hb.maxt = last.entries[len(last.entries)-1].ts
+	return out
+}
@@ -0,0 +1,233 @@
+package chunkenc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// jitteredEntries returns n entries a second apart, each shifted by up to jitter.
+func jitteredEntries(n int, jitter time.Duration) []entry {
+	rnd := rand.New(rand.NewSource(0))
+	entries := make([]entry, 0, n)
+	for i := 0; i < n; i++ {
+		ts := int64(i) * int64(time.Second)
+		if jitter > 0 {
+			ts += rnd.Int63n(int64(jitter))
+		}
+		entries = append(entries, entry{
+			t:                  ts,
+			s:                  fmt.Sprint("line:", i),
+			structuredMetadata: labels.Labels{{Name: "foo", Value: fmt.Sprint(i % 10)}},
+		})
+	}
+	return entries
+}
+
+func TestBucketedHeadBlock(t *testing.T) {
+	hb := newBucketedHeadBlock(newSymbolizer())
+	entries := jitteredEntries(500, 5*time.Minute)
+	for _, e := range entries {
+		require.NoError(t, hb.Append(e.t, e.s, e.structuredMetadata))
+	}
+	// duplicates are ignored.
+	require.NoError(t, hb.Append(entries[10].t, entries[10].s, nil))
+	// entries with the same timestamp but different content are kept.
+	require.NoError(t, hb.Append(entries[10].t, "other line", entries[10].structuredMetadata))
+
+	require.Equal(t, 501, hb.Entries())
+	mint, maxt := hb.Bounds()
+
+	unordered := newUnorderedHeadBlock(UnorderedWithStructuredMetadataColumnsHeadBlockFmt, newSymbolizer())
+	for _, e := range entries {
+		require.NoError(t, unordered.Append(e.t, e.s, e.structuredMetadata))
+	}
+	require.NoError(t, unordered.Append(entries[10].t, "other line", entries[10].structuredMetadata))
+	require.Equal(t, unordered.UncompressedSize(), hb.UncompressedSize())
+	umint, umaxt := unordered.Bounds()
+	require.Equal(t, umint, mint)
+	require.Equal(t, umaxt, maxt)
+
+	for _, direction := range []logproto.Direction{logproto.FORWARD, logproto.BACKWARD} {
+		for _, r := range [][2]int64{{0, math.MaxInt64}, {int64(time.Minute), 3 * int64(time.Minute)}, {mint + 1, mint + int64(time.Second)}} {
+			t.Run(fmt.Sprintf("%v %v", direction, r), func(t *testing.T) {
+				var exp []entry
+				it := unordered.Iterator(context.Background(), direction, r[0], r[1], noopStreamPipeline)
+				for it.Next() {
+					e := it.Entry()
+					exp = append(exp, entry{t: e.Timestamp.UnixNano(), s: e.Line, structuredMetadata: logproto.FromLabelAdaptersToLabels(e.StructuredMetadata)})
+				}
+				require.NoError(t, it.Close())
+				iterEq(t, exp, hb.Iterator(context.Background(), direction, r[0], r[1], noopStreamPipeline))
+			})
+		}
+	}
+
+	// Both head blocks serialise to the same block.
+	ub, err := unordered.Serialise(GetWriterPool(EncSnappy))
+	require.NoError(t, err)
+	bb, err := hb.Serialise(GetWriterPool(EncSnappy))
+	require.NoError(t, err)
+	require.Equal(t, ub, bb)
+
+	// Checkpoints round trip, and are converted from and to unordered head blocks.
+	b, err := hb.CheckpointBytes(nil)
+	require.NoError(t, err)
+	restored, err := HeadFromCheckpoint(b, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, newSymbolizer())
+	require.NoError(t, err)
+	require.Equal(t, BucketedHeadBlockFmt, restored.Format())
+	require.Equal(t, hb.Entries(), restored.Entries())
+
+	converted, err := unordered.Convert(BucketedHeadBlockFmt, newSymbolizer())
+	require.NoError(t, err)
+	require.Equal(t, BucketedHeadBlockFmt, converted.Format())
+	require.Equal(t, hb.Entries(), converted.Entries())
+	require.Equal(t, hb.UncompressedSize(), converted.UncompressedSize())
+
+	back, err := hb.Convert(UnorderedWithStructuredMetadataColumnsHeadBlockFmt, newSymbolizer())
+	require.NoError(t, err)
+	require.Equal(t, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, back.Format())
+	require.Equal(t, hb.Entries(), back.Entries())
+}
+
+func TestBucketedHeadBlock_NegativeTimestamps(t *testing.T) {
+	hb := newBucketedHeadBlock(newSymbolizer())
+	for _, ts := range []int64{-1, 1, -int64(time.Minute), 0, -int64(time.Minute) - 1} {
+		require.NoError(t, hb.Append(ts, fmt.Sprint(ts), nil))
+	}
+	var got []int64
+	it := hb.Iterator(context.Background(), logproto.FORWARD, math.MinInt64, math.MaxInt64, noopStreamPipeline)
+	for it.Next() {
+		got = append(got, it.Entry().Timestamp.UnixNano())
+	}
+	require.Equal(t, []int64{-int64(time.Minute) - 1, -int64(time.Minute), -1, 0, 1}, got)
+	require.Len(t, hb.buckets.buckets, 3)
+}
+
+func TestBucketedChunkCutsClosedWindows(t *testing.T) {
+	c := NewMemChunk(ChunkFormatV5, EncSnappy, BucketedHeadBlockFmt, 4*1024, 0)
+	entries := jitteredEntries(2000, 10*time.Second)
+	for _, e := range entries {
+		require.NoError(t, c.Append(&logproto.Entry{
+			Timestamp:          time.Unix(0, e.t),
+			Line:               e.s,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(e.structuredMetadata),
+		}))
+	}
+	require.Greater(t, len(c.blocks), 1)
+
+	// The blocks cut from the closed windows stop at a window boundary.
+	var aligned int
+	for _, b := range c.blocks {
+		if bucketStart(b.maxt) != bucketStart(c.blocks[len(c.blocks)-1].maxt) && bucketStart(b.maxt)+headBucketWidth > b.maxt {
+			aligned++
+		}
+	}
+	require.Greater(t, aligned, 0)
+
+	require.NoError(t, c.Close())
+	b, err := c.Bytes()
+	require.NoError(t, err)
+	chk, err := NewByteChunk(b, 4*1024, 0)
+	require.NoError(t, err)
+
+	it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+	require.NoError(t, err)
+	var (
+		n      int
+		lastTs int64
+	)
+	for it.Next() {
+		ts := it.Entry().Timestamp.UnixNano()
+		require.GreaterOrEqual(t, ts, lastTs)
+		lastTs = ts
+		n++
+	}
+	require.NoError(t, it.Close())
+	require.Equal(t, len(entries), n)
+}
+
+func TestConvertHeadToBucketed(t *testing.T) {
+	c := NewMemChunk(ChunkFormatV4, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	require.Error(t, c.ConvertHead(BucketedHeadBlockFmt))
+
+	c = NewMemChunk(ChunkFormatV5, EncSnappy, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, c.Append(logprotoEntry(2, "2")))
+	require.NoError(t, c.Append(logprotoEntry(1, "1")))
+	require.NoError(t, c.ConvertHead(BucketedHeadBlockFmt))
+	require.Equal(t, BucketedHeadBlockFmt, c.HeadFormat())
+	require.Equal(t, 2, c.head.Entries())
+}
+
+func BenchmarkBucketedHeadBlockWrites(b *testing.B) {
+	for _, jitter := range []time.Duration{0, 10 * time.Second, 5 * time.Minute} {
+		writes := jitteredEntries(100000, jitter)
+		for _, f := range []HeadBlockFmt{UnorderedWithStructuredMetadataColumnsHeadBlockFmt, BucketedHeadBlockFmt} {
+			b.Run(fmt.Sprintf("%v jitter=%v", f, jitter), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					hb := f.NewBlock(newSymbolizer())
+					for _, w := range writes {
+						_ = hb.Append(w.t, w.s, w.structuredMetadata)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkBucketedHeadBlockRead(b *testing.B) {
+	for _, jitter := range []time.Duration{0, 10 * time.Second, 5 * time.Minute} {
+		writes := jitteredEntries(10000, jitter)
+		for _, f := range []HeadBlockFmt{UnorderedWithStructuredMetadataColumnsHeadBlockFmt, BucketedHeadBlockFmt} {
+			hb := f.NewBlock(newSymbolizer())
+			for _, w := range writes {
+				_ = hb.Append(w.t, w.s, w.structuredMetadata)
+			}
+			b.Run(fmt.Sprintf("itr/%v jitter=%v", f, jitter), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					it := hb.Iterator(context.Background(), logproto.FORWARD, 0, math.MaxInt64, noopStreamPipeline)
+					for it.Next() {
+						_ = it.Entry()
+					}
+					_ = it.Close()
+				}
+			})
+			b.Run(fmt.Sprintf("smpl/%v jitter=%v", f, jitter), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					it := hb.SampleIterator(context.Background(), 0, math.MaxInt64, countExtractor)
+					for it.Next() {
+						_ = it.Sample()
+					}
+					_ = it.Close()
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkBucketedChunkWrites(b *testing.B) {
+	for _, jitter := range []time.Duration{0, 10 * time.Second, 5 * time.Minute} {
+		writes := jitteredEntries(100000, jitter)
+		for _, f := range []HeadBlockFmt{UnorderedWithStructuredMetadataColumnsHeadBlockFmt, BucketedHeadBlockFmt} {
+			b.Run(fmt.Sprintf("%v jitter=%v", f, jitter), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					c := NewMemChunk(ChunkFormatV5, EncSnappy, f, testBlockSize, 0)
+					for _, w := range writes {
+						_ = c.Append(&logproto.Entry{Timestamp: time.Unix(0, w.t), Line: w.s, StructuredMetadata: logproto.FromLabelsToLabelAdapters(w.structuredMetadata)})
+					}
+					_ = c.Close()
+				}
+			})
+		}
+	}
+}
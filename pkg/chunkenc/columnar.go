@@ -0,0 +1,256 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// Blocks of ChunkFormatV5 chunks are made of separately compressed sections, so that
+// readers only decompress the data they need:
+//
+//	entries section | lines section | #columns (uvarint) | column 1 | ... | column n
+//
+// The entries section holds the timestamp (varint) and the xxhash of the line (be64) of
+// each entry, and the lines section holds the length (uvarint) and the content of each line.
+// Each column holds the values of one structured metadata name: the name symbol (uvarint)
+// followed by a section with, for each entry, the value symbol + 1 (uvarint), or 0 when the
+// entry doesn't have this name. Columns are sorted by name and a name repeated within an
+// entry is written to as many columns.
+//
+// Each section is written as its decompressed length (uvarint), its compressed length (uvarint)
+// and the compressed bytes.
+
+type columnWriter struct {
+	name    uint32
+	entries int // number of entries written to the column
+	buf     encbuf
+}
+
+// pad marks the column as empty for the entries without this name, up to n.
+func (c *columnWriter) pad(n int) {
+	for ; c.entries < n; c.entries++ {
+		c.buf.putUvarint(0)
+	}
+}
+
+// serialiseColumns creates a columnar, compressed block from an unorderedHeadBlock.
+func (hb *unorderedHeadBlock) serialiseColumns(pool WriterPool) ([]byte, error) {
+	var (
+		entriesBuf, linesBuf encbuf
+		columns              []*columnWriter
+		n                    int
+	)
+
+	_ = hb.forEntries(
+		context.Background(),
+		logproto.FORWARD,
+		0,
+		math.MaxInt64,
+		func(_ *stats.Context, ts int64, line string, structuredMetadataSymbols symbols) error {
+			entriesBuf.putVarint64(ts)
+			entriesBuf.putBE64(xxhash.Sum64String(line))
+
+			linesBuf.putUvarint(len(line))
+			linesBuf.b = append(linesBuf.b, line...)
+
+			for _, s := range structuredMetadataSymbols {
+				var col *columnWriter
+				for _, c := range columns {
+					if c.name == s.Name && c.entries <= n {
+						col = c
+						break
+					}
+				}
+				if col == nil {
+					col = &columnWriter{name: s.Name}
+					columns = append(columns, col)
+				}
+				col.pad(n)
+				col.buf.putUvarint64(uint64(s.Value) + 1)
+				col.entries++
+			}
+			n++
+			return nil
+		},
+	)
+
+	sort.SliceStable(columns, func(i, j int) bool {
+		return hb.symbolizer.lookup(columns[i].name) < hb.symbolizer.lookup(columns[j].name)
+	})
+
+	compressedBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		compressedBuf.Reset()
+		serializeBytesBufferPool.Put(compressedBuf)
+	}()
+
+	var out encbuf
+	if err := writeSection(&out, entriesBuf.get(), pool, compressedBuf); err != nil {
+		return nil, err
+	}
+	if err := writeSection(&out, linesBuf.get(), pool, compressedBuf); err != nil {
+		return nil, err
+	}
+	out.putUvarint(len(columns))
+	for _, col := range columns {
+		col.pad(n)
+		out.putUvarint64(uint64(col.name))
+		if err := writeSection(&out, col.buf.get(), pool, compressedBuf); err != nil {
+			return nil, err
+		}
+	}
+
+	return out.get(), nil
+}
+
+func writeSection(out *encbuf, b []byte, pool WriterPool, compressedBuf *bytes.Buffer) error {
+	compressedBuf.Reset()
+	compressedWriter := pool.GetWriter(compressedBuf)
+	defer pool.PutWriter(compressedWriter)
+
+	if _, err := compressedWriter.Write(b); err != nil {
+		return errors.Wrap(err, "appending section")
+	}
+	if err := compressedWriter.Close(); err != nil {
+		return errors.Wrap(err, "flushing pending compress buffer")
+	}
+
+	out.putUvarint(len(b))
+	out.putUvarint(compressedBuf.Len())
+	out.b = append(out.b, compressedBuf.Bytes()...)
+	return nil
+}
+
+type columnReader struct {
+	name   uint32
+	values decbuf
+}
+
+// columnsReader reads the entries of a block written by serialiseColumns.
+type columnsReader struct {
+	entries decbuf
+	lines   decbuf
+	columns []columnReader
+
+	skipLines bool
+	cache     BlockCache // when set, sections are read from and added to the cache.
+
+	// decompressed sections, returned to the pool once the reader is closed.
+	bufs [][]byte
+
+	hash uint64 // the hash of the current line.
+}
+
+// newColumnsReader decompresses the sections of the block. When skipLines is set the lines
+// are not decompressed and the reader returns empty lines.
+func newColumnsReader(stats *stats.Context, pool ReaderPool, cache BlockCache, b []byte, skipLines bool) (*columnsReader, error) {
+	r := &columnsReader{skipLines: skipLines, cache: cache}
+	db := decbuf{b: b}
+
+	var err error
+	if r.entries.b, err = r.readSection(stats, &db, pool, false); err != nil {
+		r.close()
+		return nil, errors.Wrap(err, "reading entries section")
+	}
+	if r.lines.b, err = r.readSection(stats, &db, pool, skipLines); err != nil {
+		r.close()
+		return nil, errors.Wrap(err, "reading lines section")
+	}
+	decompressedBytes := int64(len(r.entries.b) + len(r.lines.b))
+
+	var decompressedStructuredMetadataBytes int64
+	r.columns = make([]columnReader, db.uvarint())
+	for i := range r.columns {
+		r.columns[i].name = uint32(db.uvarint64())
+		if r.columns[i].values.b, err = r.readSection(stats, &db, pool, false); err != nil {
+			r.close()
+			return nil, errors.Wrap(err, "reading structured metadata column")
+		}
+		decompressedStructuredMetadataBytes += int64(len(r.columns[i].values.b))
+	}
+	if db.err() != nil {
+		r.close()
+		return nil, errors.Wrap(db.err(), "reading block")
+	}
+
+	stats.AddDecompressedStructuredMetadataBytes(decompressedStructuredMetadataBytes)
+	stats.AddDecompressedBytes(decompressedBytes + decompressedStructuredMetadataBytes)
+	return r, nil
+}
+
+func (r *columnsReader) readSection(stats *stats.Context, db *decbuf, pool ReaderPool, skip bool) ([]byte, error) {
+	size := db.uvarint()
+	compressed := db.bytes(db.uvarint())
+	if db.err() != nil {
+		return nil, db.err()
+	}
+	if skip || size == 0 {
+		return nil, nil
+	}
+	if r.cache != nil {
+		// Cached sections are shared, so they are not returned to the pool.
+		return decompressCached(r.cache, stats, pool, compressed)
+	}
+
+	reader, err := pool.GetReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer pool.PutReader(reader)
+
+	buf := BytesBufferPool.Get(size).([]byte)[:size]
+	r.bufs = append(r.bufs, buf)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// next reads the next entry, appending its structured metadata symbols to syms.
+// It returns false once all entries have been read.
+func (r *columnsReader) next(syms symbols) (int64, []byte, symbols, bool, error) {
+	if len(r.entries.b) == 0 {
+		return 0, nil, syms, false, nil
+	}
+
+	ts := r.entries.varint64()
+	r.hash = r.entries.be64()
+	if r.entries.err() != nil {
+		return 0, nil, syms, false, errors.Wrap(r.entries.err(), "reading entry")
+	}
+
+	var line []byte
+	if !r.skipLines {
+		line = r.lines.bytes(r.lines.uvarint())
+		if r.lines.err() != nil {
+			return 0, nil, syms, false, errors.Wrap(r.lines.err(), "reading line")
+		}
+	}
+
+	for i := range r.columns {
+		v := r.columns[i].values.uvarint64()
+		if err := r.columns[i].values.err(); err != nil {
+			return 0, nil, syms, false, errors.Wrap(err, "reading structured metadata")
+		}
+		if v > 0 {
+			syms = append(syms, symbol{Name: r.columns[i].name, Value: uint32(v - 1)})
+		}
+	}
+	return ts, line, syms, true, nil
+}
+
+func (r *columnsReader) close() {
+	for _, b := range r.bufs {
+		BytesBufferPool.Put(b)
+	}
+	r.bufs = nil
+}
@@ -0,0 +1,108 @@
+package chunkenc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+func TestMemChunk_ColumnarStructuredMetadata(t *testing.T) {
+	streamLabels := labels.FromStrings("job", "fake")
+	entries := []logproto.Entry{
+		*logprotoEntryWithStructuredMetadata(1, "lineA", logproto.FromLabelsToLabelAdapters(labels.FromStrings("traceID", "123", "user", "a"))),
+		*logprotoEntry(2, "lineB"),
+		*logprotoEntryWithStructuredMetadata(3, "lineC", logproto.FromLabelsToLabelAdapters(labels.FromStrings("traceID", "123"))),
+		*logprotoEntryWithStructuredMetadata(4, "lineD", logproto.FromLabelsToLabelAdapters(labels.FromStrings("user", "d"))),
+	}
+
+	for _, enc := range testEncoding {
+		enc := enc
+		t.Run(enc.String(), func(t *testing.T) {
+			chk := NewMemChunk(ChunkFormatV5, enc, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, testBlockSize, testTargetSize)
+			for i := range entries {
+				e := entries[i]
+				require.NoError(t, chk.Append(&e))
+			}
+			require.NoError(t, chk.Close())
+
+			b, err := chk.Bytes()
+			require.NoError(t, err)
+			chk, err = NewByteChunk(b, testBlockSize, testTargetSize)
+			require.NoError(t, err)
+
+			it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+			require.NoError(t, err)
+			var got []logproto.Entry
+			for it.Next() {
+				got = append(got, it.Entry())
+			}
+			require.NoError(t, it.Close())
+			require.Equal(t, entries, got)
+
+			sampleBytes := func(query string) (int, int64) {
+				expr, err := syntax.ParseSampleExpr(query)
+				require.NoError(t, err)
+				extractor, err := expr.Extractor()
+				require.NoError(t, err)
+
+				sts, ctx := stats.NewContext(context.Background())
+				it := chk.SampleIterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), extractor.ForStream(streamLabels))
+				var n int
+				for it.Next() {
+					s := it.Sample()
+					require.Equal(t, xxhash.Sum64String(entries[s.Timestamp-1].Line), s.Hash)
+					n++
+				}
+				require.NoError(t, it.Close())
+				return n, sts.Result(0, 0, 0).Summary.TotalBytesProcessed
+			}
+
+			// Counting entries with a structured metadata filter doesn't decompress lines.
+			n, metadataOnlyBytes := sampleBytes(`count_over_time({job="fake"} | traceID="123" [1d])`)
+			require.Equal(t, 2, n)
+			n, lineBytes := sampleBytes(`count_over_time({job="fake"} |= "line" | traceID="123" [1d])`)
+			require.Equal(t, 2, n)
+			require.Less(t, metadataOnlyBytes, lineBytes)
+
+			n, bytesOverTimeBytes := sampleBytes(`bytes_over_time({job="fake"} | traceID="123" [1d])`)
+			require.Equal(t, 2, n)
+			require.Equal(t, lineBytes, bytesOverTimeBytes)
+		})
+	}
+}
+
+func TestMemChunk_ColumnarStructuredMetadataManyNames(t *testing.T) {
+	chk := NewMemChunk(ChunkFormatV5, EncSnappy, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, 4*1024, 0)
+
+	// Each entry only has a few of the names, so that most columns are sparse.
+	var entries []logproto.Entry
+	for i := 0; i < 1000; i++ {
+		e := logprotoEntryWithStructuredMetadata(int64(i), fmt.Sprintf("line %d", i), logproto.FromLabelsToLabelAdapters(labels.FromStrings(
+			fmt.Sprintf("name%d", i%7), fmt.Sprintf("%d", i),
+			fmt.Sprintf("other%d", i%13), "value",
+		)))
+		entries = append(entries, *e)
+		require.NoError(t, chk.Append(e))
+	}
+	require.NoError(t, chk.Close())
+	require.Greater(t, chk.BlockCount(), 1)
+
+	it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+	require.NoError(t, err)
+	var got []logproto.Entry
+	for it.Next() {
+		got = append(got, it.Entry())
+	}
+	require.NoError(t, it.Close())
+	require.Equal(t, entries, got)
+}
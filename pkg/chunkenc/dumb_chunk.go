@@ -127,6 +127,10 @@ func (c *dumbChunk) Rebound(_, _ time.Time, _ filter.Func) (Chunk, error) {
 	return nil, nil
 }
 
+func (c *dumbChunk) Rewrite(_, _ time.Time, _ filter.Func, _ filter.RewriteFunc) (Chunk, error) {
+	return nil, nil
+}
+
 type dumbChunkIterator struct {
 	direction logproto.Direction
 	i         int
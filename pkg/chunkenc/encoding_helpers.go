@@ -105,6 +105,19 @@ func (d *decbuf) be32() uint32 {
 	return x
 }
 
+func (d *decbuf) be64() uint64 {
+	if d.e != nil {
+		return 0
+	}
+	if len(d.b) < 8 {
+		d.e = ErrInvalidSize
+		return 0
+	}
+	x := binary.BigEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return x
+}
+
 func (d *decbuf) byte() byte {
 	if d.e != nil {
 		return 0
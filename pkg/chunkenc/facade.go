@@ -118,6 +118,17 @@ func (f Facade) Rebound(start, end model.Time, filter filter.Func) (chunk.Data,
 	}, nil
 }
 
+// Rewrite is like Rebound, but also rewrites the lines kept with rewrite.
+func (f Facade) Rewrite(start, end model.Time, filter filter.Func, rewrite filter.RewriteFunc) (chunk.Data, error) {
+	newChunk, err := f.c.Rewrite(start.Time(), end.Time(), filter, rewrite)
+	if err != nil {
+		return nil, err
+	}
+	return &Facade{
+		c: newChunk,
+	}, nil
+}
+
 // UncompressedSize is a helper function to hide the type assertion kludge when wanting the uncompressed size of the Cortex interface encoding.Chunk.
 func UncompressedSize(c chunk.Data) (int, bool) {
 	f, ok := c.(*Facade)
@@ -60,6 +60,7 @@ const (
 	EncLZ4_4M
 	EncFlate
 	EncZstd
+	EncZstdDict
 )
 
 var supportedEncoding = []Encoding{
@@ -72,6 +73,7 @@ var supportedEncoding = []Encoding{
 	EncLZ4_4M,
 	EncFlate,
 	EncZstd,
+	EncZstdDict,
 }
 
 func (e Encoding) String() string {
@@ -96,6 +98,8 @@ func (e Encoding) String() string {
 		return "flate"
 	case EncZstd:
 		return "zstd"
+	case EncZstdDict:
+		return "zstd-dict"
 	default:
 		return "unknown"
 	}
@@ -145,6 +149,7 @@ type Chunk interface {
 	Close() error
 	Encoding() Encoding
 	Rebound(start, end time.Time, filter filter.Func) (Chunk, error)
+	Rewrite(start, end time.Time, filter filter.Func, rewrite filter.RewriteFunc) (Chunk, error)
 }
 
 // Block is a chunk block.
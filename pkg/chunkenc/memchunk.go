@@ -32,6 +32,8 @@ const (
 	ChunkFormatV2
 	ChunkFormatV3
 	ChunkFormatV4
+	// ChunkFormatV5 stores the structured metadata of each block in separately compressed columns.
+	ChunkFormatV5
 
 	blocksPerChunk = 10
 	maxLineLength  = 1024 * 1024 * 1024
@@ -45,7 +47,7 @@ const (
 	chunkStructuredMetadataSectionIdx = 2
 )
 
-var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
+var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt, UnorderedWithStructuredMetadataColumnsHeadBlockFmt, BucketedHeadBlockFmt}
 
 type HeadBlockFmt byte
 
@@ -59,6 +61,10 @@ func (f HeadBlockFmt) String() string {
 		return "unordered"
 	case f == UnorderedWithStructuredMetadataHeadBlockFmt:
 		return "unordered with structured metadata"
+	case f == UnorderedWithStructuredMetadataColumnsHeadBlockFmt:
+		return "unordered with structured metadata columns"
+	case f == BucketedHeadBlockFmt:
+		return "bucketed"
 	default:
 		return fmt.Sprintf("unknown: %v", byte(f))
 	}
@@ -68,6 +74,8 @@ func (f HeadBlockFmt) NewBlock(symbolizer *symbolizer) HeadBlock {
 	switch {
 	case f < UnorderedHeadBlockFmt:
 		return &headBlock{}
+	case f == BucketedHeadBlockFmt:
+		return newBucketedHeadBlock(symbolizer)
 	default:
 		return newUnorderedHeadBlock(f, symbolizer)
 	}
@@ -82,6 +90,11 @@ const (
 	OrderedHeadBlockFmt
 	UnorderedHeadBlockFmt
 	UnorderedWithStructuredMetadataHeadBlockFmt
+	UnorderedWithStructuredMetadataColumnsHeadBlockFmt
+	// BucketedHeadBlockFmt is an alternative to UnorderedWithStructuredMetadataColumnsHeadBlockFmt
+	// for V5 chunks, which buckets the entries by fixed time windows instead of using a range tree,
+	// see headBuckets.
+	BucketedHeadBlockFmt
 )
 
 // ChunkHeadFormatFor returns corresponding head block format for the given `chunkfmt`.
@@ -94,8 +107,12 @@ func ChunkHeadFormatFor(chunkfmt byte) HeadBlockFmt {
 		return UnorderedHeadBlockFmt
 	}
 
-	// return the latest head format for all chunkformat >v3
-	return UnorderedWithStructuredMetadataHeadBlockFmt
+	if chunkfmt == ChunkFormatV4 {
+		return UnorderedWithStructuredMetadataHeadBlockFmt
+	}
+
+	// return the latest head format for all chunkformat >v4
+	return UnorderedWithStructuredMetadataColumnsHeadBlockFmt
 }
 
 var magicNumber = uint32(0x12EE56A)
@@ -135,6 +152,9 @@ type MemChunk struct {
 	encoding Encoding
 	headFmt  HeadBlockFmt
 
+	// id of the zstd dictionary to compress blocks with, for the EncZstdDict encoding.
+	zstdDictID uint32
+
 	// compressed size of chunk. Set when chunk is cut or while decoding chunk from storage.
 	compressedSize int
 }
@@ -366,6 +386,9 @@ func panicIfInvalidFormat(chunkFmt byte, head HeadBlockFmt) {
 		fmt.Println("received head fmt", head.String())
 		panic("only UnorderedWithStructuredMetadataHeadBlockFmt is supported for V4 chunks")
 	}
+	if chunkFmt == ChunkFormatV5 && head != UnorderedWithStructuredMetadataColumnsHeadBlockFmt && head != BucketedHeadBlockFmt {
+		panic("only UnorderedWithStructuredMetadataColumnsHeadBlockFmt and BucketedHeadBlockFmt are supported for V5 chunks")
+	}
 }
 
 // NewMemChunk returns a new in-mem chunk.
@@ -414,7 +437,7 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 	switch version {
 	case ChunkFormatV1:
 		bc.encoding = EncGZIP
-	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4:
+	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4, ChunkFormatV5:
 		// format v2+ has a byte for block encoding.
 		enc := Encoding(db.byte())
 		if db.err() != nil {
@@ -626,7 +649,7 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 			}
 		} else {
 			var err error
-			n, crcHash, err = c.symbolizer.SerializeTo(w, GetWriterPool(c.encoding))
+			n, crcHash, err = c.symbolizer.SerializeTo(w, c.writerPool())
 			if err != nil {
 				return offset, errors.Wrap(err, "write structured metadata")
 			}
@@ -753,6 +776,19 @@ func (c *MemChunk) Encoding() Encoding {
 	return c.encoding
 }
 
+// UseZstdDictionary sets the zstd dictionary to compress the blocks cut from now on with.
+// It only applies to the EncZstdDict encoding and the dictionary must be registered to ZstdDict.
+func (c *MemChunk) UseZstdDictionary(id uint32) {
+	c.zstdDictID = id
+}
+
+func (c *MemChunk) writerPool() WriterPool {
+	if c.encoding == EncZstdDict {
+		return ZstdDict.WriterPool(c.zstdDictID)
+	}
+	return GetWriterPool(c.encoding)
+}
+
 // Size implements Chunk.
 func (c *MemChunk) Size() int {
 	ne := 0
@@ -851,6 +887,9 @@ func (c *MemChunk) Append(entry *logproto.Entry) error {
 	}
 
 	if c.head.UncompressedSize() >= c.blockSize {
+		if hb, ok := c.head.(*unorderedHeadBlock); ok && hb.buckets != nil {
+			return c.cutClosedBuckets(hb)
+		}
 		return c.cut()
 	}
 
@@ -894,6 +933,9 @@ func (c *MemChunk) reorder() error {
 }
 
 func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
+	if desired == BucketedHeadBlockFmt && c.format < ChunkFormatV5 {
+		return fmt.Errorf("head block format %v is not supported for chunk format %v", desired, c.format)
+	}
 	if c.head != nil && c.head.Format() != desired {
 		newH, err := c.head.Convert(desired, c.symbolizer)
 		if err != nil {
@@ -906,29 +948,59 @@ func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
 	return nil
 }
 
+// HeadFormat returns the format of the head block of the chunk.
+func (c *MemChunk) HeadFormat() HeadBlockFmt {
+	return c.headFmt
+}
+
 // cut a new block and add it to finished blocks.
 func (c *MemChunk) cut() error {
 	if c.head.IsEmpty() {
 		return nil
 	}
 
-	b, err := c.head.Serialise(GetWriterPool(c.encoding))
+	if err := c.cutBlock(c.head); err != nil {
+		return err
+	}
+
+	c.head.Reset()
+	return nil
+}
+
+// cutClosedBuckets cuts a block from the windows of a bucketed head block but the most recent one,
+// which is still likely to receive entries, so that the blocks cut from the head rarely overlap.
+// The whole head block is cut when the closed windows are too small to make a block on their own.
+func (c *MemChunk) cutClosedBuckets(hb *unorderedHeadBlock) error {
+	closed := hb.cutClosedBuckets(c.blockSize / 2)
+	if closed == nil {
+		return c.cut()
+	}
+	if err := c.cutBlock(closed); err != nil {
+		return err
+	}
+	if hb.UncompressedSize() >= c.blockSize {
+		return c.cut()
+	}
+	return nil
+}
+
+// cutBlock serialises the given head block and adds it to finished blocks.
+func (c *MemChunk) cutBlock(head HeadBlock) error {
+	b, err := head.Serialise(c.writerPool())
 	if err != nil {
 		return err
 	}
 
-	mint, maxt := c.head.Bounds()
+	mint, maxt := head.Bounds()
 	c.blocks = append(c.blocks, block{
 		b:                b,
-		numEntries:       c.head.Entries(),
+		numEntries:       head.Entries(),
 		mint:             mint,
 		maxt:             maxt,
-		uncompressedSize: c.head.UncompressedSize(),
+		uncompressedSize: head.UncompressedSize(),
 	})
 
 	c.cutBlockSize += len(b)
-
-	c.head.Reset()
 	return nil
 }
 
@@ -1099,6 +1171,11 @@ func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 
 // Rebound builds a smaller chunk with logs having timestamp from start and end(both inclusive)
 func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, error) {
+	return c.Rewrite(start, end, filter, nil)
+}
+
+// Rewrite is like Rebound, but also replaces the lines kept with the ones returned by rewrite, when it is not nil.
+func (c *MemChunk) Rewrite(start, end time.Time, filter filter.Func, rewrite filter.RewriteFunc) (Chunk, error) {
 	// add a millisecond to end time because the Chunk.Iterator considers end time to be non-inclusive.
 	itr, err := c.Iterator(context.Background(), start, end.Add(time.Millisecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(labels.Labels{}))
 	if err != nil {
@@ -1117,11 +1194,18 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 		newChunk = NewMemChunk(c.format, c.Encoding(), c.headFmt, defaultBlockSize, c.CompressedSize())
 	}
 
+	newChunk.zstdDictID = c.zstdDictID
+
 	for itr.Next() {
 		entry := itr.Entry()
 		if filter != nil && filter(entry.Timestamp, entry.Line, logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)...) {
 			continue
 		}
+		if rewrite != nil {
+			if line, rewritten := rewrite(entry.Timestamp, entry.Line, logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)...); rewritten {
+				entry.Line = line
+			}
+		}
 		if err := newChunk.Append(&entry); err != nil {
 			return nil, err
 		}
@@ -1138,6 +1222,18 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 	return newChunk, nil
 }
 
+// NewMemChunkLike returns an empty chunk with the format, encoding and zstd dictionary of c, cut at
+// the given target size. Like Rebound, it uses the default block size if the one of c is not set.
+func NewMemChunkLike(c *MemChunk, targetSize int) *MemChunk {
+	blockSize := c.blockSize
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+	newChunk := NewMemChunk(c.format, c.Encoding(), c.headFmt, blockSize, targetSize)
+	newChunk.zstdDictID = c.zstdDictID
+	return newChunk
+}
+
 // encBlock is an internal wrapper for a block, mainly to avoid binding an encoding in a block itself.
 // This may seem roundabout, but the encoding is already a field on the parent MemChunk type. encBlock
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
@@ -1315,8 +1411,12 @@ type bufferedIterator struct {
 
 	reader     io.Reader
 	pool       ReaderPool
+	cache      BlockCache // when set, the decompressed block is read from and added to the cache.
 	symbolizer *symbolizer
 
+	columns   *columnsReader // the reader of ChunkFormatV5 blocks.
+	skipLines bool           // whether ChunkFormatV5 blocks can skip decompressing lines.
+
 	err error
 
 	readBuf      [20]byte // Enough bytes to store two varints.
@@ -1341,6 +1441,7 @@ func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte, format
 		origBytes:  b,
 		reader:     nil, // will be initialized later
 		pool:       pool,
+		cache:      blockCacheFromContext(ctx),
 		format:     format,
 		symbolizer: symbolizer,
 	}
@@ -1351,10 +1452,14 @@ func (si *bufferedIterator) Next() bool {
 		return false
 	}
 
+	if si.format >= ChunkFormatV5 {
+		return si.nextColumns()
+	}
+
 	if !si.closed && si.reader == nil {
 		// initialize reader now, hopefully reusing one of the previous readers
 		var err error
-		si.reader, err = si.pool.GetReader(bytes.NewBuffer(si.origBytes))
+		si.reader, err = si.newReader()
 		if err != nil {
 			si.err = err
 			return false
@@ -1373,6 +1478,53 @@ func (si *bufferedIterator) Next() bool {
 	return true
 }
 
+func (si *bufferedIterator) newReader() (io.Reader, error) {
+	if si.cache == nil {
+		return si.pool.GetReader(bytes.NewBuffer(si.origBytes))
+	}
+	b, err := decompressCached(si.cache, si.stats, si.pool, si.origBytes)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// nextColumns moves to the next entry of a ChunkFormatV5 block.
+func (si *bufferedIterator) nextColumns() bool {
+	if si.columns == nil {
+		var err error
+		si.columns, err = newColumnsReader(si.stats, si.pool, si.cache, si.origBytes, si.skipLines)
+		if err != nil {
+			si.err = err
+			si.Close()
+			return false
+		}
+	}
+
+	ts, line, syms, ok, err := si.columns.next(si.symbolsBuf[:0])
+	si.symbolsBuf = syms
+	if err != nil || !ok {
+		si.err = err
+		si.Close()
+		return false
+	}
+
+	si.stats.AddDecompressedLines(1)
+	si.currTs = ts
+	si.currLine = line
+	si.currStructuredMetadata = si.symbolizer.Lookup(syms)
+	return true
+}
+
+// lineHash returns the hash of the current line. ChunkFormatV5 blocks store it,
+// so that it is available even when lines are not decompressed.
+func (si *bufferedIterator) lineHash() uint64 {
+	if si.columns != nil {
+		return si.columns.hash
+	}
+	return xxhash.Sum64(si.currLine)
+}
+
 // moveNext moves the buffer to the next entry
 func (si *bufferedIterator) moveNext() (int64, []byte, labels.Labels, bool) {
 	var decompressedBytes int64
@@ -1572,10 +1724,16 @@ func (si *bufferedIterator) Close() error {
 
 func (si *bufferedIterator) close() {
 	if si.reader != nil {
-		si.pool.PutReader(si.reader)
+		if si.cache == nil {
+			si.pool.PutReader(si.reader)
+		}
 		si.reader = nil
 	}
 
+	if si.columns != nil {
+		si.columns.close()
+	}
+
 	if si.buf != nil {
 		BytesBufferPool.Put(si.buf)
 		si.buf = nil
@@ -1647,6 +1805,7 @@ func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, format by
 		extractor:        extractor,
 		stats:            stats.FromContext(ctx),
 	}
+	it.skipLines = !log.RequiresLine(extractor)
 	return it
 }
 
@@ -1669,7 +1828,7 @@ func (e *sampleBufferedIterator) Next() bool {
 		e.stats.AddPostFilterLines(1)
 		e.currLabels = labels
 		e.cur.Value = val
-		e.cur.Hash = xxhash.Sum64(e.currLine)
+		e.cur.Hash = e.lineHash()
 		e.cur.Timestamp = e.currTs
 		return true
 	}
@@ -40,6 +40,7 @@ var testEncoding = []Encoding{
 	EncSnappy,
 	EncFlate,
 	EncZstd,
+	EncZstdDict,
 }
 
 var (
@@ -73,6 +74,14 @@ var (
 			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
 			chunkFormat:  ChunkFormatV4,
 		},
+		{
+			headBlockFmt: UnorderedWithStructuredMetadataColumnsHeadBlockFmt,
+			chunkFormat:  ChunkFormatV5,
+		},
+		{
+			headBlockFmt: BucketedHeadBlockFmt,
+			chunkFormat:  ChunkFormatV5,
+		},
 	}
 )
 
@@ -1534,6 +1543,43 @@ func TestMemChunk_ReboundAndFilter_with_filter(t *testing.T) {
 	}
 }
 
+func TestMemChunk_Rewrite(t *testing.T) {
+	chkFrom := time.Unix(1, 0)
+	chkFromPlus5 := chkFrom.Add(5 * time.Second)
+	chkThrough := chkFrom.Add(10 * time.Second)
+
+	originalChunk := buildFilterableTestMemChunk(t, chkFrom, chkThrough, &chkFrom, &chkFromPlus5, true)
+	newChunk, err := originalChunk.Rewrite(chkFrom, chkThrough, func(ts time.Time, _ string, _ ...labels.Label) bool {
+		return ts.Equal(chkFrom)
+	}, func(_ time.Time, in string, _ ...labels.Label) (string, bool) {
+		if !strings.HasPrefix(in, "matching") {
+			return in, false
+		}
+		return "redacted", true
+	})
+	require.NoError(t, err)
+
+	newChunkItr, err := newChunk.Iterator(context.Background(), chkFrom, chkThrough.Add(time.Nanosecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(labels.Labels{}))
+	require.NoError(t, err)
+	var redacted, kept int
+	for newChunkItr.Next() {
+		entry := newChunkItr.Entry()
+		// lines are rewritten, but their timestamp and structured metadata are kept.
+		require.False(t, entry.Timestamp.Equal(chkFrom))
+		require.Len(t, entry.StructuredMetadata, 1)
+		if entry.Line == "redacted" {
+			require.Equal(t, push.LabelsAdapter{{Name: lblPing, Value: lblPong}}, entry.StructuredMetadata)
+			redacted++
+		} else {
+			require.False(t, strings.HasPrefix(entry.Line, "matching"))
+			kept++
+		}
+	}
+	require.NoError(t, newChunkItr.Close())
+	require.Equal(t, 4, redacted)
+	require.Equal(t, 5, kept)
+}
+
 func buildFilterableTestMemChunk(t *testing.T, from, through time.Time, matchingFrom, matchingTo *time.Time, withStructuredMetadata bool) *MemChunk {
 	chk := NewMemChunk(ChunkFormatV4, EncGZIP, DefaultTestHeadBlockFmt, defaultBlockSize, 0)
 	t.Logf("from   : %v", from.String())
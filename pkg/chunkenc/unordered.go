@@ -57,6 +57,8 @@ type unorderedHeadBlock struct {
 	// Inserts: O(log(n))
 	// Scans: (O(k+log(n))) where k=num_scanned_entries & n=total_entries
 	rt rangetree.RangeTree
+	// buckets replaces the range tree for the BucketedHeadBlockFmt.
+	buckets *headBuckets
 
 	symbolizer *symbolizer
 	lines      int   // number of entries
@@ -65,6 +67,9 @@ type unorderedHeadBlock struct {
 }
 
 func newUnorderedHeadBlock(headBlockFmt HeadBlockFmt, symbolizer *symbolizer) *unorderedHeadBlock {
+	if headBlockFmt == BucketedHeadBlockFmt {
+		return newBucketedHeadBlock(symbolizer)
+	}
 	return &unorderedHeadBlock{
 		format:     headBlockFmt,
 		symbolizer: symbolizer,
@@ -115,6 +120,12 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string, structuredMetadata l
 		// structuredMetadata must be ignored for the previous head block formats
 		structuredMetadata = nil
 	}
+	if hb.buckets != nil {
+		if hb.buckets.add(ts, line, structuredMetadata, hb.symbolizer) {
+			hb.appended(ts, line, structuredMetadata)
+		}
+		return nil
+	}
 	// This is an allocation hack. The rangetree lib does not
 	// support the ability to pass a "mutate" function during an insert
 	// and instead will displace any existing entry at the specified timestamp.
@@ -143,6 +154,12 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string, structuredMetadata l
 		e.entries = []nsEntry{{line, hb.symbolizer.Add(structuredMetadata)}}
 	}
 
+	hb.appended(ts, line, structuredMetadata)
+	return nil
+}
+
+// appended updates the metadata of the head block after an entry was appended.
+func (hb *unorderedHeadBlock) appended(ts int64, line string, structuredMetadata labels.Labels) {
 	// Update hb metdata
 	if hb.size == 0 || hb.mint > ts {
 		hb.mint = ts
@@ -152,11 +169,13 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string, structuredMetadata l
 		hb.maxt = ts
 	}
 
-	hb.size += len(line)
-	hb.size += len(structuredMetadata) * 2 * 4 // 4 bytes per label and value pair as structuredMetadataSymbols
+	hb.size += entrySize(line, structuredMetadata)
 	hb.lines++
+}
 
-	return nil
+// entrySize returns the uncompressed size of an entry in a head block.
+func entrySize(line string, structuredMetadata labels.Labels) int {
+	return len(line) + len(structuredMetadata)*2*4 // 4 bytes per label and value pair as structuredMetadataSymbols
 }
 
 func metaLabelsLen(metaLabels labels.Labels) int {
@@ -190,11 +209,6 @@ func (hb *unorderedHeadBlock) forEntries(
 		return
 	}
 
-	entries := hb.rt.Query(interval{
-		mint: mint,
-		maxt: maxt,
-	})
-
 	chunkStats := stats.FromContext(ctx)
 	process := func(es *nsEntries) {
 		chunkStats.AddHeadChunkLines(int64(len(es.entries)))
@@ -224,6 +238,35 @@ func (hb *unorderedHeadBlock) forEntries(
 		}
 	}
 
+	if hb.buckets != nil {
+		spans := hb.buckets.query(mint, maxt)
+		if direction == logproto.FORWARD {
+			for _, span := range spans {
+				for i := range span {
+					process(&span[i])
+					if err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			for i := len(spans) - 1; i >= 0; i-- {
+				for j := len(spans[i]) - 1; j >= 0; j-- {
+					process(&spans[i][j])
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	entries := hb.rt.Query(interval{
+		mint: mint,
+		maxt: maxt,
+	})
+
 	if direction == logproto.FORWARD {
 		for _, e := range entries {
 			process(e.(*nsEntries))
@@ -360,6 +403,10 @@ func (hb *unorderedHeadBlock) SampleIterator(
 // nolint:unused
 // serialise is used in creating an ordered, compressed block from an unorderedHeadBlock
 func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
+	if hb.format >= UnorderedWithStructuredMetadataColumnsHeadBlockFmt {
+		return hb.serialiseColumns(pool)
+	}
+
 	inBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
 	defer func() {
 		inBuf.Reset()
@@ -610,7 +657,7 @@ func HeadFromCheckpoint(b []byte, desiredIfNotUnordered HeadBlockFmt, symbolizer
 		return nil, errors.Wrap(db.err(), "verifying headblock header")
 	}
 	format := HeadBlockFmt(version)
-	if format > UnorderedWithStructuredMetadataHeadBlockFmt {
+	if format > BucketedHeadBlockFmt {
 		return nil, fmt.Errorf("unexpected head block version: %v", format)
 	}
 
@@ -0,0 +1,316 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// ZstdDictionaryFetcher fetches the zstd dictionaries unknown to a ZstdDictPool.
+type ZstdDictionaryFetcher interface {
+	FetchZstdDictionary(id uint32) ([]byte, error)
+}
+
+// ZstdDictPool is the compression pool of the EncZstdDict encoding. Blocks are compressed
+// with the dictionary of the tenant owning the chunk and the dictionary id is written in each
+// zstd frame, so that readers know which dictionary to decompress a block with. Blocks
+// compressed without dictionary are regular zstd frames.
+type ZstdDictPool struct {
+	ZstdPool
+
+	mtx     sync.RWMutex
+	dicts   map[uint32]*zstdDict
+	tenants map[string]uint32
+	fetcher ZstdDictionaryFetcher
+}
+
+type zstdDict struct {
+	readers sync.Pool
+	writers sync.Pool
+	id      uint32
+	dict    []byte
+	// plain compresses without dictionary when an encoder with the dictionary
+	// can't be created.
+	plain *ZstdPool
+}
+
+// zstdDictReader is a decoder with a dictionary, returned to the pool of its dictionary.
+type zstdDictReader struct {
+	*zstd.Decoder
+	dict *zstdDict
+}
+
+// zstdDictWriter is an encoder with a dictionary, returned to the pool of its dictionary.
+type zstdDictWriter struct {
+	*zstd.Encoder
+	dict *zstdDict
+}
+
+// ZstdDict is the compression pool of the EncZstdDict encoding.
+var ZstdDict = ZstdDictPool{}
+
+// SetFetcher sets the fetcher used to get the dictionaries of the blocks compressed with
+// a dictionary that isn't registered yet.
+func (pool *ZstdDictPool) SetFetcher(fetcher ZstdDictionaryFetcher) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+	pool.fetcher = fetcher
+}
+
+// Register makes a dictionary available for compression and decompression and returns its id.
+func (pool *ZstdDictPool) Register(dict []byte) (uint32, error) {
+	d, err := pool.register(dict)
+	if err != nil {
+		return 0, err
+	}
+	return d.id, nil
+}
+
+func (pool *ZstdDictPool) register(dict []byte) (*zstdDict, error) {
+	d, err := zstd.InspectDictionary(dict)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid zstd dictionary")
+	}
+	if d.ID() == 0 {
+		return nil, errors.New("invalid zstd dictionary: the id must not be 0")
+	}
+
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+	registered, ok := pool.dicts[d.ID()]
+	if !ok {
+		if pool.dicts == nil {
+			pool.dicts = map[uint32]*zstdDict{}
+		}
+		registered = &zstdDict{id: d.ID(), dict: dict, plain: &pool.ZstdPool}
+		pool.dicts[d.ID()] = registered
+	}
+	return registered, nil
+}
+
+// SetTenantDictionary sets the id of the dictionary to compress the chunks of a tenant with.
+// The dictionary must be registered. Once the dictionary of a tenant changes, the dictionaries
+// not used by any tenant are evicted: they are fetched again to read the chunks compressed
+// with them.
+func (pool *ZstdDictPool) SetTenantDictionary(tenant string, id uint32) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+	if pool.tenants == nil {
+		pool.tenants = map[string]uint32{}
+	}
+	prev, ok := pool.tenants[tenant]
+	pool.tenants[tenant] = id
+	if ok && prev != id {
+		pool.evictUnused()
+	}
+}
+
+// evictUnused removes the dictionaries which aren't the dictionary of any tenant.
+// The mutex must be held.
+func (pool *ZstdDictPool) evictUnused() {
+	used := make(map[uint32]struct{}, len(pool.tenants))
+	for _, id := range pool.tenants {
+		used[id] = struct{}{}
+	}
+	for id := range pool.dicts {
+		if _, ok := used[id]; !ok {
+			delete(pool.dicts, id)
+		}
+	}
+}
+
+// TenantDictionary returns the id of the dictionary to compress the chunks of a tenant with,
+// or 0 if the tenant doesn't have one.
+func (pool *ZstdDictPool) TenantDictionary(tenant string) uint32 {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+	return pool.tenants[tenant]
+}
+
+// WriterPool returns a pool of writers compressing with the dictionary id.
+// Writers of the pool compress without dictionary if the dictionary isn't registered.
+func (pool *ZstdDictPool) WriterPool(id uint32) WriterPool {
+	pool.mtx.RLock()
+	d, ok := pool.dicts[id]
+	pool.mtx.RUnlock()
+	if !ok {
+		return &pool.ZstdPool
+	}
+	return d
+}
+
+func (pool *ZstdDictPool) dictionary(id uint32) (*zstdDict, error) {
+	pool.mtx.RLock()
+	d, ok := pool.dicts[id]
+	fetcher := pool.fetcher
+	pool.mtx.RUnlock()
+	if ok {
+		return d, nil
+	}
+	if fetcher == nil {
+		return nil, fmt.Errorf("unknown zstd dictionary %d", id)
+	}
+
+	b, err := fetcher.FetchZstdDictionary(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching zstd dictionary %d", id)
+	}
+	// The dictionary may be evicted right after being registered, it remains
+	// usable to read the block though.
+	fetched, err := pool.register(b)
+	if err != nil {
+		return nil, err
+	}
+	if fetched.id != id {
+		return nil, fmt.Errorf("fetched zstd dictionary %d instead of %d", fetched.id, id)
+	}
+	return fetched, nil
+}
+
+// GetReader gets or creates a new CompressionReader and reset it to read from src.
+// The dictionary is selected from the frame header of src.
+func (pool *ZstdDictPool) GetReader(src io.Reader) (io.Reader, error) {
+	header := make([]byte, zstd.HeaderMaxSize)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	header = header[:n]
+	src = io.MultiReader(bytes.NewReader(header), src)
+
+	var h zstd.Header
+	if err := h.Decode(header); err != nil || h.DictionaryID == 0 {
+		// Let the decoder report invalid frames.
+		return pool.ZstdPool.GetReader(src)
+	}
+
+	d, err := pool.dictionary(h.DictionaryID)
+	if err != nil {
+		return nil, err
+	}
+	return d.GetReader(src)
+}
+
+// PutReader places back in the pool a CompressionReader
+func (pool *ZstdDictPool) PutReader(reader io.Reader) {
+	if r, ok := reader.(*zstdDictReader); ok {
+		r.dict.PutReader(r)
+		return
+	}
+	pool.ZstdPool.PutReader(reader)
+}
+
+// GetReader gets or creates a new CompressionReader and reset it to read from src
+func (d *zstdDict) GetReader(src io.Reader) (io.Reader, error) {
+	if r := d.readers.Get(); r != nil {
+		reader := r.(*zstdDictReader)
+		if err := reader.Reset(src); err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	reader, err := zstd.NewReader(src, zstd.WithDecoderDicts(d.dict))
+	if err != nil {
+		return nil, err
+	}
+	runtime.SetFinalizer(reader, (*zstd.Decoder).Close)
+	return &zstdDictReader{Decoder: reader, dict: d}, nil
+}
+
+// PutReader places back in the pool a CompressionReader
+func (d *zstdDict) PutReader(reader io.Reader) {
+	d.readers.Put(reader)
+}
+
+// GetWriter gets or creates a new CompressionWriter and reset it to write to dst
+func (d *zstdDict) GetWriter(dst io.Writer) io.WriteCloser {
+	if w := d.writers.Get(); w != nil {
+		writer := w.(*zstdDictWriter)
+		writer.Reset(dst)
+		return writer
+	}
+
+	w, err := zstd.NewWriter(dst, zstd.WithEncoderDict(d.dict))
+	if err != nil {
+		// The dictionary is validated when registered, so this is unexpected:
+		// the blocks are still readable when compressed without dictionary.
+		return d.plain.GetWriter(dst)
+	}
+	return &zstdDictWriter{Encoder: w, dict: d}
+}
+
+// PutWriter places back in the pool a CompressionWriter
+func (d *zstdDict) PutWriter(writer io.WriteCloser) {
+	if _, ok := writer.(*zstdDictWriter); !ok {
+		d.plain.PutWriter(writer)
+		return
+	}
+	d.writers.Put(writer)
+}
+
+// TrainZstdDictionary builds a zstd dictionary of at most size bytes from samples of
+// decompressed chunk blocks. The id must not be 0.
+func TrainZstdDictionary(samples [][]byte, id uint32, size int) (dict []byte, err error) {
+	if id == 0 {
+		return nil, errors.New("the dictionary id must not be 0")
+	}
+	// zstd.BuildDict panics when the samples are too small to build the entropy tables.
+	defer func() {
+		if r := recover(); r != nil {
+			dict, err = nil, fmt.Errorf("failed to build the zstd dictionary: %v", r)
+		}
+	}()
+
+	// The dictionary content is made of the last samples, which are the most likely
+	// to be referenced by the encoder.
+	start, total := len(samples), 0
+	for start > 0 && total < size {
+		start--
+		total += len(samples[start])
+	}
+	history := make([]byte, 0, total)
+	for _, s := range samples[start:] {
+		history = append(history, s...)
+	}
+	if len(history) > size {
+		history = history[len(history)-size:]
+	}
+
+	return zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       id,
+		Contents: samples,
+		History:  history,
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstd.SpeedDefault,
+	})
+}
+
+// ZstdDictionarySamples returns the lines of each block of a chunk, to be used as samples
+// by TrainZstdDictionary.
+func ZstdDictionarySamples(ctx context.Context, c Chunk) ([][]byte, error) {
+	blocks := c.Blocks(time.Unix(0, 0), time.Unix(0, math.MaxInt64))
+	samples := make([][]byte, 0, len(blocks))
+	for _, b := range blocks {
+		it := b.Iterator(ctx, log.NewNoopPipeline().ForStream(labels.EmptyLabels()))
+		var sample []byte
+		for it.Next() {
+			sample = append(sample, it.Entry().Line...)
+		}
+		if err := it.Close(); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
@@ -0,0 +1,221 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc/testdata"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type zstdDictionaryFetcherFunc func(id uint32) ([]byte, error)
+
+func (f zstdDictionaryFetcherFunc) FetchZstdDictionary(id uint32) ([]byte, error) {
+	return f(id)
+}
+
+// trainTestDictionary trains a dictionary on the first half of the test logs.
+func trainTestDictionary(t testing.TB, id uint32, size int) []byte {
+	t.Helper()
+	var samples [][]byte
+	logs := testdata.LogsBytes[:len(testdata.LogsBytes)/2]
+	for i := 0; i+10 <= len(logs); i += 10 {
+		samples = append(samples, bytes.Join(logs[i:i+10], nil))
+	}
+	dict, err := TrainZstdDictionary(samples, id, size)
+	require.NoError(t, err)
+	return dict
+}
+
+func TestTrainZstdDictionary_TooFewSamples(t *testing.T) {
+	_, err := TrainZstdDictionary([][]byte{[]byte("foo bar"), []byte("foo baz")}, 42, 16*1024)
+	require.Error(t, err)
+}
+
+func TestZstdDictPool(t *testing.T) {
+	dict := trainTestDictionary(t, 42, 16*1024)
+	data := testdata.LogsBytes[len(testdata.LogsBytes)-2]
+
+	writer := &ZstdDictPool{}
+	id, err := writer.Register(dict)
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), id)
+
+	compress := func(pool WriterPool) []byte {
+		var buf bytes.Buffer
+		w := pool.GetWriter(&buf)
+		_, err := w.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		pool.PutWriter(w)
+		return buf.Bytes()
+	}
+	withDict, withoutDict := compress(writer.WriterPool(id)), compress(writer.WriterPool(0))
+	require.Less(t, len(withDict), len(withoutDict))
+
+	var fetched []uint32
+	reader := &ZstdDictPool{}
+	reader.SetFetcher(zstdDictionaryFetcherFunc(func(id uint32) ([]byte, error) {
+		fetched = append(fetched, id)
+		if id != 42 {
+			return nil, errors.New("not found")
+		}
+		return dict, nil
+	}))
+	decompress := func(b []byte) ([]byte, error) {
+		r, err := reader.GetReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.PutReader(r)
+		return io.ReadAll(r)
+	}
+
+	// The dictionary is fetched once.
+	for i := 0; i < 2; i++ {
+		got, err := decompress(withDict)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	}
+	require.Equal(t, []uint32{42}, fetched)
+
+	got, err := decompress(withoutDict)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+	require.Equal(t, []uint32{42}, fetched)
+
+	// Blocks compressed with an unknown dictionary can't be read.
+	otherDict := trainTestDictionary(t, 43, 16*1024)
+	other := &ZstdDictPool{}
+	_, err = other.Register(otherDict)
+	require.NoError(t, err)
+	_, err = decompress(compress(other.WriterPool(43)))
+	require.Error(t, err)
+}
+
+func TestZstdDictPool_EvictsSupersededDictionaries(t *testing.T) {
+	pool := &ZstdDictPool{}
+	for _, id := range []uint32{42, 43, 44} {
+		_, err := pool.Register(trainTestDictionary(t, id, 4*1024))
+		require.NoError(t, err)
+	}
+	pool.SetTenantDictionary("a", 42)
+	pool.SetTenantDictionary("b", 43)
+	require.Len(t, pool.dicts, 3)
+
+	// The dictionaries not used by any tenant anymore are evicted.
+	pool.SetTenantDictionary("a", 44)
+	require.Len(t, pool.dicts, 2)
+	require.Contains(t, pool.dicts, uint32(43))
+	require.Contains(t, pool.dicts, uint32(44))
+	require.Equal(t, &pool.ZstdPool, pool.WriterPool(42))
+}
+
+func TestZstdDict_GetWriterFallsBackToPlainEncoder(t *testing.T) {
+	d := &zstdDict{id: 42, dict: []byte("not a dictionary"), plain: &ZstdPool{}}
+	data := testdata.LogsBytes[0]
+
+	var buf bytes.Buffer
+	w := d.GetWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	d.PutWriter(w)
+
+	pool := &ZstdDictPool{}
+	r, err := pool.GetReader(&buf)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
+
+func TestMemChunk_ZstdDictionary(t *testing.T) {
+	id, err := ZstdDict.Register(trainTestDictionary(t, 1000, 16*1024))
+	require.NoError(t, err)
+
+	for _, f := range allPossibleFormats {
+		f := f
+		t.Run(fmt.Sprintf("%v-%v", f.chunkFormat, f.headBlockFmt), func(t *testing.T) {
+			withDict := NewMemChunk(f.chunkFormat, EncZstdDict, f.headBlockFmt, 4*1024, 0)
+			withDict.UseZstdDictionary(id)
+			withoutDict := NewMemChunk(f.chunkFormat, EncZstdDict, f.headBlockFmt, 4*1024, 0)
+
+			for i := 0; i < 1000; i++ {
+				e := &logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: testdata.LogString(int64(i))}
+				require.NoError(t, withDict.Append(e))
+				require.NoError(t, withoutDict.Append(e))
+			}
+			require.NoError(t, withDict.Close())
+			require.NoError(t, withoutDict.Close())
+			require.Less(t, withDict.CompressedSize(), withoutDict.CompressedSize())
+
+			b, err := withDict.Bytes()
+			require.NoError(t, err)
+			chk, err := NewByteChunk(b, 4*1024, 0)
+			require.NoError(t, err)
+
+			it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+			require.NoError(t, err)
+			var i int64
+			for it.Next() {
+				require.Equal(t, testdata.LogString(i), it.Entry().Line)
+				i++
+			}
+			require.NoError(t, it.Close())
+			require.Equal(t, int64(1000), i)
+		})
+	}
+}
+
+func TestZstdDictionarySamples(t *testing.T) {
+	chk := NewMemChunk(ChunkFormatV4, EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	var lines []byte
+	for i := 0; i < 100; i++ {
+		e := &logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: testdata.LogString(int64(i))}
+		require.NoError(t, chk.Append(e))
+		lines = append(lines, e.Line...)
+	}
+	require.NoError(t, chk.Close())
+
+	samples, err := ZstdDictionarySamples(context.Background(), chk)
+	require.NoError(t, err)
+	require.Len(t, samples, chk.BlockCount())
+	require.Equal(t, lines, bytes.Join(samples, nil))
+}
+
+// BenchmarkZstdDictionaryRatio compares the compression ratio of chunks of the test logs
+// compressed with and without a dictionary trained on other test logs.
+func BenchmarkZstdDictionaryRatio(b *testing.B) {
+	id, err := ZstdDict.Register(trainTestDictionary(b, 1001, 64*1024))
+	require.NoError(b, err)
+
+	logs := testdata.Logs[len(testdata.Logs)/2:]
+	for _, blockSize := range []int{4 * 1024, 16 * 1024, 64 * 1024, 256 * 1024} {
+		for _, enc := range []Encoding{EncSnappy, EncZstd, EncZstdDict} {
+			b.Run(fmt.Sprintf("%s-%dk", enc, blockSize/1024), func(b *testing.B) {
+				var uncompressed, compressed int
+				for n := 0; n < b.N; n++ {
+					chk := NewMemChunk(ChunkFormatV4, enc, UnorderedWithStructuredMetadataHeadBlockFmt, blockSize, 0)
+					chk.UseZstdDictionary(id)
+					uncompressed = 0
+					for i, l := range logs {
+						require.NoError(b, chk.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: l}))
+						uncompressed += len(l)
+					}
+					require.NoError(b, chk.Close())
+					compressed = chk.CompressedSize()
+				}
+				b.ReportMetric(float64(uncompressed)/float64(compressed), "ratio")
+			})
+		}
+	}
+}
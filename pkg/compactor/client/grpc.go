@@ -81,6 +81,12 @@ func (s *compactorGRPCClient) GetAllDeleteRequestsForUser(ctx context.Context, u
 			Status:    deletion.DeleteRequestStatus(dr.Status),
 			CreatedAt: model.Time(dr.CreatedAt),
 		}
+		if dr.Redaction != nil {
+			deleteRequests[i].Redaction = &deletion.Redaction{
+				Regex:       dr.Redaction.Regex,
+				Replacement: dr.Redaction.Replacement,
+			}
+		}
 	}
 
 	return deleteRequests, nil
@@ -14,6 +14,7 @@ import (
 	math "math"
 	math_bits "math/bits"
 	reflect "reflect"
+	strconv "strconv"
 	strings "strings"
 )
 
@@ -28,6 +29,30 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type JobStatus int32
+
+const (
+	JOB_RUNNING   JobStatus = 0
+	JOB_SUCCEEDED JobStatus = 1
+	JOB_FAILED    JobStatus = 2
+)
+
+var JobStatus_name = map[int32]string{
+	0: "JOB_RUNNING",
+	1: "JOB_SUCCEEDED",
+	2: "JOB_FAILED",
+}
+
+var JobStatus_value = map[string]int32{
+	"JOB_RUNNING":   0,
+	"JOB_SUCCEEDED": 1,
+	"JOB_FAILED":    2,
+}
+
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{0}
+}
+
 type GetDeleteRequestsRequest struct {
 }
 
@@ -107,12 +132,14 @@ func (m *GetDeleteRequestsResponse) GetDeleteRequests() []*DeleteRequest {
 }
 
 type DeleteRequest struct {
-	RequestID string `protobuf:"bytes,1,opt,name=requestID,proto3" json:"requestID,omitempty"`
-	StartTime int64  `protobuf:"varint,2,opt,name=startTime,proto3" json:"startTime,omitempty"`
-	EndTime   int64  `protobuf:"varint,3,opt,name=endTime,proto3" json:"endTime,omitempty"`
-	Query     string `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
-	Status    string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
-	CreatedAt int64  `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	RequestID string     `protobuf:"bytes,1,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	StartTime int64      `protobuf:"varint,2,opt,name=startTime,proto3" json:"startTime,omitempty"`
+	EndTime   int64      `protobuf:"varint,3,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	Query     string     `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	Status    string     `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt int64      `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	Redaction *Redaction `protobuf:"bytes,7,opt,name=redaction,proto3" json:"redaction,omitempty"`
+	UserID    string     `protobuf:"bytes,8,opt,name=userID,proto3" json:"userID,omitempty"`
 }
 
 func (m *DeleteRequest) Reset()      { *m = DeleteRequest{} }
@@ -189,13 +216,78 @@ func (m *DeleteRequest) GetCreatedAt() int64 {
 	return 0
 }
 
+func (m *DeleteRequest) GetRedaction() *Redaction {
+	if m != nil {
+		return m.Redaction
+	}
+	return nil
+}
+
+func (m *DeleteRequest) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+type Redaction struct {
+	Regex       string `protobuf:"bytes,1,opt,name=regex,proto3" json:"regex,omitempty"`
+	Replacement string `protobuf:"bytes,2,opt,name=replacement,proto3" json:"replacement,omitempty"`
+}
+
+func (m *Redaction) Reset()      { *m = Redaction{} }
+func (*Redaction) ProtoMessage() {}
+func (*Redaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{3}
+}
+func (m *Redaction) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Redaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Redaction.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Redaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Redaction.Merge(m, src)
+}
+func (m *Redaction) XXX_Size() int {
+	return m.Size()
+}
+func (m *Redaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Redaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Redaction proto.InternalMessageInfo
+
+func (m *Redaction) GetRegex() string {
+	if m != nil {
+		return m.Regex
+	}
+	return ""
+}
+
+func (m *Redaction) GetReplacement() string {
+	if m != nil {
+		return m.Replacement
+	}
+	return ""
+}
+
 type GetCacheGenNumbersRequest struct {
 }
 
 func (m *GetCacheGenNumbersRequest) Reset()      { *m = GetCacheGenNumbersRequest{} }
 func (*GetCacheGenNumbersRequest) ProtoMessage() {}
 func (*GetCacheGenNumbersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_24a5f361c0f660df, []int{3}
+	return fileDescriptor_24a5f361c0f660df, []int{4}
 }
 func (m *GetCacheGenNumbersRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -231,7 +323,7 @@ type GetCacheGenNumbersResponse struct {
 func (m *GetCacheGenNumbersResponse) Reset()      { *m = GetCacheGenNumbersResponse{} }
 func (*GetCacheGenNumbersResponse) ProtoMessage() {}
 func (*GetCacheGenNumbersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_24a5f361c0f660df, []int{4}
+	return fileDescriptor_24a5f361c0f660df, []int{5}
 }
 func (m *GetCacheGenNumbersResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -267,12 +359,294 @@ func (m *GetCacheGenNumbersResponse) GetResultsCacheGen() string {
 	return ""
 }
 
+type DequeueRequest struct {
+	Worker string `protobuf:"bytes,1,opt,name=worker,proto3" json:"worker,omitempty"`
+}
+
+func (m *DequeueRequest) Reset()      { *m = DequeueRequest{} }
+func (*DequeueRequest) ProtoMessage() {}
+func (*DequeueRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{6}
+}
+func (m *DequeueRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DequeueRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DequeueRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DequeueRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DequeueRequest.Merge(m, src)
+}
+func (m *DequeueRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DequeueRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DequeueRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DequeueRequest proto.InternalMessageInfo
+
+func (m *DequeueRequest) GetWorker() string {
+	if m != nil {
+		return m.Worker
+	}
+	return ""
+}
+
+type DequeueResponse struct {
+	// job is not set when there is no job to run.
+	Job *Job `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+}
+
+func (m *DequeueResponse) Reset()      { *m = DequeueResponse{} }
+func (*DequeueResponse) ProtoMessage() {}
+func (*DequeueResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{7}
+}
+func (m *DequeueResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DequeueResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DequeueResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DequeueResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DequeueResponse.Merge(m, src)
+}
+func (m *DequeueResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *DequeueResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DequeueResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DequeueResponse proto.InternalMessageInfo
+
+func (m *DequeueResponse) GetJob() *Job {
+	if m != nil {
+		return m.Job
+	}
+	return nil
+}
+
+type Job struct {
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Table string `protobuf:"bytes,2,opt,name=table,proto3" json:"table,omitempty"`
+	// userID of the index set to compact, or empty to compact all the index sets of the table.
+	UserID         string `protobuf:"bytes,3,opt,name=userID,proto3" json:"userID,omitempty"`
+	ApplyRetention bool   `protobuf:"varint,4,opt,name=applyRetention,proto3" json:"applyRetention,omitempty"`
+	// deleteRequests to process when applying retention.
+	DeleteRequests []*DeleteRequest `protobuf:"bytes,5,rep,name=deleteRequests,proto3" json:"deleteRequests,omitempty"`
+}
+
+func (m *Job) Reset()      { *m = Job{} }
+func (*Job) ProtoMessage() {}
+func (*Job) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{8}
+}
+func (m *Job) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Job) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Job.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Job) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Job.Merge(m, src)
+}
+func (m *Job) XXX_Size() int {
+	return m.Size()
+}
+func (m *Job) XXX_DiscardUnknown() {
+	xxx_messageInfo_Job.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Job proto.InternalMessageInfo
+
+func (m *Job) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Job) GetTable() string {
+	if m != nil {
+		return m.Table
+	}
+	return ""
+}
+
+func (m *Job) GetUserID() string {
+	if m != nil {
+		return m.UserID
+	}
+	return ""
+}
+
+func (m *Job) GetApplyRetention() bool {
+	if m != nil {
+		return m.ApplyRetention
+	}
+	return false
+}
+
+func (m *Job) GetDeleteRequests() []*DeleteRequest {
+	if m != nil {
+		return m.DeleteRequests
+	}
+	return nil
+}
+
+type ReportJobStatusRequest struct {
+	Worker string    `protobuf:"bytes,1,opt,name=worker,proto3" json:"worker,omitempty"`
+	JobID  string    `protobuf:"bytes,2,opt,name=jobID,proto3" json:"jobID,omitempty"`
+	Status JobStatus `protobuf:"varint,3,opt,name=status,proto3,enum=grpc.JobStatus" json:"status,omitempty"`
+	Error  string    `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *ReportJobStatusRequest) Reset()      { *m = ReportJobStatusRequest{} }
+func (*ReportJobStatusRequest) ProtoMessage() {}
+func (*ReportJobStatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{9}
+}
+func (m *ReportJobStatusRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReportJobStatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReportJobStatusRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReportJobStatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportJobStatusRequest.Merge(m, src)
+}
+func (m *ReportJobStatusRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReportJobStatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportJobStatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportJobStatusRequest proto.InternalMessageInfo
+
+func (m *ReportJobStatusRequest) GetWorker() string {
+	if m != nil {
+		return m.Worker
+	}
+	return ""
+}
+
+func (m *ReportJobStatusRequest) GetJobID() string {
+	if m != nil {
+		return m.JobID
+	}
+	return ""
+}
+
+func (m *ReportJobStatusRequest) GetStatus() JobStatus {
+	if m != nil {
+		return m.Status
+	}
+	return JOB_RUNNING
+}
+
+func (m *ReportJobStatusRequest) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ReportJobStatusResponse struct {
+	// canceled is set when the job is no longer leased to the worker, which should stop running it.
+	Canceled bool `protobuf:"varint,1,opt,name=canceled,proto3" json:"canceled,omitempty"`
+}
+
+func (m *ReportJobStatusResponse) Reset()      { *m = ReportJobStatusResponse{} }
+func (*ReportJobStatusResponse) ProtoMessage() {}
+func (*ReportJobStatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_24a5f361c0f660df, []int{10}
+}
+func (m *ReportJobStatusResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReportJobStatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReportJobStatusResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReportJobStatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportJobStatusResponse.Merge(m, src)
+}
+func (m *ReportJobStatusResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReportJobStatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportJobStatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportJobStatusResponse proto.InternalMessageInfo
+
+func (m *ReportJobStatusResponse) GetCanceled() bool {
+	if m != nil {
+		return m.Canceled
+	}
+	return false
+}
+
 func init() {
+	proto.RegisterEnum("grpc.JobStatus", JobStatus_name, JobStatus_value)
 	proto.RegisterType((*GetDeleteRequestsRequest)(nil), "grpc.GetDeleteRequestsRequest")
 	proto.RegisterType((*GetDeleteRequestsResponse)(nil), "grpc.GetDeleteRequestsResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "grpc.DeleteRequest")
+	proto.RegisterType((*Redaction)(nil), "grpc.Redaction")
 	proto.RegisterType((*GetCacheGenNumbersRequest)(nil), "grpc.GetCacheGenNumbersRequest")
 	proto.RegisterType((*GetCacheGenNumbersResponse)(nil), "grpc.GetCacheGenNumbersResponse")
+	proto.RegisterType((*DequeueRequest)(nil), "grpc.DequeueRequest")
+	proto.RegisterType((*DequeueResponse)(nil), "grpc.DequeueResponse")
+	proto.RegisterType((*Job)(nil), "grpc.Job")
+	proto.RegisterType((*ReportJobStatusRequest)(nil), "grpc.ReportJobStatusRequest")
+	proto.RegisterType((*ReportJobStatusResponse)(nil), "grpc.ReportJobStatusResponse")
 }
 
 func init() {
@@ -280,33 +654,61 @@ func init() {
 }
 
 var fileDescriptor_24a5f361c0f660df = []byte{
-	// 373 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0xbb, 0x4e, 0xe3, 0x40,
-	0x14, 0xf5, 0x6c, 0x1e, 0xab, 0xcc, 0x6a, 0x77, 0xc5, 0x80, 0xd0, 0x60, 0xd0, 0xc5, 0xb2, 0x28,
-	0x5c, 0x25, 0x52, 0xa0, 0xa3, 0x82, 0x44, 0x44, 0x34, 0x14, 0x56, 0x24, 0x68, 0x1d, 0xe7, 0x2a,
-	0x44, 0x24, 0xb6, 0x33, 0x33, 0x2e, 0xe8, 0xf8, 0x04, 0x3e, 0x83, 0x96, 0x96, 0x2f, 0xa0, 0x4c,
-	0x99, 0x92, 0x38, 0x0d, 0x65, 0x3e, 0x01, 0x65, 0xec, 0x24, 0xca, 0xab, 0xb1, 0xe7, 0x9e, 0x73,
-	0xe6, 0x3e, 0xce, 0x1d, 0x7a, 0x16, 0x3d, 0x75, 0x2a, 0x7e, 0xd8, 0x8f, 0x3c, 0x5f, 0x85, 0xa2,
-	0xe2, 0xf7, 0xba, 0x18, 0xa8, 0x4a, 0x47, 0x44, 0xbe, 0xfe, 0x94, 0x23, 0x11, 0xaa, 0x90, 0xe5,
-	0x67, 0x67, 0xdb, 0xa4, 0xbc, 0x81, 0xaa, 0x8e, 0x3d, 0x54, 0xe8, 0xe2, 0x20, 0x46, 0xa9, 0x64,
-	0xf6, 0xb7, 0x1f, 0xe8, 0xd1, 0x16, 0x4e, 0x46, 0x61, 0x20, 0x91, 0x5d, 0xd2, 0x7f, 0xed, 0x15,
-	0x86, 0x13, 0x2b, 0xe7, 0xfc, 0xa9, 0xee, 0x97, 0x75, 0x8d, 0x95, 0x5b, 0xee, 0x9a, 0xd4, 0x7e,
-	0x27, 0xf4, 0xef, 0x8a, 0x82, 0x9d, 0xd0, 0x92, 0x48, 0x8f, 0xb7, 0x75, 0x4e, 0x2c, 0xe2, 0x94,
-	0xdc, 0x25, 0x30, 0x63, 0xa5, 0xf2, 0x84, 0x6a, 0x76, 0xfb, 0xc8, 0x7f, 0x59, 0xc4, 0xc9, 0xb9,
-	0x4b, 0x80, 0x71, 0xfa, 0x1b, 0x83, 0xb6, 0xe6, 0x72, 0x9a, 0x9b, 0x87, 0xec, 0x80, 0x16, 0x06,
-	0x31, 0x8a, 0x67, 0x9e, 0xd7, 0x19, 0xd3, 0x80, 0x1d, 0xd2, 0xa2, 0x54, 0x9e, 0x8a, 0x25, 0x2f,
-	0x68, 0x38, 0x8b, 0x66, 0x55, 0x7c, 0x81, 0x9e, 0xc2, 0xf6, 0x95, 0xe2, 0xc5, 0xb4, 0xca, 0x02,
-	0xb0, 0x8f, 0xb5, 0x1b, 0x35, 0xcf, 0x7f, 0xc4, 0x06, 0x06, 0x77, 0x71, 0xbf, 0x85, 0x62, 0x61,
-	0xd5, 0x0d, 0x35, 0xb7, 0x91, 0x99, 0x57, 0x0e, 0xfd, 0x2f, 0x50, 0xc6, 0x3d, 0x25, 0xe7, 0x8a,
-	0x6c, 0xc4, 0x75, 0xb8, 0xfa, 0x41, 0x68, 0xa9, 0x36, 0xdf, 0x1c, 0x6b, 0xd2, 0xbd, 0x8d, 0x05,
-	0x30, 0x48, 0x0d, 0xde, 0xb5, 0x35, 0xf3, 0x74, 0x27, 0x9f, 0x75, 0x73, 0x4f, 0xd9, 0x66, 0xaf,
-	0x6c, 0x79, 0x6d, 0xfb, 0x88, 0xa6, 0xb5, 0x5b, 0x90, 0x26, 0xbe, 0xbe, 0x18, 0x8e, 0xc1, 0x18,
-	0x8d, 0xc1, 0x98, 0x8e, 0x81, 0xbc, 0x24, 0x40, 0xde, 0x12, 0x20, 0x9f, 0x09, 0x90, 0x61, 0x02,
-	0xe4, 0x2b, 0x01, 0xf2, 0x9d, 0x80, 0x31, 0x4d, 0x80, 0xbc, 0x4e, 0xc0, 0x18, 0x4e, 0xc0, 0x18,
-	0x4d, 0xc0, 0x68, 0x15, 0xf5, 0x73, 0x3c, 0xff, 0x09, 0x00, 0x00, 0xff, 0xff, 0x80, 0xfa, 0x3c,
-	0xc9, 0xb6, 0x02, 0x00, 0x00,
+	// 707 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x4e, 0xdb, 0x4a,
+	0x14, 0xce, 0x24, 0x10, 0x92, 0x13, 0x91, 0xc0, 0x5c, 0x2e, 0xd7, 0x37, 0x50, 0x37, 0xb2, 0xaa,
+	0x36, 0xaa, 0x54, 0x90, 0xd2, 0x9f, 0x4d, 0x17, 0x15, 0xc4, 0x01, 0x25, 0xaa, 0x52, 0xd5, 0x80,
+	0xda, 0x5d, 0x65, 0x3b, 0x47, 0x34, 0x90, 0x78, 0xcc, 0x78, 0xa2, 0x96, 0x5d, 0x37, 0xdd, 0x57,
+	0x7d, 0x0a, 0x9e, 0xa1, 0x4f, 0xd0, 0x25, 0x4b, 0x96, 0x25, 0x6c, 0xba, 0x64, 0xd9, 0x65, 0x35,
+	0xf6, 0xd8, 0x09, 0x21, 0x51, 0xbb, 0x49, 0xe6, 0xfb, 0xce, 0xdf, 0x9c, 0x73, 0x3e, 0x0f, 0xdc,
+	0xf3, 0x8f, 0x0f, 0x37, 0x5d, 0xd6, 0xf7, 0x6d, 0x57, 0x30, 0xbe, 0xe9, 0xf6, 0xba, 0xe8, 0x89,
+	0xcd, 0x43, 0xee, 0xbb, 0xe1, 0xcf, 0x86, 0xcf, 0x99, 0x60, 0x74, 0x4e, 0x9e, 0x8d, 0x32, 0x68,
+	0xbb, 0x28, 0x4c, 0xec, 0xa1, 0x40, 0x0b, 0x4f, 0x06, 0x18, 0x88, 0x40, 0xfd, 0x1b, 0x6f, 0xe1,
+	0xff, 0x29, 0xb6, 0xc0, 0x67, 0x5e, 0x80, 0xf4, 0x39, 0x14, 0x3b, 0x37, 0x2c, 0x1a, 0xa9, 0x64,
+	0xaa, 0x85, 0xda, 0x3f, 0x1b, 0x61, 0x8d, 0x1b, 0x51, 0xd6, 0x84, 0xab, 0xf1, 0x8b, 0xc0, 0xe2,
+	0x0d, 0x0f, 0xba, 0x0e, 0x79, 0x1e, 0x1d, 0x9b, 0xa6, 0x46, 0x2a, 0xa4, 0x9a, 0xb7, 0x46, 0x84,
+	0xb4, 0x06, 0xc2, 0xe6, 0x62, 0xbf, 0xdb, 0x47, 0x2d, 0x5d, 0x21, 0xd5, 0x8c, 0x35, 0x22, 0xa8,
+	0x06, 0x0b, 0xe8, 0x75, 0x42, 0x5b, 0x26, 0xb4, 0xc5, 0x90, 0xae, 0xc0, 0xfc, 0xc9, 0x00, 0xf9,
+	0xa9, 0x36, 0x17, 0x66, 0x8c, 0x00, 0x5d, 0x85, 0x6c, 0x20, 0x6c, 0x31, 0x08, 0xb4, 0xf9, 0x90,
+	0x56, 0x48, 0x56, 0x71, 0x39, 0xda, 0x02, 0x3b, 0x5b, 0x42, 0xcb, 0x46, 0x55, 0x12, 0x82, 0x3e,
+	0x92, 0x37, 0xec, 0xd8, 0xae, 0xe8, 0x32, 0x4f, 0x5b, 0xa8, 0x90, 0x6a, 0xa1, 0x56, 0x8a, 0x7a,
+	0xb5, 0x62, 0xda, 0x1a, 0x79, 0xc8, 0x22, 0x83, 0x00, 0x79, 0xd3, 0xd4, 0x72, 0x51, 0x91, 0x08,
+	0x19, 0x75, 0xc8, 0x27, 0xfe, 0xf2, 0x7e, 0x1c, 0x0f, 0xf1, 0xa3, 0xea, 0x38, 0x02, 0xb4, 0x02,
+	0x05, 0x8e, 0x7e, 0xcf, 0x76, 0xb1, 0x8f, 0x9e, 0x08, 0xfb, 0xcd, 0x5b, 0xe3, 0x94, 0xb1, 0x16,
+	0x6e, 0xa6, 0x6e, 0xbb, 0xef, 0x71, 0x17, 0xbd, 0xf6, 0xa0, 0xef, 0x20, 0x4f, 0xd6, 0xb6, 0x03,
+	0xe5, 0x69, 0x46, 0xb5, 0xb7, 0x2a, 0x94, 0x38, 0x06, 0x83, 0x9e, 0x08, 0x62, 0x0f, 0x55, 0x7c,
+	0x92, 0x36, 0xaa, 0x50, 0x34, 0x65, 0xca, 0x41, 0xb2, 0xa4, 0x55, 0xc8, 0x7e, 0x60, 0xfc, 0x18,
+	0xb9, 0x0a, 0x51, 0xc8, 0xd8, 0x80, 0x52, 0xe2, 0xa9, 0xca, 0xac, 0x41, 0xe6, 0x88, 0x39, 0xa1,
+	0x5f, 0xa1, 0x96, 0x8f, 0xe6, 0xd4, 0x62, 0x8e, 0x25, 0x59, 0xe3, 0x8c, 0x40, 0xa6, 0xc5, 0x1c,
+	0x5a, 0x84, 0x74, 0xb7, 0xa3, 0x72, 0xa5, 0xbb, 0x1d, 0x39, 0x0e, 0x61, 0x3b, 0x3d, 0x54, 0x2d,
+	0x47, 0x60, 0x6c, 0x92, 0x99, 0xf1, 0x49, 0xd2, 0xfb, 0x50, 0xb4, 0x7d, 0xbf, 0x77, 0x6a, 0xa1,
+	0x40, 0x2f, 0xdc, 0x8a, 0xdc, 0x72, 0xce, 0x9a, 0x60, 0xa7, 0x28, 0x75, 0xfe, 0xef, 0x95, 0xfa,
+	0x99, 0xc0, 0xaa, 0x85, 0x3e, 0xe3, 0xa2, 0xc5, 0x9c, 0xbd, 0x50, 0x27, 0x7f, 0x98, 0x86, 0xec,
+	0xe2, 0x88, 0x39, 0x4d, 0x33, 0xee, 0x22, 0x04, 0xf4, 0x41, 0x22, 0x3a, 0xd9, 0x45, 0x31, 0xd6,
+	0xce, 0x28, 0xab, 0x32, 0xcb, 0x70, 0xe4, 0x9c, 0xf1, 0x58, 0xb3, 0x21, 0x30, 0x9e, 0xc2, 0x7f,
+	0xb7, 0xae, 0xa1, 0x46, 0x5d, 0x86, 0x9c, 0x6b, 0x7b, 0x2e, 0xf6, 0x30, 0x9a, 0x65, 0xce, 0x4a,
+	0xf0, 0xc3, 0x17, 0x90, 0x4f, 0x02, 0x68, 0x09, 0x0a, 0xad, 0x57, 0xdb, 0xef, 0xac, 0x83, 0x76,
+	0xbb, 0xd9, 0xde, 0x5d, 0x4a, 0xd1, 0x65, 0x58, 0x94, 0xc4, 0xde, 0x41, 0xbd, 0xde, 0x68, 0x98,
+	0x0d, 0x73, 0x89, 0xd0, 0x22, 0x80, 0xa4, 0x76, 0xb6, 0x9a, 0x2f, 0x1b, 0xe6, 0x52, 0xba, 0xf6,
+	0x8d, 0x40, 0xbe, 0x1e, 0x3f, 0x25, 0x74, 0x1f, 0x96, 0x6f, 0xbd, 0x08, 0x54, 0x8f, 0x3a, 0x99,
+	0xf5, 0x8c, 0x94, 0xef, 0xce, 0xb4, 0xab, 0x06, 0xde, 0x00, 0xbd, 0x2d, 0x58, 0x3a, 0x0a, 0x9b,
+	0xae, 0xf3, 0x72, 0x65, 0xb6, 0x43, 0x94, 0xb8, 0xf6, 0x95, 0x40, 0xae, 0xc5, 0x9c, 0xd7, 0x52,
+	0x99, 0xf4, 0x19, 0x2c, 0x28, 0x91, 0xd2, 0x95, 0x78, 0xf3, 0xe3, 0xea, 0x2e, 0xff, 0x3b, 0xc1,
+	0xaa, 0xdb, 0xb5, 0xa1, 0x34, 0x31, 0x79, 0xba, 0x1e, 0x7f, 0xf7, 0xd3, 0x74, 0x51, 0xbe, 0x33,
+	0xc3, 0x1a, 0xe5, 0xdb, 0x7e, 0x72, 0x7e, 0xa9, 0xa7, 0x2e, 0x2e, 0xf5, 0xd4, 0xf5, 0xa5, 0x4e,
+	0x3e, 0x0d, 0x75, 0x72, 0x36, 0xd4, 0xc9, 0xf7, 0xa1, 0x4e, 0xce, 0x87, 0x3a, 0xf9, 0x31, 0xd4,
+	0xc9, 0xcf, 0xa1, 0x9e, 0xba, 0x1e, 0xea, 0xe4, 0xcb, 0x95, 0x9e, 0x3a, 0xbf, 0xd2, 0x53, 0x17,
+	0x57, 0x7a, 0xca, 0xc9, 0x86, 0x8f, 0xf6, 0xe3, 0xdf, 0x03, 0x00, 0xa0, 0x67, 0x2f, 0x67, 0xdc,
+	0x05, 0x00, 0x00,
+}
+
+func (x JobStatus) String() string {
+	s, ok := JobStatus_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
 }
-
 func (this *GetDeleteRequestsRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -394,6 +796,39 @@ func (this *DeleteRequest) Equal(that interface{}) bool {
 	if this.CreatedAt != that1.CreatedAt {
 		return false
 	}
+	if !this.Redaction.Equal(that1.Redaction) {
+		return false
+	}
+	if this.UserID != that1.UserID {
+		return false
+	}
+	return true
+}
+func (this *Redaction) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Redaction)
+	if !ok {
+		that2, ok := that.(Redaction)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Regex != that1.Regex {
+		return false
+	}
+	if this.Replacement != that1.Replacement {
+		return false
+	}
 	return true
 }
 func (this *GetCacheGenNumbersRequest) Equal(that interface{}) bool {
@@ -441,15 +876,161 @@ func (this *GetCacheGenNumbersResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *GetDeleteRequestsRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *DequeueRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&grpc.GetDeleteRequestsRequest{")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
+
+	that1, ok := that.(*DequeueRequest)
+	if !ok {
+		that2, ok := that.(DequeueRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Worker != that1.Worker {
+		return false
+	}
+	return true
+}
+func (this *DequeueResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DequeueResponse)
+	if !ok {
+		that2, ok := that.(DequeueResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.Job.Equal(that1.Job) {
+		return false
+	}
+	return true
+}
+func (this *Job) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Job)
+	if !ok {
+		that2, ok := that.(Job)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Id != that1.Id {
+		return false
+	}
+	if this.Table != that1.Table {
+		return false
+	}
+	if this.UserID != that1.UserID {
+		return false
+	}
+	if this.ApplyRetention != that1.ApplyRetention {
+		return false
+	}
+	if len(this.DeleteRequests) != len(that1.DeleteRequests) {
+		return false
+	}
+	for i := range this.DeleteRequests {
+		if !this.DeleteRequests[i].Equal(that1.DeleteRequests[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *ReportJobStatusRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReportJobStatusRequest)
+	if !ok {
+		that2, ok := that.(ReportJobStatusRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Worker != that1.Worker {
+		return false
+	}
+	if this.JobID != that1.JobID {
+		return false
+	}
+	if this.Status != that1.Status {
+		return false
+	}
+	if this.Error != that1.Error {
+		return false
+	}
+	return true
+}
+func (this *ReportJobStatusResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ReportJobStatusResponse)
+	if !ok {
+		that2, ok := that.(ReportJobStatusResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Canceled != that1.Canceled {
+		return false
+	}
+	return true
+}
+func (this *GetDeleteRequestsRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&grpc.GetDeleteRequestsRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *GetDeleteRequestsResponse) GoString() string {
 	if this == nil {
 		return "nil"
@@ -466,7 +1047,7 @@ func (this *DeleteRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 10)
+	s := make([]string, 0, 12)
 	s = append(s, "&grpc.DeleteRequest{")
 	s = append(s, "RequestID: "+fmt.Sprintf("%#v", this.RequestID)+",\n")
 	s = append(s, "StartTime: "+fmt.Sprintf("%#v", this.StartTime)+",\n")
@@ -474,6 +1055,21 @@ func (this *DeleteRequest) GoString() string {
 	s = append(s, "Query: "+fmt.Sprintf("%#v", this.Query)+",\n")
 	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
 	s = append(s, "CreatedAt: "+fmt.Sprintf("%#v", this.CreatedAt)+",\n")
+	if this.Redaction != nil {
+		s = append(s, "Redaction: "+fmt.Sprintf("%#v", this.Redaction)+",\n")
+	}
+	s = append(s, "UserID: "+fmt.Sprintf("%#v", this.UserID)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Redaction) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&grpc.Redaction{")
+	s = append(s, "Regex: "+fmt.Sprintf("%#v", this.Regex)+",\n")
+	s = append(s, "Replacement: "+fmt.Sprintf("%#v", this.Replacement)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -496,6 +1092,67 @@ func (this *GetCacheGenNumbersResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *DequeueRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&grpc.DequeueRequest{")
+	s = append(s, "Worker: "+fmt.Sprintf("%#v", this.Worker)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DequeueResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&grpc.DequeueResponse{")
+	if this.Job != nil {
+		s = append(s, "Job: "+fmt.Sprintf("%#v", this.Job)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Job) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&grpc.Job{")
+	s = append(s, "Id: "+fmt.Sprintf("%#v", this.Id)+",\n")
+	s = append(s, "Table: "+fmt.Sprintf("%#v", this.Table)+",\n")
+	s = append(s, "UserID: "+fmt.Sprintf("%#v", this.UserID)+",\n")
+	s = append(s, "ApplyRetention: "+fmt.Sprintf("%#v", this.ApplyRetention)+",\n")
+	if this.DeleteRequests != nil {
+		s = append(s, "DeleteRequests: "+fmt.Sprintf("%#v", this.DeleteRequests)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReportJobStatusRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&grpc.ReportJobStatusRequest{")
+	s = append(s, "Worker: "+fmt.Sprintf("%#v", this.Worker)+",\n")
+	s = append(s, "JobID: "+fmt.Sprintf("%#v", this.JobID)+",\n")
+	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
+	s = append(s, "Error: "+fmt.Sprintf("%#v", this.Error)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReportJobStatusResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&grpc.ReportJobStatusResponse{")
+	s = append(s, "Canceled: "+fmt.Sprintf("%#v", this.Canceled)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringGrpc(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -621,6 +1278,114 @@ var _Compactor_serviceDesc = grpc.ServiceDesc{
 	Metadata: "pkg/compactor/client/grpc/grpc.proto",
 }
 
+// JobQueueClient is the client API for JobQueue service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type JobQueueClient interface {
+	Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*DequeueResponse, error)
+	ReportJobStatus(ctx context.Context, in *ReportJobStatusRequest, opts ...grpc.CallOption) (*ReportJobStatusResponse, error)
+}
+
+type jobQueueClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewJobQueueClient(cc *grpc.ClientConn) JobQueueClient {
+	return &jobQueueClient{cc}
+}
+
+func (c *jobQueueClient) Dequeue(ctx context.Context, in *DequeueRequest, opts ...grpc.CallOption) (*DequeueResponse, error) {
+	out := new(DequeueResponse)
+	err := c.cc.Invoke(ctx, "/grpc.JobQueue/Dequeue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobQueueClient) ReportJobStatus(ctx context.Context, in *ReportJobStatusRequest, opts ...grpc.CallOption) (*ReportJobStatusResponse, error) {
+	out := new(ReportJobStatusResponse)
+	err := c.cc.Invoke(ctx, "/grpc.JobQueue/ReportJobStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JobQueueServer is the server API for JobQueue service.
+type JobQueueServer interface {
+	Dequeue(context.Context, *DequeueRequest) (*DequeueResponse, error)
+	ReportJobStatus(context.Context, *ReportJobStatusRequest) (*ReportJobStatusResponse, error)
+}
+
+// UnimplementedJobQueueServer can be embedded to have forward compatible implementations.
+type UnimplementedJobQueueServer struct {
+}
+
+func (*UnimplementedJobQueueServer) Dequeue(ctx context.Context, req *DequeueRequest) (*DequeueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Dequeue not implemented")
+}
+func (*UnimplementedJobQueueServer) ReportJobStatus(ctx context.Context, req *ReportJobStatusRequest) (*ReportJobStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportJobStatus not implemented")
+}
+
+func RegisterJobQueueServer(s *grpc.Server, srv JobQueueServer) {
+	s.RegisterService(&_JobQueue_serviceDesc, srv)
+}
+
+func _JobQueue_Dequeue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DequeueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobQueueServer).Dequeue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.JobQueue/Dequeue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobQueueServer).Dequeue(ctx, req.(*DequeueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobQueue_ReportJobStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportJobStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobQueueServer).ReportJobStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.JobQueue/ReportJobStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobQueueServer).ReportJobStatus(ctx, req.(*ReportJobStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _JobQueue_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc.JobQueue",
+	HandlerType: (*JobQueueServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Dequeue",
+			Handler:    _JobQueue_Dequeue_Handler,
+		},
+		{
+			MethodName: "ReportJobStatus",
+			Handler:    _JobQueue_ReportJobStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/compactor/client/grpc/grpc.proto",
+}
+
 func (m *GetDeleteRequestsRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -701,6 +1466,25 @@ func (m *DeleteRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.UserID) > 0 {
+		i -= len(m.UserID)
+		copy(dAtA[i:], m.UserID)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.UserID)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if m.Redaction != nil {
+		{
+			size, err := m.Redaction.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGrpc(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3a
+	}
 	if m.CreatedAt != 0 {
 		i = encodeVarintGrpc(dAtA, i, uint64(m.CreatedAt))
 		i--
@@ -740,7 +1524,7 @@ func (m *DeleteRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *GetCacheGenNumbersRequest) Marshal() (dAtA []byte, err error) {
+func (m *Redaction) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -750,20 +1534,57 @@ func (m *GetCacheGenNumbersRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *GetCacheGenNumbersRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *Redaction) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *GetCacheGenNumbersRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *Redaction) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	if len(m.Replacement) > 0 {
+		i -= len(m.Replacement)
+		copy(dAtA[i:], m.Replacement)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Replacement)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Regex) > 0 {
+		i -= len(m.Regex)
+		copy(dAtA[i:], m.Regex)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Regex)))
+		i--
+		dAtA[i] = 0xa
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *GetCacheGenNumbersResponse) Marshal() (dAtA []byte, err error) {
+func (m *GetCacheGenNumbersRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetCacheGenNumbersRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GetCacheGenNumbersRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *GetCacheGenNumbersResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -793,6 +1614,221 @@ func (m *GetCacheGenNumbersResponse) MarshalToSizedBuffer(dAtA []byte) (int, err
 	return len(dAtA) - i, nil
 }
 
+func (m *DequeueRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DequeueRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DequeueRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Worker) > 0 {
+		i -= len(m.Worker)
+		copy(dAtA[i:], m.Worker)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Worker)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DequeueResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DequeueResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DequeueResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Job != nil {
+		{
+			size, err := m.Job.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGrpc(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Job) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Job) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Job) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.DeleteRequests) > 0 {
+		for iNdEx := len(m.DeleteRequests) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.DeleteRequests[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGrpc(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.ApplyRetention {
+		i--
+		if m.ApplyRetention {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.UserID) > 0 {
+		i -= len(m.UserID)
+		copy(dAtA[i:], m.UserID)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.UserID)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Table) > 0 {
+		i -= len(m.Table)
+		copy(dAtA[i:], m.Table)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Table)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Id) > 0 {
+		i -= len(m.Id)
+		copy(dAtA[i:], m.Id)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Id)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ReportJobStatusRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReportJobStatusRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ReportJobStatusRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Status != 0 {
+		i = encodeVarintGrpc(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.JobID) > 0 {
+		i -= len(m.JobID)
+		copy(dAtA[i:], m.JobID)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.JobID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Worker) > 0 {
+		i -= len(m.Worker)
+		copy(dAtA[i:], m.Worker)
+		i = encodeVarintGrpc(dAtA, i, uint64(len(m.Worker)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ReportJobStatusResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReportJobStatusResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ReportJobStatusResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Canceled {
+		i--
+		if m.Canceled {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintGrpc(dAtA []byte, offset int, v uint64) int {
 	offset -= sovGrpc(v)
 	base := offset
@@ -855,6 +1891,31 @@ func (m *DeleteRequest) Size() (n int) {
 	if m.CreatedAt != 0 {
 		n += 1 + sovGrpc(uint64(m.CreatedAt))
 	}
+	if m.Redaction != nil {
+		l = m.Redaction.Size()
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.UserID)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	return n
+}
+
+func (m *Redaction) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Regex)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.Replacement)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
 	return n
 }
 
@@ -880,61 +1941,166 @@ func (m *GetCacheGenNumbersResponse) Size() (n int) {
 	return n
 }
 
-func sovGrpc(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozGrpc(x uint64) (n int) {
-	return sovGrpc(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *GetDeleteRequestsRequest) String() string {
-	if this == nil {
-		return "nil"
-	}
-	s := strings.Join([]string{`&GetDeleteRequestsRequest{`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *GetDeleteRequestsResponse) String() string {
-	if this == nil {
-		return "nil"
+func (m *DequeueRequest) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForDeleteRequests := "[]*DeleteRequest{"
-	for _, f := range this.DeleteRequests {
-		repeatedStringForDeleteRequests += strings.Replace(f.String(), "DeleteRequest", "DeleteRequest", 1) + ","
+	var l int
+	_ = l
+	l = len(m.Worker)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
 	}
-	repeatedStringForDeleteRequests += "}"
-	s := strings.Join([]string{`&GetDeleteRequestsResponse{`,
-		`DeleteRequests:` + repeatedStringForDeleteRequests + `,`,
-		`}`,
-	}, "")
-	return s
+	return n
 }
-func (this *DeleteRequest) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *DequeueResponse) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	s := strings.Join([]string{`&DeleteRequest{`,
-		`RequestID:` + fmt.Sprintf("%v", this.RequestID) + `,`,
-		`StartTime:` + fmt.Sprintf("%v", this.StartTime) + `,`,
-		`EndTime:` + fmt.Sprintf("%v", this.EndTime) + `,`,
-		`Query:` + fmt.Sprintf("%v", this.Query) + `,`,
-		`Status:` + fmt.Sprintf("%v", this.Status) + `,`,
-		`CreatedAt:` + fmt.Sprintf("%v", this.CreatedAt) + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *GetCacheGenNumbersRequest) String() string {
-	if this == nil {
-		return "nil"
+	var l int
+	_ = l
+	if m.Job != nil {
+		l = m.Job.Size()
+		n += 1 + l + sovGrpc(uint64(l))
 	}
-	s := strings.Join([]string{`&GetCacheGenNumbersRequest{`,
-		`}`,
-	}, "")
-	return s
+	return n
 }
-func (this *GetCacheGenNumbersResponse) String() string {
+
+func (m *Job) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.Table)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.UserID)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	if m.ApplyRetention {
+		n += 2
+	}
+	if len(m.DeleteRequests) > 0 {
+		for _, e := range m.DeleteRequests {
+			l = e.Size()
+			n += 1 + l + sovGrpc(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ReportJobStatusRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Worker)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	l = len(m.JobID)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovGrpc(uint64(m.Status))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovGrpc(uint64(l))
+	}
+	return n
+}
+
+func (m *ReportJobStatusResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Canceled {
+		n += 2
+	}
+	return n
+}
+
+func sovGrpc(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozGrpc(x uint64) (n int) {
+	return sovGrpc(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *GetDeleteRequestsRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GetDeleteRequestsRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetDeleteRequestsResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForDeleteRequests := "[]*DeleteRequest{"
+	for _, f := range this.DeleteRequests {
+		repeatedStringForDeleteRequests += strings.Replace(f.String(), "DeleteRequest", "DeleteRequest", 1) + ","
+	}
+	repeatedStringForDeleteRequests += "}"
+	s := strings.Join([]string{`&GetDeleteRequestsResponse{`,
+		`DeleteRequests:` + repeatedStringForDeleteRequests + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DeleteRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DeleteRequest{`,
+		`RequestID:` + fmt.Sprintf("%v", this.RequestID) + `,`,
+		`StartTime:` + fmt.Sprintf("%v", this.StartTime) + `,`,
+		`EndTime:` + fmt.Sprintf("%v", this.EndTime) + `,`,
+		`Query:` + fmt.Sprintf("%v", this.Query) + `,`,
+		`Status:` + fmt.Sprintf("%v", this.Status) + `,`,
+		`CreatedAt:` + fmt.Sprintf("%v", this.CreatedAt) + `,`,
+		`Redaction:` + strings.Replace(this.Redaction.String(), "Redaction", "Redaction", 1) + `,`,
+		`UserID:` + fmt.Sprintf("%v", this.UserID) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Redaction) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Redaction{`,
+		`Regex:` + fmt.Sprintf("%v", this.Regex) + `,`,
+		`Replacement:` + fmt.Sprintf("%v", this.Replacement) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetCacheGenNumbersRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&GetCacheGenNumbersRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *GetCacheGenNumbersResponse) String() string {
 	if this == nil {
 		return "nil"
 	}
@@ -944,6 +2110,68 @@ func (this *GetCacheGenNumbersResponse) String() string {
 	}, "")
 	return s
 }
+func (this *DequeueRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DequeueRequest{`,
+		`Worker:` + fmt.Sprintf("%v", this.Worker) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DequeueResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DequeueResponse{`,
+		`Job:` + strings.Replace(this.Job.String(), "Job", "Job", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Job) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForDeleteRequests := "[]*DeleteRequest{"
+	for _, f := range this.DeleteRequests {
+		repeatedStringForDeleteRequests += strings.Replace(f.String(), "DeleteRequest", "DeleteRequest", 1) + ","
+	}
+	repeatedStringForDeleteRequests += "}"
+	s := strings.Join([]string{`&Job{`,
+		`Id:` + fmt.Sprintf("%v", this.Id) + `,`,
+		`Table:` + fmt.Sprintf("%v", this.Table) + `,`,
+		`UserID:` + fmt.Sprintf("%v", this.UserID) + `,`,
+		`ApplyRetention:` + fmt.Sprintf("%v", this.ApplyRetention) + `,`,
+		`DeleteRequests:` + repeatedStringForDeleteRequests + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReportJobStatusRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReportJobStatusRequest{`,
+		`Worker:` + fmt.Sprintf("%v", this.Worker) + `,`,
+		`JobID:` + fmt.Sprintf("%v", this.JobID) + `,`,
+		`Status:` + fmt.Sprintf("%v", this.Status) + `,`,
+		`Error:` + fmt.Sprintf("%v", this.Error) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReportJobStatusResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ReportJobStatusResponse{`,
+		`Canceled:` + fmt.Sprintf("%v", this.Canceled) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringGrpc(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -962,25 +2190,726 @@ func (m *GetDeleteRequestsRequest) Unmarshal(dAtA []byte) error {
 			if shift >= 64 {
 				return ErrIntOverflowGrpc
 			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetDeleteRequestsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetDeleteRequestsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetDeleteRequestsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetDeleteRequestsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetDeleteRequestsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeleteRequests", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DeleteRequests = append(m.DeleteRequests, &DeleteRequest{})
+			if err := m.DeleteRequests[len(m.DeleteRequests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeleteRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeleteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequestID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RequestID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
+			}
+			m.StartTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
+			}
+			m.EndTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.EndTime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Query = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Status = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
+			}
+			m.CreatedAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CreatedAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Redaction", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Redaction == nil {
+				m.Redaction = &Redaction{}
+			}
+			if err := m.Redaction.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UserID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.UserID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Redaction) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Redaction: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Redaction: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Regex", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Regex = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Replacement", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Replacement = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetCacheGenNumbersRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetCacheGenNumbersRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetCacheGenNumbersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetCacheGenNumbersResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetCacheGenNumbersResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetCacheGenNumbersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ResultsCacheGen", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ResultsCacheGen = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGrpc(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DequeueRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGrpc
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DequeueRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DequeueRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Worker", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: GetDeleteRequestsRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetDeleteRequestsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Worker = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGrpc(dAtA[iNdEx:])
@@ -1005,7 +2934,7 @@ func (m *GetDeleteRequestsRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetDeleteRequestsResponse) Unmarshal(dAtA []byte) error {
+func (m *DequeueResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1028,15 +2957,15 @@ func (m *GetDeleteRequestsResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetDeleteRequestsResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: DequeueResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetDeleteRequestsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DequeueResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DeleteRequests", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Job", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1063,8 +2992,10 @@ func (m *GetDeleteRequestsResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.DeleteRequests = append(m.DeleteRequests, &DeleteRequest{})
-			if err := m.DeleteRequests[len(m.DeleteRequests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.Job == nil {
+				m.Job = &Job{}
+			}
+			if err := m.Job.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -1092,7 +3023,7 @@ func (m *GetDeleteRequestsResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
+func (m *Job) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1115,15 +3046,15 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DeleteRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: Job: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DeleteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Job: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RequestID", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1151,49 +3082,11 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.RequestID = string(dAtA[iNdEx:postIndex])
+			m.Id = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StartTime", wireType)
-			}
-			m.StartTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGrpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.StartTime |= int64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field EndTime", wireType)
-			}
-			m.EndTime = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGrpc
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.EndTime |= int64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Table", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1221,11 +3114,11 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Query = string(dAtA[iNdEx:postIndex])
+			m.Table = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field UserID", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -1253,13 +3146,13 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Status = string(dAtA[iNdEx:postIndex])
+			m.UserID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 6:
+		case 4:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field CreatedAt", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ApplyRetention", wireType)
 			}
-			m.CreatedAt = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGrpc
@@ -1269,11 +3162,46 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.CreatedAt |= int64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ApplyRetention = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeleteRequests", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DeleteRequests = append(m.DeleteRequests, &DeleteRequest{})
+			if err := m.DeleteRequests[len(m.DeleteRequests)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGrpc(dAtA[iNdEx:])
@@ -1298,7 +3226,7 @@ func (m *DeleteRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetCacheGenNumbersRequest) Unmarshal(dAtA []byte) error {
+func (m *ReportJobStatusRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1321,12 +3249,127 @@ func (m *GetCacheGenNumbersRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetCacheGenNumbersRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReportJobStatusRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetCacheGenNumbersRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReportJobStatusRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Worker", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Worker = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field JobID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JobID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= JobStatus(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGrpc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGrpc
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGrpc(dAtA[iNdEx:])
@@ -1351,7 +3394,7 @@ func (m *GetCacheGenNumbersRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *GetCacheGenNumbersResponse) Unmarshal(dAtA []byte) error {
+func (m *ReportJobStatusResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1374,17 +3417,17 @@ func (m *GetCacheGenNumbersResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: GetCacheGenNumbersResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ReportJobStatusResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: GetCacheGenNumbersResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ReportJobStatusResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ResultsCacheGen", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Canceled", wireType)
 			}
-			var stringLen uint64
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGrpc
@@ -1394,24 +3437,12 @@ func (m *GetCacheGenNumbersResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthGrpc
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthGrpc
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.ResultsCacheGen = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
+			m.Canceled = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGrpc(dAtA[iNdEx:])
@@ -18,15 +18,18 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 
 	"github.com/grafana/loki/v3/pkg/analytics"
+	compactor_grpc "github.com/grafana/loki/v3/pkg/compactor/client/grpc"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
-	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/types"
 	"github.com/grafana/loki/v3/pkg/util/filter"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	lokiring "github.com/grafana/loki/v3/pkg/util/ring"
@@ -88,6 +91,17 @@ type Config struct {
 	RunOnce                     bool                `yaml:"_" doc:"hidden"`
 	TablesToCompact             int                 `yaml:"tables_to_compact"`
 	SkipLatestNTables           int                 `yaml:"skip_latest_n_tables"`
+
+	ZstdDictionaries   ZstdDictionariesConfig   `yaml:"zstd_dictionaries" category:"experimental"`
+	ReplicaChunksMerge ReplicaChunksMergeConfig `yaml:"replica_chunks_merge" category:"experimental"`
+
+	DeleteRequestDryRun deletion.PreviewConfig `yaml:"delete_request_dry_run" category:"experimental"`
+
+	HorizontalScaling HorizontalScalingConfig `yaml:"horizontal_scaling" category:"experimental"`
+
+	OrphanedChunksGC OrphanedChunksGCConfig `yaml:"orphaned_chunks_gc" category:"experimental"`
+
+	SchemaMigration SchemaMigrationConfig `yaml:"schema_migration" category:"experimental"`
 }
 
 // RegisterFlags registers flags.
@@ -109,6 +123,12 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.RunOnce, "compactor.run-once", false, "Run the compactor one time to cleanup and compact index files only (no retention applied)")
 	f.IntVar(&cfg.TablesToCompact, "compactor.tables-to-compact", 0, "Number of tables that compactor will try to compact. Newer tables are chosen when this is less than the number of tables available.")
 	f.IntVar(&cfg.SkipLatestNTables, "compactor.skip-latest-n-tables", 0, "Do not compact N latest tables. Together with -compactor.run-once and -compactor.tables-to-compact, this is useful when clearing compactor backlogs.")
+	cfg.ZstdDictionaries.RegisterFlagsWithPrefix("compactor.zstd-dictionaries.", f)
+	cfg.ReplicaChunksMerge.RegisterFlagsWithPrefix("compactor.replica-chunks-merge.", f)
+	cfg.DeleteRequestDryRun.RegisterFlagsWithPrefix("compactor.delete-request-dry-run.", f)
+	cfg.HorizontalScaling.RegisterFlagsWithPrefix("compactor.horizontal-scaling.", f)
+	cfg.OrphanedChunksGC.RegisterFlagsWithPrefix("compactor.orphaned-chunks-gc.", f)
+	cfg.SchemaMigration.RegisterFlagsWithPrefix("compactor.schema-migration.", f)
 
 	// Ring
 	skipFlags := []string{
@@ -134,6 +154,34 @@ func (cfg *Config) Validate() error {
 		return errors.New("Replication factor must not be changed as it will not take effect")
 	}
 
+	if err := cfg.ZstdDictionaries.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.ReplicaChunksMerge.Enabled && !cfg.RetentionEnabled {
+		return errors.New("retention must be enabled to merge the replica chunks, for the sweeper to delete the merged chunks")
+	}
+
+	if cfg.DeleteRequestDryRun.Enabled && !cfg.RetentionEnabled {
+		return errors.New("retention must be enabled to preview delete requests, as the delete API is served only when it is")
+	}
+
+	if err := cfg.HorizontalScaling.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.OrphanedChunksGC.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.OrphanedChunksGC.Enabled && cfg.RetentionEnabled && cfg.OrphanedChunksGC.Interval <= cfg.RetentionDeleteDelay {
+		return errors.New("orphaned chunks gc interval must be greater than the retention delete delay, for the sweepers to delete the chunks marked by the retention first")
+	}
+
+	if err := cfg.SchemaMigration.Validate(); err != nil {
+		return err
+	}
+
 	if cfg.RetentionEnabled {
 		if cfg.DeleteRequestStore == "" {
 			return fmt.Errorf("compactor.delete-request-store should be configured when retention is enabled")
@@ -174,6 +222,21 @@ type Compactor struct {
 	indexCompactors           map[string]IndexCompactor
 	schemaConfig              config.SchemaConfig
 	tableLocker               *tableLocker
+	zstdDictionaryTrainer     *zstdDictionaryTrainer
+	limits                    Limits
+	orphanedChunksGCMetrics   *orphanedChunksGCMetrics
+	schemaMigrationMetrics    *schemaMigrationMetrics
+
+	// JobQueue distributes the compaction jobs to the workers of the compactors when horizontal scaling is enabled.
+	JobQueue       *JobQueue
+	jobQueueClient func() (compactor_grpc.JobQueueClient, error)
+	// workers and sweepers of the compactors running whether they are the leader or not.
+	workersWg sync.WaitGroup
+
+	// connection of the workers to the job queue of the compactor leader.
+	jobQueueConnMtx sync.Mutex
+	jobQueueConn    *grpc.ClientConn
+	jobQueueAddr    string
 
 	// Ring used for running a single compactor
 	ringLifecycler *ring.BasicLifecycler
@@ -189,9 +252,16 @@ type Compactor struct {
 }
 
 type storeContainer struct {
-	tableMarker        retention.TableMarker
-	sweeper            *retention.Sweeper
-	indexStorageClient storage.Client
+	tableMarker         retention.TableMarker
+	sweeper             *retention.Sweeper
+	indexStorageClient  storage.Client
+	replicaChunksMerger *replicaChunksMerger
+	// orphanedChunksCollector is only set for the periods with TSDB index when the orphaned chunks gc is enabled.
+	orphanedChunksCollector *orphanedChunksCollector
+	// schemaMigrator is only set for the periods with boltdb-shipper index followed by another period when the schema migration is enabled.
+	schemaMigrator *schemaMigrator
+	// markersHandOff is only set when retention is applied by the compactors of the ring.
+	markersHandOff *retentionMarkersHandOff
 }
 
 type Limits interface {
@@ -200,7 +270,7 @@ type Limits interface {
 	DefaultLimits() *validation.Limits
 }
 
-func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer, metricsNamespace string) (*Compactor, error) {
+func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, zstdDictionaryStore ZstdDictionaryStore, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer, metricsNamespace string) (*Compactor, error) {
 	retentionEnabledStats.Set("false")
 	if cfg.RetentionEnabled {
 		retentionEnabledStats.Set("true")
@@ -216,6 +286,7 @@ func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.Objec
 		schemaConfig:    schemaConfig,
 		tableLocker:     newTableLocker(),
 	}
+	compactor.jobQueueClient = compactor.leaderJobQueueClient
 
 	ringStore, err := kv.NewClient(
 		cfg.CompactorRing.KVStore,
@@ -256,19 +327,27 @@ func NewCompactor(cfg Config, objectStoreClients map[config.DayTime]client.Objec
 	compactor.subservicesWatcher = services.NewFailureWatcher()
 	compactor.subservicesWatcher.WatchManager(compactor.subservices)
 
-	if err := compactor.init(objectStoreClients, deleteStoreClient, schemaConfig, limits, r); err != nil {
+	if err := compactor.init(objectStoreClients, deleteStoreClient, zstdDictionaryStore, schemaConfig, limits, r); err != nil {
 		return nil, fmt.Errorf("init compactor: %w", err)
 	}
 
+	if cfg.HorizontalScaling.Enabled && !cfg.RunOnce {
+		compactor.JobQueue, err = newJobQueue(cfg.HorizontalScaling, cfg.WorkingDirectory, compactor.metrics)
+		if err != nil {
+			return nil, fmt.Errorf("init job queue: %w", err)
+		}
+	}
+
 	compactor.Service = services.NewBasicService(compactor.starting, compactor.loop, compactor.stopping)
 	return compactor, nil
 }
 
-func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer) error {
+func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClient, deleteStoreClient client.ObjectClient, zstdDictionaryStore ZstdDictionaryStore, schemaConfig config.SchemaConfig, limits Limits, r prometheus.Registerer) error {
 	err := chunk_util.EnsureDirectory(c.cfg.WorkingDirectory)
 	if err != nil {
 		return err
 	}
+	c.limits = limits
 
 	if c.cfg.RetentionEnabled {
 		if deleteStoreClient == nil {
@@ -280,7 +359,28 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 		}
 	}
 
+	if c.cfg.ZstdDictionaries.TrainingEnabled && zstdDictionaryStore == nil {
+		return fmt.Errorf("zstd dictionary store not initialised when zstd dictionaries training is enabled")
+	}
+
+	var replicaChunksMergeMetrics *replicaChunksMergeMetrics
+	if c.cfg.ReplicaChunksMerge.Enabled {
+		replicaChunksMergeMetrics = newReplicaChunksMergeMetrics(r)
+	}
+
+	// the deletes of all the periods share the same rate limit.
+	var orphanedChunksLimiter *rate.Limiter
+	if c.cfg.OrphanedChunksGC.Enabled {
+		c.orphanedChunksGCMetrics = newOrphanedChunksGCMetrics(r)
+		orphanedChunksLimiter = rate.NewLimiter(rate.Limit(c.cfg.OrphanedChunksGC.MaxDeletesPerSecond), 1)
+	}
+
+	if c.cfg.SchemaMigration.Enabled {
+		c.schemaMigrationMetrics = newSchemaMigrationMetrics(r)
+	}
+
 	legacyMarkerDirs := make(map[string]struct{})
+	chunkClients := make(map[config.DayTime]client.Client, len(objectStoreClients))
 	c.storeContainers = make(map[config.DayTime]storeContainer, len(objectStoreClients))
 	for from, objectClient := range objectStoreClients {
 		period, err := schemaConfig.SchemaForTime(from.Time)
@@ -291,10 +391,12 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 		var sc storeContainer
 		sc.indexStorageClient = storage.NewIndexStorageClient(objectClient, period.IndexTables.PathPrefix)
 
+		encoder := client.KeyEncoderFor(objectClient)
+		chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
+		chunkClients[from] = chunkClient
+
 		if c.cfg.RetentionEnabled {
 			var (
-				raw              client.ObjectClient
-				encoder          client.KeyEncoder
 				name             = fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())
 				retentionWorkDir = filepath.Join(c.cfg.WorkingDirectory, "retention", name)
 				r                = prometheus.WrapRegistererWith(prometheus.Labels{"from": name}, r)
@@ -313,16 +415,6 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			// remove markers from the store dir after copying them to period specific dirs.
 			legacyMarkerDirs[period.ObjectType] = struct{}{}
 
-			if casted, ok := objectClient.(client.PrefixedObjectClient); ok {
-				raw = casted.GetDownstream()
-			} else {
-				raw = objectClient
-			}
-			if _, ok := raw.(*local.FSObjectClient); ok {
-				encoder = client.FSEncoder
-			}
-			chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
-
 			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
 			if err != nil {
 				return fmt.Errorf("failed to init sweeper: %w", err)
@@ -332,6 +424,42 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			if err != nil {
 				return fmt.Errorf("failed to init table marker: %w", err)
 			}
+
+			if c.cfg.ReplicaChunksMerge.Enabled {
+				// The merged chunks are marked for deletion along with the expired chunks.
+				sc.replicaChunksMerger = newReplicaChunksMerger(chunkClient, schemaConfig, retentionWorkDir, replicaChunksMergeMetrics)
+			}
+
+			if c.cfg.HorizontalScaling.Enabled && !c.cfg.RunOnce {
+				sc.markersHandOff = newRetentionMarkersHandOff(objectClient, name, retentionWorkDir)
+			}
+		}
+
+		if c.cfg.OrphanedChunksGC.Enabled {
+			if period.IndexType == types.TSDBType {
+				sc.orphanedChunksCollector = newOrphanedChunksCollector(c.cfg.OrphanedChunksGC, schemaConfig, period, objectClient, chunkClient, sc.indexStorageClient,
+					encoder != nil, filepath.Join(c.cfg.WorkingDirectory, "orphaned-chunks", fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())), orphanedChunksLimiter, c.orphanedChunksGCMetrics)
+			} else {
+				level.Warn(util_log.Logger).Log("msg", "orphaned chunks gc only supports the tsdb index, skipping period", "period", period.From.String(), "index-type", period.IndexType)
+			}
+		}
+
+		if c.cfg.SchemaMigration.Enabled && period.IndexType == types.BoltDBShipperType {
+			if period.IndexTables.PathPrefix == c.cfg.SchemaMigration.PathPrefix {
+				return fmt.Errorf("schema migration path prefix %s must differ from the path prefix of the period %s", c.cfg.SchemaMigration.PathPrefix, period.From.String())
+			}
+
+			// the tables of the last period are always written to.
+			if end, ok := nextPeriodStart(schemaConfig, period); ok {
+				sc.schemaMigrator, err = newSchemaMigrator(c.cfg.SchemaMigration, schemaConfig, period, end,
+					sc.indexStorageClient, storage.NewIndexStorageClient(objectClient, c.cfg.SchemaMigration.PathPrefix),
+					filepath.Join(c.cfg.WorkingDirectory, "schema-migration", fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())), c.schemaMigrationMetrics)
+				if err != nil {
+					return fmt.Errorf("failed to init schema migration: %w", err)
+				}
+			} else {
+				level.Warn(util_log.Logger).Log("msg", "schema migration only migrates the periods followed by another period, skipping period", "period", period.From.String())
+			}
 		}
 
 		c.storeContainers[from] = sc
@@ -350,6 +478,10 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 		}
 	}
 
+	if c.cfg.ZstdDictionaries.TrainingEnabled {
+		c.zstdDictionaryTrainer = newZstdDictionaryTrainer(c.cfg.ZstdDictionaries, zstdDictionaryStore, chunkClients, r, util_log.Logger)
+	}
+
 	c.metrics = newMetrics(r)
 	return nil
 }
@@ -455,6 +587,15 @@ func (c *Compactor) loop(ctx context.Context) error {
 		}
 	}
 
+	if c.JobQueue != nil {
+		defer c.closeJobQueueConn()
+		// every compactor runs the jobs planned by the leader, trains the zstd dictionaries from the chunks it sampled,
+		// and deletes the chunks it marked.
+		c.runWorkers(ctx)
+		c.runZstdDictionaryTraining(ctx)
+		c.runSweepers(ctx, &c.workersWg)
+	}
+
 	syncTicker := time.NewTicker(c.ringPollPeriod)
 	defer syncTicker.Stop()
 
@@ -468,6 +609,7 @@ func (c *Compactor) loop(ctx context.Context) error {
 				runningCancel()
 			}
 			c.wg.Wait()
+			c.workersWg.Wait()
 			level.Info(util_log.Logger).Log("msg", "compactor exiting")
 			return nil
 		case <-syncTicker.C:
@@ -525,6 +667,16 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 		}
 	}()
 
+	if c.JobQueue != nil {
+		c.JobQueue.start()
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			<-ctx.Done()
+			c.JobQueue.stop()
+		}()
+	}
+
 	// do the initial compaction
 	if err := c.RunCompaction(ctx, false); err != nil {
 		level.Error(util_log.Logger).Log("msg", "failed to run compaction", "err", err)
@@ -572,23 +724,83 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 			}
 		}()
 
-		for _, container := range c.storeContainers {
-			c.wg.Add(1)
-			go func(sc storeContainer) {
-				// starts the chunk sweeper
-				defer func() {
-					sc.sweeper.Stop()
-					c.wg.Done()
-				}()
-				sc.sweeper.Start()
-				<-ctx.Done()
-			}(container)
+		if c.JobQueue == nil {
+			c.runSweepers(ctx, &c.wg)
 		}
 	}
+
+	if c.cfg.OrphanedChunksGC.Enabled {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.runOrphanedChunksGC(ctx)
+
+			ticker := time.NewTicker(c.cfg.OrphanedChunksGC.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					c.runOrphanedChunksGC(ctx)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if c.cfg.SchemaMigration.Enabled {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.runSchemaMigration(ctx)
+
+			ticker := time.NewTicker(c.cfg.SchemaMigration.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					c.runSchemaMigration(ctx)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 	level.Info(util_log.Logger).Log("msg", "compactor started")
 }
 
+// runSweepers runs the sweepers deleting the chunks marked by retention, until the context is done.
+func (c *Compactor) runSweepers(ctx context.Context, wg *sync.WaitGroup) {
+	if !c.cfg.RetentionEnabled {
+		return
+	}
+
+	for _, container := range c.storeContainers {
+		wg.Add(1)
+		go func(sc storeContainer) {
+			// starts the chunk sweeper
+			defer func() {
+				sc.sweeper.Stop()
+				wg.Done()
+			}()
+			sc.sweeper.Start()
+			<-ctx.Done()
+		}(container)
+	}
+}
+
 func (c *Compactor) stopping(_ error) error {
+	// the compactor leaves the ring, the chunks it marked are deleted by the compactor leader.
+	for _, sc := range c.storeContainers {
+		if sc.markersHandOff == nil {
+			continue
+		}
+		if err := sc.markersHandOff.upload(context.Background(), c.ringLifecycler.GetInstanceID()); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to hand off retention markers", "err", err)
+		}
+	}
 	return services.StopManagerAndAwaitStopped(context.Background(), c.subservices)
 }
 
@@ -640,22 +852,13 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 	}
 	defer c.tableLocker.unlockTable(tableName)
 
-	table, err := newTable(ctx, filepath.Join(c.cfg.WorkingDirectory, tableName), sc.indexStorageClient, indexCompactor,
-		schemaCfg, sc.tableMarker, c.expirationChecker, c.cfg.UploadParallelism)
-	if err != nil {
-		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
-		return err
-	}
-
 	interval := retention.ExtractIntervalFromTableName(tableName)
 	intervalMayHaveExpiredChunks := false
 	if applyRetention {
 		intervalMayHaveExpiredChunks = c.expirationChecker.IntervalMayHaveExpiredChunks(interval, "")
 	}
 
-	err = table.compact(intervalMayHaveExpiredChunks)
-	if err != nil {
-		level.Error(util_log.Logger).Log("msg", "failed to compact files", "table", tableName, "err", err)
+	if err := c.compactIndexSets(ctx, tableName, "", schemaCfg, indexCompactor, sc, c.expirationChecker, intervalMayHaveExpiredChunks); err != nil {
 		return err
 	}
 
@@ -665,6 +868,41 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 	return nil
 }
 
+// compactIndexSets compacts the index sets of a table, or only the index of the given user when userID is set,
+// applying retention with the given expiration checker if needed.
+func (c *Compactor) compactIndexSets(ctx context.Context, tableName, userID string, schemaCfg config.PeriodConfig, indexCompactor IndexCompactor,
+	sc storeContainer, expirationChecker retention.ExpirationChecker, applyRetention bool) error {
+	tableMarker := sc.tableMarker
+	if marker, ok := tableMarker.(*retention.Marker); ok && expirationChecker != c.expirationChecker {
+		tableMarker = marker.WithExpirationChecker(expirationChecker)
+	}
+
+	// the name of the table is the base of its working directory, which must not be shared by the jobs of the users.
+	workingDir := filepath.Join(c.cfg.WorkingDirectory, tableName)
+	if userID != "" {
+		workingDir = filepath.Join(c.cfg.WorkingDirectory, "per-user", userID, tableName)
+	}
+
+	table, err := newTable(ctx, workingDir, sc.indexStorageClient, indexCompactor,
+		schemaCfg, tableMarker, expirationChecker, c.cfg.UploadParallelism)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
+		return err
+	}
+	if c.zstdDictionaryTrainer != nil {
+		table.chunkSampler = c.zstdDictionaryTrainer
+	}
+	table.replicaChunksMerger = sc.replicaChunksMerger
+	table.userID = userID
+
+	err = table.compact(applyRetention)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to compact files", "table", tableName, "user-id", userID, "err", err)
+		return err
+	}
+	return nil
+}
+
 func (c *Compactor) RegisterIndexCompactor(indexType string, indexCompactor IndexCompactor) {
 	c.indexCompactors[indexType] = indexCompactor
 }
@@ -709,40 +947,13 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) (err
 		}
 	}()
 
-	var (
-		tables []string
-		// it possible for two periods to use the same storage bucket and path prefix (different indexType or schema version)
-		// so more than one index storage client may end up listing the same set of buckets
-		// avoid including the same table twice in the compact tables list.
-		seen = make(map[string]struct{})
-	)
-	for _, sc := range c.storeContainers {
-		// refresh index list cache since previous compaction would have changed the index files in the object store
-		sc.indexStorageClient.RefreshIndexTableNamesCache(ctx)
-		tbls, err := sc.indexStorageClient.ListTables(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to list tables: %w", err)
-		}
-
-		for _, table := range tbls {
-			if _, ok := seen[table]; ok {
-				continue
-			}
-
-			tables = append(tables, table)
-			seen[table] = struct{}{}
-		}
+	if c.JobQueue != nil {
+		return c.runJobs(ctx, applyRetention)
 	}
 
-	// process most recent tables first
-	SortTablesByRange(tables)
-
-	// apply passed in compaction limits
-	if c.cfg.SkipLatestNTables <= len(tables) {
-		tables = tables[c.cfg.SkipLatestNTables:]
-	}
-	if c.cfg.TablesToCompact > 0 && c.cfg.TablesToCompact < len(tables) {
-		tables = tables[:c.cfg.TablesToCompact]
+	tables, err := c.listTables(ctx)
+	if err != nil {
+		return err
 	}
 
 	compactTablesChan := make(chan string)
@@ -805,9 +1016,54 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) (err
 		return firstErr
 	}
 
+	if c.zstdDictionaryTrainer != nil {
+		c.zstdDictionaryTrainer.trainIfDue(ctx)
+	}
+
 	return ctx.Err()
 }
 
+// listTables returns the tables to compact, most recent first.
+func (c *Compactor) listTables(ctx context.Context) ([]string, error) {
+	var (
+		tables []string
+		// it possible for two periods to use the same storage bucket and path prefix (different indexType or schema version)
+		// so more than one index storage client may end up listing the same set of buckets
+		// avoid including the same table twice in the compact tables list.
+		seen = make(map[string]struct{})
+	)
+	for _, sc := range c.storeContainers {
+		// refresh index list cache since previous compaction would have changed the index files in the object store
+		sc.indexStorageClient.RefreshIndexTableNamesCache(ctx)
+		tbls, err := sc.indexStorageClient.ListTables(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		for _, table := range tbls {
+			if _, ok := seen[table]; ok {
+				continue
+			}
+
+			tables = append(tables, table)
+			seen[table] = struct{}{}
+		}
+	}
+
+	// process most recent tables first
+	SortTablesByRange(tables)
+
+	// apply passed in compaction limits
+	if c.cfg.SkipLatestNTables <= len(tables) {
+		tables = tables[c.cfg.SkipLatestNTables:]
+	}
+	if c.cfg.TablesToCompact > 0 && c.cfg.TablesToCompact < len(tables) {
+		tables = tables[:c.cfg.TablesToCompact]
+	}
+
+	return tables, nil
+}
+
 type expirationChecker struct {
 	retentionExpiryChecker retention.ExpirationChecker
 	deletionExpiryChecker  retention.ExpirationChecker
@@ -825,6 +1081,14 @@ func (e *expirationChecker) Expired(ref retention.ChunkEntry, now model.Time) (b
 	return e.deletionExpiryChecker.Expired(ref, now)
 }
 
+func (e *expirationChecker) Redacted(ref retention.ChunkEntry) (bool, filter.RewriteFunc) {
+	if redacted, rewriteFunc := e.retentionExpiryChecker.Redacted(ref); redacted {
+		return redacted, rewriteFunc
+	}
+
+	return e.deletionExpiryChecker.Redacted(ref)
+}
+
 func (e *expirationChecker) MarkPhaseStarted() {
 	e.retentionExpiryChecker.MarkPhaseStarted()
 	e.deletionExpiryChecker.MarkPhaseStarted()
@@ -903,6 +1167,16 @@ func SchemaPeriodForTable(cfg config.SchemaConfig, tableName string) (config.Per
 	return schemaCfg, true
 }
 
+// nextPeriodStart returns the start of the period following the given one, if any.
+func nextPeriodStart(cfg config.SchemaConfig, period config.PeriodConfig) (time.Time, bool) {
+	for i := range cfg.Configs {
+		if cfg.Configs[i].From == period.From && i+1 < len(cfg.Configs) {
+			return cfg.Configs[i+1].From.Time.Time(), true
+		}
+	}
+	return time.Time{}, false
+}
+
 func minDuration(x time.Duration, y time.Duration) time.Duration {
 	if x < y {
 		return x
@@ -14,7 +14,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
+	compactor_grpc "github.com/grafana/loki/v3/pkg/compactor/client/grpc"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/config"
@@ -61,7 +63,7 @@ func setupTestCompactor(t *testing.T, objectClients map[config.DayTime]client.Ob
 	overrides, err := validation.NewOverrides(defaultLimits, nil)
 	require.NoError(t, err)
 
-	c, err := NewCompactor(cfg, objectClients, objectClients[periodConfigs[len(periodConfigs)-1].From], config.SchemaConfig{
+	c, err := NewCompactor(cfg, objectClients, objectClients[periodConfigs[len(periodConfigs)-1].From], nil, config.SchemaConfig{
 		Configs: periodConfigs,
 	}, overrides, prometheus.NewPedanticRegistry(), constants.Loki)
 	require.NoError(t, err)
@@ -123,6 +125,93 @@ func TestCompactor_RunCompaction(t *testing.T) {
 	}
 }
 
+type inProcessJobQueueClient struct {
+	queue *JobQueue
+}
+
+func (c inProcessJobQueueClient) Dequeue(ctx context.Context, req *compactor_grpc.DequeueRequest, _ ...grpc.CallOption) (*compactor_grpc.DequeueResponse, error) {
+	return c.queue.Dequeue(ctx, req)
+}
+
+func (c inProcessJobQueueClient) ReportJobStatus(ctx context.Context, req *compactor_grpc.ReportJobStatusRequest, _ ...grpc.CallOption) (*compactor_grpc.ReportJobStatusResponse, error) {
+	return c.queue.ReportJobStatus(ctx, req)
+}
+
+func TestCompactor_RunCompactionWithJobs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tablesPath := filepath.Join(tempDir, "index")
+
+	daySeconds := int64(24 * time.Hour / time.Second)
+	tableNumEnd := time.Now().Unix() / daySeconds
+	tableNumStart := tableNumEnd - 5
+
+	periodConfigs := []config.PeriodConfig{
+		{
+			From:       config.DayTime{Time: model.Time(0)},
+			IndexType:  "dummy",
+			ObjectType: "fs_01",
+			IndexTables: config.IndexPeriodicTableConfig{
+				PathPrefix: "index/",
+				PeriodicTableConfig: config.PeriodicTableConfig{
+					Prefix: indexTablePrefix,
+					Period: config.ObjectStorageIndexRequiredPeriod,
+				}},
+		},
+	}
+
+	// the tables with common index files are compacted by a job, the other ones by a job per user.
+	tableDBsConfig := func(i int64) (IndexesConfig, PerUserIndexesConfig) {
+		if i%2 == 0 {
+			return IndexesConfig{NumUnCompactedFiles: 5}, PerUserIndexesConfig{IndexesConfig: IndexesConfig{NumUnCompactedFiles: 5}, NumUsers: 3}
+		}
+		return IndexesConfig{}, PerUserIndexesConfig{IndexesConfig: IndexesConfig{NumCompactedFiles: 2}, NumUsers: 3}
+	}
+	for i := tableNumStart; i <= tableNumEnd; i++ {
+		commonDBsConfig, perUserDBsConfig := tableDBsConfig(i)
+		SetupTable(t, filepath.Join(tablesPath, fmt.Sprintf("%s%d", indexTablePrefix, i)), commonDBsConfig, perUserDBsConfig)
+	}
+
+	var (
+		objectClients = map[config.DayTime]client.ObjectClient{}
+		err           error
+	)
+	objectClients[periodConfigs[0].From], err = local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	compactor := setupTestCompactor(t, objectClients, periodConfigs, tempDir)
+	compactor.ringPollPeriod = 10 * time.Millisecond
+	compactor.cfg.MaxCompactionParallelism = 2
+	compactor.cfg.HorizontalScaling.Enabled = true
+	compactor.JobQueue, err = newJobQueue(compactor.cfg.HorizontalScaling, compactor.cfg.WorkingDirectory, compactor.metrics)
+	require.NoError(t, err)
+	compactor.jobQueueClient = func() (compactor_grpc.JobQueueClient, error) {
+		return inProcessJobQueueClient{compactor.JobQueue}, nil
+	}
+	compactor.JobQueue.start()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	compactor.runWorkers(ctx)
+	t.Cleanup(func() {
+		cancel()
+		compactor.workersWg.Wait()
+	})
+
+	err = compactor.RunCompaction(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, float64(3+3*3), testutil.ToFloat64(compactor.metrics.jobsProcessedTotal.WithLabelValues(statusSuccess)))
+
+	for i := tableNumStart; i <= tableNumEnd; i++ {
+		name := fmt.Sprintf("%s%d", indexTablePrefix, i)
+		commonDBsConfig, perUserDBsConfig := tableDBsConfig(i)
+		verifyCompactedIndexTable(t, commonDBsConfig, perUserDBsConfig, filepath.Join(tablesPath, name))
+	}
+
+	// the jobs of the compaction are no longer persisted once it is done.
+	compactor.JobQueue.start()
+	require.Nil(t, compactor.JobQueue.resumedPass(false))
+}
+
 func TestCompactor_RunCompactionMultipleStores(t *testing.T) {
 	tempDir := t.TempDir()
 
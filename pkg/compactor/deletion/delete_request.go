@@ -1,6 +1,8 @@
 package deletion
 
 import (
+	"errors"
+	"regexp"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -17,6 +19,15 @@ type timeInterval struct {
 	start, end time.Time
 }
 
+// Redaction rewrites the lines matched by a DeleteRequest, replacing the matches of Regex with Replacement,
+// instead of deleting them.
+type Redaction struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+
+	regexp *regexp.Regexp
+}
+
 type DeleteRequest struct {
 	RequestID string              `json:"request_id"`
 	StartTime model.Time          `json:"start_time"`
@@ -31,8 +42,12 @@ type DeleteRequest struct {
 	logSelectorExpr syntax.LogSelectorExpr `json:"-"`
 	timeInterval    *timeInterval          `json:"-"`
 
-	Metrics      *deleteRequestsManagerMetrics `json:"-"`
-	DeletedLines int32                         `json:"-"`
+	// Redaction is set for the requests redacting lines, which are then never deleted.
+	Redaction *Redaction `json:"redaction,omitempty"`
+
+	Metrics       *deleteRequestsManagerMetrics `json:"-"`
+	DeletedLines  int32                         `json:"-"`
+	RedactedLines int32                         `json:"-"`
 }
 
 func (d *DeleteRequest) SetQuery(logQL string) error {
@@ -46,8 +61,67 @@ func (d *DeleteRequest) SetQuery(logQL string) error {
 	return nil
 }
 
+// SetRedaction makes the DeleteRequest redact the matches of regex in the lines it matches, instead of deleting them.
+func (d *DeleteRequest) SetRedaction(regex, replacement string) error {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return err
+	}
+	d.Redaction = &Redaction{
+		Regex:       regex,
+		Replacement: replacement,
+		regexp:      re,
+	}
+	return nil
+}
+
 // FilterFunction returns a filter function that returns true if the given line should be deleted based on the DeleteRequest
 func (d *DeleteRequest) FilterFunction(lbls labels.Labels) (filter.Func, error) {
+	return d.filterFunction(lbls, func() {
+		if d.Metrics != nil {
+			d.Metrics.deletedLinesTotal.WithLabelValues(d.UserID).Inc()
+		}
+		d.DeletedLines++
+	})
+}
+
+// RewriteFunction returns a rewrite function that redacts the given line based on the DeleteRequest, if it has a Redaction.
+func (d *DeleteRequest) RewriteFunction(lbls labels.Labels) (filter.RewriteFunc, error) {
+	if d.Redaction == nil {
+		return nil, errors.New("delete request does not redact lines")
+	}
+	if d.Redaction.regexp == nil {
+		if err := d.SetRedaction(d.Redaction.Regex, d.Redaction.Replacement); err != nil {
+			return nil, err
+		}
+	}
+
+	ff, err := d.filterFunction(lbls, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ts time.Time, s string, structuredMetadata ...labels.Label) (string, bool) {
+		if !ff(ts, s, structuredMetadata...) {
+			return s, false
+		}
+
+		redacted := d.Redaction.regexp.ReplaceAllString(s, d.Redaction.Replacement)
+		if redacted == s {
+			return s, false
+		}
+
+		if d.Metrics != nil {
+			d.Metrics.redactedLinesTotal.WithLabelValues(d.UserID).Inc()
+		}
+		d.RedactedLines++
+		return redacted, true
+	}, nil
+}
+
+// filterFunction returns a filter function that returns true if the given line is matched by the DeleteRequest.
+// onFilterMatch is called for the lines matched by the line filters of the request.
+func (d *DeleteRequest) filterFunction(lbls labels.Labels, onFilterMatch func()) (filter.Func, error) {
 	// init d.timeInterval used to efficiently check log ts is within the bounds of delete request below in filter func
 	// without having to do conversion of timestamps for each log line we check.
 	if d.timeInterval == nil {
@@ -87,8 +161,7 @@ func (d *DeleteRequest) FilterFunction(lbls labels.Labels) (filter.Func, error)
 
 		result, _, skip := f(0, s, structuredMetadata...)
 		if len(result) != 0 || skip {
-			d.Metrics.deletedLinesTotal.WithLabelValues(d.UserID).Inc()
-			d.DeletedLines++
+			onFilterMatch()
 			return true
 		}
 		return false
@@ -107,11 +180,62 @@ func allMatch(matchers []*labels.Matcher, labels labels.Labels) bool {
 // IsDeleted checks if the given ChunkEntry will be deleted by this DeleteRequest.
 // It returns a filter.Func if the chunk is supposed to be deleted partially or the delete request contains line filters.
 // If the filter.Func is nil, the whole chunk is supposed to be deleted.
+// Requests with a Redaction never delete chunks.
 func (d *DeleteRequest) IsDeleted(entry retention.ChunkEntry) (bool, filter.Func) {
-	if d.UserID != unsafeGetString(entry.UserID) {
+	if d.Redaction != nil || !d.matchesChunk(entry) {
+		return false, nil
+	}
+
+	if d.StartTime <= entry.From && d.EndTime >= entry.Through && !d.logSelectorExpr.HasFilter() {
+		// Delete request covers the whole chunk and there are no line filters in the logSelectorExpr so the whole chunk will be deleted
+		return true, nil
+	}
+
+	ff, err := d.FilterFunction(entry.Labels)
+	if err != nil {
+		// The query in the delete request is checked when added to the table.
+		// So this error should not occur.
+		level.Error(util_log.Logger).Log(
+			"msg", "unexpected error getting filter function",
+			"delete_request_id", d.RequestID,
+			"user", d.UserID,
+			"err", err,
+		)
 		return false, nil
 	}
 
+	return true, ff
+}
+
+// IsRedacted checks if some lines of the given ChunkEntry will be redacted by this DeleteRequest,
+// and returns the filter.RewriteFunc redacting them.
+func (d *DeleteRequest) IsRedacted(entry retention.ChunkEntry) (bool, filter.RewriteFunc) {
+	if d.Redaction == nil || !d.matchesChunk(entry) {
+		return false, nil
+	}
+
+	rf, err := d.RewriteFunction(entry.Labels)
+	if err != nil {
+		// The query and the regex of the redaction are checked when added to the table.
+		// So this error should not occur.
+		level.Error(util_log.Logger).Log(
+			"msg", "unexpected error getting rewrite function",
+			"delete_request_id", d.RequestID,
+			"user", d.UserID,
+			"err", err,
+		)
+		return false, nil
+	}
+
+	return true, rf
+}
+
+// matchesChunk checks if the given ChunkEntry belongs to the user and streams of this DeleteRequest, and overlaps with it.
+func (d *DeleteRequest) matchesChunk(entry retention.ChunkEntry) bool {
+	if d.UserID != unsafeGetString(entry.UserID) {
+		return false
+	}
+
 	if !intervalsOverlap(model.Interval{
 		Start: entry.From,
 		End:   entry.Through,
@@ -119,7 +243,7 @@ func (d *DeleteRequest) IsDeleted(entry retention.ChunkEntry) (bool, filter.Func
 		Start: d.StartTime,
 		End:   d.EndTime,
 	}) {
-		return false, nil
+		return false
 	}
 
 	if d.logSelectorExpr == nil {
@@ -131,33 +255,15 @@ func (d *DeleteRequest) IsDeleted(entry retention.ChunkEntry) (bool, filter.Func
 				"user", d.UserID,
 				"err", err,
 			)
-			return false, nil
+			return false
 		}
 	}
 
 	if !labels.Selector(d.matchers).Matches(entry.Labels) {
-		return false, nil
-	}
-
-	if d.StartTime <= entry.From && d.EndTime >= entry.Through && !d.logSelectorExpr.HasFilter() {
-		// Delete request covers the whole chunk and there are no line filters in the logSelectorExpr so the whole chunk will be deleted
-		return true, nil
-	}
-
-	ff, err := d.FilterFunction(entry.Labels)
-	if err != nil {
-		// The query in the delete request is checked when added to the table.
-		// So this error should not occur.
-		level.Error(util_log.Logger).Log(
-			"msg", "unexpected error getting filter function",
-			"delete_request_id", d.RequestID,
-			"user", d.UserID,
-			"err", err,
-		)
-		return false, nil
+		return false
 	}
 
-	return true, ff
+	return true
 }
 
 func intervalsOverlap(interval1, interval2 model.Interval) bool {
@@ -432,3 +432,60 @@ func TestDeleteRequest_FilterFunction(t *testing.T) {
 		require.Panics(t, func() { testutil.ToFloat64(dr.Metrics.deletedLinesTotal) })
 	})
 }
+
+func TestDeleteRequest_RewriteFunction(t *testing.T) {
+	now := model.Now()
+	dr := DeleteRequest{
+		UserID:    "tenant1",
+		Query:     `{foo="bar"} |= "user"`,
+		Metrics:   newDeleteRequestsManagerMetrics(prometheus.NewPedanticRegistry()),
+		StartTime: now.Add(-time.Hour),
+		EndTime:   now,
+	}
+	require.NoError(t, dr.SetQuery(dr.Query))
+	require.NoError(t, dr.SetRedaction(`password=(\S+)`, "password=<$1 redacted>"))
+
+	chunkEntry := retention.ChunkEntry{
+		ChunkRef: retention.ChunkRef{
+			UserID:  []byte("tenant1"),
+			From:    now.Add(-2 * time.Hour),
+			Through: now.Add(-30 * time.Minute),
+		},
+		Labels: mustParseLabel(lblFooBar),
+	}
+
+	// requests redacting lines never delete chunks.
+	isDeleted, _ := dr.IsDeleted(chunkEntry)
+	require.False(t, isDeleted)
+
+	isRedacted, rf := dr.IsRedacted(chunkEntry)
+	require.True(t, isRedacted)
+	require.NotNil(t, rf)
+
+	line, ok := rf(now.Time(), "user password=secret logged in")
+	require.True(t, ok)
+	require.Equal(t, "user password=<secret redacted> logged in", line)
+
+	// lines not matched by the line filter, out of the request interval or without a match of the regex are kept as is.
+	for _, l := range []struct {
+		ts   time.Time
+		line string
+	}{
+		{now.Time(), "admin password=secret logged in"},
+		{now.Time().Add(-90 * time.Minute), "user password=secret logged in"},
+		{now.Time(), "user logged in"},
+	} {
+		line, ok := rf(l.ts, l.line)
+		require.False(t, ok)
+		require.Equal(t, l.line, line)
+	}
+
+	require.Equal(t, int32(1), dr.RedactedLines)
+	require.Equal(t, int32(0), dr.DeletedLines)
+	require.Equal(t, float64(1), testutil.ToFloat64(dr.Metrics.redactedLinesTotal))
+
+	// other streams are not redacted.
+	chunkEntry.Labels = mustParseLabel(`{foo="baz"}`)
+	isRedacted, _ = dr.IsRedacted(chunkEntry)
+	require.False(t, isRedacted)
+}
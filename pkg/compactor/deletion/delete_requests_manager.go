@@ -154,22 +154,80 @@ func (d *DeleteRequestsManager) loadDeleteRequestsToProcess() error {
 			"user", deleteRequest.UserID,
 		)
 
-		deleteRequest.Metrics = d.metrics
-
-		ur := d.requestsForUser(deleteRequest)
-		ur.requests = append(ur.requests, &deleteRequest)
-		if deleteRequest.StartTime < ur.requestsInterval.Start {
-			ur.requestsInterval.Start = deleteRequest.StartTime
-		}
-		if deleteRequest.EndTime > ur.requestsInterval.End {
-			ur.requestsInterval.End = deleteRequest.EndTime
-		}
+		d.addDeleteRequestToProcess(deleteRequest)
 		reqCount++
 	}
 
 	return nil
 }
 
+func (d *DeleteRequestsManager) addDeleteRequestToProcess(deleteRequest DeleteRequest) {
+	deleteRequest.Metrics = d.metrics
+
+	ur := d.requestsForUser(deleteRequest)
+	ur.requests = append(ur.requests, &deleteRequest)
+	if deleteRequest.StartTime < ur.requestsInterval.Start {
+		ur.requestsInterval.Start = deleteRequest.StartTime
+	}
+	if deleteRequest.EndTime > ur.requestsInterval.End {
+		ur.requestsInterval.End = deleteRequest.EndTime
+	}
+}
+
+// DeleteRequestsToProcess returns the delete requests loaded for processing by MarkPhaseStarted.
+func (d *DeleteRequestsManager) DeleteRequestsToProcess() []DeleteRequest {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	var deleteRequests []DeleteRequest
+	for _, ur := range d.deleteRequestsToProcess {
+		for _, deleteRequest := range ur.requests {
+			deleteRequests = append(deleteRequests, *deleteRequest)
+		}
+	}
+
+	sort.Slice(deleteRequests, func(i, j int) bool {
+		return deleteRequests[i].StartTime < deleteRequests[j].StartTime
+	})
+	return deleteRequests
+}
+
+// ResumeDeleteRequestsToProcess replaces the delete requests loaded by MarkPhaseStarted with the ones of an interrupted phase
+// which is resumed, for MarkPhaseFinished to only mark as processed the requests which were processed by the phase.
+func (d *DeleteRequestsManager) ResumeDeleteRequestsToProcess(deleteRequests []DeleteRequest) {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	d.deleteRequestsToProcess = map[string]*userDeleteRequests{}
+	for _, deleteRequest := range deleteRequests {
+		d.addDeleteRequestToProcess(deleteRequest)
+	}
+}
+
+// ExpirationCheckerFor returns an ExpirationChecker processing the given delete requests, loaded by another compactor.
+// Its phases are managed by that compactor, so the requests are never marked as processed by it.
+func (d *DeleteRequestsManager) ExpirationCheckerFor(deleteRequests []DeleteRequest) retention.ExpirationChecker {
+	checker := &DeleteRequestsManager{
+		deleteRequestsToProcess: map[string]*userDeleteRequests{},
+		metrics:                 d.metrics,
+		limits:                  d.limits,
+	}
+	for _, deleteRequest := range deleteRequests {
+		checker.addDeleteRequestToProcess(deleteRequest)
+	}
+	return deleteRequestsChecker{checker}
+}
+
+// deleteRequestsChecker processes a fixed set of delete requests, ignoring the phases.
+type deleteRequestsChecker struct {
+	*DeleteRequestsManager
+}
+
+func (deleteRequestsChecker) MarkPhaseStarted()  {}
+func (deleteRequestsChecker) MarkPhaseFailed()   {}
+func (deleteRequestsChecker) MarkPhaseTimedOut() {}
+func (deleteRequestsChecker) MarkPhaseFinished() {}
+
 func (d *DeleteRequestsManager) filteredSortedDeleteRequests() ([]DeleteRequest, error) {
 	deleteRequests, err := d.deleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusReceived)
 	if err != nil {
@@ -296,6 +354,46 @@ func (d *DeleteRequestsManager) Expired(ref retention.ChunkEntry, _ model.Time)
 	}
 }
 
+func (d *DeleteRequestsManager) Redacted(ref retention.ChunkEntry) (bool, filter.RewriteFunc) {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	userIDStr := unsafeGetString(ref.UserID)
+	if d.deleteRequestsToProcess[userIDStr] == nil || !intervalsOverlap(d.deleteRequestsToProcess[userIDStr].requestsInterval, model.Interval{
+		Start: ref.From,
+		End:   ref.Through,
+	}) {
+		return false, nil
+	}
+
+	var rewriteFuncs []filter.RewriteFunc
+
+	for _, deleteRequest := range d.deleteRequestsToProcess[userIDStr].requests {
+		isRedacted, rf := deleteRequest.IsRedacted(ref)
+		if !isRedacted {
+			continue
+		}
+		rewriteFuncs = append(rewriteFuncs, rf)
+	}
+
+	if len(rewriteFuncs) == 0 {
+		return false, nil
+	}
+
+	d.metrics.deleteRequestsChunksSelectedTotal.WithLabelValues(string(ref.UserID)).Inc()
+	return true, func(ts time.Time, s string, structuredMetadata ...labels.Label) (string, bool) {
+		rewritten := false
+		for _, rf := range rewriteFuncs {
+			if line, ok := rf(ts, s, structuredMetadata...); ok {
+				s = line
+				rewritten = true
+			}
+		}
+
+		return s, rewritten
+	}
+}
+
 func (d *DeleteRequestsManager) MarkPhaseStarted() {
 	status := statusSuccess
 	if err := d.loadDeleteRequestsToProcess(); err != nil {
@@ -330,6 +428,7 @@ func (d *DeleteRequestsManager) markRequestAsProcessed(deleteRequest DeleteReque
 			"user", deleteRequest.UserID,
 			"err", err,
 			"deleted_lines", deleteRequest.DeletedLines,
+			"redacted_lines", deleteRequest.RedactedLines,
 		)
 	} else {
 		level.Info(util_log.Logger).Log(
@@ -338,6 +437,7 @@ func (d *DeleteRequestsManager) markRequestAsProcessed(deleteRequest DeleteReque
 			"sequence_num", deleteRequest.SequenceNum,
 			"user", deleteRequest.UserID,
 			"deleted_lines", deleteRequest.DeletedLines,
+			"redacted_lines", deleteRequest.RedactedLines,
 		)
 		d.metrics.deleteRequestsProcessedTotal.WithLabelValues(deleteRequest.UserID).Inc()
 	}
@@ -1056,3 +1056,135 @@ func requestsAreEqual(req1, req2 DeleteRequest) bool {
 
 	return false
 }
+
+func TestDeleteRequestsManager_Redacted(t *testing.T) {
+	now := model.Now()
+	lblFoo, err := syntax.ParseLabels(`{foo="bar"}`)
+	require.NoError(t, err)
+
+	chunkEntry := retention.ChunkEntry{
+		ChunkRef: retention.ChunkRef{
+			UserID:  []byte(testUserID),
+			From:    now.Add(-12 * time.Hour),
+			Through: now.Add(-time.Hour),
+		},
+		Labels: lblFoo,
+	}
+
+	deleteRequests := []DeleteRequest{
+		{
+			UserID:    testUserID,
+			Query:     lblFoo.String(),
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now,
+			Status:    StatusReceived,
+		},
+		{
+			UserID:    testUserID,
+			Query:     lblFoo.String(),
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now,
+			Status:    StatusReceived,
+		},
+		{
+			UserID:    testUserID,
+			Query:     `{fizz="buzz"}`,
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now,
+			Status:    StatusReceived,
+		},
+	}
+	require.NoError(t, deleteRequests[0].SetRedaction(`password=\S+`, "password=<redacted>"))
+	require.NoError(t, deleteRequests[1].SetRedaction(`token=\S+`, "token=<redacted>"))
+	require.NoError(t, deleteRequests[2].SetRedaction(`.+`, "<redacted>"))
+
+	mockDeleteRequestsStore := &mockDeleteRequestsStore{deleteRequests: deleteRequests}
+	mgr := NewDeleteRequestsManager(mockDeleteRequestsStore, time.Hour, 70, &fakeLimits{defaultLimit: limit{
+		retentionPeriod: 7 * 24 * time.Hour,
+		deletionMode:    deletionmode.FilterAndDelete.String(),
+	}}, nil)
+	require.NoError(t, mgr.loadDeleteRequestsToProcess())
+
+	// redactions do not delete chunks.
+	isExpired, _ := mgr.Expired(chunkEntry, model.Now())
+	require.False(t, isExpired)
+
+	isRedacted, rewriteFunc := mgr.Redacted(chunkEntry)
+	require.True(t, isRedacted)
+
+	line, ok := rewriteFunc(now.Add(-2*time.Hour).Time(), "password=foo token=bar")
+	require.True(t, ok)
+	require.Equal(t, "password=<redacted> token=<redacted>", line)
+
+	line, ok = rewriteFunc(now.Add(-2*time.Hour).Time(), "nothing to redact")
+	require.False(t, ok)
+	require.Equal(t, "nothing to redact", line)
+
+	// chunks of other streams are not redacted.
+	chunkEntry.Labels = labels.FromStrings("foo", "baz")
+	isRedacted, _ = mgr.Redacted(chunkEntry)
+	require.False(t, isRedacted)
+
+	mgr.MarkPhaseFinished()
+	processedRequests, err := mockDeleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusProcessed)
+	require.NoError(t, err)
+	require.Len(t, processedRequests, 3)
+}
+
+func TestDeleteRequestsManager_ExpirationCheckerFor(t *testing.T) {
+	now := model.Now()
+	lblFoo, err := syntax.ParseLabels(`{foo="bar"}`)
+	require.NoError(t, err)
+
+	chunkEntry := retention.ChunkEntry{
+		ChunkRef: retention.ChunkRef{
+			UserID:  []byte(testUserID),
+			From:    now.Add(-12 * time.Hour),
+			Through: now.Add(-time.Hour),
+		},
+		Labels: lblFoo,
+	}
+
+	deleteRequests := []DeleteRequest{
+		{
+			UserID:    testUserID,
+			Query:     lblFoo.String(),
+			StartTime: now.Add(-2 * time.Hour),
+			EndTime:   now,
+			Status:    StatusReceived,
+		},
+		{
+			UserID:    testUserID,
+			Query:     lblFoo.String(),
+			StartTime: now.Add(-24 * time.Hour),
+			EndTime:   now,
+			Status:    StatusReceived,
+		},
+	}
+
+	mockDeleteRequestsStore := &mockDeleteRequestsStore{deleteRequests: deleteRequests}
+	mgr := NewDeleteRequestsManager(mockDeleteRequestsStore, time.Hour, 70, &fakeLimits{defaultLimit: limit{
+		retentionPeriod: 7 * 24 * time.Hour,
+		deletionMode:    deletionmode.FilterAndDelete.String(),
+	}}, nil)
+	mgr.MarkPhaseStarted()
+
+	// the requests to process are shipped to the workers, sorted by start time.
+	toProcess := mgr.DeleteRequestsToProcess()
+	require.Len(t, toProcess, 2)
+	require.Equal(t, now.Add(-24*time.Hour), toProcess[0].StartTime)
+
+	checker := mgr.ExpirationCheckerFor(toProcess[:1])
+	isExpired, _ := checker.Expired(chunkEntry, model.Now())
+	require.True(t, isExpired)
+	require.True(t, checker.IntervalMayHaveExpiredChunks(model.Interval{Start: chunkEntry.From, End: chunkEntry.Through}, testUserID))
+	require.False(t, checker.IntervalMayHaveExpiredChunks(model.Interval{Start: chunkEntry.From, End: chunkEntry.Through}, "other-user"))
+
+	// the phases of the requests are managed by the manager which loaded them.
+	checker.MarkPhaseFinished()
+	require.Len(t, mgr.DeleteRequestsToProcess(), 2)
+
+	// a resumed phase only processes the requests of the interrupted phase.
+	mgr.ResumeDeleteRequestsToProcess(toProcess[1:])
+	require.Len(t, mgr.DeleteRequestsToProcess(), 1)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash/fnv"
@@ -33,6 +34,7 @@ const (
 	deleteRequestID      indexType = "1"
 	deleteRequestDetails indexType = "2"
 	cacheGenNum          indexType = "3"
+	redactionDetails     indexType = "4"
 
 	tempFileSuffix          = ".temp"
 	DeleteRequestsTableName = "delete_requests"
@@ -130,6 +132,11 @@ func (ds *deleteRequestsStore) writeDeleteRequest(req DeleteRequest, writeBatch
 	rangeValue := fmt.Sprintf("%x:%x:%x", int64(ds.now()), int64(req.StartTime), int64(req.EndTime))
 	writeBatch.Add(DeleteRequestsTableName, fmt.Sprintf("%s:%s", deleteRequestDetails, userIDAndRequestID), []byte(rangeValue), []byte(req.Query))
 
+	// Add the regex and replacement of redaction requests in a separate entry, read along with the details.
+	if req.Redaction != nil {
+		writeBatch.Add(DeleteRequestsTableName, fmt.Sprintf("%s:%s", redactionDetails, userIDAndRequestID), []byte(rangeValue), marshalRedaction(req.Redaction))
+	}
+
 	// create a gen number for this result
 	writeBatch.Add(DeleteRequestsTableName, fmt.Sprintf("%s:%s", cacheGenNum, req.UserID), []byte{}, generateCacheGenNumber())
 }
@@ -308,9 +315,43 @@ func (ds *deleteRequestsStore) queryDeleteRequestDetails(ctx context.Context, de
 		return DeleteRequest{}, err
 	}
 
+	redactionQuery := []index.Query{
+		{
+			TableName: DeleteRequestsTableName,
+			HashValue: fmt.Sprintf("%s:%s", redactionDetails, userIDAndRequestID),
+		},
+	}
+	err = ds.indexClient.QueryPages(ctx, redactionQuery, func(query index.Query, batch index.ReadBatchResult) (shouldContinue bool) {
+		itr := batch.Iterator()
+		if itr.Next() {
+			marshalError = unmarshalRedaction(itr.Value(), &requestWithDetails)
+		}
+		return false
+	})
+	if err != nil {
+		return DeleteRequest{}, err
+	}
+	if marshalError != nil {
+		return DeleteRequest{}, marshalError
+	}
+
 	return requestWithDetails, nil
 }
 
+func marshalRedaction(redaction *Redaction) []byte {
+	// json.Marshal cannot fail on a struct of strings.
+	buf, _ := json.Marshal(redaction)
+	return buf
+}
+
+func unmarshalRedaction(buf []byte, req *DeleteRequest) error {
+	var redaction Redaction
+	if err := json.Unmarshal(buf, &redaction); err != nil {
+		return fmt.Errorf("invalid redaction of delete request %s: %w", req.RequestID, err)
+	}
+	return req.SetRedaction(redaction.Regex, redaction.Replacement)
+}
+
 func unmarshalDeleteRequestDetails(itr index.ReadBatchIterator, req DeleteRequest) (DeleteRequest, error) {
 	itr.Next()
 
@@ -344,6 +385,9 @@ func (ds *deleteRequestsStore) removeRequest(req DeleteRequest, writeBatch index
 	// Add another entry with additional details like creation time, time range of delete request and selectors in value
 	rangeValue := fmt.Sprintf("%x:%x:%x", int64(req.CreatedAt), int64(req.StartTime), int64(req.EndTime))
 	writeBatch.Delete(DeleteRequestsTableName, fmt.Sprintf("%s:%s", deleteRequestDetails, userIDAndRequestID), []byte(rangeValue))
+	if req.Redaction != nil {
+		writeBatch.Delete(DeleteRequestsTableName, fmt.Sprintf("%s:%s", redactionDetails, userIDAndRequestID), []byte(rangeValue))
+	}
 
 	// ensure caches are invalidated
 	writeBatch.Add(DeleteRequestsTableName, fmt.Sprintf("%s:%s", cacheGenNum, req.UserID), []byte{}, []byte(strconv.FormatInt(time.Now().UnixNano(), 10)))
@@ -192,6 +192,33 @@ func TestBatchCreateGet(t *testing.T) {
 		require.ErrorIs(t, err, ErrDeleteRequestNotFound)
 		require.Empty(t, results)
 	})
+
+	t.Run("stores the redactions of the requests", func(t *testing.T) {
+		tc := setup(t)
+		defer tc.store.Stop()
+
+		for i := range tc.user1Requests {
+			require.NoError(t, tc.user1Requests[i].SetRedaction(`password=\S+`, "password=<redacted>"))
+		}
+		savedRequests, err := tc.store.AddDeleteRequestGroup(context.Background(), tc.user1Requests)
+		require.NoError(t, err)
+		_, err = tc.store.AddDeleteRequestGroup(context.Background(), tc.user2Requests)
+		require.NoError(t, err)
+
+		results, err := tc.store.GetDeleteRequestGroup(context.Background(), savedRequests[0].UserID, savedRequests[0].RequestID)
+		require.NoError(t, err)
+		require.Equal(t, savedRequests, results)
+
+		user2Requests, err := tc.store.GetAllDeleteRequestsForUser(context.Background(), user2)
+		require.NoError(t, err)
+		for _, req := range user2Requests {
+			require.Nil(t, req.Redaction)
+		}
+
+		require.NoError(t, tc.store.RemoveDeleteRequests(context.Background(), savedRequests))
+		_, err = tc.store.GetDeleteRequestGroup(context.Background(), savedRequests[0].UserID, savedRequests[0].RequestID)
+		require.ErrorIs(t, err, ErrDeleteRequestNotFound)
+	})
 }
 
 func compareRequests(t *testing.T, expected []DeleteRequest, actual []DeleteRequest) {
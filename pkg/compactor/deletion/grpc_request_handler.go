@@ -64,6 +64,12 @@ func (g *GRPCRequestHandler) GetDeleteRequests(ctx context.Context, _ *grpc.GetD
 			Status:    string(dr.Status),
 			CreatedAt: int64(dr.CreatedAt),
 		}
+		if dr.Redaction != nil {
+			resp.DeleteRequests[i].Redaction = &grpc.Redaction{
+				Regex:       dr.Redaction.Regex,
+				Replacement: dr.Redaction.Replacement,
+			}
+		}
 	}
 
 	return &resp, nil
@@ -0,0 +1,244 @@
+package deletion
+
+import (
+	"context"
+	"flag"
+	"sort"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/fetcher"
+	"github.com/grafana/loki/v3/pkg/util/filter"
+)
+
+// PreviewConfig configures the dry-run mode of the delete API.
+type PreviewConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxChunks int  `yaml:"max_chunks"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *PreviewConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Allow previewing the impact of delete requests with the dry_run parameter of the delete API. The compactor then reads the index and chunks of the store, like the queriers do, to report the streams, chunks and lines which would be deleted, without adding the delete request. Only the chunks flushed to the store are previewed, not the lines still held by the ingesters.")
+	f.IntVar(&cfg.MaxChunks, prefix+"max-chunks", 100, "Experimental: Maximum number of chunks read to preview a delete request. When more chunks are matched, the number of lines and bytes deleted is extrapolated from the chunks read. 0 to read all the matched chunks.")
+}
+
+// PreviewStore is the chunk store the impact of delete requests is previewed against.
+type PreviewStore interface {
+	GetChunks(ctx context.Context, userID string, from, through model.Time, predicate chunk.Predicate) ([][]chunk.Chunk, []*fetcher.Fetcher, error)
+}
+
+// DeletePreview is the impact report of a delete request which has not been added.
+// For requests redacting lines, the lines are the ones which would be redacted.
+type DeletePreview struct {
+	// Streams are the streams with chunks matched by the delete request.
+	Streams []string `json:"streams"`
+	// Chunks is the number of chunks matched by the delete request, ChunksRead how many of them were read.
+	Chunks     int `json:"chunks"`
+	ChunksRead int `json:"chunks_read"`
+	// Lines and Bytes are the number of lines, and their size, which would be deleted.
+	// They are extrapolated from the chunks read when not all the matched chunks were read.
+	Lines     int64 `json:"lines"`
+	Bytes     int64 `json:"bytes"`
+	Estimated bool  `json:"estimated"`
+	// Sample are some of the lines which would be deleted, or redacted lines.
+	Sample []PreviewLine `json:"sample"`
+}
+
+// PreviewLine is a line which would be deleted by a delete request.
+type PreviewLine struct {
+	Stream    string    `json:"stream"`
+	Timestamp time.Time `json:"timestamp"`
+	Line      string    `json:"line"`
+}
+
+// DeletePreviewer runs the filters of delete requests against the chunks of the store, without deleting anything.
+type DeletePreviewer struct {
+	store     PreviewStore
+	maxChunks int
+}
+
+// NewDeletePreviewer creates a DeletePreviewer reading at most maxChunks chunks per preview.
+// The lines of the other matched chunks are extrapolated from the ones read.
+func NewDeletePreviewer(store PreviewStore, maxChunks int) *DeletePreviewer {
+	return &DeletePreviewer{
+		store:     store,
+		maxChunks: maxChunks,
+	}
+}
+
+type previewChunk struct {
+	chunk   chunk.Chunk
+	fetcher *fetcher.Fetcher
+	// filters of the delete requests matching the chunk. A nil filter deletes the whole chunk.
+	filters []filter.Func
+	// rewrites of the requests redacting lines of the chunk.
+	rewrites []filter.RewriteFunc
+}
+
+// Preview returns the impact of the given delete requests, which are the shards of a single delete request.
+func (p *DeletePreviewer) Preview(ctx context.Context, deleteRequests []DeleteRequest, sampleSize int) (*DeletePreview, error) {
+	var (
+		chunks  []*previewChunk
+		byRef   = map[logproto.ChunkRef]*previewChunk{}
+		streams = map[string]struct{}{}
+	)
+	for i := range deleteRequests {
+		req := &deleteRequests[i]
+		if err := req.SetQuery(req.Query); err != nil {
+			return nil, err
+		}
+
+		ctx := user.InjectOrgID(ctx, req.UserID)
+		groups, fetchers, err := p.store.GetChunks(ctx, req.UserID, req.StartTime, req.EndTime, chunk.NewPredicate(req.matchers, nil))
+		if err != nil {
+			return nil, err
+		}
+
+		for g, group := range groups {
+			for _, c := range group {
+				entry := retention.ChunkEntry{
+					ChunkRef: retention.ChunkRef{
+						UserID:  []byte(c.UserID),
+						From:    c.From,
+						Through: c.Through,
+					},
+					Labels: c.Metric,
+				}
+				deleted, ff := req.IsDeleted(entry)
+				redacted, rf := req.IsRedacted(entry)
+				if !deleted && !redacted {
+					continue
+				}
+
+				pc, ok := byRef[c.ChunkRef]
+				if !ok {
+					pc = &previewChunk{chunk: c, fetcher: fetchers[g]}
+					byRef[c.ChunkRef] = pc
+					chunks = append(chunks, pc)
+					streams[streamLabels(c.Metric).String()] = struct{}{}
+				}
+				if deleted {
+					pc.filters = append(pc.filters, ff)
+				} else {
+					pc.rewrites = append(pc.rewrites, rf)
+				}
+			}
+		}
+	}
+
+	preview := &DeletePreview{
+		Streams: make([]string, 0, len(streams)),
+		Chunks:  len(chunks),
+		Sample:  []PreviewLine{},
+	}
+	for s := range streams {
+		preview.Streams = append(preview.Streams, s)
+	}
+	sort.Strings(preview.Streams)
+
+	toRead := p.chunksToRead(chunks)
+	for _, pc := range toRead {
+		if err := p.readChunk(ctx, pc, preview, sampleSize); err != nil {
+			return nil, err
+		}
+	}
+	preview.ChunksRead = len(toRead)
+
+	if preview.ChunksRead < preview.Chunks {
+		preview.Estimated = true
+		preview.Lines = preview.Lines * int64(preview.Chunks) / int64(preview.ChunksRead)
+		preview.Bytes = preview.Bytes * int64(preview.Chunks) / int64(preview.ChunksRead)
+	}
+
+	return preview, nil
+}
+
+// chunksToRead returns at most maxChunks chunks, spread evenly across the matched chunks.
+func (p *DeletePreviewer) chunksToRead(chunks []*previewChunk) []*previewChunk {
+	if p.maxChunks <= 0 || len(chunks) <= p.maxChunks {
+		return chunks
+	}
+
+	toRead := make([]*previewChunk, 0, p.maxChunks)
+	for i := 0; i < p.maxChunks; i++ {
+		toRead = append(toRead, chunks[i*len(chunks)/p.maxChunks])
+	}
+	return toRead
+}
+
+func (p *DeletePreviewer) readChunk(ctx context.Context, pc *previewChunk, preview *DeletePreview, sampleSize int) error {
+	ctx = user.InjectOrgID(ctx, pc.chunk.UserID)
+	fetched, err := pc.fetcher.FetchChunks(ctx, []chunk.Chunk{pc.chunk})
+	if err != nil {
+		return err
+	}
+
+	stream := streamLabels(pc.chunk.Metric)
+	for _, c := range fetched {
+		it, err := c.Data.(*chunkenc.Facade).LokiChunk().Iterator(
+			ctx,
+			c.From.Time(),
+			c.Through.Time().Add(time.Nanosecond),
+			logproto.FORWARD,
+			log.NewNoopPipeline().ForStream(stream),
+		)
+		if err != nil {
+			return err
+		}
+
+		for it.Next() {
+			entry := it.Entry()
+			line, ok := previewLine(pc, entry)
+			if !ok {
+				continue
+			}
+
+			preview.Lines++
+			preview.Bytes += int64(len(entry.Line))
+			if len(preview.Sample) < sampleSize {
+				preview.Sample = append(preview.Sample, PreviewLine{
+					Stream:    stream.String(),
+					Timestamp: entry.Timestamp,
+					Line:      line,
+				})
+			}
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// previewLine tells if the line of the entry is deleted or redacted.
+// It returns the line, redacted by the requests redacting lines.
+func previewLine(pc *previewChunk, entry logproto.Entry) (string, bool) {
+	structuredMetadata := logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)
+	for _, ff := range pc.filters {
+		if ff == nil || ff(entry.Timestamp, entry.Line, structuredMetadata...) {
+			return entry.Line, true
+		}
+	}
+
+	line, redacted := entry.Line, false
+	for _, rf := range pc.rewrites {
+		if rewritten, ok := rf(entry.Timestamp, line, structuredMetadata...); ok {
+			line, redacted = rewritten, true
+		}
+	}
+	return line, redacted
+}
+
+func streamLabels(metric labels.Labels) labels.Labels {
+	return labels.NewBuilder(metric).Del(labels.MetricName).Labels()
+}
@@ -0,0 +1,193 @@
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/fetcher"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+)
+
+type mockPreviewStore struct {
+	chunks  []chunk.Chunk
+	fetcher *fetcher.Fetcher
+}
+
+func newMockPreviewStore(t *testing.T, chunks ...chunk.Chunk) *mockPreviewStore {
+	f, err := fetcher.New(cache.NewNoopCache(), nil, false, config.SchemaConfig{}, &mockChunkClient{chunks: chunks}, 0)
+	require.NoError(t, err)
+	t.Cleanup(f.Stop)
+	return &mockPreviewStore{chunks: chunks, fetcher: f}
+}
+
+func (m *mockPreviewStore) GetChunks(_ context.Context, userID string, from, through model.Time, predicate chunk.Predicate) ([][]chunk.Chunk, []*fetcher.Fetcher, error) {
+	var refs []chunk.Chunk
+	for _, c := range m.chunks {
+		if c.UserID != userID || c.Through < from || c.From > through || !labels.Selector(predicate.Matchers).Matches(c.Metric) {
+			continue
+		}
+		refs = append(refs, chunk.Chunk{ChunkRef: c.ChunkRef, Metric: c.Metric})
+	}
+	return [][]chunk.Chunk{refs}, []*fetcher.Fetcher{m.fetcher}, nil
+}
+
+type mockChunkClient struct {
+	chunks []chunk.Chunk
+}
+
+func (m *mockChunkClient) Stop() {}
+
+func (m *mockChunkClient) PutChunks(_ context.Context, _ []chunk.Chunk) error { return nil }
+
+func (m *mockChunkClient) GetChunks(_ context.Context, refs []chunk.Chunk) ([]chunk.Chunk, error) {
+	var chunks []chunk.Chunk
+	for _, ref := range refs {
+		for _, c := range m.chunks {
+			if c.ChunkRef == ref.ChunkRef {
+				chunks = append(chunks, c)
+			}
+		}
+	}
+	return chunks, nil
+}
+
+func (m *mockChunkClient) DeleteChunk(_ context.Context, _, _ string) error { return nil }
+
+func (m *mockChunkClient) IsChunkNotFoundErr(_ error) bool { return false }
+
+func (m *mockChunkClient) IsRetryableErr(_ error) bool { return false }
+
+// newPreviewChunk returns a chunk of the given stream with a line per second within [from, through].
+func newPreviewChunk(t *testing.T, userID, stream string, from, through model.Time) chunk.Chunk {
+	expr, err := parseDeletionQuery(stream)
+	require.NoError(t, err)
+	b := labels.NewBuilder(labels.FromStrings(labels.MetricName, "logs"))
+	for _, m := range expr.Matchers() {
+		b.Set(m.Name, m.Value)
+	}
+	metric := b.Labels()
+
+	chk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+	for ts := from; ts <= through; ts = ts.Add(time.Second) {
+		require.NoError(t, chk.Append(&logproto.Entry{
+			Timestamp: ts.Time(),
+			Line:      fmt.Sprintf("line %d", ts.Unix()),
+		}))
+	}
+	require.NoError(t, chk.Close())
+
+	c := chunk.NewChunk(userID, client.Fingerprint(metric), metric, chunkenc.NewFacade(chk, 0, 0), from, through)
+	require.NoError(t, c.Encode())
+	return c
+}
+
+func TestDeletePreviewer(t *testing.T) {
+	// Aligned for the chunks to have 6 lines with a timestamp ending with 0.
+	now := model.TimeFromUnix(time.Now().Unix()/10*10 - 5)
+	store := newMockPreviewStore(t,
+		newPreviewChunk(t, "org-id", `{foo="bar"}`, now.Add(-2*time.Hour), now.Add(-2*time.Hour+time.Minute)),
+		newPreviewChunk(t, "org-id", `{foo="bar"}`, now.Add(-time.Hour), now.Add(-time.Hour+time.Minute)),
+		newPreviewChunk(t, "org-id", `{foo="bar", bar="baz"}`, now.Add(-time.Hour), now.Add(-time.Hour+time.Minute)),
+		newPreviewChunk(t, "org-id", `{foo="baz"}`, now.Add(-time.Hour), now.Add(-time.Hour+time.Minute)),
+		newPreviewChunk(t, "other-org", `{foo="bar"}`, now.Add(-time.Hour), now.Add(-time.Hour+time.Minute)),
+	)
+
+	for _, tc := range []struct {
+		name                   string
+		query                  string
+		start, end             model.Time
+		interval               time.Duration
+		maxChunks, sampleSize  int
+		expectedStreams        []string
+		expectedChunks         int
+		expectedLines          int64
+		expectedEstimated      bool
+		expectedSample         int
+		expectedSampleLineEnds string
+	}{
+		{
+			name:            "whole chunks",
+			query:           `{foo="bar"}`,
+			start:           now.Add(-3 * time.Hour),
+			end:             now,
+			interval:        3 * time.Hour,
+			sampleSize:      5,
+			expectedStreams: []string{`{bar="baz", foo="bar"}`, `{foo="bar"}`},
+			expectedChunks:  3,
+			expectedLines:   3 * 61,
+			expectedSample:  5,
+		},
+		{
+			name:            "partial chunks",
+			query:           `{foo="bar"}`,
+			start:           now.Add(-time.Hour),
+			end:             now.Add(-time.Hour + 9*time.Second),
+			interval:        time.Hour,
+			sampleSize:      100,
+			expectedStreams: []string{`{bar="baz", foo="bar"}`, `{foo="bar"}`},
+			expectedChunks:  2,
+			expectedLines:   2 * 10,
+			expectedSample:  20,
+		},
+		{
+			name:                   "line filter",
+			query:                  `{foo="bar"} |~ "0$"`,
+			start:                  now.Add(-3 * time.Hour),
+			end:                    now,
+			interval:               time.Hour,
+			sampleSize:             100,
+			expectedStreams:        []string{`{bar="baz", foo="bar"}`, `{foo="bar"}`},
+			expectedChunks:         3,
+			expectedSample:         3 * 6,
+			expectedSampleLineEnds: "0",
+		},
+		{
+			name:              "estimated",
+			query:             `{foo=~"ba.+"}`,
+			start:             now.Add(-3 * time.Hour),
+			end:               now,
+			interval:          3 * time.Hour,
+			maxChunks:         2,
+			expectedStreams:   []string{`{bar="baz", foo="bar"}`, `{foo="bar"}`, `{foo="baz"}`},
+			expectedChunks:    4,
+			expectedLines:     4 * 61,
+			expectedEstimated: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deleteRequests := shardDeleteRequestsByInterval(tc.start, tc.end, tc.query, "org-id", tc.interval)
+			preview, err := NewDeletePreviewer(store, tc.maxChunks).Preview(context.Background(), deleteRequests, tc.sampleSize)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expectedStreams, preview.Streams)
+			require.Equal(t, tc.expectedChunks, preview.Chunks)
+			require.Equal(t, tc.expectedEstimated, preview.Estimated)
+			require.Len(t, preview.Sample, tc.expectedSample)
+			if tc.expectedLines != 0 {
+				require.Equal(t, tc.expectedLines, preview.Lines)
+			}
+			if tc.expectedEstimated {
+				require.Equal(t, tc.maxChunks, preview.ChunksRead)
+			} else {
+				require.Equal(t, preview.Chunks, preview.ChunksRead)
+			}
+			for _, l := range preview.Sample {
+				require.True(t, strings.HasSuffix(l.Line, tc.expectedSampleLineEnds))
+				require.False(t, l.Timestamp.Before(tc.start.Time()))
+				require.False(t, l.Timestamp.After(tc.end.Time()))
+			}
+		})
+	}
+}
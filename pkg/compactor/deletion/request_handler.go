@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -20,11 +21,19 @@ import (
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
+const (
+	// defaultPreviewSampleLines is the number of lines sampled by dry runs without a sample_lines parameter.
+	defaultPreviewSampleLines = 10
+	// defaultRedactionReplacement replaces the matches of redaction requests without a replacement parameter.
+	defaultRedactionReplacement = "<redacted>"
+)
+
 // DeleteRequestHandler provides handlers for delete requests
 type DeleteRequestHandler struct {
 	deleteRequestsStore DeleteRequestsStore
 	metrics             *deleteRequestHandlerMetrics
 	maxInterval         time.Duration
+	previewer           *DeletePreviewer
 }
 
 // NewDeleteRequestHandler creates a DeleteRequestHandler
@@ -38,6 +47,11 @@ func NewDeleteRequestHandler(deleteStore DeleteRequestsStore, maxInterval time.D
 	return &deleteMgr
 }
 
+// SetPreviewer enables the dry-run mode of AddDeleteRequestHandler, previewing delete requests with the given DeletePreviewer.
+func (dm *DeleteRequestHandler) SetPreviewer(previewer *DeletePreviewer) {
+	dm.previewer = previewer
+}
+
 // AddDeleteRequestHandler handles addition of a new delete request
 func (dm *DeleteRequestHandler) AddDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -78,7 +92,27 @@ func (dm *DeleteRequestHandler) AddDeleteRequestHandler(w http.ResponseWriter, r
 		shardByInterval = endTime.Sub(startTime) + time.Minute
 	}
 
+	redaction, err := redaction(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	deleteRequests := shardDeleteRequestsByInterval(startTime, endTime, query, userID, shardByInterval)
+	for i := range deleteRequests {
+		deleteRequests[i].Redaction = redaction
+	}
+
+	dryRun, err := dryRun(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if dryRun {
+		dm.previewDeleteRequests(w, r, deleteRequests)
+		return
+	}
+
 	createdDeleteRequests, err := dm.deleteRequestsStore.AddDeleteRequestGroup(ctx, deleteRequests)
 	if err != nil {
 		level.Error(util_log.Logger).Log("msg", "error adding delete request to the store", "err", err)
@@ -98,12 +132,39 @@ func (dm *DeleteRequestHandler) AddDeleteRequestHandler(w http.ResponseWriter, r
 		"user", userID,
 		"query", query,
 		"interval", shardByInterval.String(),
+		"redact", redaction != nil,
 	)
 
 	dm.metrics.deleteRequestsReceivedTotal.WithLabelValues(userID).Inc()
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// previewDeleteRequests writes the impact of the delete requests, without adding them.
+func (dm *DeleteRequestHandler) previewDeleteRequests(w http.ResponseWriter, r *http.Request, deleteRequests []DeleteRequest) {
+	if dm.previewer == nil {
+		http.Error(w, "dry_run is not enabled, see -compactor.delete-request-dry-run.enabled", http.StatusBadRequest)
+		return
+	}
+
+	sampleSize, err := sampleLines(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preview, err := dm.previewer.Preview(r.Context(), deleteRequests, sampleSize)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "error previewing delete request", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		level.Error(util_log.Logger).Log("msg", "error marshalling delete request preview", "err", err)
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+	}
+}
+
 func shardDeleteRequestsByInterval(startTime, endTime model.Time, query, userID string, interval time.Duration) []DeleteRequest {
 	deleteRequests := make([]DeleteRequest, 0, endTime.Sub(startTime)/interval)
 	for start := startTime; start.Before(endTime); start = start.Add(interval) + 1 {
@@ -386,3 +447,55 @@ func timeFromInt(in string) (int64, error) {
 
 	return util.ParseTime(in)
 }
+
+func dryRun(params url.Values) (bool, error) {
+	dryRunParam := params.Get("dry_run")
+	if dryRunParam == "" {
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(dryRunParam)
+	if err != nil {
+		return false, errors.New("invalid dry_run: require a boolean")
+	}
+
+	return dryRun, nil
+}
+
+func sampleLines(params url.Values) (int, error) {
+	sampleParam := params.Get("sample_lines")
+	if sampleParam == "" {
+		return defaultPreviewSampleLines, nil
+	}
+
+	sampleLines, err := strconv.Atoi(sampleParam)
+	if err != nil || sampleLines < 0 {
+		return 0, errors.New("invalid sample_lines: require a positive integer")
+	}
+
+	return sampleLines, nil
+}
+
+// redaction returns the Redaction of the delete requests redacting lines, or nil for the ones deleting them.
+func redaction(params url.Values) (*Redaction, error) {
+	regex := params.Get("redact")
+	if regex == "" {
+		if params.Has("replacement") {
+			return nil, errors.New("replacement is only valid with redact")
+		}
+		return nil, nil
+	}
+
+	var req DeleteRequest
+	if err := req.SetRedaction(regex, params.Get("replacement")); err != nil {
+		return nil, fmt.Errorf("invalid redact regex: %w", err)
+	}
+	if req.Redaction.regexp.MatchString("") {
+		return nil, errors.New("invalid redact regex: it must not match empty strings")
+	}
+	if !params.Has("replacement") {
+		req.Redaction.Replacement = defaultRedactionReplacement
+	}
+
+	return req.Redaction, nil
+}
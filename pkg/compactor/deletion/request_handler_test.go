@@ -171,6 +171,95 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 		require.Equal(t, w.Code, http.StatusInternalServerError)
 	})
 
+	t.Run("it previews the delete request without adding it on dry runs", func(t *testing.T) {
+		now := model.Now()
+		store := &mockDeleteRequestsStore{}
+		h := NewDeleteRequestHandler(store, 0, nil)
+		h.SetPreviewer(NewDeletePreviewer(newMockPreviewStore(t,
+			newPreviewChunk(t, "org-id", `{foo="bar"}`, now.Add(-time.Hour), now.Add(-time.Hour+time.Minute)),
+		), 0))
+
+		req := buildRequest("org-id", `{foo="bar"}`, unixString(now.Add(-2*time.Hour)), unixString(now))
+		params := req.URL.Query()
+		params.Set("dry_run", "true")
+		params.Set("sample_lines", "2")
+		req.URL.RawQuery = params.Encode()
+
+		w := httptest.NewRecorder()
+		h.AddDeleteRequestHandler(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, store.addReqs)
+
+		var preview DeletePreview
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+		require.Equal(t, []string{`{foo="bar"}`}, preview.Streams)
+		require.Equal(t, 1, preview.Chunks)
+		require.Equal(t, int64(61), preview.Lines)
+		require.Len(t, preview.Sample, 2)
+	})
+
+	t.Run("dry runs are rejected when not enabled", func(t *testing.T) {
+		store := &mockDeleteRequestsStore{}
+		h := NewDeleteRequestHandler(store, 0, nil)
+
+		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
+		params := req.URL.Query()
+		params.Set("dry_run", "true")
+		req.URL.RawQuery = params.Encode()
+
+		w := httptest.NewRecorder()
+		h.AddDeleteRequestHandler(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, store.addReqs)
+	})
+
+	t.Run("it adds requests redacting lines", func(t *testing.T) {
+		store := &mockDeleteRequestsStore{}
+		h := NewDeleteRequestHandler(store, 0, nil)
+
+		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
+		params := req.URL.Query()
+		params.Set("redact", `password=\S+`)
+		req.URL.RawQuery = params.Encode()
+
+		w := httptest.NewRecorder()
+		h.AddDeleteRequestHandler(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		require.NotNil(t, store.addReqs[0].Redaction)
+		require.Equal(t, `password=\S+`, store.addReqs[0].Redaction.Regex)
+		require.Equal(t, "<redacted>", store.addReqs[0].Redaction.Replacement)
+	})
+
+	t.Run("invalid redactions are rejected", func(t *testing.T) {
+		for _, tc := range []struct {
+			redact, replacement, error string
+		}{
+			{"", "***", "replacement is only valid with redact\n"},
+			{"(", "", "invalid redact regex: error parsing regexp: missing closing ): `(`\n"},
+			{"a*", "", "invalid redact regex: it must not match empty strings\n"},
+		} {
+			t.Run(strings.TrimSpace(tc.error), func(t *testing.T) {
+				store := &mockDeleteRequestsStore{}
+				h := NewDeleteRequestHandler(store, 0, nil)
+
+				req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
+				params := req.URL.Query()
+				if tc.redact != "" {
+					params.Set("redact", tc.redact)
+				}
+				params.Set("replacement", tc.replacement)
+				req.URL.RawQuery = params.Encode()
+
+				w := httptest.NewRecorder()
+				h.AddDeleteRequestHandler(w, req)
+				require.Equal(t, http.StatusBadRequest, w.Code)
+				require.Equal(t, tc.error, w.Body.String())
+				require.Empty(t, store.addReqs)
+			})
+		}
+	})
+
 	t.Run("Validation", func(t *testing.T) {
 		h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, time.Minute, nil)
 
@@ -182,6 +182,26 @@ func (is *indexSet) runRetention(tableMarker retention.TableMarker) error {
 	return nil
 }
 
+// mergeReplicaChunks merges the overlapping chunks flushed by the ingester replicas of the
+// streams of the compacted index, and flags the index to be uploaded if it was modified.
+func (is *indexSet) mergeReplicaChunks(merger *replicaChunksMerger) error {
+	if is.compactedIndex == nil {
+		return nil
+	}
+
+	modified, err := merger.mergeChunks(is.ctx, is.tableName, is.compactedIndex, is.logger)
+	if err != nil {
+		return err
+	}
+
+	if modified {
+		is.uploadCompactedDB = true
+		is.removeSourceObjects = true
+	}
+
+	return nil
+}
+
 // upload uploads the compacted index in compressed format.
 func (is *indexSet) upload() error {
 	if is.compactedIndex == nil {
@@ -0,0 +1,480 @@
+package compactor
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/grpcclient"
+	"github.com/pkg/errors"
+
+	compactor_grpc "github.com/grafana/loki/v3/pkg/compactor/client/grpc"
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	jobsDirName   = "jobs"
+	jobsStateFile = "jobs.json"
+
+	jobPending   = "pending"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// HorizontalScalingConfig configures the distribution of the compactions across the compactors of the ring.
+type HorizontalScalingConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	JobLeaseTimeout time.Duration     `yaml:"job_lease_timeout"`
+	MaxJobRetries   int               `yaml:"max_job_retries"`
+	WorkerClient    grpcclient.Config `yaml:"worker_client" doc:"description=Configures the gRPC client used by the workers to get their jobs from the compactor leader."`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *HorizontalScalingConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Distribute the compaction and the retention of the tables across all the compactors of the ring. The compactor elected by the ring plans a job per table, or per tenant index of the tables which only have per tenant index, which are run by max_compaction_parallelism workers on every compactor. The jobs of a compaction are persisted in the working directory of the elected compactor, for the compaction to resume after a restart.")
+	f.DurationVar(&cfg.JobLeaseTimeout, prefix+"job-lease-timeout", 10*time.Minute, "Experimental: Time after which a job is given to another worker when its worker stopped reporting that it is running it.")
+	f.IntVar(&cfg.MaxJobRetries, prefix+"max-job-retries", 3, "Experimental: Number of times a failed job is retried before failing the compaction it belongs to.")
+	cfg.WorkerClient.RegisterFlagsWithPrefix(prefix+"worker-client", f)
+}
+
+// Validate verifies the config does not contain inappropriate values
+func (cfg *HorizontalScalingConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.JobLeaseTimeout <= 0 {
+		return errors.New("job lease timeout must be positive")
+	}
+	if cfg.MaxJobRetries < 0 {
+		return errors.New("max job retries must be >= 0")
+	}
+	return cfg.WorkerClient.Validate()
+}
+
+// queuedJob is a job of a compaction, run by a worker of the compactors.
+type queuedJob struct {
+	ID             string `json:"id"`
+	Table          string `json:"table"`
+	UserID         string `json:"user_id,omitempty"`
+	ApplyRetention bool   `json:"apply_retention,omitempty"`
+	Status         string `json:"status"`
+	Worker         string `json:"worker,omitempty"`
+	Attempts       int    `json:"attempts,omitempty"`
+	Error          string `json:"error,omitempty"`
+
+	pass        *jobPass
+	leaseExpiry time.Time
+}
+
+// conflicts tells if both jobs process the same index set, in which case they can't run at the same time.
+func (j *queuedJob) conflicts(other *queuedJob) bool {
+	return j.Table == other.Table && (j.UserID == "" || other.UserID == "" || j.UserID == other.UserID)
+}
+
+// jobPass is a run of the compaction, or of the retention, of all the tables.
+type jobPass struct {
+	ID             string                   `json:"id"`
+	Retention      bool                     `json:"retention"`
+	DeleteRequests []persistedDeleteRequest `json:"delete_requests,omitempty"`
+	Jobs           []*queuedJob             `json:"jobs"`
+
+	remaining int
+	done      chan struct{}
+}
+
+// persistedDeleteRequest is a delete request processed by a retention pass.
+type persistedDeleteRequest struct {
+	UserID      string `json:"user_id"`
+	SequenceNum int64  `json:"sequence_num"`
+	deletion.DeleteRequest
+}
+
+func (p *jobPass) deleteRequests() []deletion.DeleteRequest {
+	deleteRequests := make([]deletion.DeleteRequest, 0, len(p.DeleteRequests))
+	for _, dr := range p.DeleteRequests {
+		deleteRequest := dr.DeleteRequest
+		deleteRequest.UserID = dr.UserID
+		deleteRequest.SequenceNum = dr.SequenceNum
+		deleteRequests = append(deleteRequests, deleteRequest)
+	}
+	return deleteRequests
+}
+
+// err returns an error when some jobs of the pass failed.
+func (p *jobPass) err() error {
+	var (
+		failed  int
+		lastErr string
+	)
+	for _, job := range p.Jobs {
+		if job.Status == jobFailed {
+			failed++
+			lastErr = job.Error
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d jobs failed, last error: %s", failed, len(p.Jobs), lastErr)
+}
+
+func (p *jobPass) toProto(job *queuedJob) *compactor_grpc.Job {
+	protoJob := &compactor_grpc.Job{
+		Id:             job.ID,
+		Table:          job.Table,
+		UserID:         job.UserID,
+		ApplyRetention: job.ApplyRetention,
+	}
+	if !job.ApplyRetention {
+		return protoJob
+	}
+
+	for _, dr := range p.deleteRequests() {
+		if job.UserID != "" && dr.UserID != job.UserID {
+			continue
+		}
+		protoJob.DeleteRequests = append(protoJob.DeleteRequests, deleteRequestToProto(dr))
+	}
+	return protoJob
+}
+
+// JobQueue distributes the jobs of the compactions planned by the compactor leader to the workers of the compactors.
+// Each job processes a table, or the index of a user in a table, and the jobs processing the same index set never run
+// at the same time. The state of the jobs is persisted in the working directory of the leader, for the compactions
+// to resume where they stopped after a restart.
+type JobQueue struct {
+	cfg     HorizontalScalingConfig
+	dir     string
+	metrics *metrics
+	now     func() time.Time
+
+	mtx    sync.Mutex
+	active bool
+	passes []*jobPass
+	jobs   map[string]*queuedJob
+}
+
+func newJobQueue(cfg HorizontalScalingConfig, workingDir string, metrics *metrics) (*JobQueue, error) {
+	dir := filepath.Join(workingDir, jobsDirName)
+	if err := chunk_util.EnsureDirectory(dir); err != nil {
+		return nil, err
+	}
+
+	return &JobQueue{
+		cfg:     cfg,
+		dir:     dir,
+		metrics: metrics,
+		now:     time.Now,
+		jobs:    map[string]*queuedJob{},
+	}, nil
+}
+
+// start makes the queue serve the jobs of the compactions, once this compactor is elected leader.
+// The compactions persisted by a previous leader running on this compactor are resumed.
+func (q *JobQueue) start() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.active = true
+	q.passes = nil
+	q.jobs = map[string]*queuedJob{}
+
+	passes, err := q.load()
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to load the jobs of the interrupted compactions, starting over", "err", err)
+		passes = nil
+	}
+
+	now := q.now()
+	for _, pass := range passes {
+		pass.done = make(chan struct{})
+		for _, job := range pass.Jobs {
+			job.pass = pass
+			q.jobs[job.ID] = job
+			switch job.Status {
+			case jobPending:
+				pass.remaining++
+			case jobRunning:
+				// the worker may still be running the job, which is given to another worker if it stopped.
+				job.leaseExpiry = now.Add(q.cfg.JobLeaseTimeout)
+				pass.remaining++
+			}
+		}
+		if pass.remaining == 0 {
+			continue
+		}
+
+		level.Info(util_log.Logger).Log("msg", "resuming interrupted compaction", "pass", pass.ID, "jobs", len(pass.Jobs), "remaining", pass.remaining)
+		q.passes = append(q.passes, pass)
+	}
+	q.updateMetrics()
+}
+
+// stop stops serving jobs, once this compactor is no longer the leader. The persisted compactions are kept.
+func (q *JobQueue) stop() {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.active = false
+	q.passes = nil
+	q.jobs = map[string]*queuedJob{}
+	q.updateMetrics()
+}
+
+// resumedPass returns the running pass applying retention or not, which was resumed by start, if any.
+func (q *JobQueue) resumedPass(retention bool) *jobPass {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	for _, pass := range q.passes {
+		if pass.Retention == retention {
+			return pass
+		}
+	}
+	return nil
+}
+
+// enqueue adds a pass running the given jobs, and processing the given delete requests when it applies retention.
+func (q *JobQueue) enqueue(retention bool, jobs []*queuedJob, deleteRequests []deletion.DeleteRequest) (*jobPass, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if !q.active {
+		return nil, errors.New("job queue is not running")
+	}
+
+	kind := "compaction"
+	if retention {
+		kind = "retention"
+	}
+	pass := &jobPass{
+		ID:        fmt.Sprintf("%s-%d", kind, q.now().UnixNano()),
+		Retention: retention,
+		Jobs:      jobs,
+		remaining: len(jobs),
+		done:      make(chan struct{}),
+	}
+	for _, dr := range deleteRequests {
+		pass.DeleteRequests = append(pass.DeleteRequests, persistedDeleteRequest{
+			UserID:        dr.UserID,
+			SequenceNum:   dr.SequenceNum,
+			DeleteRequest: dr,
+		})
+	}
+	for _, job := range jobs {
+		job.ID = fmt.Sprintf("%s/%s/%s", pass.ID, job.Table, job.UserID)
+		job.Status = jobPending
+		job.pass = pass
+		q.jobs[job.ID] = job
+	}
+
+	if pass.remaining == 0 {
+		close(pass.done)
+		return pass, nil
+	}
+
+	q.passes = append(q.passes, pass)
+	q.persist()
+	q.updateMetrics()
+	return pass, nil
+}
+
+// wait waits for all the jobs of the pass to be done, and returns an error if some of them failed.
+func (q *JobQueue) wait(ctx context.Context, pass *jobPass) error {
+	select {
+	case <-pass.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return pass.err()
+}
+
+// Dequeue gives the next job to run to a worker, if any.
+func (q *JobQueue) Dequeue(_ context.Context, req *compactor_grpc.DequeueRequest) (*compactor_grpc.DequeueResponse, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if !q.active {
+		return &compactor_grpc.DequeueResponse{}, nil
+	}
+
+	now := q.now()
+	var (
+		running []*queuedJob
+		expired bool
+	)
+	for _, job := range q.jobs {
+		if job.Status != jobRunning {
+			continue
+		}
+		if now.After(job.leaseExpiry) {
+			level.Warn(util_log.Logger).Log("msg", "compaction job lease expired", "job", job.ID, "worker", job.Worker)
+			q.retryOrFail(job, fmt.Sprintf("worker %s stopped running the job", job.Worker))
+			expired = true
+			continue
+		}
+		running = append(running, job)
+	}
+	if expired {
+		// persist the retried or failed jobs, even when no job is given to the worker.
+		q.persist()
+		q.updateMetrics()
+	}
+
+	for _, pass := range q.passes {
+	jobs:
+		for _, job := range pass.Jobs {
+			if job.Status != jobPending {
+				continue
+			}
+			for _, r := range running {
+				if job.conflicts(r) {
+					continue jobs
+				}
+			}
+
+			job.Status = jobRunning
+			job.Worker = req.Worker
+			job.Attempts++
+			job.leaseExpiry = now.Add(q.cfg.JobLeaseTimeout)
+			q.persist()
+			q.updateMetrics()
+			return &compactor_grpc.DequeueResponse{Job: pass.toProto(job)}, nil
+		}
+	}
+
+	return &compactor_grpc.DequeueResponse{}, nil
+}
+
+// ReportJobStatus records the status of a job reported by its worker, and extends its lease while it is running.
+func (q *JobQueue) ReportJobStatus(_ context.Context, req *compactor_grpc.ReportJobStatusRequest) (*compactor_grpc.ReportJobStatusResponse, error) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	job, ok := q.jobs[req.JobID]
+	if !q.active || !ok || job.Status != jobRunning || job.Worker != req.Worker {
+		return &compactor_grpc.ReportJobStatusResponse{Canceled: true}, nil
+	}
+
+	switch req.Status {
+	case compactor_grpc.JOB_RUNNING:
+		job.leaseExpiry = q.now().Add(q.cfg.JobLeaseTimeout)
+		return &compactor_grpc.ReportJobStatusResponse{}, nil
+	case compactor_grpc.JOB_SUCCEEDED:
+		job.Error = ""
+		q.finish(job, jobSucceeded)
+	case compactor_grpc.JOB_FAILED:
+		level.Warn(util_log.Logger).Log("msg", "compaction job failed", "job", job.ID, "worker", job.Worker, "attempts", job.Attempts, "err", req.Error)
+		q.retryOrFail(job, req.Error)
+	}
+
+	q.persist()
+	q.updateMetrics()
+	return &compactor_grpc.ReportJobStatusResponse{}, nil
+}
+
+// retryOrFail queues a job which did not complete again, unless it ran out of retries.
+func (q *JobQueue) retryOrFail(job *queuedJob, err string) {
+	job.Error = err
+	if job.Attempts > q.cfg.MaxJobRetries {
+		q.finish(job, jobFailed)
+		return
+	}
+	job.Status = jobPending
+	job.Worker = ""
+}
+
+func (q *JobQueue) finish(job *queuedJob, status string) {
+	job.Status = status
+	pass := job.pass
+	pass.remaining--
+	if pass.remaining > 0 {
+		return
+	}
+
+	for i, p := range q.passes {
+		if p == pass {
+			q.passes = append(q.passes[:i], q.passes[i+1:]...)
+			break
+		}
+	}
+	for _, job := range pass.Jobs {
+		delete(q.jobs, job.ID)
+	}
+	close(pass.done)
+}
+
+func (q *JobQueue) updateMetrics() {
+	var pending, running int
+	for _, job := range q.jobs {
+		switch job.Status {
+		case jobPending:
+			pending++
+		case jobRunning:
+			running++
+		}
+	}
+	q.metrics.jobsQueued.WithLabelValues(jobPending).Set(float64(pending))
+	q.metrics.jobsQueued.WithLabelValues(jobRunning).Set(float64(running))
+}
+
+// persist writes the state of the running passes to the working directory.
+func (q *JobQueue) persist() {
+	data, err := json.Marshal(q.passes)
+	if err == nil {
+		tmp := filepath.Join(q.dir, jobsStateFile+".tmp")
+		if err = os.WriteFile(tmp, data, 0o640); err == nil {
+			err = os.Rename(tmp, filepath.Join(q.dir, jobsStateFile))
+		}
+	}
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to persist the compaction jobs", "err", err)
+	}
+}
+
+func (q *JobQueue) load() ([]*jobPass, error) {
+	data, err := os.ReadFile(filepath.Join(q.dir, jobsStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var passes []*jobPass
+	if err := json.Unmarshal(data, &passes); err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
+
+func deleteRequestToProto(dr deletion.DeleteRequest) *compactor_grpc.DeleteRequest {
+	protoDeleteRequest := &compactor_grpc.DeleteRequest{
+		RequestID: dr.RequestID,
+		StartTime: int64(dr.StartTime),
+		EndTime:   int64(dr.EndTime),
+		Query:     dr.Query,
+		Status:    string(dr.Status),
+		CreatedAt: int64(dr.CreatedAt),
+		UserID:    dr.UserID,
+	}
+	if dr.Redaction != nil {
+		protoDeleteRequest.Redaction = &compactor_grpc.Redaction{
+			Regex:       dr.Redaction.Regex,
+			Replacement: dr.Redaction.Replacement,
+		}
+	}
+	return protoDeleteRequest
+}
@@ -0,0 +1,196 @@
+package compactor
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	compactor_grpc "github.com/grafana/loki/v3/pkg/compactor/client/grpc"
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+)
+
+func newTestJobQueue(t *testing.T, dir string, now *time.Time) *JobQueue {
+	cfg := HorizontalScalingConfig{}
+	cfg.RegisterFlagsWithPrefix("", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.Enabled = true
+	cfg.MaxJobRetries = 1
+
+	q, err := newJobQueue(cfg, dir, newMetrics(prometheus.NewPedanticRegistry()))
+	require.NoError(t, err)
+	q.now = func() time.Time { return *now }
+	q.start()
+	return q
+}
+
+func dequeue(t *testing.T, q *JobQueue, worker string) *compactor_grpc.Job {
+	resp, err := q.Dequeue(context.Background(), &compactor_grpc.DequeueRequest{Worker: worker})
+	require.NoError(t, err)
+	return resp.Job
+}
+
+func reportJobStatus(t *testing.T, q *JobQueue, worker string, job *compactor_grpc.Job, status compactor_grpc.JobStatus) bool {
+	resp, err := q.ReportJobStatus(context.Background(), &compactor_grpc.ReportJobStatusRequest{Worker: worker, JobID: job.Id, Status: status, Error: "fail"})
+	require.NoError(t, err)
+	return resp.Canceled
+}
+
+func passDone(pass *jobPass) bool {
+	select {
+	case <-pass.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestJobQueue_Dequeue(t *testing.T) {
+	now := time.Now()
+	q := newTestJobQueue(t, t.TempDir(), &now)
+
+	pass, err := q.enqueue(false, []*queuedJob{
+		{Table: "table_1"},
+		{Table: "table_2", UserID: "user1"},
+		{Table: "table_2", UserID: "user2"},
+	}, nil)
+	require.NoError(t, err)
+
+	job1 := dequeue(t, q, "worker1")
+	require.Equal(t, "table_1", job1.Table)
+	job2 := dequeue(t, q, "worker2")
+	require.Equal(t, "table_2", job2.Table)
+	require.Equal(t, "user1", job2.UserID)
+	job3 := dequeue(t, q, "worker1")
+	require.Equal(t, "user2", job3.UserID)
+	require.Nil(t, dequeue(t, q, "worker2"))
+
+	// only the worker running a job can report its status.
+	require.True(t, reportJobStatus(t, q, "worker2", job1, compactor_grpc.JOB_SUCCEEDED))
+
+	for _, job := range []*compactor_grpc.Job{job1, job3} {
+		require.False(t, reportJobStatus(t, q, "worker1", job, compactor_grpc.JOB_SUCCEEDED))
+	}
+	require.False(t, passDone(pass))
+	require.False(t, reportJobStatus(t, q, "worker2", job2, compactor_grpc.JOB_SUCCEEDED))
+	require.True(t, passDone(pass))
+	require.NoError(t, q.wait(context.Background(), pass))
+
+	// the reports of finished jobs are rejected.
+	require.True(t, reportJobStatus(t, q, "worker1", job1, compactor_grpc.JOB_SUCCEEDED))
+}
+
+func TestJobQueue_Conflicts(t *testing.T) {
+	now := time.Now()
+	q := newTestJobQueue(t, t.TempDir(), &now)
+
+	_, err := q.enqueue(false, []*queuedJob{{Table: "table_1"}}, nil)
+	require.NoError(t, err)
+	_, err = q.enqueue(true, []*queuedJob{
+		{Table: "table_1", UserID: "user1", ApplyRetention: true},
+		{Table: "table_2", ApplyRetention: true},
+	}, nil)
+	require.NoError(t, err)
+
+	job1 := dequeue(t, q, "worker1")
+	require.Equal(t, "table_1", job1.Table)
+	require.False(t, job1.ApplyRetention)
+
+	// the job of user1 in table_1 can't run while the whole table is compacted.
+	job2 := dequeue(t, q, "worker2")
+	require.Equal(t, "table_2", job2.Table)
+	require.Nil(t, dequeue(t, q, "worker2"))
+
+	require.False(t, reportJobStatus(t, q, "worker1", job1, compactor_grpc.JOB_SUCCEEDED))
+	job3 := dequeue(t, q, "worker2")
+	require.Equal(t, "table_1", job3.Table)
+	require.Equal(t, "user1", job3.UserID)
+	require.True(t, job3.ApplyRetention)
+}
+
+func TestJobQueue_Retries(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	q := newTestJobQueue(t, dir, &now)
+
+	pass, err := q.enqueue(false, []*queuedJob{{Table: "table_1"}}, nil)
+	require.NoError(t, err)
+
+	// the job is retried once.
+	job := dequeue(t, q, "worker1")
+	require.False(t, reportJobStatus(t, q, "worker1", job, compactor_grpc.JOB_FAILED))
+	require.False(t, passDone(pass))
+
+	// the job is given to another worker when its lease expires, which counts as a failure.
+	job = dequeue(t, q, "worker1")
+	require.NotNil(t, job)
+	now = now.Add(q.cfg.JobLeaseTimeout / 2)
+	require.False(t, reportJobStatus(t, q, "worker1", job, compactor_grpc.JOB_RUNNING))
+	now = now.Add(q.cfg.JobLeaseTimeout / 2)
+	require.Nil(t, dequeue(t, q, "worker2"))
+	now = now.Add(q.cfg.JobLeaseTimeout)
+	require.Nil(t, dequeue(t, q, "worker2"))
+
+	require.True(t, passDone(pass))
+	require.ErrorContains(t, q.wait(context.Background(), pass), "1 of 1 jobs failed")
+	require.True(t, reportJobStatus(t, q, "worker1", job, compactor_grpc.JOB_RUNNING))
+
+	// the pass failed by the expired lease is not resumed after a restart.
+	q.stop()
+	q = newTestJobQueue(t, dir, &now)
+	require.Nil(t, q.resumedPass(false))
+}
+
+func TestJobQueue_Resume(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	q := newTestJobQueue(t, dir, &now)
+
+	deleteRequest := deletion.DeleteRequest{
+		RequestID:   "1",
+		UserID:      "user1",
+		SequenceNum: 2,
+		Query:       `{foo="bar"}`,
+		StartTime:   model.Time(10),
+		EndTime:     model.Time(20),
+	}
+	_, err := q.enqueue(true, []*queuedJob{
+		{Table: "table_1", UserID: "user1", ApplyRetention: true},
+		{Table: "table_1", UserID: "user2", ApplyRetention: true},
+		{Table: "table_2", UserID: "user1"},
+	}, []deletion.DeleteRequest{deleteRequest})
+	require.NoError(t, err)
+
+	job1 := dequeue(t, q, "worker1")
+	require.Equal(t, "user1", job1.UserID)
+	require.Len(t, job1.DeleteRequests, 1)
+	require.Equal(t, "user1", job1.DeleteRequests[0].UserID)
+	require.False(t, reportJobStatus(t, q, "worker1", job1, compactor_grpc.JOB_SUCCEEDED))
+
+	job2 := dequeue(t, q, "worker1")
+	require.Equal(t, "user2", job2.UserID)
+	require.Empty(t, job2.DeleteRequests)
+	q.stop()
+
+	// the compactor restarts and resumes the pass, with the running job leased to its worker.
+	q = newTestJobQueue(t, dir, &now)
+	require.Nil(t, q.resumedPass(false))
+	pass := q.resumedPass(true)
+	require.NotNil(t, pass)
+	require.Equal(t, []deletion.DeleteRequest{deleteRequest}, pass.deleteRequests())
+
+	job3 := dequeue(t, q, "worker2")
+	require.Equal(t, "table_2", job3.Table)
+	require.False(t, job3.ApplyRetention)
+	require.Empty(t, job3.DeleteRequests)
+	require.False(t, reportJobStatus(t, q, "worker2", job3, compactor_grpc.JOB_SUCCEEDED))
+	require.False(t, reportJobStatus(t, q, "worker1", job2, compactor_grpc.JOB_SUCCEEDED))
+	require.NoError(t, q.wait(context.Background(), pass))
+
+	// nothing is left to resume once the pass is done.
+	q = newTestJobQueue(t, dir, &now)
+	require.Nil(t, q.resumedPass(true))
+}
@@ -0,0 +1,338 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/common/model"
+	"google.golang.org/grpc"
+
+	compactor_grpc "github.com/grafana/loki/v3/pkg/compactor/client/grpc"
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// runJobs runs a compaction, applying retention or not, by distributing its jobs to the workers of the compactors.
+// An interrupted compaction persisted by the job queue is resumed instead of planning a new one.
+func (c *Compactor) runJobs(ctx context.Context, applyRetention bool) error {
+	if applyRetention {
+		c.takeOverRetentionMarkers(ctx)
+	}
+
+	pass := c.JobQueue.resumedPass(applyRetention)
+	if pass != nil {
+		if applyRetention && c.deleteRequestsManager != nil {
+			c.deleteRequestsManager.ResumeDeleteRequestsToProcess(pass.deleteRequests())
+		}
+	} else {
+		tables, err := c.listTables(ctx)
+		if err != nil {
+			return err
+		}
+
+		jobs, err := c.planJobs(ctx, tables, applyRetention)
+		if err != nil {
+			return err
+		}
+
+		var deleteRequests []deletion.DeleteRequest
+		if applyRetention && c.deleteRequestsManager != nil {
+			deleteRequests = c.deleteRequestsManager.DeleteRequestsToProcess()
+		}
+
+		pass, err = c.JobQueue.enqueue(applyRetention, jobs, deleteRequests)
+		if err != nil {
+			return err
+		}
+		level.Info(util_log.Logger).Log("msg", "queued compaction jobs", "pass", pass.ID, "jobs", len(jobs), "delete_requests", len(deleteRequests))
+	}
+
+	return c.JobQueue.wait(ctx, pass)
+}
+
+// takeOverRetentionMarkers downloads the retention markers handed off by the compactors which left the ring,
+// for the chunks they marked to be deleted by the sweepers of the leader.
+func (c *Compactor) takeOverRetentionMarkers(ctx context.Context) {
+	for _, sc := range c.storeContainers {
+		if sc.markersHandOff == nil {
+			continue
+		}
+		if err := sc.markersHandOff.download(ctx); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to take over handed off retention markers", "err", err)
+		}
+	}
+}
+
+// planJobs returns the jobs compacting the given tables. The tables with common index files are compacted by a
+// single job, which builds the per user index from them. The other tables are compacted by a job per user.
+func (c *Compactor) planJobs(ctx context.Context, tables []string, applyRetention bool) ([]*queuedJob, error) {
+	var jobs []*queuedJob
+	for _, tableName := range tables {
+		if tableName == deletion.DeleteRequestsTableName {
+			continue
+		}
+
+		schemaCfg, ok := SchemaPeriodForTable(c.schemaConfig, tableName)
+		if !ok {
+			level.Error(util_log.Logger).Log("msg", "skipping compaction since we can't find schema for table", "table", tableName)
+			continue
+		}
+
+		sc, ok := c.storeContainers[schemaCfg.From]
+		if !ok {
+			return nil, fmt.Errorf("index store client not found for period starting at %s", schemaCfg.From.String())
+		}
+
+		tableRetention := applyRetention && c.expirationChecker.IntervalMayHaveExpiredChunks(retention.ExtractIntervalFromTableName(tableName), "")
+
+		sc.indexStorageClient.RefreshIndexTableCache(ctx, tableName)
+		commonIndexFiles, usersWithPerUserIndex, err := sc.indexStorageClient.ListFiles(ctx, tableName, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of table %s: %w", tableName, err)
+		}
+
+		if len(commonIndexFiles) > 0 {
+			jobs = append(jobs, &queuedJob{Table: tableName, ApplyRetention: tableRetention})
+			continue
+		}
+		for _, userID := range usersWithPerUserIndex {
+			jobs = append(jobs, &queuedJob{Table: tableName, UserID: userID, ApplyRetention: tableRetention})
+		}
+	}
+
+	return jobs, nil
+}
+
+// runWorkers runs the jobs given by the compactor leader with max_compaction_parallelism workers, until the context is done.
+func (c *Compactor) runWorkers(ctx context.Context) {
+	for i := 0; i < c.cfg.MaxCompactionParallelism; i++ {
+		c.workersWg.Add(1)
+		go func() {
+			defer c.workersWg.Done()
+			c.runWorker(ctx)
+		}()
+	}
+}
+
+// runZstdDictionaryTraining trains the zstd dictionaries from the chunks sampled by the workers of this compactor,
+// once the training interval elapsed, until the context is done.
+func (c *Compactor) runZstdDictionaryTraining(ctx context.Context) {
+	if c.zstdDictionaryTrainer == nil {
+		return
+	}
+
+	c.workersWg.Add(1)
+	go func() {
+		defer c.workersWg.Done()
+
+		ticker := time.NewTicker(c.ringPollPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.zstdDictionaryTrainer.trainIfDue(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *Compactor) runWorker(ctx context.Context) {
+	worker := c.ringLifecycler.GetInstanceID()
+	for ctx.Err() == nil {
+		ran, err := c.runNextJob(ctx, worker)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to run compaction job", "err", err)
+		}
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(c.ringPollPeriod):
+		}
+	}
+}
+
+// runNextJob gets the next job from the compactor leader and runs it. It returns false when there was no job to run.
+func (c *Compactor) runNextJob(ctx context.Context, worker string) (bool, error) {
+	queue, err := c.jobQueueClient()
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := queue.Dequeue(ctx, &compactor_grpc.DequeueRequest{Worker: worker})
+	if err != nil {
+		return false, err
+	}
+	job := resp.Job
+	if job == nil {
+		return false, nil
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// report that the job is still running, for the leader to not give it to another worker.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(c.cfg.HorizontalScaling.JobLeaseTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				resp, err := queue.ReportJobStatus(jobCtx, &compactor_grpc.ReportJobStatusRequest{Worker: worker, JobID: job.Id, Status: compactor_grpc.JOB_RUNNING})
+				if err != nil {
+					level.Warn(util_log.Logger).Log("msg", "failed to report compaction job status", "job", job.Id, "err", err)
+					continue
+				}
+				if resp.Canceled {
+					level.Warn(util_log.Logger).Log("msg", "compaction job is no longer leased to this worker, canceling it", "job", job.Id)
+					cancel()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	err = c.runJob(jobCtx, job)
+	close(done)
+	wg.Wait()
+
+	report := &compactor_grpc.ReportJobStatusRequest{Worker: worker, JobID: job.Id, Status: compactor_grpc.JOB_SUCCEEDED}
+	status := statusSuccess
+	if err != nil {
+		report.Status = compactor_grpc.JOB_FAILED
+		report.Error = err.Error()
+		status = statusFailure
+		level.Error(util_log.Logger).Log("msg", "compaction job failed", "job", job.Id, "duration", time.Since(start), "err", err)
+	} else {
+		level.Info(util_log.Logger).Log("msg", "finished compaction job", "job", job.Id, "duration", time.Since(start))
+	}
+	c.metrics.jobsProcessedTotal.WithLabelValues(status).Inc()
+
+	if _, err := queue.ReportJobStatus(ctx, report); err != nil {
+		return true, fmt.Errorf("failed to report status of compaction job %s: %w", job.Id, err)
+	}
+	return true, nil
+}
+
+// runJob compacts the index sets of the job, applying retention with the delete requests of the job if needed.
+func (c *Compactor) runJob(ctx context.Context, job *compactor_grpc.Job) error {
+	level.Info(util_log.Logger).Log("msg", "running compaction job", "job", job.Id, "table-name", job.Table, "user-id", job.UserID, "apply-retention", job.ApplyRetention)
+
+	schemaCfg, ok := SchemaPeriodForTable(c.schemaConfig, job.Table)
+	if !ok {
+		return fmt.Errorf("schema not found for table %s", job.Table)
+	}
+
+	indexCompactor, ok := c.indexCompactors[schemaCfg.IndexType]
+	if !ok {
+		return fmt.Errorf("index processor not found for index type %s", schemaCfg.IndexType)
+	}
+
+	sc, ok := c.storeContainers[schemaCfg.From]
+	if !ok {
+		return fmt.Errorf("index store client not found for period starting at %s", schemaCfg.From.String())
+	}
+
+	expirationChecker := c.expirationChecker
+	if job.ApplyRetention {
+		if c.deleteRequestsManager == nil {
+			return fmt.Errorf("retention is not enabled on this compactor")
+		}
+		expirationChecker = newExpirationChecker(
+			retention.NewExpirationChecker(c.limits),
+			c.deleteRequestsManager.ExpirationCheckerFor(deleteRequestsFromProto(job.DeleteRequests)),
+		)
+		// computes the retention periods of the users, the phases of the delete requests are managed by the leader.
+		expirationChecker.MarkPhaseStarted()
+	}
+
+	return c.compactIndexSets(ctx, job.Table, job.UserID, schemaCfg, indexCompactor, sc, expirationChecker, job.ApplyRetention)
+}
+
+// leaderJobQueueClient returns a client of the job queue of the compactor elected leader by the ring.
+func (c *Compactor) leaderJobQueueClient() (compactor_grpc.JobQueueClient, error) {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	rs, err := c.ring.Get(ringKeyOfLeader, ring.Write, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the compactor leader: %w", err)
+	}
+
+	addrs := rs.GetAddresses()
+	if len(addrs) != 1 {
+		return nil, fmt.Errorf("found %d compactor leaders instead of 1", len(addrs))
+	}
+
+	c.jobQueueConnMtx.Lock()
+	defer c.jobQueueConnMtx.Unlock()
+
+	if c.jobQueueConn == nil || c.jobQueueAddr != addrs[0] {
+		if c.jobQueueConn != nil {
+			c.jobQueueConn.Close()
+			c.jobQueueConn = nil
+		}
+
+		dialOpts, err := c.cfg.HorizontalScaling.WorkerClient.DialOption(nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := grpc.Dial(addrs[0], dialOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial the compactor leader %s: %w", addrs[0], err)
+		}
+		c.jobQueueConn, c.jobQueueAddr = conn, addrs[0]
+	}
+
+	return compactor_grpc.NewJobQueueClient(c.jobQueueConn), nil
+}
+
+func (c *Compactor) closeJobQueueConn() {
+	c.jobQueueConnMtx.Lock()
+	defer c.jobQueueConnMtx.Unlock()
+
+	if c.jobQueueConn != nil {
+		c.jobQueueConn.Close()
+		c.jobQueueConn = nil
+	}
+}
+
+func deleteRequestsFromProto(protoDeleteRequests []*compactor_grpc.DeleteRequest) []deletion.DeleteRequest {
+	deleteRequests := make([]deletion.DeleteRequest, 0, len(protoDeleteRequests))
+	for _, dr := range protoDeleteRequests {
+		deleteRequest := deletion.DeleteRequest{
+			RequestID: dr.RequestID,
+			StartTime: model.Time(dr.StartTime),
+			EndTime:   model.Time(dr.EndTime),
+			Query:     dr.Query,
+			Status:    deletion.DeleteRequestStatus(dr.Status),
+			CreatedAt: model.Time(dr.CreatedAt),
+			UserID:    dr.UserID,
+		}
+		if dr.Redaction != nil {
+			deleteRequest.Redaction = &deletion.Redaction{
+				Regex:       dr.Redaction.Regex,
+				Replacement: dr.Redaction.Replacement,
+			}
+		}
+		deleteRequests = append(deleteRequests, deleteRequest)
+	}
+	return deleteRequests
+}
@@ -19,6 +19,8 @@ type metrics struct {
 	applyRetentionLastSuccess              prometheus.Gauge
 	compactorRunning                       prometheus.Gauge
 	skippedCompactingLockedTables          *prometheus.GaugeVec
+	jobsQueued                             *prometheus.GaugeVec
+	jobsProcessedTotal                     *prometheus.CounterVec
 }
 
 func newMetrics(r prometheus.Registerer) *metrics {
@@ -63,6 +65,16 @@ func newMetrics(r prometheus.Registerer) *metrics {
 			Name:      "locked_table_successive_compaction_skips",
 			Help:      "Number of times uncompacted tables were consecutively skipped due to them being locked by retention",
 		}, []string{"table_name"}),
+		jobsQueued: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki_compactor",
+			Name:      "queued_jobs",
+			Help:      "Number of jobs of the running compactions which are pending or running, only set on the compactor leader when horizontal scaling is enabled",
+		}, []string{"status"}),
+		jobsProcessedTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "jobs_processed_total",
+			Help:      "Total number of compaction jobs run by the workers of this compactor with status",
+		}, []string{"status"}),
 	}
 
 	return &m
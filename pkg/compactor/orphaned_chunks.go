@@ -0,0 +1,478 @@
+package compactor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const orphanedChunksMarksFile = "marks.json"
+
+type OrphanedChunksGCConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	Interval            time.Duration `yaml:"interval"`
+	GracePeriod         time.Duration `yaml:"grace_period"`
+	DryRun              bool          `yaml:"dry_run"`
+	MaxDeletesPerSecond int           `yaml:"max_deletes_per_second"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *OrphanedChunksGCConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Delete the chunks of the TSDB schema periods which are not referenced by the index, like the chunks of failed flushes or of interrupted compactions. The chunks of each tenant are listed and compared with the chunk references of the index tables they belong to, and a chunk is only deleted when it was not referenced in two consecutive runs.")
+	f.DurationVar(&cfg.Interval, prefix+"interval", 24*time.Hour, "Interval at which to look for orphaned chunks. It must be longer than -compactor.retention-delete-delay when retention is enabled, for the chunks marked for deletion by the retention to be deleted by the sweepers first.")
+	f.DurationVar(&cfg.GracePeriod, prefix+"grace-period", 24*time.Hour, "Minimum age of the chunk objects to consider them orphaned, as the index referencing the recently flushed chunks may not have been uploaded yet.")
+	f.BoolVar(&cfg.DryRun, prefix+"dry-run", false, "Only log and count the orphaned chunks instead of deleting them.")
+	f.IntVar(&cfg.MaxDeletesPerSecond, prefix+"max-deletes-per-second", 10, "Maximum number of orphaned chunks deleted per second.")
+}
+
+func (cfg *OrphanedChunksGCConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("orphaned chunks gc interval must be positive")
+	}
+	if cfg.GracePeriod <= 0 {
+		return errors.New("orphaned chunks gc grace period must be positive")
+	}
+	if cfg.MaxDeletesPerSecond <= 0 {
+		return errors.New("orphaned chunks gc max deletes per second must be positive")
+	}
+	return nil
+}
+
+type orphanedChunksGCMetrics struct {
+	chunksFound          prometheus.Counter
+	chunksDeleted        prometheus.Counter
+	chunkDeleteFailures  prometheus.Counter
+	runsTotal            *prometheus.CounterVec
+	lastSuccessTimestamp prometheus.Gauge
+}
+
+func newOrphanedChunksGCMetrics(r prometheus.Registerer) *orphanedChunksGCMetrics {
+	return &orphanedChunksGCMetrics{
+		chunksFound: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "orphaned_chunks_found_total",
+			Help:      "Total number of chunks found not referenced by the index, including the ones found again by later runs",
+		}),
+		chunksDeleted: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "orphaned_chunks_deleted_total",
+			Help:      "Total number of orphaned chunks deleted",
+		}),
+		chunkDeleteFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "orphaned_chunks_delete_failures_total",
+			Help:      "Total number of orphaned chunks which failed to be deleted",
+		}),
+		runsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "orphaned_chunks_gc_runs_total",
+			Help:      "Total number of runs of the orphaned chunks garbage collection with status",
+		}, []string{"status"}),
+		lastSuccessTimestamp: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_compactor",
+			Name:      "orphaned_chunks_gc_last_successful_run_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful run of the orphaned chunks garbage collection",
+		}),
+	}
+}
+
+// orphanedChunksMarks are the orphaned chunks found by a run, persisted for the next run to delete them.
+type orphanedChunksMarks struct {
+	MarkedAt time.Time `json:"marked_at"`
+	// Chunks are the external keys of the chunks by tenant.
+	Chunks map[string][]string `json:"chunks"`
+}
+
+// orphanedChunksCollector deletes the chunks of a schema period which are not referenced by its index.
+//
+// Each run lists the chunk objects of the tenants having an index in the tables of the period, and looks
+// for their references in the index tables overlapping them. The chunks without references, older than
+// the grace period, are marked as orphaned, and the chunks already marked by the previous run are deleted.
+// Requiring two runs lets the sweepers delete the chunks marked by the retention after the retention delete
+// delay, and lets the queriers refresh their index, before the orphaned chunks are deleted.
+//
+// The chunks overlapping a table with common index files are not collected, since the tenants of the common
+// index files would need to be read for each tenant. The tables with TSDB index are compacted to per user
+// index files by the compactor.
+type orphanedChunksCollector struct {
+	cfg                OrphanedChunksGCConfig
+	schemaConfig       config.SchemaConfig
+	period             config.PeriodConfig
+	objectClient       client.ObjectClient
+	chunkClient        client.Client
+	indexStorageClient storage.Client
+	// fsEncoded is true when the last segment of the chunk keys is base64 encoded, as by the filesystem object client.
+	fsEncoded  bool
+	workingDir string
+	limiter    *rate.Limiter
+	metrics    *orphanedChunksGCMetrics
+	logger     log.Logger
+	now        func() time.Time
+}
+
+func newOrphanedChunksCollector(
+	cfg OrphanedChunksGCConfig,
+	schemaConfig config.SchemaConfig,
+	period config.PeriodConfig,
+	objectClient client.ObjectClient,
+	chunkClient client.Client,
+	indexStorageClient storage.Client,
+	fsEncoded bool,
+	workingDir string,
+	limiter *rate.Limiter,
+	metrics *orphanedChunksGCMetrics,
+) *orphanedChunksCollector {
+	return &orphanedChunksCollector{
+		cfg:                cfg,
+		schemaConfig:       schemaConfig,
+		period:             period,
+		objectClient:       objectClient,
+		chunkClient:        chunkClient,
+		indexStorageClient: indexStorageClient,
+		fsEncoded:          fsEncoded,
+		workingDir:         workingDir,
+		limiter:            limiter,
+		metrics:            metrics,
+		logger:             log.With(util_log.Logger, "period", period.From.String()),
+		now:                time.Now,
+	}
+}
+
+type orphanedChunksTable struct {
+	// hasCommonIndex is true when the table has common index files, shared by the tenants.
+	hasCommonIndex bool
+	users          map[string]struct{}
+}
+
+// run marks the orphaned chunks of the period, and deletes the ones already marked by the previous run.
+func (g *orphanedChunksCollector) run(ctx context.Context, indexCompactor IndexCompactor) error {
+	if err := chunk_util.EnsureDirectory(g.workingDir); err != nil {
+		return err
+	}
+
+	previous, err := g.readMarks()
+	if err != nil {
+		return err
+	}
+
+	tables, err := g.listTables(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenants := map[string]struct{}{}
+	for _, t := range tables {
+		for userID := range t.users {
+			tenants[userID] = struct{}{}
+		}
+	}
+	sortedTenants := make([]string, 0, len(tenants))
+	for tenant := range tenants {
+		sortedTenants = append(sortedTenants, tenant)
+	}
+	sort.Strings(sortedTenants)
+
+	marks := orphanedChunksMarks{MarkedAt: g.now(), Chunks: map[string][]string{}}
+	deleted, failed := 0, 0
+	for _, tenant := range sortedTenants {
+		orphans, err := g.findOrphanedChunks(ctx, tenant, tables, indexCompactor)
+		if err != nil {
+			return fmt.Errorf("failed to find the orphaned chunks of tenant %s: %w", tenant, err)
+		}
+		g.metrics.chunksFound.Add(float64(len(orphans)))
+
+		previouslyMarked := make(map[string]struct{}, len(previous.Chunks[tenant]))
+		for _, chunkID := range previous.Chunks[tenant] {
+			previouslyMarked[chunkID] = struct{}{}
+		}
+
+		for _, chunkID := range orphans {
+			if _, ok := previouslyMarked[chunkID]; !ok {
+				marks.Chunks[tenant] = append(marks.Chunks[tenant], chunkID)
+				continue
+			}
+			if g.cfg.DryRun {
+				level.Info(g.logger).Log("msg", "orphaned chunk would be deleted", "user-id", tenant, "chunk-id", chunkID)
+				marks.Chunks[tenant] = append(marks.Chunks[tenant], chunkID)
+				continue
+			}
+
+			if err := g.deleteChunk(ctx, tenant, chunkID); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				level.Error(g.logger).Log("msg", "failed to delete orphaned chunk", "user-id", tenant, "chunk-id", chunkID, "err", err)
+				g.metrics.chunkDeleteFailures.Inc()
+				// the chunk is deleted by the next run if it is still orphaned.
+				marks.Chunks[tenant] = append(marks.Chunks[tenant], chunkID)
+				failed++
+				continue
+			}
+			g.metrics.chunksDeleted.Inc()
+			deleted++
+		}
+	}
+
+	marked := 0
+	for _, chunkIDs := range marks.Chunks {
+		marked += len(chunkIDs)
+	}
+	level.Info(g.logger).Log("msg", "finished looking for orphaned chunks", "tenants", len(sortedTenants), "marked", marked, "deleted", deleted, "failed", failed, "dry_run", g.cfg.DryRun)
+
+	return g.writeMarks(marks)
+}
+
+// listTables returns the index tables of the period by name.
+func (g *orphanedChunksCollector) listTables(ctx context.Context) (map[string]orphanedChunksTable, error) {
+	g.indexStorageClient.RefreshIndexTableNamesCache(ctx)
+	tableNames, err := g.indexStorageClient.ListTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := make(map[string]orphanedChunksTable, len(tableNames))
+	for _, tableName := range tableNames {
+		if period, ok := SchemaPeriodForTable(g.schemaConfig, tableName); !ok || period.From != g.period.From {
+			continue
+		}
+
+		g.indexStorageClient.RefreshIndexTableCache(ctx, tableName)
+		commonIndexFiles, users, err := g.indexStorageClient.ListFiles(ctx, tableName, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of table %s: %w", tableName, err)
+		}
+
+		t := orphanedChunksTable{hasCommonIndex: len(commonIndexFiles) > 0, users: make(map[string]struct{}, len(users))}
+		for _, userID := range users {
+			t.users[userID] = struct{}{}
+		}
+		tables[tableName] = t
+	}
+	return tables, nil
+}
+
+// findOrphanedChunks returns the external keys of the chunks of the tenant older than the grace period which are
+// not referenced by the index tables they overlap.
+func (g *orphanedChunksCollector) findOrphanedChunks(ctx context.Context, tenant string, tables map[string]orphanedChunksTable, indexCompactor IndexCompactor) ([]string, error) {
+	objects, _, err := g.objectClient.List(ctx, tenant+"/", "")
+	if err != nil {
+		return nil, err
+	}
+
+	maxModifiedAt := g.now().Add(-g.cfg.GracePeriod)
+	candidates := map[string]struct{}{}
+	tablesToRead := map[string]struct{}{}
+	for _, object := range objects {
+		if object.ModifiedAt.After(maxModifiedAt) {
+			continue
+		}
+
+		chk, chunkID, ok := g.parseObjectKey(tenant, object.Key)
+		if !ok {
+			continue
+		}
+		if period, err := g.schemaConfig.SchemaForTime(chk.From); err != nil || period.From != g.period.From {
+			continue
+		}
+
+		chunkTables := g.tablesFor(chk.From, chk.Through)
+		safe := true
+		for _, tableName := range chunkTables {
+			if tables[tableName].hasCommonIndex {
+				safe = false
+				break
+			}
+		}
+		if !safe {
+			continue
+		}
+
+		candidates[chunkID] = struct{}{}
+		for _, tableName := range chunkTables {
+			if _, ok := tables[tableName].users[tenant]; ok {
+				tablesToRead[tableName] = struct{}{}
+			}
+		}
+	}
+
+	for tableName := range tablesToRead {
+		if len(candidates) == 0 {
+			break
+		}
+		if err := g.removeReferencedChunks(ctx, tableName, tenant, indexCompactor, candidates); err != nil {
+			return nil, fmt.Errorf("failed to read the index of table %s: %w", tableName, err)
+		}
+	}
+
+	orphans := make([]string, 0, len(candidates))
+	for chunkID := range candidates {
+		orphans = append(orphans, chunkID)
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// removeReferencedChunks removes the chunks referenced by the index of the tenant in the table from the candidates.
+func (g *orphanedChunksCollector) removeReferencedChunks(ctx context.Context, tableName, tenant string, indexCompactor IndexCompactor, candidates map[string]struct{}) error {
+	workingDir := filepath.Join(g.workingDir, tableName, tenant)
+	defer func() {
+		if err := os.RemoveAll(filepath.Join(g.workingDir, tableName)); err != nil {
+			level.Error(g.logger).Log("msg", "failed to remove working directory", "table-name", tableName, "err", err)
+		}
+	}()
+
+	is, err := newUserIndexSet(ctx, tableName, tenant, storage.NewIndexSet(g.indexStorageClient, true), workingDir, g.logger)
+	if err != nil {
+		return err
+	}
+
+	for _, indexFile := range is.ListSourceFiles() {
+		path, err := is.GetSourceFile(indexFile)
+		if err != nil {
+			return err
+		}
+
+		compactedIndex, err := indexCompactor.OpenCompactedIndexFile(ctx, path, tableName, tenant, workingDir, g.period, is.GetLogger())
+		if err != nil {
+			return err
+		}
+
+		err = compactedIndex.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+			delete(candidates, string(ce.ChunkID))
+			return false, nil
+		})
+		compactedIndex.Cleanup()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tablesFor returns the index tables of the period overlapping the interval.
+func (g *orphanedChunksCollector) tablesFor(from, through model.Time) []string {
+	periodSecs := int64(g.period.IndexTables.Period / time.Second)
+	if periodSecs == 0 {
+		return []string{g.period.IndexTables.TableFor(from)}
+	}
+
+	var tables []string
+	for i := from.Unix() / periodSecs; i <= through.Unix()/periodSecs; i++ {
+		tables = append(tables, g.period.IndexTables.TableFor(model.TimeFromUnix(i*periodSecs)))
+	}
+	return tables
+}
+
+// parseObjectKey returns the chunk and the external key of an object, which is not a chunk when false is returned.
+func (g *orphanedChunksCollector) parseObjectKey(tenant, key string) (chunk.Chunk, string, bool) {
+	externalKey := key
+	if g.fsEncoded {
+		split := strings.LastIndexByte(key, '/')
+		decoded, err := base64.StdEncoding.DecodeString(key[split+1:])
+		if err != nil {
+			return chunk.Chunk{}, "", false
+		}
+		externalKey = key[:split+1] + string(decoded)
+	}
+
+	chk, err := chunk.ParseExternalKey(tenant, externalKey)
+	if err != nil {
+		return chunk.Chunk{}, "", false
+	}
+	return chk, externalKey, true
+}
+
+func (g *orphanedChunksCollector) deleteChunk(ctx context.Context, tenant, chunkID string) error {
+	if err := g.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	err := g.chunkClient.DeleteChunk(ctx, tenant, chunkID)
+	if err != nil && !g.chunkClient.IsChunkNotFoundErr(err) {
+		return err
+	}
+	level.Info(g.logger).Log("msg", "deleted orphaned chunk", "user-id", tenant, "chunk-id", chunkID)
+	return nil
+}
+
+func (g *orphanedChunksCollector) readMarks() (orphanedChunksMarks, error) {
+	data, err := os.ReadFile(filepath.Join(g.workingDir, orphanedChunksMarksFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return orphanedChunksMarks{}, nil
+		}
+		return orphanedChunksMarks{}, err
+	}
+
+	var marks orphanedChunksMarks
+	if err := json.Unmarshal(data, &marks); err != nil {
+		return orphanedChunksMarks{}, fmt.Errorf("failed to decode the orphaned chunks marks: %w", err)
+	}
+	return marks, nil
+}
+
+func (g *orphanedChunksCollector) writeMarks(marks orphanedChunksMarks) error {
+	data, err := json.Marshal(marks)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(g.workingDir, orphanedChunksMarksFile)
+	if err := os.WriteFile(path+".tmp", data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// runOrphanedChunksGC runs the orphaned chunks garbage collection of the periods which support it.
+func (c *Compactor) runOrphanedChunksGC(ctx context.Context) {
+	status := statusSuccess
+	for from, sc := range c.storeContainers {
+		if sc.orphanedChunksCollector == nil {
+			continue
+		}
+
+		indexCompactor, ok := c.indexCompactors[sc.orphanedChunksCollector.period.IndexType]
+		if !ok {
+			level.Error(util_log.Logger).Log("msg", "index processor not found for orphaned chunks gc", "index-type", sc.orphanedChunksCollector.period.IndexType)
+			status = statusFailure
+			continue
+		}
+
+		if err := sc.orphanedChunksCollector.run(ctx, indexCompactor); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to collect orphaned chunks", "period", from.String(), "err", err)
+			status = statusFailure
+		}
+	}
+
+	c.orphanedChunksGCMetrics.runsTotal.WithLabelValues(status).Inc()
+	if status == statusSuccess {
+		c.orphanedChunksGCMetrics.lastSuccessTimestamp.SetToCurrentTime()
+	}
+}
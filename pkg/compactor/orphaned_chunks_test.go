@@ -0,0 +1,165 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+)
+
+// chunkRefsIndexCompactor opens index files holding the external keys of the chunks they reference, one per line.
+type chunkRefsIndexCompactor struct {
+	testIndexCompactor
+}
+
+func (chunkRefsIndexCompactor) OpenCompactedIndexFile(_ context.Context, path, _, userID, _ string, _ config.PeriodConfig, _ log.Logger) (CompactedIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries chunkEntries
+	for _, chunkID := range strings.Fields(string(data)) {
+		entries = append(entries, retention.ChunkEntry{ChunkRef: retention.ChunkRef{UserID: []byte(userID), ChunkID: []byte(chunkID)}})
+	}
+	return chunkRefsIndex{chunkEntries: entries}, nil
+}
+
+type chunkRefsIndex struct {
+	compactedIndex
+	chunkEntries
+}
+
+func (i chunkRefsIndex) ForEachChunk(_ context.Context, callback retention.ChunkEntryCallback) error {
+	for _, e := range i.chunkEntries {
+		if _, err := callback(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestOrphanedChunksCollector(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Now()
+	tableSecs := int64(config.ObjectStorageIndexRequiredPeriod / time.Second)
+	firstTableNum := now.Add(-5*24*time.Hour).Unix() / tableSecs
+
+	period := config.PeriodConfig{
+		From:       config.DayTime{Time: model.TimeFromUnix(now.Add(-10 * 24 * time.Hour).Unix())},
+		IndexType:  "tsdb",
+		ObjectType: "filesystem",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			}},
+	}
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{period}}
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaCfg)
+	indexStorageClient := storage.NewIndexStorageClient(objectClient, period.IndexTables.PathPrefix)
+
+	tableName := func(i int64) string {
+		return period.IndexTables.TableFor(model.TimeFromUnix((firstTableNum + i) * tableSecs))
+	}
+	putChunk := func(from, through model.Time, modifiedAt time.Time) string {
+		chk := chunk.Chunk{ChunkRef: logproto.ChunkRef{UserID: "user1", Fingerprint: 1, From: from, Through: through, Checksum: uint32(from)}}
+		key := client.FSEncoder(schemaCfg, chk)
+		require.NoError(t, objectClient.PutObject(context.Background(), key, bytes.NewReader([]byte("chunk"))))
+		require.NoError(t, os.Chtimes(filepath.Join(tempDir, key), modifiedAt, modifiedAt))
+		return schemaCfg.ExternalKey(chk.ChunkRef)
+	}
+	putIndex := func(table, userID string, chunkIDs ...string) {
+		content := strings.NewReader(strings.Join(chunkIDs, "\n"))
+		if userID == "" {
+			require.NoError(t, indexStorageClient.PutFile(context.Background(), table, "common", content))
+			return
+		}
+		require.NoError(t, indexStorageClient.PutUserFile(context.Background(), table, userID, "index", content))
+	}
+
+	tableStart := func(i int64) model.Time {
+		return model.TimeFromUnix((firstTableNum + i) * tableSecs)
+	}
+	old := now.Add(-48 * time.Hour)
+	var (
+		referenced = putChunk(tableStart(0).Add(time.Hour), tableStart(0).Add(2*time.Hour), old)
+		orphaned   = putChunk(tableStart(0).Add(3*time.Hour), tableStart(0).Add(4*time.Hour), old)
+		// the chunk overlapping two tables is only referenced by the second one.
+		overlapping = putChunk(tableStart(1).Add(-time.Hour), tableStart(1).Add(time.Hour), old)
+		// the chunks of the tables with common index files are not collected.
+		commonIndex = putChunk(tableStart(2).Add(time.Hour), tableStart(2).Add(2*time.Hour), old)
+		// the chunks in the grace period may not be indexed yet.
+		recent = putChunk(tableStart(0).Add(5*time.Hour), tableStart(0).Add(6*time.Hour), now)
+		// the chunks of the tables without index of the tenant are orphaned.
+		unindexed = putChunk(tableStart(3).Add(time.Hour), tableStart(3).Add(2*time.Hour), old)
+	)
+	putIndex(tableName(0), "user1", referenced)
+	putIndex(tableName(1), "user1", overlapping)
+	putIndex(tableName(2), "", commonIndex)
+
+	cfg := OrphanedChunksGCConfig{}
+	cfg.RegisterFlagsWithPrefix("", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.Enabled = true
+	metrics := newOrphanedChunksGCMetrics(prometheus.NewPedanticRegistry())
+	newCollector := func(dryRun bool) *orphanedChunksCollector {
+		cfg.DryRun = dryRun
+		return newOrphanedChunksCollector(cfg, schemaCfg, period, objectClient, chunkClient, indexStorageClient, true,
+			filepath.Join(tempDir, "orphaned-chunks"), rate.NewLimiter(rate.Inf, 1), metrics)
+	}
+	chunkExists := func(chunkID string) bool {
+		chk, err := chunk.ParseExternalKey("user1", chunkID)
+		require.NoError(t, err)
+		_, err = os.Stat(filepath.Join(tempDir, client.FSEncoder(schemaCfg, chk)))
+		return err == nil
+	}
+
+	// the first run only marks the orphaned chunks.
+	require.NoError(t, newCollector(false).run(context.Background(), chunkRefsIndexCompactor{}))
+	marks, err := newCollector(false).readMarks()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{orphaned, unindexed}, marks.Chunks["user1"])
+	for _, chunkID := range []string{referenced, orphaned, overlapping, commonIndex, recent, unindexed} {
+		require.True(t, chunkExists(chunkID))
+	}
+
+	// the chunks marked again are not deleted in dry run mode.
+	require.NoError(t, newCollector(true).run(context.Background(), chunkRefsIndexCompactor{}))
+	require.True(t, chunkExists(orphaned))
+	require.True(t, chunkExists(unindexed))
+
+	// the chunks still orphaned are deleted by the next run, the ones indexed in the meantime are kept.
+	putIndex(tableName(3), "user1", unindexed)
+	require.NoError(t, newCollector(false).run(context.Background(), chunkRefsIndexCompactor{}))
+	require.False(t, chunkExists(orphaned))
+	for _, chunkID := range []string{referenced, overlapping, commonIndex, recent, unindexed} {
+		require.True(t, chunkExists(chunkID))
+	}
+
+	marks, err = newCollector(false).readMarks()
+	require.NoError(t, err)
+	require.Empty(t, marks.Chunks)
+}
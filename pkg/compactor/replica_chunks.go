@@ -0,0 +1,434 @@
+package compactor
+
+import (
+	"container/heap"
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	logql_log "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/util"
+)
+
+type ReplicaChunksMergeConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *ReplicaChunksMergeConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Merge the overlapping chunks of a stream, flushed by each of its ingester replicas, into deduplicated chunks while compacting the index. The replaced chunks are deleted by the retention sweeper after -compactor.retention-delete-delay, so retention must be enabled.")
+}
+
+type replicaChunksMergeMetrics struct {
+	mergedChunks    prometheus.Counter
+	createdChunks   prometheus.Counter
+	bytesSaved      prometheus.Counter
+	failedMerges    prometheus.Counter
+	duplicatesFound prometheus.Counter
+}
+
+func newReplicaChunksMergeMetrics(r prometheus.Registerer) *replicaChunksMergeMetrics {
+	return &replicaChunksMergeMetrics{
+		mergedChunks: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "replica_chunks_merged_total",
+			Help:      "Total number of overlapping chunks replaced by merged chunks",
+		}),
+		createdChunks: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "replica_chunks_merge_created_chunks_total",
+			Help:      "Total number of chunks created by merging overlapping chunks",
+		}),
+		bytesSaved: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "replica_chunks_merge_saved_bytes_total",
+			Help:      "Total number of bytes saved in the object store by merging overlapping chunks",
+		}),
+		failedMerges: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "replica_chunks_merge_failures_total",
+			Help:      "Total number of groups of overlapping chunks which failed to be merged",
+		}),
+		duplicatesFound: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "replica_chunks_merge_duplicate_entries_total",
+			Help:      "Total number of duplicate log entries dropped by merging overlapping chunks",
+		}),
+	}
+}
+
+// replicaChunksMerger merges the overlapping chunks of the streams of a compacted index.
+// With a replication factor above 1, each ingester replica of a stream flushes its own chunks,
+// which are only deduplicated when their IDs match. Their IDs often differ as the replicas cut
+// their chunks at different times, so the data is stored once per replica.
+//
+// The overlapping chunks of a stream are merged into new chunks without duplicate entries, which
+// replace them in the index. The replaced chunks are marked for deletion like expired chunks and
+// deleted by the retention sweeper, to let the queriers refresh their index in the meantime.
+type replicaChunksMerger struct {
+	chunkClient      client.Client
+	schemaCfg        config.SchemaConfig
+	workingDirectory string
+	metrics          *replicaChunksMergeMetrics
+}
+
+func newReplicaChunksMerger(chunkClient client.Client, schemaCfg config.SchemaConfig, workingDirectory string, metrics *replicaChunksMergeMetrics) *replicaChunksMerger {
+	return &replicaChunksMerger{
+		chunkClient:      chunkClient,
+		schemaCfg:        schemaCfg,
+		workingDirectory: workingDirectory,
+		metrics:          metrics,
+	}
+}
+
+type replicaChunkRef struct {
+	userID, chunkID string
+	from, through   model.Time
+}
+
+// mergeChunks merges the overlapping chunks of each stream of the index, and returns true if
+// the index was modified.
+// Only the chunks within the table interval are merged, since the chunks spanning several tables
+// are also indexed by the other tables.
+func (m *replicaChunksMerger) mergeChunks(ctx context.Context, tableName string, index retention.IndexProcessor, logger log.Logger) (bool, error) {
+	tableInterval := retention.ExtractIntervalFromTableName(tableName)
+
+	streams := map[string][]replicaChunkRef{}
+	err := index.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+		if ce.From < tableInterval.Start || ce.Through > tableInterval.End {
+			return false, nil
+		}
+		// The bytes of the entry are only valid during the callback.
+		streamID := string(ce.UserID) + "/" + string(ce.SeriesID)
+		streams[streamID] = append(streams[streamID], replicaChunkRef{
+			userID:  string(ce.UserID),
+			chunkID: string(ce.ChunkID),
+			from:    ce.From,
+			through: ce.Through,
+		})
+		return false, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var markers retention.MarkerStorageWriter
+	replaced := map[string]struct{}{}
+	for _, chunks := range streams {
+		for _, group := range overlappingChunks(chunks) {
+			if ctx.Err() != nil {
+				break
+			}
+			if markers == nil {
+				if markers, err = retention.NewMarkerStorageWriter(m.workingDirectory); err != nil {
+					return false, fmt.Errorf("failed to create marker writer: %w", err)
+				}
+			}
+
+			if err := m.mergeGroup(ctx, group, index, markers); err != nil {
+				m.metrics.failedMerges.Inc()
+				level.Error(logger).Log("msg", "failed to merge overlapping chunks", "chunks", len(group), "from", group[0].from, "err", err)
+				continue
+			}
+			for _, c := range group {
+				replaced[c.chunkID] = struct{}{}
+			}
+		}
+	}
+	if markers != nil {
+		if err := markers.Close(); err != nil {
+			return false, fmt.Errorf("failed to close marker writer: %w", err)
+		}
+	}
+	if len(replaced) == 0 {
+		return false, ctx.Err()
+	}
+
+	// Drop the merged chunks from the index, the chunks replacing them are already indexed.
+	err = index.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+		_, ok := replaced[string(ce.ChunkID)]
+		return ok, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	level.Info(logger).Log("msg", "merged overlapping chunks", "chunks", len(replaced))
+	return true, nil
+}
+
+// overlappingChunks returns the groups of overlapping chunks of a stream.
+func overlappingChunks(chunks []replicaChunkRef) [][]replicaChunkRef {
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].from < chunks[j].from
+	})
+
+	var groups [][]replicaChunkRef
+	for start := 0; start < len(chunks); {
+		end, through := start+1, chunks[start].through
+		for ; end < len(chunks) && chunks[end].from <= through; end++ {
+			if chunks[end].through > through {
+				through = chunks[end].through
+			}
+		}
+		if end-start > 1 {
+			groups = append(groups, chunks[start:end])
+		}
+		start = end
+	}
+	return groups
+}
+
+// mergeGroup merges a group of overlapping chunks sorted by start time into new chunks, which
+// are uploaded and indexed, and marks the chunks of the group for deletion.
+// The chunks are fetched one at a time while merging, when the merge reaches their start time,
+// so that only the chunks overlapping each other are in memory at once.
+// The group is merged entirely or not at all: if the merge fails before the chunks of the group
+// are marked for deletion, the merged chunks are removed from the index and the object store.
+func (m *replicaChunksMerger) mergeGroup(ctx context.Context, group []replicaChunkRef, index retention.IndexProcessor, markers retention.MarkerStorageWriter) (err error) {
+	var (
+		userID          = group[0].userID
+		entries         = &chunkEntriesHeap{}
+		next            int
+		source          chunk.Chunk
+		sourceBytes     int
+		targetSize      int
+		mergedBytes     int
+		mergedChunks    []chunk.Chunk
+		current         *chunkenc.MemChunk
+		lastTs          time.Time
+		linesAtLastTs   []logproto.Entry
+		duplicatesFound int
+		indexedChunks   int
+		marking         bool
+	)
+	defer func() {
+		for _, it := range *entries {
+			_ = it.Close()
+		}
+	}()
+	defer func() {
+		if err == nil || marking || len(mergedChunks) == 0 {
+			return
+		}
+		if rollbackErr := m.discardMergedChunks(context.WithoutCancel(ctx), userID, mergedChunks, indexedChunks, index); rollbackErr != nil {
+			err = fmt.Errorf("%w, and failed to discard the merged chunks: %v", err, rollbackErr)
+		}
+	}()
+
+	open := func() error {
+		chk, err := chunk.ParseExternalKey(userID, group[next].chunkID)
+		if err != nil {
+			return err
+		}
+		chks, err := m.chunkClient.GetChunks(ctx, []chunk.Chunk{chk})
+		if err != nil {
+			return err
+		}
+		if len(chks) != 1 {
+			return fmt.Errorf("expected 1 entry for chunk %s but found %d in storage", group[next].chunkID, len(chks))
+		}
+		facade, ok := chks[0].Data.(*chunkenc.Facade)
+		if !ok {
+			return errors.New("invalid chunk type")
+		}
+		memChunk, ok := facade.LokiChunk().(*chunkenc.MemChunk)
+		if !ok {
+			return errors.New("invalid chunk type")
+		}
+
+		source = chks[0]
+		sourceBytes += facade.Size()
+		targetSize = max(targetSize, facade.Size())
+		if current == nil {
+			current = chunkenc.NewMemChunkLike(memChunk, targetSize)
+		}
+
+		it, err := memChunk.Iterator(ctx, group[next].from.Time(), group[next].through.Time().Add(time.Millisecond), logproto.FORWARD, logql_log.NewNoopPipeline().ForStream(labels.Labels{}))
+		if err != nil {
+			return err
+		}
+		next++
+		if !it.Next() {
+			err := it.Error()
+			_ = it.Close()
+			return err
+		}
+		heap.Push(entries, it)
+		return nil
+	}
+
+	cut := func() error {
+		if err := current.Close(); err != nil {
+			return err
+		}
+		from, through := util.RoundToMilliseconds(current.Bounds())
+		merged := chunk.NewChunk(userID, source.FingerprintModel(), source.Metric, chunkenc.NewFacade(current, 0, 0), from, through)
+		merged.StorageClass = source.StorageClass
+		if err := merged.Encode(); err != nil {
+			return err
+		}
+		encoded, err := merged.Encoded()
+		if err != nil {
+			return err
+		}
+		// Upload the merged chunks as they are cut to avoid holding the whole group in memory.
+		if err := m.chunkClient.PutChunks(ctx, []chunk.Chunk{merged}); err != nil {
+			return err
+		}
+		mergedBytes += len(encoded)
+		mergedChunks = append(mergedChunks, merged)
+		current = chunkenc.NewMemChunkLike(current, targetSize)
+		return nil
+	}
+
+	for {
+		// Open the chunks starting before the next entry, which might hold entries preceding it or duplicating it.
+		for next < len(group) && (entries.Len() == 0 || !group[next].from.Time().After((*entries)[0].Entry().Timestamp)) {
+			if err := open(); err != nil {
+				return err
+			}
+		}
+		if entries.Len() == 0 {
+			break
+		}
+
+		it := (*entries)[0]
+		entry := it.Entry()
+		if it.Next() {
+			heap.Fix(entries, 0)
+		} else {
+			heap.Pop(entries)
+			if err := it.Error(); err != nil {
+				return err
+			}
+			_ = it.Close()
+		}
+
+		// The replicas of an entry have the same timestamp, line and structured metadata.
+		if !entry.Timestamp.Equal(lastTs) {
+			lastTs = entry.Timestamp
+			linesAtLastTs = linesAtLastTs[:0]
+		} else if containsEntry(linesAtLastTs, entry) {
+			duplicatesFound++
+			continue
+		}
+		linesAtLastTs = append(linesAtLastTs, entry)
+
+		if !current.SpaceFor(&entry) && current.Size() > 0 {
+			if err := cut(); err != nil {
+				return err
+			}
+		}
+		if err := current.Append(&entry); err != nil {
+			return err
+		}
+	}
+	if current != nil && current.Size() > 0 {
+		if err := cut(); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for _, merged := range mergedChunks {
+		indexed, err := index.IndexChunk(merged)
+		if err != nil {
+			return err
+		}
+		if !indexed {
+			return fmt.Errorf("merged chunk [%s,%s] is out of the range of the table", merged.From, merged.Through)
+		}
+		indexedChunks++
+	}
+	// The merged chunks replace the chunks of the group from here on.
+	marking = true
+	for _, c := range group {
+		if err := markers.Put([]byte(c.chunkID)); err != nil {
+			return err
+		}
+	}
+
+	m.metrics.mergedChunks.Add(float64(len(group)))
+	m.metrics.createdChunks.Add(float64(len(mergedChunks)))
+	m.metrics.duplicatesFound.Add(float64(duplicatesFound))
+	if sourceBytes > mergedBytes {
+		m.metrics.bytesSaved.Add(float64(sourceBytes - mergedBytes))
+	}
+	return nil
+}
+
+// discardMergedChunks removes the first indexed merged chunks from the index, and deletes all
+// the merged chunks from the object store, after a group failed to be merged.
+func (m *replicaChunksMerger) discardMergedChunks(ctx context.Context, userID string, mergedChunks []chunk.Chunk, indexed int, index retention.IndexProcessor) error {
+	chunkIDs := make(map[string]struct{}, len(mergedChunks))
+	for _, merged := range mergedChunks {
+		chunkIDs[m.schemaCfg.ExternalKey(merged.ChunkRef)] = struct{}{}
+	}
+
+	if indexed > 0 {
+		err := index.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+			_, ok := chunkIDs[string(ce.ChunkID)]
+			return ok, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for chunkID := range chunkIDs {
+		if err := m.chunkClient.DeleteChunk(ctx, userID, chunkID); err != nil && !m.chunkClient.IsChunkNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsEntry returns true if entries contain an entry with the same line and structured metadata.
+func containsEntry(entries []logproto.Entry, entry logproto.Entry) bool {
+	for _, e := range entries {
+		if e.Line == entry.Line && slices.Equal(e.StructuredMetadata, entry.StructuredMetadata) {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkEntriesHeap orders the iterators of the chunks being merged by the timestamp of their current entry.
+type chunkEntriesHeap []iter.EntryIterator
+
+func (h chunkEntriesHeap) Len() int { return len(h) }
+func (h chunkEntriesHeap) Less(i, j int) bool {
+	return h[i].Entry().Timestamp.Before(h[j].Entry().Timestamp)
+}
+func (h chunkEntriesHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *chunkEntriesHeap) Push(x any) {
+	*h = append(*h, x.(iter.EntryIterator))
+}
+
+func (h *chunkEntriesHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
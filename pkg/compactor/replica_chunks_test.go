@@ -0,0 +1,241 @@
+package compactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	logql_log "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+)
+
+type fakeIndexProcessor struct {
+	chunkEntries
+	schemaCfg config.SchemaConfig
+	deleted   map[string]struct{}
+	indexed   []chunk.Chunk
+	// maxIndexed is the number of chunks which can be indexed before IndexChunk fails, when positive.
+	maxIndexed int
+}
+
+func (f *fakeIndexProcessor) ForEachChunk(_ context.Context, callback retention.ChunkEntryCallback) error {
+	for _, e := range f.chunkEntries {
+		if _, ok := f.deleted[string(e.ChunkID)]; ok {
+			continue
+		}
+		deleteChunk, err := callback(e)
+		if err != nil {
+			return err
+		}
+		if deleteChunk {
+			f.deleted[string(e.ChunkID)] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (f *fakeIndexProcessor) IndexChunk(chk chunk.Chunk) (bool, error) {
+	if f.maxIndexed > 0 && len(f.indexed) == f.maxIndexed {
+		return false, errors.New("failed to index chunk")
+	}
+	f.indexed = append(f.indexed, chk)
+	f.chunkEntries = append(f.chunkEntries, retention.ChunkEntry{
+		ChunkRef: retention.ChunkRef{
+			UserID:   []byte(chk.UserID),
+			SeriesID: []byte(chk.Metric.String()),
+			ChunkID:  []byte(f.schemaCfg.ExternalKey(chk.ChunkRef)),
+			From:     chk.From,
+			Through:  chk.Through,
+		},
+		Labels: chk.Metric,
+	})
+	return true, nil
+}
+
+func (f *fakeIndexProcessor) CleanupSeries(_ []byte, _ labels.Labels) error {
+	return nil
+}
+
+func TestReplicaChunksMerger(t *testing.T) {
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{{
+		From:       config.DayTime{Time: 0},
+		IndexType:  types.TSDBType,
+		ObjectType: types.StorageTypeFileSystem,
+		Schema:     "v13",
+		RowShards:  16,
+	}}}
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaCfg)
+
+	index := &fakeIndexProcessor{schemaCfg: schemaCfg, deleted: map[string]struct{}{}}
+	putChunk := func(lbs labels.Labels, entries []logproto.Entry) string {
+		return putReplicaChunk(t, chunkClient, index, lbs, entries)
+	}
+	lines := func(from, to int, skip ...int) []logproto.Entry {
+		var entries []logproto.Entry
+	next:
+		for i := from; i < to; i++ {
+			for _, s := range skip {
+				if i == s {
+					continue next
+				}
+			}
+			entries = append(entries, logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: fmt.Sprintf("line %d", i)})
+		}
+		return entries
+	}
+
+	// Three replicas of a stream, cutting their chunks at different times.
+	stream := labels.FromStrings("app", "foo")
+	replicas := []string{
+		putChunk(stream, append(lines(0, 10), append([]logproto.Entry{{Timestamp: time.Unix(10, 0), Line: "other line"}}, lines(10, 50)...)...)),
+		putChunk(stream, lines(50, 100)),
+		putChunk(stream, lines(0, 30)),
+		putChunk(stream, lines(30, 100)),
+		putChunk(stream, lines(0, 100, 42)),
+	}
+	// A stream without overlapping chunks, and chunks spanning the next table.
+	other := labels.FromStrings("app", "bar")
+	untouched := []string{
+		putChunk(other, lines(0, 50)),
+		putChunk(other, lines(50, 100)),
+		putChunk(stream, lines(86399, 86401)),
+		putChunk(stream, lines(86400, 86402)),
+	}
+
+	workingDir := t.TempDir()
+	metrics := newReplicaChunksMergeMetrics(prometheus.NewPedanticRegistry())
+	merger := newReplicaChunksMerger(chunkClient, schemaCfg, workingDir, metrics)
+	modified, err := merger.mergeChunks(context.Background(), "index_00000", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.True(t, modified)
+
+	// The replica chunks were replaced by the merged chunks.
+	require.Len(t, index.deleted, len(replicas))
+	for _, chunkID := range replicas {
+		require.Contains(t, index.deleted, chunkID)
+	}
+	for _, chunkID := range untouched {
+		require.NotContains(t, index.deleted, chunkID)
+	}
+	require.NotEmpty(t, index.indexed)
+	for _, chk := range index.indexed {
+		require.NotContains(t, index.deleted, schemaCfg.ExternalKey(chk.ChunkRef))
+	}
+
+	chks, err := chunkClient.GetChunks(context.Background(), index.indexed)
+	require.NoError(t, err)
+	require.Len(t, chks, len(index.indexed))
+	var merged []logproto.Entry
+	for _, chk := range chks {
+		require.Equal(t, stream, chk.Metric)
+		it, err := chk.Data.(*chunkenc.Facade).LokiChunk().Iterator(context.Background(), time.Unix(0, 0), time.Unix(100, 0), logproto.FORWARD, logql_log.NewNoopPipeline().ForStream(labels.Labels{}))
+		require.NoError(t, err)
+		for it.Next() {
+			merged = append(merged, it.Entry())
+		}
+		require.NoError(t, it.Close())
+	}
+	// The entries with the same timestamp but different lines are all kept.
+	require.ElementsMatch(t, append(lines(0, 100), logproto.Entry{Timestamp: time.Unix(10, 0), Line: "other line"}), merged)
+
+	// The replica chunks are marked for deletion.
+	markers, err := os.ReadDir(filepath.Join(workingDir, retention.MarkersFolder))
+	require.NoError(t, err)
+	require.Len(t, markers, 1)
+
+	require.Equal(t, float64(len(replicas)), testutil.ToFloat64(metrics.mergedChunks))
+	require.Equal(t, float64(len(index.indexed)), testutil.ToFloat64(metrics.createdChunks))
+	require.Equal(t, 199.0, testutil.ToFloat64(metrics.duplicatesFound))
+	require.Greater(t, testutil.ToFloat64(metrics.bytesSaved), 0.0)
+}
+
+func TestReplicaChunksMerger_IndexingFailure(t *testing.T) {
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{{
+		From:       config.DayTime{Time: 0},
+		IndexType:  types.TSDBType,
+		ObjectType: types.StorageTypeFileSystem,
+		Schema:     "v13",
+		RowShards:  16,
+	}}}
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaCfg)
+
+	// The replicas have different lines, so that the merged entries need more than one chunk.
+	index := &fakeIndexProcessor{schemaCfg: schemaCfg, deleted: map[string]struct{}{}, maxIndexed: 1}
+	stream := labels.FromStrings("app", "foo")
+	var first, second []logproto.Entry
+	for i := 0; i < 100; i++ {
+		first = append(first, logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: fmt.Sprintf("line %d", i)})
+		second = append(second, logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: fmt.Sprintf("other line %d", i)})
+	}
+	replicas := []string{
+		putReplicaChunk(t, chunkClient, index, stream, first),
+		putReplicaChunk(t, chunkClient, index, stream, second),
+	}
+
+	metrics := newReplicaChunksMergeMetrics(prometheus.NewPedanticRegistry())
+	merger := newReplicaChunksMerger(chunkClient, schemaCfg, t.TempDir(), metrics)
+	modified, err := merger.mergeChunks(context.Background(), "index_00000", index, log.NewNopLogger())
+	require.NoError(t, err)
+	require.False(t, modified)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.failedMerges))
+
+	// The merged chunk which was indexed before the failure was removed from the index, and
+	// none of the merged chunks were left in the object store.
+	require.Len(t, index.indexed, 1)
+	var chunkIDs []string
+	require.NoError(t, index.ForEachChunk(context.Background(), func(ce retention.ChunkEntry) (bool, error) {
+		chunkIDs = append(chunkIDs, string(ce.ChunkID))
+		return false, nil
+	}))
+	require.ElementsMatch(t, replicas, chunkIDs)
+	_, err = chunkClient.GetChunks(context.Background(), index.indexed)
+	require.True(t, chunkClient.IsChunkNotFoundErr(err))
+}
+
+// putReplicaChunk uploads a chunk of the stream with the entries and adds it to the index.
+func putReplicaChunk(t *testing.T, chunkClient client.Client, index *fakeIndexProcessor, lbs labels.Labels, entries []logproto.Entry) string {
+	memChk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+	for i := range entries {
+		require.NoError(t, memChk.Append(&entries[i]))
+	}
+	require.NoError(t, memChk.Close())
+	from, through := memChk.Bounds()
+	c := chunk.NewChunk("user", model.Fingerprint(lbs.Hash()), lbs, chunkenc.NewFacade(memChk, 0, 0), model.TimeFromUnixNano(from.UnixNano()), model.TimeFromUnixNano(through.UnixNano()))
+	require.NoError(t, c.Encode())
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{c}))
+
+	chunkID := index.schemaCfg.ExternalKey(c.ChunkRef)
+	index.chunkEntries = append(index.chunkEntries, retention.ChunkEntry{
+		ChunkRef: retention.ChunkRef{
+			UserID:   []byte("user"),
+			SeriesID: []byte(lbs.String()),
+			ChunkID:  []byte(chunkID),
+			From:     c.From,
+			Through:  c.Through,
+		},
+		Labels: lbs,
+	})
+	return chunkID
+}
@@ -23,6 +23,8 @@ type IntervalFilter struct {
 
 type ExpirationChecker interface {
 	Expired(ref ChunkEntry, now model.Time) (bool, filter.Func)
+	// Redacted tells if some lines of a chunk are rewritten, using the returned filter.RewriteFunc.
+	Redacted(ref ChunkEntry) (bool, filter.RewriteFunc)
 	IntervalMayHaveExpiredChunks(interval model.Interval, userID string) bool
 	MarkPhaseStarted()
 	MarkPhaseFailed()
@@ -60,6 +62,10 @@ func (e *expirationChecker) Expired(ref ChunkEntry, now model.Time) (bool, filte
 	return now.Sub(ref.Through) > period, nil
 }
 
+func (e *expirationChecker) Redacted(_ ChunkEntry) (bool, filter.RewriteFunc) {
+	return false, nil
+}
+
 // DropFromIndex tells if it is okay to drop the chunk entry from index table.
 // We check if tableEndTime is out of retention period, calculated using the labels from the chunk.
 // If the tableEndTime is out of retention then we can drop the chunk entry without removing the chunk from the store.
@@ -110,6 +116,9 @@ type neverExpiringExpirationChecker struct{}
 func (e *neverExpiringExpirationChecker) Expired(_ ChunkEntry, _ model.Time) (bool, filter.Func) {
 	return false, nil
 }
+func (e *neverExpiringExpirationChecker) Redacted(_ ChunkEntry) (bool, filter.RewriteFunc) {
+	return false, nil
+}
 func (e *neverExpiringExpirationChecker) IntervalMayHaveExpiredChunks(_ model.Interval, _ string) bool {
 	return false
 }
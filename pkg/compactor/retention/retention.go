@@ -100,6 +100,13 @@ func NewMarker(workingDirectory string, expiration ExpirationChecker, markTimeou
 	}, nil
 }
 
+// WithExpirationChecker returns a Marker marking the chunks expired according to the given ExpirationChecker.
+func (t *Marker) WithExpirationChecker(expiration ExpirationChecker) *Marker {
+	marker := *t
+	marker.expiration = expiration
+	return &marker
+}
+
 // MarkForDelete marks all chunks expired for a given table.
 func (t *Marker) MarkForDelete(ctx context.Context, tableName, userID string, indexProcessor IndexProcessor, logger log.Logger) (bool, bool, error) {
 	start := time.Now()
@@ -179,13 +186,21 @@ func markForDelete(
 		chunksFound = true
 		seriesMap.Add(c.SeriesID, c.UserID, c.Labels)
 
-		// see if the chunk is deleted completely or partially
-		if expired, filterFunc := expiration.Expired(c, now); expired {
-			linesDeleted := true // tracks whether we deleted at least some data from the chunk
-			if filterFunc != nil {
+		// see if the chunk is deleted completely or partially, or if some of its lines are redacted
+		expired, filterFunc := expiration.Expired(c, now)
+		var (
+			redacted    bool
+			rewriteFunc filter.RewriteFunc
+		)
+		if !expired || filterFunc != nil {
+			redacted, rewriteFunc = expiration.Redacted(c)
+		}
+		if expired || redacted {
+			linesDeleted := true // tracks whether we deleted or rewrote at least some data from the chunk
+			if filterFunc != nil || rewriteFunc != nil {
 				wroteChunks := false
 				var err error
-				wroteChunks, linesDeleted, err = chunkRewriter.rewriteChunk(ctx, c, tableInterval, filterFunc)
+				wroteChunks, linesDeleted, err = chunkRewriter.rewriteChunk(ctx, c, tableInterval, filterFunc, rewriteFunc)
 				if err != nil {
 					return false, fmt.Errorf("failed to rewrite chunk %s with error %s", c.ChunkID, err)
 				}
@@ -203,7 +218,7 @@ func markForDelete(
 				// Mark the chunk for deletion only if it is completely deleted, or this is the last table that the chunk is index in.
 				// For a partially deleted chunk, if we delete the source chunk before all the tables which index it are processed then
 				// the retention would fail because it would fail to find it in the storage.
-				if filterFunc == nil || c.From >= tableInterval.Start {
+				if (filterFunc == nil && rewriteFunc == nil) || c.From >= tableInterval.Start {
 					if err := marker.Put(c.ChunkID); err != nil {
 						return false, err
 					}
@@ -342,13 +357,14 @@ func newChunkRewriter(chunkClient client.Client, tableName string, chunkIndexer
 	}
 }
 
-// rewriteChunk rewrites a chunk after filtering out logs using filterFunc.
-// It first builds a newChunk using filterFunc.
+// rewriteChunk rewrites a chunk after filtering out logs using filterFunc, and rewriting the lines left using rewriteFunc.
+// Either of them can be nil.
+// It first builds a newChunk using filterFunc and rewriteFunc.
 // If the newChunk is same as the original chunk then there is nothing to do here, wroteChunks and linesDeleted both would be false.
 // If the newChunk is different, linesDeleted would be true.
 // The newChunk is indexed and uploaded only if it belongs to the current index table being processed,
 // the status of which is set to wroteChunks.
-func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, tableInterval model.Interval, filterFunc filter.Func) (wroteChunks bool, linesDeleted bool, err error) {
+func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, tableInterval model.Interval, filterFunc filter.Func, rewriteFunc filter.RewriteFunc) (wroteChunks bool, linesDeleted bool, err error) {
 	userID := unsafeGetString(ce.UserID)
 	chunkID := unsafeGetString(ce.ChunkID)
 
@@ -366,14 +382,30 @@ func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, tableIn
 		return false, false, fmt.Errorf("expected 1 entry for chunk %s but found %d in storage", chunkID, len(chks))
 	}
 
-	newChunkData, err := chks[0].Data.Rebound(ce.From, ce.Through, func(ts time.Time, s string, structuredMetadata ...labels.Label) bool {
-		if filterFunc(ts, s, structuredMetadata...) {
+	var newChunkData chunk.Data
+	deleteFilter := func(ts time.Time, s string, structuredMetadata ...labels.Label) bool {
+		if filterFunc != nil && filterFunc(ts, s, structuredMetadata...) {
 			linesDeleted = true
 			return true
 		}
 
 		return false
-	})
+	}
+	if rewriteFunc == nil {
+		newChunkData, err = chks[0].Data.Rebound(ce.From, ce.Through, deleteFilter)
+	} else {
+		facade, ok := chks[0].Data.(*chunkenc.Facade)
+		if !ok {
+			return false, false, errors.New("invalid chunk type")
+		}
+		newChunkData, err = facade.Rewrite(ce.From, ce.Through, deleteFilter, func(ts time.Time, s string, structuredMetadata ...labels.Label) (string, bool) {
+			line, rewritten := rewriteFunc(ts, s, structuredMetadata...)
+			if rewritten {
+				linesDeleted = true
+			}
+			return line, rewritten
+		})
+	}
 	if err != nil {
 		if errors.Is(err, chunk.ErrSliceNoDataInRange) {
 			level.Info(util_log.Logger).Log("msg", "Delete request filterFunc leaves an empty chunk", "chunk ref", string(ce.ChunkRef.ChunkID))
@@ -405,6 +437,7 @@ func (c *chunkRewriter) rewriteChunk(ctx context.Context, ce ChunkEntry, tableIn
 		newChunkStart,
 		newChunkEnd,
 	)
+	newChunk.StorageClass = chks[0].StorageClass
 
 	err = newChunk.Encode()
 	if err != nil {
@@ -516,7 +516,7 @@ func TestChunkRewriter(t *testing.T) {
 			for _, indexTable := range indexTables {
 				cr := newChunkRewriter(store.chunkClient, indexTable.name, indexTable)
 
-				wroteChunks, linesDeleted, err := cr.rewriteChunk(context.Background(), entryFromChunk(tt.chunk), ExtractIntervalFromTableName(indexTable.name), tt.filterFunc)
+				wroteChunks, linesDeleted, err := cr.rewriteChunk(context.Background(), entryFromChunk(tt.chunk), ExtractIntervalFromTableName(indexTable.name), tt.filterFunc, nil)
 				require.NoError(t, err)
 				require.Equal(t, tt.expectedRespByTables[indexTable.name].mustDeleteLines, linesDeleted)
 				require.Equal(t, tt.expectedRespByTables[indexTable.name].mustRewriteChunk, wroteChunks)
@@ -592,8 +592,10 @@ func (s *seriesCleanedRecorder) CleanupSeries(userID []byte, lbls labels.Labels)
 }
 
 type chunkExpiry struct {
-	isExpired  bool
-	filterFunc filter.Func
+	isExpired   bool
+	filterFunc  filter.Func
+	isRedacted  bool
+	rewriteFunc filter.RewriteFunc
 }
 
 type mockExpirationChecker struct {
@@ -616,6 +618,11 @@ func (m *mockExpirationChecker) Expired(ref ChunkEntry, _ model.Time) (bool, fil
 	return ce.isExpired, ce.filterFunc
 }
 
+func (m *mockExpirationChecker) Redacted(ref ChunkEntry) (bool, filter.RewriteFunc) {
+	ce := m.chunksExpiry[string(ref.ChunkID)]
+	return ce.isRedacted, ce.rewriteFunc
+}
+
 func (m *mockExpirationChecker) DropFromIndex(_ ChunkEntry, _ model.Time, _ model.Time) bool {
 	return false
 }
@@ -742,6 +749,58 @@ func TestMarkForDelete_SeriesCleanup(t *testing.T) {
 				1,
 			},
 		},
+		{
+			name: "only one chunk in store which gets redacted",
+			chunks: []chunk.Chunk{
+				createChunk(t, userID, labels.Labels{labels.Label{Name: "foo", Value: "1"}}, todaysTableInterval.Start, todaysTableInterval.Start.Add(30*time.Minute)),
+			},
+			expiry: []chunkExpiry{
+				{
+					isRedacted: true,
+					rewriteFunc: func(_ time.Time, s string, _ ...labels.Label) (string, bool) {
+						return "<redacted>", true
+					},
+				},
+			},
+			expectedDeletedSeries: []map[uint64]struct{}{
+				nil,
+			},
+			expectedEmpty: []bool{
+				false,
+			},
+			expectedModified: []bool{
+				true,
+			},
+			numChunksDeleted: []int64{
+				1,
+			},
+		},
+		{
+			name: "chunk redacted but no lines matching",
+			chunks: []chunk.Chunk{
+				createChunk(t, userID, labels.Labels{labels.Label{Name: "foo", Value: "1"}}, todaysTableInterval.Start, todaysTableInterval.Start.Add(30*time.Minute)),
+			},
+			expiry: []chunkExpiry{
+				{
+					isRedacted: true,
+					rewriteFunc: func(_ time.Time, s string, _ ...labels.Label) (string, bool) {
+						return s, false
+					},
+				},
+			},
+			expectedDeletedSeries: []map[uint64]struct{}{
+				nil,
+			},
+			expectedEmpty: []bool{
+				false,
+			},
+			expectedModified: []bool{
+				false,
+			},
+			numChunksDeleted: []int64{
+				0,
+			},
+		},
 		{
 			name: "one of two chunks deleted",
 			chunks: []chunk.Chunk{
@@ -0,0 +1,135 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const handedOffMarkersPrefix = "compactor-retention-markers/"
+
+// retentionMarkersHandOff hands off the retention markers of the chunks not deleted yet by the sweeper of a
+// compactor leaving the ring to the compactor leader, through the object store of the period.
+type retentionMarkersHandOff struct {
+	objectClient client.ObjectClient
+	prefix       string
+	markersDir   string
+}
+
+func newRetentionMarkersHandOff(objectClient client.ObjectClient, name, retentionWorkDir string) *retentionMarkersHandOff {
+	return &retentionMarkersHandOff{
+		objectClient: objectClient,
+		prefix:       handedOffMarkersPrefix + name + "/",
+		markersDir:   filepath.Join(retentionWorkDir, retention.MarkersFolder),
+	}
+}
+
+// upload uploads the marker files of the compactor, which are removed once uploaded.
+func (h *retentionMarkersHandOff) upload(ctx context.Context, instanceID string) error {
+	files, err := os.ReadDir(h.markersDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read markers dir: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		filePath := filepath.Join(h.markersDir, file.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("read marker file: %w", err)
+		}
+		// the file names are the creation times of the markers, which are kept for the sweeper to honor the deletion delay.
+		if err := h.objectClient.PutObject(ctx, h.prefix+instanceID+"/"+file.Name(), bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("upload marker file %s: %w", file.Name(), err)
+		}
+		if err := os.Remove(filePath); err != nil {
+			return fmt.Errorf("remove uploaded marker file: %w", err)
+		}
+		level.Info(util_log.Logger).Log("msg", "handed off retention marker file", "file", filePath)
+	}
+	return nil
+}
+
+// download downloads the marker files handed off by the compactors which left the ring, for the sweeper of
+// this compactor to delete the marked chunks. They are deleted from the object store once downloaded.
+func (h *retentionMarkersHandOff) download(ctx context.Context) error {
+	objects, _, err := h.objectClient.List(ctx, h.prefix, "")
+	if err != nil {
+		return fmt.Errorf("list handed off marker files: %w", err)
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	if err := chunk_util.EnsureDirectory(h.markersDir); err != nil {
+		return err
+	}
+	for _, object := range objects {
+		createdAt, err := strconv.ParseInt(path.Base(object.Key), 10, 64)
+		if err != nil {
+			level.Warn(util_log.Logger).Log("msg", "skipping handed off marker file with wrong name", "key", object.Key, "err", err)
+			continue
+		}
+
+		data, err := h.getObject(ctx, object.Key)
+		if err != nil {
+			return err
+		}
+
+		// marker files of several compactors could have been created at the same time.
+		filePath := filepath.Join(h.markersDir, strconv.FormatInt(createdAt, 10))
+		for {
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				break
+			}
+			createdAt++
+			filePath = filepath.Join(h.markersDir, strconv.FormatInt(createdAt, 10))
+		}
+		// the file is renamed once written, for the sweeper to not read it partially.
+		tmpPath := filepath.Join(filepath.Dir(h.markersDir), path.Base(object.Key)+".tmp")
+		if err := os.WriteFile(tmpPath, data, 0o640); err != nil {
+			return fmt.Errorf("write handed off marker file: %w", err)
+		}
+		if err := os.Rename(tmpPath, filePath); err != nil {
+			return fmt.Errorf("rename handed off marker file: %w", err)
+		}
+
+		if err := h.objectClient.DeleteObject(ctx, object.Key); err != nil && !h.objectClient.IsObjectNotFoundErr(err) {
+			return fmt.Errorf("delete handed off marker file %s: %w", object.Key, err)
+		}
+		level.Info(util_log.Logger).Log("msg", "took over handed off retention marker file", "key", object.Key, "file", filePath)
+	}
+	return nil
+}
+
+func (h *retentionMarkersHandOff) getObject(ctx context.Context, key string) ([]byte, error) {
+	reader, _, err := h.objectClient.GetObject(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get handed off marker file %s: %w", key, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read handed off marker file %s: %w", key, err)
+	}
+	return data, nil
+}
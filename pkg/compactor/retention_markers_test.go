@@ -0,0 +1,64 @@
+package compactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+)
+
+func TestRetentionMarkersHandOff(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	writeMarkers := func(workDir string, files map[string]string) {
+		dir := filepath.Join(workDir, retention.MarkersFolder)
+		require.NoError(t, os.MkdirAll(dir, 0o750))
+		for name, data := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o640))
+		}
+	}
+	readMarkers := func(workDir string) map[string]string {
+		entries, err := os.ReadDir(filepath.Join(workDir, retention.MarkersFolder))
+		if os.IsNotExist(err) {
+			return map[string]string{}
+		}
+		require.NoError(t, err)
+
+		files := map[string]string{}
+		for _, entry := range entries {
+			data, err := os.ReadFile(filepath.Join(workDir, retention.MarkersFolder, entry.Name()))
+			require.NoError(t, err)
+			files[entry.Name()] = string(data)
+		}
+		return files
+	}
+
+	// two compactors, which marked chunks at the same time, leave the ring.
+	workDir1, workDir2, leaderWorkDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writeMarkers(workDir1, map[string]string{"100": "a", "200": "b"})
+	writeMarkers(workDir2, map[string]string{"100": "c"})
+	require.NoError(t, newRetentionMarkersHandOff(objectClient, "period", workDir1).upload(context.Background(), "compactor-1"))
+	require.NoError(t, newRetentionMarkersHandOff(objectClient, "period", workDir2).upload(context.Background(), "compactor-2"))
+	require.Empty(t, readMarkers(workDir1))
+	require.Empty(t, readMarkers(workDir2))
+
+	// the markers of another period are not taken over.
+	writeMarkers(leaderWorkDir, map[string]string{"50": "d"})
+	require.NoError(t, newRetentionMarkersHandOff(objectClient, "other-period", leaderWorkDir).download(context.Background()))
+	require.Equal(t, map[string]string{"50": "d"}, readMarkers(leaderWorkDir))
+
+	// the leader takes over the markers, keeping their creation time.
+	handOff := newRetentionMarkersHandOff(objectClient, "period", leaderWorkDir)
+	require.NoError(t, handOff.download(context.Background()))
+	require.Equal(t, map[string]string{"50": "d", "100": "a", "101": "c", "200": "b"}, readMarkers(leaderWorkDir))
+
+	objects, _, err := objectClient.List(context.Background(), handedOffMarkersPrefix, "")
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
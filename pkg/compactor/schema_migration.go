@@ -0,0 +1,655 @@
+package compactor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const schemaMigrationStatusFile = "status.json"
+
+type SchemaMigrationConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Interval   time.Duration `yaml:"interval"`
+	PathPrefix string        `yaml:"path_prefix"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *SchemaMigrationConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Migrate the index of the ended boltdb-shipper schema periods to TSDB. The tables of the periods are rewritten as per tenant TSDB indexes under the path prefix, and validated against the chunks of the boltdb-shipper indexes. Once all the tables of a period are migrated, the schema config switching the period to TSDB is served by the /compactor/schema_migration endpoint, after which the boltdb-shipper indexes of the period can be deleted.")
+	f.DurationVar(&cfg.Interval, prefix+"interval", time.Hour, "Interval at which to look for the tables to migrate. The tables already migrated are migrated again when their boltdb-shipper index changed, like after applying retention.")
+	f.StringVar(&cfg.PathPrefix, prefix+"path-prefix", "index_tsdb/", "Path prefix of the migrated TSDB indexes in the object store of the periods. It must differ from the path prefix of the migrated periods.")
+}
+
+func (cfg *SchemaMigrationConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("schema migration interval must be positive")
+	}
+	if err := config.ValidatePathPrefix(cfg.PathPrefix); err != nil {
+		return fmt.Errorf("validate schema migration path prefix: %w", err)
+	}
+	return nil
+}
+
+type schemaMigrationMetrics struct {
+	tablesMigrated prometheus.Counter
+	tableFailures  prometheus.Counter
+	pendingTables  *prometheus.GaugeVec
+}
+
+func newSchemaMigrationMetrics(r prometheus.Registerer) *schemaMigrationMetrics {
+	return &schemaMigrationMetrics{
+		tablesMigrated: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "schema_migration_tables_migrated_total",
+			Help:      "Total number of boltdb-shipper index tables migrated to TSDB, including the tables migrated again after they changed",
+		}),
+		tableFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "schema_migration_table_failures_total",
+			Help:      "Total number of boltdb-shipper index tables which failed to be migrated or validated",
+		}),
+		pendingTables: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki_compactor",
+			Name:      "schema_migration_pending_tables",
+			Help:      "Number of boltdb-shipper index tables of the schema period left to migrate",
+		}, []string{"period"}),
+	}
+}
+
+// schemaMigrationTable is a table migrated to TSDB.
+type schemaMigrationTable struct {
+	// SourceFiles identify the boltdb-shipper index files the table was migrated from, for migrating it again when they change.
+	SourceFiles []string  `json:"source_files"`
+	MigratedAt  time.Time `json:"migrated_at"`
+	Users       int       `json:"users"`
+	Chunks      int       `json:"chunks"`
+}
+
+// schemaMigrationStatus is the progress of the migration of a period, persisted in its working directory.
+type schemaMigrationStatus struct {
+	LastRun   time.Time `json:"last_run"`
+	LastError string    `json:"last_error,omitempty"`
+	// Ended is true once the period has ended, as its tables are only migrated afterwards.
+	Ended bool `json:"ended"`
+	// PendingTables are the tables left to migrate, including the tables with uncompacted index files.
+	PendingTables []string `json:"pending_tables"`
+	// FailedTables are the errors of the tables which failed to be migrated by the last run, by table name.
+	FailedTables map[string]string               `json:"failed_tables,omitempty"`
+	Tables       map[string]schemaMigrationTable `json:"tables"`
+}
+
+// completed returns true when all the tables of the ended period were migrated by the last run.
+func (s schemaMigrationStatus) completed() bool {
+	return s.Ended && !s.LastRun.IsZero() && s.LastError == "" && len(s.PendingTables) == 0 && len(s.FailedTables) == 0
+}
+
+// schemaMigrator migrates the index of an ended boltdb-shipper period to TSDB.
+//
+// Each table of the period is read with the boltdb-shipper IndexCompactor once compacted, and a TSDB index is built
+// for each tenant from its chunks, with the same fingerprints and chunk references. The TSDB indexes are uploaded
+// under the migration path prefix as per tenant files, like the compacted TSDB tables, then downloaded again and
+// compared with the chunks of the boltdb-shipper index. The chunks themselves are not rewritten, so switching the
+// period to TSDB with the migration path prefix keeps serving the same data.
+type schemaMigrator struct {
+	cfg          SchemaMigrationConfig
+	schemaConfig config.SchemaConfig
+	period       config.PeriodConfig
+	// targetPeriod is the period with the TSDB index the tables are migrated to.
+	targetPeriod config.PeriodConfig
+	// end is the start of the next period, after which the tables of the period are not written to anymore.
+	end          time.Time
+	sourceClient storage.Client
+	targetClient storage.Client
+	workingDir   string
+	metrics      *schemaMigrationMetrics
+	logger       log.Logger
+	now          func() time.Time
+
+	statusMtx sync.Mutex
+	status    schemaMigrationStatus
+}
+
+func newSchemaMigrator(
+	cfg SchemaMigrationConfig,
+	schemaConfig config.SchemaConfig,
+	period config.PeriodConfig,
+	end time.Time,
+	sourceClient storage.Client,
+	targetClient storage.Client,
+	workingDir string,
+	metrics *schemaMigrationMetrics,
+) (*schemaMigrator, error) {
+	targetPeriod := period
+	targetPeriod.IndexType = types.TSDBType
+	targetPeriod.IndexTables.PathPrefix = cfg.PathPrefix
+
+	m := &schemaMigrator{
+		cfg:          cfg,
+		schemaConfig: schemaConfig,
+		period:       period,
+		targetPeriod: targetPeriod,
+		end:          end,
+		sourceClient: sourceClient,
+		targetClient: targetClient,
+		workingDir:   workingDir,
+		metrics:      metrics,
+		logger:       log.With(util_log.Logger, "period", period.From.String()),
+		now:          time.Now,
+	}
+
+	if err := chunk_util.EnsureDirectory(workingDir); err != nil {
+		return nil, err
+	}
+	status, err := m.readStatus()
+	if err != nil {
+		return nil, err
+	}
+	m.status = status
+	return m, nil
+}
+
+// run migrates the tables of the period which were not migrated yet or changed since they were migrated, and deletes
+// the migrated tables which do not exist anymore.
+func (m *schemaMigrator) run(ctx context.Context, sourceCompactor IndexCompactor, targetCompactor IndexCompactor, builderFactory IndexBuilderFactory) error {
+	status := m.getStatus()
+	status.LastRun = m.now()
+	status.LastError = ""
+	status.Ended = !m.now().Before(m.end)
+	status.PendingTables = nil
+	status.FailedTables = nil
+	if status.Tables == nil {
+		status.Tables = map[string]schemaMigrationTable{}
+	}
+
+	err := m.migrateTables(ctx, &status, sourceCompactor, targetCompactor, builderFactory)
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	sort.Strings(status.PendingTables)
+	m.metrics.pendingTables.WithLabelValues(m.period.From.String()).Set(float64(len(status.PendingTables) + len(status.FailedTables)))
+
+	m.setStatus(status)
+	if writeErr := m.writeStatus(status); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+func (m *schemaMigrator) migrateTables(ctx context.Context, status *schemaMigrationStatus, sourceCompactor IndexCompactor, targetCompactor IndexCompactor, builderFactory IndexBuilderFactory) error {
+	sourceTables, err := m.listTables(ctx, m.sourceClient)
+	if err != nil {
+		return err
+	}
+
+	// the tables are still written to until the period has ended.
+	if !status.Ended {
+		status.PendingTables = sourceTables
+		return nil
+	}
+
+	existing := make(map[string]struct{}, len(sourceTables))
+	for _, tableName := range sourceTables {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		existing[tableName] = struct{}{}
+
+		m.sourceClient.RefreshIndexTableCache(ctx, tableName)
+		sourceFiles, compacted, err := m.listSourceFiles(ctx, tableName)
+		if err != nil {
+			return err
+		}
+		if !compacted {
+			level.Info(m.logger).Log("msg", "skipping migration of table with uncompacted index", "table-name", tableName)
+			status.PendingTables = append(status.PendingTables, tableName)
+			delete(status.Tables, tableName)
+			continue
+		}
+		if migrated, ok := status.Tables[tableName]; ok && slices.Equal(migrated.SourceFiles, sourceFiles) {
+			continue
+		}
+
+		migrated, err := m.migrateTable(ctx, tableName, sourceCompactor, targetCompactor, builderFactory)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			level.Error(m.logger).Log("msg", "failed to migrate table", "table-name", tableName, "err", err)
+			m.metrics.tableFailures.Inc()
+			if status.FailedTables == nil {
+				status.FailedTables = map[string]string{}
+			}
+			status.FailedTables[tableName] = err.Error()
+			// the table is not served from the migrated index until migrated again.
+			delete(status.Tables, tableName)
+			continue
+		}
+		migrated.SourceFiles = sourceFiles
+		status.Tables[tableName] = migrated
+		m.metrics.tablesMigrated.Inc()
+		level.Info(m.logger).Log("msg", "migrated table", "table-name", tableName, "users", migrated.Users, "chunks", migrated.Chunks)
+	}
+
+	// the tables deleted by the retention are deleted from the migrated index too.
+	targetTables, err := m.listTables(ctx, m.targetClient)
+	if err != nil {
+		return err
+	}
+	for _, tableName := range targetTables {
+		if _, ok := existing[tableName]; ok {
+			continue
+		}
+		if err := m.deleteMigratedIndexes(ctx, tableName, nil); err != nil {
+			return fmt.Errorf("failed to delete migrated table %s: %w", tableName, err)
+		}
+		delete(status.Tables, tableName)
+		level.Info(m.logger).Log("msg", "deleted migrated table of deleted table", "table-name", tableName)
+	}
+	return nil
+}
+
+// listTables returns the tables of the period in the index of the client.
+func (m *schemaMigrator) listTables(ctx context.Context, indexStorageClient storage.Client) ([]string, error) {
+	indexStorageClient.RefreshIndexTableNamesCache(ctx)
+	tableNames, err := indexStorageClient.ListTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []string
+	for _, tableName := range tableNames {
+		if period, ok := SchemaPeriodForTable(m.schemaConfig, tableName); ok && period.From == m.period.From {
+			tables = append(tables, tableName)
+		}
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// listSourceFiles returns the names and modification times of the boltdb-shipper index files of a table, and whether
+// the table is compacted.
+func (m *schemaMigrator) listSourceFiles(ctx context.Context, tableName string) ([]string, bool, error) {
+	commonIndexFiles, users, err := m.sourceClient.ListFiles(ctx, tableName, false)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list files of table %s: %w", tableName, err)
+	}
+	if len(commonIndexFiles) > 1 {
+		return nil, false, nil
+	}
+
+	var files []string
+	for _, f := range commonIndexFiles {
+		files = append(files, fmt.Sprintf("%s@%d", f.Name, f.ModifiedAt.UnixNano()))
+	}
+	for _, userID := range users {
+		userFiles, err := m.sourceClient.ListUserFiles(ctx, tableName, userID, false)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list files of user %s in table %s: %w", userID, tableName, err)
+		}
+		if len(userFiles) > 1 {
+			return nil, false, nil
+		}
+		for _, f := range userFiles {
+			files = append(files, fmt.Sprintf("%s/%s@%d", userID, f.Name, f.ModifiedAt.UnixNano()))
+		}
+	}
+	sort.Strings(files)
+	return files, true, nil
+}
+
+// migrateTable builds the TSDB index of each user of a table, uploads it in place of the previously migrated one,
+// and validates the uploaded indexes.
+func (m *schemaMigrator) migrateTable(ctx context.Context, tableName string, sourceCompactor IndexCompactor, targetCompactor IndexCompactor, builderFactory IndexBuilderFactory) (schemaMigrationTable, error) {
+	workingDir := filepath.Join(m.workingDir, tableName)
+	defer func() {
+		if err := os.RemoveAll(workingDir); err != nil {
+			level.Error(m.logger).Log("msg", "failed to remove working directory", "table-name", tableName, "err", err)
+		}
+	}()
+
+	builders := map[string]IndexBuilder{}
+	expected := map[string]map[string]struct{}{}
+	addChunk := func(ce retention.ChunkEntry) (bool, error) {
+		userID := string(ce.UserID)
+		b, ok := builders[userID]
+		if !ok {
+			dir := filepath.Join(workingDir, "target", userID)
+			if err := chunk_util.EnsureDirectory(dir); err != nil {
+				return false, err
+			}
+			var err error
+			b, err = builderFactory.NewIndexBuilder(ctx, tableName, userID, dir, m.targetPeriod)
+			if err != nil {
+				return false, err
+			}
+			builders[userID] = b
+			expected[userID] = map[string]struct{}{}
+		}
+
+		chunkID, err := m.normalizeChunkID(userID, string(ce.ChunkID))
+		if err != nil {
+			return false, err
+		}
+		if err := b.AddChunk(ce); err != nil {
+			return false, err
+		}
+		expected[userID][chunkID] = struct{}{}
+		return false, nil
+	}
+
+	commonIndexSet, err := newCommonIndexSet(ctx, tableName, storage.NewIndexSet(m.sourceClient, false), filepath.Join(workingDir, "source"), m.logger)
+	if err != nil {
+		return schemaMigrationTable{}, err
+	}
+	if err := m.readIndexSet(ctx, commonIndexSet, sourceCompactor, addChunk); err != nil {
+		return schemaMigrationTable{}, err
+	}
+
+	_, users, err := m.sourceClient.ListFiles(ctx, tableName, false)
+	if err != nil {
+		return schemaMigrationTable{}, err
+	}
+	for _, userID := range users {
+		userIndexSet, err := newUserIndexSet(ctx, tableName, userID, storage.NewIndexSet(m.sourceClient, true), filepath.Join(workingDir, "source", userID), m.logger)
+		if err != nil {
+			return schemaMigrationTable{}, err
+		}
+		if err := m.readIndexSet(ctx, userIndexSet, sourceCompactor, addChunk); err != nil {
+			return schemaMigrationTable{}, err
+		}
+	}
+
+	// the previously migrated index of each user is replaced once the new one is uploaded.
+	m.targetClient.RefreshIndexTableCache(ctx, tableName)
+	for userID, b := range builders {
+		is, err := newUserIndexSet(ctx, tableName, userID, storage.NewIndexSet(m.targetClient, true), filepath.Join(workingDir, "target", userID), m.logger)
+		if err != nil {
+			return schemaMigrationTable{}, err
+		}
+		is.setCompactedIndex(b, true, true)
+		err = is.done()
+		is.cleanup()
+		if err != nil {
+			return schemaMigrationTable{}, fmt.Errorf("failed to upload index of user %s: %w", userID, err)
+		}
+	}
+	// the users without chunks left in the table are removed from the migrated index.
+	if err := m.deleteMigratedIndexes(ctx, tableName, builders); err != nil {
+		return schemaMigrationTable{}, err
+	}
+
+	migrated := schemaMigrationTable{MigratedAt: m.now(), Users: len(builders)}
+	m.targetClient.RefreshIndexTableCache(ctx, tableName)
+	for userID, chunkIDs := range expected {
+		if err := m.validateUserIndex(ctx, tableName, userID, filepath.Join(workingDir, "validate", userID), targetCompactor, chunkIDs); err != nil {
+			return schemaMigrationTable{}, fmt.Errorf("failed to validate index of user %s: %w", userID, err)
+		}
+		migrated.Chunks += len(chunkIDs)
+	}
+	return migrated, nil
+}
+
+func (m *schemaMigrator) readIndexSet(ctx context.Context, is *indexSet, sourceCompactor IndexCompactor, callback retention.ChunkEntryCallback) error {
+	for _, indexFile := range is.ListSourceFiles() {
+		path, err := is.GetSourceFile(indexFile)
+		if err != nil {
+			return err
+		}
+
+		compactedIndex, err := sourceCompactor.OpenCompactedIndexFile(ctx, path, is.GetTableName(), is.userID, is.GetWorkingDir(), m.period, is.GetLogger())
+		if err != nil {
+			return err
+		}
+		err = compactedIndex.ForEachChunk(ctx, callback)
+		compactedIndex.Cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to read index %s: %w", indexFile.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateUserIndex downloads the migrated index of a user and verifies it references the chunks of the boltdb-shipper index.
+func (m *schemaMigrator) validateUserIndex(ctx context.Context, tableName, userID, workingDir string, targetCompactor IndexCompactor, expected map[string]struct{}) error {
+	is, err := newUserIndexSet(ctx, tableName, userID, storage.NewIndexSet(m.targetClient, true), workingDir, m.logger)
+	if err != nil {
+		return err
+	}
+	if len(is.ListSourceFiles()) != 1 {
+		return fmt.Errorf("expected a single migrated index file, found %d", len(is.ListSourceFiles()))
+	}
+
+	path, err := is.GetSourceFile(is.ListSourceFiles()[0])
+	if err != nil {
+		return err
+	}
+	compactedIndex, err := targetCompactor.OpenCompactedIndexFile(ctx, path, tableName, userID, workingDir, m.targetPeriod, is.GetLogger())
+	if err != nil {
+		return err
+	}
+	defer compactedIndex.Cleanup()
+
+	found := 0
+	err = compactedIndex.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+		chunkID, err := m.normalizeChunkID(userID, string(ce.ChunkID))
+		if err != nil {
+			return false, err
+		}
+		if _, ok := expected[chunkID]; !ok {
+			return false, fmt.Errorf("unexpected chunk %s in migrated index", chunkID)
+		}
+		found++
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	if found != len(expected) {
+		return fmt.Errorf("expected %d chunks in migrated index, found %d", len(expected), found)
+	}
+	return nil
+}
+
+// deleteMigratedIndexes deletes the migrated index files of a table, but the ones of the users to keep.
+func (m *schemaMigrator) deleteMigratedIndexes(ctx context.Context, tableName string, keep map[string]IndexBuilder) error {
+	m.targetClient.RefreshIndexTableCache(ctx, tableName)
+	commonIndexFiles, users, err := m.targetClient.ListFiles(ctx, tableName, false)
+	if err != nil {
+		return err
+	}
+	for _, f := range commonIndexFiles {
+		if err := m.targetClient.DeleteFile(ctx, tableName, f.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, userID := range users {
+		if _, ok := keep[userID]; ok {
+			continue
+		}
+		files, err := m.targetClient.ListUserFiles(ctx, tableName, userID, false)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := m.targetClient.DeleteUserFile(ctx, tableName, userID, f.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeChunkID returns the external key of a chunk in the schema of the period, as the chunk IDs of the indexes
+// may be encoded differently.
+func (m *schemaMigrator) normalizeChunkID(userID, chunkID string) (string, error) {
+	chk, err := chunk.ParseExternalKey(userID, chunkID)
+	if err != nil {
+		return "", err
+	}
+	return config.SchemaConfig{Configs: []config.PeriodConfig{m.targetPeriod}}.ExternalKey(chk.ChunkRef), nil
+}
+
+func (m *schemaMigrator) getStatus() schemaMigrationStatus {
+	m.statusMtx.Lock()
+	defer m.statusMtx.Unlock()
+
+	status := m.status
+	status.Tables = make(map[string]schemaMigrationTable, len(m.status.Tables))
+	for tableName, t := range m.status.Tables {
+		status.Tables[tableName] = t
+	}
+	return status
+}
+
+func (m *schemaMigrator) setStatus(status schemaMigrationStatus) {
+	m.statusMtx.Lock()
+	defer m.statusMtx.Unlock()
+
+	m.status = status
+}
+
+func (m *schemaMigrator) readStatus() (schemaMigrationStatus, error) {
+	data, err := os.ReadFile(filepath.Join(m.workingDir, schemaMigrationStatusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return schemaMigrationStatus{}, nil
+		}
+		return schemaMigrationStatus{}, err
+	}
+
+	var status schemaMigrationStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return schemaMigrationStatus{}, fmt.Errorf("failed to decode the schema migration status: %w", err)
+	}
+	return status, nil
+}
+
+func (m *schemaMigrator) writeStatus(status schemaMigrationStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.workingDir, schemaMigrationStatusFile)
+	if err := os.WriteFile(path+".tmp", data, 0o640); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// runSchemaMigration migrates the boltdb-shipper periods to TSDB.
+func (c *Compactor) runSchemaMigration(ctx context.Context) {
+	sourceCompactor, ok := c.indexCompactors[types.BoltDBShipperType]
+	if !ok {
+		level.Error(util_log.Logger).Log("msg", "index processor not found for schema migration", "index-type", types.BoltDBShipperType)
+		return
+	}
+	targetCompactor, ok := c.indexCompactors[types.TSDBType]
+	if !ok {
+		level.Error(util_log.Logger).Log("msg", "index processor not found for schema migration", "index-type", types.TSDBType)
+		return
+	}
+	builderFactory, ok := targetCompactor.(IndexBuilderFactory)
+	if !ok {
+		level.Error(util_log.Logger).Log("msg", "index processor does not support building indexes for schema migration", "index-type", types.TSDBType)
+		return
+	}
+
+	for from, sc := range c.storeContainers {
+		if sc.schemaMigrator == nil {
+			continue
+		}
+
+		if err := sc.schemaMigrator.run(ctx, sourceCompactor, targetCompactor, builderFactory); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to migrate schema period", "period", from.String(), "err", err)
+		}
+	}
+}
+
+type schemaMigrationPeriodStatus struct {
+	From           string            `json:"from"`
+	Tables         int               `json:"tables"`
+	MigratedTables int               `json:"migrated_tables"`
+	PendingTables  []string          `json:"pending_tables"`
+	FailedTables   map[string]string `json:"failed_tables,omitempty"`
+	LastRun        time.Time         `json:"last_run"`
+	LastError      string            `json:"last_error,omitempty"`
+	Completed      bool              `json:"completed"`
+}
+
+type schemaMigrationResponse struct {
+	Periods []schemaMigrationPeriodStatus `json:"periods"`
+	// SchemaConfig is the schema config with the completely migrated periods switched to TSDB.
+	SchemaConfig string `json:"schema_config"`
+}
+
+// SchemaMigrationHandler returns the progress of the migration of the boltdb-shipper periods to TSDB, and the schema
+// config to switch to once migrated.
+func (c *Compactor) SchemaMigrationHandler(w http.ResponseWriter, _ *http.Request) {
+	var resp schemaMigrationResponse
+	configs := make([]config.PeriodConfig, len(c.schemaConfig.Configs))
+	copy(configs, c.schemaConfig.Configs)
+
+	for i, period := range configs {
+		sc, ok := c.storeContainers[period.From]
+		if !ok || sc.schemaMigrator == nil {
+			continue
+		}
+
+		status := sc.schemaMigrator.getStatus()
+		resp.Periods = append(resp.Periods, schemaMigrationPeriodStatus{
+			From:           period.From.String(),
+			Tables:         len(status.Tables) + len(status.PendingTables) + len(status.FailedTables),
+			MigratedTables: len(status.Tables),
+			PendingTables:  status.PendingTables,
+			FailedTables:   status.FailedTables,
+			LastRun:        status.LastRun,
+			LastError:      status.LastError,
+			Completed:      status.completed(),
+		})
+		if status.completed() {
+			configs[i] = sc.schemaMigrator.targetPeriod
+		}
+	}
+
+	schemaConfig, err := yaml.Marshal(config.SchemaConfig{Configs: configs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp.SchemaConfig = string(schemaConfig)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		level.Error(util_log.Logger).Log("msg", "error marshalling response", "err", err)
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,236 @@
+package compactor
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/index"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+)
+
+// multiTenantChunkRefsIndexCompactor opens index files holding the external keys of the chunks of any tenant, one per line.
+type multiTenantChunkRefsIndexCompactor struct {
+	testIndexCompactor
+}
+
+func (multiTenantChunkRefsIndexCompactor) OpenCompactedIndexFile(_ context.Context, path, _, _, _ string, _ config.PeriodConfig, _ log.Logger) (CompactedIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries chunkEntries
+	for _, chunkID := range strings.Fields(string(data)) {
+		userID := chunkID[:strings.Index(chunkID, "/")]
+		entries = append(entries, retention.ChunkEntry{ChunkRef: retention.ChunkRef{UserID: []byte(userID), ChunkID: []byte(chunkID)}})
+	}
+	return chunkRefsIndex{chunkEntries: entries}, nil
+}
+
+// chunkRefsIndexBuilderCompactor builds index files holding the external keys of the chunks added to them.
+type chunkRefsIndexBuilderCompactor struct {
+	chunkRefsIndexCompactor
+}
+
+func (chunkRefsIndexBuilderCompactor) NewIndexBuilder(_ context.Context, _, _, workingDir string, _ config.PeriodConfig) (IndexBuilder, error) {
+	idx, err := openCompactedIndex(filepath.Join(workingDir, fmt.Sprintf("migrated-%d", time.Now().UnixNano())))
+	if err != nil {
+		return nil, err
+	}
+	return chunkRefsIndexBuilder{compactedIndex: idx}, nil
+}
+
+type chunkRefsIndexBuilder struct {
+	*compactedIndex
+}
+
+func (b chunkRefsIndexBuilder) AddChunk(entry retention.ChunkEntry) error {
+	_, err := fmt.Fprintln(b.indexFile, string(entry.ChunkID))
+	return err
+}
+
+func (b chunkRefsIndexBuilder) ToIndexFile() (index.Index, error) {
+	return b, nil
+}
+
+func (b chunkRefsIndexBuilder) Name() string {
+	return filepath.Base(b.Path())
+}
+
+func TestSchemaMigrator(t *testing.T) {
+	tempDir := t.TempDir()
+	now := time.Now()
+	tableSecs := int64(config.ObjectStorageIndexRequiredPeriod / time.Second)
+	firstTableNum := now.Add(-5*24*time.Hour).Unix() / tableSecs
+
+	period := config.PeriodConfig{
+		From:       config.DayTime{Time: model.TimeFromUnix(now.Add(-10 * 24 * time.Hour).Truncate(24 * time.Hour).Unix())},
+		IndexType:  "boltdb-shipper",
+		ObjectType: "filesystem",
+		Schema:     "v12",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			}},
+	}
+	nextPeriod := period
+	nextPeriod.From = config.DayTime{Time: model.TimeFromUnix(now.Add(-2 * 24 * time.Hour).Truncate(24 * time.Hour).Unix())}
+	nextPeriod.IndexType = "tsdb"
+	nextPeriod.Schema = "v13"
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{period, nextPeriod}}
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+	sourceClient := storage.NewIndexStorageClient(objectClient, period.IndexTables.PathPrefix)
+	targetClient := storage.NewIndexStorageClient(objectClient, "index_tsdb/")
+
+	tableName := func(i int64) string {
+		return period.IndexTables.TableFor(model.TimeFromUnix((firstTableNum + i) * tableSecs))
+	}
+	chunkID := func(userID string, i int64) string {
+		from := model.TimeFromUnix((firstTableNum + i) * tableSecs).Add(time.Hour)
+		return schemaCfg.ExternalKey(logproto.ChunkRef{UserID: userID, Fingerprint: uint64(i), From: from, Through: from.Add(time.Hour), Checksum: uint32(len(userID))})
+	}
+	putIndex := func(table, userID, name string, chunkIDs ...string) {
+		content := strings.NewReader(strings.Join(chunkIDs, "\n"))
+		if userID == "" {
+			require.NoError(t, sourceClient.PutFile(context.Background(), table, name, content))
+			return
+		}
+		require.NoError(t, sourceClient.PutUserFile(context.Background(), table, userID, name, content))
+	}
+	readMigratedIndex := func(table string) map[string][]string {
+		targetClient.RefreshIndexTableCache(context.Background(), table)
+		files, users, err := targetClient.ListFiles(context.Background(), table, false)
+		require.NoError(t, err)
+		require.Empty(t, files)
+
+		chunkIDs := map[string][]string{}
+		for _, userID := range users {
+			files, err := targetClient.ListUserFiles(context.Background(), table, userID, false)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			// the migrated index files are uploaded compressed.
+			r, err := targetClient.GetUserFile(context.Background(), table, userID, files[0].Name)
+			require.NoError(t, err)
+			gzipReader, err := gzip.NewReader(r)
+			require.NoError(t, err)
+			data, err := io.ReadAll(gzipReader)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			chunkIDs[userID] = strings.Fields(string(data))
+		}
+		return chunkIDs
+	}
+
+	// the first table is compacted in a common file, the second is not compacted yet and the third has a file per user.
+	putIndex(tableName(0), "", "compacted", chunkID("user1", 0), chunkID("user2", 0))
+	putIndex(tableName(1), "", "uncompacted-1", chunkID("user1", 1))
+	putIndex(tableName(1), "", "uncompacted-2", chunkID("user2", 1))
+	putIndex(tableName(2), "user1", "compacted", chunkID("user1", 2))
+
+	cfg := SchemaMigrationConfig{}
+	cfg.RegisterFlagsWithPrefix("", flag.NewFlagSet("", flag.PanicOnError))
+	cfg.Enabled = true
+	metrics := newSchemaMigrationMetrics(prometheus.NewPedanticRegistry())
+	migrator, err := newSchemaMigrator(cfg, schemaCfg, period, nextPeriod.From.Time.Time(), sourceClient, targetClient, filepath.Join(tempDir, "schema-migration"), metrics)
+	require.NoError(t, err)
+	run := func() {
+		require.NoError(t, migrator.run(context.Background(), multiTenantChunkRefsIndexCompactor{}, chunkRefsIndexBuilderCompactor{}, chunkRefsIndexBuilderCompactor{}))
+	}
+
+	// the tables are not migrated before the end of the period.
+	migrator.now = func() time.Time { return nextPeriod.From.Time.Time().Add(-time.Hour) }
+	run()
+	require.Equal(t, []string{tableName(0), tableName(1), tableName(2)}, migrator.getStatus().PendingTables)
+	require.False(t, migrator.getStatus().completed())
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.tablesMigrated))
+
+	// the uncompacted tables are only migrated once compacted.
+	migrator.now = time.Now
+	run()
+	status := migrator.getStatus()
+	require.Equal(t, []string{tableName(1)}, status.PendingTables)
+	require.False(t, status.completed())
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.tablesMigrated))
+	require.Equal(t, map[string][]string{"user1": {chunkID("user1", 0)}, "user2": {chunkID("user2", 0)}}, readMigratedIndex(tableName(0)))
+	require.Equal(t, map[string][]string{"user1": {chunkID("user1", 2)}}, readMigratedIndex(tableName(2)))
+
+	// the tables are only migrated again when their index changed.
+	run()
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.tablesMigrated))
+
+	// the index of the users without chunks left is deleted from the migrated table.
+	require.NoError(t, sourceClient.DeleteFile(context.Background(), tableName(0), "compacted"))
+	putIndex(tableName(0), "", "compacted-retention", chunkID("user1", 0))
+	require.NoError(t, sourceClient.DeleteFile(context.Background(), tableName(1), "uncompacted-2"))
+	// the migrated tables of the deleted tables are deleted.
+	require.NoError(t, sourceClient.DeleteUserFile(context.Background(), tableName(2), "user1", "compacted"))
+	run()
+	status = migrator.getStatus()
+	require.Empty(t, status.PendingTables)
+	require.Empty(t, status.FailedTables)
+	require.True(t, status.completed())
+	require.Equal(t, float64(4), testutil.ToFloat64(metrics.tablesMigrated))
+	require.Equal(t, map[string][]string{"user1": {chunkID("user1", 0)}}, readMigratedIndex(tableName(0)))
+	require.Equal(t, map[string][]string{"user1": {chunkID("user1", 1)}}, readMigratedIndex(tableName(1)))
+	require.Empty(t, readMigratedIndex(tableName(2)))
+	require.NotContains(t, status.Tables, tableName(2))
+
+	// the status is kept across restarts.
+	restarted, err := newSchemaMigrator(cfg, schemaCfg, period, nextPeriod.From.Time.Time(), sourceClient, targetClient, filepath.Join(tempDir, "schema-migration"), metrics)
+	require.NoError(t, err)
+	for tableName, migrated := range status.Tables {
+		require.Equal(t, migrated.SourceFiles, restarted.getStatus().Tables[tableName].SourceFiles)
+	}
+	require.True(t, restarted.getStatus().completed())
+
+	// the schema config switches the completely migrated periods to tsdb.
+	c := &Compactor{schemaConfig: schemaCfg, storeContainers: map[config.DayTime]storeContainer{
+		period.From:     {schemaMigrator: restarted},
+		nextPeriod.From: {},
+	}}
+	rec := httptest.NewRecorder()
+	c.SchemaMigrationHandler(rec, httptest.NewRequest(http.MethodGet, "/compactor/schema_migration", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp schemaMigrationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Periods, 1)
+	require.Equal(t, 2, resp.Periods[0].MigratedTables)
+	require.True(t, resp.Periods[0].Completed)
+
+	var migratedSchemaCfg config.SchemaConfig
+	require.NoError(t, yaml.Unmarshal([]byte(resp.SchemaConfig), &migratedSchemaCfg))
+	require.Len(t, migratedSchemaCfg.Configs, 2)
+	require.Equal(t, "tsdb", migratedSchemaCfg.Configs[0].IndexType)
+	require.Equal(t, "index_tsdb/", migratedSchemaCfg.Configs[0].IndexTables.PathPrefix)
+	require.Equal(t, period.From, migratedSchemaCfg.Configs[0].From)
+	require.Equal(t, nextPeriod.From, migratedSchemaCfg.Configs[1].From)
+	require.Equal(t, "index/", migratedSchemaCfg.Configs[1].IndexTables.PathPrefix)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 
 	"github.com/go-kit/log"
@@ -59,6 +60,29 @@ type IndexCompactor interface {
 	)
 }
 
+// IndexBuilderFactory is implemented by the IndexCompactors of the index types the schema periods can be migrated to.
+type IndexBuilderFactory interface {
+	// NewIndexBuilder returns an empty IndexBuilder for building the index of a user in a table from the chunks
+	// of an index of another type.
+	NewIndexBuilder(
+		ctx context.Context,
+		tableName,
+		userID,
+		workingDir string,
+		periodConfig config.PeriodConfig,
+	) (
+		IndexBuilder,
+		error,
+	)
+}
+
+// IndexBuilder is a CompactedIndex built from the chunks of an index of another type.
+type IndexBuilder interface {
+	CompactedIndex
+	// AddChunk adds a chunk of the index being migrated.
+	AddChunk(entry retention.ChunkEntry) error
+}
+
 type TableCompactor interface {
 	// CompactTable compacts the table.
 	// After compaction is done successfully, it should set the new/updated CompactedIndex for relevant IndexSets.
@@ -67,6 +91,11 @@ type TableCompactor interface {
 
 type MakeEmptyUserIndexSetFunc func(userID string) (IndexSet, error)
 
+// chunkSampler samples the chunks of the compacted indexes.
+type chunkSampler interface {
+	sampleChunks(ctx context.Context, period config.DayTime, chunks retention.ChunkIterator) error
+}
+
 type table struct {
 	name               string
 	workingDirectory   string
@@ -76,6 +105,12 @@ type table struct {
 	tableMarker        retention.TableMarker
 	expirationChecker  tableExpirationChecker
 	periodConfig       config.PeriodConfig
+	chunkSampler       chunkSampler
+	// replicaChunksMerger is only set when the merge of the replica chunks is enabled.
+	replicaChunksMerger *replicaChunksMerger
+	// userID restricts the compaction to the index of a single user when set.
+	// The common index is then left for the compactions of the whole table.
+	userID string
 
 	baseUserIndexSet, baseCommonIndexSet storage.IndexSet
 
@@ -122,6 +157,15 @@ func (t *table) compact(applyRetention bool) error {
 		return err
 	}
 
+	if t.userID != "" {
+		indexFiles = nil
+		if slices.Contains(usersWithPerUserIndex, t.userID) {
+			usersWithPerUserIndex = []string{t.userID}
+		} else {
+			usersWithPerUserIndex = nil
+		}
+	}
+
 	if len(indexFiles) == 0 && len(usersWithPerUserIndex) == 0 {
 		level.Info(t.logger).Log("msg", "no common index files and user index found")
 		return nil
@@ -145,6 +189,9 @@ func (t *table) compact(applyRetention bool) error {
 	if err != nil {
 		return err
 	}
+	if t.userID != "" {
+		t.indexSets[""].sourceObjects = nil
+	}
 
 	// userIndexSets is just for passing it to NewTableCompactor since go considers map[string]*indexSet different type than map[string]IndexSet
 	userIndexSets := make(map[string]IndexSet, len(t.usersWithPerUserIndex))
@@ -174,6 +221,27 @@ func (t *table) compact(applyRetention bool) error {
 		return err
 	}
 
+	if t.chunkSampler != nil {
+		for _, is := range t.indexSets {
+			if is.compactedIndex == nil {
+				continue
+			}
+			if err := t.chunkSampler.sampleChunks(t.ctx, t.periodConfig.From, is.compactedIndex); err != nil {
+				level.Error(t.logger).Log("msg", "failed to sample chunks", "err", err)
+			}
+		}
+	}
+
+	// The chunks dropped or rewritten by the retention are still visible in the compacted index until it is
+	// uploaded, so the replica chunks are only merged by the compactions not applying retention.
+	if t.replicaChunksMerger != nil && !applyRetention {
+		for _, is := range t.indexSets {
+			if err := is.mergeReplicaChunks(t.replicaChunksMerger); err != nil {
+				return err
+			}
+		}
+	}
+
 	if applyRetention {
 		err := t.applyRetention()
 		if err != nil {
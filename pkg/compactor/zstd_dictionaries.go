@@ -0,0 +1,225 @@
+package compactor
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/zstddict"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+type ZstdDictionariesConfig struct {
+	TrainingEnabled  bool             `yaml:"training_enabled"`
+	TrainingInterval time.Duration    `yaml:"training_interval"`
+	SamplesPerTenant int              `yaml:"samples_per_tenant"`
+	DictionarySize   flagext.ByteSize `yaml:"dictionary_size"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *ZstdDictionariesConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.TrainingEnabled, prefix+"training-enabled", false, "Experimental: Train a zstd dictionary per tenant from the chunks found while compacting the index, for the zstd-dict chunk encoding. Requires -store.zstd-dictionaries.store.")
+	f.DurationVar(&cfg.TrainingInterval, prefix+"training-interval", 24*time.Hour, "Interval at which to train new versions of the zstd dictionaries of the tenants.")
+	f.IntVar(&cfg.SamplesPerTenant, prefix+"samples-per-tenant", 100, "Number of chunks sampled per tenant to train its dictionary with.")
+	cfg.DictionarySize = 64 * 1024
+	f.Var(&cfg.DictionarySize, prefix+"dictionary-size", "Maximum size of the zstd dictionaries.")
+}
+
+// Validate verifies the config does not contain inappropriate values
+func (cfg *ZstdDictionariesConfig) Validate() error {
+	if !cfg.TrainingEnabled {
+		return nil
+	}
+	if cfg.TrainingInterval <= 0 {
+		return errors.New("the zstd dictionaries training interval must be positive")
+	}
+	if cfg.SamplesPerTenant < 1 {
+		return errors.New("the number of chunks sampled per tenant must be >= 1")
+	}
+	if cfg.DictionarySize < 8 {
+		return errors.New("the zstd dictionaries size must be >= 8")
+	}
+	return nil
+}
+
+// ZstdDictionaryStore stores the trained zstd dictionaries.
+type ZstdDictionaryStore interface {
+	PutDictionary(ctx context.Context, tenant string, version time.Time, dict []byte) error
+}
+
+type sampledChunk struct {
+	period          config.DayTime
+	userID, chunkID string
+}
+
+type tenantChunkSamples struct {
+	seen   int
+	chunks []sampledChunk
+}
+
+// zstdDictionaryTrainer samples the chunks of the compacted indexes of each tenant, and
+// periodically trains new dictionaries from the sampled chunks.
+type zstdDictionaryTrainer struct {
+	cfg          ZstdDictionariesConfig
+	store        ZstdDictionaryStore
+	chunkClients map[config.DayTime]client.Client
+	logger       log.Logger
+
+	mtx          sync.Mutex
+	tenants      map[string]*tenantChunkSamples
+	rand         *rand.Rand
+	lastTraining time.Time
+
+	trainings *prometheus.CounterVec
+}
+
+func newZstdDictionaryTrainer(cfg ZstdDictionariesConfig, store ZstdDictionaryStore, chunkClients map[config.DayTime]client.Client, r prometheus.Registerer, logger log.Logger) *zstdDictionaryTrainer {
+	return &zstdDictionaryTrainer{
+		cfg:          cfg,
+		store:        store,
+		chunkClients: chunkClients,
+		logger:       logger,
+		tenants:      map[string]*tenantChunkSamples{},
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		lastTraining: time.Now(),
+		trainings: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "zstd_dictionary_trainings_total",
+			Help:      "Total number of zstd dictionaries trained by status",
+		}, []string{"status"}),
+	}
+}
+
+// sampleChunks adds the chunks of a compacted index to the reservoir of samples of their tenant.
+func (t *zstdDictionaryTrainer) sampleChunks(ctx context.Context, period config.DayTime, chunks retention.ChunkIterator) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return chunks.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+		samples, ok := t.tenants[string(ce.UserID)]
+		if !ok {
+			samples = &tenantChunkSamples{}
+			t.tenants[string(ce.UserID)] = samples
+		}
+
+		samples.seen++
+		i := len(samples.chunks)
+		if i >= t.cfg.SamplesPerTenant {
+			if i = t.rand.Intn(samples.seen); i >= t.cfg.SamplesPerTenant {
+				return false, nil
+			}
+		}
+		// The bytes of the entry are only valid during the callback.
+		s := sampledChunk{period: period, userID: string(ce.UserID), chunkID: string(ce.ChunkID)}
+		if i == len(samples.chunks) {
+			samples.chunks = append(samples.chunks, s)
+		} else {
+			samples.chunks[i] = s
+		}
+		return false, nil
+	})
+}
+
+// trainIfDue trains new dictionaries for the tenants with sampled chunks once the training interval elapsed.
+func (t *zstdDictionaryTrainer) trainIfDue(ctx context.Context) {
+	t.mtx.Lock()
+	if time.Since(t.lastTraining) < t.cfg.TrainingInterval {
+		t.mtx.Unlock()
+		return
+	}
+	tenants := t.tenants
+	t.tenants = map[string]*tenantChunkSamples{}
+	t.lastTraining = time.Now()
+	t.mtx.Unlock()
+
+	for tenant, samples := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+		status := statusSuccess
+		if err := t.train(ctx, tenant, samples.chunks); err != nil {
+			status = statusFailure
+			level.Error(t.logger).Log("msg", "failed to train zstd dictionary", "tenant", tenant, "err", err)
+		}
+		t.trainings.WithLabelValues(status).Inc()
+	}
+}
+
+// train trains a new dictionary for the tenant from the sampled chunks. The chunks
+// which can't be read are skipped.
+func (t *zstdDictionaryTrainer) train(ctx context.Context, tenant string, chunks []sampledChunk) error {
+	var samples [][]byte
+	for _, s := range chunks {
+		chunkSamples, err := t.chunkSamples(ctx, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			level.Warn(t.logger).Log("msg", "skipping zstd dictionary training sample", "tenant", tenant, "chunk", s.chunkID, "err", err)
+			continue
+		}
+		samples = append(samples, chunkSamples...)
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	version := time.Now()
+	dict, err := chunkenc.TrainZstdDictionary(samples, zstddict.DictionaryID(tenant, version), t.cfg.DictionarySize.Val())
+	if err != nil {
+		return err
+	}
+	if err := t.store.PutDictionary(ctx, tenant, version, dict); err != nil {
+		return err
+	}
+	level.Info(t.logger).Log("msg", "trained zstd dictionary", "tenant", tenant, "chunks", len(chunks), "size", len(dict))
+	return nil
+}
+
+// chunkSamples returns the decompressed blocks of a sampled chunk.
+func (t *zstdDictionaryTrainer) chunkSamples(ctx context.Context, s sampledChunk) ([][]byte, error) {
+	chunkClient, ok := t.chunkClients[s.period]
+	if !ok {
+		return nil, fmt.Errorf("chunk client not found for period starting at %s", s.period.String())
+	}
+	chk, err := chunk.ParseExternalKey(s.userID, s.chunkID)
+	if err != nil {
+		return nil, err
+	}
+	chks, err := chunkClient.GetChunks(ctx, []chunk.Chunk{chk})
+	if err != nil {
+		if chunkClient.IsChunkNotFoundErr(err) {
+			// The chunk was deleted since it was sampled.
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var samples [][]byte
+	for _, c := range chks {
+		facade, ok := c.Data.(*chunkenc.Facade)
+		if !ok {
+			continue
+		}
+		blockSamples, err := chunkenc.ZstdDictionarySamples(ctx, facade.LokiChunk())
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, blockSamples...)
+	}
+	return samples, nil
+}
@@ -0,0 +1,131 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/chunkenc/testdata"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+)
+
+type chunkEntries []retention.ChunkEntry
+
+func (c chunkEntries) ForEachChunk(_ context.Context, callback retention.ChunkEntryCallback) error {
+	for _, e := range c {
+		if _, err := callback(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeZstdDictionaryStore struct {
+	mtx   sync.Mutex
+	dicts map[string][]byte
+}
+
+func (s *fakeZstdDictionaryStore) PutDictionary(_ context.Context, tenant string, _ time.Time, dict []byte) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.dicts[tenant] = dict
+	return nil
+}
+
+func TestZstdDictionaryTrainer(t *testing.T) {
+	period := config.PeriodConfig{
+		From:       config.DayTime{Time: 0},
+		IndexType:  types.TSDBType,
+		ObjectType: types.StorageTypeFileSystem,
+		Schema:     "v13",
+		RowShards:  16,
+	}
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{period}}
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaCfg)
+
+	var entries chunkEntries
+	for i := 0; i < 10; i++ {
+		userID := fmt.Sprintf("user%d", i%2)
+		lbs := labels.FromStrings("i", fmt.Sprint(i))
+		memChk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 4*1024, 0)
+		for j := 0; j < 100; j++ {
+			require.NoError(t, memChk.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(j)), Line: testdata.LogString(int64(i*100 + j))}))
+		}
+		require.NoError(t, memChk.Close())
+		from, through := memChk.Bounds()
+		c := chunk.NewChunk(userID, model.Fingerprint(lbs.Hash()), lbs, chunkenc.NewFacade(memChk, 0, 0), model.TimeFromUnixNano(from.UnixNano()), model.TimeFromUnixNano(through.UnixNano()))
+		require.NoError(t, c.Encode())
+		require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{c}))
+
+		entries = append(entries, retention.ChunkEntry{
+			ChunkRef: retention.ChunkRef{
+				UserID:  []byte(userID),
+				ChunkID: []byte(schemaCfg.ExternalKey(c.ChunkRef)),
+				From:    c.From,
+				Through: c.Through,
+			},
+			Labels: lbs,
+		})
+	}
+
+	cfg := ZstdDictionariesConfig{
+		TrainingEnabled:  true,
+		TrainingInterval: time.Hour,
+		SamplesPerTenant: 3,
+		DictionarySize:   4 * 1024,
+	}
+	store := &fakeZstdDictionaryStore{dicts: map[string][]byte{}}
+	trainer := newZstdDictionaryTrainer(cfg, store, map[config.DayTime]client.Client{period.From: chunkClient}, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+
+	require.NoError(t, trainer.sampleChunks(context.Background(), period.From, entries))
+	require.Len(t, trainer.tenants, 2)
+	for _, samples := range trainer.tenants {
+		require.Equal(t, 5, samples.seen)
+		require.Len(t, samples.chunks, 3)
+	}
+
+	// Nothing is trained before the training interval elapsed.
+	trainer.trainIfDue(context.Background())
+	require.Empty(t, store.dicts)
+
+	trainer.lastTraining = time.Now().Add(-cfg.TrainingInterval)
+	trainer.trainIfDue(context.Background())
+	require.Len(t, store.dicts, 2)
+	require.Empty(t, trainer.tenants)
+
+	// The dictionaries can be used to compress chunks.
+	pool := &chunkenc.ZstdDictPool{}
+	for _, dict := range store.dicts {
+		_, err := pool.Register(dict)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(dict), 8*1024)
+	}
+
+	// The samples which can't be read are skipped.
+	store.dicts = map[string][]byte{}
+	require.NoError(t, trainer.train(context.Background(), "user0", []sampledChunk{
+		{period: period.From, userID: "user0", chunkID: "invalid"},
+		{period: config.DayTime{Time: 1}, userID: "user0", chunkID: string(entries[0].ChunkID)},
+		{period: period.From, userID: "user0", chunkID: string(entries[0].ChunkID)},
+		{period: period.From, userID: "user0", chunkID: string(entries[2].ChunkID)},
+		{period: period.From, userID: "user0", chunkID: string(entries[4].ChunkID)},
+	}))
+	require.Contains(t, store.dicts, "user0")
+}
@@ -41,9 +41,11 @@ import (
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
 	"github.com/grafana/loki/v3/pkg/ingester"
 	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/kafka"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/metadata"
 	"github.com/grafana/loki/v3/pkg/runtime"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/constants"
@@ -57,6 +59,8 @@ const (
 
 	ringAutoForgetUnhealthyPeriods = 2
 
+	labelDemotedWarning = "stream label '%s' exceeded %d distinct values within %s and was moved to structured metadata, consider removing it from the stream labels"
+
 	labelServiceName = "service_name"
 	serviceUnknown   = "unknown_service"
 	labelLevel       = "level"
@@ -79,7 +83,15 @@ type Config struct {
 	DistributorRing RingConfig `yaml:"ring,omitempty"`
 
 	// For testing.
-	factory ring_client.PoolFactory `yaml:"-"`
+	factory     ring_client.PoolFactory `yaml:"-"`
+	kafkaClient kafka.Client            `yaml:"-"`
+
+	// KafkaConfig is set from the top level kafka_config block.
+	KafkaConfig kafka.Config `yaml:"-"`
+
+	// HATrackerConfig configures the deduplication of the logs sent by the
+	// replicas of HA clusters.
+	HATrackerConfig HATrackerConfig `yaml:"ha_tracker"`
 
 	// RateStore customizes the rate storing used by stream sharding.
 	RateStore RateStoreConfig `yaml:"rate_store"`
@@ -94,10 +106,19 @@ type Config struct {
 func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
 	cfg.OTLPConfig.RegisterFlags(fs)
 	cfg.DistributorRing.RegisterFlags(fs)
+	cfg.HATrackerConfig.RegisterFlags(fs)
 	cfg.RateStore.RegisterFlagsWithPrefix("distributor.rate-store", fs)
 	cfg.WriteFailuresLogging.RegisterFlagsWithPrefix("distributor.write-failures-logging", fs)
 }
 
+// Validate config and returns error on failure
+func (cfg *Config) Validate() error {
+	if cfg.HATrackerConfig.EnableHATracker {
+		return cfg.HATrackerConfig.Validate()
+	}
+	return nil
+}
+
 // RateStore manages the ingestion rate of streams, populated by data fetched from ingesters.
 type RateStore interface {
 	RateFor(tenantID string, streamHash uint64) (int64, float64)
@@ -134,6 +155,19 @@ type Distributor struct {
 	ingestionRateLimiter *limiter.RateLimiter
 	labelCache           *lru.Cache
 
+	// Per-user and per stream limit policy rate limiter.
+	policyRateLimiter *policyRateLimiter
+
+	labelCardinality *labelCardinalityTracker
+
+	// haTracker elects the replica accepted for each HA cluster, nil if the
+	// HA tracker is disabled.
+	haTracker *haTracker
+
+	// kafkaClient is used instead of the ingesters pool when the Kafka write
+	// path is enabled.
+	kafkaClient kafka.Client
+
 	// Push failures rate limiter.
 	writeFailuresManager *writefailures.Manager
 
@@ -144,6 +178,10 @@ type Distributor struct {
 	ingesterAppendTimeouts *prometheus.CounterVec
 	replicationFactor      prometheus.Gauge
 	streamShardCount       prometheus.Counter
+	demotedLabelEntries    *prometheus.CounterVec
+	dedupedEntries         *prometheus.CounterVec
+	kafkaAppends           *prometheus.CounterVec
+	kafkaWriteBytes        prometheus.Counter
 
 	usageTracker push.UsageTracker
 }
@@ -203,6 +241,7 @@ func New(
 		pool:                  clientpool.NewPool("ingester", clientCfg.PoolConfig, ingestersRing, factory, logger, metricsNamespace),
 		labelCache:            labelCache,
 		shardTracker:          NewShardTracker(),
+		labelCardinality:      newLabelCardinalityTracker(),
 		healthyInstancesCount: atomic.NewUint32(0),
 		rateLimitStrat:        rateLimitStrat,
 		tee:                   tee,
@@ -227,6 +266,26 @@ func New(
 			Name:      "stream_sharding_count",
 			Help:      "Total number of times the distributor has sharded streams",
 		}),
+		demotedLabelEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_demoted_label_entries_total",
+			Help:      "The total number of entries whose high cardinality stream labels were moved to structured metadata.",
+		}, []string{"tenant", "label"}),
+		dedupedEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_deduped_entries_total",
+			Help:      "The total number of entries dropped because they were sent by a replica of an HA cluster which is not the elected one.",
+		}, []string{"tenant", "cluster"}),
+		kafkaAppends: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_kafka_appends_total",
+			Help:      "The total number of records written to Kafka partitions.",
+		}, []string{"partition", "status"}),
+		kafkaWriteBytes: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_kafka_sent_bytes_total",
+			Help:      "The total number of bytes written to Kafka.",
+		}),
 		writeFailuresManager: writefailures.NewManager(logger, registerer, cfg.WriteFailuresLogging, configs, "distributor"),
 	}
 
@@ -241,8 +300,28 @@ func New(
 		servs = append(servs, distributorsLifecycler, distributorsRing)
 
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(overrides, d)
+		d.policyRateLimiter = newPolicyRateLimiter(overrides, d)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(overrides)
+		d.policyRateLimiter = newPolicyRateLimiter(overrides, nil)
+	}
+
+	if cfg.HATrackerConfig.EnableHATracker {
+		d.haTracker, err = newHATracker(cfg.HATrackerConfig, overrides, registerer, logger)
+		if err != nil {
+			return nil, err
+		}
+		servs = append(servs, d.haTracker)
+	}
+
+	if cfg.KafkaConfig.Enabled {
+		d.kafkaClient = cfg.kafkaClient
+		if d.kafkaClient == nil {
+			d.kafkaClient, err = kafka.NewClient(cfg.KafkaConfig)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
@@ -294,12 +373,33 @@ func (d *Distributor) running(ctx context.Context) error {
 }
 
 func (d *Distributor) stopping(_ error) error {
-	return services.StopManagerAndAwaitStopped(context.Background(), d.subservices)
+	err := services.StopManagerAndAwaitStopped(context.Background(), d.subservices)
+	if d.kafkaClient != nil {
+		if closeErr := d.kafkaClient.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
 
 type KeyedStream struct {
 	HashKey uint32
 	Stream  logproto.Stream
+
+	// index of the stream in the push request.
+	index int
+	// offsets of the entries in the stream of the push request, nil when they
+	// are at the same offsets.
+	offsets []int32
+}
+
+// requestOffset returns the offset in the stream of the push request of the
+// entry at the given offset.
+func (s *KeyedStream) requestOffset(offset int32) int32 {
+	if s.offsets == nil {
+		return offset
+	}
+	return s.offsets[offset]
 }
 
 // TODO taken from Cortex, see if we can refactor out an usable interface.
@@ -317,6 +417,7 @@ type pushTracker struct {
 	streamsFailed  atomic.Int32
 	done           chan struct{}
 	err            chan error
+	results        *pushResults
 }
 
 // Push a set of streams.
@@ -339,8 +440,14 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	validatedLineSize := 0
 	validatedLineCount := 0
 
-	var validationErrors util.GroupedErrors
-	validationContext := d.validator.getValidationContextForTime(time.Now(), tenantID)
+	var validationErrors, policyErrors util.GroupedErrors
+	var reservations policyReservations
+	now := time.Now()
+	validationContext := d.validator.getValidationContextForTime(now, tenantID)
+	results := newPushResults(req)
+	haDedupe := d.newHADedupe(tenantID)
+	var haErr error
+	demotedLabels := map[string]struct{}{}
 
 	func() {
 		sp := opentracing.SpanFromContext(ctx)
@@ -350,7 +457,7 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				sp.LogKV("event", "finished to validate request")
 			}()
 		}
-		for _, stream := range req.Streams {
+		for i, stream := range req.Streams {
 			// Return early if stream does not contain any entries
 			if len(stream.Entries) == 0 {
 				continue
@@ -364,6 +471,7 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 			if err != nil {
 				d.writeFailuresManager.Log(tenantID, err)
 				validationErrors.Add(err)
+				results.reject(i, nil, validation.InvalidLabels, err.Error(), false)
 				validation.DiscardedSamples.WithLabelValues(validation.InvalidLabels, tenantID).Add(float64(len(stream.Entries)))
 				bytes := 0
 				for _, e := range stream.Entries {
@@ -373,16 +481,53 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				continue
 			}
 
+			if haDedupe != nil {
+				cluster, err := haDedupe.check(ctx, lbs)
+				switch {
+				case errors.As(err, &replicasNotMatchError{}):
+					// The elected replica sends the same logs: drop these ones
+					// without failing the push.
+					d.dedupedEntries.WithLabelValues(tenantID, cluster).Add(float64(len(stream.Entries)))
+					results.reject(i, nil, validation.HADuplicate, err.Error(), false)
+					continue
+				case errors.As(err, &tooManyClustersError{}):
+					d.writeFailuresManager.Log(tenantID, err)
+					validationErrors.Add(err)
+					results.reject(i, nil, validation.TooManyHAClusters, err.Error(), false)
+					validation.DiscardedSamples.WithLabelValues(validation.TooManyHAClusters, tenantID).Add(float64(len(stream.Entries)))
+					bytes := 0
+					for _, e := range stream.Entries {
+						bytes += len(e.Line)
+					}
+					validation.DiscardedBytes.WithLabelValues(validation.TooManyHAClusters, tenantID).Add(float64(bytes))
+					continue
+				case err != nil:
+					haErr = err
+					return
+				}
+				lbs = haDedupe.removeReplicaLabel(lbs, &stream)
+			}
+
 			n := 0
 			pushSize := 0
+			// offsets of the valid entries in the stream of the request, only
+			// tracked once an entry is rejected.
+			var offsets []int32
 			prevTs := stream.Entries[0].Timestamp
 			addLogLevel := validationContext.allowStructuredMetadata && validationContext.discoverLogLevels && !lbs.Has(labelLevel)
-			for _, entry := range stream.Entries {
-				if err := d.validator.ValidateEntry(ctx, validationContext, lbs, entry); err != nil {
+			for j, entry := range stream.Entries {
+				if reason, err := d.validator.ValidateEntry(ctx, validationContext, lbs, entry); err != nil {
 					d.writeFailuresManager.Log(tenantID, err)
 					validationErrors.Add(err)
+					results.reject(i, []int32{int32(j)}, reason, err.Error(), false)
+					if offsets == nil {
+						offsets = allOffsets(n)
+					}
 					continue
 				}
+				if offsets != nil {
+					offsets = append(offsets, int32(j))
+				}
 
 				structuredMetadata := logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)
 				if addLogLevel && !structuredMetadata.Has(labelLevel) {
@@ -410,36 +555,79 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				}
 
 				n++
-				validatedLineSize += len(entry.Line)
-				validatedLineCount++
 				pushSize += len(entry.Line)
 			}
 			stream.Entries = stream.Entries[:n]
 
+			// Demote the labels once the entries are validated, so the structured metadata
+			// limits only apply to the structured metadata sent by the client.
+			if validationContext.labelCardinalityDemotionThreshold > 0 && validationContext.allowStructuredMetadata && n > 0 {
+				var demoted []string
+				lbs, demoted = d.demoteHighCardinalityLabels(validationContext, lbs, &stream)
+				for _, name := range demoted {
+					demotedLabels[name] = struct{}{}
+				}
+			}
+
+			streamReservations, err := d.checkPolicyRateLimits(ctx, now, tenantID, lbs, stream, pushSize)
+			if err != nil {
+				d.writeFailuresManager.Log(tenantID, err)
+				policyErrors.Add(err)
+				if offsets == nil {
+					offsets = allOffsets(n)
+				}
+				results.reject(i, offsets, validation.PolicyRateLimited, err.Error(), true)
+				continue
+			}
+			reservations = append(reservations, streamReservations...)
+			validatedLineSize += pushSize
+			validatedLineCount += n
+
 			shardStreamsCfg := d.validator.Limits.ShardStreams(tenantID)
 			if shardStreamsCfg.Enabled {
-				streams = append(streams, d.shardStream(stream, pushSize, tenantID)...)
+				for _, shard := range d.shardStream(stream, pushSize, tenantID) {
+					shard.index = i
+					shard.offsets = requestOffsets(offsets, shard.offsets)
+					streams = append(streams, shard)
+				}
 			} else {
 				streams = append(streams, KeyedStream{
 					HashKey: lokiring.TokenFor(tenantID, stream.Labels),
 					Stream:  stream,
+					index:   i,
+					offsets: offsets,
 				})
 			}
 		}
 	}()
 
+	if haErr != nil {
+		// The streams are not ingested, so they don't count against the policy rate limits.
+		reservations.cancel(now)
+		return nil, haErr
+	}
+
+	d.warnDemotedLabels(ctx, validationContext, demotedLabels)
+
 	var validationErr error
-	if validationErrors.Err() != nil {
+	if policyErrors.Err() != nil {
+		// Streams rejected by a policy rate limit can be retried later, so report
+		// them with a 429 even if some other entries were invalid.
+		validationErrors.Add(policyErrors.MultiError)
+		validationErr = httpgrpc.Errorf(http.StatusTooManyRequests, validationErrors.Error())
+	} else if validationErrors.Err() != nil {
 		validationErr = httpgrpc.Errorf(http.StatusBadRequest, validationErrors.Error())
 	}
 
 	// Return early if none of the streams contained entries
 	if len(streams) == 0 {
-		return &logproto.PushResponse{}, validationErr
+		return results.response(), validationErr
 	}
 
-	now := time.Now()
 	if !d.ingestionRateLimiter.AllowN(now, tenantID, validatedLineSize) {
+		// The streams are not ingested, so they don't count against the policy rate limits.
+		reservations.cancel(now)
+
 		// Return a 429 to indicate to the client they are being rate limited
 		validation.DiscardedSamples.WithLabelValues(validation.RateLimited, tenantID).Add(float64(validatedLineCount))
 		validation.DiscardedBytes.WithLabelValues(validation.RateLimited, tenantID).Add(float64(validatedLineSize))
@@ -473,6 +661,13 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 		d.tee.Duplicate(tenantID, streams)
 	}
 
+	if d.kafkaClient != nil {
+		if err := d.sendStreamsToKafka(ctx, tenantID, streams); err != nil {
+			return nil, err
+		}
+		return results.response(), validationErr
+	}
+
 	const maxExpectedReplicationSet = 5 // typical replication factor 3 plus one for inactive plus one for luck
 	var descs [maxExpectedReplicationSet]ring.InstanceDesc
 
@@ -513,6 +708,8 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	tracker := pushTracker{
 		done: make(chan struct{}, 1), // buffer avoids blocking if caller terminates - sendSamples() only sends once on each
 		err:  make(chan error, 1),
+
+		results: results,
 	}
 	tracker.streamsPending.Store(int32(len(streams)))
 	for ingester, streams := range streamsByIngester {
@@ -531,12 +728,54 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	case err := <-tracker.err:
 		return nil, err
 	case <-tracker.done:
-		return &logproto.PushResponse{}, validationErr
+		if err := results.ingesterError(); err != nil {
+			return results.response(), err
+		}
+		return results.response(), validationErr
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
+// checkPolicyRateLimits returns an error if the stream is rejected by the rate
+// limit of one of the stream limit policies it matches, and reports the
+// discarded entries accordingly. Otherwise it returns the reservations of the
+// consumed tokens.
+func (d *Distributor) checkPolicyRateLimits(ctx context.Context, now time.Time, tenantID string, lbs labels.Labels, stream logproto.Stream, pushSize int) (policyReservations, error) {
+	allowed, reservations, policy := d.policyRateLimiter.AllowN(now, tenantID, lbs, pushSize)
+	if allowed {
+		return reservations, nil
+	}
+
+	validation.DiscardedSamples.WithLabelValues(validation.PolicyRateLimited, tenantID).Add(float64(len(stream.Entries)))
+	validation.DiscardedBytes.WithLabelValues(validation.PolicyRateLimited, tenantID).Add(float64(pushSize))
+	validation.PolicyDiscardedSamples.WithLabelValues(validation.PolicyRateLimited, tenantID, policy.Name).Add(float64(len(stream.Entries)))
+	validation.PolicyDiscardedBytes.WithLabelValues(validation.PolicyRateLimited, tenantID, policy.Name).Add(float64(pushSize))
+	if d.usageTracker != nil {
+		d.usageTracker.DiscardedBytesAdd(ctx, tenantID, validation.PolicyRateLimited, lbs, float64(pushSize))
+	}
+
+	return nil, fmt.Errorf(validation.PolicyRateLimitedErrorMsg, policy.Name, tenantID, int(d.policyRateLimiter.Limit(policy)), len(stream.Entries), pushSize, policy.Selector)
+}
+
+// requestOffsets maps the offsets of the entries of a shard in the validated
+// stream to their offsets in the stream of the request. Nil offsets of the
+// validated stream mean that no entry was rejected, and nil offsets of the
+// shard that the stream was not sharded.
+func requestOffsets(validated, shard []int32) []int32 {
+	if validated == nil || shard == nil {
+		if shard == nil {
+			return validated
+		}
+		return shard
+	}
+	offsets := make([]int32, len(shard))
+	for i, o := range shard {
+		offsets[i] = validated[o]
+	}
+	return offsets
+}
+
 // shardStream shards (divides) the given stream into N smaller streams, where
 // N is the sharding size for the given stream. shardSteam returns the smaller
 // streams and their associated keys for hashing to ingesters.
@@ -566,6 +805,7 @@ func (d *Distributor) divideEntriesBetweenShards(tenantID string, totalShards in
 		streamIndex := i % len(derivedStreams)
 		entries := append(derivedStreams[streamIndex].Stream.Entries, stream.Entries[i])
 		derivedStreams[streamIndex].Stream.Entries = entries
+		derivedStreams[streamIndex].offsets = append(derivedStreams[streamIndex].offsets, int32(i))
 	}
 
 	return derivedStreams
@@ -674,9 +914,78 @@ func (d *Distributor) truncateLines(vContext validationContext, stream *logproto
 	validation.MutatedBytes.WithLabelValues(validation.LineTooLong, vContext.userID).Add(float64(truncatedBytes))
 }
 
+// demoteHighCardinalityLabels moves the stream labels having more distinct values
+// than allowed by the tenant's label cardinality threshold to the structured
+// metadata of the stream entries, and returns the remaining stream labels and the
+// names of the demoted ones.
+func (d *Distributor) demoteHighCardinalityLabels(vContext validationContext, lbs labels.Labels, stream *logproto.Stream) (labels.Labels, []string) {
+	demoted := d.labelCardinality.Observe(vContext.userID, lbs, vContext.labelCardinalityDemotionThreshold, vContext.labelCardinalityDemotionWindow, labelServiceName)
+
+	// A stream needs at least one label, so keep them all rather than
+	// demoting every one of them.
+	if len(demoted) == 0 || len(demoted) == len(lbs) {
+		return lbs, nil
+	}
+
+	builder := labels.NewBuilder(lbs)
+	for _, name := range demoted {
+		value := lbs.Get(name)
+		builder.Del(name)
+
+		for i := range stream.Entries {
+			if logproto.FromLabelAdaptersToLabels(stream.Entries[i].StructuredMetadata).Has(name) {
+				continue
+			}
+			stream.Entries[i].StructuredMetadata = append(stream.Entries[i].StructuredMetadata, logproto.LabelAdapter{
+				Name:  name,
+				Value: value,
+			})
+		}
+
+		d.demotedLabelEntries.WithLabelValues(vContext.userID, name).Add(float64(len(stream.Entries)))
+	}
+
+	lbs = builder.Labels()
+	stream.Labels = lbs.String()
+	stream.Hash = lbs.Hash()
+	return lbs, demoted
+}
+
+// warnDemotedLabels adds a single warning to the response for each label demoted
+// from the streams of the request.
+func (d *Distributor) warnDemotedLabels(ctx context.Context, vContext validationContext, demoted map[string]struct{}) {
+	if len(demoted) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(demoted))
+	for name := range demoted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	md := metadata.FromContext(ctx)
+	for _, name := range names {
+		md.AddWarning(fmt.Sprintf(labelDemotedWarning, name, vContext.labelCardinalityDemotionThreshold, vContext.labelCardinalityDemotionWindow))
+	}
+}
+
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (d *Distributor) sendStreams(ctx context.Context, ingester ring.InstanceDesc, streamTrackers []*streamTracker, pushTracker *pushTracker) {
-	err := d.sendStreamsErr(ctx, ingester, streamTrackers)
+	resp, err := d.sendStreamsErr(ctx, ingester, streamTrackers)
+
+	// Streams rejected by the ingester count as failures for those streams
+	// only. Once rejected by too many ingesters, the stream is reported as
+	// rejected in the partial success instead of failing the whole push.
+	var rejected map[int]*logproto.StreamPushResult
+	if err == nil && resp != nil && resp.PartialSuccess != nil {
+		rejected = make(map[int]*logproto.StreamPushResult, len(resp.PartialSuccess.Streams))
+		for i, res := range resp.PartialSuccess.Streams {
+			if int(res.Index) < len(streamTrackers) {
+				rejected[int(res.Index)] = &resp.PartialSuccess.Streams[i]
+			}
+		}
+	}
 
 	// If we succeed, decrement each stream's pending count by one.
 	// If we reach the required number of successful puts on this stream, then
@@ -688,6 +997,17 @@ func (d *Distributor) sendStreams(ctx context.Context, ingester ring.InstanceDes
 	// The use of atomic increments here guarantees only a single sendStreams
 	// goroutine will write to either channel.
 	for i := range streamTrackers {
+		if res, ok := rejected[i]; ok {
+			if streamTrackers[i].failed.Inc() != int32(streamTrackers[i].maxFailures+1) {
+				continue
+			}
+			pushTracker.results.rejectByIngester(streamTrackers[i], res)
+			if pushTracker.streamsPending.Dec() == 0 {
+				pushTracker.done <- struct{}{}
+			}
+			continue
+		}
+
 		if err != nil {
 			if streamTrackers[i].failed.Inc() <= int32(streamTrackers[i].maxFailures) {
 				continue
@@ -707,10 +1027,10 @@ func (d *Distributor) sendStreams(ctx context.Context, ingester ring.InstanceDes
 }
 
 // TODO taken from Cortex, see if we can refactor out an usable interface.
-func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.InstanceDesc, streams []*streamTracker) error {
+func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.InstanceDesc, streams []*streamTracker) (*logproto.PushResponse, error) {
 	c, err := d.pool.GetClientFor(ingester.Addr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req := &logproto.PushRequest{
@@ -720,7 +1040,7 @@ func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.Instance
 		req.Streams[i] = s.Stream
 	}
 
-	_, err = c.(logproto.PusherClient).Push(ctx, req)
+	resp, err := c.(logproto.PusherClient).Push(withPartialSuccess(ctx), req)
 	d.ingesterAppends.WithLabelValues(ingester.Addr).Inc()
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
@@ -730,7 +1050,61 @@ func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.Instance
 			}
 		}
 	}
-	return err
+	return resp, err
+}
+
+// sendStreamsToKafka writes the streams to the Kafka partitions matching their
+// hash key, one record per partition, and waits for the writes to be
+// acknowledged. The ingesters consume the records from there, so ingester
+// slowdowns and restarts are not propagated back to the clients.
+func (d *Distributor) sendStreamsToKafka(ctx context.Context, tenantID string, streams []KeyedStream) error {
+	partitions, err := d.kafkaClient.Partitions()
+	if err != nil {
+		return err
+	}
+	if partitions <= 0 {
+		return fmt.Errorf("kafka topic %s has no partitions", d.cfg.KafkaConfig.Topic)
+	}
+
+	streamsByPartition := map[int32][]logproto.Stream{}
+	for _, s := range streams {
+		partition := int32(s.HashKey % uint32(partitions))
+		streamsByPartition[partition] = append(streamsByPartition[partition], s.Stream)
+	}
+
+	records := make([]kafka.Record, 0, len(streamsByPartition))
+	for partition, streams := range streamsByPartition {
+		req := &logproto.PushRequest{Streams: streams}
+		value, err := req.Marshal()
+		if err != nil {
+			return err
+		}
+		records = append(records, kafka.Record{
+			Partition: partition,
+			Key:       []byte(tenantID),
+			Value:     value,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.KafkaConfig.WriteTimeout)
+	defer cancel()
+
+	err = d.kafkaClient.Produce(ctx, records)
+	status := "success"
+	if err != nil {
+		status = "fail"
+	}
+	for _, r := range records {
+		d.kafkaAppends.WithLabelValues(strconv.Itoa(int(r.Partition)), status).Inc()
+		if err == nil {
+			d.kafkaWriteBytes.Add(float64(len(r.Value)))
+		}
+	}
+	if err != nil {
+		level.Error(d.logger).Log("msg", "failed to write push request to kafka", "tenant", tenantID, "err", err)
+		return httpgrpc.Errorf(http.StatusServiceUnavailable, "failed to write to kafka: %s", err)
+	}
+	return nil
 }
 
 type labelData struct {
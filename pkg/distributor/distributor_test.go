@@ -2,10 +2,12 @@ package distributor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,15 +36,19 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/ingester"
 	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/kafka"
+	"github.com/grafana/loki/v3/pkg/kafka/kafkatest"
 	loghttp_push "github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/metadata"
 	"github.com/grafana/loki/v3/pkg/runtime"
 	"github.com/grafana/loki/v3/pkg/util/constants"
 	fe "github.com/grafana/loki/v3/pkg/util/flagext"
 	loki_flagext "github.com/grafana/loki/v3/pkg/util/flagext"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	loki_net "github.com/grafana/loki/v3/pkg/util/net"
+	lokiring "github.com/grafana/loki/v3/pkg/util/ring"
 	"github.com/grafana/loki/v3/pkg/util/test"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -61,7 +67,11 @@ func TestDistributor(t *testing.T) {
 		streams          int
 		mangleLabels     int
 		expectedResponse *logproto.PushResponse
-		expectedErrors   []error
+		// expectedRejected is the number of rejected entries reported in the partial success.
+		expectedRejected int64
+		// expectedReasons are the reasons of the rejected streams reported in the partial success.
+		expectedReasons []string
+		expectedErrors  []error
 	}{
 		{
 			lines:            10,
@@ -77,14 +87,16 @@ func TestDistributor(t *testing.T) {
 			lines:            100,
 			streams:          1,
 			maxLineSize:      1,
-			expectedResponse: success,
+			expectedRejected: 100,
+			expectedReasons:  []string{validation.LineTooLong},
 			expectedErrors:   []error{httpgrpc.Errorf(http.StatusBadRequest, "100 errors like: %s", fmt.Sprintf(validation.LineTooLongErrorMsg, 1, "{foo=\"bar\"}", 10))},
 		},
 		{
 			lines:            100,
 			streams:          1,
 			mangleLabels:     1,
-			expectedResponse: success,
+			expectedRejected: 100,
+			expectedReasons:  []string{validation.InvalidLabels},
 			expectedErrors:   []error{httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidLabelsErrorMsg, "{ab\"", "1:4: parse error: unterminated quoted string")},
 		},
 		{
@@ -92,7 +104,8 @@ func TestDistributor(t *testing.T) {
 			streams:          2,
 			mangleLabels:     1,
 			maxLineSize:      1,
-			expectedResponse: success,
+			expectedRejected: 20,
+			expectedReasons:  []string{validation.InvalidLabels, validation.LineTooLong},
 			expectedErrors: []error{
 				httpgrpc.Errorf(http.StatusBadRequest, ""),
 				fmt.Errorf("1 errors like: %s", fmt.Sprintf(validation.InvalidLabelsErrorMsg, "{ab\"", "1:4: parse error: unterminated quoted string")),
@@ -121,7 +134,17 @@ func TestDistributor(t *testing.T) {
 			}
 
 			response, err := distributors[i%len(distributors)].Push(ctx, &request)
-			assert.Equal(t, tc.expectedResponse, response)
+			if tc.expectedRejected > 0 {
+				require.NotNil(t, response.PartialSuccess)
+				assert.Equal(t, tc.expectedRejected, response.PartialSuccess.RejectedEntries)
+				reasons := make([]string, 0, len(response.PartialSuccess.Streams))
+				for _, s := range response.PartialSuccess.Streams {
+					reasons = append(reasons, s.Reason)
+				}
+				assert.Equal(t, tc.expectedReasons, reasons)
+			} else {
+				assert.Equal(t, tc.expectedResponse, response)
+			}
 			if len(tc.expectedErrors) > 0 {
 				for _, expectedError := range tc.expectedErrors {
 					if len(tc.expectedErrors) == 1 {
@@ -1267,6 +1290,72 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushStreamLimitPolicies(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverServiceName = nil
+	limits.StreamLimitPolicies = []validation.StreamLimitPolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, IngestionRate: 10},
+	}
+	require.NoError(t, limits.Validate())
+
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+	lbs := []string{`{namespace="batch"}`, `{namespace="prod"}`}
+
+	response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, lbs))
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+
+	// The batch stream exceeds the policy rate, while the prod stream is still accepted.
+	response, err = distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, lbs))
+	require.NotNil(t, response.PartialSuccess)
+	require.Equal(t, int64(1), response.PartialSuccess.RejectedEntries)
+	require.Len(t, response.PartialSuccess.Streams, 1)
+	require.Equal(t, int32(0), response.PartialSuccess.Streams[0].Index)
+	require.Equal(t, validation.PolicyRateLimited, response.PartialSuccess.Streams[0].Reason)
+	require.True(t, response.PartialSuccess.Streams[0].Retryable)
+	require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, validation.PolicyRateLimitedErrorMsg, "batch", "test", 10, 1, 6, `{namespace="batch"}`), err)
+
+	// Each accepted stream is replicated to all 3 ingesters.
+	test.Poll(t, time.Second, map[string]int{`{namespace="batch"}`: 3, `{namespace="prod"}`: 6}, func() interface{} {
+		pushed := map[string]int{}
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				for _, stream := range req.Streams {
+					pushed[stream.Labels]++
+				}
+			}
+			ingesters[i].mu.Unlock()
+		}
+		return pushed
+	})
+}
+
+func TestDistributor_PushStreamLimitPoliciesTenantRateLimited(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverServiceName = nil
+	limits.IngestionRateMB = 1 / float64(1<<20)
+	limits.IngestionBurstSizeMB = 6 / float64(1<<20)
+	limits.StreamLimitPolicies = []validation.StreamLimitPolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, IngestionRate: 10},
+	}
+	require.NoError(t, limits.Validate())
+
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	// The push exceeds the tenant rate limit, so it doesn't use up the budget of the policy.
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, []string{`{namespace="batch"}`, `{namespace="prod"}`}))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, []string{`{namespace="batch"}`}))
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+}
+
 func prepare(t *testing.T, numDistributors, numIngesters int, limits *validation.Limits, factory func(addr string) (ring_client.PoolClient, error)) ([]*Distributor, []mockIngester) {
 	t.Helper()
 
@@ -1399,8 +1488,14 @@ type mockIngester struct {
 
 	failAfter    time.Duration
 	succeedAfter time.Duration
-	mu           sync.Mutex
-	pushed       []*logproto.PushRequest
+	// rejectLabels rejects the streams whose labels contain it, reporting them
+	// in the partial success of the response.
+	rejectLabels string
+	// rejectOffsets only rejects the entries at these offsets of the streams
+	// matching rejectLabels.
+	rejectOffsets []int32
+	mu            sync.Mutex
+	pushed        []*logproto.PushRequest
 }
 
 func (i *mockIngester) Push(_ context.Context, in *logproto.PushRequest, _ ...grpc.CallOption) (*logproto.PushResponse, error) {
@@ -1416,6 +1511,36 @@ func (i *mockIngester) Push(_ context.Context, in *logproto.PushRequest, _ ...gr
 	defer i.mu.Unlock()
 
 	i.pushed = append(i.pushed, in)
+
+	if i.rejectLabels != "" {
+		var partial logproto.PushPartialSuccess
+		for idx, stream := range in.Streams {
+			if strings.Contains(stream.Labels, i.rejectLabels) {
+				res := logproto.StreamPushResult{
+					Index:           int32(idx),
+					Labels:          stream.Labels,
+					RejectedEntries: int64(len(stream.Entries)),
+					Reason:          validation.StreamRateLimit,
+					Error:           "stream rate limited",
+					Retryable:       true,
+				}
+				if i.rejectOffsets != nil {
+					for _, o := range i.rejectOffsets {
+						if int(o) < len(stream.Entries) {
+							res.RejectedOffsets = append(res.RejectedOffsets, o)
+						}
+					}
+					res.RejectedEntries = int64(len(res.RejectedOffsets))
+					res.AcceptedEntries = int64(len(stream.Entries)) - res.RejectedEntries
+				}
+				partial.RejectedEntries += res.RejectedEntries
+				partial.Streams = append(partial.Streams, res)
+			}
+		}
+		if len(partial.Streams) > 0 {
+			return &logproto.PushResponse{PartialSuccess: &partial}, nil
+		}
+	}
 	return nil, nil
 }
 
@@ -1589,6 +1714,54 @@ func Test_DetectLogLevels(t *testing.T) {
 	})
 }
 
+func Test_DemoteHighCardinalityLabels(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.LabelCardinalityDemotionThreshold = 2
+	limits.MaxStructuredMetadataEntriesCount = 1
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	for _, requestID := range []string{"1", "2"} {
+		md, ctx := metadata.NewContext(ctx)
+		_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 10, []string{fmt.Sprintf(`{foo="bar", request_id="%s"}`, requestID)}))
+		require.NoError(t, err)
+		require.Empty(t, md.Warnings())
+	}
+
+	// A single warning is added for the demoted label of all the streams, and the demoted
+	// label doesn't count against the structured metadata limits of the entries.
+	md, ctx := metadata.NewContext(ctx)
+	req := makeWriteRequestWithLabels(1, 10, []string{`{foo="baz", request_id="4"}`, `{foo="bar", request_id="3"}`})
+	for i := range req.Streams {
+		req.Streams[i].Entries[0].StructuredMetadata = push.LabelsAdapter{{Name: "trace_id", Value: "1"}}
+	}
+	_, err := distributors[0].Push(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{fmt.Sprintf(labelDemotedWarning, "request_id", 2, time.Hour)}, md.Warnings())
+
+	ingester.mu.Lock()
+	defer ingester.mu.Unlock()
+	var pushed []logproto.Stream
+	for _, req := range ingester.pushed {
+		pushed = append(pushed, req.Streams...)
+	}
+	pushed = pushed[len(pushed)-2:]
+	sort.Slice(pushed, func(i, j int) bool { return pushed[i].Labels < pushed[j].Labels })
+	require.Equal(t, `{foo="bar", service_name="unknown_service"}`, pushed[0].Labels)
+	require.Equal(t, push.LabelsAdapter{
+		{
+			Name:  "trace_id",
+			Value: "1",
+		},
+		{
+			Name:  "request_id",
+			Value: "3",
+		},
+	}, pushed[0].Entries[0].StructuredMetadata)
+}
+
 func Test_detectLogLevelFromLogEntry(t *testing.T) {
 	for _, tc := range []struct {
 		name             string
@@ -1674,3 +1847,122 @@ func Benchmark_extractLogLevelFromLogLine(b *testing.B) {
 		require.Equal(b, logLevelInfo, level)
 	}
 }
+
+type failingKafkaClient struct {
+	kafka.Client
+}
+
+func (failingKafkaClient) Produce(_ context.Context, _ []kafka.Record) error {
+	return errors.New("broker unavailable")
+}
+
+func TestDistributor_PushToKafka(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+	d := distributors[0]
+
+	cluster := kafkatest.NewCluster(4)
+	d.kafkaClient = cluster
+	d.cfg.KafkaConfig = kafka.Config{Enabled: true, Topic: "loki", WriteTimeout: time.Second}
+
+	request := makeWriteRequestWithLabels(10, 64, []string{`{foo="bar"}`, `{foo="baz"}`, `{foo="qux"}`, `{foo="quux"}`, `{foo="corge"}`})
+	_, err := d.Push(ctx, request)
+	require.NoError(t, err)
+
+	var pushedStreams int
+	for partition := int32(0); partition < 4; partition++ {
+		for _, record := range cluster.Records(partition) {
+			require.Equal(t, "test", string(record.Key))
+
+			var req logproto.PushRequest
+			require.NoError(t, req.Unmarshal(record.Value))
+			for _, stream := range req.Streams {
+				require.Len(t, stream.Entries, 10)
+				require.Equal(t, partition, int32(lokiring.TokenFor("test", stream.Labels)%4))
+				pushedStreams++
+			}
+		}
+	}
+	require.Equal(t, 5, pushedStreams)
+
+	for i := range ingesters {
+		ingesters[i].mu.Lock()
+		require.Empty(t, ingesters[i].pushed)
+		ingesters[i].mu.Unlock()
+	}
+
+	d.kafkaClient = failingKafkaClient{cluster}
+	_, err = d.Push(ctx, makeWriteRequestWithLabels(1, 64, []string{`{foo="bar"}`}))
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusServiceUnavailable), resp.Code)
+}
+
+func TestDistributor_PushPartialSuccessFromIngesters(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+
+	lbs := []string{`{foo="good"}`, `{foo="bad"}`, `{foo="fine"}`}
+
+	t.Run("rejected by a quorum of ingesters", func(t *testing.T) {
+		distributors, ingesters := prepare(t, 1, 3, limits, nil)
+		for i := range ingesters {
+			ingesters[i].rejectLabels = `foo="bad"`
+		}
+
+		response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(5, 10, lbs))
+		require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, "stream rate limited"), err)
+		require.Equal(t, &logproto.PushResponse{PartialSuccess: &logproto.PushPartialSuccess{
+			RejectedEntries: 5,
+			Streams: []logproto.StreamPushResult{{
+				Index:           1,
+				Labels:          `{foo="bad"}`,
+				AcceptedEntries: 0,
+				RejectedEntries: 5,
+				Reason:          validation.StreamRateLimit,
+				Error:           "stream rate limited",
+				Retryable:       true,
+			}},
+		}}, response)
+	})
+
+	t.Run("shards partially rejected by the ingesters", func(t *testing.T) {
+		limits := &validation.Limits{}
+		flagext.DefaultValues(limits)
+		// 4 valid entries of 10 bytes make 2 shards.
+		limits.ShardStreams.DesiredRate = loki_flagext.ByteSize(30)
+
+		distributors, ingesters := prepare(t, 1, 3, limits, nil)
+		distributors[0].rateStore = &fakeRateStore{pushRate: 1}
+		for i := range ingesters {
+			ingesters[i].rejectLabels = `foo="bad"`
+			ingesters[i].rejectOffsets = []int32{1}
+		}
+
+		req := makeWriteRequestWithLabels(5, 10, []string{`{foo="bad"}`})
+		req.Streams[0].Entries[0].Timestamp = time.Unix(0, 0)
+
+		response, err := distributors[0].Push(ctx, req)
+		require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, "stream rate limited"), err)
+		require.Len(t, response.PartialSuccess.Streams, 1)
+		res := response.PartialSuccess.Streams[0]
+		require.Equal(t, int64(2), res.AcceptedEntries)
+		require.Equal(t, int64(3), res.RejectedEntries)
+		// The first entry is too old, and the shards get the valid entries
+		// at offsets 1, 3 and 2, 4 of the request in turn.
+		require.Equal(t, []int32{0, 3, 4}, res.RejectedOffsets)
+		require.True(t, res.Retryable)
+	})
+
+	t.Run("rejected by a single ingester", func(t *testing.T) {
+		distributors, ingesters := prepare(t, 1, 3, limits, nil)
+		ingesters[0].rejectLabels = `foo="bad"`
+
+		response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(5, 10, lbs))
+		require.NoError(t, err)
+		require.Equal(t, success, response)
+	})
+}
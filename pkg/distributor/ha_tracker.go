@@ -0,0 +1,507 @@
+package distributor
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/codec"
+	"github.com/grafana/dskit/kv/memberlist"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/timestamp"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	// haCleanupPeriod is how often the HA tracker looks for the elections to
+	// delete from the KV store.
+	haCleanupPeriod = 5 * time.Minute
+	// haReplicaDeletionDelay is how long after the last push of its elected
+	// replica an election is marked as deleted, and how long after being
+	// marked it is deleted from the KV store. The delay between the two lets
+	// all the distributors learn about the deletion.
+	haReplicaDeletionDelay = 30 * time.Minute
+)
+
+var (
+	errNegativeUpdateTimeoutJitterMax = errors.New("HA tracker max update timeout jitter shouldn't be negative")
+	errInvalidFailoverTimeout         = errors.New("HA tracker failover timeout must be greater than the update timeout plus its max jitter")
+)
+
+// HATrackerConfig configures the tracker electing, for each HA cluster of a
+// tenant, the replica whose logs are accepted.
+type HATrackerConfig struct {
+	EnableHATracker bool `yaml:"enable_ha_tracker"`
+	// We should only update the timestamp if the difference
+	// between the stored timestamp and the time we received a push at
+	// is more than this duration.
+	UpdateTimeout          time.Duration `yaml:"ha_tracker_update_timeout"`
+	UpdateTimeoutJitterMax time.Duration `yaml:"ha_tracker_update_timeout_jitter_max"`
+	// We should only failover to accepting logs from a replica
+	// other than the replica written in the KVStore if the difference
+	// between the stored timestamp and the time we received a push is
+	// more than this duration
+	FailoverTimeout time.Duration `yaml:"ha_tracker_failover_timeout"`
+
+	KVStore kv.Config `yaml:"kvstore" doc:"description=Backend storage to use for the HA tracker elections."`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet.
+func (cfg *HATrackerConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.EnableHATracker, "distributor.ha-tracker.enable", false, "Enable the HA tracker so that the distributors accept the logs of a single replica of each HA cluster, as identified by the `ha_cluster_label` and `ha_replica_label` stream labels. The tracking must also be enabled per tenant with `ha_tracker_enabled`.")
+	f.DurationVar(&cfg.UpdateTimeout, "distributor.ha-tracker.update-timeout", 15*time.Second, "Update the timestamp of the elected replica in the KV store at most this often.")
+	f.DurationVar(&cfg.UpdateTimeoutJitterMax, "distributor.ha-tracker.update-timeout-jitter-max", 5*time.Second, "Maximum jitter applied to the update timeout, in order to spread the updates to the KV store over time.")
+	f.DurationVar(&cfg.FailoverTimeout, "distributor.ha-tracker.failover-timeout", 30*time.Second, "If no push was received from the elected replica for this long, fail over to another replica. It must be greater than the update timeout plus its max jitter.")
+
+	// We want the ability to use different Consul instances for the ring and
+	// for the HA tracker, so the KV store gets its own flags.
+	cfg.KVStore.RegisterFlagsWithPrefix("distributor.ha-tracker.", "ha-tracker/", f)
+}
+
+// Validate config and returns error on failure
+func (cfg *HATrackerConfig) Validate() error {
+	if cfg.UpdateTimeoutJitterMax < 0 {
+		return errNegativeUpdateTimeoutJitterMax
+	}
+	if cfg.FailoverTimeout < cfg.UpdateTimeout+cfg.UpdateTimeoutJitterMax+time.Second {
+		return errInvalidFailoverTimeout
+	}
+	return nil
+}
+
+// GetReplicaDescCodec returns the codec of the values stored by the HA tracker.
+func GetReplicaDescCodec() codec.Proto {
+	return codec.NewProtoCodec("replicaDesc", func() proto.Message {
+		return &ReplicaDesc{}
+	})
+}
+
+// Merge implements memberlist.Mergeable. The most recent election wins, and
+// the deletion of an election wins over the election itself.
+func (r *ReplicaDesc) Merge(mergeable memberlist.Mergeable, _ bool) (memberlist.Mergeable, error) {
+	if mergeable == nil {
+		return nil, nil
+	}
+	other, ok := mergeable.(*ReplicaDesc)
+	if !ok {
+		return nil, fmt.Errorf("expected *distributor.ReplicaDesc, got %T", mergeable)
+	}
+	if other == nil || !other.supersedes(r) {
+		return nil, nil
+	}
+
+	*r = *other
+	return r.Clone(), nil
+}
+
+func (r *ReplicaDesc) supersedes(other *ReplicaDesc) bool {
+	if r.ReceivedAt != other.ReceivedAt {
+		return r.ReceivedAt > other.ReceivedAt
+	}
+	if r.Replica != other.Replica {
+		return r.Replica > other.Replica
+	}
+	return r.DeletedAt > other.DeletedAt
+}
+
+// MergeContent implements memberlist.Mergeable.
+func (r *ReplicaDesc) MergeContent() []string {
+	return []string{r.Replica}
+}
+
+// RemoveTombstones implements memberlist.Mergeable. An election marked as
+// deleted is a tombstone, removed once marked before the limit. Memberlist
+// can't delete keys, so the tombstone is only reported as removed.
+func (r *ReplicaDesc) RemoveTombstones(limit time.Time) (total, removed int) {
+	if r.DeletedAt == 0 {
+		return 0, 0
+	}
+	if limit.IsZero() || timestamp.Time(r.DeletedAt).Before(limit) {
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// Clone implements memberlist.Mergeable.
+func (r *ReplicaDesc) Clone() memberlist.Mergeable {
+	out := *r
+	return &out
+}
+
+// haTrackerLimits are the per-tenant limits used by the HA tracker.
+type haTrackerLimits interface {
+	// HAMaxClusters returns the maximum number of HA clusters tracked for the tenant.
+	HAMaxClusters(userID string) int
+}
+
+// haTracker elects, for each HA cluster of a tenant, the replica whose logs
+// are accepted. The elected replicas are stored in the KV store, so that all
+// the distributors agree on them. The elected replica is replaced by the
+// first other replica pushing logs once it did not push for the failover
+// timeout.
+type haTracker struct {
+	services.Service
+
+	cfg    HATrackerConfig
+	limits haTrackerLimits
+	client kv.Client
+	logger log.Logger
+
+	// Replicas we are accepting logs from, keyed by tenant and cluster.
+	electedLock sync.RWMutex
+	elected     map[string]ReplicaDesc
+	clusters    map[string]map[string]struct{}
+
+	updateTimeoutJitter time.Duration
+
+	electedReplicaChanges   *prometheus.CounterVec
+	electedReplicaTimestamp *prometheus.GaugeVec
+	kvCASCalls              *prometheus.CounterVec
+}
+
+func newHATracker(cfg HATrackerConfig, limits haTrackerLimits, reg prometheus.Registerer, logger log.Logger) (*haTracker, error) {
+	var jitter time.Duration
+	if cfg.UpdateTimeoutJitterMax > 0 {
+		jitter = time.Duration(rand.Int63n(int64(2*cfg.UpdateTimeoutJitterMax))) - cfg.UpdateTimeoutJitterMax
+	}
+
+	t := &haTracker{
+		cfg:                 cfg,
+		limits:              limits,
+		logger:              logger,
+		elected:             map[string]ReplicaDesc{},
+		clusters:            map[string]map[string]struct{}{},
+		updateTimeoutJitter: jitter,
+		electedReplicaChanges: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ha_tracker_elected_replica_changes_total",
+			Help:      "The total number of times the elected replica has changed for a tenant's HA cluster.",
+		}, []string{"tenant", "cluster"}),
+		electedReplicaTimestamp: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ha_tracker_elected_replica_timestamp_seconds",
+			Help:      "The timestamp stored for the currently elected replica, from the KV store.",
+		}, []string{"tenant", "cluster"}),
+		kvCASCalls: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ha_tracker_kv_store_cas_total",
+			Help:      "The total number of CAS calls to the KV store for a tenant's HA cluster.",
+		}, []string{"tenant", "cluster"}),
+	}
+
+	client, err := kv.NewClient(cfg.KVStore, GetReplicaDescCodec(), kv.RegistererWithKVName(reg, "distributor-hatracker"), logger)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+
+	t.Service = services.NewBasicService(nil, t.loop, nil)
+	return t, nil
+}
+
+// loop follows the changes of the elected replicas made by all the
+// distributors, and deletes the elections of the clusters which stopped
+// pushing.
+func (t *haTracker) loop(ctx context.Context) error {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t.client.WatchPrefix(ctx, "", func(key string, value interface{}) bool {
+			tenant, cluster, ok := splitHAKey(key)
+			if !ok {
+				level.Warn(t.logger).Log("msg", "invalid HA tracker key", "key", key)
+				return true
+			}
+			replica, _ := value.(*ReplicaDesc)
+			if replica == nil || replica.DeletedAt > 0 {
+				t.removeElected(tenant, cluster)
+				return true
+			}
+			t.setElected(tenant, cluster, *replica)
+			return true
+		})
+	}()
+
+	ticker := time.NewTicker(haCleanupPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.cleanupOldReplicas(ctx, time.Now())
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+	}
+}
+
+// cleanupOldReplicas marks as deleted the elections whose replica did not push
+// for haReplicaDeletionDelay, and deletes from the KV store the elections
+// marked as deleted for as long.
+func (t *haTracker) cleanupOldReplicas(ctx context.Context, now time.Time) {
+	deadline := now.Add(-haReplicaDeletionDelay)
+
+	keys, err := t.client.List(ctx, "")
+	if err != nil {
+		level.Warn(t.logger).Log("msg", "failed to list the HA tracker elections", "err", err)
+		return
+	}
+
+	var marked, deleted int
+	for _, key := range keys {
+		tenant, cluster, ok := splitHAKey(key)
+		if !ok {
+			continue
+		}
+		value, err := t.client.Get(ctx, key)
+		if err != nil {
+			level.Warn(t.logger).Log("msg", "failed to get the HA tracker election", "key", key, "err", err)
+			continue
+		}
+		desc, _ := value.(*ReplicaDesc)
+		if desc == nil {
+			continue
+		}
+
+		if desc.DeletedAt > 0 {
+			if !timestamp.Time(desc.DeletedAt).Before(deadline) {
+				continue
+			}
+			// The election may have been renewed since it was read: the
+			// distributors electing it again will create the key again.
+			if err := t.client.Delete(ctx, key); err != nil {
+				// Memberlist can't delete keys, the tombstone is kept.
+				level.Debug(t.logger).Log("msg", "failed to delete the HA tracker election", "key", key, "err", err)
+				continue
+			}
+			deleted++
+			continue
+		}
+
+		if !timestamp.Time(desc.ReceivedAt).Before(deadline) {
+			continue
+		}
+		var marking bool
+		err = t.client.CAS(ctx, key, func(in interface{}) (interface{}, bool, error) {
+			desc, _ := in.(*ReplicaDesc)
+			marking = desc != nil && desc.DeletedAt == 0 && timestamp.Time(desc.ReceivedAt).Before(deadline)
+			if !marking {
+				return nil, false, nil
+			}
+			out := *desc
+			out.DeletedAt = timestamp.FromTime(now)
+			return &out, true, nil
+		})
+		if err != nil {
+			level.Warn(t.logger).Log("msg", "failed to mark the HA tracker election as deleted", "key", key, "err", err)
+			continue
+		}
+		if marking {
+			// Do not wait for the watch to stop tracking the cluster.
+			t.removeElected(tenant, cluster)
+			marked++
+		}
+	}
+
+	if marked > 0 || deleted > 0 {
+		level.Info(t.logger).Log("msg", "cleaned up the HA tracker elections", "marked_deleted", marked, "deleted", deleted)
+	}
+}
+
+func (t *haTracker) setElected(tenant, cluster string, replica ReplicaDesc) {
+	t.electedLock.Lock()
+	defer t.electedLock.Unlock()
+
+	key := haKey(tenant, cluster)
+	if prev, ok := t.elected[key]; ok && prev.Replica != replica.Replica {
+		t.electedReplicaChanges.WithLabelValues(tenant, cluster).Inc()
+	}
+	t.elected[key] = replica
+	if t.clusters[tenant] == nil {
+		t.clusters[tenant] = map[string]struct{}{}
+	}
+	t.clusters[tenant][cluster] = struct{}{}
+	t.electedReplicaTimestamp.WithLabelValues(tenant, cluster).Set(float64(replica.ReceivedAt / 1000))
+}
+
+// removeElected stops tracking the tenant's cluster, so that it no longer
+// counts against the maximum number of clusters of the tenant.
+func (t *haTracker) removeElected(tenant, cluster string) {
+	t.electedLock.Lock()
+	defer t.electedLock.Unlock()
+
+	delete(t.elected, haKey(tenant, cluster))
+	if clusters, ok := t.clusters[tenant]; ok {
+		delete(clusters, cluster)
+		if len(clusters) == 0 {
+			delete(t.clusters, tenant)
+		}
+	}
+	t.electedReplicaChanges.DeleteLabelValues(tenant, cluster)
+	t.electedReplicaTimestamp.DeleteLabelValues(tenant, cluster)
+	t.kvCASCalls.DeleteLabelValues(tenant, cluster)
+}
+
+// checkReplica returns nil if the logs of the replica of the tenant's cluster
+// must be accepted, a replicasNotMatchError if another replica is elected, or
+// another error if the election failed.
+func (t *haTracker) checkReplica(ctx context.Context, tenant, cluster, replica string, now time.Time) error {
+	key := haKey(tenant, cluster)
+
+	t.electedLock.RLock()
+	entry, ok := t.elected[key]
+	numClusters := len(t.clusters[tenant])
+	t.electedLock.RUnlock()
+
+	if ok {
+		// The elected replica pushed recently: no need to touch the KV store.
+		if entry.Replica == replica && now.Sub(timestamp.Time(entry.ReceivedAt)) < t.cfg.UpdateTimeout+t.updateTimeoutJitter {
+			return nil
+		}
+		// Another replica is elected and it did not time out yet.
+		if entry.Replica != replica && now.Sub(timestamp.Time(entry.ReceivedAt)) < t.cfg.FailoverTimeout {
+			return replicasNotMatchError{cluster: cluster, replica: replica, elected: entry.Replica}
+		}
+	} else if maxClusters := t.limits.HAMaxClusters(tenant); maxClusters > 0 && numClusters >= maxClusters {
+		return tooManyClustersError{tenant: tenant, cluster: cluster, limit: maxClusters}
+	}
+
+	return t.updateKVStore(ctx, tenant, cluster, replica, now)
+}
+
+func (t *haTracker) updateKVStore(ctx context.Context, tenant, cluster, replica string, now time.Time) error {
+	var elected *ReplicaDesc
+	err := t.client.CAS(ctx, haKey(tenant, cluster), func(in interface{}) (interface{}, bool, error) {
+		desc, ok := in.(*ReplicaDesc)
+		// An election marked as deleted is replaced by a new one.
+		if ok && desc != nil && desc.DeletedAt == 0 {
+			receivedAt := timestamp.Time(desc.ReceivedAt)
+			// Another distributor may have updated the entry in the meantime.
+			if desc.Replica == replica && now.Sub(receivedAt) < t.cfg.UpdateTimeout {
+				elected = desc
+				return nil, false, nil
+			}
+			if desc.Replica != replica && now.Sub(receivedAt) < t.cfg.FailoverTimeout {
+				elected = desc
+				return nil, false, replicasNotMatchError{cluster: cluster, replica: replica, elected: desc.Replica}
+			}
+		}
+
+		elected = &ReplicaDesc{Replica: replica, ReceivedAt: timestamp.FromTime(now)}
+		return elected, true, nil
+	})
+	t.kvCASCalls.WithLabelValues(tenant, cluster).Inc()
+
+	// Do not wait for the watch to learn about the outcome of the CAS.
+	if elected != nil {
+		t.setElected(tenant, cluster, *elected)
+	}
+	if err != nil && !errors.As(err, &replicasNotMatchError{}) {
+		level.Error(t.logger).Log("msg", "failed to update the elected HA replica", "tenant", tenant, "cluster", cluster, "err", err)
+	}
+	return err
+}
+
+func haKey(tenant, cluster string) string {
+	return tenant + "/" + cluster
+}
+
+func splitHAKey(key string) (tenant, cluster string, ok bool) {
+	return strings.Cut(key, "/")
+}
+
+// replicasNotMatchError is returned when the logs of a replica which is not
+// the elected one for its cluster are dropped.
+type replicasNotMatchError struct {
+	cluster, replica, elected string
+}
+
+func (e replicasNotMatchError) Error() string {
+	return fmt.Sprintf(validation.HADuplicateErrorMsg, e.replica, e.cluster, e.elected)
+}
+
+// tooManyClustersError is returned when a tenant already has the maximum
+// number of HA clusters tracked.
+type tooManyClustersError struct {
+	tenant, cluster string
+	limit           int
+}
+
+func (e tooManyClustersError) Error() string {
+	return fmt.Sprintf(validation.TooManyHAClustersErrorMsg, e.cluster, e.limit, e.tenant)
+}
+
+// haDedupe checks the HA cluster and replica of the streams of a push request,
+// caching the outcome of each pair for the duration of the request.
+type haDedupe struct {
+	tracker      *haTracker
+	tenant       string
+	clusterLabel string
+	replicaLabel string
+
+	checked map[[2]string]error
+}
+
+func (d *Distributor) newHADedupe(tenant string) *haDedupe {
+	if d.haTracker == nil || !d.validator.HATrackerEnabled(tenant) {
+		return nil
+	}
+	return &haDedupe{
+		tracker:      d.haTracker,
+		tenant:       tenant,
+		clusterLabel: d.validator.HAClusterLabel(tenant),
+		replicaLabel: d.validator.HAReplicaLabel(tenant),
+		checked:      map[[2]string]error{},
+	}
+}
+
+// check returns the HA cluster of the stream and an error if its replica is
+// not the elected one or could not be elected. Streams without both labels
+// are not tracked and always accepted.
+func (h *haDedupe) check(ctx context.Context, lbs labels.Labels) (string, error) {
+	cluster, replica := lbs.Get(h.clusterLabel), lbs.Get(h.replicaLabel)
+	if cluster == "" || replica == "" {
+		return "", nil
+	}
+	key := [2]string{cluster, replica}
+	if err, ok := h.checked[key]; ok {
+		return cluster, err
+	}
+	err := h.tracker.checkReplica(ctx, h.tenant, cluster, replica, time.Now())
+	if err != nil && !errors.As(err, &replicasNotMatchError{}) && !errors.As(err, &tooManyClustersError{}) {
+		// The KV store is unavailable: ask the client to retry later.
+		err = httpgrpc.Errorf(http.StatusServiceUnavailable, "failed to check the HA replica: %s", err)
+	}
+	h.checked[key] = err
+	return cluster, err
+}
+
+// removeReplicaLabel removes the replica label from the stream, so that the
+// logs of all the replicas of a cluster end up in the same streams.
+func (h *haDedupe) removeReplicaLabel(lbs labels.Labels, stream *logproto.Stream) labels.Labels {
+	if !lbs.Has(h.replicaLabel) {
+		return lbs
+	}
+	lbs = labels.NewBuilder(lbs).Del(h.replicaLabel).Labels()
+	stream.Labels = lbs.String()
+	stream.Hash = lbs.Hash()
+	return lbs
+}
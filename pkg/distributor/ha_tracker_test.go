@@ -0,0 +1,320 @@
+package distributor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/kv"
+	"github.com/grafana/dskit/kv/consul"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util/test"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type haMaxClustersLimits int
+
+func (l haMaxClustersLimits) HAMaxClusters(_ string) int {
+	return int(l)
+}
+
+func newTestHATracker(t *testing.T, kvStore kv.Client, maxClusters int) *haTracker {
+	t.Helper()
+
+	cfg := HATrackerConfig{
+		EnableHATracker: true,
+		UpdateTimeout:   time.Second,
+		FailoverTimeout: 5 * time.Second,
+		KVStore:         kv.Config{Mock: kvStore},
+	}
+	require.NoError(t, cfg.Validate())
+
+	tracker, err := newHATracker(cfg, haMaxClustersLimits(maxClusters), prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), tracker))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), tracker))
+	})
+	return tracker
+}
+
+func TestHATracker_CheckReplica(t *testing.T) {
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+
+	tracker := newTestHATracker(t, kvStore, 0)
+	other := newTestHATracker(t, kvStore, 0)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// The first replica pushing is elected.
+	require.NoError(t, tracker.checkReplica(ctx, "user", "cluster", "r1", now))
+	require.ErrorAs(t, tracker.checkReplica(ctx, "user", "cluster", "r2", now), &replicasNotMatchError{})
+	// Clusters are elected independently.
+	require.NoError(t, tracker.checkReplica(ctx, "user", "other-cluster", "r2", now))
+
+	// Other distributors learn about the election from the KV store.
+	require.ErrorAs(t, other.checkReplica(ctx, "user", "cluster", "r2", now), &replicasNotMatchError{})
+	test.Poll(t, time.Second, "r1", func() interface{} {
+		other.electedLock.RLock()
+		defer other.electedLock.RUnlock()
+		return other.elected[haKey("user", "cluster")].Replica
+	})
+
+	// The elected replica keeps pushing, so its timestamp is updated.
+	now = now.Add(3 * time.Second)
+	require.NoError(t, tracker.checkReplica(ctx, "user", "cluster", "r1", now))
+	now = now.Add(3 * time.Second)
+	require.ErrorAs(t, other.checkReplica(ctx, "user", "cluster", "r2", now), &replicasNotMatchError{})
+
+	// The elected replica stopped pushing for longer than the failover timeout.
+	now = now.Add(5 * time.Second)
+	require.NoError(t, other.checkReplica(ctx, "user", "cluster", "r2", now))
+	require.ErrorAs(t, tracker.checkReplica(ctx, "user", "cluster", "r1", now), &replicasNotMatchError{})
+
+	desc, err := kvStore.Get(ctx, haKey("user", "cluster"))
+	require.NoError(t, err)
+	require.Equal(t, &ReplicaDesc{Replica: "r2", ReceivedAt: timestamp.FromTime(now)}, desc)
+}
+
+func TestHATracker_MaxClusters(t *testing.T) {
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+
+	tracker := newTestHATracker(t, kvStore, 2)
+
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, tracker.checkReplica(ctx, "user", "a", "r1", now))
+	require.NoError(t, tracker.checkReplica(ctx, "user", "b", "r1", now))
+	require.ErrorAs(t, tracker.checkReplica(ctx, "user", "c", "r1", now), &tooManyClustersError{})
+	// Known clusters and other tenants are not affected.
+	require.NoError(t, tracker.checkReplica(ctx, "user", "a", "r1", now))
+	require.NoError(t, tracker.checkReplica(ctx, "other", "c", "r1", now))
+}
+
+func TestReplicaDesc_Merge(t *testing.T) {
+	older := &ReplicaDesc{Replica: "r1", ReceivedAt: 1000}
+	newer := &ReplicaDesc{Replica: "r2", ReceivedAt: 2000}
+
+	r := older.Clone().(*ReplicaDesc)
+	change, err := r.Merge(newer.Clone(), false)
+	require.NoError(t, err)
+	require.Equal(t, newer, change)
+	require.Equal(t, newer, r)
+
+	// Merging is idempotent and commutative.
+	change, err = r.Merge(newer.Clone(), false)
+	require.NoError(t, err)
+	require.Nil(t, change)
+
+	r = newer.Clone().(*ReplicaDesc)
+	change, err = r.Merge(older.Clone(), false)
+	require.NoError(t, err)
+	require.Nil(t, change)
+	require.Equal(t, newer, r)
+
+	// The deletion of an election wins over the election, but not over a
+	// more recent one.
+	deleted := &ReplicaDesc{Replica: "r2", ReceivedAt: 2000, DeletedAt: 3000}
+	change, err = r.Merge(deleted.Clone(), false)
+	require.NoError(t, err)
+	require.Equal(t, deleted, change)
+	require.Equal(t, deleted, r)
+
+	change, err = r.Merge(newer.Clone(), false)
+	require.NoError(t, err)
+	require.Nil(t, change)
+
+	renewed := &ReplicaDesc{Replica: "r1", ReceivedAt: 4000}
+	change, err = r.Merge(renewed.Clone(), false)
+	require.NoError(t, err)
+	require.Equal(t, renewed, change)
+}
+
+func TestReplicaDesc_RemoveTombstones(t *testing.T) {
+	now := time.Now()
+
+	total, removed := (&ReplicaDesc{Replica: "r1", ReceivedAt: timestamp.FromTime(now)}).RemoveTombstones(now)
+	require.Equal(t, 0, total)
+	require.Equal(t, 0, removed)
+
+	deleted := &ReplicaDesc{Replica: "r1", ReceivedAt: timestamp.FromTime(now), DeletedAt: timestamp.FromTime(now)}
+	total, removed = deleted.RemoveTombstones(now.Add(-time.Minute))
+	require.Equal(t, 1, total)
+	require.Equal(t, 0, removed)
+	total, removed = deleted.RemoveTombstones(now.Add(time.Minute))
+	require.Equal(t, 0, total)
+	require.Equal(t, 1, removed)
+	total, removed = deleted.RemoveTombstones(time.Time{})
+	require.Equal(t, 0, total)
+	require.Equal(t, 1, removed)
+}
+
+func TestHATracker_CleanupOldReplicas(t *testing.T) {
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+
+	tracker := newTestHATracker(t, kvStore, 1)
+	other := newTestHATracker(t, kvStore, 1)
+
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, tracker.checkReplica(ctx, "user", "a", "r1", now))
+	require.ErrorAs(t, tracker.checkReplica(ctx, "user", "b", "r1", now), &tooManyClustersError{})
+
+	// Recent elections are kept.
+	tracker.cleanupOldReplicas(ctx, now.Add(haReplicaDeletionDelay/2))
+	desc, err := kvStore.Get(ctx, haKey("user", "a"))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), desc.(*ReplicaDesc).DeletedAt)
+
+	// The cluster stopped pushing: its election is marked as deleted and it no
+	// longer counts against the limit of any distributor.
+	now = now.Add(haReplicaDeletionDelay + time.Minute)
+	tracker.cleanupOldReplicas(ctx, now)
+	desc, err = kvStore.Get(ctx, haKey("user", "a"))
+	require.NoError(t, err)
+	require.Equal(t, timestamp.FromTime(now), desc.(*ReplicaDesc).DeletedAt)
+	for _, tr := range []*haTracker{tracker, other} {
+		tr := tr
+		test.Poll(t, time.Second, 0, func() interface{} {
+			tr.electedLock.RLock()
+			defer tr.electedLock.RUnlock()
+			return len(tr.elected) + len(tr.clusters)
+		})
+	}
+	require.NoError(t, other.checkReplica(ctx, "user", "b", "r1", now))
+
+	// The election marked as deleted is eventually deleted from the KV store.
+	tracker.cleanupOldReplicas(ctx, now.Add(haReplicaDeletionDelay+time.Minute))
+	desc, err = kvStore.Get(ctx, haKey("user", "a"))
+	require.NoError(t, err)
+	require.Nil(t, desc)
+	desc, err = kvStore.Get(ctx, haKey("user", "b"))
+	require.NoError(t, err)
+	require.NotNil(t, desc)
+}
+
+func TestDistributor_PushHADedupe(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.HATrackerEnabled = true
+	limits.HAMaxClusters = 1
+
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+
+	kvStore, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+	distributors[0].haTracker = newTestHATracker(t, kvStore, limits.HAMaxClusters)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	push := func(labels string) (*logproto.PushResponse, error) {
+		return distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 10, []string{labels}))
+	}
+
+	// The replica label is removed from the streams of the elected replica.
+	_, err := push(`{cluster="a", __replica__="r1", foo="bar"}`)
+	require.NoError(t, err)
+	// The other replicas are dropped, reported in the partial success.
+	resp, err := push(`{cluster="a", __replica__="r2", foo="bar"}`)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), resp.PartialSuccess.RejectedEntries)
+	require.Equal(t, validation.HADuplicate, resp.PartialSuccess.Streams[0].Reason)
+	require.False(t, resp.PartialSuccess.Streams[0].Retryable)
+	// Streams without replica are not deduplicated.
+	_, err = push(`{cluster="a", foo="baz"}`)
+	require.NoError(t, err)
+	// Streams of clusters above the limit are rejected.
+	resp, err = push(`{cluster="b", __replica__="r1", foo="bar"}`)
+	require.Error(t, err)
+	require.Equal(t, int64(1), resp.PartialSuccess.RejectedEntries)
+
+	// Each stream is replicated to the 3 ingesters, the last one asynchronously.
+	pushedLabels := func() []string {
+		var labels []string
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				for _, s := range req.Streams {
+					labels = append(labels, s.Labels)
+				}
+			}
+			ingesters[i].mu.Unlock()
+		}
+		return labels
+	}
+	test.Poll(t, time.Second, 6, func() interface{} {
+		return len(pushedLabels())
+	})
+	require.ElementsMatch(t, []string{
+		`{cluster="a", foo="bar", service_name="unknown_service"}`,
+		`{cluster="a", foo="bar", service_name="unknown_service"}`,
+		`{cluster="a", foo="bar", service_name="unknown_service"}`,
+		`{cluster="a", foo="baz", service_name="unknown_service"}`,
+		`{cluster="a", foo="baz", service_name="unknown_service"}`,
+		`{cluster="a", foo="baz", service_name="unknown_service"}`,
+	}, pushedLabels())
+}
+
+// failingKV fails the CAS calls while failing is set.
+type failingKV struct {
+	kv.Client
+	failing atomic.Bool
+}
+
+func (f *failingKV) CAS(ctx context.Context, key string, fn func(in interface{}) (out interface{}, retry bool, err error)) error {
+	if f.failing.Load() {
+		return errors.New("kv store unavailable")
+	}
+	return f.Client.CAS(ctx, key, fn)
+}
+
+func TestDistributor_PushHAKVFailure(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverServiceName = nil
+	limits.HATrackerEnabled = true
+	limits.StreamLimitPolicies = []validation.StreamLimitPolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, IngestionRate: 10},
+	}
+	require.NoError(t, limits.Validate())
+
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	consulKV, closer := consul.NewInMemoryClient(GetReplicaDescCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { require.NoError(t, closer.Close()) })
+	kvStore := &failingKV{Client: consulKV}
+	distributors[0].haTracker = newTestHATracker(t, kvStore, limits.HAMaxClusters)
+
+	// The push fails when the replica of a stream can't be checked.
+	kvStore.failing.Store(true)
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, []string{`{namespace="batch"}`, `{cluster="a", __replica__="r1"}`}))
+	require.Error(t, err)
+
+	// The batch stream was not ingested, so it did not use the rate of its policy.
+	kvStore.failing.Store(false)
+	response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, []string{`{namespace="batch"}`}))
+	require.NoError(t, err)
+	require.Nil(t, response.PartialSuccess)
+
+	// The policy rate is enforced on the ingested streams.
+	_, err = distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 6, []string{`{namespace="batch"}`}))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+}
@@ -1,6 +1,7 @@
 package distributor
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -13,10 +14,16 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/metadata"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
 
+// PushWarningHeader is the response header used to report non-fatal problems
+// with a push request, such as stream labels moved to structured metadata.
+const PushWarningHeader = "X-Loki-Push-Warning"
+
 // PushHandler reads a snappy-compressed proto from the HTTP body.
 func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 	d.pushHandler(w, r, push.ParseLokiRequest)
@@ -65,7 +72,25 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 		)
 	}
 
-	_, err = d.Push(r.Context(), req)
+	md, ctx := metadata.NewContext(r.Context())
+	pushResp, err := d.Push(ctx, req)
+	for _, warning := range md.Warnings() {
+		w.Header().Add(PushWarningHeader, warning)
+	}
+	if r.Header.Get(AcceptPartialSuccessHeader) == "true" && isPartialSuccess(req, pushResp) {
+		if d.tenantConfigs.LogPushRequest(tenantID) {
+			level.Debug(logger).Log(
+				"msg", "push request partially successful",
+				"rejected_entries", pushResp.PartialSuccess.RejectedEntries,
+				"err", err,
+			)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pushResp); err != nil {
+			level.Error(logger).Log("msg", "failed to write push response", "err", err)
+		}
+		return
+	}
 	if err == nil {
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
@@ -99,6 +124,20 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 	}
 }
 
+// isPartialSuccess returns true if the response reports rejected entries while
+// some other entries of the request were accepted.
+func isPartialSuccess(req *logproto.PushRequest, resp *logproto.PushResponse) bool {
+	if resp == nil || resp.PartialSuccess == nil {
+		return false
+	}
+
+	var total int64
+	for _, s := range req.Streams {
+		total += int64(len(s.Entries))
+	}
+	return resp.PartialSuccess.RejectedEntries < total
+}
+
 // ServeHTTP implements the distributor ring status page.
 //
 // If the rate limiting strategy is local instead of global, no ring is used by
@@ -2,10 +2,12 @@ package distributor
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/grafana/dskit/user"
 
@@ -85,3 +87,53 @@ func TestRequestParserWrapping(t *testing.T) {
 func stubParser(_ string, _ *http.Request, _ push.TenantsRetention, _ push.Limits, _ push.UsageTracker) (*logproto.PushRequest, *push.Stats, error) {
 	return &logproto.PushRequest{}, &push.Stats{}, nil
 }
+
+func TestPushHandlerPartialSuccess(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.MaxLineSize = 10
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	parser := func(_ string, _ *http.Request, _ push.TenantsRetention, _ push.Limits, _ push.UsageTracker) (*logproto.PushRequest, *push.Stats, error) {
+		now := time.Now()
+		return &logproto.PushRequest{Streams: []logproto.Stream{
+			{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: now, Line: "short"}}},
+			{Labels: `{foo="baz"}`, Entries: []logproto.Entry{{Timestamp: now, Line: "short"}, {Timestamp: now, Line: "way too long line"}}},
+		}}, &push.Stats{}, nil
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for _, tc := range []struct {
+		name           string
+		acceptPartial  bool
+		expectedStatus int
+	}{
+		{name: "partial success not accepted", expectedStatus: http.StatusBadRequest},
+		{name: "partial success accepted", acceptPartial: true, expectedStatus: http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "fake-path", nil)
+			require.NoError(t, err)
+			if tc.acceptPartial {
+				req.Header.Set(AcceptPartialSuccessHeader, "true")
+			}
+
+			rec := httptest.NewRecorder()
+			distributors[0].pushHandler(rec, req, parser)
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			if !tc.acceptPartial {
+				return
+			}
+
+			var resp logproto.PushResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.NotNil(t, resp.PartialSuccess)
+			require.Equal(t, int64(1), resp.PartialSuccess.RejectedEntries)
+			require.Len(t, resp.PartialSuccess.Streams, 1)
+			require.Equal(t, int32(1), resp.PartialSuccess.Streams[0].Index)
+			require.Equal(t, `{foo="baz"}`, resp.PartialSuccess.Streams[0].Labels)
+			require.Equal(t, int64(1), resp.PartialSuccess.Streams[0].AcceptedEntries)
+			require.False(t, resp.PartialSuccess.Streams[0].Retryable)
+		})
+	}
+}
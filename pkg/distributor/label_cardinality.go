@@ -0,0 +1,105 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// labelCardinalityTracker keeps track, per tenant and label name, of the
+// distinct label values pushed within a time window, and reports the labels
+// whose number of values exceeds the tenant's threshold. Such labels are
+// demoted to structured metadata by the distributor.
+//
+// The tracker only sees the streams pushed to this distributor, and once a
+// label is demoted it stays demoted until a full window goes by without the
+// threshold being exceeded again.
+type labelCardinalityTracker struct {
+	mtx     sync.Mutex
+	tenants map[string]*tenantLabelCardinality
+
+	now func() time.Time
+}
+
+type tenantLabelCardinality struct {
+	windowStart time.Time
+	labels      map[string]*labelValues
+}
+
+type labelValues struct {
+	// values seen in the current window. It holds at most threshold+1 values.
+	values  map[string]struct{}
+	demoted bool
+}
+
+func newLabelCardinalityTracker() *labelCardinalityTracker {
+	return &labelCardinalityTracker{
+		tenants: map[string]*tenantLabelCardinality{},
+		now:     time.Now,
+	}
+}
+
+// Observe records the values of the given stream labels and returns the names
+// of the labels that need to be demoted. Labels listed in exempt are never
+// tracked nor demoted.
+func (t *labelCardinalityTracker) Observe(tenantID string, lbs labels.Labels, threshold int, window time.Duration, exempt ...string) []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	now := t.now()
+	tenant, ok := t.tenants[tenantID]
+	if !ok {
+		tenant = &tenantLabelCardinality{
+			windowStart: now,
+			labels:      map[string]*labelValues{},
+		}
+		t.tenants[tenantID] = tenant
+	}
+
+	if now.Sub(tenant.windowStart) >= window {
+		tenant.rotate(threshold)
+		tenant.windowStart = now
+	}
+
+	var demoted []string
+outer:
+	for _, l := range lbs {
+		for _, name := range exempt {
+			if l.Name == name {
+				continue outer
+			}
+		}
+
+		lv, ok := tenant.labels[l.Name]
+		if !ok {
+			lv = &labelValues{values: map[string]struct{}{}}
+			tenant.labels[l.Name] = lv
+		}
+
+		if len(lv.values) <= threshold {
+			lv.values[l.Value] = struct{}{}
+		}
+		if len(lv.values) > threshold {
+			lv.demoted = true
+		}
+		if lv.demoted {
+			demoted = append(demoted, l.Name)
+		}
+	}
+
+	return demoted
+}
+
+// rotate starts a new window. Labels stay demoted only if they exceeded the
+// threshold during the window that just ended.
+func (t *tenantLabelCardinality) rotate(threshold int) {
+	for name, lv := range t.labels {
+		lv.demoted = len(lv.values) > threshold
+		if !lv.demoted {
+			delete(t.labels, name)
+			continue
+		}
+		clear(lv.values)
+	}
+}
@@ -0,0 +1,37 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelCardinalityTracker(t *testing.T) {
+	now := time.Unix(0, 0)
+	tracker := newLabelCardinalityTracker()
+	tracker.now = func() time.Time { return now }
+
+	observe := func(tenant, requestID string) []string {
+		lbs := labels.FromStrings("app", "foo", "request_id", requestID, "service_name", requestID)
+		return tracker.Observe(tenant, lbs, 2, time.Hour, "service_name")
+	}
+
+	require.Empty(t, observe("tenant-a", "1"))
+	require.Empty(t, observe("tenant-a", "2"))
+	require.Empty(t, observe("tenant-a", "2"))
+	require.Equal(t, []string{"request_id"}, observe("tenant-a", "3"))
+	require.Equal(t, []string{"request_id"}, observe("tenant-a", "1"))
+
+	// Tenants are tracked independently.
+	require.Empty(t, observe("tenant-b", "3"))
+
+	// The label stays demoted in the window following the one it exceeded the threshold.
+	now = now.Add(time.Hour)
+	require.Equal(t, []string{"request_id"}, observe("tenant-a", "1"))
+
+	// It is promoted back once a whole window goes by without exceeding the threshold.
+	now = now.Add(time.Hour)
+	require.Empty(t, observe("tenant-a", "1"))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // Limits is an interface for distributor limits/related configs
@@ -25,10 +26,19 @@ type Limits interface {
 	DiscoverServiceName(userID string) []string
 	DiscoverLogLevels(userID string) bool
 
+	LabelCardinalityDemotionThreshold(userID string) int
+	LabelCardinalityDemotionWindow(userID string) time.Duration
+
+	HATrackerEnabled(userID string) bool
+	HAClusterLabel(userID string) string
+	HAReplicaLabel(userID string) string
+	HAMaxClusters(userID string) int
+
 	ShardStreams(userID string) *shardstreams.Config
 	IngestionRateStrategy() string
 	IngestionRateBytes(userID string) float64
 	IngestionBurstSizeBytes(userID string) int
+	StreamLimitPolicies(userID string) []validation.StreamLimitPolicy
 	AllowStructuredMetadata(userID string) bool
 	MaxStructuredMetadataSize(userID string) int
 	MaxStructuredMetadataCount(userID string) int
@@ -0,0 +1,182 @@
+package distributor
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/grafana/dskit/httpgrpc"
+
+	"github.com/grafana/loki/v3/pkg/ingester"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// AcceptPartialSuccessHeader is the request header clients set to receive a
+// 200 response listing the rejected streams when only part of a push request
+// was accepted, instead of an error for the whole request.
+const AcceptPartialSuccessHeader = "X-Loki-Accept-Partial-Success"
+
+// withPartialSuccess asks the ingesters to report the streams they reject in
+// the push response rather than failing the whole push.
+func withPartialSuccess(ctx context.Context) context.Context {
+	return ingester.WithPartialSuccess(ctx)
+}
+
+// pushResults collects the entries rejected for each stream of a push request,
+// by the distributor itself or by the ingesters.
+type pushResults struct {
+	req *logproto.PushRequest
+
+	mtx     sync.Mutex
+	streams map[int]*streamResult
+	// ingesterErr is the first rejection reported by the ingesters, returned
+	// as error to the clients not accepting partial success.
+	ingesterErr error
+}
+
+// streamResult is the result of a stream of the request, with the offsets of
+// its rejected entries.
+type streamResult struct {
+	logproto.StreamPushResult
+
+	rejected map[int32]struct{}
+	// unknown is the number of entries rejected by ingesters which did not
+	// report which ones.
+	unknown int
+}
+
+func newPushResults(req *logproto.PushRequest) *pushResults {
+	return &pushResults{
+		req:     req,
+		streams: map[int]*streamResult{},
+	}
+}
+
+// reject records that the entries at the given offsets of the stream at the
+// given index of the request were rejected. Nil offsets stand for all the
+// entries of the stream.
+func (r *pushResults) reject(index int, offsets []int32, reason, msg string, retryable bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	res := r.result(index, reason, msg)
+	if offsets == nil {
+		offsets = allOffsets(len(r.req.Streams[index].Entries))
+	}
+	for _, o := range offsets {
+		res.rejected[o] = struct{}{}
+	}
+	// Pushing the stream again is worth it as soon as some of its entries may
+	// be accepted later.
+	res.Retryable = res.Retryable || retryable
+}
+
+func (r *pushResults) result(index int, reason, msg string) *streamResult {
+	res, ok := r.streams[index]
+	if !ok {
+		res = &streamResult{
+			StreamPushResult: logproto.StreamPushResult{
+				Index:  int32(index),
+				Labels: r.req.Streams[index].Labels,
+				Reason: reason,
+				Error:  msg,
+			},
+			rejected: map[int32]struct{}{},
+		}
+		r.streams[index] = res
+	}
+	return res
+}
+
+// rejectByIngester records the rejection of a stream reported by an ingester.
+// The result from the ingester refers to the given keyed stream, which may be
+// one of the shards of the stream of the request.
+func (r *pushResults) rejectByIngester(stream *streamTracker, res *logproto.StreamPushResult) {
+	switch {
+	case len(res.RejectedOffsets) > 0:
+		offsets := make([]int32, 0, len(res.RejectedOffsets))
+		for _, o := range res.RejectedOffsets {
+			if int(o) < len(stream.Stream.Entries) {
+				offsets = append(offsets, stream.requestOffset(o))
+			}
+		}
+		r.reject(stream.index, offsets, res.Reason, res.Error, res.Retryable)
+	case int(res.RejectedEntries) >= len(stream.Stream.Entries):
+		offsets := make([]int32, 0, len(stream.Stream.Entries))
+		for o := range stream.Stream.Entries {
+			offsets = append(offsets, stream.requestOffset(int32(o)))
+		}
+		r.reject(stream.index, offsets, res.Reason, res.Error, res.Retryable)
+	default:
+		// The ingester did not report which entries it rejected.
+		r.mtx.Lock()
+		result := r.result(stream.index, res.Reason, res.Error)
+		result.unknown += int(res.RejectedEntries)
+		result.Retryable = result.Retryable || res.Retryable
+		r.mtx.Unlock()
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.ingesterErr == nil {
+		code := http.StatusBadRequest
+		if res.Retryable {
+			code = http.StatusTooManyRequests
+		}
+		r.ingesterErr = httpgrpc.Errorf(code, res.Error)
+	}
+}
+
+func (r *pushResults) ingesterError() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.ingesterErr
+}
+
+// response returns the push response, with the partial success set if some
+// entries were rejected.
+func (r *pushResults) response() *logproto.PushResponse {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if len(r.streams) == 0 {
+		return &logproto.PushResponse{}
+	}
+
+	partial := &logproto.PushPartialSuccess{
+		Streams: make([]logproto.StreamPushResult, 0, len(r.streams)),
+	}
+	for index, res := range r.streams {
+		total := len(r.req.Streams[index].Entries)
+		result := res.StreamPushResult
+		result.RejectedEntries = int64(min(len(res.rejected)+res.unknown, total))
+		result.AcceptedEntries = int64(total) - result.RejectedEntries
+		// The offsets are only reported when some entries were accepted, and
+		// all the rejected ones are known.
+		if res.unknown == 0 && result.AcceptedEntries > 0 {
+			result.RejectedOffsets = make([]int32, 0, len(res.rejected))
+			for o := range res.rejected {
+				result.RejectedOffsets = append(result.RejectedOffsets, o)
+			}
+			slices.Sort(result.RejectedOffsets)
+		}
+
+		partial.RejectedEntries += result.RejectedEntries
+		partial.Streams = append(partial.Streams, result)
+	}
+	sort.Slice(partial.Streams, func(i, j int) bool {
+		return partial.Streams[i].Index < partial.Streams[j].Index
+	})
+	return &logproto.PushResponse{PartialSuccess: partial}
+}
+
+// allOffsets returns the offsets of n entries.
+func allOffsets(n int) []int32 {
+	offsets := make([]int32, n)
+	for i := range offsets {
+		offsets[i] = int32(i)
+	}
+	return offsets
+}
@@ -0,0 +1,117 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type policyKey struct {
+	tenant string
+	policy string
+}
+
+// policyRateLimiter enforces the ingestion rate of the stream limit policies
+// configured for each tenant. Limiters are created lazily, one per tenant and
+// policy, and reconfigured whenever the policy limits change.
+type policyRateLimiter struct {
+	limits Limits
+	// ring is used to divide the policy rate between the healthy distributors
+	// when the global ingestion rate strategy is used. Nil for the local strategy.
+	ring ReadLifecycler
+
+	mtx      sync.Mutex
+	limiters map[policyKey]*rate.Limiter
+}
+
+func newPolicyRateLimiter(limits Limits, ring ReadLifecycler) *policyRateLimiter {
+	return &policyRateLimiter{
+		limits:   limits,
+		ring:     ring,
+		limiters: make(map[policyKey]*rate.Limiter),
+	}
+}
+
+// policyReservations are the tokens consumed from the policy rate limiters by a
+// stream.
+type policyReservations []*rate.Reservation
+
+// cancel gives the tokens back to the limiters, when the stream is rejected
+// after the policy rate limits were checked. The tokens are only given back
+// when now is the time of the reservations.
+func (r policyReservations) cancel(now time.Time) {
+	for _, res := range r {
+		res.CancelAt(now)
+	}
+}
+
+// AllowN reports whether n bytes of the stream with the given labels may be
+// ingested at time now. Tokens are only consumed when all matching policies
+// allow the stream, and are returned so they can be given back if the stream is
+// rejected later on; otherwise the first policy that rejected it is returned.
+func (l *policyRateLimiter) AllowN(now time.Time, tenantID string, lbs labels.Labels, n int) (bool, policyReservations, *validation.StreamLimitPolicy) {
+	policies := l.limits.StreamLimitPolicies(tenantID)
+	if len(policies) == 0 {
+		return true, nil, nil
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	var reservations policyReservations
+	for i := range policies {
+		policy := &policies[i]
+		if policy.IngestionRate.Val() <= 0 || !policy.Matches(lbs) {
+			continue
+		}
+
+		r := l.limiterFor(now, tenantID, policy).ReserveN(now, n)
+		if !r.OK() || r.DelayFrom(now) > 0 {
+			r.CancelAt(now)
+			reservations.cancel(now)
+			return false, nil, policy
+		}
+		reservations = append(reservations, r)
+	}
+
+	return true, reservations, nil
+}
+
+// Limit returns the current rate limit in bytes per second of the given policy.
+func (l *policyRateLimiter) Limit(policy *validation.StreamLimitPolicy) float64 {
+	limit := float64(policy.IngestionRate.Val())
+	if l.ring == nil {
+		return limit
+	}
+
+	if numDistributors := l.ring.HealthyInstancesCount(); numDistributors > 0 {
+		return limit / float64(numDistributors)
+	}
+	return limit
+}
+
+func (l *policyRateLimiter) limiterFor(now time.Time, tenantID string, policy *validation.StreamLimitPolicy) *rate.Limiter {
+	key := policyKey{tenant: tenantID, policy: policy.Name}
+	limit, burst := rate.Limit(l.Limit(policy)), policy.IngestionBurst.Val()
+
+	lim, ok := l.limiters[key]
+	if !ok {
+		lim = rate.NewLimiter(limit, burst)
+		l.limiters[key] = lim
+		return lim
+	}
+
+	// The limit changes with the number of healthy distributors and with
+	// runtime config reloads, so keep the limiter in sync with it.
+	if lim.Limit() != limit {
+		lim.SetLimitAt(now, limit)
+	}
+	if lim.Burst() != burst {
+		lim.SetBurstAt(now, burst)
+	}
+	return lim
+}
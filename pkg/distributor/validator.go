@@ -46,6 +46,9 @@ type validationContext struct {
 	discoverServiceName          []string
 	discoverLogLevels            bool
 
+	labelCardinalityDemotionThreshold int
+	labelCardinalityDemotionWindow    time.Duration
+
 	allowStructuredMetadata    bool
 	maxStructuredMetadataSize  int
 	maxStructuredMetadataCount int
@@ -55,26 +58,28 @@ type validationContext struct {
 
 func (v Validator) getValidationContextForTime(now time.Time, userID string) validationContext {
 	return validationContext{
-		userID:                       userID,
-		rejectOldSample:              v.RejectOldSamples(userID),
-		rejectOldSampleMaxAge:        now.Add(-v.RejectOldSamplesMaxAge(userID)).UnixNano(),
-		creationGracePeriod:          now.Add(v.CreationGracePeriod(userID)).UnixNano(),
-		maxLineSize:                  v.MaxLineSize(userID),
-		maxLineSizeTruncate:          v.MaxLineSizeTruncate(userID),
-		maxLabelNamesPerSeries:       v.MaxLabelNamesPerSeries(userID),
-		maxLabelNameLength:           v.MaxLabelNameLength(userID),
-		maxLabelValueLength:          v.MaxLabelValueLength(userID),
-		incrementDuplicateTimestamps: v.IncrementDuplicateTimestamps(userID),
-		discoverServiceName:          v.DiscoverServiceName(userID),
-		discoverLogLevels:            v.DiscoverLogLevels(userID),
-		allowStructuredMetadata:      v.AllowStructuredMetadata(userID),
-		maxStructuredMetadataSize:    v.MaxStructuredMetadataSize(userID),
-		maxStructuredMetadataCount:   v.MaxStructuredMetadataCount(userID),
+		userID:                            userID,
+		rejectOldSample:                   v.RejectOldSamples(userID),
+		rejectOldSampleMaxAge:             now.Add(-v.RejectOldSamplesMaxAge(userID)).UnixNano(),
+		creationGracePeriod:               now.Add(v.CreationGracePeriod(userID)).UnixNano(),
+		maxLineSize:                       v.MaxLineSize(userID),
+		maxLineSizeTruncate:               v.MaxLineSizeTruncate(userID),
+		maxLabelNamesPerSeries:            v.MaxLabelNamesPerSeries(userID),
+		maxLabelNameLength:                v.MaxLabelNameLength(userID),
+		maxLabelValueLength:               v.MaxLabelValueLength(userID),
+		incrementDuplicateTimestamps:      v.IncrementDuplicateTimestamps(userID),
+		discoverServiceName:               v.DiscoverServiceName(userID),
+		discoverLogLevels:                 v.DiscoverLogLevels(userID),
+		labelCardinalityDemotionThreshold: v.LabelCardinalityDemotionThreshold(userID),
+		labelCardinalityDemotionWindow:    v.LabelCardinalityDemotionWindow(userID),
+		allowStructuredMetadata:           v.AllowStructuredMetadata(userID),
+		maxStructuredMetadataSize:         v.MaxStructuredMetadataSize(userID),
+		maxStructuredMetadataCount:        v.MaxStructuredMetadataCount(userID),
 	}
 }
 
-// ValidateEntry returns an error if the entry is invalid and report metrics for invalid entries accordingly.
-func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, labels labels.Labels, entry logproto.Entry) error {
+// ValidateEntry returns an error and the discard reason if the entry is invalid and report metrics for invalid entries accordingly.
+func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, labels labels.Labels, entry logproto.Entry) (string, error) {
 	ts := entry.Timestamp.UnixNano()
 	validation.LineLengthHist.Observe(float64(len(entry.Line)))
 
@@ -87,7 +92,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.GreaterThanMaxSampleAge, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
+		return validation.GreaterThanMaxSampleAge, fmt.Errorf(validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
 	}
 
 	if ts > vCtx.creationGracePeriod {
@@ -97,7 +102,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.TooFarInFuture, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
+		return validation.TooFarInFuture, fmt.Errorf(validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
 	}
 
 	if maxSize := vCtx.maxLineSize; maxSize != 0 && len(entry.Line) > maxSize {
@@ -110,7 +115,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.LineTooLong, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
+		return validation.LineTooLong, fmt.Errorf(validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
 	}
 
 	if len(entry.StructuredMetadata) > 0 {
@@ -120,7 +125,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.DisallowedStructuredMetadata, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, labels)
+			return validation.DisallowedStructuredMetadata, fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, labels)
 		}
 
 		var structuredMetadataSizeBytes, structuredMetadataCount int
@@ -135,7 +140,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.StructuredMetadataTooLarge, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, labels, structuredMetadataSizeBytes, vCtx.maxStructuredMetadataSize)
+			return validation.StructuredMetadataTooLarge, fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, labels, structuredMetadataSizeBytes, vCtx.maxStructuredMetadataSize)
 		}
 
 		if maxCount := vCtx.maxStructuredMetadataCount; maxCount != 0 && structuredMetadataCount > maxCount {
@@ -144,11 +149,11 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.StructuredMetadataTooMany, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, labels, structuredMetadataCount, vCtx.maxStructuredMetadataCount)
+			return validation.StructuredMetadataTooMany, fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, labels, structuredMetadataCount, vCtx.maxStructuredMetadataCount)
 		}
 	}
 
-	return nil
+	return "", nil
 }
 
 // Validate labels returns an error if the labels are invalid
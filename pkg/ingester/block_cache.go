@@ -0,0 +1,87 @@
+package ingester
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blockCache is a LRU cache of the decompressed chunk blocks of the streams of an instance,
+// bounded by the size of the blocks. It lets overlapping queries, like the splits of a query
+// or the refreshes of a dashboard, reuse the blocks decompressed by the previous ones.
+type blockCache struct {
+	maxSize int
+	metrics *ingesterMetrics
+
+	mtx     sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[uint64]*list.Element
+}
+
+type blockCacheEntry struct {
+	key uint64
+	b   []byte
+}
+
+func newBlockCache(maxSize int, metrics *ingesterMetrics) *blockCache {
+	return &blockCache{
+		maxSize: maxSize,
+		metrics: metrics,
+		lru:     list.New(),
+		entries: map[uint64]*list.Element{},
+	}
+}
+
+// Get implements chunkenc.BlockCache.
+func (c *blockCache) Get(key uint64) ([]byte, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.metrics.blockCacheMisses.Inc()
+		return nil, false
+	}
+	c.metrics.blockCacheHits.Inc()
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*blockCacheEntry).b, true
+}
+
+// Put implements chunkenc.BlockCache.
+func (c *blockCache) Put(key uint64, b []byte) {
+	if len(b) > c.maxSize {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		// Added by a concurrent query.
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&blockCacheEntry{key: key, b: b})
+	c.add(len(b))
+
+	for c.size > c.maxSize {
+		entry := c.lru.Remove(c.lru.Back()).(*blockCacheEntry)
+		delete(c.entries, entry.key)
+		c.add(-len(entry.b))
+		c.metrics.blockCacheEvictions.Inc()
+	}
+}
+
+// purge removes all the blocks from the cache, once the streams they belong to are gone.
+func (c *blockCache) purge() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.lru.Init()
+	c.entries = map[uint64]*list.Element{}
+	c.add(-c.size)
+}
+
+func (c *blockCache) add(size int) {
+	c.size += size
+	c.metrics.blockCacheBytes.Add(float64(size))
+}
@@ -0,0 +1,83 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+func TestBlockCache(t *testing.T) {
+	metrics := newIngesterMetrics(prometheus.NewRegistry(), constants.Loki)
+	c := newBlockCache(10, metrics)
+
+	c.Put(1, []byte("aaaa"))
+	c.Put(2, []byte("bbbb"))
+	// Too large to be cached.
+	c.Put(3, []byte("ccccccccccc"))
+
+	b, ok := c.Get(1)
+	require.True(t, ok)
+	require.Equal(t, []byte("aaaa"), b)
+	_, ok = c.Get(3)
+	require.False(t, ok)
+
+	// 2 is the least recently used block.
+	c.Put(4, []byte("dddd"))
+	_, ok = c.Get(2)
+	require.False(t, ok)
+	for _, key := range []uint64{1, 4} {
+		_, ok = c.Get(key)
+		require.True(t, ok)
+	}
+
+	require.Equal(t, 3.0, testutil.ToFloat64(metrics.blockCacheHits))
+	require.Equal(t, 2.0, testutil.ToFloat64(metrics.blockCacheMisses))
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.blockCacheEvictions))
+	require.Equal(t, 8.0, testutil.ToFloat64(metrics.blockCacheBytes))
+
+	// The purged blocks are subtracted from the shared gauge.
+	other := newBlockCache(10, metrics)
+	other.Put(5, []byte("eeee"))
+	require.Equal(t, 12.0, testutil.ToFloat64(metrics.blockCacheBytes))
+	c.purge()
+	_, ok = c.Get(1)
+	require.False(t, ok)
+	require.Equal(t, 4.0, testutil.ToFloat64(metrics.blockCacheBytes))
+}
+
+func TestStreamIterator_BlockCache(t *testing.T) {
+	chunkfmt, headfmt := defaultChunkFormat(t)
+	s := stream{blockCache: newBlockCache(1<<20, NilMetrics)}
+	chunk := chunkenc.NewMemChunk(chunkfmt, chunkenc.EncSnappy, headfmt, 1024, 0)
+	for i := int64(0); i < 1000; i++ {
+		require.NoError(t, chunk.Append(&logproto.Entry{Timestamp: time.Unix(i, 0), Line: fmt.Sprintf("line %d", i)}))
+	}
+	require.Greater(t, chunk.BlockCount(), 1)
+	s.chunks = append(s.chunks, chunkDesc{chunk: chunk})
+
+	query := func(from, through int64) int64 {
+		statsCtx, ctx := stats.NewContext(context.Background())
+		it, err := s.Iterator(ctx, statsCtx, time.Unix(from, 0), time.Unix(through, 0), logproto.FORWARD, log.NewNoopPipeline().ForStream(s.labels))
+		require.NoError(t, err)
+		testIteratorForward(t, it, from, through)
+		require.NoError(t, it.Close())
+		return statsCtx.Ingester().TotalBlockCacheHits
+	}
+
+	require.Zero(t, query(0, 500))
+	// The blocks of the overlapping range are read from the cache.
+	require.Greater(t, query(250, 750), int64(0))
+	query(0, 1000)
+	require.Equal(t, int64(chunk.BlockCount()), query(0, 1000))
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -348,7 +349,7 @@ func (w *WALCheckpointWriter) Advance() (bool, error) {
 		return false, errors.Wrap(err, "create checkpoint dir")
 	}
 
-	checkpoint, err := wlog.NewSize(log.With(util_log.Logger, "component", "checkpoint_wal"), nil, checkpointDirTemp, walSegmentSize, wlog.CompressionNone)
+	checkpoint, err := wlog.NewSize(log.With(util_log.Logger, "component", "checkpoint_wal"), nil, checkpointDirTemp, walSegmentSize, w.segmentWAL.CompressionType())
 	if err != nil {
 		return false, errors.Wrap(err, "open checkpoint")
 	}
@@ -523,6 +524,8 @@ type Checkpointer struct {
 	metrics *ingesterMetrics
 
 	quit <-chan struct{}
+	// Prevents the periodic checkpoints and the ones requested with CheckpointNow from overlapping.
+	mtx sync.Mutex
 }
 
 func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter, metrics *ingesterMetrics, quit <-chan struct{}) *Checkpointer {
@@ -536,6 +539,19 @@ func NewCheckpointer(dur time.Duration, iter SeriesIter, writer CheckpointWriter
 }
 
 func (c *Checkpointer) PerformCheckpoint() (err error) {
+	return c.performCheckpoint(true)
+}
+
+// CheckpointNow performs a checkpoint without spreading the writes of the series over the
+// checkpoint duration.
+func (c *Checkpointer) CheckpointNow() error {
+	return c.performCheckpoint(false)
+}
+
+func (c *Checkpointer) performCheckpoint(paced bool) (err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
 	noop, err := c.writer.Advance()
 	if err != nil {
 		return err
@@ -557,12 +573,16 @@ func (c *Checkpointer) PerformCheckpoint() (err error) {
 		return c.writer.Close(false)
 	}
 
-	// Give a 10% buffer to the checkpoint duration in order to account for
-	// new series, slow writes, etc.
-	perSeriesDuration := (90 * c.dur) / (100 * time.Duration(n))
+	var tick <-chan time.Time
+	if paced {
+		// Give a 10% buffer to the checkpoint duration in order to account for
+		// new series, slow writes, etc.
+		perSeriesDuration := (90 * c.dur) / (100 * time.Duration(n))
 
-	ticker := time.NewTicker(perSeriesDuration)
-	defer ticker.Stop()
+		ticker := time.NewTicker(perSeriesDuration)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 	start := time.Now()
 	defer func() {
 		elapsed := time.Since(start)
@@ -576,6 +596,9 @@ func (c *Checkpointer) PerformCheckpoint() (err error) {
 			return err
 		}
 
+		if !paced {
+			continue
+		}
 		if !immediate {
 			if time.Since(start) > c.dur {
 				// This indicates the checkpoint is taking too long; stop waiting
@@ -588,7 +611,7 @@ func (c *Checkpointer) PerformCheckpoint() (err error) {
 		select {
 		case <-c.quit:
 			return c.writer.Close(true)
-		case <-ticker.C:
+		case <-tick:
 		}
 
 	}
@@ -35,6 +35,7 @@ type ClosableHealthAndIngesterClient struct {
 	logproto.PusherClient
 	logproto.QuerierClient
 	logproto.StreamDataClient
+	logproto.IngesterClient
 	grpc_health_v1.HealthClient
 	io.Closer
 }
@@ -82,6 +83,7 @@ func New(cfg Config, addr string) (HealthAndIngesterClient, error) {
 		PusherClient:     logproto.NewPusherClient(conn),
 		QuerierClient:    logproto.NewQuerierClient(conn),
 		StreamDataClient: logproto.NewStreamDataClient(conn),
+		IngesterClient:   logproto.NewIngesterClient(conn),
 		HealthClient:     grpc_health_v1.NewHealthClient(conn),
 		Closer:           conn,
 	}, nil
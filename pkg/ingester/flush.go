@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log/level"
-	"github.com/grafana/dskit/ring"
 	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -18,6 +18,8 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/util"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
@@ -36,6 +38,10 @@ const (
 	flushReasonForced = "forced"
 	flushReasonFull   = "full"
 	flushReasonSynced = "synced"
+
+	// maxIndexedStructuredMetadataValues is the maximum number of values of a structured metadata key
+	// recorded in the index for a chunk.
+	maxIndexedStructuredMetadataValues = 64
 )
 
 // Note: this is called both during the WAL replay (zero or more times)
@@ -55,13 +61,6 @@ func (i *Ingester) Flush() {
 	i.flush(true)
 }
 
-// TransferOut implements ring.FlushTransferer
-// Noop implemenetation because ingesters have a WAL now that does not require transferring chunks any more.
-// We return ErrTransferDisabled to indicate that we don't support transfers, and therefore we may flush on shutdown if configured to do so.
-func (i *Ingester) TransferOut(_ context.Context) error {
-	return ring.ErrTransferDisabled
-}
-
 func (i *Ingester) flush(mayRemoveStreams bool) {
 	i.sweepUsers(true, mayRemoveStreams)
 
@@ -294,6 +293,7 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelP
 
 	sizePerTenant := i.metrics.chunkSizePerTenant.WithLabelValues(userID)
 	countPerTenant := i.metrics.chunksPerTenant.WithLabelValues(userID)
+	indexedKeys := i.limiter.limits.IndexStructuredMetadataKeys(userID)
 
 	for j, c := range cs {
 		if err := i.closeChunk(c, chunkMtx); err != nil {
@@ -307,6 +307,13 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelP
 			firstTime,
 			lastTime,
 		)
+		ch.StorageClass = c.storageClass
+		if len(indexedKeys) > 0 {
+			ch.IndexedStructuredMetadata, err = indexedStructuredMetadata(ctx, c.chunk, labelPairs, indexedKeys)
+			if err != nil {
+				return fmt.Errorf("collecting the indexed structured metadata: %w", err)
+			}
+		}
 
 		// encodeChunk mutates the chunk so we must pass by reference
 		if err := i.encodeChunk(ctx, &ch, c); err != nil {
@@ -331,6 +338,56 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelP
 	return nil
 }
 
+// indexedStructuredMetadata returns the distinct values of the given structured metadata keys in the chunk,
+// recorded in the index to prune the chunks of the queries filtering on them.
+//
+// The keys of the stream labels are skipped, since the structured metadata conflicting with them is renamed
+// at query time, and so are the keys with more than maxIndexedStructuredMetadataValues values in the chunk.
+func indexedStructuredMetadata(ctx context.Context, c chunkenc.Chunk, ls labels.Labels, keys []string) (map[string][]string, error) {
+	values := make(map[string]map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if !ls.Has(key) {
+			values[key] = map[string]struct{}{}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	from, through := c.Bounds()
+	it, err := c.Iterator(ctx, from, through.Add(time.Nanosecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(ls))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		for _, l := range it.Entry().StructuredMetadata {
+			vs, ok := values[l.Name]
+			if !ok {
+				continue
+			}
+			vs[l.Value] = struct{}{}
+			if len(vs) > maxIndexedStructuredMetadataValues {
+				delete(values, l.Name)
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]string, len(values))
+	for key, vs := range values {
+		res[key] = make([]string, 0, len(vs))
+		for v := range vs {
+			res[key] = append(res[key], v)
+		}
+		sort.Strings(res[key])
+	}
+	return res, nil
+}
+
 // markChunkAsFlushed mark a chunk to make sure it won't be flushed if this operation fails.
 func (i *Ingester) markChunkAsFlushed(desc *chunkDesc, chunkMtx sync.Locker) {
 	chunkMtx.Lock()
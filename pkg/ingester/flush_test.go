@@ -70,6 +70,7 @@ func TestChunkFlushingShutdown(t *testing.T) {
 type fullWAL struct{}
 
 func (fullWAL) Log(_ *wal.Record) error { return &os.PathError{Err: syscall.ENOSPC} }
+func (fullWAL) Checkpoint() error       { return &os.PathError{Err: syscall.ENOSPC} }
 func (fullWAL) Start()                  {}
 func (fullWAL) Stop() error             { return nil }
 
@@ -121,6 +122,53 @@ func Test_Flush(t *testing.T) {
 	require.NoError(t, ing.flushChunks(ctx, 0, lbs, buildChunkDecs(t), &sync.RWMutex{}))
 }
 
+func Test_FlushStorageClass(t *testing.T) {
+	var (
+		store, ing = newTestStore(t, defaultIngesterTestConfig(t), nil)
+		lbs        = makeRandomLabels()
+		ctx        = user.InjectOrgID(context.Background(), "foo")
+		flushed    []chunk.Chunk
+	)
+	store.onPut = func(_ context.Context, chunks []chunk.Chunk) error {
+		flushed = append(flushed, chunks...)
+		return nil
+	}
+	descs := buildChunkDecs(t)
+	for _, desc := range descs {
+		desc.storageClass = "cold"
+	}
+	require.NoError(t, ing.flushChunks(ctx, 0, lbs, descs, &sync.RWMutex{}))
+
+	require.Len(t, flushed, len(descs))
+	for _, c := range flushed {
+		require.Equal(t, "cold", c.StorageClass)
+	}
+}
+
+func Test_indexedStructuredMetadata(t *testing.T) {
+	c := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, dummyConf().BlockSize, dummyConf().TargetChunkSize)
+	for i := 0; i <= maxIndexedStructuredMetadataValues; i++ {
+		require.NoError(t, c.Append(&logproto.Entry{
+			Timestamp: time.Unix(0, int64(i)),
+			Line:      fmt.Sprintf("line %d", i),
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(labels.FromStrings(
+				"level", []string{"info", "error"}[i%2],
+				"trace_id", fmt.Sprint(i),
+				"app", "foo",
+			)),
+		}))
+	}
+	require.NoError(t, c.Close())
+
+	values, err := indexedStructuredMetadata(context.Background(), c, labels.FromStrings("app", "foo"), []string{"level", "trace_id", "app", "service_version"})
+	require.NoError(t, err)
+	// the trace ids exceed the values limit, and app is a stream label.
+	require.Equal(t, map[string][]string{
+		"level":           {"error", "info"},
+		"service_version": {},
+	}, values)
+}
+
 func buildChunkDecs(t testing.TB) []*chunkDesc {
 	res := make([]*chunkDesc, 10)
 	for i := range res {
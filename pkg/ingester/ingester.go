@@ -38,6 +38,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/ingester/client"
 	"github.com/grafana/loki/v3/pkg/ingester/index"
 	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/kafka"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
@@ -52,8 +53,8 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	index_stats "github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/v3/pkg/util"
+	"github.com/grafana/loki/v3/pkg/util/flagext"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
-	"github.com/grafana/loki/v3/pkg/util/wal"
 )
 
 const (
@@ -96,8 +97,20 @@ type Config struct {
 
 	MaxReturnedErrors int `yaml:"max_returned_stream_errors"`
 
+	BlockCacheSize flagext.ByteSize `yaml:"block_cache_size" category:"experimental"`
+
+	BucketedHeadBlock bool `yaml:"bucketed_head_block" category:"experimental"`
+
+	MaxTransferRetries int `yaml:"max_transfer_retries" category:"experimental"`
+
+	MetricStreams MetricStreamsConfig `yaml:"metric_streams" category:"experimental" doc:"description=Configures the metrics aggregated from the pushed log lines, following the 'metric_streams' limits of the tenants."`
+
 	// For testing, you can override the address and ID of this ingester.
 	ingesterClientFactory func(cfg client.Config, addr string) (client.HealthAndIngesterClient, error)
+	kafkaClient           kafka.Client
+
+	// KafkaConfig is set from the top level kafka_config block.
+	KafkaConfig kafka.Config `yaml:"-"`
 
 	QueryStore                  bool          `yaml:"-"`
 	QueryStoreMaxLookBackPeriod time.Duration `yaml:"query_store_max_look_back_period"`
@@ -122,6 +135,7 @@ type Config struct {
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.LifecyclerConfig.RegisterFlags(f, util_log.Logger)
 	cfg.WAL.RegisterFlags(f)
+	cfg.MetricStreams.RegisterFlags(f)
 
 	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "How many flushes can happen concurrently from each stream.")
 	f.DurationVar(&cfg.FlushCheckPeriod, "ingester.flush-check-period", 30*time.Second, "How often should the ingester see if there are any blocks to flush. The first flush check is delayed by a random time up to 0.8x the flush check period. Additionally, there is +/- 1% jitter added to the interval.")
@@ -134,6 +148,9 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.DurationVar(&cfg.SyncPeriod, "ingester.sync-period", 1*time.Hour, "Parameters used to synchronize ingesters to cut chunks at the same moment. Sync period is used to roll over incoming entry to a new chunk. If chunk's utilization isn't high enough (eg. less than 50% when sync_min_utilization is set to 0.5), then this chunk rollover doesn't happen.")
 	f.Float64Var(&cfg.SyncMinUtilization, "ingester.sync-min-utilization", 0.1, "Minimum utilization of chunk when doing synchronization.")
 	f.IntVar(&cfg.MaxReturnedErrors, "ingester.max-ignored-stream-errors", 10, "The maximum number of errors a stream will report to the user when a push fails. 0 to make unlimited.")
+	f.Var(&cfg.BlockCacheSize, "ingester.block-cache-size", "Experimental: Maximum size of the decompressed chunk blocks cached per tenant, so that queries of overlapping time ranges reuse them instead of decompressing the blocks again. 0 disables the cache.")
+	f.BoolVar(&cfg.BucketedHeadBlock, "ingester.bucketed-head-block", false, "Experimental: Bucket the entries of the head blocks of the chunks by fixed time windows instead of indexing them in a range tree, and cut the chunk blocks at the window boundaries. This speeds up the pushes and the queries of the streams with a lot of out-of-order entries. Only applies to the chunks of the schema v14 periods and above, the head blocks of the chunks replayed from the WAL are converted.")
+	f.IntVar(&cfg.MaxTransferRetries, "ingester.max-transfer-retries", 0, "Experimental: Number of times a leaving ingester tries to hand off its in-memory streams to a pending ingester, which takes over its tokens, before falling back to flushing them. The pending ingesters must wait for the handoff with 'join_after'. 0 disables the handoff.")
 	f.DurationVar(&cfg.MaxChunkAge, "ingester.max-chunk-age", 2*time.Hour, "The maximum duration of a timeseries chunk in memory. If a timeseries runs for longer than this, the current chunk will be flushed to the store and a new chunk created.")
 	f.DurationVar(&cfg.QueryStoreMaxLookBackPeriod, "ingester.query-store-max-look-back-period", 0, "How far back should an ingester be allowed to query the store for data, for use only with boltdb-shipper/tsdb index and filesystem object store. -1 for infinite.")
 	f.BoolVar(&cfg.AutoForgetUnhealthy, "ingester.autoforget-unhealthy", false, "Forget about ingesters having heartbeat timestamps older than `ring.kvstore.heartbeat_timeout`. This is equivalent to clicking on the `/ring` `forget` button in the UI: the ingester is removed from the ring. This is a useful setting when you are sure that an unhealthy node won't return. An example is when not using stateful sets or the equivalent. Use `memberlist.rejoin_interval` > 0 to handle network partition cases when using a memberlist.")
@@ -157,6 +174,10 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid ingester index shard factor: %d", cfg.IndexShards)
 	}
 
+	if err = cfg.MetricStreams.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -180,12 +201,14 @@ type Interface interface {
 	logproto.PusherServer
 	logproto.QuerierServer
 	logproto.StreamDataServer
+	logproto.IngesterServer
 
 	CheckReady(ctx context.Context) error
 	FlushHandler(w http.ResponseWriter, _ *http.Request)
 	GetOrCreateInstance(instanceID string) (*instance, error)
 	ShutdownHandler(w http.ResponseWriter, r *http.Request)
 	PrepareShutdown(w http.ResponseWriter, r *http.Request)
+	WALStatusHandler(w http.ResponseWriter, _ *http.Request)
 }
 
 // Ingester builds chunks for incoming log streams.
@@ -230,6 +253,8 @@ type Ingester struct {
 
 	// Only used by WAL & flusher to coordinate backpressure during replay.
 	replayController *replayController
+	// Progress of the WAL replay shown by the WAL status endpoint.
+	walStatus *walStatus
 
 	metrics *ingesterMetrics
 
@@ -242,6 +267,13 @@ type Ingester struct {
 	streamRateCalculator *StreamRateCalculator
 
 	writeLogManager *writefailures.Manager
+
+	// Only set when the metric streams are enabled.
+	metricStreamsWriter *metricStreamsWriter
+
+	// Only set when the Kafka write path is enabled.
+	kafkaClient     kafka.Client
+	partitionReader *partitionReader
 }
 
 // New makes a new Ingester.
@@ -275,6 +307,10 @@ func New(cfg Config, clientConfig client.Config, store Store, limits Limits, con
 		writeLogManager:       writefailures.NewManager(logger, registerer, writeFailuresCfg, configs, "ingester"),
 	}
 	i.replayController = newReplayController(metrics, cfg.WAL, &replayFlusher{i})
+	i.walStatus = newWALStatus()
+	if cfg.MetricStreams.Enabled {
+		i.metricStreamsWriter = newMetricStreamsWriter(cfg.MetricStreams, metrics, logger)
+	}
 
 	if cfg.WAL.Enabled {
 		if err := os.MkdirAll(cfg.WAL.Dir, os.ModePerm); err != nil {
@@ -294,6 +330,21 @@ func New(cfg Config, clientConfig client.Config, store Store, limits Limits, con
 	}
 	i.wal = wal
 
+	if cfg.KafkaConfig.Enabled {
+		partition, err := kafka.PartitionFromID(cfg.LifecyclerConfig.ID)
+		if err != nil {
+			return nil, err
+		}
+		i.kafkaClient = cfg.kafkaClient
+		if i.kafkaClient == nil {
+			i.kafkaClient, err = kafka.NewClient(cfg.KafkaConfig)
+			if err != nil {
+				return nil, err
+			}
+		}
+		i.partitionReader = newPartitionReader(cfg.KafkaConfig, partition, i.kafkaClient, i, metrics, logger)
+	}
+
 	i.lifecycler, err = ring.NewLifecycler(cfg.LifecyclerConfig, i, "ingester", RingKey, !cfg.WAL.Enabled || cfg.WAL.FlushOnShutdown, logger, prometheus.WrapRegistererWithPrefix(metricsNamespace+"_", registerer))
 	if err != nil {
 		return nil, err
@@ -423,6 +474,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 		recoverer := newIngesterRecoverer(i)
 
 		i.metrics.walReplayActive.Set(1)
+		i.walStatus.startReplay()
 
 		endReplay := func() func() {
 			var once sync.Once
@@ -434,6 +486,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 					elapsed := time.Since(start)
 
 					i.metrics.walReplayActive.Set(0)
+					i.walStatus.finishReplay()
 					i.metrics.walReplayDuration.Set(elapsed.Seconds())
 					i.cfg.RetainPeriod = oldRetain
 					level.Info(i.logger).Log("msg", "WAL recovery finished", "time", elapsed.String())
@@ -443,7 +496,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 		defer endReplay()
 
 		level.Info(i.logger).Log("msg", "recovering from checkpoint")
-		checkpointReader, checkpointCloser, err := newCheckpointReader(i.cfg.WAL.Dir, i.logger)
+		checkpointReader, checkpointCloser, err := newCheckpointReader(i.cfg.WAL.Dir, i.metrics, i.walStatus, i.logger)
 		if err != nil {
 			return err
 		}
@@ -465,11 +518,11 @@ func (i *Ingester) starting(ctx context.Context) error {
 		)
 
 		level.Info(i.logger).Log("msg", "recovering from WAL")
-		segmentReader, segmentCloser, err := wal.NewWalReader(i.cfg.WAL.Dir, -1)
+		segmentReader, err := newSegmentsReader(i.cfg.WAL.Dir, walTypeSegment, i.metrics, i.walStatus, i.logger)
 		if err != nil {
 			return err
 		}
-		defer segmentCloser.Close()
+		defer segmentReader.Close()
 
 		segmentRecoveryErr := RecoverWAL(ctx, segmentReader, recoverer)
 		if segmentRecoveryErr != nil {
@@ -515,9 +568,21 @@ func (i *Ingester) starting(ctx context.Context) error {
 		i.setPrepareShutdown()
 	}
 
+	if i.partitionReader != nil {
+		if err := services.StartAndAwaitRunning(ctx, i.partitionReader); err != nil {
+			return errors.Wrap(err, "failed to start kafka partition reader")
+		}
+		i.lifecyclerWatcher.WatchService(i.partitionReader)
+	}
+
 	// start our loop
 	i.loopDone.Add(1)
 	go i.loop()
+
+	if i.metricStreamsWriter != nil {
+		i.loopDone.Add(1)
+		go i.metricStreamsLoop()
+	}
 	return nil
 }
 
@@ -546,8 +611,11 @@ func (i *Ingester) running(ctx context.Context) error {
 //
 // At this point, loop no longer runs, but flushers are still running.
 func (i *Ingester) stopping(_ error) error {
-	i.stopIncomingRequests()
 	var errs util.MultiError
+	if i.partitionReader != nil {
+		errs.Add(services.StopAndAwaitTerminated(context.Background(), i.partitionReader))
+	}
+	i.stopIncomingRequests()
 	errs.Add(i.wal.Stop())
 
 	if i.flushOnShutdownSwitch.Get() {
@@ -560,6 +628,19 @@ func (i *Ingester) stopping(_ error) error {
 	}
 	i.flushQueuesDone.Wait()
 
+	for _, instance := range i.getInstances() {
+		if instance.blockCache != nil {
+			instance.blockCache.purge()
+		}
+	}
+
+	if i.partitionReader != nil {
+		// Chunks have been flushed on shutdown, if enabled, so commit the
+		// offset one last time to avoid consuming them again on restart.
+		i.partitionReader.commit()
+		errs.Add(i.kafkaClient.Close())
+	}
+
 	i.streamRateCalculator.Stop()
 
 	// In case the flag to terminate on shutdown is set or this instance is marked to release its resources,
@@ -833,7 +914,18 @@ func (i *Ingester) Push(ctx context.Context, req *logproto.PushRequest) (*logpro
 	if err != nil {
 		return &logproto.PushResponse{}, err
 	}
-	return &logproto.PushResponse{}, instance.Push(ctx, req)
+
+	if !acceptsPartialSuccess(ctx) {
+		return &logproto.PushResponse{}, instance.Push(ctx, req)
+	}
+
+	results, err := instance.push(ctx, req)
+	if len(results) == 0 {
+		return &logproto.PushResponse{}, err
+	}
+	// The error is the one of the last rejected stream, which is reported in
+	// the partial success instead.
+	return &logproto.PushResponse{PartialSuccess: newPushPartialSuccess(results)}, nil
 }
 
 // GetStreamRates returns a response containing all streams and their current rate
@@ -1365,15 +1457,26 @@ func adjustQueryStartTime(maxLookBackPeriod time.Duration, start, now time.Time)
 	return start
 }
 
-func (i *Ingester) GetDetectedFields(_ context.Context, r *logproto.DetectedFieldsRequest) (*logproto.DetectedFieldsResponse, error) {
+// GetDetectedFields returns the fields detected in the most recent entries of
+// the in-memory streams matching the request.
+func (i *Ingester) GetDetectedFields(ctx context.Context, r *logproto.DetectedFieldsRequest) (*logproto.DetectedFieldsResponse, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := i.GetOrCreateInstance(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := instance.GetDetectedFields(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &logproto.DetectedFieldsResponse{
-		Fields: []*logproto.DetectedField{
-			{
-				Label:       "foo",
-				Type:        logproto.DetectedFieldString,
-				Cardinality: 1,
-			},
-		},
+		Fields:     fields,
 		FieldLimit: r.GetFieldLimit(),
 	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"sort"
 	"sync"
 	"syscall"
 	"time"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/util/httpreq"
 
+	"github.com/axiomhq/hyperloglog"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/opentracing/opentracing-go"
@@ -40,9 +42,11 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/runtime"
 	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/detected"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/constants"
@@ -125,6 +129,14 @@ type instance struct {
 	schemaconfig *config.SchemaConfig
 
 	customStreamsTracker push.UsageTracker
+
+	policyStreams *policyStreamsTracker
+
+	// decompressed chunk blocks of the streams, nil when disabled.
+	blockCache *blockCache
+
+	// series of the metric streams of the tenant, nil when disabled.
+	metricStreams *metricStreams
 }
 
 func newInstance(
@@ -174,6 +186,14 @@ func newInstance(
 
 		writeFailures: writeFailures,
 		schemaconfig:  &c,
+
+		policyStreams: newPolicyStreamsTracker(),
+	}
+	if cfg.BlockCacheSize > 0 {
+		i.blockCache = newBlockCache(cfg.BlockCacheSize.Val(), metrics)
+	}
+	if cfg.MetricStreams.Enabled {
+		i.metricStreams = newMetricStreams(instanceID, cfg.LifecyclerConfig.ID, limiter.limits, cfg.MetricStreams.MaxSeries, metrics)
 	}
 	i.mapper = NewFPMapper(i.getLabelsFromFingerprint)
 	return i, err
@@ -214,17 +234,28 @@ func (i *instance) consumeChunk(ctx context.Context, ls labels.Labels, chunk *lo
 // happened to *the last stream in the request*. Ex: if three streams are part of the PushRequest
 // and all three failed, the returned error only describes what happened to the last processed stream.
 func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
+	_, err := i.push(ctx, req)
+	return err
+}
+
+// push is like Push but also returns the results of the streams with rejected
+// entries. The returned error is the last one seen.
+func (i *instance) push(ctx context.Context, req *logproto.PushRequest) ([]logproto.StreamPushResult, error) {
 	record := recordPool.GetRecord()
 	record.UserID = i.instanceID
 	defer recordPool.PutRecord(record)
 	rateLimitWholeStream := i.limiter.limits.ShardStreams(i.instanceID).Enabled
 
 	var appendErr error
-	for _, reqStream := range req.Streams {
+	var results []logproto.StreamPushResult
+	for idx, reqStream := range req.Streams {
 
+		var reason string
 		s, _, err := i.streams.LoadOrStoreNew(reqStream.Labels,
 			func() (*stream, error) {
-				s, err := i.createStream(ctx, reqStream, record)
+				var s *stream
+				var err error
+				s, reason, err = i.tryCreateStream(ctx, reqStream, record)
 				// Lock before adding to maps
 				if err == nil {
 					s.chunkMtx.Lock()
@@ -238,10 +269,15 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 		)
 		if err != nil {
 			appendErr = err
+			results = append(results, newStreamPushResult(idx, reqStream, nil, reason, err))
 			continue
 		}
 
-		_, appendErr = s.Push(ctx, reqStream.Entries, record, 0, false, rateLimitWholeStream)
+		var failed []entryWithError
+		_, failed, appendErr = s.push(ctx, reqStream.Entries, record, 0, false, rateLimitWholeStream)
+		if appendErr != nil {
+			results = append(results, newStreamPushResult(idx, reqStream, rejectedOffsets(reqStream.Entries, failed), s.discardReason(failed), appendErr))
+		}
 		s.chunkMtx.Unlock()
 	}
 
@@ -256,15 +292,22 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 					)
 				})
 			} else {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	return appendErr
+	return results, appendErr
 }
 
 func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stream, record *wal.Record) (*stream, error) {
+	s, _, err := i.tryCreateStream(ctx, pushReqStream, record)
+	return s, err
+}
+
+// tryCreateStream is like createStream but also returns the discard reason of
+// the stream when it is rejected.
+func (i *instance) tryCreateStream(ctx context.Context, pushReqStream logproto.Stream, record *wal.Record) (*stream, string, error) {
 	// record is only nil when replaying WAL. We don't want to drop data when replaying a WAL after
 	// reducing the stream limits, for instance.
 	var err error
@@ -279,7 +322,7 @@ func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stre
 				"stream", pushReqStream.Labels,
 			)
 		}
-		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		return nil, validation.InvalidLabels, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
 
 	if record != nil {
@@ -305,7 +348,33 @@ func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stre
 		if i.customStreamsTracker != nil {
 			i.customStreamsTracker.DiscardedBytesAdd(ctx, i.instanceID, validation.StreamLimit, labels, float64(bytes))
 		}
-		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, validation.StreamLimitErrorMsg, labels, i.instanceID)
+		return nil, validation.StreamLimit, httpgrpc.Errorf(http.StatusTooManyRequests, validation.StreamLimitErrorMsg, labels, i.instanceID)
+	}
+
+	policies := streamLimitPolicies(i.limiter.limits, i.instanceID, labels)
+	if policy, reason, err := i.policyStreams.tryAdd(i.instanceID, labels, policies, i.limiter, record != nil); err != nil {
+		if i.configs.LogStreamCreation(i.instanceID) {
+			level.Debug(util_log.Logger).Log(
+				"msg", "failed to create stream, exceeded policy limit",
+				"org_id", i.instanceID,
+				"policy", policy.Name,
+				"err", err,
+				"stream", pushReqStream.Labels,
+			)
+		}
+
+		bytes := 0
+		for _, e := range pushReqStream.Entries {
+			bytes += len(e.Line)
+		}
+		validation.DiscardedSamples.WithLabelValues(reason, i.instanceID).Add(float64(len(pushReqStream.Entries)))
+		validation.DiscardedBytes.WithLabelValues(reason, i.instanceID).Add(float64(bytes))
+		validation.PolicyDiscardedSamples.WithLabelValues(reason, i.instanceID, policy.Name).Add(float64(len(pushReqStream.Entries)))
+		validation.PolicyDiscardedBytes.WithLabelValues(reason, i.instanceID, policy.Name).Add(float64(bytes))
+		if i.customStreamsTracker != nil {
+			i.customStreamsTracker.DiscardedBytesAdd(ctx, i.instanceID, reason, labels, float64(bytes))
+		}
+		return nil, reason, httpgrpc.Errorf(http.StatusTooManyRequests, "%s", err.Error())
 	}
 
 	fp := i.getHashForLabels(labels)
@@ -314,10 +383,15 @@ func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stre
 
 	chunkfmt, headfmt, err := i.chunkFormatAt(minTs(&pushReqStream))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stream: %w", err)
+		i.policyStreams.remove(labels, policyNames(policies))
+		return nil, "", fmt.Errorf("failed to create stream: %w", err)
 	}
 
 	s := newStream(chunkfmt, headfmt, i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
+	s.limitPolicies = policyNames(policies)
+	s.blockCache = i.blockCache
+	i.setStorageClass(s)
+	s.metricStreams = i.metricStreams
 
 	// record will be nil when replaying the wal (we don't want to rewrite wal entries as we replay them).
 	if record != nil {
@@ -344,7 +418,7 @@ func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stre
 		)
 	}
 
-	return s, nil
+	return s, "", nil
 }
 
 func (i *instance) createStreamByFP(ls labels.Labels, fp model.Fingerprint) (*stream, error) {
@@ -357,6 +431,13 @@ func (i *instance) createStreamByFP(ls labels.Labels, fp model.Fingerprint) (*st
 
 	s := newStream(chunkfmt, headfmt, i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics, i.writeFailures)
 
+	policies := streamLimitPolicies(i.limiter.limits, i.instanceID, ls)
+	_, _, _ = i.policyStreams.tryAdd(i.instanceID, ls, policies, i.limiter, false)
+	s.limitPolicies = policyNames(policies)
+	s.blockCache = i.blockCache
+	i.setStorageClass(s)
+	s.metricStreams = i.metricStreams
+
 	i.streamsCreatedTotal.Inc()
 	memoryStreams.WithLabelValues(i.instanceID).Inc()
 	memoryStreamsLabelsBytes.Add(float64(len(s.labels.String())))
@@ -365,6 +446,28 @@ func (i *instance) createStreamByFP(ls labels.Labels, fp model.Fingerprint) (*st
 	return s, nil
 }
 
+// setStorageClass sets the storage class and the chunk encoding of the given
+// stream from the highest priority storage class rule matching its labels.
+func (i *instance) setStorageClass(s *stream) {
+	var rule *validation.StorageClassRule
+	rules := i.limiter.limits.StorageClassStream(i.instanceID)
+	for j := range rules {
+		if rules[j].Matches(s.labels) && (rule == nil || rules[j].Priority > rule.Priority) {
+			rule = &rules[j]
+		}
+	}
+	if rule == nil {
+		return
+	}
+	s.storageClass = rule.StorageClass
+	if rule.ChunkEncoding != "" {
+		// The encoding is checked when the limits are validated.
+		if enc, err := chunkenc.ParseEncoding(rule.ChunkEncoding); err == nil {
+			s.chunkEncoding = enc
+		}
+	}
+}
+
 // chunkFormatAt returns chunk formats to use at given period of time.
 func (i *instance) chunkFormatAt(at model.Time) (byte, chunkenc.HeadBlockFmt, error) {
 	// NOTE: We choose chunk formats for stream based on it's entries timestamp.
@@ -382,6 +485,10 @@ func (i *instance) chunkFormatAt(at model.Time) (byte, chunkenc.HeadBlockFmt, er
 		return 0, 0, err
 	}
 
+	if i.cfg.BucketedHeadBlock && headblock == chunkenc.UnorderedWithStructuredMetadataColumnsHeadBlockFmt {
+		headblock = chunkenc.BucketedHeadBlockFmt
+	}
+
 	return chunkFormat, headblock, nil
 }
 
@@ -400,10 +507,14 @@ func (i *instance) getOrCreateStream(ctx context.Context, pushReqStream logproto
 func (i *instance) removeStream(s *stream) {
 	if i.streams.Delete(s) {
 		i.index.Delete(s.labels, s.fp)
+		i.policyStreams.remove(s.labels, s.limitPolicies)
 		i.streamsRemovedTotal.Inc()
 		memoryStreams.WithLabelValues(i.instanceID).Dec()
 		memoryStreamsLabelsBytes.Sub(float64(len(s.labels.String())))
 		streamsCountStats.Add(-1)
+		if i.blockCache != nil && i.streams.Len() == 0 {
+			i.blockCache.purge()
+		}
 	}
 }
 
@@ -610,6 +721,75 @@ func (i *instance) LabelsWithValues(ctx context.Context, startTime time.Time, ma
 	return labelMap, nil
 }
 
+// GetDetectedFields parses the most recent entries of the streams matching the
+// request, up to the line limit, and returns the fields found along with a
+// sketch of their values, to be merged with the fields detected elsewhere.
+func (i *instance) GetDetectedFields(ctx context.Context, req *logproto.DetectedFieldsRequest) ([]*logproto.DetectedField, error) {
+	expr, err := syntax.ParseLogSelector(req.Query, true)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := i.Query(ctx, logql.SelectLogParams{
+		QueryRequest: &logproto.QueryRequest{
+			Selector:  expr.String(),
+			Start:     req.Start,
+			End:       req.End,
+			Limit:     req.LineLimit,
+			Direction: logproto.BACKWARD,
+			Plan: &plan.QueryPlan{
+				AST: expr,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer util.LogErrorWithContext(ctx, "closing iterator", it.Close)
+
+	fields := make(map[string]*detected.UnmarshaledDetectedField, req.FieldLimit)
+	for lines := uint32(0); lines < req.LineLimit && it.Next(); lines++ {
+		for name, values := range detected.ParseLine(it.Entry().Line) {
+			field, ok := fields[name]
+			if !ok {
+				if uint32(len(fields)) >= req.FieldLimit {
+					continue
+				}
+				field = &detected.UnmarshaledDetectedField{
+					Label:  name,
+					Type:   detected.DetermineType(values[0]),
+					Sketch: hyperloglog.New(),
+				}
+				fields[name] = field
+			}
+			for _, v := range values {
+				field.Sketch.Insert([]byte(v))
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*logproto.DetectedField, 0, len(fields))
+	for _, field := range fields {
+		sketch, err := field.Sketch.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &logproto.DetectedField{
+			Label:       field.Label,
+			Type:        field.Type,
+			Cardinality: field.Sketch.Estimate(),
+			Sketch:      sketch,
+		})
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Label < result[b].Label
+	})
+	return result, nil
+}
+
 func (i *instance) Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
 	groups, err := logql.MatchForSeriesRequest(req.GetGroups())
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/chunkenc"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
@@ -33,6 +34,7 @@ import (
 	loki_runtime "github.com/grafana/loki/v3/pkg/runtime"
 	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/detected"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/v3/pkg/util/constants"
 	"github.com/grafana/loki/v3/pkg/validation"
@@ -640,6 +642,61 @@ func Test_Iterator(t *testing.T) {
 	require.Equal(t, int64(8*1e6), res.Streams[1].Entries[0].Timestamp.UnixNano())
 }
 
+func TestInstance_GetDetectedFields(t *testing.T) {
+	instance := defaultInstance(t)
+
+	require.NoError(t, instance.Push(context.TODO(), &logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{
+				Labels: `{job="app"}`,
+				Entries: []logproto.Entry{
+					{Timestamp: time.Unix(1, 0), Line: `old=true`},
+					{Timestamp: time.Unix(2, 0), Line: `user=alice status=200 ratio=0.5 ok=true took=10ms size=10KB`},
+					{Timestamp: time.Unix(3, 0), Line: `{"user":"bob","status":404}`},
+				},
+			},
+		},
+	}))
+
+	detect := func(lineLimit, fieldLimit uint32) map[string]*logproto.DetectedField {
+		fields, err := instance.GetDetectedFields(context.TODO(), &logproto.DetectedFieldsRequest{
+			Query:      `{job="app"}`,
+			Start:      time.Unix(0, 0),
+			End:        time.Unix(10, 0),
+			LineLimit:  lineLimit,
+			FieldLimit: fieldLimit,
+		})
+		require.NoError(t, err)
+
+		byLabel := map[string]*logproto.DetectedField{}
+		for _, f := range fields {
+			byLabel[f.Label] = f
+		}
+		return byLabel
+	}
+
+	// Only the most recent entries are sampled.
+	fields := detect(2, 100)
+	require.Len(t, fields, 6)
+	require.Equal(t, logproto.DetectedFieldString, fields["user"].Type)
+	require.Equal(t, uint64(2), fields["user"].Cardinality)
+	require.Equal(t, logproto.DetectedFieldInt, fields["status"].Type)
+	require.Equal(t, uint64(2), fields["status"].Cardinality)
+	require.Equal(t, logproto.DetectedFieldFloat, fields["ratio"].Type)
+	require.Equal(t, logproto.DetectedFieldBoolean, fields["ok"].Type)
+	require.Equal(t, logproto.DetectedFieldDuration, fields["took"].Type)
+	require.Equal(t, logproto.DetectedFieldBytes, fields["size"].Type)
+
+	// The sketches can be merged with the fields detected by other ingesters.
+	merged, err := detected.MergeFields([]*logproto.DetectedField{fields["user"], detect(3, 100)["user"]}, 100)
+	require.NoError(t, err)
+	require.Len(t, merged, 1)
+	require.Equal(t, uint64(2), merged[0].Cardinality)
+
+	require.Contains(t, detect(3, 100), "old")
+	require.Len(t, detect(3, 2), 2)
+}
+
 type testFilter struct{}
 
 func (t *testFilter) ForRequest(_ context.Context) chunk.Filterer {
@@ -1133,6 +1190,128 @@ func TestStreamShardingUsage(t *testing.T) {
 	})
 }
 
+func TestInstance_StreamLimitPolicies(t *testing.T) {
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.StreamLimitPolicies = []validation.StreamLimitPolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, MaxGlobalStreams: 2},
+		{Name: "dev", Selector: `{namespace="dev"}`, MaxLabelValuesPerLabel: 2},
+	}
+	require.NoError(t, limitsCfg.Validate())
+
+	limits, err := validation.NewOverrides(limitsCfg, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	inst, err := newInstance(defaultConfig(), defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	push := func(lbs string) error {
+		return inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+			{Labels: lbs, Entries: entries(1, time.Now().Add(-time.Minute))},
+		}})
+	}
+
+	t.Run("max streams per policy", func(t *testing.T) {
+		require.NoError(t, push(`{namespace="batch", job="a"}`))
+		require.NoError(t, push(`{namespace="batch", job="b"}`))
+		require.ErrorContains(t, push(`{namespace="batch", job="c"}`), "Maximum active stream limit exceeded for policy 'batch'")
+
+		// streams not matching the policy are not affected.
+		require.NoError(t, push(`{namespace="prod", job="c"}`))
+	})
+
+	t.Run("max label values per policy", func(t *testing.T) {
+		require.NoError(t, push(`{namespace="dev", pod="1"}`))
+		require.NoError(t, push(`{namespace="dev", pod="2"}`))
+		// existing label values are still accepted.
+		require.NoError(t, push(`{namespace="dev", pod="2", container="x"}`))
+		require.ErrorContains(t, push(`{namespace="dev", pod="3"}`), "Maximum number of values for label 'pod' exceeded for policy 'dev'")
+
+		// removing a stream frees its label values.
+		s, ok := inst.streams.Load(`{namespace="dev", pod="1"}`)
+		require.True(t, ok)
+		inst.removeStream(s)
+		require.NoError(t, push(`{namespace="dev", pod="3"}`))
+	})
+}
+
+func TestInstance_StorageClassStream(t *testing.T) {
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.StorageClassStream = []validation.StorageClassRule{
+		{StorageClass: "cold", ChunkEncoding: "zstd", Priority: 1, Selector: `{env="dev"}`},
+		{StorageClass: "short-ttl", Priority: 2, Selector: `{level="debug"}`},
+	}
+	require.NoError(t, limitsCfg.Validate())
+
+	limits, err := validation.NewOverrides(limitsCfg, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	inst, err := newInstance(cfg, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		labels       string
+		storageClass string
+		encoding     chunkenc.Encoding
+	}{
+		{labels: `{env="prod"}`, encoding: cfg.parsedEncoding},
+		{labels: `{env="dev"}`, storageClass: "cold", encoding: chunkenc.EncZstd},
+		// the rule with the highest priority is picked.
+		{labels: `{env="dev", level="debug"}`, storageClass: "short-ttl", encoding: cfg.parsedEncoding},
+	} {
+		t.Run(tc.labels, func(t *testing.T) {
+			require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+				{Labels: tc.labels, Entries: entries(1, time.Now().Add(-time.Minute))},
+			}}))
+
+			s, ok := inst.streams.Load(tc.labels)
+			require.True(t, ok)
+			require.Equal(t, tc.storageClass, s.storageClass)
+			require.Len(t, s.chunks, 1)
+			require.Equal(t, tc.storageClass, s.chunks[0].storageClass)
+			require.Equal(t, tc.encoding, s.chunks[0].chunk.Encoding())
+		})
+	}
+}
+
+func TestInstance_BucketedHeadBlock(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	periodConfigs := []config.PeriodConfig{
+		{From: MustParseDayTime("1900-01-01"), IndexType: types.TSDBType, Schema: "v13"},
+		{From: MustParseDayTime("2000-01-01"), IndexType: types.TSDBType, Schema: "v14"},
+	}
+	cfg := defaultConfig()
+	cfg.BucketedHeadBlock = true
+	inst, err := newInstance(cfg, periodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		labels  string
+		at      time.Time
+		headFmt chunkenc.HeadBlockFmt
+	}{
+		// only the V5 chunks of the schema v14 periods have bucketed head blocks.
+		{labels: `{app="v13"}`, at: time.Unix(0, 0), headFmt: chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt},
+		{labels: `{app="v14"}`, at: time.Now().Add(-time.Minute), headFmt: chunkenc.BucketedHeadBlockFmt},
+	} {
+		t.Run(tc.labels, func(t *testing.T) {
+			require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+				{Labels: tc.labels, Entries: entries(1, tc.at)},
+			}}))
+
+			s, ok := inst.streams.Load(tc.labels)
+			require.True(t, ok)
+			require.Len(t, s.chunks, 1)
+			require.Equal(t, tc.headFmt, s.chunks[0].chunk.HeadFormat())
+		})
+	}
+}
+
 func TestInstance_Volume(t *testing.T) {
 	prepareInstance := func(t *testing.T) *instance {
 		instance := defaultInstance(t)
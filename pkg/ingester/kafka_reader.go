@@ -0,0 +1,184 @@
+package ingester
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/kafka"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type kafkaOffsetContextKey struct{}
+
+// withKafkaOffset returns a context carrying the offset of the Kafka record
+// being pushed, so that the chunks it is appended to remember it.
+func withKafkaOffset(ctx context.Context, offset int64) context.Context {
+	return context.WithValue(ctx, kafkaOffsetContextKey{}, offset)
+}
+
+func kafkaOffsetFromContext(ctx context.Context) (int64, bool) {
+	offset, ok := ctx.Value(kafkaOffsetContextKey{}).(int64)
+	return offset, ok
+}
+
+// partitionReader consumes the Kafka partition owned by the ingester and
+// pushes the records to the tenant instances.
+//
+// The committed offset is the offset of the oldest record with entries in a
+// chunk not yet flushed, so that on restart the records that were only in
+// memory are consumed again. It replaces the WAL replay for this write path.
+type partitionReader struct {
+	services.Service
+
+	cfg       kafka.Config
+	partition int32
+	client    kafka.Client
+	ingester  *Ingester
+	logger    log.Logger
+	metrics   *ingesterMetrics
+
+	reader kafka.Reader
+	// next is the offset of the next record to consume.
+	next atomic.Int64
+}
+
+func newPartitionReader(cfg kafka.Config, partition int32, client kafka.Client, ingester *Ingester, metrics *ingesterMetrics, logger log.Logger) *partitionReader {
+	r := &partitionReader{
+		cfg:       cfg,
+		partition: partition,
+		client:    client,
+		ingester:  ingester,
+		logger:    log.With(logger, "partition", partition),
+		metrics:   metrics,
+	}
+	r.Service = services.NewBasicService(r.starting, r.running, r.stopping)
+	return r
+}
+
+func (r *partitionReader) starting(_ context.Context) error {
+	offset, err := r.client.CommittedOffset(r.partition)
+	if err != nil {
+		return err
+	}
+	r.reader, err = r.client.Reader(r.partition, offset)
+	if err != nil {
+		return err
+	}
+	r.next.Store(offset)
+	r.metrics.kafkaCommittedOffset.Set(float64(offset))
+
+	level.Info(r.logger).Log("msg", "consuming kafka partition", "offset", offset)
+	return nil
+}
+
+func (r *partitionReader) running(ctx context.Context) error {
+	go r.commitLoop(ctx)
+
+	for {
+		records, err := r.reader.Poll(ctx, r.cfg.MaxFetchBatch)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			level.Error(r.logger).Log("msg", "failed to read from kafka partition", "err", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, record := range records {
+			r.consume(ctx, record)
+			r.next.Store(record.Offset + 1)
+			r.metrics.kafkaConsumedOffset.Set(float64(record.Offset))
+		}
+	}
+}
+
+func (r *partitionReader) stopping(_ error) error {
+	return r.reader.Close()
+}
+
+// consume pushes the content of the record. Records that cannot be pushed are
+// not retried, the same way clients do not retry pushes rejected by the
+// ingesters with a 4xx error.
+func (r *partitionReader) consume(ctx context.Context, record kafka.Record) {
+	r.metrics.kafkaRecordsConsumed.Inc()
+
+	var req logproto.PushRequest
+	if err := req.Unmarshal(record.Value); err != nil {
+		r.metrics.kafkaConsumeFailures.Inc()
+		level.Error(r.logger).Log("msg", "failed to unmarshal kafka record", "offset", record.Offset, "err", err)
+		return
+	}
+
+	tenantID := string(record.Key)
+	instance, err := r.ingester.GetOrCreateInstance(tenantID)
+	if err == nil {
+		ctx = withKafkaOffset(user.InjectOrgID(ctx, tenantID), record.Offset)
+		err = instance.Push(ctx, &req)
+	}
+	if err != nil {
+		r.metrics.kafkaConsumeFailures.Inc()
+		level.Warn(r.logger).Log("msg", "failed to push kafka record", "offset", record.Offset, "tenant", tenantID, "err", err)
+	}
+}
+
+func (r *partitionReader) commitLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.CommitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.commit()
+		}
+	}
+}
+
+// commit commits the offset of the oldest record not yet flushed.
+func (r *partitionReader) commit() {
+	// Load the next offset before looking at the chunks: records consumed
+	// in the meantime have a greater offset anyway.
+	offset := r.next.Load()
+	if unflushed, ok := r.ingester.oldestUnflushedKafkaOffset(); ok && unflushed < offset {
+		offset = unflushed
+	}
+
+	if err := r.client.Commit(r.partition, offset); err != nil {
+		r.metrics.kafkaCommitFailures.Inc()
+		level.Error(r.logger).Log("msg", "failed to commit kafka offset", "offset", offset, "err", err)
+		return
+	}
+	r.metrics.kafkaCommittedOffset.Set(float64(offset))
+}
+
+// oldestUnflushedKafkaOffset returns the offset of the oldest Kafka record
+// with entries in a chunk that has not been flushed yet.
+func (i *Ingester) oldestUnflushedKafkaOffset() (int64, bool) {
+	oldest := int64(math.MaxInt64)
+	for _, instance := range i.getInstances() {
+		_ = instance.streams.ForEach(func(s *stream) (bool, error) {
+			s.chunkMtx.RLock()
+			defer s.chunkMtx.RUnlock()
+			for _, c := range s.chunks {
+				if c.hasKafkaOffset && c.flushed.IsZero() && c.kafkaOffset < oldest {
+					oldest = c.kafkaOffset
+				}
+			}
+			return true, nil
+		})
+	}
+	return oldest, oldest != math.MaxInt64
+}
@@ -0,0 +1,112 @@
+package ingester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/kafka"
+	"github.com/grafana/loki/v3/pkg/kafka/kafkatest"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/util/test"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestIngester_KafkaPartitionReader(t *testing.T) {
+	cluster := kafkatest.NewCluster(2)
+
+	ingesterConfig := defaultIngesterTestConfig(t)
+	ingesterConfig.LifecyclerConfig.ID = "ingester-1"
+	ingesterConfig.KafkaConfig = kafka.Config{Enabled: true, CommitInterval: time.Hour, MaxFetchBatch: 10}
+	ingesterConfig.kafkaClient = cluster
+
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+	i, err := New(ingesterConfig, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	produce := func(partition int32, tenant, lbs string, ts int64) {
+		req := &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  lbs,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(ts, 0), Line: "line"}},
+		}}}
+		value, err := req.Marshal()
+		require.NoError(t, err)
+		require.NoError(t, cluster.Produce(context.Background(), []kafka.Record{{Partition: partition, Key: []byte(tenant), Value: value}}))
+	}
+	produce(1, "test", `{foo="bar"}`, 1)
+	produce(1, "test", `{foo="baz"}`, 2)
+	produce(1, "other", `{foo="bar"}`, 3)
+	// Records of the partitions owned by other ingesters are ignored.
+	produce(0, "test", `{foo="qux"}`, 4)
+
+	test.Poll(t, time.Second, int64(3), func() interface{} {
+		return i.partitionReader.next.Load()
+	})
+
+	inst, ok := i.getInstanceByID("test")
+	require.True(t, ok)
+	require.Equal(t, 2, inst.numStreams())
+	inst, ok = i.getInstanceByID("other")
+	require.True(t, ok)
+	require.Equal(t, 1, inst.numStreams())
+
+	// Nothing has been flushed yet, so everything must be consumed again on restart.
+	i.partitionReader.commit()
+	committed, err := cluster.CommittedOffset(1)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), committed)
+
+	i.sweepUsers(true, false)
+	test.Poll(t, time.Second, false, func() interface{} {
+		_, ok := i.oldestUnflushedKafkaOffset()
+		return ok
+	})
+
+	i.partitionReader.commit()
+	committed, err = cluster.CommittedOffset(1)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), committed)
+}
+
+func TestStream_KafkaOffset(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	chunkfmt, headfmt := defaultChunkFormat(t)
+	s := newStream(chunkfmt, headfmt, defaultConfig(), limiter, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, NewStreamRateCalculator(), NilMetrics, nil)
+
+	push := func(ctx context.Context, ts int64) {
+		_, err := s.Push(ctx, []logproto.Entry{{Timestamp: time.Unix(ts, 0), Line: "line"}}, recordPool.GetRecord(), 0, true, false)
+		require.NoError(t, err)
+	}
+
+	// Entries not coming from Kafka do not set an offset.
+	push(context.Background(), 1)
+	require.False(t, s.chunks[0].hasKafkaOffset)
+
+	push(withKafkaOffset(context.Background(), 10), 2)
+	push(withKafkaOffset(context.Background(), 11), 3)
+	require.True(t, s.chunks[0].hasKafkaOffset)
+	require.Equal(t, int64(10), s.chunks[0].kafkaOffset)
+
+	s.cutChunk(context.Background())
+	push(withKafkaOffset(context.Background(), 12), 4)
+	require.Equal(t, int64(12), s.chunks[1].kafkaOffset)
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
@@ -28,6 +29,10 @@ type Limits interface {
 	MaxGlobalStreamsPerUser(userID string) int
 	PerStreamRateLimit(userID string) validation.RateLimit
 	ShardStreams(userID string) *shardstreams.Config
+	StreamLimitPolicies(userID string) []validation.StreamLimitPolicy
+	StorageClassStream(userID string) []validation.StorageClassRule
+	MetricStreams(userID string) []validation.MetricStreamRule
+	IndexStructuredMetadataKeys(userID string) []string
 }
 
 // Limiter implements primitives to get the maximum number of streams
@@ -111,6 +116,39 @@ func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
 	return fmt.Errorf(errMaxStreamsPerUserLimitExceeded, userID, streams, calculatedLimit, localLimit, globalLimit, adjustedGlobalLimit)
 }
 
+// AssertMaxStreamsPerPolicy ensures the limit of active streams matching the given
+// stream limit policy has not been reached compared to the current number of
+// streams in input and returns an error if so.
+func (l *Limiter) AssertMaxStreamsPerPolicy(userID string, policy *validation.StreamLimitPolicy, lbs labels.Labels, streams int) error {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if l.disabled || policy.MaxGlobalStreams <= 0 {
+		return nil
+	}
+
+	// The policy limit is global, so convert it to a local limit in the
+	// same way as the per-user limit.
+	localLimit := l.convertGlobalToLocalLimit(policy.MaxGlobalStreams)
+	if localLimit == 0 || streams < localLimit {
+		return nil
+	}
+
+	return fmt.Errorf(validation.PolicyStreamLimitErrorMsg, policy.Name, lbs, policy.Selector, userID)
+}
+
+// AssertMaxLabelValuesPerPolicy ensures that adding a new value for the given
+// label name doesn't exceed the number of label values allowed by the given
+// stream limit policy, and returns an error if so.
+func (l *Limiter) AssertMaxLabelValuesPerPolicy(userID string, policy *validation.StreamLimitPolicy, lbs labels.Labels, name string, values int) error {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if l.disabled || policy.MaxLabelValuesPerLabel <= 0 || values < policy.MaxLabelValuesPerLabel {
+		return nil
+	}
+
+	return fmt.Errorf(validation.PolicyLabelValuesLimitErrorMsg, name, policy.Name, lbs, policy.MaxLabelValuesPerLabel, userID)
+}
+
 func (l *Limiter) convertGlobalToLocalLimit(globalLimit int) int {
 	if globalLimit == 0 {
 		return 0
@@ -0,0 +1,385 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/storage/remote"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	lokilog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+// metricStreamsIngesterLabel is the label holding the ID of the ingester
+// writing the series, as the replicas of a stream write the same series.
+const metricStreamsIngesterLabel = "ingester"
+
+// MetricStreamsConfig configures the metrics aggregated by the ingesters from
+// the pushed log lines, following the 'metric_streams' limits of the tenants.
+type MetricStreamsConfig struct {
+	Enabled       bool                      `yaml:"enabled"`
+	WriteInterval time.Duration             `yaml:"write_interval"`
+	IdleTimeout   time.Duration             `yaml:"idle_timeout"`
+	MaxSeries     int                       `yaml:"max_series_per_tenant"`
+	Client        *config.RemoteWriteConfig `yaml:"client,omitempty" doc:"description=Remote-write client the samples are sent to. The X-Scope-OrgID header of the requests is set to the tenant of the samples."`
+}
+
+// RegisterFlags registers the flags.
+func (cfg *MetricStreamsConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "ingester.metric-streams.enabled", false, "Experimental: Aggregate the metrics defined by the 'metric_streams' limits of the tenants from the pushed log lines, and remote-write them. Each ingester writes the series of the streams it holds with its ID as the 'ingester' label, so the replicas of a stream write distinct series.")
+	f.DurationVar(&cfg.WriteInterval, "ingester.metric-streams.write-interval", 15*time.Second, "Experimental: Interval at which the samples of the metric streams are remote-written.")
+	f.DurationVar(&cfg.IdleTimeout, "ingester.metric-streams.idle-timeout", 5*time.Minute, "Experimental: Time after which the series not updated by any log line are no longer written.")
+	f.IntVar(&cfg.MaxSeries, "ingester.metric-streams.max-series-per-tenant", 10000, "Experimental: Maximum number of metric streams series per tenant in each ingester. The log lines of new series are not aggregated once the limit is reached. 0 to make unlimited.")
+}
+
+func (cfg *MetricStreamsConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Client == nil || cfg.Client.URL == nil {
+		return errors.New("metric streams enabled but no remote-write client URL is configured")
+	}
+	if cfg.WriteInterval <= 0 {
+		return fmt.Errorf("invalid metric streams write interval: %s", cfg.WriteInterval)
+	}
+	return nil
+}
+
+// metricStreams holds the series aggregated from the log lines of a tenant.
+type metricStreams struct {
+	tenant    string
+	ingester  string
+	limits    Limits
+	maxSeries int
+	metrics   *ingesterMetrics
+
+	mtx    sync.Mutex
+	series map[uint64]*metricStreamSeries
+}
+
+type metricStreamSeries struct {
+	labels      labels.Labels
+	lastUpdated time.Time
+
+	// value is the total of a counter, or the sum of the observations of a histogram.
+	value float64
+	// count and bucketCounts are the number of observations of a histogram,
+	// overall and per bucket.
+	count        uint64
+	buckets      []float64
+	bucketCounts []uint64
+}
+
+// streamMetricRule is a metric streams rule evaluated on the log lines of a stream.
+type streamMetricRule struct {
+	rule     *validation.MetricStreamRule
+	pipeline lokilog.StreamPipeline
+}
+
+func newMetricStreams(tenant, ingester string, limits Limits, maxSeries int, metrics *ingesterMetrics) *metricStreams {
+	return &metricStreams{
+		tenant:    tenant,
+		ingester:  ingester,
+		limits:    limits,
+		maxSeries: maxSeries,
+		metrics:   metrics,
+		series:    map[uint64]*metricStreamSeries{},
+	}
+}
+
+// streamMetricRules caches the rules of the tenant matching a stream, along with
+// the rules of the tenant they were selected from.
+type streamMetricRules struct {
+	source []validation.MetricStreamRule
+	rules  []streamMetricRule
+}
+
+// rulesFor returns the rules of the tenant matching the given stream labels. They
+// are cached until the rules of the tenant change, when the overrides are reloaded.
+func (m *metricStreams) rulesFor(lbs labels.Labels, cached *streamMetricRules) []streamMetricRule {
+	rules := m.limits.MetricStreams(m.tenant)
+	if len(rules) != len(cached.source) || len(rules) > 0 && &rules[0] != &cached.source[0] {
+		cached.source = rules
+		cached.rules = m.forStream(lbs, rules)
+	}
+	return cached.rules
+}
+
+// forStream returns the rules matching the given stream labels.
+func (m *metricStreams) forStream(lbs labels.Labels, rules []validation.MetricStreamRule) []streamMetricRule {
+	var res []streamMetricRule
+	for j := range rules {
+		if !rules[j].Matches(lbs) {
+			continue
+		}
+		pipeline, err := rules[j].Expr.Pipeline()
+		if err != nil {
+			level.Warn(util_log.Logger).Log("msg", "failed to create metric stream pipeline", "org_id", m.tenant, "metric", rules[j].Name, "err", err)
+			continue
+		}
+		res = append(res, streamMetricRule{rule: &rules[j], pipeline: pipeline.ForStream(lbs)})
+	}
+	return res
+}
+
+// observe aggregates the given log lines of a stream into the series of the rules.
+// The pipelines of the rules are not thread-safe, so the lines of a stream must be observed sequentially.
+func (m *metricStreams) observe(rules []streamMetricRule, entries []logproto.Entry) {
+	now := time.Now()
+	for _, r := range rules {
+		for _, e := range entries {
+			line, lbs, ok := r.pipeline.Process(e.Timestamp.UnixNano(), []byte(e.Line), logproto.FromLabelAdaptersToLabels(e.StructuredMetadata)...)
+			if !ok || lbs.Labels().Has(logqlmodel.ErrorLabel) {
+				continue
+			}
+
+			value := 1.0
+			switch r.rule.Type {
+			case validation.MetricStreamBytes:
+				value = float64(len(line))
+			case validation.MetricStreamHistogram:
+				v, err := strconv.ParseFloat(lbs.Labels().Get(r.rule.Unwrap), 64)
+				if err != nil {
+					continue
+				}
+				value = v
+			}
+			m.add(r.rule, m.seriesLabels(r.rule, lbs), value, now)
+		}
+	}
+}
+
+// seriesLabels returns the labels of the series of the given rule for a log line.
+func (m *metricStreams) seriesLabels(rule *validation.MetricStreamRule, lbs lokilog.LabelsResult) labels.Labels {
+	var b *labels.Builder
+	if len(rule.Labels) == 0 {
+		b = labels.NewBuilder(lbs.Stream())
+	} else {
+		all := lbs.Labels()
+		b = labels.NewBuilder(nil)
+		for _, name := range rule.Labels {
+			if v := all.Get(name); v != "" {
+				b.Set(name, v)
+			}
+		}
+	}
+	b.Set(labels.MetricName, rule.Name)
+	b.Set(metricStreamsIngesterLabel, m.ingester)
+	return b.Labels()
+}
+
+func (m *metricStreams) add(rule *validation.MetricStreamRule, lbs labels.Labels, value float64, now time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	h := lbs.Hash()
+	s, ok := m.series[h]
+	if !ok {
+		if m.maxSeries > 0 && len(m.series) >= m.maxSeries {
+			m.metrics.metricStreamsSeriesDropped.Inc()
+			return
+		}
+		s = &metricStreamSeries{labels: lbs}
+		if rule.Type == validation.MetricStreamHistogram {
+			s.buckets = rule.Buckets
+			if len(s.buckets) == 0 {
+				s.buckets = prometheus.DefBuckets
+			}
+			s.bucketCounts = make([]uint64, len(s.buckets))
+		}
+		m.series[h] = s
+	}
+
+	s.lastUpdated = now
+	s.value += value
+	if s.bucketCounts != nil {
+		s.count++
+		if i := sort.SearchFloat64s(s.buckets, value); i < len(s.buckets) {
+			s.bucketCounts[i]++
+		}
+	}
+}
+
+// collect returns the samples of the series at the given time, and removes the idle series.
+func (m *metricStreams) collect(now time.Time, idleTimeout time.Duration) []prompb.TimeSeries {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	ts := now.UnixMilli()
+	var res []prompb.TimeSeries
+	for h, s := range m.series {
+		if idleTimeout > 0 && now.Sub(s.lastUpdated) > idleTimeout {
+			delete(m.series, h)
+			continue
+		}
+
+		if s.bucketCounts == nil {
+			res = append(res, timeSeries(s.labels, "", "", s.value, ts))
+			continue
+		}
+
+		var cumulative uint64
+		for i, upper := range s.buckets {
+			cumulative += s.bucketCounts[i]
+			res = append(res, timeSeries(s.labels, "_bucket", strconv.FormatFloat(upper, 'f', -1, 64), float64(cumulative), ts))
+		}
+		res = append(res,
+			timeSeries(s.labels, "_bucket", "+Inf", float64(s.count), ts),
+			timeSeries(s.labels, "_sum", "", s.value, ts),
+			timeSeries(s.labels, "_count", "", float64(s.count), ts),
+		)
+	}
+	return res
+}
+
+// timeSeries returns a remote-write series of a single sample, suffixing the metric name of
+// the given labels and setting the le label of histogram buckets if given.
+func timeSeries(lbs labels.Labels, suffix, le string, value float64, ts int64) prompb.TimeSeries {
+	if suffix != "" || le != "" {
+		b := labels.NewBuilder(lbs)
+		b.Set(labels.MetricName, lbs.Get(labels.MetricName)+suffix)
+		if le != "" {
+			b.Set(labels.BucketLabel, le)
+		}
+		lbs = b.Labels()
+	}
+
+	series := prompb.TimeSeries{
+		Labels:  make([]prompb.Label, 0, len(lbs)),
+		Samples: []prompb.Sample{{Value: value, Timestamp: ts}},
+	}
+	for _, l := range lbs {
+		series.Labels = append(series.Labels, prompb.Label{Name: l.Name, Value: l.Value})
+	}
+	return series
+}
+
+// metricStreamsLoop periodically remote-writes the metric streams series of the tenants.
+func (i *Ingester) metricStreamsLoop() {
+	defer i.loopDone.Done()
+
+	ticker := time.NewTicker(i.cfg.MetricStreams.WriteInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			i.writeMetricStreams()
+		case <-i.loopQuit:
+			// write the latest samples before stopping.
+			i.writeMetricStreams()
+			return
+		}
+	}
+}
+
+func (i *Ingester) writeMetricStreams() {
+	ctx, cancel := context.WithTimeout(context.Background(), i.cfg.MetricStreams.WriteInterval)
+	defer cancel()
+
+	now := time.Now()
+	for _, inst := range i.getInstances() {
+		if inst.metricStreams != nil {
+			i.metricStreamsWriter.write(ctx, inst.metricStreams, now)
+		}
+	}
+}
+
+// metricStreamsWriter remote-writes the metric streams series of the tenants.
+type metricStreamsWriter struct {
+	cfg     MetricStreamsConfig
+	logger  log.Logger
+	metrics *ingesterMetrics
+
+	mtx     sync.Mutex
+	clients map[string]remote.WriteClient
+}
+
+func newMetricStreamsWriter(cfg MetricStreamsConfig, metrics *ingesterMetrics, logger log.Logger) *metricStreamsWriter {
+	return &metricStreamsWriter{
+		cfg:     cfg,
+		logger:  logger,
+		metrics: metrics,
+		clients: map[string]remote.WriteClient{},
+	}
+}
+
+// client returns the remote-write client of the given tenant.
+func (w *metricStreamsWriter) client(tenant string) (remote.WriteClient, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if c, ok := w.clients[tenant]; ok {
+		return c, nil
+	}
+
+	headers := make(map[string]string, len(w.cfg.Client.Headers)+1)
+	for k, v := range w.cfg.Client.Headers {
+		headers[k] = v
+	}
+	headers["X-Scope-OrgID"] = tenant
+
+	timeout := w.cfg.Client.RemoteTimeout
+	if timeout <= 0 {
+		timeout = model.Duration(w.cfg.WriteInterval)
+	}
+
+	c, err := remote.NewWriteClient(fmt.Sprintf("metric-streams-%s", tenant), &remote.ClientConfig{
+		URL:              w.cfg.Client.URL,
+		Timeout:          timeout,
+		HTTPClientConfig: w.cfg.Client.HTTPClientConfig,
+		SigV4Config:      w.cfg.Client.SigV4Config,
+		AzureADConfig:    w.cfg.Client.AzureADConfig,
+		Headers:          headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.clients[tenant] = c
+	return c, nil
+}
+
+// write remote-writes the series of the given tenant.
+func (w *metricStreamsWriter) write(ctx context.Context, m *metricStreams, now time.Time) {
+	series := m.collect(now, w.cfg.IdleTimeout)
+	if len(series) == 0 {
+		return
+	}
+
+	if err := w.store(ctx, m.tenant, series); err != nil {
+		w.metrics.metricStreamsWriteFailures.Inc()
+		level.Warn(w.logger).Log("msg", "failed to write metric streams samples", "org_id", m.tenant, "series", len(series), "err", err)
+		return
+	}
+	w.metrics.metricStreamsSamplesWritten.Add(float64(len(series)))
+}
+
+func (w *metricStreamsWriter) store(ctx context.Context, tenant string, series []prompb.TimeSeries) error {
+	c, err := w.client(tenant)
+	if err != nil {
+		return err
+	}
+	req := prompb.WriteRequest{Timeseries: series}
+	buf, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	return c.Store(ctx, snappy.Encode(nil, buf), 0)
+}
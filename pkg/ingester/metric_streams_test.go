@@ -0,0 +1,180 @@
+package ingester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	config_util "github.com/prometheus/common/config"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	loki_runtime "github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestInstance_MetricStreams(t *testing.T) {
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.MetricStreams = []validation.MetricStreamRule{
+		{Name: "nginx_lines_total", Selector: `{app="nginx"}`, Type: validation.MetricStreamCount},
+		{Name: "nginx_error_bytes_total", Selector: `{app="nginx"} | logfmt | status >= 500`, Type: validation.MetricStreamBytes, Labels: []string{"status"}},
+		{Name: "nginx_duration_seconds", Selector: `{app="nginx"} | logfmt`, Type: validation.MetricStreamHistogram, Unwrap: "duration", Buckets: []float64{0.1, 1}, Labels: []string{"app"}},
+	}
+	require.NoError(t, limitsCfg.Validate())
+
+	limits, err := validation.NewOverrides(limitsCfg, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	cfg.LifecyclerConfig.ID = "ingester-1"
+	cfg.MetricStreams = MetricStreamsConfig{Enabled: true, MaxSeries: 10}
+	inst, err := newInstance(cfg, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: `{app="nginx", pod="a"}`, Entries: []logproto.Entry{
+			{Timestamp: now.Add(-3 * time.Second), Line: `status=200 duration=0.05`},
+			{Timestamp: now.Add(-2 * time.Second), Line: `status=500 duration=0.5`},
+			{Timestamp: now.Add(-1 * time.Second), Line: `status=500 duration=5`},
+		}},
+		{Labels: `{app="nginx", pod="b"}`, Entries: []logproto.Entry{
+			{Timestamp: now.Add(-time.Second), Line: `status=200 duration=invalid`},
+		}},
+		{Labels: `{app="other"}`, Entries: []logproto.Entry{
+			{Timestamp: now.Add(-time.Second), Line: `status=500 duration=1`},
+		}},
+	}}))
+
+	samples := map[string]float64{}
+	for _, ts := range inst.metricStreams.collect(now, time.Minute) {
+		require.Len(t, ts.Samples, 1)
+		require.Equal(t, now.UnixMilli(), ts.Samples[0].Timestamp)
+		samples[seriesString(ts.Labels)] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		`{__name__="nginx_lines_total", app="nginx", ingester="ingester-1", pod="a"}`:               3,
+		`{__name__="nginx_lines_total", app="nginx", ingester="ingester-1", pod="b"}`:               1,
+		`{__name__="nginx_error_bytes_total", ingester="ingester-1", status="500"}`:                 44,
+		`{__name__="nginx_duration_seconds_bucket", app="nginx", ingester="ingester-1", le="0.1"}`:  1,
+		`{__name__="nginx_duration_seconds_bucket", app="nginx", ingester="ingester-1", le="1"}`:    2,
+		`{__name__="nginx_duration_seconds_bucket", app="nginx", ingester="ingester-1", le="+Inf"}`: 3,
+		`{__name__="nginx_duration_seconds_sum", app="nginx", ingester="ingester-1"}`:               5.55,
+		`{__name__="nginx_duration_seconds_count", app="nginx", ingester="ingester-1"}`:             3,
+	}, samples)
+
+	// Idle series are removed.
+	require.Empty(t, inst.metricStreams.collect(now.Add(2*time.Minute), time.Minute))
+}
+
+func TestInstance_MetricStreamsOverridesReload(t *testing.T) {
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.MetricStreams = []validation.MetricStreamRule{
+		{Name: "lines_total", Selector: `{app="nginx"}`, Type: validation.MetricStreamCount},
+	}
+	require.NoError(t, limitsCfg.Validate())
+	tenantLimits := fakeLimits{limits: map[string]*validation.Limits{"test": &limitsCfg}}
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), tenantLimits)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	cfg := defaultConfig()
+	cfg.LifecyclerConfig.ID = "ingester-1"
+	cfg.MetricStreams = MetricStreamsConfig{Enabled: true, MaxSeries: 10}
+	inst, err := newInstance(cfg, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	push := func(ts time.Time) {
+		require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{
+			{Labels: `{app="nginx"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "line"}}},
+		}}))
+	}
+	push(now.Add(-2 * time.Second))
+
+	// The rules of the existing streams follow the reloaded overrides.
+	reloaded := defaultLimitsTestConfig()
+	reloaded.MetricStreams = []validation.MetricStreamRule{
+		{Name: "bytes_total", Selector: `{app="nginx"}`, Type: validation.MetricStreamBytes},
+	}
+	require.NoError(t, reloaded.Validate())
+	tenantLimits.limits["test"] = &reloaded
+	push(now.Add(-time.Second))
+
+	samples := map[string]float64{}
+	for _, ts := range inst.metricStreams.collect(now, time.Minute) {
+		samples[seriesString(ts.Labels)] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		`{__name__="lines_total", app="nginx", ingester="ingester-1"}`: 1,
+		`{__name__="bytes_total", app="nginx", ingester="ingester-1"}`: 4,
+	}, samples)
+}
+
+func TestMetricStreamsWriter(t *testing.T) {
+	var (
+		tenant string
+		req    prompb.WriteRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Scope-OrgID")
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, req.Unmarshal(buf))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	cfg := MetricStreamsConfig{
+		Enabled:       true,
+		WriteInterval: time.Second,
+		Client:        &config.RemoteWriteConfig{URL: &config_util.URL{URL: u}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	limitsCfg := defaultLimitsTestConfig()
+	limitsCfg.MetricStreams = []validation.MetricStreamRule{
+		{Name: "lines_total", Selector: `{app="foo"}`, Type: validation.MetricStreamCount},
+	}
+	require.NoError(t, limitsCfg.Validate())
+	limits, err := validation.NewOverrides(limitsCfg, nil)
+	require.NoError(t, err)
+
+	metrics := newIngesterMetrics(prometheus.NewRegistry(), "loki")
+	m := newMetricStreams("tenant", "ingester-1", limits, 0, metrics)
+	lbs := labels.FromStrings("app", "foo")
+	m.observe(m.forStream(lbs, limits.MetricStreams("tenant")), []logproto.Entry{{Timestamp: time.Now(), Line: "line"}})
+
+	w := newMetricStreamsWriter(cfg, metrics, log.NewNopLogger())
+	w.write(context.Background(), m, time.Now())
+
+	require.Equal(t, "tenant", tenant)
+	require.Len(t, req.Timeseries, 1)
+	require.Equal(t, `{__name__="lines_total", app="foo", ingester="ingester-1"}`, seriesString(req.Timeseries[0].Labels))
+	require.Equal(t, 1.0, req.Timeseries[0].Samples[0].Value)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.metricStreamsSamplesWritten))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.metricStreamsWriteFailures))
+}
+
+func seriesString(lbs []prompb.Label) string {
+	ls := make(labels.Labels, 0, len(lbs))
+	for _, l := range lbs {
+		ls = append(ls, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	return ls.String()
+}
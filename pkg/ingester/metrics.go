@@ -23,6 +23,7 @@ type ingesterMetrics struct {
 	walReplaySamplesDropped *prometheus.CounterVec
 	walReplayBytesDropped   *prometheus.CounterVec
 	walCorruptionsTotal     *prometheus.CounterVec
+	walSkippedRecordsTotal  *prometheus.CounterVec
 	walLoggedBytesTotal     prometheus.Counter
 	walRecordsLogged        prometheus.Counter
 
@@ -48,6 +49,8 @@ type ingesterMetrics struct {
 	chunkAge                      prometheus.Histogram
 	chunkEncodeTime               prometheus.Histogram
 	chunksFlushedPerReason        *prometheus.CounterVec
+	sentChunks                    prometheus.Counter
+	receivedChunks                prometheus.Counter
 	chunkLifespan                 prometheus.Histogram
 	flushedChunksStats            *analytics.Counter
 	flushedChunksBytesStats       *analytics.Statistics
@@ -65,6 +68,21 @@ type ingesterMetrics struct {
 	shutdownMarker prometheus.Gauge
 
 	flushQueueLength prometheus.Gauge
+
+	kafkaRecordsConsumed prometheus.Counter
+	kafkaConsumeFailures prometheus.Counter
+	kafkaConsumedOffset  prometheus.Gauge
+	kafkaCommittedOffset prometheus.Gauge
+	kafkaCommitFailures  prometheus.Counter
+
+	blockCacheHits      prometheus.Counter
+	blockCacheMisses    prometheus.Counter
+	blockCacheEvictions prometheus.Counter
+	blockCacheBytes     prometheus.Gauge
+
+	metricStreamsSamplesWritten prometheus.Counter
+	metricStreamsWriteFailures  prometheus.Counter
+	metricStreamsSeriesDropped  prometheus.Counter
 }
 
 // setRecoveryBytesInUse bounds the bytes reports to >= 0.
@@ -109,6 +127,10 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Name: "loki_ingester_wal_corruptions_total",
 			Help: "Total number of WAL corruptions encountered.",
 		}, []string{"type"}),
+		walSkippedRecordsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "loki_ingester_wal_skipped_records_total",
+			Help: "Total number of corrupt WAL records skipped during replay.",
+		}, []string{"type"}),
 		checkpointDeleteFail: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "loki_ingester_checkpoint_deletions_failed_total",
 			Help: "Total number of checkpoint deletions that failed.",
@@ -237,6 +259,16 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Name:      "ingester_chunks_flushed_total",
 			Help:      "Total flushed chunks per reason.",
 		}, []string{"reason"}),
+		sentChunks: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ingester_sent_chunks_total",
+			Help:      "The total number of chunks sent by this ingester whilst leaving.",
+		}),
+		receivedChunks: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "ingester_received_chunks_total",
+			Help:      "The total number of chunks received by this ingester whilst joining.",
+		}),
 		chunkLifespan: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
 			Namespace: constants.Loki,
 			Name:      "ingester_chunk_bounds_hours",
@@ -287,5 +319,78 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Name:      "flush_queue_length",
 			Help:      "The total number of series pending in the flush queue.",
 		}),
+
+		kafkaRecordsConsumed: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "kafka_records_consumed_total",
+			Help:      "The total number of records consumed from the Kafka partition.",
+		}),
+		kafkaConsumeFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "kafka_consume_failures_total",
+			Help:      "The total number of records consumed from the Kafka partition that could not be pushed.",
+		}),
+		kafkaConsumedOffset: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "kafka_consumed_offset",
+			Help:      "The offset of the last record consumed from the Kafka partition.",
+		}),
+		kafkaCommittedOffset: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "kafka_committed_offset",
+			Help:      "The last offset committed for the Kafka partition. Records from this offset are replayed on restart.",
+		}),
+		kafkaCommitFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "kafka_commit_failures_total",
+			Help:      "The total number of failed Kafka offset commits.",
+		}),
+		blockCacheHits: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "block_cache_hits_total",
+			Help:      "The total number of chunk blocks read from the decompressed block cache.",
+		}),
+		blockCacheMisses: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "block_cache_misses_total",
+			Help:      "The total number of chunk blocks not found in the decompressed block cache.",
+		}),
+		blockCacheEvictions: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "block_cache_evictions_total",
+			Help:      "The total number of chunk blocks evicted from the decompressed block cache.",
+		}),
+		blockCacheBytes: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "block_cache_bytes",
+			Help:      "The size in bytes of the blocks in the decompressed block caches of the tenants.",
+		}),
+		metricStreamsSamplesWritten: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "metric_streams_samples_written_total",
+			Help:      "The total number of metric streams samples remote-written.",
+		}),
+		metricStreamsWriteFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "metric_streams_write_failures_total",
+			Help:      "The total number of failed remote-writes of metric streams samples.",
+		}),
+		metricStreamsSeriesDropped: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Subsystem: "ingester",
+			Name:      "metric_streams_series_dropped_total",
+			Help:      "The total number of metric streams series dropped because the tenant reached the maximum number of series.",
+		}),
 	}
 }
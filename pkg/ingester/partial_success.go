@@ -0,0 +1,97 @@
+package ingester
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// PartialSuccessMetadataKey is the gRPC metadata key used by the distributors
+// to ask the ingesters to report the streams rejected by a push in the
+// response, instead of failing the whole push. Ingesters not supporting it
+// keep failing the push, which the distributors handle as before.
+const PartialSuccessMetadataKey = "x-loki-accept-partial-success"
+
+// WithPartialSuccess returns a context for pushing to the ingesters with
+// partial success enabled.
+func WithPartialSuccess(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, PartialSuccessMetadataKey, "true")
+}
+
+func acceptsPartialSuccess(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	values := md.Get(PartialSuccessMetadataKey)
+	return len(values) > 0 && values[0] == "true"
+}
+
+// newStreamPushResult returns the result of the stream at the given index of
+// the push request, of which the entries at the rejected offsets failed with
+// err. A nil rejected stands for all the entries of the stream.
+func newStreamPushResult(index int, stream logproto.Stream, rejected []int32, reason string, err error) logproto.StreamPushResult {
+	msg, retryable := err.Error(), false
+	if resp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+		msg = string(resp.Body)
+		retryable = resp.Code == http.StatusTooManyRequests
+	}
+	n := len(rejected)
+	if rejected == nil || n >= len(stream.Entries) {
+		// The offsets are only reported when some entries were accepted.
+		n, rejected = len(stream.Entries), nil
+	}
+
+	return logproto.StreamPushResult{
+		Index:           int32(index),
+		Labels:          stream.Labels,
+		AcceptedEntries: int64(len(stream.Entries) - n),
+		RejectedEntries: int64(n),
+		Reason:          reason,
+		Error:           msg,
+		Retryable:       retryable,
+		RejectedOffsets: rejected,
+	}
+}
+
+// rejectedOffsets returns the offsets in entries of the failed entries. The
+// failed entries may be copies of the pushed ones, so they are matched by
+// timestamp and line: the same entry pushed twice is interchangeable.
+func rejectedOffsets(entries []logproto.Entry, failed []entryWithError) []int32 {
+	type key struct {
+		ts   int64
+		line string
+	}
+	remaining := make(map[key]int, len(failed))
+	for _, f := range failed {
+		remaining[key{f.entry.Timestamp.UnixNano(), f.entry.Line}]++
+	}
+
+	offsets := make([]int32, 0, len(failed))
+	for i := range entries {
+		k := key{entries[i].Timestamp.UnixNano(), entries[i].Line}
+		if remaining[k] > 0 {
+			remaining[k]--
+			offsets = append(offsets, int32(i))
+		}
+	}
+	return offsets
+}
+
+// newPushPartialSuccess returns the partial success of a push from the results
+// of the streams with rejected entries, or nil if there are none.
+func newPushPartialSuccess(results []logproto.StreamPushResult) *logproto.PushPartialSuccess {
+	if len(results) == 0 {
+		return nil
+	}
+
+	partial := &logproto.PushPartialSuccess{Streams: results}
+	for _, r := range results {
+		partial.RejectedEntries += r.RejectedEntries
+	}
+	return partial
+}
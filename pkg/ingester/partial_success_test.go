@@ -0,0 +1,118 @@
+package ingester
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
+	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestIngester_PushPartialSuccess(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.MaxLocalStreamsPerUser = 1
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+	i, err := New(defaultIngesterTestConfig(t), client.Config{}, store, overrides, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, log.NewNopLogger())
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	req := &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "line 1"}}},
+		{Labels: `{foo="baz"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0), Line: "line 1"}, {Timestamp: time.Unix(2, 0), Line: "line 2"}}},
+	}}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+
+	// Without partial success, the push fails as a whole.
+	_, err = i.Push(ctx, req)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(PartialSuccessMetadataKey, "true"))
+	req.Streams[0].Entries[0].Timestamp = time.Unix(3, 0)
+	pushResp, err := i.Push(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, &logproto.PushPartialSuccess{
+		RejectedEntries: 2,
+		Streams: []logproto.StreamPushResult{{
+			Index:           1,
+			Labels:          `{foo="baz"}`,
+			RejectedEntries: 2,
+			Reason:          validation.StreamLimit,
+			Error:           string(resp.Body),
+			Retryable:       true,
+		}},
+	}, pushResp.PartialSuccess)
+}
+
+func TestIngester_PushPartialSuccess_RejectedOffsets(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.PerStreamRateLimit = 6
+	limits.PerStreamRateLimitBurst = 12
+	// Without sharding, the entries of a stream are rate limited one by one.
+	limits.ShardStreams = &shardstreams.Config{Enabled: false}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+	i, err := New(defaultIngesterTestConfig(t), client.Config{}, store, overrides, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, log.NewNopLogger())
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// The per stream rate limit only lets the first 2 lines through.
+	req := &logproto.PushRequest{Streams: []logproto.Stream{
+		{Labels: `{foo="bar"}`, Entries: []logproto.Entry{
+			{Timestamp: time.Unix(1, 0), Line: "line 1"},
+			{Timestamp: time.Unix(2, 0), Line: "line 2"},
+			{Timestamp: time.Unix(3, 0), Line: "line 3"},
+			{Timestamp: time.Unix(4, 0), Line: "line 4"},
+		}},
+	}}
+
+	ctx := metadata.NewIncomingContext(user.InjectOrgID(context.Background(), "test"), metadata.Pairs(PartialSuccessMetadataKey, "true"))
+	pushResp, err := i.Push(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, pushResp.PartialSuccess)
+	require.Len(t, pushResp.PartialSuccess.Streams, 1)
+
+	res := pushResp.PartialSuccess.Streams[0]
+	require.Equal(t, int64(2), res.AcceptedEntries)
+	require.Equal(t, int64(2), res.RejectedEntries)
+	require.Equal(t, validation.StreamRateLimit, res.Reason)
+	require.True(t, res.Retryable)
+	require.Equal(t, []int32{2, 3}, res.RejectedOffsets)
+}
+
+func TestRejectedOffsets(t *testing.T) {
+	entries := []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "a"},
+		{Timestamp: time.Unix(2, 0), Line: "b"},
+		{Timestamp: time.Unix(1, 0), Line: "a"},
+		{Timestamp: time.Unix(3, 0), Line: "c"},
+	}
+	// The failed entries may be copies of the pushed ones.
+	failed := []entryWithError{
+		{entry: &logproto.Entry{Timestamp: time.Unix(3, 0), Line: "c"}},
+		{entry: &logproto.Entry{Timestamp: time.Unix(1, 0), Line: "a"}},
+	}
+	require.Equal(t, []int32{0, 3}, rejectedOffsets(entries, failed))
+}
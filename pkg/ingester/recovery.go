@@ -10,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/record"
-	"github.com/prometheus/prometheus/tsdb/wlog"
 	"golang.org/x/net/context"
 
 	"github.com/grafana/loki/v3/pkg/ingester/wal"
@@ -31,7 +30,7 @@ func (NoopWALReader) Err() error     { return nil }
 func (NoopWALReader) Record() []byte { return nil }
 func (NoopWALReader) Close() error   { return nil }
 
-func newCheckpointReader(dir string, logger log.Logger) (WALReader, io.Closer, error) {
+func newCheckpointReader(dir string, metrics *ingesterMetrics, status *walStatus, logger log.Logger) (WALReader, io.Closer, error) {
 	lastCheckpointDir, idx, err := lastCheckpoint(dir)
 	if err != nil {
 		return nil, nil, err
@@ -42,11 +41,11 @@ func newCheckpointReader(dir string, logger log.Logger) (WALReader, io.Closer, e
 		return reader, reader, nil
 	}
 
-	r, err := wlog.NewSegmentsReader(lastCheckpointDir)
+	r, err := newSegmentsReader(lastCheckpointDir, walTypeCheckpoint, metrics, status, logger)
 	if err != nil {
 		return nil, nil, err
 	}
-	return wlog.NewReader(r), r, nil
+	return r, r, nil
 }
 
 type Recoverer interface {
@@ -223,6 +222,20 @@ func (r *ingesterRecoverer) Close() {
 				}
 			}
 
+			// Likewise, convert the head block between the unordered and the bucketed
+			// formats if the bucketed head blocks were enabled or disabled since the WAL was written.
+			chk := s.chunks[len(s.chunks)-1].chunk
+			if isAllowed && isBucketedHeadSwitch(chk.HeadFormat(), s.chunkHeadBlockFormat) {
+				if err := chk.ConvertHead(s.chunkHeadBlockFormat); err != nil {
+					level.Warn(r.logger).Log(
+						"msg", "error converting headblock",
+						"err", err.Error(),
+						"stream", s.labels.String(),
+						"component", "ingesterRecoverer",
+					)
+				}
+			}
+
 			return nil
 		})
 	}
@@ -394,6 +407,9 @@ func recoverGeneric(
 				continue
 			}
 		}
+		if err := reader.Err(); err != nil {
+			errCh <- err
+		}
 
 		for _, w := range inputs {
 			close(w)
@@ -49,6 +49,14 @@ type stream struct {
 	labelHash        uint64
 	labelHashNoShard uint64
 
+	// names of the stream limit policies matching the stream when it was created.
+	limitPolicies []string
+
+	// storage class and encoding of the chunks of the stream, selected when the
+	// stream was created.
+	storageClass  string
+	chunkEncoding chunkenc.Encoding
+
 	// most recently pushed line. This is used to prevent duplicate pushes.
 	// It also determines chunk synchronization when unordered writes are disabled.
 	lastLine line
@@ -77,6 +85,14 @@ type stream struct {
 
 	chunkFormat          byte
 	chunkHeadBlockFormat chunkenc.HeadBlockFmt
+
+	// decompressed blocks of the chunks reused across queries, nil when disabled.
+	blockCache *blockCache
+
+	// metric streams rules matching the stream, and the series of the tenant
+	// they are aggregated into.
+	metricRules   streamMetricRules
+	metricStreams *metricStreams
 }
 
 type chunkDesc struct {
@@ -87,6 +103,14 @@ type chunkDesc struct {
 	reason  string
 
 	lastUpdated time.Time
+
+	// storageClass is the storage class the chunk is flushed to.
+	storageClass string
+
+	// kafkaOffset is the offset of the oldest Kafka record with entries in the
+	// chunk. Only set when hasKafkaOffset is true.
+	kafkaOffset    int64
+	hasKafkaOffset bool
 }
 
 type entryWithError struct {
@@ -125,6 +149,7 @@ func newStream(
 		writeFailures:        writeFailures,
 		chunkFormat:          chunkFormat,
 		chunkHeadBlockFormat: headBlockFmt,
+		chunkEncoding:        cfg.parsedEncoding,
 	}
 }
 
@@ -139,7 +164,8 @@ func (s *stream) consumeChunk(_ context.Context, chunk *logproto.Chunk) error {
 	}
 
 	s.chunks = append(s.chunks, chunkDesc{
-		chunk: c,
+		chunk:        c,
+		storageClass: s.storageClass,
 	})
 	s.metrics.chunksCreatedTotal.Inc()
 	return nil
@@ -154,7 +180,8 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 		return 0, 0, err
 	}
 	s.chunks = chks
-	for _, c := range s.chunks {
+	for j, c := range s.chunks {
+		s.chunks[j].storageClass = s.storageClass
 		entriesAdded += c.chunk.Size()
 		bytesAdded += c.chunk.UncompressedSize()
 	}
@@ -162,7 +189,11 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 }
 
 func (s *stream) NewChunk() *chunkenc.MemChunk {
-	return chunkenc.NewMemChunk(s.chunkFormat, s.cfg.parsedEncoding, s.chunkHeadBlockFormat, s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	c := chunkenc.NewMemChunk(s.chunkFormat, s.chunkEncoding, s.chunkHeadBlockFormat, s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	if s.chunkEncoding == chunkenc.EncZstdDict {
+		c.UseZstdDictionary(chunkenc.ZstdDict.TenantDictionary(s.tenant))
+	}
+	return c
 }
 
 func (s *stream) Push(
@@ -182,6 +213,12 @@ func (s *stream) Push(
 	// Whether nor not to ingest all at once or not. It is a per-tenant configuration.
 	rateLimitWholeStream bool,
 ) (int, error) {
+	bytesAdded, _, err := s.push(ctx, entries, record, counter, lockChunk, rateLimitWholeStream)
+	return bytesAdded, err
+}
+
+// push is like Push but also returns the entries that could not be pushed.
+func (s *stream) push(ctx context.Context, entries []logproto.Entry, record *wal.Record, counter int64, lockChunk bool, rateLimitWholeStream bool) (int, []entryWithError, error) {
 	if lockChunk {
 		s.chunkMtx.Lock()
 		defer s.chunkMtx.Unlock()
@@ -196,18 +233,19 @@ func (s *stream) Push(
 
 		s.metrics.walReplaySamplesDropped.WithLabelValues(duplicateReason).Add(float64(len(entries)))
 		s.metrics.walReplayBytesDropped.WithLabelValues(duplicateReason).Add(float64(byteCt))
-		return 0, ErrEntriesExist
+		return 0, nil, ErrEntriesExist
 	}
 
 	toStore, invalid := s.validateEntries(entries, isReplay, rateLimitWholeStream)
 	if rateLimitWholeStream && hasRateLimitErr(invalid) {
-		return 0, errorForFailedEntries(s, invalid, len(entries))
+		return 0, invalid, errorForFailedEntries(s, invalid, len(entries))
 	}
 
 	prevNumChunks := len(s.chunks)
 	if prevNumChunks == 0 {
 		s.chunks = append(s.chunks, chunkDesc{
-			chunk: s.NewChunk(),
+			chunk:        s.NewChunk(),
+			storageClass: s.storageClass,
 		})
 		s.metrics.chunksCreatedTotal.Inc()
 		s.metrics.chunkCreatedStats.Inc(1)
@@ -215,12 +253,18 @@ func (s *stream) Push(
 
 	bytesAdded, storedEntries, entriesWithErr := s.storeEntries(ctx, toStore)
 	s.recordAndSendToTailers(record, storedEntries)
+	if s.metricStreams != nil && !isReplay {
+		if rules := s.metricStreams.rulesFor(s.labels, &s.metricRules); len(rules) > 0 {
+			s.metricStreams.observe(rules, storedEntries)
+		}
+	}
 
 	if len(s.chunks) != prevNumChunks {
 		s.metrics.memoryChunks.Add(float64(len(s.chunks) - prevNumChunks))
 	}
 
-	return bytesAdded, errorForFailedEntries(s, append(invalid, entriesWithErr...), len(entries))
+	failed := append(invalid, entriesWithErr...)
+	return bytesAdded, failed, errorForFailedEntries(s, failed, len(entries))
 }
 
 func errorForFailedEntries(s *stream, failedEntriesWithError []entryWithError, totalEntries int) error {
@@ -261,6 +305,26 @@ func errorForFailedEntries(s *stream, failedEntriesWithError []entryWithError, t
 	return httpgrpc.Errorf(statusCode, buf.String())
 }
 
+// discardReason returns the reason the entries failed to be pushed, based on
+// the last failure like errorForFailedEntries does.
+func (s *stream) discardReason(failedEntriesWithError []entryWithError) string {
+	if len(failedEntriesWithError) == 0 {
+		return ""
+	}
+
+	err := failedEntriesWithError[len(failedEntriesWithError)-1].e
+	if _, ok := err.(*validation.ErrStreamRateLimit); ok {
+		return validation.StreamRateLimit
+	}
+	if chunkenc.IsOutOfOrderErr(err) {
+		if s.unorderedWrites {
+			return validation.TooFarBehind
+		}
+		return validation.OutOfOrder
+	}
+	return ""
+}
+
 func hasRateLimitErr(errs []entryWithError) bool {
 	if len(errs) == 0 {
 		return false
@@ -323,6 +387,7 @@ func (s *stream) storeEntries(ctx context.Context, entries []logproto.Entry) (in
 
 	var invalid []entryWithError
 	storedEntries := make([]logproto.Entry, 0, len(entries))
+	kafkaOffset, fromKafka := kafkaOffsetFromContext(ctx)
 	for i := 0; i < len(entries); i++ {
 		chunk := &s.chunks[len(s.chunks)-1]
 		if chunk.closed || !chunk.chunk.SpaceFor(&entries[i]) || s.cutChunkForSynchronization(entries[i].Timestamp, s.highestTs, chunk, s.cfg.SyncPeriod, s.cfg.SyncMinUtilization) {
@@ -340,6 +405,11 @@ func (s *stream) storeEntries(ctx context.Context, entries []logproto.Entry) (in
 			continue
 		}
 
+		if fromKafka && !chunk.hasKafkaOffset {
+			chunk.kafkaOffset = kafkaOffset
+			chunk.hasKafkaOffset = true
+		}
+
 		s.entryCt++
 		s.lastLine.ts = entries[i].Timestamp
 		s.lastLine.content = entries[i].Line
@@ -467,7 +537,8 @@ func (s *stream) cutChunk(ctx context.Context) *chunkDesc {
 	s.metrics.chunkCreatedStats.Inc(1)
 
 	s.chunks = append(s.chunks, chunkDesc{
-		chunk: s.NewChunk(),
+		chunk:        s.NewChunk(),
+		storageClass: s.storageClass,
 	})
 	return &s.chunks[len(s.chunks)-1]
 }
@@ -516,6 +587,7 @@ func (s *stream) Bounds() (from, to time.Time) {
 func (s *stream) Iterator(ctx context.Context, statsCtx *stats.Context, from, through time.Time, direction logproto.Direction, pipeline log.StreamPipeline) (iter.EntryIterator, error) {
 	s.chunkMtx.RLock()
 	defer s.chunkMtx.RUnlock()
+	ctx = s.withBlockCache(ctx)
 	iterators := make([]iter.EntryIterator, 0, len(s.chunks))
 
 	var lastMax time.Time
@@ -563,6 +635,7 @@ func (s *stream) Iterator(ctx context.Context, statsCtx *stats.Context, from, th
 func (s *stream) SampleIterator(ctx context.Context, statsCtx *stats.Context, from, through time.Time, extractor log.StreamSampleExtractor) (iter.SampleIterator, error) {
 	s.chunkMtx.RLock()
 	defer s.chunkMtx.RUnlock()
+	ctx = s.withBlockCache(ctx)
 	iterators := make([]iter.SampleIterator, 0, len(s.chunks))
 
 	var lastMax time.Time
@@ -596,6 +669,14 @@ func (s *stream) SampleIterator(ctx context.Context, statsCtx *stats.Context, fr
 	return iter.NewSortSampleIterator(iterators), nil
 }
 
+// withBlockCache makes the chunk iterators created with the context use the block cache.
+func (s *stream) withBlockCache(ctx context.Context) context.Context {
+	if s.blockCache == nil {
+		return ctx
+	}
+	return chunkenc.WithBlockCache(ctx, s.blockCache)
+}
+
 func (s *stream) addTailer(t *tailer) {
 	s.tailerMtx.Lock()
 	defer s.tailerMtx.Unlock()
@@ -615,3 +696,12 @@ func headBlockType(chunkfmt byte, unorderedWrites bool) chunkenc.HeadBlockFmt {
 	}
 	return chunkenc.OrderedHeadBlockFmt
 }
+
+// isBucketedHeadSwitch returns true if the head block format from is the bucketed
+// variant of the head block format to, or conversely.
+func isBucketedHeadSwitch(from, to chunkenc.HeadBlockFmt) bool {
+	isSwitchable := func(f chunkenc.HeadBlockFmt) bool {
+		return f == chunkenc.UnorderedWithStructuredMetadataColumnsHeadBlockFmt || f == chunkenc.BucketedHeadBlockFmt
+	}
+	return from != to && isSwitchable(from) && isSwitchable(to)
+}
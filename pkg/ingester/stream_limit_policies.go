@@ -0,0 +1,146 @@
+package ingester
+
+import (
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+// policyStreamsTracker keeps track of the active streams of a tenant matching
+// each of its stream limit policies, and of the label values used by them.
+type policyStreamsTracker struct {
+	mtx sync.Mutex
+	// streams is the number of active streams per policy name.
+	streams map[string]int
+	// labelValues holds, per policy name and label name, the number of active
+	// streams using each label value.
+	labelValues map[string]map[string]map[string]int
+}
+
+func newPolicyStreamsTracker() *policyStreamsTracker {
+	return &policyStreamsTracker{
+		streams:     map[string]int{},
+		labelValues: map[string]map[string]map[string]int{},
+	}
+}
+
+// tryAdd registers a new stream with the given labels for all the given
+// policies. If enforce is true and registering the stream would exceed a limit
+// of one of the policies, nothing is registered and the offending policy is
+// returned together with the discard reason and the error.
+func (t *policyStreamsTracker) tryAdd(tenant string, lbs labels.Labels, policies []*validation.StreamLimitPolicy, limiter *Limiter, enforce bool) (*validation.StreamLimitPolicy, string, error) {
+	if len(policies) == 0 {
+		return nil, "", nil
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if enforce {
+		for _, policy := range policies {
+			if err := limiter.AssertMaxStreamsPerPolicy(tenant, policy, lbs, t.streams[policy.Name]); err != nil {
+				return policy, validation.PolicyStreamLimit, err
+			}
+
+			for _, l := range lbs {
+				if l.Name == ShardLbName {
+					continue
+				}
+				values := t.labelValues[policy.Name][l.Name]
+				if _, ok := values[l.Value]; ok {
+					continue
+				}
+				if err := limiter.AssertMaxLabelValuesPerPolicy(tenant, policy, lbs, l.Name, len(values)); err != nil {
+					return policy, validation.PolicyLabelValuesLimit, err
+				}
+			}
+		}
+	}
+
+	for _, policy := range policies {
+		t.streams[policy.Name]++
+
+		byName, ok := t.labelValues[policy.Name]
+		if !ok {
+			byName = map[string]map[string]int{}
+			t.labelValues[policy.Name] = byName
+		}
+		for _, l := range lbs {
+			if l.Name == ShardLbName {
+				continue
+			}
+			values, ok := byName[l.Name]
+			if !ok {
+				values = map[string]int{}
+				byName[l.Name] = values
+			}
+			values[l.Value]++
+		}
+	}
+
+	return nil, "", nil
+}
+
+// remove unregisters a stream previously added for the given policy names.
+func (t *policyStreamsTracker) remove(lbs labels.Labels, policyNames []string) {
+	if len(policyNames) == 0 {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for _, name := range policyNames {
+		if t.streams[name]--; t.streams[name] <= 0 {
+			delete(t.streams, name)
+		}
+
+		byName := t.labelValues[name]
+		for _, l := range lbs {
+			values, ok := byName[l.Name]
+			if !ok {
+				continue
+			}
+			if values[l.Value]--; values[l.Value] <= 0 {
+				delete(values, l.Value)
+			}
+			if len(values) == 0 {
+				delete(byName, l.Name)
+			}
+		}
+		if len(byName) == 0 {
+			delete(t.labelValues, name)
+		}
+	}
+}
+
+// streamLimitPolicies returns the stream limit policies of the tenant matching
+// the given stream labels.
+func streamLimitPolicies(limits Limits, tenant string, lbs labels.Labels) []*validation.StreamLimitPolicy {
+	policies := limits.StreamLimitPolicies(tenant)
+	if len(policies) == 0 {
+		return nil
+	}
+
+	var matching []*validation.StreamLimitPolicy
+	for i := range policies {
+		if policies[i].Matches(lbs) {
+			matching = append(matching, &policies[i])
+		}
+	}
+	return matching
+}
+
+func policyNames(policies []*validation.StreamLimitPolicy) []string {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+	}
+	return names
+}
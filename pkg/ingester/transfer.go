@@ -0,0 +1,270 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// TransferChunks receives the in-memory streams of a leaving ingester and takes over its
+// tokens. The ingester must be in the PENDING state or else the call fails.
+func (i *Ingester) TransferChunks(stream logproto.Ingester_TransferChunksServer) error {
+	logger := util_log.WithContext(stream.Context(), i.logger)
+	// Prevent a shutdown from happening until we've completely finished a handoff
+	// from a leaving ingester.
+	i.shutdownMtx.Lock()
+	defer i.shutdownMtx.Unlock()
+
+	// Enter the JOINING state, only valid from PENDING.
+	if err := i.lifecycler.ChangeState(stream.Context(), ring.JOINING); err != nil {
+		return err
+	}
+
+	// The ingester state effectively works as a mutex around the handoff, go back to PENDING
+	// on failure to let the leaving ingester retry.
+	defer func() {
+		if state := i.lifecycler.GetState(); state == ring.JOINING {
+			level.Info(logger).Log("msg", "chunks transfer failed, going back to the PENDING state")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := i.lifecycler.ChangeState(ctx, ring.PENDING); err != nil {
+				level.Error(logger).Log("msg", "failed to go back to the PENDING state after a chunks transfer failure", "err", err)
+			}
+		}
+	}()
+
+	// The streams of the leaving ingester were already accepted by the limiter.
+	i.limiter.DisableForWALReplay()
+	defer i.limiter.Enable()
+
+	fromIngesterID := ""
+	streamsReceived := 0
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		// The ID of the leaving ingester is repeated in every request of the stream.
+		if fromIngesterID == "" {
+			fromIngesterID = req.FromIngesterID
+			level.Info(logger).Log("msg", "receiving chunks transfer", "from_ingester", fromIngesterID)
+
+			// Make sure the leaving ingester is in the right state to claim its tokens at the end.
+			if err := i.checkFromIngesterIsLeaving(stream.Context(), fromIngesterID); err != nil {
+				return err
+			}
+		}
+
+		var series Series
+		if err := series.Unmarshal(req.Series); err != nil {
+			return errors.Wrap(err, "decoding transferred stream")
+		}
+		if err := i.receiveStream(stream.Context(), &series); err != nil {
+			return err
+		}
+		streamsReceived++
+		i.metrics.receivedChunks.Add(float64(len(series.Chunks)))
+	}
+
+	if fromIngesterID == "" {
+		return errors.New("chunks transfer without streams")
+	}
+
+	// The transferred streams did not go through the WAL of this ingester.
+	if err := i.wal.Checkpoint(); err != nil {
+		return errors.Wrap(err, "checkpointing transferred streams")
+	}
+
+	if err := i.lifecycler.ClaimTokensFor(stream.Context(), fromIngesterID); err != nil {
+		return errors.Wrap(err, "claiming tokens")
+	}
+	if err := i.lifecycler.ChangeState(stream.Context(), ring.ACTIVE); err != nil {
+		return errors.Wrap(err, "changing state to ACTIVE")
+	}
+
+	// Close the stream last, as this is what tells the leaving ingester that it can shut down.
+	if err := stream.SendAndClose(&logproto.TransferChunksResponse{}); err != nil {
+		level.Error(logger).Log("msg", "failed to close the chunks transfer", "from_ingester", fromIngesterID, "err", err)
+		return err
+	}
+	level.Info(logger).Log("msg", "chunks transfer received", "from_ingester", fromIngesterID, "streams", streamsReceived)
+	return nil
+}
+
+// receiveStream restores a stream transferred by a leaving ingester, like the streams of
+// the WAL checkpoints.
+func (i *Ingester) receiveStream(ctx context.Context, series *Series) error {
+	inst, err := i.GetOrCreateInstance(series.UserID)
+	if err != nil {
+		return err
+	}
+
+	s, err := inst.getOrCreateStream(user.InjectOrgID(ctx, series.UserID), logproto.Stream{
+		Labels: logproto.FromLabelAdaptersToLabels(series.Labels).String(),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := s.setChunks(series.Chunks); err != nil {
+		return err
+	}
+	s.chunkMtx.Lock()
+	s.lastLine.ts = series.To
+	s.lastLine.content = series.LastLine
+	s.entryCt = series.EntryCt
+	s.highestTs = series.HighestTs
+	s.chunkMtx.Unlock()
+
+	i.metrics.memoryChunks.Add(float64(len(series.Chunks)))
+	return nil
+}
+
+func (i *Ingester) checkFromIngesterIsLeaving(ctx context.Context, fromIngesterID string) error {
+	desc, err := i.lifecycler.KVStore.Get(ctx, i.lifecycler.RingKey)
+	if err != nil {
+		return err
+	}
+	r, ok := desc.(*ring.Desc)
+	if !ok || r == nil {
+		return errors.New("ingester ring not found")
+	}
+	from, ok := r.Ingesters[fromIngesterID]
+	if !ok {
+		return fmt.Errorf("ingester %s not found in the ring", fromIngesterID)
+	}
+	if from.State != ring.LEAVING {
+		return fmt.Errorf("ingester %s is in the %s state, expected LEAVING", fromIngesterID, from.State)
+	}
+	return nil
+}
+
+// TransferOut implements ring.FlushTransferer
+// It hands off the in-memory streams to a pending ingester, which takes over the tokens of
+// this ingester, instead of flushing them. ErrTransferDisabled is returned when transfers are
+// disabled, in which case the streams may be flushed on shutdown if configured to do so.
+func (i *Ingester) TransferOut(ctx context.Context) error {
+	if i.cfg.MaxTransferRetries <= 0 {
+		return ring.ErrTransferDisabled
+	}
+
+	// Stop the flush loops first, so that the chunks don't change while they're transferred.
+	// Their streams are flushed again by Flush if the transfer fails.
+	for _, flushQueue := range i.flushQueues {
+		flushQueue.DiscardAndClose()
+	}
+	i.flushQueuesDone.Wait()
+
+	backoff := backoff.New(ctx, backoff.Config{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		MaxRetries: i.cfg.MaxTransferRetries,
+	})
+	for backoff.Ongoing() {
+		err := i.transferOut(ctx)
+		if err == nil {
+			return nil
+		}
+		level.Error(i.logger).Log("msg", "chunks transfer failed", "err", err)
+		backoff.Wait()
+	}
+
+	// Restart the flush loops, the streams are still owned by this ingester.
+	i.InitFlushQueues()
+	return backoff.Err()
+}
+
+func (i *Ingester) transferOut(ctx context.Context) error {
+	target, err := i.findTransferTarget(ctx)
+	if err != nil {
+		return errors.Wrap(err, "finding an ingester to transfer chunks to")
+	}
+	level.Info(i.logger).Log("msg", "transferring chunks", "to_ingester", target.Addr)
+
+	c, err := i.cfg.ingesterClientFactory(i.clientConfig, target.Addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	ic, ok := c.(logproto.IngesterClient)
+	if !ok {
+		return fmt.Errorf("ingester client %T does not support chunks transfers", c)
+	}
+
+	// The transfer carries the streams of all the tenants.
+	s, err := ic.TransferChunks(user.InjectOrgID(ctx, "-1"))
+	if err != nil {
+		return errors.Wrap(err, "TransferChunks")
+	}
+
+	var buf []byte
+	it := newStreamsIterator(i)
+	for it.Next() {
+		series := it.Stream()
+		if cap(buf) < series.Size() {
+			buf = make([]byte, series.Size())
+		}
+		n, err := series.MarshalTo(buf[:series.Size()])
+		if err != nil {
+			return err
+		}
+		// One stream is sent at a time, to keep the requests under the gRPC message size limits.
+		if err := s.Send(&logproto.TransferChunksRequest{
+			FromIngesterID: i.lifecycler.ID,
+			Series:         buf[:n],
+		}); err != nil {
+			return errors.Wrap(err, "sending stream")
+		}
+		i.metrics.sentChunks.Add(float64(len(series.Chunks)))
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	if _, err := s.CloseAndRecv(); err != nil {
+		return errors.Wrap(err, "closing the chunks transfer")
+	}
+
+	// The receiving ingester checkpointed the streams, they must not be replayed from the WAL
+	// of this ingester if it restarts with the same disk.
+	if i.cfg.WAL.Enabled {
+		if err := os.RemoveAll(i.cfg.WAL.Dir); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to remove the WAL after the chunks transfer", "dir", i.cfg.WAL.Dir, "err", err)
+		}
+	}
+
+	level.Info(i.logger).Log("msg", "chunks transferred", "to_ingester", target.Addr)
+	return nil
+}
+
+// findTransferTarget finds a pending ingester to transfer the chunks to.
+func (i *Ingester) findTransferTarget(ctx context.Context) (*ring.InstanceDesc, error) {
+	desc, err := i.lifecycler.KVStore.Get(ctx, i.lifecycler.RingKey)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := desc.(*ring.Desc)
+	if !ok || r == nil {
+		return nil, errors.New("ingester ring not found")
+	}
+
+	ingesters := r.FindIngestersByState(ring.PENDING)
+	if len(ingesters) == 0 {
+		return nil, errors.New("no pending ingester")
+	}
+	return &ingesters[0], nil
+}
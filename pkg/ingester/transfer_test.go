@@ -0,0 +1,180 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	gokit_log "github.com/go-kit/log"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestTransferOut(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	// Both ingesters share the ring.
+	cfg := defaultIngesterTestConfigWithWAL(t, t.TempDir())
+	cfg.LifecyclerConfig.ID = "ingester0"
+	cfg.MaxTransferRetries = 1
+	var receiver *Ingester
+	cfg.ingesterClientFactory = func(_ client.Config, _ string) (client.HealthAndIngesterClient, error) {
+		return &testTransferClient{ing: receiver}, nil
+	}
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+	sender, err := New(cfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, gokit_log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), sender))
+
+	req := logproto.PushRequest{
+		Streams: []logproto.Stream{
+			{Labels: `{foo="bar",bar="baz1"}`},
+			{Labels: `{foo="bar",bar="baz2"}`},
+		},
+	}
+	start := time.Now()
+	steps := 10
+	end := start.Add(time.Second * time.Duration(steps))
+	for i := 0; i < steps; i++ {
+		for j := range req.Streams {
+			req.Streams[j].Entries = append(req.Streams[j].Entries, logproto.Entry{
+				Timestamp: start.Add(time.Duration(i) * time.Second),
+				Line:      fmt.Sprintf("line %d", i),
+			})
+		}
+	}
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err = sender.Push(ctx, &req)
+	require.NoError(t, err)
+
+	receiverCfg := cfg
+	receiverCfg.LifecyclerConfig.ID = "ingester1"
+	receiverCfg.LifecyclerConfig.Addr = "ingester1"
+	// Wait for the handoff instead of joining the ring.
+	receiverCfg.LifecyclerConfig.JoinAfter = time.Hour
+	receiverCfg.WAL.Dir = t.TempDir()
+	receiver, err = New(receiverCfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, gokit_log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), receiver))
+	defer services.StopAndAwaitTerminated(context.Background(), receiver) //nolint:errcheck
+	require.Eventually(t, func() bool {
+		return receiver.lifecycler.GetState() == ring.PENDING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), sender))
+
+	// The receiver owns the streams and the tokens of the sender.
+	require.Equal(t, ring.ACTIVE, receiver.lifecycler.GetState())
+	desc, err := receiver.lifecycler.KVStore.Get(context.Background(), receiver.lifecycler.RingKey)
+	require.NoError(t, err)
+	ingesters := desc.(*ring.Desc).Ingesters
+	require.NotContains(t, ingesters, "ingester0")
+	require.Len(t, ingesters["ingester1"].Tokens, cfg.LifecyclerConfig.NumTokens)
+	ensureIngesterData(ctx, t, start, end, receiver)
+
+	// The streams were neither flushed nor left in the WAL of the sender, but checkpointed by the receiver.
+	require.Empty(t, store.chunks)
+	_, err = os.Stat(cfg.WAL.Dir)
+	require.True(t, os.IsNotExist(err))
+	_, idx, err := lastCheckpoint(receiverCfg.WAL.Dir)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, idx, 0)
+}
+
+func TestTransferOut_FlushesOnFailure(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	cfg := defaultIngesterTestConfigWithWAL(t, t.TempDir())
+	cfg.WAL.FlushOnShutdown = true
+	cfg.MaxTransferRetries = 1
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+	ing, err := New(cfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, gokit_log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	_, err = ing.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  `{foo="bar"}`,
+		Entries: []logproto.Entry{{Timestamp: time.Now(), Line: "line"}},
+	}}})
+	require.NoError(t, err)
+
+	// There is no pending ingester, the streams are flushed by the restarted flush loops.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	require.Len(t, store.chunks["test"], 1)
+}
+
+// testTransferClient calls the TransferChunks handler of an ingester in process.
+type testTransferClient struct {
+	grpc_health_v1.HealthClient
+	ing *Ingester
+}
+
+func (c *testTransferClient) Close() error { return nil }
+
+func (c *testTransferClient) TransferChunks(ctx context.Context, _ ...grpc.CallOption) (logproto.Ingester_TransferChunksClient, error) {
+	reqs := make(chan *logproto.TransferChunksRequest)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.ing.TransferChunks(&testTransferServer{ctx: ctx, reqs: reqs})
+	}()
+	return &testTransferStream{reqs: reqs, done: done}, nil
+}
+
+type testTransferStream struct {
+	grpc.ClientStream
+	reqs chan<- *logproto.TransferChunksRequest
+	done <-chan error
+}
+
+func (s *testTransferStream) Send(req *logproto.TransferChunksRequest) error {
+	s.reqs <- req
+	return nil
+}
+
+func (s *testTransferStream) CloseAndRecv() (*logproto.TransferChunksResponse, error) {
+	close(s.reqs)
+	if err := <-s.done; err != nil {
+		return nil, err
+	}
+	return &logproto.TransferChunksResponse{}, nil
+}
+
+type testTransferServer struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs <-chan *logproto.TransferChunksRequest
+}
+
+func (s *testTransferServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testTransferServer) Recv() (*logproto.TransferChunksRequest, error) {
+	req, ok := <-s.reqs
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (s *testTransferServer) SendAndClose(*logproto.TransferChunksResponse) error {
+	return nil
+}
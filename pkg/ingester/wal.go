@@ -29,13 +29,22 @@ type WALConfig struct {
 	CheckpointDuration  time.Duration    `yaml:"checkpoint_duration"`
 	FlushOnShutdown     bool             `yaml:"flush_on_shutdown"`
 	ReplayMemoryCeiling flagext.ByteSize `yaml:"replay_memory_ceiling"`
+	Compression         string           `yaml:"compression" category:"experimental"`
 }
 
 func (cfg *WALConfig) Validate() error {
 	if cfg.Enabled && cfg.CheckpointDuration < 1 {
 		return errors.Errorf("invalid checkpoint duration: %v", cfg.CheckpointDuration)
 	}
-	return nil
+	if !cfg.Enabled {
+		return nil
+	}
+	switch wlog.CompressionType(cfg.Compression) {
+	case wlog.CompressionNone, wlog.CompressionSnappy, wlog.CompressionZstd:
+		return nil
+	default:
+		return errors.Errorf("invalid WAL compression: %q, supported values: %s, %s, %s", cfg.Compression, wlog.CompressionNone, wlog.CompressionSnappy, wlog.CompressionZstd)
+	}
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet
@@ -48,6 +57,7 @@ func (cfg *WALConfig) RegisterFlags(f *flag.FlagSet) {
 	// Need to set default here
 	cfg.ReplayMemoryCeiling = flagext.ByteSize(defaultCeiling)
 	f.Var(&cfg.ReplayMemoryCeiling, "ingester.wal-replay-memory-ceiling", "Maximum memory size the WAL may use during replay. After hitting this, it will flush data to storage before continuing. A unit suffix (KB, MB, GB) may be applied.")
+	f.StringVar(&cfg.Compression, "ingester.wal-compression", string(wlog.CompressionNone), "Compression of the records of the WAL and its checkpoints. Supported values: 'none', 'snappy' and 'zstd'. The WAL can be replayed whatever the compression of its records, so it can be changed between restarts.")
 }
 
 // WAL interface allows us to have a no-op WAL when the WAL is disabled.
@@ -55,6 +65,8 @@ type WAL interface {
 	Start()
 	// Log marshalls the records and writes it into the WAL.
 	Log(*wal.Record) error
+	// Checkpoint writes a checkpoint of all the in-memory streams right away.
+	Checkpoint() error
 	// Stop stops all the WAL operations.
 	Stop() error
 }
@@ -63,13 +75,15 @@ type noopWAL struct{}
 
 func (noopWAL) Start()                {}
 func (noopWAL) Log(*wal.Record) error { return nil }
+func (noopWAL) Checkpoint() error     { return nil }
 func (noopWAL) Stop() error           { return nil }
 
 type walWrapper struct {
-	cfg        WALConfig
-	wal        *wlog.WL
-	metrics    *ingesterMetrics
-	seriesIter SeriesIter
+	cfg          WALConfig
+	wal          *wlog.WL
+	metrics      *ingesterMetrics
+	seriesIter   SeriesIter
+	checkpointer *Checkpointer
 
 	wait sync.WaitGroup
 	quit chan struct{}
@@ -81,7 +95,7 @@ func newWAL(cfg WALConfig, registerer prometheus.Registerer, metrics *ingesterMe
 		return noopWAL{}, nil
 	}
 
-	tsdbWAL, err := wlog.NewSize(util_log.Logger, registerer, cfg.Dir, walSegmentSize, wlog.CompressionNone)
+	tsdbWAL, err := wlog.NewSize(util_log.Logger, registerer, cfg.Dir, walSegmentSize, wlog.CompressionType(cfg.Compression))
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +107,13 @@ func newWAL(cfg WALConfig, registerer prometheus.Registerer, metrics *ingesterMe
 		metrics:    metrics,
 		seriesIter: seriesIter,
 	}
+	w.checkpointer = NewCheckpointer(
+		w.cfg.CheckpointDuration,
+		w.seriesIter,
+		w.checkpointWriter(),
+		w.metrics,
+		w.quit,
+	)
 
 	return w, nil
 }
@@ -137,6 +158,12 @@ func (w *walWrapper) Log(record *wal.Record) error {
 	}
 }
 
+// Checkpoint makes the in-memory streams durable, even the data that did not go through the
+// WAL, like the streams handed off by a leaving ingester.
+func (w *walWrapper) Checkpoint() error {
+	return w.checkpointer.CheckpointNow()
+}
+
 func (w *walWrapper) Stop() error {
 	close(w.quit)
 	w.wait.Wait()
@@ -156,13 +183,6 @@ func (w *walWrapper) run() {
 	level.Info(util_log.Logger).Log("msg", "started", "component", "wal")
 	defer w.wait.Done()
 
-	checkpointer := NewCheckpointer(
-		w.cfg.CheckpointDuration,
-		w.seriesIter,
-		w.checkpointWriter(),
-		w.metrics,
-		w.quit,
-	)
-	checkpointer.Run()
+	w.checkpointer.Run()
 
 }
@@ -0,0 +1,246 @@
+package ingester
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+)
+
+// The layout of the pages of the WAL segments, see the wlog package. The records are split
+// in fragments at the page boundaries, so a page always starts with a new fragment.
+const (
+	walPageSize         = 32 * 1024
+	walRecordHeaderSize = 7
+	walRecordTypeMask   = 1<<3 - 1
+	walRecordFull       = 1
+	walRecordMiddle     = 3
+	walRecordLast       = 4
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// segmentsReader reads the records of the segments of a WAL or checkpoint directory.
+// The fragments of the records are verified with their CRC by the wlog reader. Instead of
+// stopping at the first corrupt record, the segmentsReader reports it and resumes reading
+// at the next page of the segment.
+type segmentsReader struct {
+	dir     string
+	typ     string
+	metrics *ingesterMetrics
+	status  *walStatus
+	logger  log.Logger
+
+	cur, last int
+	segment   *os.File
+	size      int64
+	start     int64 // offset in the segment at which reader started.
+	reader    *wlog.Reader
+	// skipFirst is true when the first record of the reader is the padding record
+	// aligning it on the pages of the segment.
+	skipFirst bool
+	// resumed is true when no record was read since resuming after a corrupt record,
+	// in which case the remaining fragments of that record are skipped without
+	// reporting them again.
+	resumed bool
+	err     error
+}
+
+func newSegmentsReader(dir, typ string, metrics *ingesterMetrics, status *walStatus, logger log.Logger) (*segmentsReader, error) {
+	first, last, err := wlog.Segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if first < 0 {
+		// No segment to read.
+		first, last = 0, -1
+	}
+	status.startReplayPhase(typ, first, last)
+
+	return &segmentsReader{
+		dir:     dir,
+		typ:     typ,
+		metrics: metrics,
+		status:  status,
+		logger:  logger,
+		cur:     first,
+		last:    last,
+	}, nil
+}
+
+func (r *segmentsReader) Next() bool {
+	for r.err == nil {
+		if r.reader == nil {
+			if r.cur > r.last {
+				return false
+			}
+			if err := r.openSegment(); err != nil {
+				r.err = err
+				return false
+			}
+		}
+
+		if r.reader.Next() {
+			if r.skipFirst {
+				r.skipFirst = false
+				continue
+			}
+			r.resumed = false
+			r.status.recordReplayed(r.typ, r.cur, r.start+r.reader.Offset())
+			return true
+		}
+		if err := r.reader.Err(); err != nil {
+			r.skip(err)
+			continue
+		}
+		r.nextSegment()
+	}
+	return false
+}
+
+// skip reports the corrupt record and resumes reading at the first record starting in
+// the next pages of the segment.
+func (r *segmentsReader) skip(err error) {
+	offset := r.start + r.reader.Offset()
+	var corruptionErr *wlog.CorruptionErr
+	if errors.As(err, &corruptionErr) {
+		err = corruptionErr.Err
+	}
+
+	if !r.resumed {
+		r.metrics.walSkippedRecordsTotal.WithLabelValues(r.typ).Inc()
+		r.status.recordSkipped(r.typ, r.cur, offset, err)
+		level.Warn(r.logger).Log("msg", "skipping corrupt WAL record", "type", r.typ, "dir", r.dir, "segment", r.cur, "offset", offset, "err", err)
+	}
+	r.resumed = true
+
+	page := (offset + walPageSize - 1) / walPageSize * walPageSize
+	if page <= r.start {
+		page = r.start + walPageSize
+	}
+	r.resume(page)
+}
+
+// resume resumes reading at the first record starting in the page at the given offset
+// or in the following ones.
+func (r *segmentsReader) resume(page int64) {
+	// Skip the fragments of the record started in the previous pages.
+	start := page
+	hdr := make([]byte, walRecordHeaderSize)
+	for start < r.size {
+		if _, err := r.segment.ReadAt(hdr, start); err != nil {
+			break
+		}
+		typ := hdr[0] & walRecordTypeMask
+		if typ != walRecordMiddle && typ != walRecordLast {
+			break
+		}
+		start += walRecordHeaderSize + int64(binary.BigEndian.Uint16(hdr[1:]))
+	}
+	if start >= r.size {
+		r.nextSegment()
+		return
+	}
+
+	// The wlog reader expects to start at the beginning of a page, the skipped
+	// fragments are replaced by a padding record of the same size.
+	var reader io.Reader = io.NewSectionReader(r.segment, start, r.size-start)
+	padding := start % walPageSize
+	r.skipFirst = padding > 0
+	if padding > 0 {
+		if padding < walRecordHeaderSize {
+			// The length of the skipped fragments is corrupt.
+			r.resume(start - padding + walPageSize)
+			return
+		}
+		reader = io.MultiReader(bytes.NewReader(paddingRecord(int(padding))), reader)
+	}
+	r.start = start - padding
+	r.reader = wlog.NewReader(reader)
+}
+
+// paddingRecord returns a record of the given size, header included.
+func paddingRecord(size int) []byte {
+	rec := make([]byte, size)
+	rec[0] = walRecordFull
+	binary.BigEndian.PutUint16(rec[1:], uint16(size-walRecordHeaderSize))
+	binary.BigEndian.PutUint32(rec[3:], crc32.Checksum(rec[walRecordHeaderSize:], castagnoliTable))
+	return rec
+}
+
+func (r *segmentsReader) openSegment() error {
+	segment, err := os.Open(wlog.SegmentName(r.dir, r.cur))
+	if err != nil {
+		return err
+	}
+	info, err := segment.Stat()
+	if err != nil {
+		segment.Close()
+		return err
+	}
+
+	r.segment = segment
+	r.size = info.Size()
+	r.start = 0
+	r.skipFirst = false
+	r.reader = wlog.NewReader(io.NewSectionReader(segment, 0, r.size))
+	return nil
+}
+
+func (r *segmentsReader) nextSegment() {
+	if err := r.closeSegment(); err != nil {
+		r.err = err
+		return
+	}
+	r.cur++
+}
+
+func (r *segmentsReader) closeSegment() error {
+	r.reader = nil
+	if r.segment == nil {
+		return nil
+	}
+	err := r.segment.Close()
+	r.segment = nil
+	return err
+}
+
+func (r *segmentsReader) Err() error {
+	return r.err
+}
+
+func (r *segmentsReader) Record() []byte {
+	return r.reader.Record()
+}
+
+func (r *segmentsReader) Close() error {
+	return r.closeSegment()
+}
+
+// segmentSizes returns the size of the segments of a WAL or checkpoint directory.
+func segmentSizes(dir string) ([]walSegmentStatus, error) {
+	first, last, err := wlog.Segments(dir)
+	if err != nil || first < 0 {
+		return nil, err
+	}
+
+	segments := make([]walSegmentStatus, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		info, err := os.Stat(wlog.SegmentName(dir, i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Removed by a checkpoint in the meantime.
+				continue
+			}
+			return nil, err
+		}
+		segments = append(segments, walSegmentStatus{Segment: i, Size: info.Size()})
+	}
+	return segments, nil
+}
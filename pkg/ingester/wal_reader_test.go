@@ -0,0 +1,113 @@
+package ingester
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+func writeTestSegments(t *testing.T, dir string, compression wlog.CompressionType, n int) [][]byte {
+	w, err := wlog.NewSize(log.NewNopLogger(), nil, dir, walSegmentSize, compression)
+	require.NoError(t, err)
+
+	// Random records of 5KB, that are not compressible.
+	rnd := rand.New(rand.NewSource(0))
+	var records [][]byte
+	for i := 0; i < n; i++ {
+		rec := make([]byte, 5<<10)
+		_, _ = rnd.Read(rec)
+		require.NoError(t, w.Log(rec))
+		records = append(records, rec)
+	}
+	require.NoError(t, w.Close())
+	return records
+}
+
+func TestSegmentsReader_SkipsCorruptRecords(t *testing.T) {
+	for _, compression := range []wlog.CompressionType{wlog.CompressionNone, wlog.CompressionSnappy, wlog.CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			dir := t.TempDir()
+			records := writeTestSegments(t, dir, compression, 100)
+
+			// Corrupt a byte in the middle of the segment.
+			f, err := os.OpenFile(wlog.SegmentName(dir, 0), os.O_RDWR, 0)
+			require.NoError(t, err)
+			info, err := f.Stat()
+			require.NoError(t, err)
+			b := make([]byte, 1)
+			_, err = f.ReadAt(b, info.Size()/2)
+			require.NoError(t, err)
+			b[0] ^= 0xff
+			_, err = f.WriteAt(b, info.Size()/2)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			metrics := newIngesterMetrics(prometheus.NewRegistry(), constants.Loki)
+			status := newWALStatus()
+			r, err := newSegmentsReader(dir, walTypeSegment, metrics, status, log.NewNopLogger())
+			require.NoError(t, err)
+			defer r.Close()
+
+			var read [][]byte
+			for r.Next() {
+				read = append(read, append([]byte(nil), r.Record()...))
+			}
+			require.NoError(t, r.Err())
+
+			// Only the corrupt record, and the records following it in the corrupt page, are lost.
+			require.GreaterOrEqual(t, len(read), len(records)-walPageSize/(5<<10)-1)
+			require.Less(t, len(read), len(records))
+			j := 0
+			for _, rec := range read {
+				for j < len(records) && string(records[j]) != string(rec) {
+					j++
+				}
+				require.Less(t, j, len(records), "unexpected record")
+			}
+			require.Equal(t, records[len(records)-1], read[len(read)-1])
+
+			require.Equal(t, 1.0, testutil.ToFloat64(metrics.walSkippedRecordsTotal.WithLabelValues(walTypeSegment)))
+			replay := status.replayStatus()
+			require.Equal(t, int64(len(read)), replay.RecordsReplayed[walTypeSegment])
+			require.Equal(t, int64(1), replay.RecordsSkipped[walTypeSegment])
+			require.Len(t, replay.SkippedRecords, 1)
+			require.Equal(t, 0, replay.SkippedRecords[0].Segment)
+		})
+	}
+}
+
+func TestWALStatusHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSegments(t, dir, wlog.CompressionSnappy, 10)
+
+	cfg := defaultIngesterTestConfigWithWAL(t, dir)
+	cfg.WAL.Compression = string(wlog.CompressionSnappy)
+	i := &Ingester{cfg: cfg, walStatus: newWALStatus()}
+	i.walStatus.startReplay()
+	i.walStatus.recordReplayed(walTypeSegment, 0, 1024)
+
+	w := httptest.NewRecorder()
+	i.WALStatusHandler(w, httptest.NewRequest("GET", "/ingester/wal/status", nil))
+	require.Equal(t, 200, w.Code)
+
+	var resp walStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.Enabled)
+	require.Equal(t, "snappy", resp.Compression)
+	require.Len(t, resp.Segments, 1)
+	require.Greater(t, resp.Segments[0].Size, int64(0))
+	require.Empty(t, resp.Checkpoint)
+	require.Equal(t, walReplayRunning, resp.Replay.State)
+	require.Equal(t, int64(1), resp.Replay.RecordsReplayed[walTypeSegment])
+	require.Equal(t, int64(1024), resp.Replay.Offset)
+}
@@ -0,0 +1,172 @@
+package ingester
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/util"
+)
+
+const (
+	walReplayPending  = "pending"
+	walReplayRunning  = "replaying"
+	walReplayFinished = "finished"
+
+	// maxSkippedRecords is the maximum number of skipped records listed by the WAL status.
+	maxSkippedRecords = 100
+)
+
+// walStatus tracks the replay of the WAL for the /ingester/wal/status endpoint.
+type walStatus struct {
+	mtx    sync.Mutex
+	replay walReplayStatus
+}
+
+type walReplayStatus struct {
+	State      string     `json:"state"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Type is the type of the segments being replayed, either checkpoint or segment.
+	Type        string `json:"type,omitempty"`
+	Segment     int    `json:"segment"`
+	LastSegment int    `json:"last_segment"`
+	Offset      int64  `json:"offset"`
+
+	RecordsReplayed map[string]int64 `json:"records_replayed"`
+	RecordsSkipped  map[string]int64 `json:"records_skipped"`
+	// SkippedRecords lists the first skipped records.
+	SkippedRecords []walSkippedRecord `json:"skipped_records"`
+}
+
+type walSkippedRecord struct {
+	Type    string `json:"type"`
+	Segment int    `json:"segment"`
+	Offset  int64  `json:"offset"`
+	Error   string `json:"error"`
+}
+
+type walSegmentStatus struct {
+	Segment int   `json:"segment"`
+	Size    int64 `json:"size"`
+}
+
+type walStatusResponse struct {
+	Enabled            bool               `json:"enabled"`
+	Dir                string             `json:"dir,omitempty"`
+	Compression        string             `json:"compression,omitempty"`
+	Segments           []walSegmentStatus `json:"segments,omitempty"`
+	Checkpoint         string             `json:"checkpoint,omitempty"`
+	CheckpointSegments []walSegmentStatus `json:"checkpoint_segments,omitempty"`
+	Replay             *walReplayStatus   `json:"replay,omitempty"`
+}
+
+func newWALStatus() *walStatus {
+	return &walStatus{
+		replay: walReplayStatus{
+			State:           walReplayPending,
+			RecordsReplayed: map[string]int64{},
+			RecordsSkipped:  map[string]int64{},
+		},
+	}
+}
+
+func (s *walStatus) startReplay() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.replay.State = walReplayRunning
+	now := time.Now()
+	s.replay.StartedAt = &now
+}
+
+func (s *walStatus) finishReplay() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.replay.State = walReplayFinished
+	now := time.Now()
+	s.replay.FinishedAt = &now
+}
+
+func (s *walStatus) startReplayPhase(typ string, first, last int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.replay.Type = typ
+	s.replay.Segment = first
+	s.replay.LastSegment = last
+	s.replay.Offset = 0
+}
+
+func (s *walStatus) recordReplayed(typ string, segment int, offset int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.replay.Segment = segment
+	s.replay.Offset = offset
+	s.replay.RecordsReplayed[typ]++
+}
+
+func (s *walStatus) recordSkipped(typ string, segment int, offset int64, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.replay.RecordsSkipped[typ]++
+	if len(s.replay.SkippedRecords) < maxSkippedRecords {
+		s.replay.SkippedRecords = append(s.replay.SkippedRecords, walSkippedRecord{
+			Type:    typ,
+			Segment: segment,
+			Offset:  offset,
+			Error:   err.Error(),
+		})
+	}
+}
+
+func (s *walStatus) replayStatus() walReplayStatus {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	replay := s.replay
+	replay.RecordsReplayed = make(map[string]int64, len(s.replay.RecordsReplayed))
+	for typ, n := range s.replay.RecordsReplayed {
+		replay.RecordsReplayed[typ] = n
+	}
+	replay.RecordsSkipped = make(map[string]int64, len(s.replay.RecordsSkipped))
+	for typ, n := range s.replay.RecordsSkipped {
+		replay.RecordsSkipped[typ] = n
+	}
+	replay.SkippedRecords = append([]walSkippedRecord(nil), s.replay.SkippedRecords...)
+	return replay
+}
+
+// WALStatusHandler shows the size of the segments of the WAL and of its last checkpoint,
+// the progress of the replay and the corrupt records skipped by it.
+func (i *Ingester) WALStatusHandler(w http.ResponseWriter, _ *http.Request) {
+	if !i.cfg.WAL.Enabled {
+		util.WriteJSONResponse(w, walStatusResponse{})
+		return
+	}
+
+	resp := walStatusResponse{
+		Enabled:     true,
+		Dir:         i.cfg.WAL.Dir,
+		Compression: i.cfg.WAL.Compression,
+	}
+	var err error
+	if resp.Segments, err = segmentSizes(i.cfg.WAL.Dir); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	checkpointDir, idx, err := lastCheckpoint(i.cfg.WAL.Dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if idx >= 0 {
+		resp.Checkpoint = checkpointDir
+		if resp.CheckpointSegments, err = segmentSizes(checkpointDir); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	replay := i.walStatus.replayStatus()
+	resp.Replay = &replay
+	util.WriteJSONResponse(w, resp)
+}
@@ -0,0 +1,58 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Record is a single Kafka record carrying a marshalled push request. The key
+// of the record is the tenant ID.
+type Record struct {
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// Client is the subset of Kafka operations used by the write path.
+// Offsets committed through it are the offset of the next record to consume.
+type Client interface {
+	// Partitions returns the number of partitions of the topic.
+	Partitions() (int32, error)
+	// Produce synchronously writes the records to their partition.
+	Produce(ctx context.Context, records []Record) error
+	// Reader returns a reader of the partition starting at the given offset.
+	Reader(partition int32, offset int64) (Reader, error)
+	// CommittedOffset returns the committed offset of the partition, or the
+	// oldest available offset if nothing has been committed yet.
+	CommittedOffset(partition int32) (int64, error)
+	// Commit commits the offset of the partition.
+	Commit(partition int32, offset int64) error
+	Close() error
+}
+
+// Reader reads the records of a single partition in order.
+type Reader interface {
+	// Poll blocks until at least one record is available or the context is
+	// done, and returns at most max records.
+	Poll(ctx context.Context, max int) ([]Record, error)
+	Close() error
+}
+
+var partitionIDRegexp = regexp.MustCompile(`-(\d+)$`)
+
+// PartitionFromID returns the partition owned by the instance with the given
+// ID, e.g. partition 2 for "ingester-zone-a-2".
+func PartitionFromID(id string) (int32, error) {
+	match := partitionIDRegexp.FindStringSubmatch(id)
+	if len(match) != 2 {
+		return 0, fmt.Errorf("instance ID %q does not end with a partition number", id)
+	}
+	partition, err := strconv.ParseInt(match[1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid partition number in instance ID %q: %w", id, err)
+	}
+	return int32(partition), nil
+}
@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionFromID(t *testing.T) {
+	for _, tc := range []struct {
+		id        string
+		partition int32
+		err       bool
+	}{
+		{id: "ingester-0", partition: 0},
+		{id: "ingester-zone-a-12", partition: 12},
+		{id: "loki-write-3", partition: 3},
+		{id: "ingester", err: true},
+		{id: "ingester-a", err: true},
+		{id: "ingester-99999999999", err: true},
+	} {
+		t.Run(tc.id, func(t *testing.T) {
+			partition, err := PartitionFromID(tc.id)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.partition, partition)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"errors"
+	"flag"
+	"time"
+)
+
+var (
+	ErrMissingAddress = errors.New("the Kafka address has not been configured")
+	ErrMissingTopic   = errors.New("the Kafka topic has not been configured")
+)
+
+// Config holds the configuration of the Kafka write path, used by the
+// distributors to write push requests to Kafka and by the ingesters to consume
+// them back.
+type Config struct {
+	Enabled        bool          `yaml:"enabled"`
+	Address        string        `yaml:"address"`
+	Topic          string        `yaml:"topic"`
+	ClientID       string        `yaml:"client_id"`
+	WriteTimeout   time.Duration `yaml:"write_timeout"`
+	ConsumerGroup  string        `yaml:"consumer_group"`
+	CommitInterval time.Duration `yaml:"commit_interval"`
+	MaxFetchBatch  int           `yaml:"max_fetch_batch"`
+}
+
+// RegisterFlags registers the Kafka flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("kafka", f)
+}
+
+// RegisterFlagsWithPrefix registers the Kafka flags with the given prefix.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Experimental: write the pushed streams to Kafka instead of sending them to the ingesters over gRPC. Ingesters consume the partition matching the numeric suffix of their ID, and their WAL must be disabled since the consumer offsets are used for replay.")
+	f.StringVar(&cfg.Address, prefix+".address", "localhost:9092", "Comma separated list of the Kafka seed brokers.")
+	f.StringVar(&cfg.Topic, prefix+".topic", "", "The Kafka topic name.")
+	f.StringVar(&cfg.ClientID, prefix+".client-id", "loki", "The Kafka client ID.")
+	f.DurationVar(&cfg.WriteTimeout, prefix+".write-timeout", 10*time.Second, "How long to wait for the brokers to acknowledge a write before failing the push request.")
+	f.StringVar(&cfg.ConsumerGroup, prefix+".consumer-group", "loki-ingester", "The consumer group used by the ingesters to commit their offsets.")
+	f.DurationVar(&cfg.CommitInterval, prefix+".commit-interval", 15*time.Second, "How often the ingesters commit the offset of the oldest record not yet flushed to the store.")
+	f.IntVar(&cfg.MaxFetchBatch, prefix+".max-fetch-batch", 100, "Maximum number of records read by the ingesters in one go.")
+}
+
+// Validate validates the Kafka configuration.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Address == "" {
+		return ErrMissingAddress
+	}
+	if cfg.Topic == "" {
+		return ErrMissingTopic
+	}
+	return nil
+}
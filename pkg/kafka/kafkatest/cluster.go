@@ -0,0 +1,130 @@
+// Package kafkatest provides an in-process stand-in for a Kafka cluster, to
+// be used in tests of the Kafka write path.
+package kafkatest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/loki/v3/pkg/kafka"
+)
+
+// Cluster is an in-memory, single topic Kafka cluster implementing
+// kafka.Client. It can be shared between several distributors and ingesters.
+type Cluster struct {
+	mtx        sync.Mutex
+	partitions [][]kafka.Record
+	committed  map[int32]int64
+	// notify is closed and replaced whenever records are produced.
+	notify chan struct{}
+}
+
+// NewCluster returns a cluster with the given number of partitions.
+func NewCluster(partitions int) *Cluster {
+	return &Cluster{
+		partitions: make([][]kafka.Record, partitions),
+		committed:  map[int32]int64{},
+		notify:     make(chan struct{}),
+	}
+}
+
+func (c *Cluster) Partitions() (int32, error) {
+	return int32(len(c.partitions)), nil
+}
+
+func (c *Cluster) Produce(_ context.Context, records []kafka.Record) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, r := range records {
+		if err := c.checkPartition(r.Partition); err != nil {
+			return err
+		}
+	}
+	for _, r := range records {
+		r.Offset = int64(len(c.partitions[r.Partition]))
+		c.partitions[r.Partition] = append(c.partitions[r.Partition], r)
+	}
+	close(c.notify)
+	c.notify = make(chan struct{})
+	return nil
+}
+
+// Records returns all the records written to the partition.
+func (c *Cluster) Records(partition int32) []kafka.Record {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]kafka.Record(nil), c.partitions[partition]...)
+}
+
+func (c *Cluster) Reader(partition int32, offset int64) (kafka.Reader, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.checkPartition(partition); err != nil {
+		return nil, err
+	}
+	return &reader{cluster: c, partition: partition, offset: offset}, nil
+}
+
+func (c *Cluster) CommittedOffset(partition int32) (int64, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.checkPartition(partition); err != nil {
+		return 0, err
+	}
+	return c.committed[partition], nil
+}
+
+func (c *Cluster) Commit(partition int32, offset int64) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if err := c.checkPartition(partition); err != nil {
+		return err
+	}
+	c.committed[partition] = offset
+	return nil
+}
+
+func (c *Cluster) Close() error {
+	return nil
+}
+
+func (c *Cluster) checkPartition(partition int32) error {
+	if partition < 0 || int(partition) >= len(c.partitions) {
+		return fmt.Errorf("unknown partition %d", partition)
+	}
+	return nil
+}
+
+type reader struct {
+	cluster   *Cluster
+	partition int32
+	offset    int64
+}
+
+func (r *reader) Poll(ctx context.Context, max int) ([]kafka.Record, error) {
+	for {
+		r.cluster.mtx.Lock()
+		records := r.cluster.partitions[r.partition]
+		notify := r.cluster.notify
+		if r.offset < int64(len(records)) {
+			end := min(int64(len(records)), r.offset+int64(max))
+			polled := append([]kafka.Record(nil), records[r.offset:end]...)
+			r.offset = end
+			r.cluster.mtx.Unlock()
+			return polled, nil
+		}
+		r.cluster.mtx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+func (r *reader) Close() error {
+	return nil
+}
@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+var errReaderClosed = errors.New("kafka partition reader closed")
+
+type saramaClient struct {
+	cfg Config
+
+	client   sarama.Client
+	producer sarama.SyncProducer
+	consumer sarama.Consumer
+	offsets  sarama.OffsetManager
+
+	mtx        sync.Mutex
+	partitions map[int32]sarama.PartitionOffsetManager
+}
+
+// NewClient returns a Client connected to the configured Kafka brokers.
+func NewClient(cfg Config) (Client, error) {
+	config := sarama.NewConfig()
+	config.ClientID = cfg.ClientID
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Timeout = cfg.WriteTimeout
+	config.Producer.Partitioner = sarama.NewManualPartitioner
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(strings.Split(cfg.Address, ","), config)
+	if err != nil {
+		return nil, fmt.Errorf("creating kafka client: %w", err)
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("creating kafka producer: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		producer.Close()
+		client.Close()
+		return nil, fmt.Errorf("creating kafka consumer: %w", err)
+	}
+	offsets, err := sarama.NewOffsetManagerFromClient(cfg.ConsumerGroup, client)
+	if err != nil {
+		consumer.Close()
+		producer.Close()
+		client.Close()
+		return nil, fmt.Errorf("creating kafka offset manager: %w", err)
+	}
+
+	return &saramaClient{
+		cfg:        cfg,
+		client:     client,
+		producer:   producer,
+		consumer:   consumer,
+		offsets:    offsets,
+		partitions: map[int32]sarama.PartitionOffsetManager{},
+	}, nil
+}
+
+func (c *saramaClient) Partitions() (int32, error) {
+	partitions, err := c.client.Partitions(c.cfg.Topic)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(partitions)), nil
+}
+
+// Produce sends the records and waits for their acknowledgement, or until the
+// context is done. The sends can't be aborted, so the records may still be
+// written after the context is done.
+func (c *saramaClient) Produce(ctx context.Context, records []Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msgs := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, r := range records {
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:     c.cfg.Topic,
+			Partition: r.Partition,
+			Key:       sarama.ByteEncoder(r.Key),
+			Value:     sarama.ByteEncoder(r.Value),
+		})
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.producer.SendMessages(msgs)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *saramaClient) Reader(partition int32, offset int64) (Reader, error) {
+	pc, err := c.consumer.ConsumePartition(c.cfg.Topic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &saramaReader{pc: pc}, nil
+}
+
+func (c *saramaClient) partitionOffsets(partition int32) (sarama.PartitionOffsetManager, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if pom, ok := c.partitions[partition]; ok {
+		return pom, nil
+	}
+	pom, err := c.offsets.ManagePartition(c.cfg.Topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	c.partitions[partition] = pom
+	return pom, nil
+}
+
+func (c *saramaClient) CommittedOffset(partition int32) (int64, error) {
+	pom, err := c.partitionOffsets(partition)
+	if err != nil {
+		return 0, err
+	}
+	offset, _ := pom.NextOffset()
+	return offset, nil
+}
+
+func (c *saramaClient) Commit(partition int32, offset int64) error {
+	pom, err := c.partitionOffsets(partition)
+	if err != nil {
+		return err
+	}
+	pom.MarkOffset(offset, "")
+	c.offsets.Commit()
+	return nil
+}
+
+func (c *saramaClient) Close() error {
+	c.mtx.Lock()
+	for _, pom := range c.partitions {
+		pom.AsyncClose()
+	}
+	c.mtx.Unlock()
+
+	var firstErr error
+	for _, closer := range []interface{ Close() error }{c.offsets, c.consumer, c.producer, c.client} {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type saramaReader struct {
+	pc sarama.PartitionConsumer
+}
+
+func (r *saramaReader) Poll(ctx context.Context, max int) ([]Record, error) {
+	var records []Record
+	add := func(msg *sarama.ConsumerMessage) {
+		records = append(records, Record{
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Key:       msg.Key,
+			Value:     msg.Value,
+		})
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg, ok := <-r.pc.Messages():
+		if !ok {
+			return nil, errReaderClosed
+		}
+		add(msg)
+	}
+
+	for len(records) < max {
+		select {
+		case msg, ok := <-r.pc.Messages():
+			if !ok {
+				return records, nil
+			}
+			add(msg)
+		default:
+			return records, nil
+		}
+	}
+	return records, nil
+}
+
+func (r *saramaReader) Close() error {
+	return r.pc.Close()
+}
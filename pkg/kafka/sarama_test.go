@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingProducer struct {
+	sarama.SyncProducer
+	unblock chan struct{}
+}
+
+func (p *blockingProducer) SendMessages(_ []*sarama.ProducerMessage) error {
+	<-p.unblock
+	return nil
+}
+
+func TestSaramaClient_ProduceContextDone(t *testing.T) {
+	producer := &blockingProducer{unblock: make(chan struct{})}
+	defer close(producer.unblock)
+	c := &saramaClient{cfg: Config{Topic: "loki"}, producer: producer}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.Produce(ctx, []Record{{Partition: 1, Value: []byte("push")}}), context.DeadlineExceeded)
+
+	// a push cancelled before the send doesn't reach the producer.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, c.Produce(ctx, []Record{{Partition: 1, Value: []byte("push")}}), context.Canceled)
+}
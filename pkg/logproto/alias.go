@@ -13,6 +13,8 @@ type Stream = push.Stream
 type LabelAdapter = push.LabelAdapter
 type PushRequest = push.PushRequest
 type PushResponse = push.PushResponse
+type PushPartialSuccess = push.PushPartialSuccess
+type StreamPushResult = push.StreamPushResult
 type PusherClient = push.PusherClient
 type PusherServer = push.PusherServer
 
@@ -303,6 +303,94 @@ func (m *StreamRate) GetPushes() uint32 {
 	return 0
 }
 
+type TransferChunksRequest struct {
+	FromIngesterID string `protobuf:"bytes,1,opt,name=fromIngesterID,proto3" json:"fromIngesterID,omitempty"`
+	// series is a stream serialized like in the WAL checkpoints, with the chunks
+	// and head blocks of the stream.
+	Series []byte `protobuf:"bytes,2,opt,name=series,proto3" json:"series,omitempty"`
+}
+
+func (m *TransferChunksRequest) Reset()      { *m = TransferChunksRequest{} }
+func (*TransferChunksRequest) ProtoMessage() {}
+func (*TransferChunksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{5}
+}
+func (m *TransferChunksRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferChunksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferChunksRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferChunksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferChunksRequest.Merge(m, src)
+}
+func (m *TransferChunksRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferChunksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferChunksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferChunksRequest proto.InternalMessageInfo
+
+func (m *TransferChunksRequest) GetFromIngesterID() string {
+	if m != nil {
+		return m.FromIngesterID
+	}
+	return ""
+}
+
+func (m *TransferChunksRequest) GetSeries() []byte {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+type TransferChunksResponse struct {
+}
+
+func (m *TransferChunksResponse) Reset()      { *m = TransferChunksResponse{} }
+func (*TransferChunksResponse) ProtoMessage() {}
+func (*TransferChunksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{6}
+}
+func (m *TransferChunksResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferChunksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferChunksResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferChunksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferChunksResponse.Merge(m, src)
+}
+func (m *TransferChunksResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferChunksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferChunksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferChunksResponse proto.InternalMessageInfo
+
 type QueryRequest struct {
 	Selector  string                                                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"` // Deprecated: Do not use.
 	Limit     uint32                                                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
@@ -317,7 +405,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{5}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{7}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -408,7 +496,7 @@ type SampleQueryRequest struct {
 func (m *SampleQueryRequest) Reset()      { *m = SampleQueryRequest{} }
 func (*SampleQueryRequest) ProtoMessage() {}
 func (*SampleQueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{6}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{8}
 }
 func (m *SampleQueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -481,7 +569,7 @@ type Plan struct {
 func (m *Plan) Reset()      { *m = Plan{} }
 func (*Plan) ProtoMessage() {}
 func (*Plan) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{7}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{9}
 }
 func (m *Plan) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -526,7 +614,7 @@ type Delete struct {
 func (m *Delete) Reset()      { *m = Delete{} }
 func (*Delete) ProtoMessage() {}
 func (*Delete) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{8}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{10}
 }
 func (m *Delete) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -585,7 +673,7 @@ type QueryResponse struct {
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{9}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{11}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -637,7 +725,7 @@ type SampleQueryResponse struct {
 func (m *SampleQueryResponse) Reset()      { *m = SampleQueryResponse{} }
 func (*SampleQueryResponse) ProtoMessage() {}
 func (*SampleQueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{10}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{12}
 }
 func (m *SampleQueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -691,7 +779,7 @@ type LabelRequest struct {
 func (m *LabelRequest) Reset()      { *m = LabelRequest{} }
 func (*LabelRequest) ProtoMessage() {}
 func (*LabelRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{11}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{13}
 }
 func (m *LabelRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -762,7 +850,7 @@ type LabelResponse struct {
 func (m *LabelResponse) Reset()      { *m = LabelResponse{} }
 func (*LabelResponse) ProtoMessage() {}
 func (*LabelResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{12}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{14}
 }
 func (m *LabelResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -807,7 +895,7 @@ type Sample struct {
 func (m *Sample) Reset()      { *m = Sample{} }
 func (*Sample) ProtoMessage() {}
 func (*Sample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{13}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{15}
 }
 func (m *Sample) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -866,7 +954,7 @@ type LegacySample struct {
 func (m *LegacySample) Reset()      { *m = LegacySample{} }
 func (*LegacySample) ProtoMessage() {}
 func (*LegacySample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{14}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{16}
 }
 func (m *LegacySample) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -918,7 +1006,7 @@ type Series struct {
 func (m *Series) Reset()      { *m = Series{} }
 func (*Series) ProtoMessage() {}
 func (*Series) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{15}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{17}
 }
 func (m *Series) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -979,7 +1067,7 @@ type TailRequest struct {
 func (m *TailRequest) Reset()      { *m = TailRequest{} }
 func (*TailRequest) ProtoMessage() {}
 func (*TailRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{16}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{18}
 }
 func (m *TailRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1045,7 +1133,7 @@ type TailResponse struct {
 func (m *TailResponse) Reset()      { *m = TailResponse{} }
 func (*TailResponse) ProtoMessage() {}
 func (*TailResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{17}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{19}
 }
 func (m *TailResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1091,7 +1179,7 @@ type SeriesRequest struct {
 func (m *SeriesRequest) Reset()      { *m = SeriesRequest{} }
 func (*SeriesRequest) ProtoMessage() {}
 func (*SeriesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{18}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{20}
 }
 func (m *SeriesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1155,7 +1243,7 @@ type SeriesResponse struct {
 func (m *SeriesResponse) Reset()      { *m = SeriesResponse{} }
 func (*SeriesResponse) ProtoMessage() {}
 func (*SeriesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{19}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{21}
 }
 func (m *SeriesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1198,7 +1286,7 @@ type SeriesIdentifier struct {
 func (m *SeriesIdentifier) Reset()      { *m = SeriesIdentifier{} }
 func (*SeriesIdentifier) ProtoMessage() {}
 func (*SeriesIdentifier) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{20}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{22}
 }
 func (m *SeriesIdentifier) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1242,7 +1330,7 @@ type SeriesIdentifier_LabelsEntry struct {
 func (m *SeriesIdentifier_LabelsEntry) Reset()      { *m = SeriesIdentifier_LabelsEntry{} }
 func (*SeriesIdentifier_LabelsEntry) ProtoMessage() {}
 func (*SeriesIdentifier_LabelsEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{20, 0}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{22, 0}
 }
 func (m *SeriesIdentifier_LabelsEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1294,7 +1382,7 @@ type DroppedStream struct {
 func (m *DroppedStream) Reset()      { *m = DroppedStream{} }
 func (*DroppedStream) ProtoMessage() {}
 func (*DroppedStream) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{21}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{23}
 }
 func (m *DroppedStream) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1352,7 +1440,7 @@ type LabelPair struct {
 func (m *LabelPair) Reset()      { *m = LabelPair{} }
 func (*LabelPair) ProtoMessage() {}
 func (*LabelPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{22}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{24}
 }
 func (m *LabelPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1405,7 +1493,7 @@ type LegacyLabelPair struct {
 func (m *LegacyLabelPair) Reset()      { *m = LegacyLabelPair{} }
 func (*LegacyLabelPair) ProtoMessage() {}
 func (*LegacyLabelPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{23}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{25}
 }
 func (m *LegacyLabelPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1455,7 +1543,7 @@ type Chunk struct {
 func (m *Chunk) Reset()      { *m = Chunk{} }
 func (*Chunk) ProtoMessage() {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{24}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{26}
 }
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1497,7 +1585,7 @@ type TailersCountRequest struct {
 func (m *TailersCountRequest) Reset()      { *m = TailersCountRequest{} }
 func (*TailersCountRequest) ProtoMessage() {}
 func (*TailersCountRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{25}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{27}
 }
 func (m *TailersCountRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1533,7 +1621,7 @@ type TailersCountResponse struct {
 func (m *TailersCountResponse) Reset()      { *m = TailersCountResponse{} }
 func (*TailersCountResponse) ProtoMessage() {}
 func (*TailersCountResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{26}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{28}
 }
 func (m *TailersCountResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1578,7 +1666,7 @@ type GetChunkIDsRequest struct {
 func (m *GetChunkIDsRequest) Reset()      { *m = GetChunkIDsRequest{} }
 func (*GetChunkIDsRequest) ProtoMessage() {}
 func (*GetChunkIDsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{27}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{29}
 }
 func (m *GetChunkIDsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1635,7 +1723,7 @@ type GetChunkIDsResponse struct {
 func (m *GetChunkIDsResponse) Reset()      { *m = GetChunkIDsResponse{} }
 func (*GetChunkIDsResponse) ProtoMessage() {}
 func (*GetChunkIDsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{28}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{30}
 }
 func (m *GetChunkIDsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1683,12 +1771,14 @@ type ChunkRef struct {
 	// The checksum is not written to the external storage. We use crc32,
 	// Castagnoli table. See http://www.evanjones.ca/crc32c.html.
 	Checksum uint32 `protobuf:"varint,5,opt,name=checksum,proto3" json:"-"`
+	// The storage class of the chunk, which prefixes its key in the object store.
+	StorageClass string `protobuf:"bytes,6,opt,name=storage_class,json=storageClass,proto3" json:"storageClass,omitempty"`
 }
 
 func (m *ChunkRef) Reset()      { *m = ChunkRef{} }
 func (*ChunkRef) ProtoMessage() {}
 func (*ChunkRef) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{29}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{31}
 }
 func (m *ChunkRef) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1738,6 +1828,13 @@ func (m *ChunkRef) GetChecksum() uint32 {
 	return 0
 }
 
+func (m *ChunkRef) GetStorageClass() string {
+	if m != nil {
+		return m.StorageClass
+	}
+	return ""
+}
+
 type LabelValuesForMetricNameRequest struct {
 	MetricName string                                  `protobuf:"bytes,1,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
 	LabelName  string                                  `protobuf:"bytes,2,opt,name=label_name,json=labelName,proto3" json:"label_name,omitempty"`
@@ -1749,7 +1846,7 @@ type LabelValuesForMetricNameRequest struct {
 func (m *LabelValuesForMetricNameRequest) Reset()      { *m = LabelValuesForMetricNameRequest{} }
 func (*LabelValuesForMetricNameRequest) ProtoMessage() {}
 func (*LabelValuesForMetricNameRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{30}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{32}
 }
 func (m *LabelValuesForMetricNameRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1808,7 +1905,7 @@ type LabelNamesForMetricNameRequest struct {
 func (m *LabelNamesForMetricNameRequest) Reset()      { *m = LabelNamesForMetricNameRequest{} }
 func (*LabelNamesForMetricNameRequest) ProtoMessage() {}
 func (*LabelNamesForMetricNameRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{31}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{33}
 }
 func (m *LabelNamesForMetricNameRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1852,7 +1949,7 @@ type LineFilter struct {
 func (m *LineFilter) Reset()      { *m = LineFilter{} }
 func (*LineFilter) ProtoMessage() {}
 func (*LineFilter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{32}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{34}
 }
 func (m *LineFilter) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1900,7 +1997,7 @@ type GetChunkRefRequest struct {
 func (m *GetChunkRefRequest) Reset()      { *m = GetChunkRefRequest{} }
 func (*GetChunkRefRequest) ProtoMessage() {}
 func (*GetChunkRefRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{33}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{35}
 }
 func (m *GetChunkRefRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1943,7 +2040,7 @@ type GetChunkRefResponse struct {
 func (m *GetChunkRefResponse) Reset()      { *m = GetChunkRefResponse{} }
 func (*GetChunkRefResponse) ProtoMessage() {}
 func (*GetChunkRefResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{34}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{36}
 }
 func (m *GetChunkRefResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1988,7 +2085,7 @@ type GetSeriesRequest struct {
 func (m *GetSeriesRequest) Reset()      { *m = GetSeriesRequest{} }
 func (*GetSeriesRequest) ProtoMessage() {}
 func (*GetSeriesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{35}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{37}
 }
 func (m *GetSeriesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2031,7 +2128,7 @@ type GetSeriesResponse struct {
 func (m *GetSeriesResponse) Reset()      { *m = GetSeriesResponse{} }
 func (*GetSeriesResponse) ProtoMessage() {}
 func (*GetSeriesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{36}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{38}
 }
 func (m *GetSeriesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2075,7 +2172,7 @@ type IndexSeries struct {
 func (m *IndexSeries) Reset()      { *m = IndexSeries{} }
 func (*IndexSeries) ProtoMessage() {}
 func (*IndexSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{37}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{39}
 }
 func (m *IndexSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2112,7 +2209,7 @@ type QueryIndexResponse struct {
 func (m *QueryIndexResponse) Reset()      { *m = QueryIndexResponse{} }
 func (*QueryIndexResponse) ProtoMessage() {}
 func (*QueryIndexResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{38}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{40}
 }
 func (m *QueryIndexResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2163,7 +2260,7 @@ type Row struct {
 func (m *Row) Reset()      { *m = Row{} }
 func (*Row) ProtoMessage() {}
 func (*Row) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{39}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{41}
 }
 func (m *Row) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2213,7 +2310,7 @@ type QueryIndexRequest struct {
 func (m *QueryIndexRequest) Reset()      { *m = QueryIndexRequest{} }
 func (*QueryIndexRequest) ProtoMessage() {}
 func (*QueryIndexRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{40}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{42}
 }
 func (m *QueryIndexRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2260,7 +2357,7 @@ type IndexQuery struct {
 func (m *IndexQuery) Reset()      { *m = IndexQuery{} }
 func (*IndexQuery) ProtoMessage() {}
 func (*IndexQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{41}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{43}
 }
 func (m *IndexQuery) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2333,7 +2430,7 @@ type IndexStatsRequest struct {
 func (m *IndexStatsRequest) Reset()      { *m = IndexStatsRequest{} }
 func (*IndexStatsRequest) ProtoMessage() {}
 func (*IndexStatsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{42}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{44}
 }
 func (m *IndexStatsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2379,7 +2476,7 @@ type IndexStatsResponse struct {
 func (m *IndexStatsResponse) Reset()      { *m = IndexStatsResponse{} }
 func (*IndexStatsResponse) ProtoMessage() {}
 func (*IndexStatsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{43}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{45}
 }
 func (m *IndexStatsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2449,7 +2546,7 @@ type VolumeRequest struct {
 func (m *VolumeRequest) Reset()      { *m = VolumeRequest{} }
 func (*VolumeRequest) ProtoMessage() {}
 func (*VolumeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{44}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{46}
 }
 func (m *VolumeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2521,7 +2618,7 @@ type VolumeResponse struct {
 func (m *VolumeResponse) Reset()      { *m = VolumeResponse{} }
 func (*VolumeResponse) ProtoMessage() {}
 func (*VolumeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{45}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{47}
 }
 func (m *VolumeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2572,7 +2669,7 @@ type Volume struct {
 func (m *Volume) Reset()      { *m = Volume{} }
 func (*Volume) ProtoMessage() {}
 func (*Volume) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{46}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{48}
 }
 func (m *Volume) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2627,7 +2724,7 @@ type DetectedFieldsRequest struct {
 func (m *DetectedFieldsRequest) Reset()      { *m = DetectedFieldsRequest{} }
 func (*DetectedFieldsRequest) ProtoMessage() {}
 func (*DetectedFieldsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{47}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{49}
 }
 func (m *DetectedFieldsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2706,7 +2803,7 @@ type DetectedFieldsResponse struct {
 func (m *DetectedFieldsResponse) Reset()      { *m = DetectedFieldsResponse{} }
 func (*DetectedFieldsResponse) ProtoMessage() {}
 func (*DetectedFieldsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{48}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{50}
 }
 func (m *DetectedFieldsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2761,7 +2858,7 @@ type DetectedField struct {
 func (m *DetectedField) Reset()      { *m = DetectedField{} }
 func (*DetectedField) ProtoMessage() {}
 func (*DetectedField) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{49}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{51}
 }
 func (m *DetectedField) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2827,7 +2924,7 @@ type DetectedLabelsRequest struct {
 func (m *DetectedLabelsRequest) Reset()      { *m = DetectedLabelsRequest{} }
 func (*DetectedLabelsRequest) ProtoMessage() {}
 func (*DetectedLabelsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{50}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{52}
 }
 func (m *DetectedLabelsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2884,7 +2981,7 @@ type DetectedLabelsResponse struct {
 func (m *DetectedLabelsResponse) Reset()      { *m = DetectedLabelsResponse{} }
 func (*DetectedLabelsResponse) ProtoMessage() {}
 func (*DetectedLabelsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{51}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{53}
 }
 func (m *DetectedLabelsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2928,7 +3025,7 @@ type DetectedLabel struct {
 func (m *DetectedLabel) Reset()      { *m = DetectedLabel{} }
 func (*DetectedLabel) ProtoMessage() {}
 func (*DetectedLabel) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c28a5f14f1f4c79a, []int{52}
+	return fileDescriptor_c28a5f14f1f4c79a, []int{54}
 }
 func (m *DetectedLabel) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2979,6 +3076,8 @@ func init() {
 	proto.RegisterType((*StreamRatesRequest)(nil), "logproto.StreamRatesRequest")
 	proto.RegisterType((*StreamRatesResponse)(nil), "logproto.StreamRatesResponse")
 	proto.RegisterType((*StreamRate)(nil), "logproto.StreamRate")
+	proto.RegisterType((*TransferChunksRequest)(nil), "logproto.TransferChunksRequest")
+	proto.RegisterType((*TransferChunksResponse)(nil), "logproto.TransferChunksResponse")
 	proto.RegisterType((*QueryRequest)(nil), "logproto.QueryRequest")
 	proto.RegisterType((*SampleQueryRequest)(nil), "logproto.SampleQueryRequest")
 	proto.RegisterType((*Plan)(nil), "logproto.Plan")
@@ -3033,169 +3132,175 @@ func init() {
 func init() { proto.RegisterFile("pkg/logproto/logproto.proto", fileDescriptor_c28a5f14f1f4c79a) }
 
 var fileDescriptor_c28a5f14f1f4c79a = []byte{
-	// 2590 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x1a, 0x4d, 0x6f, 0x1b, 0xc7,
-	0x95, 0x4b, 0x2e, 0xbf, 0x1e, 0x29, 0x59, 0x1e, 0xd1, 0x36, 0x41, 0xdb, 0xa4, 0x32, 0x68, 0x13,
-	0x35, 0x76, 0xc4, 0x58, 0x69, 0xd2, 0xc4, 0x69, 0x9a, 0x9a, 0x52, 0xac, 0xc8, 0x51, 0x1c, 0x67,
-	0xa4, 0x38, 0x69, 0xd1, 0x20, 0x58, 0x91, 0x23, 0x6a, 0x21, 0x72, 0x97, 0xde, 0x1d, 0xc6, 0xe1,
-	0xad, 0x7f, 0xa0, 0x68, 0x8a, 0x1e, 0xda, 0x5e, 0x0a, 0x14, 0x28, 0xd0, 0x22, 0x45, 0x2f, 0x45,
-	0x8f, 0x45, 0x7b, 0xe9, 0x21, 0xbd, 0xa5, 0xb7, 0x20, 0x07, 0xb6, 0x56, 0x2e, 0x85, 0x4e, 0x01,
-	0x7a, 0xcb, 0xa9, 0x98, 0xaf, 0xfd, 0x12, 0x59, 0x87, 0x8a, 0x83, 0xc0, 0x17, 0x71, 0xe6, 0xcd,
-	0x9b, 0x37, 0xf3, 0x3e, 0xe6, 0x7d, 0xad, 0xe0, 0xfc, 0xe0, 0xa0, 0xdb, 0xec, 0xb9, 0xdd, 0x81,
-	0xe7, 0x32, 0x37, 0x18, 0xac, 0x88, 0xbf, 0xa8, 0xa0, 0xe7, 0xb5, 0x4a, 0xd7, 0xed, 0xba, 0x12,
-	0x87, 0x8f, 0xe4, 0x7a, 0xad, 0xd1, 0x75, 0xdd, 0x6e, 0x8f, 0x36, 0xc5, 0x6c, 0x77, 0xb8, 0xd7,
-	0x64, 0x76, 0x9f, 0xfa, 0xcc, 0xea, 0x0f, 0x14, 0xc2, 0x92, 0xa2, 0x7e, 0xa7, 0xd7, 0x77, 0x3b,
-	0xb4, 0xd7, 0xf4, 0x99, 0xc5, 0x7c, 0xf9, 0x57, 0x61, 0x2c, 0x72, 0x8c, 0xc1, 0xd0, 0xdf, 0x17,
-	0x7f, 0x24, 0x10, 0xff, 0xd9, 0x80, 0x33, 0x5b, 0xd6, 0x2e, 0xed, 0xed, 0xb8, 0xb7, 0xad, 0xde,
-	0x90, 0xfa, 0x84, 0xfa, 0x03, 0xd7, 0xf1, 0x29, 0x5a, 0x83, 0x5c, 0x8f, 0x2f, 0xf8, 0x55, 0x63,
-	0x29, 0xb3, 0x5c, 0x5a, 0xbd, 0xb4, 0x12, 0x5c, 0x79, 0xe2, 0x06, 0x09, 0xf5, 0x5f, 0x72, 0x98,
-	0x37, 0x22, 0x6a, 0x6b, 0xed, 0x36, 0x94, 0x22, 0x60, 0xb4, 0x00, 0x99, 0x03, 0x3a, 0xaa, 0x1a,
-	0x4b, 0xc6, 0x72, 0x91, 0xf0, 0x21, 0xba, 0x02, 0xd9, 0x77, 0x39, 0x99, 0x6a, 0x7a, 0xc9, 0x58,
-	0x2e, 0xad, 0x9e, 0x0f, 0x0f, 0x79, 0xc3, 0xb1, 0xef, 0x0c, 0xa9, 0xd8, 0xad, 0x0e, 0x92, 0x98,
-	0x57, 0xd3, 0xcf, 0x1a, 0xf8, 0x12, 0x9c, 0x3e, 0xb6, 0x8e, 0xce, 0x42, 0x4e, 0x60, 0xc8, 0x1b,
-	0x17, 0x89, 0x9a, 0xe1, 0x0a, 0xa0, 0x6d, 0xe6, 0x51, 0xab, 0x4f, 0x2c, 0xc6, 0xef, 0x7b, 0x67,
-	0x48, 0x7d, 0x86, 0x5f, 0x85, 0xc5, 0x18, 0x54, 0xb1, 0xfd, 0x0c, 0x94, 0xfc, 0x10, 0xac, 0x78,
-	0xaf, 0x84, 0xd7, 0x0a, 0xf7, 0x90, 0x28, 0x22, 0xfe, 0xb5, 0x01, 0x10, 0xae, 0xa1, 0x3a, 0x80,
-	0x5c, 0x7d, 0xd9, 0xf2, 0xf7, 0x05, 0xc3, 0x26, 0x89, 0x40, 0xd0, 0x65, 0x38, 0x1d, 0xce, 0x6e,
-	0xba, 0xdb, 0xfb, 0x96, 0xd7, 0x11, 0x32, 0x30, 0xc9, 0xf1, 0x05, 0x84, 0xc0, 0xf4, 0x2c, 0x46,
-	0xab, 0x99, 0x25, 0x63, 0x39, 0x43, 0xc4, 0x98, 0x73, 0xcb, 0xa8, 0x63, 0x39, 0xac, 0x6a, 0x0a,
-	0x71, 0xaa, 0x19, 0x87, 0x73, 0xfd, 0x52, 0xbf, 0x9a, 0x5d, 0x32, 0x96, 0xe7, 0x88, 0x9a, 0xe1,
-	0x0f, 0x32, 0x50, 0x7e, 0x7d, 0x48, 0xbd, 0x91, 0x12, 0x00, 0xaa, 0x43, 0xc1, 0xa7, 0x3d, 0xda,
-	0x66, 0xae, 0x27, 0x35, 0xd2, 0x4a, 0x57, 0x0d, 0x12, 0xc0, 0x50, 0x05, 0xb2, 0x3d, 0xbb, 0x6f,
-	0x33, 0x71, 0xad, 0x39, 0x22, 0x27, 0xe8, 0x2a, 0x64, 0x7d, 0x66, 0x79, 0x4c, 0xdc, 0xa5, 0xb4,
-	0x5a, 0x5b, 0x91, 0x86, 0xb9, 0xa2, 0x0d, 0x73, 0x65, 0x47, 0x1b, 0x66, 0xab, 0xf0, 0xe1, 0xb8,
-	0x91, 0x7a, 0xff, 0x5f, 0x0d, 0x83, 0xc8, 0x2d, 0xe8, 0x19, 0xc8, 0x50, 0xa7, 0x23, 0xee, 0xfb,
-	0x45, 0x77, 0xf2, 0x0d, 0xe8, 0x0a, 0x14, 0x3b, 0xb6, 0x47, 0xdb, 0xcc, 0x76, 0x1d, 0xc1, 0xd5,
-	0xfc, 0xea, 0x62, 0xa8, 0x91, 0x75, 0xbd, 0x44, 0x42, 0x2c, 0x74, 0x19, 0x72, 0x3e, 0x17, 0x9d,
-	0x5f, 0xcd, 0x73, 0x5b, 0x68, 0x55, 0x8e, 0xc6, 0x8d, 0x05, 0x09, 0xb9, 0xec, 0xf6, 0x6d, 0x46,
-	0xfb, 0x03, 0x36, 0x22, 0x0a, 0x07, 0x3d, 0x0e, 0xf9, 0x0e, 0xed, 0x51, 0xae, 0xf0, 0x82, 0x50,
-	0xf8, 0x42, 0x84, 0xbc, 0x58, 0x20, 0x1a, 0x01, 0xbd, 0x0d, 0xe6, 0xa0, 0x67, 0x39, 0xd5, 0xa2,
-	0xe0, 0x62, 0x3e, 0x44, 0xbc, 0xd5, 0xb3, 0x9c, 0xd6, 0x73, 0x9f, 0x8c, 0x1b, 0x4f, 0x77, 0x6d,
-	0xb6, 0x3f, 0xdc, 0x5d, 0x69, 0xbb, 0xfd, 0x66, 0xd7, 0xb3, 0xf6, 0x2c, 0xc7, 0x6a, 0xf6, 0xdc,
-	0x03, 0xbb, 0xf9, 0xee, 0x53, 0x4d, 0xfe, 0x06, 0xef, 0x0c, 0xa9, 0x67, 0x53, 0xaf, 0xc9, 0xc9,
-	0xac, 0x08, 0x95, 0xf0, 0xad, 0x44, 0x90, 0xbd, 0x61, 0x16, 0x72, 0x0b, 0x79, 0x7c, 0x2f, 0x0d,
-	0x68, 0xdb, 0xea, 0x0f, 0x7a, 0x74, 0x26, 0x95, 0x05, 0xca, 0x49, 0x9f, 0x58, 0x39, 0x99, 0x59,
-	0x95, 0x13, 0x4a, 0xda, 0x9c, 0x4d, 0xd2, 0xd9, 0x2f, 0x2a, 0xe9, 0xdc, 0x57, 0x22, 0x69, 0x5c,
-	0x05, 0x93, 0xcf, 0xb8, 0x53, 0xf2, 0xac, 0xbb, 0x42, 0x9e, 0x65, 0xc2, 0x87, 0x78, 0x0b, 0x72,
-	0xf2, 0x2e, 0xa8, 0x96, 0x14, 0x78, 0xfc, 0x7d, 0x84, 0xc2, 0xce, 0x68, 0x31, 0x2e, 0x84, 0x62,
-	0xcc, 0x08, 0x01, 0xe1, 0xbf, 0x18, 0x30, 0xa7, 0xb4, 0xa8, 0x7c, 0xcc, 0x2e, 0xe4, 0xe5, 0x1b,
-	0xd7, 0xfe, 0xe5, 0x5c, 0xd2, 0xbf, 0x5c, 0xeb, 0x58, 0x03, 0x46, 0xbd, 0x56, 0xf3, 0xc3, 0x71,
-	0xc3, 0xf8, 0x64, 0xdc, 0x78, 0x6c, 0x1a, 0xa3, 0xda, 0xa7, 0x6b, 0xbf, 0xa4, 0x09, 0xa3, 0x4b,
-	0xe2, 0x76, 0xcc, 0x57, 0xa6, 0x70, 0x6a, 0x45, 0x86, 0x82, 0x4d, 0xa7, 0x4b, 0x7d, 0x4e, 0xd9,
-	0xe4, 0x5a, 0x24, 0x12, 0x87, 0xb3, 0x79, 0xd7, 0xf2, 0x1c, 0xdb, 0xe9, 0xfa, 0xd5, 0x8c, 0xf0,
-	0x9d, 0xc1, 0x1c, 0xff, 0xd2, 0x80, 0xc5, 0x98, 0x29, 0x2a, 0x26, 0x9e, 0x85, 0x9c, 0xcf, 0xa5,
-	0xab, 0x79, 0x88, 0x28, 0x72, 0x5b, 0xc0, 0x5b, 0xf3, 0xea, 0xf2, 0x39, 0x39, 0x27, 0x0a, 0xff,
-	0xc1, 0x5d, 0xed, 0xef, 0x06, 0x94, 0x45, 0x00, 0xd0, 0xef, 0x03, 0x81, 0xe9, 0x58, 0x7d, 0xaa,
-	0x54, 0x25, 0xc6, 0x91, 0xa8, 0xc0, 0x8f, 0x2b, 0xe8, 0xa8, 0x30, 0xab, 0x23, 0x33, 0x4e, 0xec,
-	0xc8, 0x8c, 0xf0, 0xad, 0x54, 0x20, 0xcb, 0x4d, 0x72, 0x24, 0x9c, 0x58, 0x91, 0xc8, 0x09, 0x7e,
-	0x0c, 0xe6, 0x14, 0x17, 0x4a, 0xb4, 0xd3, 0x02, 0x59, 0x1f, 0x72, 0x52, 0x13, 0xe8, 0x1b, 0x50,
-	0x0c, 0x12, 0x00, 0xc1, 0x6d, 0xa6, 0x95, 0x3b, 0x1a, 0x37, 0xd2, 0xcc, 0x27, 0xe1, 0x02, 0x6a,
-	0x44, 0x83, 0xab, 0xd1, 0x2a, 0x1e, 0x8d, 0x1b, 0x12, 0xa0, 0x42, 0x29, 0xba, 0x00, 0xe6, 0x3e,
-	0x8f, 0x4f, 0x5c, 0x04, 0x66, 0xab, 0x70, 0x34, 0x6e, 0x88, 0x39, 0x11, 0x7f, 0xf1, 0x06, 0x94,
-	0xb7, 0x68, 0xd7, 0x6a, 0x8f, 0xd4, 0xa1, 0x15, 0x4d, 0x8e, 0x1f, 0x68, 0x68, 0x1a, 0x8f, 0x40,
-	0x39, 0x38, 0xf1, 0x9d, 0xbe, 0xaf, 0x5e, 0x43, 0x29, 0x80, 0xbd, 0xea, 0xe3, 0x5f, 0x19, 0xa0,
-	0x6c, 0x00, 0xe1, 0x48, 0x56, 0xc1, 0xfd, 0x17, 0x1c, 0x8d, 0x1b, 0x0a, 0xa2, 0x93, 0x06, 0xf4,
-	0x3c, 0xe4, 0x7d, 0x71, 0x22, 0x27, 0x96, 0x34, 0x2d, 0xb1, 0xd0, 0x3a, 0xc5, 0x4d, 0xe4, 0x68,
-	0xdc, 0xd0, 0x88, 0x44, 0x0f, 0xd0, 0x4a, 0x2c, 0xf0, 0x4a, 0xc6, 0xe6, 0x8f, 0xc6, 0x8d, 0x08,
-	0x34, 0x1a, 0x88, 0xf1, 0xe7, 0x06, 0x94, 0x76, 0x2c, 0x3b, 0x30, 0xa1, 0xaa, 0x56, 0x51, 0xe8,
-	0x5f, 0x25, 0x80, 0x5b, 0x62, 0x87, 0xf6, 0xac, 0xd1, 0x75, 0xd7, 0x13, 0x74, 0xe7, 0x48, 0x30,
-	0x0f, 0x63, 0xa5, 0x39, 0x31, 0x56, 0x66, 0x67, 0x77, 0xc7, 0x5f, 0xad, 0xf3, 0xbb, 0x61, 0x16,
-	0xd2, 0x0b, 0x19, 0xfc, 0x47, 0x03, 0xca, 0x92, 0x79, 0x65, 0x79, 0x3f, 0x82, 0x9c, 0x94, 0x8d,
-	0x60, 0xff, 0xff, 0x38, 0xa6, 0x4b, 0xb3, 0x38, 0x25, 0x45, 0x13, 0xbd, 0x08, 0xf3, 0x1d, 0xcf,
-	0x1d, 0x0c, 0x68, 0x67, 0x5b, 0xb9, 0xbf, 0x74, 0xd2, 0xfd, 0xad, 0x47, 0xd7, 0x49, 0x02, 0x1d,
-	0xff, 0xc3, 0x80, 0x39, 0xe5, 0x4c, 0x94, 0xba, 0x02, 0x11, 0x1b, 0x27, 0x8e, 0x78, 0xe9, 0x59,
-	0x23, 0xde, 0x59, 0xc8, 0x75, 0x3d, 0x77, 0x38, 0xd0, 0x0e, 0x49, 0xcd, 0x66, 0x8b, 0x84, 0xf8,
-	0x06, 0xcc, 0x6b, 0x56, 0xa6, 0x78, 0xd4, 0x5a, 0xd2, 0xa3, 0x6e, 0x76, 0xa8, 0xc3, 0xec, 0x3d,
-	0x3b, 0xf0, 0x91, 0x0a, 0x1f, 0xff, 0xd4, 0x80, 0x85, 0x24, 0x0a, 0x5a, 0x4f, 0x24, 0xf0, 0x8f,
-	0x4e, 0x27, 0x17, 0xcd, 0xdd, 0x35, 0x69, 0x95, 0xc1, 0x3f, 0x7d, 0xbf, 0x0c, 0xbe, 0x12, 0x75,
-	0x32, 0x45, 0xe5, 0x15, 0xf0, 0x2f, 0x0c, 0x98, 0x8b, 0xe9, 0x12, 0x3d, 0x0b, 0xe6, 0x9e, 0xe7,
-	0xf6, 0x67, 0x52, 0x94, 0xd8, 0x81, 0xbe, 0x0d, 0x69, 0xe6, 0xce, 0xa4, 0xa6, 0x34, 0x73, 0xb9,
-	0x96, 0x14, 0xfb, 0x19, 0x99, 0x1f, 0xcb, 0x19, 0x7e, 0x1a, 0x8a, 0x82, 0xa1, 0x5b, 0x96, 0xed,
-	0x4d, 0x0c, 0x18, 0x93, 0x19, 0x7a, 0x1e, 0x4e, 0x49, 0x67, 0x38, 0x79, 0x73, 0x79, 0xd2, 0xe6,
-	0xb2, 0xde, 0x7c, 0x1e, 0xb2, 0x6b, 0xfb, 0x43, 0xe7, 0x80, 0x6f, 0xe9, 0x58, 0xcc, 0xd2, 0x5b,
-	0xf8, 0x18, 0x9f, 0x81, 0x45, 0xfe, 0x06, 0xa9, 0xe7, 0xaf, 0xb9, 0x43, 0x87, 0xe9, 0xfa, 0xe4,
-	0x32, 0x54, 0xe2, 0x60, 0x65, 0x25, 0x15, 0xc8, 0xb6, 0x39, 0x40, 0xd0, 0x98, 0x23, 0x72, 0x82,
-	0x7f, 0x6b, 0x00, 0xda, 0xa0, 0x4c, 0x9c, 0xb2, 0xb9, 0x1e, 0x3c, 0x8f, 0x1a, 0x14, 0xfa, 0x16,
-	0x6b, 0xef, 0x53, 0xcf, 0xd7, 0xf9, 0x8b, 0x9e, 0x7f, 0x1d, 0xc9, 0x22, 0xbe, 0x02, 0x8b, 0xb1,
-	0x5b, 0x2a, 0x9e, 0x6a, 0x50, 0x68, 0x2b, 0x98, 0x0a, 0x79, 0xc1, 0x1c, 0xff, 0x29, 0x0d, 0x05,
-	0xb1, 0x81, 0xd0, 0x3d, 0x74, 0x05, 0x4a, 0x7b, 0xb6, 0xd3, 0xa5, 0xde, 0xc0, 0xb3, 0x95, 0x08,
-	0xcc, 0xd6, 0xa9, 0xa3, 0x71, 0x23, 0x0a, 0x26, 0xd1, 0x09, 0x7a, 0x02, 0xf2, 0x43, 0x9f, 0x7a,
-	0xef, 0xd8, 0xf2, 0xa5, 0x17, 0x5b, 0x95, 0xc3, 0x71, 0x23, 0xf7, 0x86, 0x4f, 0xbd, 0xcd, 0x75,
-	0x1e, 0x7c, 0x86, 0x62, 0x44, 0xe4, 0x6f, 0x07, 0xbd, 0xa2, 0xcc, 0x54, 0x24, 0x70, 0xad, 0xef,
-	0xf0, 0xeb, 0x27, 0x5c, 0xdd, 0xc0, 0x73, 0xfb, 0x94, 0xed, 0xd3, 0xa1, 0xdf, 0x6c, 0xbb, 0xfd,
-	0xbe, 0xeb, 0x34, 0x45, 0xc5, 0x2d, 0x98, 0xe6, 0x11, 0x94, 0x6f, 0x57, 0x96, 0xbb, 0x03, 0x79,
-	0xb6, 0xef, 0xb9, 0xc3, 0xee, 0xbe, 0x08, 0x0c, 0x99, 0xd6, 0xd5, 0xd9, 0xe9, 0x69, 0x0a, 0x44,
-	0x0f, 0xd0, 0x23, 0x5c, 0x5a, 0xb4, 0x7d, 0xe0, 0x0f, 0xfb, 0xb2, 0xc6, 0x6b, 0x65, 0x8f, 0xc6,
-	0x0d, 0xe3, 0x09, 0x12, 0x80, 0xf1, 0x4f, 0xd2, 0xd0, 0x88, 0x94, 0xc6, 0xd7, 0x5d, 0xef, 0x55,
-	0xca, 0x3c, 0xbb, 0x7d, 0xd3, 0xea, 0x53, 0x6d, 0x1b, 0x0d, 0x28, 0xf5, 0x05, 0xf0, 0x9d, 0xc8,
-	0x13, 0x80, 0x7e, 0x80, 0x87, 0x2e, 0x02, 0x88, 0x37, 0x23, 0xd7, 0xe5, 0x6b, 0x28, 0x0a, 0x88,
-	0x58, 0x5e, 0x8b, 0x49, 0xaa, 0x39, 0x23, 0x67, 0x4a, 0x42, 0x9b, 0x49, 0x09, 0xcd, 0x4c, 0x27,
-	0x10, 0x4b, 0xd4, 0xd6, 0xb3, 0x71, 0x5b, 0xc7, 0xff, 0x34, 0xa0, 0xbe, 0xa5, 0x6f, 0x7e, 0x42,
-	0x71, 0x68, 0x7e, 0xd3, 0x0f, 0x88, 0xdf, 0xcc, 0x97, 0xe3, 0x17, 0xd7, 0x01, 0xb6, 0x6c, 0x87,
-	0x5e, 0xb7, 0x7b, 0x8c, 0x7a, 0x13, 0xaa, 0x98, 0x9f, 0x67, 0x42, 0x97, 0x40, 0xe8, 0x9e, 0xe6,
-	0x73, 0x2d, 0xe2, 0x87, 0x1f, 0x04, 0x1b, 0xe9, 0x07, 0xa8, 0xb6, 0x4c, 0xc2, 0x45, 0x39, 0x90,
-	0xdf, 0x13, 0xec, 0xc9, 0x90, 0x1a, 0x6b, 0xc4, 0x84, 0xbc, 0xb7, 0xbe, 0xa7, 0x0e, 0x7f, 0xe6,
-	0x3e, 0x19, 0x91, 0x68, 0x8f, 0x35, 0xfd, 0x91, 0xc3, 0xac, 0xf7, 0x22, 0xfb, 0x89, 0x3e, 0x04,
-	0x59, 0x2a, 0xe9, 0xca, 0x4e, 0x4c, 0xba, 0x5e, 0x50, 0xc7, 0x7c, 0xa9, 0xaa, 0xf3, 0x85, 0xd0,
-	0x03, 0x0a, 0xa5, 0x28, 0x0f, 0xf8, 0x28, 0x98, 0x1e, 0xdd, 0xd3, 0xa1, 0x1a, 0x85, 0x27, 0x07,
-	0x98, 0x62, 0x1d, 0xff, 0xd5, 0x80, 0x85, 0x0d, 0xca, 0xe2, 0x49, 0xd0, 0x43, 0xa4, 0x52, 0xfc,
-	0x32, 0x9c, 0x8e, 0xdc, 0x5f, 0x71, 0xff, 0x54, 0x22, 0xf3, 0x39, 0x13, 0xf2, 0xbf, 0xe9, 0x74,
-	0xe8, 0x7b, 0xaa, 0xa0, 0x8c, 0x27, 0x3d, 0xb7, 0xa0, 0x14, 0x59, 0x44, 0xd7, 0x12, 0xe9, 0xce,
-	0x62, 0xa2, 0x5f, 0xc9, 0x43, 0x76, 0xab, 0xa2, 0x78, 0x92, 0x65, 0xa3, 0x4a, 0x66, 0x83, 0xd4,
-	0x60, 0x1b, 0x90, 0x50, 0x97, 0x20, 0x1b, 0x0d, 0x4e, 0x02, 0xfa, 0x4a, 0x90, 0xf7, 0x04, 0x73,
-	0xf4, 0x08, 0x98, 0x9e, 0x7b, 0x57, 0xe7, 0xb1, 0x73, 0xe1, 0x91, 0xc4, 0xbd, 0x4b, 0xc4, 0x12,
-	0x7e, 0x1e, 0x32, 0xc4, 0xbd, 0x8b, 0xea, 0x00, 0x9e, 0xe5, 0x74, 0xe9, 0xed, 0xa0, 0x82, 0x2a,
-	0x93, 0x08, 0x64, 0x4a, 0xe2, 0xb0, 0x06, 0xa7, 0xa3, 0x37, 0x92, 0xea, 0x5e, 0x81, 0xfc, 0xeb,
-	0xc3, 0xa8, 0xb8, 0x2a, 0x09, 0x71, 0xc9, 0x42, 0x5d, 0x23, 0x71, 0x9b, 0x81, 0x10, 0x8e, 0x2e,
-	0x40, 0x91, 0x59, 0xbb, 0x3d, 0x7a, 0x33, 0x74, 0x73, 0x21, 0x80, 0xaf, 0xf2, 0xe2, 0xef, 0x76,
-	0x24, 0x03, 0x0a, 0x01, 0xe8, 0x71, 0x58, 0x08, 0xef, 0x7c, 0xcb, 0xa3, 0x7b, 0xf6, 0x7b, 0x42,
-	0xc3, 0x65, 0x72, 0x0c, 0x8e, 0x96, 0xe1, 0x54, 0x08, 0xdb, 0x16, 0x99, 0x86, 0x29, 0x50, 0x93,
-	0x60, 0x2e, 0x1b, 0xc1, 0xee, 0x4b, 0x77, 0x86, 0x56, 0x4f, 0x3c, 0xbe, 0x32, 0x89, 0x40, 0xf0,
-	0xdf, 0x0c, 0x38, 0x2d, 0x55, 0xcd, 0x2c, 0xf6, 0x50, 0x5a, 0xfd, 0xef, 0x0c, 0x40, 0x51, 0x0e,
-	0x94, 0x69, 0x7d, 0x33, 0xda, 0x08, 0xe2, 0xa9, 0x4c, 0x49, 0xd4, 0xb4, 0x12, 0x14, 0xf6, 0x72,
-	0x30, 0xe4, 0x44, 0x3a, 0x24, 0x8b, 0x6b, 0x53, 0x16, 0xcd, 0x12, 0x42, 0xd4, 0x2f, 0xaf, 0xf5,
-	0x77, 0x47, 0x8c, 0xfa, 0xaa, 0xe4, 0x15, 0xb5, 0xbe, 0x00, 0x10, 0xf9, 0xc3, 0xcf, 0xa2, 0x0e,
-	0x13, 0x56, 0x63, 0x86, 0x67, 0x29, 0x10, 0xd1, 0x03, 0xfc, 0x87, 0x34, 0xcc, 0xdd, 0x76, 0x7b,
-	0xc3, 0x30, 0x30, 0x3e, 0x4c, 0x01, 0x23, 0x56, 0x87, 0x67, 0x75, 0x1d, 0x8e, 0xc0, 0xf4, 0x19,
-	0x1d, 0x08, 0xcb, 0xca, 0x10, 0x31, 0x46, 0x18, 0xca, 0xcc, 0xf2, 0xba, 0x94, 0xc9, 0xea, 0xa6,
-	0x9a, 0x13, 0x69, 0x67, 0x0c, 0x86, 0x96, 0xa0, 0x64, 0x75, 0xbb, 0x1e, 0xed, 0x5a, 0x8c, 0xb6,
-	0x46, 0xd5, 0xbc, 0x38, 0x2c, 0x0a, 0xc2, 0x6f, 0xc1, 0xbc, 0x16, 0x96, 0x52, 0xe9, 0x93, 0x90,
-	0x7f, 0x57, 0x40, 0x26, 0xf4, 0xc5, 0x24, 0xaa, 0x72, 0x63, 0x1a, 0x2d, 0xde, 0x67, 0xd7, 0x77,
-	0xc6, 0x37, 0x20, 0x27, 0xd1, 0xd1, 0x85, 0x68, 0x8d, 0x22, 0x9b, 0x34, 0x7c, 0xae, 0x0a, 0x0e,
-	0x0c, 0x39, 0x49, 0x48, 0x29, 0x5e, 0xd8, 0x86, 0x84, 0x10, 0xf5, 0x8b, 0xff, 0x6b, 0xc0, 0x99,
-	0x75, 0xca, 0x68, 0x9b, 0xd1, 0xce, 0x75, 0x9b, 0xf6, 0x3a, 0x5f, 0x6b, 0xf9, 0x1c, 0x34, 0xc1,
-	0x32, 0x91, 0x26, 0x18, 0xf7, 0x3b, 0x3d, 0xdb, 0xa1, 0x5b, 0x91, 0x2e, 0x4a, 0x08, 0xe0, 0x1e,
-	0x62, 0x8f, 0x5f, 0x5c, 0x2e, 0xcb, 0x0f, 0x1b, 0x11, 0x48, 0xa0, 0xe1, 0x5c, 0xa8, 0x61, 0x6c,
-	0xc3, 0xd9, 0x24, 0xd3, 0x4a, 0x47, 0x4d, 0xc8, 0x89, 0xbd, 0x13, 0xda, 0xaf, 0xb1, 0x1d, 0x44,
-	0xa1, 0x25, 0x8e, 0x4f, 0x27, 0x8f, 0xc7, 0x3f, 0xe3, 0xd5, 0x6e, 0x74, 0xa7, 0x50, 0x2a, 0x37,
-	0x22, 0xe5, 0x60, 0xe5, 0x04, 0x7d, 0x0b, 0x4c, 0x36, 0x1a, 0x28, 0xbf, 0xda, 0x3a, 0xf3, 0xf9,
-	0xb8, 0x71, 0x3a, 0xb6, 0x6d, 0x67, 0x34, 0xa0, 0x44, 0xa0, 0x70, 0xdb, 0x6b, 0x5b, 0x5e, 0xc7,
-	0x76, 0xac, 0x9e, 0xcd, 0xa4, 0xac, 0x4c, 0x12, 0x05, 0xa1, 0x8b, 0x90, 0xf3, 0x0f, 0x28, 0x6b,
-	0xcb, 0xcc, 0xb9, 0xac, 0x8b, 0x00, 0x05, 0xc4, 0xbf, 0x89, 0x28, 0x5d, 0xda, 0xf3, 0x09, 0x95,
-	0x6e, 0x9c, 0x58, 0xe9, 0xc6, 0x7d, 0x94, 0x8e, 0x7f, 0x10, 0xaa, 0x48, 0x5f, 0x51, 0xa9, 0xe8,
-	0x45, 0x98, 0xef, 0xc4, 0x56, 0xa6, 0xab, 0x4a, 0xf6, 0x4e, 0x13, 0xe8, 0x78, 0x23, 0xd4, 0x88,
-	0x80, 0x4c, 0xd1, 0x48, 0x42, 0xcc, 0xe9, 0x63, 0x62, 0x7e, 0xfc, 0x51, 0x28, 0x06, 0x5f, 0x98,
-	0x50, 0x09, 0xf2, 0xd7, 0x5f, 0x23, 0x6f, 0x5e, 0x23, 0xeb, 0x0b, 0x29, 0x54, 0x86, 0x42, 0xeb,
-	0xda, 0xda, 0x2b, 0x62, 0x66, 0xac, 0x7e, 0x90, 0xd3, 0x61, 0xd9, 0x43, 0xdf, 0x85, 0xac, 0x8c,
-	0xb5, 0x67, 0xc3, 0xeb, 0x46, 0x3f, 0xe4, 0xd4, 0xce, 0x1d, 0x83, 0x4b, 0xbe, 0x71, 0xea, 0x49,
-	0x03, 0xdd, 0x84, 0x92, 0x00, 0xaa, 0xb6, 0xeb, 0x85, 0x64, 0xf7, 0x33, 0x46, 0xe9, 0xe2, 0x94,
-	0xd5, 0x08, 0xbd, 0xab, 0x90, 0x95, 0x22, 0x38, 0x9b, 0x48, 0x89, 0x26, 0xdc, 0x26, 0xd6, 0x88,
-	0xc6, 0x29, 0xf4, 0x1c, 0x98, 0x3b, 0x96, 0xdd, 0x43, 0x91, 0x8c, 0x2c, 0xd2, 0x2d, 0xad, 0x9d,
-	0x4d, 0x82, 0x23, 0xc7, 0xbe, 0x10, 0x34, 0x7d, 0xcf, 0x25, 0x3b, 0x4f, 0x7a, 0x7b, 0xf5, 0xf8,
-	0x42, 0x70, 0xf2, 0x6b, 0xb2, 0x35, 0xa9, 0xfb, 0x1f, 0xe8, 0x62, 0xfc, 0xa8, 0x44, 0xbb, 0xa4,
-	0x56, 0x9f, 0xb6, 0x1c, 0x10, 0xdc, 0x82, 0x52, 0xa4, 0xf7, 0x10, 0x15, 0xeb, 0xf1, 0xc6, 0x49,
-	0x54, 0xac, 0x13, 0x1a, 0x16, 0x38, 0x85, 0x36, 0xa0, 0xc0, 0xf3, 0x58, 0xf1, 0x8d, 0xe2, 0x7c,
-	0x32, 0x5d, 0x8d, 0xa4, 0x29, 0xb5, 0x0b, 0x93, 0x17, 0x03, 0x42, 0xdf, 0x87, 0xe2, 0x06, 0x65,
-	0xca, 0xd7, 0x9f, 0x4b, 0x06, 0x8b, 0x09, 0x92, 0x8a, 0x07, 0x1c, 0x9c, 0x42, 0x6f, 0x89, 0x94,
-	0x3a, 0xee, 0xeb, 0x50, 0x63, 0x8a, 0x4f, 0x0b, 0xee, 0xb5, 0x34, 0x1d, 0x21, 0xa0, 0xfc, 0x66,
-	0x8c, 0xb2, 0x8a, 0x8a, 0x8d, 0x29, 0x4f, 0x30, 0xa0, 0xdc, 0xb8, 0xcf, 0x7f, 0x0a, 0xe0, 0xd4,
-	0xea, 0xdb, 0xfa, 0x63, 0xf9, 0xba, 0xc5, 0x2c, 0xf4, 0x1a, 0xcc, 0x0b, 0x59, 0x06, 0x5f, 0xd3,
-	0x63, 0x36, 0x7f, 0xec, 0xd3, 0x7d, 0xcc, 0xe6, 0x8f, 0x7f, 0xc2, 0xc7, 0xa9, 0xd6, 0xdb, 0x1f,
-	0xdd, 0xab, 0xa7, 0x3e, 0xbe, 0x57, 0x4f, 0x7d, 0x76, 0xaf, 0x6e, 0xfc, 0xf8, 0xb0, 0x6e, 0xfc,
-	0xfe, 0xb0, 0x6e, 0x7c, 0x78, 0x58, 0x37, 0x3e, 0x3a, 0xac, 0x1b, 0xff, 0x3e, 0xac, 0x1b, 0xff,
-	0x39, 0xac, 0xa7, 0x3e, 0x3b, 0xac, 0x1b, 0xef, 0x7f, 0x5a, 0x4f, 0x7d, 0xf4, 0x69, 0x3d, 0xf5,
-	0xf1, 0xa7, 0xf5, 0xd4, 0x0f, 0x1f, 0xbb, 0x7f, 0xf9, 0x28, 0x1d, 0x5d, 0x4e, 0xfc, 0x3c, 0xf5,
-	0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x90, 0x3c, 0x9c, 0xf7, 0xd2, 0x21, 0x00, 0x00,
+	// 2681 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x3a, 0xcd, 0x6f, 0x1b, 0xc7,
+	0xf5, 0x5a, 0x72, 0x49, 0x91, 0x8f, 0x94, 0x2c, 0x8f, 0x68, 0x99, 0xa0, 0x6d, 0x52, 0x19, 0xfc,
+	0x7e, 0x8e, 0x1a, 0x3b, 0x62, 0xec, 0x34, 0x69, 0xe2, 0x34, 0x4d, 0x4d, 0x29, 0x76, 0xec, 0x28,
+	0x8e, 0x33, 0x72, 0xec, 0xb4, 0x68, 0x60, 0xac, 0xc9, 0x11, 0xb5, 0x30, 0xb9, 0x4b, 0xef, 0x0e,
+	0xe3, 0xf0, 0xd6, 0x7f, 0xa0, 0x68, 0x8a, 0x1e, 0xda, 0x5e, 0x0a, 0x14, 0x28, 0xd0, 0x22, 0x45,
+	0x6f, 0x3d, 0x16, 0xed, 0xa5, 0x87, 0xf4, 0x96, 0xde, 0x82, 0x1c, 0xd8, 0x5a, 0xb9, 0x14, 0x3a,
+	0x05, 0xc8, 0x2d, 0xa7, 0x62, 0xbe, 0x76, 0x67, 0x57, 0x64, 0x5d, 0x2a, 0x0e, 0x02, 0x5f, 0xc4,
+	0x99, 0x37, 0x6f, 0xde, 0xcc, 0xfb, 0x98, 0xf7, 0xb5, 0x82, 0x13, 0x83, 0xbb, 0xdd, 0x66, 0xcf,
+	0xef, 0x0e, 0x02, 0x9f, 0xf9, 0xd1, 0x60, 0x5d, 0xfc, 0x45, 0x05, 0x3d, 0xaf, 0x55, 0xba, 0x7e,
+	0xd7, 0x97, 0x38, 0x7c, 0x24, 0xd7, 0x6b, 0x8d, 0xae, 0xef, 0x77, 0x7b, 0xb4, 0x29, 0x66, 0x77,
+	0x86, 0x3b, 0x4d, 0xe6, 0xf6, 0x69, 0xc8, 0x9c, 0xfe, 0x40, 0x21, 0xac, 0x2a, 0xea, 0xf7, 0x7a,
+	0x7d, 0xbf, 0x43, 0x7b, 0xcd, 0x90, 0x39, 0x2c, 0x94, 0x7f, 0x15, 0xc6, 0x32, 0xc7, 0x18, 0x0c,
+	0xc3, 0x5d, 0xf1, 0x47, 0x02, 0xf1, 0x9f, 0x2c, 0x38, 0xb6, 0xe5, 0xdc, 0xa1, 0xbd, 0x1b, 0xfe,
+	0x4d, 0xa7, 0x37, 0xa4, 0x21, 0xa1, 0xe1, 0xc0, 0xf7, 0x42, 0x8a, 0x36, 0x20, 0xdf, 0xe3, 0x0b,
+	0x61, 0xd5, 0x5a, 0xcd, 0xae, 0x95, 0xce, 0x9f, 0x59, 0x8f, 0xae, 0x3c, 0x71, 0x83, 0x84, 0x86,
+	0xaf, 0x7a, 0x2c, 0x18, 0x11, 0xb5, 0xb5, 0x76, 0x13, 0x4a, 0x06, 0x18, 0x2d, 0x41, 0xf6, 0x2e,
+	0x1d, 0x55, 0xad, 0x55, 0x6b, 0xad, 0x48, 0xf8, 0x10, 0x9d, 0x83, 0xdc, 0x7b, 0x9c, 0x4c, 0x35,
+	0xb3, 0x6a, 0xad, 0x95, 0xce, 0x9f, 0x88, 0x0f, 0x79, 0xdb, 0x73, 0xef, 0x0d, 0xa9, 0xd8, 0xad,
+	0x0e, 0x92, 0x98, 0x17, 0x32, 0x2f, 0x58, 0xf8, 0x0c, 0x1c, 0x3d, 0xb0, 0x8e, 0x56, 0x20, 0x2f,
+	0x30, 0xe4, 0x8d, 0x8b, 0x44, 0xcd, 0x70, 0x05, 0xd0, 0x36, 0x0b, 0xa8, 0xd3, 0x27, 0x0e, 0xe3,
+	0xf7, 0xbd, 0x37, 0xa4, 0x21, 0xc3, 0x6f, 0xc0, 0x72, 0x02, 0xaa, 0xd8, 0x7e, 0x1e, 0x4a, 0x61,
+	0x0c, 0x56, 0xbc, 0x57, 0xe2, 0x6b, 0xc5, 0x7b, 0x88, 0x89, 0x88, 0x7f, 0x6d, 0x01, 0xc4, 0x6b,
+	0xa8, 0x0e, 0x20, 0x57, 0x5f, 0x73, 0xc2, 0x5d, 0xc1, 0xb0, 0x4d, 0x0c, 0x08, 0x3a, 0x0b, 0x47,
+	0xe3, 0xd9, 0x35, 0x7f, 0x7b, 0xd7, 0x09, 0x3a, 0x42, 0x06, 0x36, 0x39, 0xb8, 0x80, 0x10, 0xd8,
+	0x81, 0xc3, 0x68, 0x35, 0xbb, 0x6a, 0xad, 0x65, 0x89, 0x18, 0x73, 0x6e, 0x19, 0xf5, 0x1c, 0x8f,
+	0x55, 0x6d, 0x21, 0x4e, 0x35, 0xe3, 0x70, 0xae, 0x5f, 0x1a, 0x56, 0x73, 0xab, 0xd6, 0xda, 0x02,
+	0x51, 0x33, 0x7c, 0x0b, 0x8e, 0xdd, 0x08, 0x1c, 0x2f, 0xdc, 0xa1, 0xc1, 0xc6, 0xee, 0xd0, 0xbb,
+	0xab, 0x05, 0x81, 0x4e, 0xc3, 0xe2, 0x4e, 0xe0, 0xf7, 0xaf, 0x78, 0x5d, 0x1a, 0x32, 0x1a, 0x5c,
+	0xd9, 0x54, 0xfa, 0x49, 0x41, 0x39, 0xe1, 0x90, 0x06, 0x2e, 0x0d, 0xc5, 0x3d, 0xcb, 0x44, 0xcd,
+	0x70, 0x15, 0x56, 0xd2, 0x84, 0xa5, 0x2c, 0xf1, 0x87, 0x59, 0x28, 0xbf, 0x35, 0xa4, 0xc1, 0x48,
+	0x1f, 0x55, 0x87, 0x42, 0x48, 0x7b, 0xb4, 0xcd, 0xfc, 0x40, 0x1e, 0xd2, 0xca, 0x54, 0x2d, 0x12,
+	0xc1, 0x50, 0x05, 0x72, 0x3d, 0xb7, 0xef, 0x32, 0x71, 0xc2, 0x02, 0x91, 0x13, 0x74, 0x01, 0x72,
+	0x21, 0x73, 0x02, 0x26, 0xd8, 0x2f, 0x9d, 0xaf, 0xad, 0xcb, 0xb7, 0xb0, 0xae, 0xdf, 0xc2, 0xfa,
+	0x0d, 0xfd, 0x16, 0x5a, 0x85, 0x8f, 0xc6, 0x8d, 0xb9, 0x0f, 0xfe, 0xd9, 0xb0, 0x88, 0xdc, 0x82,
+	0x9e, 0x87, 0x2c, 0xf5, 0x3a, 0x55, 0x7b, 0x86, 0x9d, 0x7c, 0x03, 0x3a, 0x07, 0xc5, 0x8e, 0x1b,
+	0xd0, 0x36, 0x73, 0x7d, 0x4f, 0x08, 0x72, 0xf1, 0xfc, 0x72, 0x6c, 0x04, 0x9b, 0x7a, 0x89, 0xc4,
+	0x58, 0xe8, 0x2c, 0xe4, 0x43, 0xae, 0xad, 0xb0, 0x3a, 0xcf, 0xcd, 0xaf, 0x55, 0xd9, 0x1f, 0x37,
+	0x96, 0x24, 0xe4, 0xac, 0xdf, 0x77, 0x19, 0xed, 0x0f, 0xd8, 0x88, 0x28, 0x1c, 0xf4, 0x14, 0xcc,
+	0x77, 0x68, 0x8f, 0x72, 0x1b, 0x2b, 0x08, 0x1b, 0x5b, 0x32, 0xc8, 0x8b, 0x05, 0xa2, 0x11, 0xd0,
+	0xbb, 0x60, 0x0f, 0x7a, 0x8e, 0x57, 0x2d, 0x0a, 0x2e, 0x16, 0x63, 0xc4, 0xeb, 0x3d, 0xc7, 0x6b,
+	0xbd, 0xf8, 0xe9, 0xb8, 0xf1, 0x5c, 0xd7, 0x65, 0xbb, 0xc3, 0x3b, 0xeb, 0x6d, 0xbf, 0xdf, 0xec,
+	0x06, 0xce, 0x8e, 0xe3, 0x39, 0xcd, 0x9e, 0x7f, 0xd7, 0x6d, 0xbe, 0xf7, 0x6c, 0x93, 0x3f, 0xfb,
+	0x7b, 0x43, 0xae, 0xab, 0xa0, 0xc9, 0xc9, 0xac, 0x0b, 0x95, 0xf0, 0xad, 0x44, 0x90, 0xbd, 0x6a,
+	0x17, 0xf2, 0x4b, 0xf3, 0xf8, 0x41, 0x06, 0xd0, 0xb6, 0xd3, 0x1f, 0xf4, 0xe8, 0x4c, 0x2a, 0x8b,
+	0x94, 0x93, 0x39, 0xb4, 0x72, 0xb2, 0xb3, 0x2a, 0x27, 0x96, 0xb4, 0x3d, 0x9b, 0xa4, 0x73, 0xff,
+	0xab, 0xa4, 0xf3, 0x5f, 0x8b, 0xa4, 0x71, 0x15, 0x6c, 0x3e, 0xe3, 0x7e, 0x30, 0x70, 0xee, 0x0b,
+	0x79, 0x96, 0x09, 0x1f, 0xe2, 0x2d, 0xc8, 0xcb, 0xbb, 0xa0, 0x5a, 0x5a, 0xe0, 0xc9, 0xf7, 0x11,
+	0x0b, 0x3b, 0xab, 0xc5, 0xb8, 0x14, 0x8b, 0x31, 0x2b, 0x04, 0x84, 0xff, 0x6c, 0xc1, 0x82, 0xd2,
+	0xa2, 0x72, 0x6b, 0x77, 0x60, 0x5e, 0xba, 0x15, 0xed, 0xd2, 0x8e, 0xa7, 0x5d, 0xda, 0xc5, 0x8e,
+	0x33, 0x60, 0x34, 0x68, 0x35, 0x3f, 0x1a, 0x37, 0xac, 0x4f, 0xc7, 0x8d, 0x27, 0xa7, 0x31, 0xaa,
+	0xc3, 0x88, 0xda, 0x47, 0x34, 0x61, 0x74, 0x46, 0xdc, 0x8e, 0x85, 0xca, 0x14, 0x8e, 0xac, 0x8b,
+	0xd9, 0xba, 0x76, 0x21, 0x2d, 0x9b, 0x6b, 0x91, 0x48, 0x1c, 0xce, 0xe6, 0x7d, 0x27, 0xf0, 0x5c,
+	0xaf, 0x1b, 0x56, 0xb3, 0xc2, 0x5d, 0x47, 0x73, 0xfc, 0x4b, 0x0b, 0x96, 0x13, 0xa6, 0xa8, 0x98,
+	0x78, 0x21, 0xf2, 0x40, 0x56, 0x5a, 0x91, 0xdb, 0x02, 0xde, 0x5a, 0x54, 0x97, 0xcf, 0xcb, 0xb9,
+	0xf6, 0x51, 0x8f, 0xee, 0x6a, 0x7f, 0xb3, 0xa0, 0x2c, 0x62, 0x8e, 0x7e, 0x1f, 0x08, 0x6c, 0xcf,
+	0xe9, 0x53, 0xa5, 0x2a, 0x31, 0x36, 0x02, 0x11, 0x3f, 0xae, 0xa0, 0x03, 0xd1, 0xac, 0x8e, 0xcc,
+	0x3a, 0xb4, 0x23, 0xb3, 0xe2, 0xb7, 0x52, 0x81, 0x1c, 0x37, 0xc9, 0x91, 0x70, 0x62, 0x45, 0x22,
+	0x27, 0xf8, 0x49, 0x58, 0x50, 0x5c, 0x28, 0xd1, 0x4e, 0x8b, 0x9d, 0x7d, 0xc8, 0x4b, 0x4d, 0xa0,
+	0xff, 0x83, 0x62, 0x94, 0x73, 0x08, 0x6e, 0xb3, 0xad, 0xfc, 0xfe, 0xb8, 0x91, 0x61, 0x21, 0x89,
+	0x17, 0x50, 0xc3, 0x8c, 0xe7, 0x56, 0xab, 0xb8, 0x3f, 0x6e, 0x48, 0x80, 0x8a, 0xde, 0xe8, 0x24,
+	0xd8, 0xbb, 0x3c, 0x24, 0x72, 0x11, 0xd8, 0xad, 0xc2, 0xfe, 0xb8, 0x21, 0xe6, 0x44, 0xfc, 0xc5,
+	0x97, 0xa1, 0xbc, 0x45, 0xbb, 0x4e, 0x7b, 0xa4, 0x0e, 0xad, 0x68, 0x72, 0xfc, 0x40, 0x4b, 0xd3,
+	0x78, 0x02, 0xca, 0xd1, 0x89, 0xb7, 0xfb, 0xa1, 0x7a, 0x0d, 0xa5, 0x08, 0xf6, 0x46, 0x88, 0x7f,
+	0x65, 0x81, 0xb2, 0x01, 0x84, 0x8d, 0x44, 0x86, 0xfb, 0x2f, 0xd8, 0x1f, 0x37, 0x14, 0x44, 0xe7,
+	0x29, 0xe8, 0x25, 0x98, 0x0f, 0xc5, 0x89, 0x9c, 0x58, 0xda, 0xb4, 0xc4, 0x42, 0xeb, 0x08, 0x37,
+	0x91, 0xfd, 0x71, 0x43, 0x23, 0x12, 0x3d, 0x40, 0xeb, 0x89, 0x58, 0x2f, 0x19, 0x5b, 0xdc, 0x1f,
+	0x37, 0x0c, 0xa8, 0x19, 0xfb, 0xf1, 0x97, 0x16, 0x94, 0x6e, 0x38, 0x6e, 0x64, 0x42, 0x55, 0xad,
+	0xa2, 0xd8, 0xbf, 0x4a, 0x00, 0xb7, 0xc4, 0x0e, 0xed, 0x39, 0xa3, 0x4b, 0x7e, 0x20, 0xe8, 0x2e,
+	0x90, 0x68, 0x1e, 0xc7, 0x4a, 0x7b, 0x62, 0xac, 0xcc, 0xcd, 0xee, 0x8e, 0xbf, 0x5e, 0xe7, 0x77,
+	0xd5, 0x2e, 0x64, 0x96, 0xb2, 0xf8, 0x8f, 0x16, 0x94, 0x25, 0xf3, 0xca, 0xf2, 0x7e, 0x04, 0x79,
+	0x29, 0x1b, 0xc1, 0xfe, 0x7f, 0x71, 0x4c, 0x67, 0x66, 0x71, 0x4a, 0x8a, 0x26, 0x7a, 0x05, 0x16,
+	0x3b, 0x81, 0x3f, 0x18, 0xd0, 0xce, 0xb6, 0x72, 0x7f, 0x99, 0xb4, 0xfb, 0xdb, 0x34, 0xd7, 0x49,
+	0x0a, 0x1d, 0xff, 0xdd, 0x82, 0x05, 0xe5, 0x4c, 0x94, 0xba, 0x22, 0x11, 0x5b, 0x87, 0x8e, 0x78,
+	0x99, 0x59, 0x23, 0xde, 0x0a, 0xe4, 0xbb, 0x81, 0x3f, 0x1c, 0x68, 0x87, 0xa4, 0x66, 0xb3, 0x45,
+	0x42, 0x7c, 0x15, 0x16, 0x35, 0x2b, 0x53, 0x3c, 0x6a, 0x2d, 0xed, 0x51, 0xaf, 0x74, 0xa8, 0xc7,
+	0xdc, 0x1d, 0x37, 0xf2, 0x91, 0x3a, 0xeb, 0xfb, 0xa9, 0x05, 0x4b, 0x69, 0x14, 0xb4, 0x99, 0xaa,
+	0x19, 0x4e, 0x4f, 0x27, 0x67, 0x96, 0x0b, 0x9a, 0xb4, 0x2a, 0x1a, 0x9e, 0x7b, 0x58, 0xd1, 0x50,
+	0x31, 0x9d, 0x4c, 0x51, 0x79, 0x05, 0xfc, 0x0b, 0x0b, 0x16, 0x12, 0xba, 0x44, 0x2f, 0x80, 0xcd,
+	0x73, 0xd8, 0x99, 0x14, 0x25, 0x76, 0xa0, 0x6f, 0x43, 0x86, 0xf9, 0x33, 0xa9, 0x29, 0xc3, 0x7c,
+	0xae, 0x25, 0xc5, 0x7e, 0x56, 0xa6, 0xe4, 0x72, 0x86, 0x9f, 0x83, 0xa2, 0x60, 0xe8, 0xba, 0xe3,
+	0x06, 0x13, 0x03, 0xc6, 0x64, 0x86, 0x5e, 0x82, 0x23, 0xd2, 0x19, 0x4e, 0xde, 0x5c, 0x9e, 0xb4,
+	0xb9, 0xac, 0x37, 0x9f, 0x80, 0x9c, 0xc8, 0xc6, 0xf9, 0x96, 0x8e, 0xc3, 0x1c, 0xbd, 0x85, 0x8f,
+	0xf1, 0x31, 0x58, 0xe6, 0x6f, 0x90, 0x06, 0xe1, 0x86, 0x3f, 0xf4, 0x98, 0x2e, 0x89, 0xce, 0x42,
+	0x25, 0x09, 0x56, 0x56, 0x52, 0x81, 0x5c, 0x9b, 0x03, 0x04, 0x8d, 0x05, 0x22, 0x27, 0xf8, 0xb7,
+	0x16, 0xa0, 0xcb, 0x94, 0x89, 0x53, 0xae, 0x6c, 0x46, 0xcf, 0xa3, 0x06, 0x85, 0xbe, 0xc3, 0xda,
+	0xbb, 0x34, 0x08, 0x75, 0xfe, 0xa2, 0xe7, 0xdf, 0x44, 0xb2, 0x88, 0xcf, 0xc1, 0x72, 0xe2, 0x96,
+	0x8a, 0xa7, 0x1a, 0x14, 0xda, 0x0a, 0xa6, 0x42, 0x5e, 0x34, 0xc7, 0x5f, 0x64, 0xa0, 0x20, 0x36,
+	0x10, 0xba, 0x83, 0xce, 0x41, 0x69, 0xc7, 0xf5, 0xba, 0x34, 0x18, 0x04, 0xae, 0x12, 0x81, 0xdd,
+	0x3a, 0xb2, 0x3f, 0x6e, 0x98, 0x60, 0x62, 0x4e, 0xd0, 0xd3, 0x30, 0x3f, 0x0c, 0x69, 0x70, 0xdb,
+	0x95, 0x2f, 0xbd, 0xd8, 0xaa, 0xec, 0x8d, 0x1b, 0xf9, 0xb7, 0x43, 0x5e, 0x46, 0xf1, 0xe0, 0x33,
+	0x14, 0x23, 0x22, 0x7f, 0x3b, 0xe8, 0x75, 0x65, 0xa6, 0x22, 0x81, 0x6b, 0x7d, 0x87, 0x5f, 0x3f,
+	0xe5, 0xea, 0x06, 0x81, 0xdf, 0xa7, 0x6c, 0x97, 0x0e, 0xc3, 0x66, 0xdb, 0xef, 0xf7, 0x7d, 0xaf,
+	0x29, 0x8a, 0x7c, 0xc1, 0x34, 0x8f, 0xa0, 0x7c, 0xbb, 0xb2, 0xdc, 0x1b, 0x30, 0xcf, 0x76, 0x03,
+	0x7f, 0xd8, 0xdd, 0x15, 0x81, 0x21, 0xdb, 0xba, 0x30, 0x3b, 0x3d, 0x4d, 0x81, 0xe8, 0x01, 0x7a,
+	0x82, 0x4b, 0x8b, 0xb6, 0xef, 0x86, 0xc3, 0xbe, 0x2c, 0x2b, 0x5b, 0xb9, 0xfd, 0x71, 0xc3, 0x7a,
+	0x9a, 0x44, 0x60, 0xf4, 0x0a, 0x2c, 0x84, 0xcc, 0x0f, 0x9c, 0x2e, 0xbd, 0xdd, 0xee, 0x39, 0x61,
+	0x28, 0xc2, 0x48, 0xb1, 0x55, 0xdb, 0x1f, 0x37, 0x56, 0xd4, 0xc2, 0x06, 0x87, 0x1b, 0x7e, 0xa9,
+	0x6c, 0xc2, 0xf1, 0x4f, 0x32, 0xd0, 0x30, 0xca, 0xf9, 0x4b, 0x7e, 0xf0, 0x06, 0x65, 0x81, 0xdb,
+	0xbe, 0xe6, 0xf4, 0xa9, 0x36, 0xae, 0x06, 0x94, 0xfa, 0x02, 0x78, 0xdb, 0x78, 0x43, 0xd0, 0x8f,
+	0xf0, 0xd0, 0x29, 0x00, 0xf1, 0xe8, 0xe4, 0xba, 0x7c, 0x4e, 0x45, 0x01, 0x11, 0xcb, 0x1b, 0x09,
+	0x51, 0x37, 0x67, 0x14, 0x8d, 0x12, 0xf1, 0x95, 0xb4, 0x88, 0x67, 0xa6, 0x13, 0xc9, 0xd5, 0x7c,
+	0x2c, 0xb9, 0xe4, 0x63, 0xc1, 0xff, 0xb0, 0xa0, 0xbe, 0xa5, 0x6f, 0x7e, 0x48, 0x71, 0x68, 0x7e,
+	0x33, 0x8f, 0x88, 0xdf, 0xec, 0x57, 0xe3, 0x17, 0xd7, 0x01, 0xb6, 0x5c, 0x8f, 0x5e, 0x72, 0x7b,
+	0x8c, 0x06, 0x13, 0xca, 0xa0, 0x9f, 0x67, 0x63, 0x9f, 0x42, 0xe8, 0x8e, 0xe6, 0x73, 0xc3, 0x70,
+	0xe4, 0x8f, 0x82, 0x8d, 0xcc, 0x23, 0x54, 0x5b, 0x36, 0xe5, 0xe3, 0x3c, 0x98, 0xdf, 0x11, 0xec,
+	0xc9, 0x98, 0x9c, 0x68, 0x1e, 0xc5, 0xbc, 0xb7, 0xbe, 0xa7, 0x0e, 0x7f, 0xfe, 0x21, 0x29, 0x95,
+	0x68, 0xe9, 0x35, 0xc3, 0x91, 0xc7, 0x9c, 0xf7, 0x8d, 0xfd, 0x44, 0x1f, 0x82, 0x1c, 0x95, 0xb5,
+	0xe5, 0x26, 0x66, 0x6d, 0x2f, 0xab, 0x63, 0xbe, 0x52, 0xd9, 0xfa, 0x32, 0x2c, 0x27, 0x94, 0xa2,
+	0x5c, 0xe8, 0x69, 0xb0, 0x03, 0xba, 0xa3, 0x63, 0x3d, 0x8a, 0x4f, 0x8e, 0x30, 0xc5, 0x3a, 0xfe,
+	0x8b, 0x05, 0x4b, 0x97, 0x29, 0x4b, 0x66, 0x51, 0x8f, 0x91, 0x4a, 0xf1, 0x6b, 0x70, 0xd4, 0xb8,
+	0xbf, 0xe2, 0xfe, 0xd9, 0x54, 0xea, 0x74, 0x2c, 0xe6, 0xff, 0x8a, 0xd7, 0xa1, 0xef, 0xab, 0x8a,
+	0x34, 0x99, 0x35, 0x5d, 0x87, 0x92, 0xb1, 0x88, 0x2e, 0xa6, 0xf2, 0xa5, 0xe5, 0x54, 0x8f, 0x95,
+	0xc7, 0xfc, 0x56, 0x45, 0xf1, 0x24, 0xeb, 0x4e, 0x95, 0x0d, 0x47, 0xb9, 0xc5, 0x36, 0x20, 0xa1,
+	0x2e, 0x41, 0xd6, 0x8c, 0x6e, 0x02, 0xfa, 0x7a, 0x94, 0x38, 0x45, 0x73, 0xf4, 0x04, 0xd8, 0x81,
+	0x7f, 0x5f, 0x27, 0xc2, 0x0b, 0xf1, 0x91, 0xc4, 0xbf, 0x4f, 0xc4, 0x12, 0x7e, 0x09, 0xb2, 0xc4,
+	0xbf, 0xcf, 0x9b, 0x98, 0x81, 0xe3, 0x75, 0xe9, 0xcd, 0xa8, 0x04, 0x2b, 0x13, 0x03, 0x32, 0x25,
+	0xf3, 0xd8, 0x80, 0xa3, 0xe6, 0x8d, 0xa4, 0xba, 0xd7, 0x61, 0xfe, 0xad, 0xa1, 0x29, 0xae, 0x4a,
+	0x4a, 0x5c, 0x62, 0x0b, 0xd1, 0x48, 0xdc, 0x66, 0x20, 0x86, 0xa3, 0x93, 0x50, 0x64, 0xce, 0x9d,
+	0x1e, 0xbd, 0x16, 0xbb, 0xb9, 0x18, 0xc0, 0x57, 0x79, 0xf5, 0x78, 0xd3, 0x48, 0xa1, 0x62, 0x00,
+	0x7a, 0x0a, 0x96, 0xe2, 0x3b, 0x5f, 0x0f, 0xe8, 0x8e, 0xfb, 0xbe, 0xd0, 0x70, 0x99, 0x1c, 0x80,
+	0xa3, 0x35, 0x38, 0x12, 0xc3, 0xb6, 0x45, 0xaa, 0x62, 0x0b, 0xd4, 0x34, 0x98, 0xcb, 0x46, 0xb0,
+	0xfb, 0xea, 0xbd, 0xa1, 0xd3, 0x13, 0x8f, 0xaf, 0x4c, 0x0c, 0x08, 0xfe, 0xab, 0x05, 0x47, 0xa5,
+	0xaa, 0x99, 0xc3, 0x1e, 0x4b, 0xab, 0xff, 0x9d, 0x05, 0xc8, 0xe4, 0x40, 0x99, 0xd6, 0xff, 0x9b,
+	0x9d, 0x24, 0x9e, 0x0b, 0x95, 0x44, 0x51, 0x2c, 0x41, 0x71, 0x33, 0x08, 0x43, 0x5e, 0xe4, 0x53,
+	0xb2, 0x3a, 0xb7, 0x65, 0xd5, 0x2d, 0x21, 0x44, 0xfd, 0xf2, 0x66, 0xc1, 0x9d, 0x11, 0xa3, 0xa1,
+	0xaa, 0x99, 0x45, 0xb3, 0x40, 0x00, 0x88, 0xfc, 0xe1, 0x67, 0x51, 0x8f, 0x09, 0xab, 0xb1, 0xe3,
+	0xb3, 0x14, 0x88, 0xe8, 0x01, 0xfe, 0x43, 0x06, 0x16, 0x6e, 0xfa, 0xbd, 0x61, 0x9f, 0x3e, 0x86,
+	0x72, 0x4e, 0x16, 0xf2, 0x39, 0x5d, 0xc8, 0x23, 0xb0, 0x43, 0x46, 0x07, 0xc2, 0xb2, 0xb2, 0x44,
+	0x8c, 0x11, 0x86, 0x32, 0x73, 0x82, 0x2e, 0x65, 0xb2, 0x3c, 0xaa, 0xe6, 0x45, 0xde, 0x9a, 0x80,
+	0xa1, 0x55, 0x28, 0x39, 0xdd, 0x6e, 0x40, 0xbb, 0x0e, 0xa3, 0xad, 0x51, 0x75, 0x5e, 0x1c, 0x66,
+	0x82, 0xf0, 0x3b, 0xb0, 0xa8, 0x85, 0xa5, 0x54, 0xfa, 0x0c, 0xcc, 0xbf, 0x27, 0x20, 0x13, 0x1a,
+	0x6b, 0x12, 0x55, 0xb9, 0x31, 0x8d, 0x96, 0x6c, 0xd4, 0xeb, 0x3b, 0xe3, 0xab, 0x90, 0x97, 0xe8,
+	0xbc, 0xcb, 0x13, 0x67, 0x24, 0xb2, 0xcb, 0xc3, 0xe7, 0xaa, 0x62, 0xc1, 0x90, 0x97, 0x84, 0xaa,
+	0xd9, 0xd8, 0x36, 0x24, 0x84, 0xa8, 0x5f, 0xfc, 0x85, 0x05, 0xc7, 0x36, 0x29, 0xa3, 0x6d, 0x46,
+	0x3b, 0x97, 0x5c, 0xda, 0xeb, 0x7c, 0xa3, 0xf5, 0x77, 0xd4, 0x45, 0xcb, 0x1a, 0x5d, 0x34, 0xee,
+	0x77, 0x7a, 0xae, 0x47, 0xb7, 0x8c, 0x36, 0x4c, 0x0c, 0xe0, 0x1e, 0x62, 0x87, 0x5f, 0x5c, 0x2e,
+	0xcb, 0x8f, 0x31, 0x06, 0x24, 0xd2, 0x70, 0x3e, 0xd6, 0x30, 0x76, 0x61, 0x25, 0xcd, 0xb4, 0xd2,
+	0x51, 0x13, 0xf2, 0x62, 0xef, 0x84, 0xfe, 0x6d, 0x62, 0x07, 0x51, 0x68, 0xa9, 0xe3, 0x33, 0xe9,
+	0xe3, 0xf1, 0xcf, 0x78, 0xb9, 0x6c, 0xee, 0x14, 0x4a, 0xe5, 0x46, 0xa4, 0x1c, 0xac, 0x9c, 0xa0,
+	0x6f, 0x81, 0xcd, 0x46, 0x03, 0xe5, 0x57, 0x5b, 0xc7, 0xbe, 0x1c, 0x37, 0x8e, 0x26, 0xb6, 0xdd,
+	0x18, 0x0d, 0x28, 0x11, 0x28, 0xdc, 0xf6, 0xda, 0x4e, 0xd0, 0x71, 0x3d, 0xa7, 0xe7, 0x32, 0x29,
+	0x2b, 0x9b, 0x98, 0x20, 0x74, 0x0a, 0xf2, 0xe1, 0x5d, 0xca, 0xda, 0x32, 0x73, 0x2e, 0xeb, 0x2a,
+	0x42, 0x01, 0xf1, 0x6f, 0x0c, 0xa5, 0x4b, 0x7b, 0x3e, 0xa4, 0xd2, 0xad, 0x43, 0x2b, 0xdd, 0x7a,
+	0x88, 0xd2, 0xf1, 0x0f, 0x60, 0x25, 0x7d, 0x45, 0xa5, 0x22, 0xde, 0x6b, 0x4a, 0xac, 0x4c, 0x57,
+	0x95, 0x58, 0x27, 0x29, 0x74, 0x7c, 0x39, 0xd6, 0x88, 0x80, 0x4c, 0xd1, 0x48, 0x4a, 0xcc, 0x99,
+	0x03, 0x62, 0x7e, 0xea, 0x34, 0x14, 0xa3, 0x4f, 0x54, 0xa8, 0x04, 0xf3, 0x97, 0xde, 0x24, 0xb7,
+	0x2e, 0x92, 0xcd, 0xa5, 0x39, 0x54, 0x86, 0x42, 0xeb, 0xe2, 0xc6, 0xeb, 0x62, 0x66, 0x9d, 0xff,
+	0x30, 0xaf, 0xc3, 0x72, 0x80, 0xbe, 0x0b, 0x39, 0x19, 0x6b, 0x57, 0xe2, 0xeb, 0x9a, 0x5f, 0x82,
+	0x6a, 0xc7, 0x0f, 0xc0, 0xd5, 0x67, 0xbe, 0xb9, 0x67, 0x2c, 0x74, 0x0d, 0x4a, 0x02, 0xa8, 0xfa,
+	0xb6, 0x27, 0xd3, 0xed, 0xd3, 0x04, 0xa5, 0x53, 0x53, 0x56, 0x0d, 0x7a, 0x17, 0x20, 0x27, 0x45,
+	0xb0, 0x92, 0x4a, 0x89, 0x26, 0xdc, 0x26, 0xd1, 0xc9, 0xc6, 0x73, 0xe8, 0x45, 0xb0, 0x79, 0x1b,
+	0x03, 0x19, 0x19, 0x99, 0xd1, 0x6e, 0xad, 0xad, 0xa4, 0xc1, 0xc6, 0xb1, 0x2f, 0x47, 0x5d, 0xe3,
+	0xe3, 0xe9, 0xd6, 0x95, 0xde, 0x5e, 0x3d, 0xb8, 0x10, 0x9d, 0xfc, 0x26, 0x94, 0xcd, 0x06, 0x0a,
+	0x3a, 0x95, 0x3c, 0x2a, 0xd5, 0x6f, 0xa9, 0xd5, 0xa7, 0x2d, 0x47, 0x04, 0xb7, 0xa0, 0x64, 0x34,
+	0x2f, 0x4c, 0xb1, 0x1e, 0xec, 0xbc, 0xd4, 0x4e, 0x4d, 0x59, 0x8d, 0xa8, 0x5d, 0x86, 0x02, 0xcf,
+	0x63, 0xc5, 0x47, 0x8e, 0x13, 0xe9, 0x74, 0xd5, 0x48, 0x53, 0x6a, 0x27, 0x27, 0x2f, 0x46, 0x84,
+	0xbe, 0x0f, 0xc5, 0xcb, 0x94, 0x29, 0x5f, 0x7f, 0x3c, 0x1d, 0x2c, 0x26, 0x48, 0x2a, 0x19, 0x70,
+	0xf0, 0x1c, 0x7a, 0x47, 0xa4, 0xd4, 0x49, 0x5f, 0x87, 0x1a, 0x53, 0x7c, 0x5a, 0x74, 0xaf, 0xd5,
+	0xe9, 0x08, 0x11, 0xe5, 0x5b, 0x09, 0xca, 0x2a, 0x2a, 0x36, 0xa6, 0x3c, 0xc1, 0x88, 0x72, 0xe3,
+	0x21, 0xff, 0xdd, 0x80, 0xe7, 0xce, 0xbf, 0xab, 0x3f, 0xf0, 0x6f, 0x3a, 0xcc, 0x41, 0x6f, 0xc2,
+	0xa2, 0x90, 0x65, 0xf4, 0x1f, 0x00, 0x09, 0x9b, 0x3f, 0xf0, 0xef, 0x06, 0xb5, 0x53, 0x53, 0x56,
+	0x23, 0xf2, 0x6d, 0x28, 0xe8, 0xcf, 0x51, 0xe8, 0x16, 0x2c, 0x26, 0x3f, 0xa9, 0x9b, 0x0c, 0x4c,
+	0xfc, 0x8a, 0x5f, 0x5b, 0x9d, 0x8e, 0xa0, 0x8f, 0x58, 0xb3, 0x5a, 0xef, 0x7e, 0xfc, 0xa0, 0x3e,
+	0xf7, 0xc9, 0x83, 0xfa, 0xdc, 0xe7, 0x0f, 0xea, 0xd6, 0x8f, 0xf7, 0xea, 0xd6, 0xef, 0xf7, 0xea,
+	0xd6, 0x47, 0x7b, 0x75, 0xeb, 0xe3, 0xbd, 0xba, 0xf5, 0xaf, 0xbd, 0xba, 0xf5, 0xef, 0xbd, 0xfa,
+	0xdc, 0xe7, 0x7b, 0x75, 0xeb, 0x83, 0xcf, 0xea, 0x73, 0x1f, 0x7f, 0x56, 0x9f, 0xfb, 0xe4, 0xb3,
+	0xfa, 0xdc, 0x0f, 0x9f, 0x7c, 0x78, 0x8d, 0x2a, 0xbd, 0x69, 0x5e, 0xfc, 0x3c, 0xfb, 0x9f, 0x01,
+	0x00, 0x27, 0xc5, 0x05, 0x76, 0xeb, 0x22, 0x00, 0x00,
 }
 
 func (x Direction) String() string {
@@ -3349,6 +3454,54 @@ func (this *StreamRate) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *TransferChunksRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TransferChunksRequest)
+	if !ok {
+		that2, ok := that.(TransferChunksRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.FromIngesterID != that1.FromIngesterID {
+		return false
+	}
+	if !bytes.Equal(this.Series, that1.Series) {
+		return false
+	}
+	return true
+}
+func (this *TransferChunksResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TransferChunksResponse)
+	if !ok {
+		that2, ok := that.(TransferChunksResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	return true
+}
 func (this *QueryRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -4210,6 +4363,9 @@ func (this *ChunkRef) Equal(that interface{}) bool {
 	if this.Checksum != that1.Checksum {
 		return false
 	}
+	if this.StorageClass != that1.StorageClass {
+		return false
+	}
 	return true
 }
 func (this *LabelValuesForMetricNameRequest) Equal(that interface{}) bool {
@@ -5018,6 +5174,26 @@ func (this *StreamRate) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *TransferChunksRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&logproto.TransferChunksRequest{")
+	s = append(s, "FromIngesterID: "+fmt.Sprintf("%#v", this.FromIngesterID)+",\n")
+	s = append(s, "Series: "+fmt.Sprintf("%#v", this.Series)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TransferChunksResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&logproto.TransferChunksResponse{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *QueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -5155,9 +5331,9 @@ func (this *Series) GoString() string {
 	s = append(s, "&logproto.Series{")
 	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
 	if this.Samples != nil {
-		vs := make([]*Sample, len(this.Samples))
+		vs := make([]Sample, len(this.Samples))
 		for i := range vs {
-			vs[i] = &this.Samples[i]
+			vs[i] = this.Samples[i]
 		}
 		s = append(s, "Samples: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
@@ -5212,9 +5388,9 @@ func (this *SeriesResponse) GoString() string {
 	s := make([]string, 0, 5)
 	s = append(s, "&logproto.SeriesResponse{")
 	if this.Series != nil {
-		vs := make([]*SeriesIdentifier, len(this.Series))
+		vs := make([]SeriesIdentifier, len(this.Series))
 		for i := range vs {
-			vs[i] = &this.Series[i]
+			vs[i] = this.Series[i]
 		}
 		s = append(s, "Series: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
@@ -5228,9 +5404,9 @@ func (this *SeriesIdentifier) GoString() string {
 	s := make([]string, 0, 5)
 	s = append(s, "&logproto.SeriesIdentifier{")
 	if this.Labels != nil {
-		vs := make([]*SeriesIdentifier_LabelsEntry, len(this.Labels))
+		vs := make([]SeriesIdentifier_LabelsEntry, len(this.Labels))
 		for i := range vs {
-			vs[i] = &this.Labels[i]
+			vs[i] = this.Labels[i]
 		}
 		s = append(s, "Labels: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
@@ -5337,13 +5513,14 @@ func (this *ChunkRef) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&logproto.ChunkRef{")
 	s = append(s, "Fingerprint: "+fmt.Sprintf("%#v", this.Fingerprint)+",\n")
 	s = append(s, "UserID: "+fmt.Sprintf("%#v", this.UserID)+",\n")
 	s = append(s, "From: "+fmt.Sprintf("%#v", this.From)+",\n")
 	s = append(s, "Through: "+fmt.Sprintf("%#v", this.Through)+",\n")
 	s = append(s, "Checksum: "+fmt.Sprintf("%#v", this.Checksum)+",\n")
+	s = append(s, "StorageClass: "+fmt.Sprintf("%#v", this.StorageClass)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -5428,9 +5605,9 @@ func (this *GetSeriesResponse) GoString() string {
 	s := make([]string, 0, 5)
 	s = append(s, "&logproto.GetSeriesResponse{")
 	if this.Series != nil {
-		vs := make([]*IndexSeries, len(this.Series))
+		vs := make([]IndexSeries, len(this.Series))
 		for i := range vs {
-			vs[i] = &this.Series[i]
+			vs[i] = this.Series[i]
 		}
 		s = append(s, "Series: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
@@ -5545,9 +5722,9 @@ func (this *VolumeResponse) GoString() string {
 	s := make([]string, 0, 6)
 	s = append(s, "&logproto.VolumeResponse{")
 	if this.Volumes != nil {
-		vs := make([]*Volume, len(this.Volumes))
+		vs := make([]Volume, len(this.Volumes))
 		for i := range vs {
-			vs[i] = &this.Volumes[i]
+			vs[i] = this.Volumes[i]
 		}
 		s = append(s, "Volumes: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
@@ -6253,6 +6430,112 @@ var _StreamData_serviceDesc = grpc.ServiceDesc{
 	Metadata: "pkg/logproto/logproto.proto",
 }
 
+// IngesterClient is the client API for Ingester service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IngesterClient interface {
+	TransferChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferChunksClient, error)
+}
+
+type ingesterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIngesterClient(cc *grpc.ClientConn) IngesterClient {
+	return &ingesterClient{cc}
+}
+
+func (c *ingesterClient) TransferChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferChunksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[0], "/logproto.Ingester/TransferChunks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterTransferChunksClient{stream}
+	return x, nil
+}
+
+type Ingester_TransferChunksClient interface {
+	Send(*TransferChunksRequest) error
+	CloseAndRecv() (*TransferChunksResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterTransferChunksClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterTransferChunksClient) Send(m *TransferChunksRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterTransferChunksClient) CloseAndRecv() (*TransferChunksResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferChunksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterServer is the server API for Ingester service.
+type IngesterServer interface {
+	TransferChunks(Ingester_TransferChunksServer) error
+}
+
+// UnimplementedIngesterServer can be embedded to have forward compatible implementations.
+type UnimplementedIngesterServer struct {
+}
+
+func (*UnimplementedIngesterServer) TransferChunks(srv Ingester_TransferChunksServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransferChunks not implemented")
+}
+
+func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
+	s.RegisterService(&_Ingester_serviceDesc, srv)
+}
+
+func _Ingester_TransferChunks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServer).TransferChunks(&ingesterTransferChunksServer{stream})
+}
+
+type Ingester_TransferChunksServer interface {
+	SendAndClose(*TransferChunksResponse) error
+	Recv() (*TransferChunksRequest, error)
+	grpc.ServerStream
+}
+
+type ingesterTransferChunksServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterTransferChunksServer) SendAndClose(m *TransferChunksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterTransferChunksServer) Recv() (*TransferChunksRequest, error) {
+	m := new(TransferChunksRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Ingester_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "logproto.Ingester",
+	HandlerType: (*IngesterServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TransferChunks",
+			Handler:       _Ingester_TransferChunks_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/logproto/logproto.proto",
+}
+
 func (m *LabelToValuesResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -6439,8 +6722,68 @@ func (m *StreamRate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	if m.StreamHash != 0 {
 		i = encodeVarintLogproto(dAtA, i, uint64(m.StreamHash))
 		i--
-		dAtA[i] = 0x8
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferChunksRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferChunksRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferChunksRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Series) > 0 {
+		i -= len(m.Series)
+		copy(dAtA[i:], m.Series)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Series)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.FromIngesterID) > 0 {
+		i -= len(m.FromIngesterID)
+		copy(dAtA[i:], m.FromIngesterID)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.FromIngesterID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferChunksResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferChunksResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferChunksResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
 	return len(dAtA) - i, nil
 }
 
@@ -7595,6 +7938,13 @@ func (m *ChunkRef) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.StorageClass) > 0 {
+		i -= len(m.StorageClass)
+		copy(dAtA[i:], m.StorageClass)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.StorageClass)))
+		i--
+		dAtA[i] = 0x32
+	}
 	if m.Checksum != 0 {
 		i = encodeVarintLogproto(dAtA, i, uint64(m.Checksum))
 		i--
@@ -8737,6 +9087,32 @@ func (m *StreamRate) Size() (n int) {
 	return n
 }
 
+func (m *TransferChunksRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.FromIngesterID)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	l = len(m.Series)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	return n
+}
+
+func (m *TransferChunksResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
 func (m *QueryRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -9240,6 +9616,10 @@ func (m *ChunkRef) Size() (n int) {
 	if m.Checksum != 0 {
 		n += 1 + sovLogproto(uint64(m.Checksum))
 	}
+	l = len(m.StorageClass)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -9776,6 +10156,26 @@ func (this *StreamRate) String() string {
 	}, "")
 	return s
 }
+func (this *TransferChunksRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TransferChunksRequest{`,
+		`FromIngesterID:` + fmt.Sprintf("%v", this.FromIngesterID) + `,`,
+		`Series:` + fmt.Sprintf("%v", this.Series) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TransferChunksResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TransferChunksResponse{`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -10107,6 +10507,7 @@ func (this *ChunkRef) String() string {
 		`From:` + fmt.Sprintf("%v", this.From) + `,`,
 		`Through:` + fmt.Sprintf("%v", this.Through) + `,`,
 		`Checksum:` + fmt.Sprintf("%v", this.Checksum) + `,`,
+		`StorageClass:` + fmt.Sprintf("%v", this.StorageClass) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -10995,6 +11396,178 @@ func (m *StreamRate) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TransferChunksRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransferChunksRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransferChunksRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromIngesterID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FromIngesterID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Series = append(m.Series[:0], dAtA[iNdEx:postIndex]...)
+			if m.Series == nil {
+				m.Series = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransferChunksResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransferChunksResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransferChunksResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *QueryRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -14433,6 +15006,38 @@ func (m *ChunkRef) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StorageClass", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StorageClass = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -40,6 +40,40 @@ type StreamSampleExtractor interface {
 	ReferencedStructuredMetadata() bool
 }
 
+// LineRequirer is implemented by stream sample extractors which know whether they need
+// the content of log lines. Extractors only relying on timestamps, stream labels and
+// structured metadata allow chunks to skip decompressing log lines.
+type LineRequirer interface {
+	RequiresLine() bool
+}
+
+// RequiresLine returns whether the extractor needs the content of log lines.
+// Extractors that don't implement LineRequirer are assumed to need it.
+func RequiresLine(e StreamSampleExtractor) bool {
+	if r, ok := e.(LineRequirer); ok {
+		return r.RequiresLine()
+	}
+	return true
+}
+
+// stagesRequireLine returns whether any of the stages reads or modifies the log line.
+func stagesRequireLine(stages ...Stage) bool {
+	for _, s := range stages {
+		switch s := s.(type) {
+		case *noopStage, *NoopLabelFilter, *StringLabelFilter, *LineFilterLabelFilter,
+			*NumericLabelFilter, *DurationLabelFilter, *BytesLabelFilter, *IPLabelFilter,
+			*DropLabels, *KeepLabels:
+		case *BinaryLabelFilter:
+			if stagesRequireLine(s.Left, s.Right) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 // SampleExtractorWrapper takes an extractor, wraps it is some desired functionality
 // and returns a new pipeline
 type SampleExtractorWrapper interface {
@@ -52,11 +86,23 @@ type lineSampleExtractor struct {
 
 	baseBuilder      *BaseLabelsBuilder
 	streamExtractors map[uint64]StreamSampleExtractor
+	requiresLine     bool
 }
 
 // NewLineSampleExtractor creates a SampleExtractor from a LineExtractor.
 // Multiple log stages are run before converting the log line.
 func NewLineSampleExtractor(ex LineExtractor, stages []Stage, groups []string, without, noLabels bool) (SampleExtractor, error) {
+	return newLineSampleExtractor(ex, true, stages, groups, without, noLabels), nil
+}
+
+// NewCountSampleExtractor creates a SampleExtractor counting the log lines
+// passing the stages. Unlike the other line extractors, it doesn't need the
+// content of the lines unless one of the stages does.
+func NewCountSampleExtractor(stages []Stage, groups []string, without, noLabels bool) (SampleExtractor, error) {
+	return newLineSampleExtractor(CountExtractor, stagesRequireLine(stages...), stages, groups, without, noLabels), nil
+}
+
+func newLineSampleExtractor(ex LineExtractor, requiresLine bool, stages []Stage, groups []string, without, noLabels bool) *lineSampleExtractor {
 	s := ReduceStages(stages)
 	hints := NewParserHint(s.RequiredLabelNames(), groups, without, noLabels, "", stages)
 	return &lineSampleExtractor{
@@ -64,7 +110,8 @@ func NewLineSampleExtractor(ex LineExtractor, stages []Stage, groups []string, w
 		LineExtractor:    ex,
 		baseBuilder:      NewBaseLabelsBuilderWithGrouping(groups, hints, without, noLabels),
 		streamExtractors: make(map[uint64]StreamSampleExtractor),
-	}, nil
+		requiresLine:     requiresLine,
+	}
 }
 
 func (l *lineSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtractor {
@@ -77,6 +124,7 @@ func (l *lineSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtrac
 		Stage:         l.Stage,
 		LineExtractor: l.LineExtractor,
 		builder:       l.baseBuilder.ForLabels(labels, hash),
+		requiresLine:  l.requiresLine,
 	}
 	l.streamExtractors[hash] = res
 	return res
@@ -85,7 +133,12 @@ func (l *lineSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtrac
 type streamLineSampleExtractor struct {
 	Stage
 	LineExtractor
-	builder *LabelsBuilder
+	builder      *LabelsBuilder
+	requiresLine bool
+}
+
+func (l *streamLineSampleExtractor) RequiresLine() bool {
+	return l.requiresLine
 }
 
 func (l *streamLineSampleExtractor) ReferencedStructuredMetadata() bool {
@@ -125,6 +178,7 @@ type labelSampleExtractor struct {
 
 	baseBuilder      *BaseLabelsBuilder
 	streamExtractors map[uint64]StreamSampleExtractor
+	requiresLine     bool
 }
 
 // LabelExtractorWithStages creates a SampleExtractor that will extract metrics from a labels.
@@ -161,6 +215,7 @@ func LabelExtractorWithStages(
 		postFilter:       postFilter,
 		baseBuilder:      NewBaseLabelsBuilderWithGrouping(groups, hints, without, noLabels),
 		streamExtractors: make(map[uint64]StreamSampleExtractor),
+		requiresLine:     stagesRequireLine(append(preStages, postFilter)...),
 	}, nil
 }
 
@@ -173,6 +228,10 @@ func (l *labelSampleExtractor) ReferencedStructuredMetadata() bool {
 	return l.baseBuilder.referencedStructuredMetadata
 }
 
+func (l *labelSampleExtractor) RequiresLine() bool {
+	return l.requiresLine
+}
+
 func (l *labelSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtractor {
 	hash := l.baseBuilder.Hash(labels)
 	if res, ok := l.streamExtractors[hash]; ok {
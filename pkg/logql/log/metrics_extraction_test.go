@@ -361,6 +361,63 @@ func TestNewLineSampleExtractor(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestRequiresLine(t *testing.T) {
+	lbs := labels.FromStrings("namespace", "dev")
+	traceFilter := NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "123"))
+	lineFilter := mustFilter(NewFilter("foo", LineMatchEqual)).ToStage()
+
+	for _, tc := range []struct {
+		name         string
+		ex           SampleExtractor
+		requiresLine bool
+	}{
+		{
+			name: "count",
+			ex:   mustSampleExtractor(NewCountSampleExtractor(nil, nil, false, false)),
+		},
+		{
+			name: "count with label filters",
+			ex: mustSampleExtractor(NewCountSampleExtractor([]Stage{
+				NewAndLabelFilter(traceFilter, NewNumericLabelFilter(LabelFilterGreaterThan, "status", 400)),
+				NewDropLabels([]DropLabel{{Name: "trace_id"}}),
+			}, nil, false, false)),
+		},
+		{
+			name:         "count with line filter",
+			ex:           mustSampleExtractor(NewCountSampleExtractor([]Stage{traceFilter, lineFilter}, nil, false, false)),
+			requiresLine: true,
+		},
+		{
+			name:         "count with parser",
+			ex:           mustSampleExtractor(NewCountSampleExtractor([]Stage{NewLogfmtParser(false, false)}, nil, false, false)),
+			requiresLine: true,
+		},
+		{
+			name:         "count line extractor",
+			ex:           mustSampleExtractor(NewLineSampleExtractor(CountExtractor, nil, nil, false, false)),
+			requiresLine: true,
+		},
+		{
+			name:         "bytes",
+			ex:           mustSampleExtractor(NewLineSampleExtractor(BytesExtractor, []Stage{traceFilter}, nil, false, false)),
+			requiresLine: true,
+		},
+		{
+			name: "unwrap structured metadata",
+			ex:   mustSampleExtractor(LabelExtractorWithStages("duration", ConvertDuration, nil, false, false, []Stage{traceFilter}, NoopStage)),
+		},
+		{
+			name:         "unwrap parsed label",
+			ex:           mustSampleExtractor(LabelExtractorWithStages("duration", ConvertDuration, nil, false, false, []Stage{NewLogfmtParser(false, false)}, NoopStage)),
+			requiresLine: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.requiresLine, RequiresLine(tc.ex.ForStream(lbs)))
+		})
+	}
+}
+
 func TestNewLineSampleExtractorWithStructuredMetadata(t *testing.T) {
 	lbs := labels.FromStrings("foo", "bar")
 	structuredMetadata := labels.FromStrings("user", "bob")
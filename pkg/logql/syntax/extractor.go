@@ -75,7 +75,7 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 	// otherwise we extract metrics from the log line.
 	switch r.Operation {
 	case OpRangeTypeRate, OpRangeTypeCount, OpRangeTypeAbsent:
-		return log.NewLineSampleExtractor(log.CountExtractor, stages, groups, without, noLabels)
+		return log.NewCountSampleExtractor(stages, groups, without, noLabels)
 	case OpRangeTypeBytes, OpRangeTypeBytesRate:
 		return log.NewLineSampleExtractor(log.BytesExtractor, stages, groups, without, noLabels)
 	default:
@@ -89,11 +89,12 @@ func FromContext(ctx context.Context) *Context {
 // Ingester returns the ingester statistics accumulated so far.
 func (c *Context) Ingester() Ingester {
 	return Ingester{
-		TotalReached:       c.ingester.TotalReached,
-		TotalChunksMatched: c.ingester.TotalChunksMatched,
-		TotalBatches:       c.ingester.TotalBatches,
-		TotalLinesSent:     c.ingester.TotalLinesSent,
-		Store:              c.store,
+		TotalReached:        c.ingester.TotalReached,
+		TotalChunksMatched:  c.ingester.TotalChunksMatched,
+		TotalBatches:        c.ingester.TotalBatches,
+		TotalLinesSent:      c.ingester.TotalLinesSent,
+		TotalBlockCacheHits: c.ingester.TotalBlockCacheHits,
+		Store:               c.store,
 	}
 }
 
@@ -228,6 +229,7 @@ func (i *Ingester) Merge(m Ingester) {
 	i.TotalLinesSent += m.TotalLinesSent
 	i.TotalChunksMatched += m.TotalChunksMatched
 	i.TotalReached += m.TotalReached
+	i.TotalBlockCacheHits += m.TotalBlockCacheHits
 }
 
 func (i *Index) Merge(m Index) {
@@ -341,6 +343,10 @@ func (c *Context) AddIngesterReached(i int32) {
 	atomic.AddInt32(&c.ingester.TotalReached, i)
 }
 
+func (c *Context) AddIngesterBlockCacheHits(i int64) {
+	atomic.AddInt64(&c.ingester.TotalBlockCacheHits, i)
+}
+
 func (c *Context) AddHeadChunkLines(i int64) {
 	atomic.AddInt64(&c.store.Chunk.HeadChunkLines, i)
 }
@@ -525,6 +531,7 @@ func (r Result) Log(log log.Logger) {
 		"Ingester.TotalChunksMatched", r.Ingester.TotalChunksMatched,
 		"Ingester.TotalBatches", r.Ingester.TotalBatches,
 		"Ingester.TotalLinesSent", r.Ingester.TotalLinesSent,
+		"Ingester.TotalBlockCacheHits", r.Ingester.TotalBlockCacheHits,
 		"Ingester.TotalChunksRef", r.Ingester.Store.TotalChunksRef,
 		"Ingester.TotalChunksDownloaded", r.Ingester.Store.TotalChunksDownloaded,
 		"Ingester.ChunksDownloadTime", time.Duration(r.Ingester.Store.ChunksDownloadTime),
@@ -172,10 +172,11 @@ func TestResult_Merge(t *testing.T) {
 
 	toMerge := Result{
 		Ingester: Ingester{
-			TotalChunksMatched: 200,
-			TotalBatches:       50,
-			TotalLinesSent:     60,
-			TotalReached:       2,
+			TotalChunksMatched:  200,
+			TotalBatches:        50,
+			TotalLinesSent:      60,
+			TotalReached:        2,
+			TotalBlockCacheHits: 3,
 			Store: Store{
 				PipelineWrapperFilteredLines: 4,
 				Chunk: Chunk{
@@ -237,9 +238,10 @@ func TestResult_Merge(t *testing.T) {
 	res.Merge(toMerge)
 	require.Equal(t, Result{
 		Ingester: Ingester{
-			TotalChunksMatched: 2 * 200,
-			TotalBatches:       2 * 50,
-			TotalLinesSent:     2 * 60,
+			TotalChunksMatched:  2 * 200,
+			TotalBatches:        2 * 50,
+			TotalLinesSent:      2 * 60,
+			TotalBlockCacheHits: 2 * 3,
 			Store: Store{
 				PipelineWrapperFilteredLines: 8,
 				Chunk: Chunk{
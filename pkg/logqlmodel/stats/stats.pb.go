@@ -468,6 +468,8 @@ type Ingester struct {
 	// Total lines sent by ingesters.
 	TotalLinesSent int64 `protobuf:"varint,4,opt,name=totalLinesSent,proto3" json:"totalLinesSent"`
 	Store          Store `protobuf:"bytes,5,opt,name=store,proto3" json:"store"`
+	// Total of chunk blocks read from the decompressed block cache of ingesters.
+	TotalBlockCacheHits int64 `protobuf:"varint,6,opt,name=totalBlockCacheHits,proto3" json:"totalBlockCacheHits"`
 }
 
 func (m *Ingester) Reset()      { *m = Ingester{} }
@@ -537,6 +539,13 @@ func (m *Ingester) GetStore() Store {
 	return Store{}
 }
 
+func (m *Ingester) GetTotalBlockCacheHits() int64 {
+	if m != nil {
+		return m.TotalBlockCacheHits
+	}
+	return 0
+}
+
 type Store struct {
 	// The total of chunk reference fetched from index.
 	TotalChunksRef int64 `protobuf:"varint,1,opt,name=totalChunksRef,proto3" json:"totalChunksRef"`
@@ -873,94 +882,94 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 1378 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x58, 0xcf, 0x6f, 0xdc, 0x44,
-	0x14, 0xde, 0xcd, 0xd6, 0x9b, 0x74, 0xf2, 0xab, 0x9d, 0xa4, 0x74, 0x4b, 0x2b, 0x3b, 0x2c, 0x54,
-	0x14, 0x21, 0x65, 0x55, 0x8a, 0x84, 0x40, 0x54, 0x42, 0x4e, 0x89, 0x54, 0x29, 0x15, 0xe5, 0x2d,
-	0x08, 0x04, 0x27, 0xc7, 0x9e, 0xec, 0x5a, 0xf5, 0xda, 0x8e, 0x3d, 0x0e, 0xcd, 0x09, 0xfe, 0x04,
-	0xee, 0xdc, 0x11, 0x17, 0x4e, 0x5c, 0xe0, 0xcc, 0xa5, 0xc7, 0x1e, 0x7b, 0xb2, 0xe8, 0xe6, 0x82,
-	0x7c, 0xea, 0x1f, 0xc0, 0x01, 0xcd, 0x9b, 0x59, 0xff, 0x5a, 0x6f, 0x9a, 0xcb, 0x7a, 0xde, 0xf7,
-	0xbe, 0xef, 0xcd, 0xf8, 0x79, 0xe6, 0xbd, 0xd1, 0x92, 0x9d, 0xf0, 0xc9, 0x68, 0xe0, 0x05, 0xa3,
-	0x63, 0x6f, 0x12, 0x38, 0xcc, 0x1b, 0xc4, 0xdc, 0xe2, 0xb1, 0xfc, 0xdd, 0x0d, 0xa3, 0x80, 0x07,
-	0x54, 0x43, 0xe3, 0xcd, 0xed, 0x51, 0x30, 0x0a, 0x10, 0x19, 0x88, 0x91, 0x74, 0xf6, 0x7f, 0x5d,
-	0x22, 0x5d, 0x60, 0x71, 0xe2, 0x71, 0xfa, 0x31, 0x59, 0x8e, 0x93, 0xc9, 0xc4, 0x8a, 0x4e, 0x7b,
-	0xed, 0x9d, 0xf6, 0x9d, 0xd5, 0x0f, 0x36, 0x76, 0x65, 0x98, 0xa1, 0x44, 0xcd, 0xcd, 0x67, 0xa9,
-	0xd1, 0xca, 0x52, 0x63, 0x46, 0x83, 0xd9, 0x40, 0x48, 0x8f, 0x13, 0x16, 0xb9, 0x2c, 0xea, 0x2d,
-	0x55, 0xa4, 0x5f, 0x4a, 0xb4, 0x90, 0x2a, 0x1a, 0xcc, 0x06, 0xf4, 0x3e, 0x59, 0x71, 0xfd, 0x11,
-	0x8b, 0x39, 0x8b, 0x7a, 0x1d, 0xd4, 0x6e, 0x2a, 0xed, 0x43, 0x05, 0x9b, 0x57, 0x94, 0x38, 0x27,
-	0x42, 0x3e, 0xa2, 0x1f, 0x92, 0xae, 0x6d, 0xd9, 0x63, 0x16, 0xf7, 0x2e, 0xa1, 0x78, 0x5d, 0x89,
-	0xf7, 0x10, 0x34, 0xd7, 0x95, 0x54, 0x43, 0x12, 0x28, 0x2e, 0xbd, 0x4b, 0x34, 0xd7, 0x77, 0xd8,
-	0xd3, 0x9e, 0x86, 0xa2, 0xb5, 0x7c, 0x46, 0x87, 0x3d, 0x2d, 0x34, 0x48, 0x01, 0xf9, 0xe8, 0xff,
-	0x72, 0x89, 0x74, 0xf7, 0x72, 0xb5, 0x3d, 0x4e, 0xfc, 0x27, 0x2a, 0x4d, 0x6b, 0xe5, 0x29, 0x4b,
-	0x33, 0x0a, 0x0a, 0xc8, 0x47, 0x31, 0xe1, 0xd2, 0x79, 0x92, 0xf2, 0x84, 0xe2, 0xcd, 0x22, 0xfc,
-	0x30, 0x2a, 0x2d, 0x55, 0xcd, 0x86, 0xd2, 0x28, 0x0e, 0xa8, 0x27, 0xdd, 0x23, 0xab, 0x48, 0x93,
-	0xdf, 0x54, 0x25, 0xa5, 0x2a, 0xdd, 0x52, 0xd2, 0x32, 0x11, 0xca, 0x06, 0xdd, 0x27, 0x6b, 0x27,
-	0x81, 0x97, 0x4c, 0x98, 0x8a, 0xa2, 0x35, 0x44, 0xd9, 0x56, 0x51, 0x2a, 0x4c, 0xa8, 0x58, 0x22,
-	0x4e, 0x2c, 0xbe, 0xf2, 0x6c, 0x35, 0xdd, 0xf3, 0xe2, 0x94, 0x99, 0x50, 0xb1, 0xc4, 0x4b, 0x79,
-	0xd6, 0x21, 0xf3, 0x54, 0x98, 0xe5, 0xf3, 0x5e, 0xaa, 0x44, 0x84, 0xb2, 0x41, 0xbf, 0x27, 0x5b,
-	0xae, 0x1f, 0x73, 0xcb, 0xe7, 0x8f, 0x18, 0x8f, 0x5c, 0x5b, 0x05, 0x5b, 0x69, 0x08, 0x76, 0x53,
-	0x05, 0x6b, 0x12, 0x40, 0x13, 0xd8, 0xff, 0xab, 0x4b, 0x96, 0xd5, 0x31, 0xa1, 0x5f, 0x93, 0xeb,
-	0x87, 0xa7, 0x9c, 0xc5, 0x8f, 0xa3, 0xc0, 0x66, 0x71, 0xcc, 0x9c, 0xc7, 0x2c, 0x1a, 0x32, 0x3b,
-	0xf0, 0x1d, 0xdc, 0x30, 0x1d, 0xf3, 0x66, 0x96, 0x1a, 0x8b, 0x28, 0xb0, 0xc8, 0x21, 0xc2, 0x7a,
-	0xae, 0xdf, 0x18, 0x76, 0xa9, 0x08, 0xbb, 0x80, 0x02, 0x8b, 0x1c, 0xf4, 0x21, 0xd9, 0xe2, 0x01,
-	0xb7, 0x3c, 0xb3, 0x32, 0x2d, 0xee, 0xb9, 0x8e, 0x79, 0x5d, 0x24, 0xa1, 0xc1, 0x0d, 0x4d, 0x60,
-	0x1e, 0xea, 0xa0, 0x32, 0x15, 0xee, 0xc1, 0x72, 0xa8, 0xaa, 0x1b, 0x9a, 0x40, 0x7a, 0x87, 0xac,
-	0xb0, 0xa7, 0xcc, 0xfe, 0xca, 0x9d, 0x30, 0xdc, 0x7d, 0x6d, 0x73, 0x4d, 0x14, 0x80, 0x19, 0x06,
-	0xf9, 0x88, 0xbe, 0x4f, 0x2e, 0x1f, 0x27, 0x2c, 0x61, 0x48, 0xed, 0x22, 0x75, 0x3d, 0x4b, 0x8d,
-	0x02, 0x84, 0x62, 0x48, 0x77, 0x09, 0x89, 0x93, 0x43, 0x59, 0x7a, 0x62, 0xdc, 0x47, 0x1d, 0x73,
-	0x23, 0x4b, 0x8d, 0x12, 0x0a, 0xa5, 0x31, 0x3d, 0x20, 0xdb, 0xb8, 0xba, 0xcf, 0x7d, 0x2e, 0xb7,
-	0x23, 0x4f, 0x22, 0x9f, 0x39, 0xb8, 0x69, 0x3a, 0x66, 0x2f, 0x4b, 0x8d, 0x46, 0x3f, 0x34, 0xa2,
-	0xb4, 0x4f, 0xba, 0x71, 0xe8, 0xb9, 0x3c, 0xee, 0x5d, 0x46, 0x3d, 0x11, 0xe7, 0x57, 0x22, 0xa0,
-	0x9e, 0xc8, 0x19, 0x5b, 0x91, 0x13, 0xf7, 0x48, 0x89, 0x83, 0x08, 0xa8, 0x67, 0xbe, 0xaa, 0xc7,
-	0x41, 0xcc, 0xf7, 0x5d, 0x8f, 0xb3, 0x08, 0xb3, 0xd7, 0x5b, 0xad, 0xad, 0xaa, 0xe6, 0x87, 0x46,
-	0x94, 0xfe, 0x48, 0x6e, 0x23, 0x3e, 0xe4, 0x51, 0x62, 0xf3, 0x24, 0x62, 0xce, 0x23, 0xc6, 0x2d,
-	0xc7, 0xe2, 0x56, 0x6d, 0x4b, 0xac, 0x61, 0xf8, 0xf7, 0xb2, 0xd4, 0xb8, 0x98, 0x00, 0x2e, 0x46,
-	0xeb, 0xff, 0xd9, 0x26, 0x1a, 0x56, 0x5e, 0x7a, 0x97, 0xac, 0xa2, 0x64, 0x4f, 0xd4, 0xcc, 0x58,
-	0x9d, 0x96, 0x4d, 0x71, 0xaa, 0x4b, 0x30, 0x94, 0x0d, 0xfa, 0x19, 0xb9, 0x12, 0xe6, 0x2f, 0xa4,
-	0x74, 0xf2, 0x38, 0x6c, 0x67, 0xa9, 0x31, 0xe7, 0x83, 0x39, 0x84, 0x7e, 0x42, 0x36, 0x64, 0x5e,
-	0x1f, 0x24, 0x91, 0xc5, 0xdd, 0xc0, 0x57, 0x7b, 0x9f, 0x66, 0xa9, 0x51, 0xf3, 0x40, 0xcd, 0xee,
-	0x7f, 0x4a, 0x96, 0x55, 0x87, 0x13, 0x15, 0x3e, 0xe6, 0x41, 0xc4, 0x6a, 0x4d, 0x61, 0x28, 0xb0,
-	0xa2, 0xc2, 0x23, 0x05, 0xe4, 0xa3, 0xff, 0xfb, 0x12, 0x59, 0x79, 0x58, 0x34, 0xb2, 0x35, 0x7c,
-	0x2f, 0x60, 0xa2, 0x04, 0xc9, 0x52, 0xa1, 0x99, 0x57, 0x44, 0x65, 0x2c, 0xe3, 0x50, 0xb1, 0xe8,
-	0x3e, 0xa1, 0xa5, 0x6c, 0x3c, 0xb2, 0x38, 0x6a, 0x65, 0x02, 0xde, 0xc8, 0x52, 0xa3, 0xc1, 0x0b,
-	0x0d, 0x58, 0x3e, 0xbb, 0x89, 0x76, 0xac, 0x52, 0x50, 0xcc, 0xae, 0x70, 0xa8, 0x58, 0x22, 0x75,
-	0xc5, 0xe1, 0x1d, 0x32, 0x9f, 0xab, 0xb3, 0x8e, 0xa9, 0xab, 0x7a, 0xa0, 0x66, 0x17, 0xf9, 0xd2,
-	0x2e, 0x9c, 0xaf, 0xff, 0x2e, 0x11, 0x0d, 0xfd, 0xf9, 0xc4, 0xea, 0xa3, 0xb2, 0x23, 0xb5, 0x57,
-	0x8a, 0x89, 0x73, 0x0f, 0xd4, 0x6c, 0xfa, 0x05, 0xb9, 0x56, 0x42, 0x1e, 0x04, 0x3f, 0xf8, 0x5e,
-	0x60, 0x39, 0x79, 0xd6, 0x6e, 0x64, 0xa9, 0xd1, 0x4c, 0x80, 0x66, 0x58, 0x7c, 0x03, 0xbb, 0x82,
-	0x61, 0x29, 0xea, 0x14, 0xdf, 0x60, 0xde, 0x0b, 0x0d, 0x18, 0xb5, 0xc9, 0x0d, 0x51, 0x77, 0x4e,
-	0x81, 0x1d, 0xb1, 0x88, 0xf9, 0x36, 0x73, 0x8a, 0xa3, 0xd3, 0x5b, 0xdf, 0x69, 0xdf, 0x59, 0x31,
-	0x6f, 0x67, 0xa9, 0xf1, 0xd6, 0x42, 0xd2, 0xec, 0x7c, 0xc1, 0xe2, 0x38, 0xc5, 0xdd, 0xa5, 0x76,
-	0x33, 0x10, 0xd8, 0x82, 0xbb, 0xcb, 0xec, 0xfd, 0x80, 0x1d, 0xc5, 0xfb, 0x8c, 0xdb, 0xe3, 0xbc,
-	0x2a, 0x97, 0xdf, 0xaf, 0xe2, 0x85, 0x06, 0x8c, 0x7e, 0x4b, 0x7a, 0x76, 0x80, 0xdb, 0xdd, 0x0d,
-	0xfc, 0xbd, 0xc0, 0xe7, 0x51, 0xe0, 0x1d, 0x58, 0x9c, 0xf9, 0xf6, 0x29, 0x16, 0xee, 0x8e, 0x79,
-	0x2b, 0x4b, 0x8d, 0x85, 0x1c, 0x58, 0xe8, 0xa1, 0x0e, 0xb9, 0x15, 0xba, 0x21, 0x13, 0x2d, 0xee,
-	0x9b, 0xc8, 0x0a, 0x43, 0x16, 0xc9, 0x13, 0xce, 0x1c, 0x59, 0x18, 0x65, 0xa1, 0xdf, 0xc9, 0x52,
-	0xe3, 0x5c, 0x1e, 0x9c, 0xeb, 0xed, 0xff, 0xa1, 0x11, 0x0d, 0xf3, 0x24, 0xb6, 0xdf, 0x98, 0x59,
-	0x8e, 0x4c, 0x9a, 0x28, 0x66, 0xe5, 0x7d, 0x5f, 0xf5, 0x40, 0xcd, 0xae, 0x68, 0xe5, 0xea, 0xb4,
-	0x06, 0xad, 0x5c, 0x4f, 0xcd, 0xa6, 0x7b, 0xe4, 0xaa, 0xc3, 0xec, 0x60, 0x12, 0x46, 0x58, 0x39,
-	0xe5, 0xd4, 0x32, 0x75, 0xd7, 0xb2, 0xd4, 0x98, 0x77, 0xc2, 0x3c, 0x54, 0x0f, 0x52, 0xce, 0xd0,
-	0x5c, 0x10, 0xb9, 0x8c, 0x79, 0x88, 0xde, 0x27, 0x9b, 0xf5, 0x75, 0xc8, 0x9e, 0xb8, 0x95, 0xa5,
-	0x46, 0xdd, 0x05, 0x75, 0x40, 0xc8, 0xf1, 0x2c, 0x3d, 0x48, 0x42, 0xcf, 0xb5, 0x2d, 0x21, 0xbf,
-	0x5c, 0xc8, 0x6b, 0x2e, 0xa8, 0x03, 0x42, 0x1e, 0xd6, 0x7a, 0x1f, 0x29, 0xe4, 0x35, 0x17, 0xd4,
-	0x01, 0x1a, 0x92, 0x9d, 0x3c, 0xb1, 0x0b, 0xba, 0x93, 0xea, 0xa5, 0xef, 0x64, 0xa9, 0xf1, 0x5a,
-	0x2e, 0xbc, 0x96, 0x41, 0x4f, 0xc9, 0xdb, 0xe5, 0x1c, 0x2e, 0x9a, 0x54, 0x76, 0xd8, 0x77, 0xb3,
-	0xd4, 0xb8, 0x08, 0x1d, 0x2e, 0x42, 0xea, 0xff, 0xdd, 0x21, 0x1a, 0xde, 0x6a, 0x45, 0x8d, 0x67,
-	0xf2, 0x46, 0xb2, 0x1f, 0x24, 0x7e, 0xa5, 0xc3, 0x94, 0x71, 0xa8, 0x58, 0xa2, 0xc1, 0xb2, 0xd9,
-	0x3d, 0xe6, 0x38, 0x11, 0xbd, 0x4a, 0x56, 0x4a, 0x4d, 0x36, 0xd8, 0xba, 0x0f, 0xe6, 0x10, 0xfa,
-	0x11, 0x59, 0x57, 0x18, 0x16, 0x6f, 0x79, 0xb7, 0xd4, 0xcc, 0xab, 0x59, 0x6a, 0x54, 0x1d, 0x50,
-	0x35, 0x85, 0x10, 0x2f, 0xc3, 0xc0, 0x6c, 0xe6, 0x9e, 0xe4, 0x37, 0x49, 0x14, 0x56, 0x1c, 0x50,
-	0x35, 0xc5, 0x9d, 0x10, 0x01, 0x6c, 0x49, 0xf2, 0x78, 0xe1, 0x9d, 0x30, 0x07, 0xa1, 0x18, 0x8a,
-	0xab, 0x66, 0x24, 0xd7, 0x2a, 0xcf, 0x92, 0x26, 0xaf, 0x9a, 0x33, 0x0c, 0xf2, 0x91, 0x48, 0xa0,
-	0x53, 0x2e, 0xf1, 0xcb, 0x45, 0x93, 0x2c, 0xe3, 0x50, 0xb1, 0xc4, 0x79, 0xc3, 0x72, 0x7c, 0xc0,
-	0xfc, 0x11, 0x1f, 0x0f, 0x59, 0x74, 0x92, 0x5f, 0x20, 0xf1, 0xbc, 0xcd, 0x39, 0x61, 0x1e, 0x32,
-	0xd9, 0xf3, 0x97, 0x7a, 0xeb, 0xc5, 0x4b, 0xbd, 0xf5, 0xea, 0xa5, 0xde, 0xfe, 0x69, 0xaa, 0xb7,
-	0x7f, 0x9b, 0xea, 0xed, 0x67, 0x53, 0xbd, 0xfd, 0x7c, 0xaa, 0xb7, 0xff, 0x99, 0xea, 0xed, 0x7f,
-	0xa7, 0x7a, 0xeb, 0xd5, 0x54, 0x6f, 0xff, 0x7c, 0xa6, 0xb7, 0x9e, 0x9f, 0xe9, 0xad, 0x17, 0x67,
-	0x7a, 0xeb, 0xbb, 0xc1, 0xc8, 0xe5, 0xe3, 0xe4, 0x70, 0xd7, 0x0e, 0x26, 0x83, 0x51, 0x64, 0x1d,
-	0x59, 0xbe, 0x35, 0xf0, 0x82, 0x27, 0xee, 0xe0, 0xe4, 0xde, 0xa0, 0xe9, 0x6f, 0x83, 0xc3, 0x2e,
-	0xfe, 0x29, 0x70, 0xef, 0xff, 0x00, 0x00, 0x00, 0xff, 0xff, 0x64, 0x90, 0x88, 0xaf, 0x55, 0x10,
-	0x00, 0x00,
+	// 1392 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x58, 0xcf, 0x6f, 0xdc, 0xc4,
+	0x17, 0x5f, 0x67, 0xe3, 0x4d, 0x3a, 0xf9, 0xd5, 0x4e, 0xd2, 0x6f, 0xdd, 0x6f, 0xab, 0x75, 0x58,
+	0xa8, 0x28, 0x42, 0xca, 0xaa, 0x14, 0x09, 0x81, 0xa8, 0x84, 0x9c, 0x12, 0x51, 0x29, 0x15, 0xe5,
+	0x05, 0x04, 0x82, 0x93, 0x63, 0xbf, 0xec, 0x5a, 0xf1, 0xda, 0x1b, 0x7b, 0x1c, 0x9a, 0x13, 0xfc,
+	0x09, 0xdc, 0xb9, 0x23, 0xee, 0x5c, 0xe0, 0xcc, 0xa5, 0xc7, 0x1e, 0x7b, 0xb2, 0xe8, 0xe6, 0x82,
+	0x7c, 0xea, 0x91, 0x03, 0x07, 0x34, 0x3f, 0xd6, 0xbf, 0xd6, 0x9b, 0xe6, 0xb2, 0x9e, 0xf7, 0x79,
+	0xef, 0xf3, 0x66, 0xe6, 0xcd, 0xbc, 0xf7, 0x46, 0x4b, 0xb6, 0xc7, 0xc7, 0x83, 0xbe, 0x1f, 0x0e,
+	0x4e, 0xfc, 0x51, 0xe8, 0xa2, 0xdf, 0x8f, 0x99, 0xcd, 0x62, 0xf9, 0xbb, 0x33, 0x8e, 0x42, 0x16,
+	0x52, 0x5d, 0x08, 0xff, 0xdf, 0x1a, 0x84, 0x83, 0x50, 0x20, 0x7d, 0x3e, 0x92, 0xca, 0xde, 0x2f,
+	0x0b, 0xa4, 0x03, 0x18, 0x27, 0x3e, 0xa3, 0x1f, 0x92, 0xa5, 0x38, 0x19, 0x8d, 0xec, 0xe8, 0xcc,
+	0xd0, 0xb6, 0xb5, 0xbb, 0x2b, 0xef, 0xad, 0xef, 0x48, 0x37, 0x07, 0x12, 0xb5, 0x36, 0x9e, 0xa5,
+	0x66, 0x2b, 0x4b, 0xcd, 0xa9, 0x19, 0x4c, 0x07, 0x9c, 0x7a, 0x92, 0x60, 0xe4, 0x61, 0x64, 0x2c,
+	0x54, 0xa8, 0x5f, 0x48, 0xb4, 0xa0, 0x2a, 0x33, 0x98, 0x0e, 0xe8, 0x03, 0xb2, 0xec, 0x05, 0x03,
+	0x8c, 0x19, 0x46, 0x46, 0x5b, 0x70, 0x37, 0x14, 0xf7, 0x91, 0x82, 0xad, 0xab, 0x8a, 0x9c, 0x1b,
+	0x42, 0x3e, 0xa2, 0xef, 0x93, 0x8e, 0x63, 0x3b, 0x43, 0x8c, 0x8d, 0x45, 0x41, 0x5e, 0x53, 0xe4,
+	0x5d, 0x01, 0x5a, 0x6b, 0x8a, 0xaa, 0x0b, 0x23, 0x50, 0xb6, 0xf4, 0x1e, 0xd1, 0xbd, 0xc0, 0xc5,
+	0xa7, 0x86, 0x2e, 0x48, 0xab, 0xf9, 0x8c, 0x2e, 0x3e, 0x2d, 0x38, 0xc2, 0x04, 0xe4, 0xa7, 0xf7,
+	0xf3, 0x22, 0xe9, 0xec, 0xe6, 0x6c, 0x67, 0x98, 0x04, 0xc7, 0x86, 0x56, 0x61, 0x0b, 0x6d, 0x69,
+	0x46, 0x6e, 0x02, 0xf2, 0x53, 0x4c, 0xb8, 0x70, 0x11, 0xa5, 0x3c, 0x21, 0xdf, 0x59, 0x24, 0x0e,
+	0xc6, 0x68, 0x37, 0x70, 0xd6, 0x15, 0x47, 0xd9, 0x80, 0xfa, 0xd2, 0x5d, 0xb2, 0x22, 0xcc, 0xe4,
+	0x99, 0x1a, 0x8b, 0x0d, 0xd4, 0x4d, 0x45, 0x2d, 0x1b, 0x42, 0x59, 0xa0, 0x7b, 0x64, 0xf5, 0x34,
+	0xf4, 0x93, 0x11, 0x2a, 0x2f, 0x7a, 0x83, 0x97, 0x2d, 0xe5, 0xa5, 0x62, 0x09, 0x15, 0x89, 0xfb,
+	0x89, 0xf9, 0x29, 0x4f, 0x57, 0xd3, 0xb9, 0xc8, 0x4f, 0xd9, 0x12, 0x2a, 0x12, 0xdf, 0x94, 0x6f,
+	0x1f, 0xa2, 0xaf, 0xdc, 0x2c, 0x5d, 0xb4, 0xa9, 0x92, 0x21, 0x94, 0x05, 0xfa, 0x1d, 0xd9, 0xf4,
+	0x82, 0x98, 0xd9, 0x01, 0x7b, 0x8c, 0x2c, 0xf2, 0x1c, 0xe5, 0x6c, 0xb9, 0xc1, 0xd9, 0x2d, 0xe5,
+	0xac, 0x89, 0x00, 0x4d, 0x60, 0xef, 0x8f, 0x0e, 0x59, 0x52, 0x69, 0x42, 0xbf, 0x22, 0x37, 0x0e,
+	0xcf, 0x18, 0xc6, 0x4f, 0xa2, 0xd0, 0xc1, 0x38, 0x46, 0xf7, 0x09, 0x46, 0x07, 0xe8, 0x84, 0x81,
+	0x2b, 0x2e, 0x4c, 0xdb, 0xba, 0x95, 0xa5, 0xe6, 0x3c, 0x13, 0x98, 0xa7, 0xe0, 0x6e, 0x7d, 0x2f,
+	0x68, 0x74, 0xbb, 0x50, 0xb8, 0x9d, 0x63, 0x02, 0xf3, 0x14, 0xf4, 0x11, 0xd9, 0x64, 0x21, 0xb3,
+	0x7d, 0xab, 0x32, 0xad, 0xb8, 0x73, 0x6d, 0xeb, 0x06, 0x0f, 0x42, 0x83, 0x1a, 0x9a, 0xc0, 0xdc,
+	0xd5, 0x7e, 0x65, 0x2a, 0x63, 0xb1, 0xe6, 0xaa, 0xaa, 0x86, 0x26, 0x90, 0xde, 0x25, 0xcb, 0xf8,
+	0x14, 0x9d, 0x2f, 0xbd, 0x11, 0x8a, 0xdb, 0xa7, 0x59, 0xab, 0xbc, 0x00, 0x4c, 0x31, 0xc8, 0x47,
+	0xf4, 0x5d, 0x72, 0xe5, 0x24, 0xc1, 0x04, 0x85, 0x69, 0x47, 0x98, 0xae, 0x65, 0xa9, 0x59, 0x80,
+	0x50, 0x0c, 0xe9, 0x0e, 0x21, 0x71, 0x72, 0x28, 0x4b, 0x4f, 0x2c, 0xee, 0x51, 0xdb, 0x5a, 0xcf,
+	0x52, 0xb3, 0x84, 0x42, 0x69, 0x4c, 0xf7, 0xc9, 0x96, 0x58, 0xdd, 0xa7, 0x01, 0x13, 0x3a, 0x64,
+	0x49, 0x14, 0xa0, 0x2b, 0x2e, 0x4d, 0xdb, 0x32, 0xb2, 0xd4, 0x6c, 0xd4, 0x43, 0x23, 0x4a, 0x7b,
+	0xa4, 0x13, 0x8f, 0x7d, 0x8f, 0xc5, 0xc6, 0x15, 0xc1, 0x27, 0x3c, 0x7f, 0x25, 0x02, 0xea, 0x2b,
+	0x6c, 0x86, 0x76, 0xe4, 0xc6, 0x06, 0x29, 0xd9, 0x08, 0x04, 0xd4, 0x37, 0x5f, 0xd5, 0x93, 0x30,
+	0x66, 0x7b, 0x9e, 0xcf, 0x30, 0x12, 0xd1, 0x33, 0x56, 0x6a, 0xab, 0xaa, 0xe9, 0xa1, 0x11, 0xa5,
+	0x3f, 0x90, 0x3b, 0x02, 0x3f, 0x60, 0x51, 0xe2, 0xb0, 0x24, 0x42, 0xf7, 0x31, 0x32, 0xdb, 0xb5,
+	0x99, 0x5d, 0xbb, 0x12, 0xab, 0xc2, 0xfd, 0x3b, 0x59, 0x6a, 0x5e, 0x8e, 0x00, 0x97, 0x33, 0xeb,
+	0xfd, 0xae, 0x11, 0x5d, 0x54, 0x5e, 0x7a, 0x8f, 0xac, 0x08, 0xca, 0x2e, 0xaf, 0x99, 0xb1, 0xca,
+	0x96, 0x0d, 0x9e, 0xd5, 0x25, 0x18, 0xca, 0x02, 0xfd, 0x84, 0x5c, 0x1d, 0xe7, 0x1b, 0x52, 0x3c,
+	0x99, 0x0e, 0x5b, 0x59, 0x6a, 0xce, 0xe8, 0x60, 0x06, 0xa1, 0x1f, 0x91, 0x75, 0x19, 0xd7, 0x87,
+	0x49, 0x64, 0x33, 0x2f, 0x0c, 0xd4, 0xdd, 0xa7, 0x59, 0x6a, 0xd6, 0x34, 0x50, 0x93, 0x7b, 0x1f,
+	0x93, 0x25, 0xd5, 0xe1, 0x78, 0x85, 0x8f, 0x59, 0x18, 0x61, 0xad, 0x29, 0x1c, 0x70, 0xac, 0xa8,
+	0xf0, 0xc2, 0x04, 0xe4, 0xa7, 0xf7, 0xcf, 0x02, 0x59, 0x7e, 0x54, 0x34, 0xb2, 0x55, 0xb1, 0x2f,
+	0x40, 0x5e, 0x82, 0x64, 0xa9, 0xd0, 0xad, 0xab, 0xbc, 0x32, 0x96, 0x71, 0xa8, 0x48, 0x74, 0x8f,
+	0xd0, 0x52, 0x34, 0x1e, 0xdb, 0x4c, 0x70, 0x65, 0x00, 0xfe, 0x97, 0xa5, 0x66, 0x83, 0x16, 0x1a,
+	0xb0, 0x7c, 0x76, 0x4b, 0xc8, 0xb1, 0x0a, 0x41, 0x31, 0xbb, 0xc2, 0xa1, 0x22, 0xf1, 0xd0, 0x15,
+	0xc9, 0x7b, 0x80, 0x01, 0x33, 0x16, 0x8b, 0xd0, 0x55, 0x35, 0x50, 0x93, 0x8b, 0x78, 0xe9, 0x97,
+	0x8d, 0x57, 0x51, 0xaa, 0xfc, 0xd0, 0x39, 0x16, 0x95, 0xfa, 0x33, 0x9e, 0x4c, 0x9d, 0x7a, 0xa9,
+	0xaa, 0xa8, 0xa1, 0x09, 0xec, 0xfd, 0xbb, 0x48, 0x74, 0x31, 0x55, 0xbe, 0x07, 0x75, 0x3f, 0xf0,
+	0xc8, 0xd0, 0x6a, 0x7b, 0xc8, 0x35, 0x50, 0x93, 0xe9, 0xe7, 0xe4, 0x7a, 0x09, 0x79, 0x18, 0x7e,
+	0x1f, 0xf8, 0xa1, 0xed, 0xe6, 0x07, 0x70, 0x33, 0x4b, 0xcd, 0x66, 0x03, 0x68, 0x86, 0xf9, 0x71,
+	0x3a, 0x15, 0x4c, 0x54, 0xb5, 0x76, 0x71, 0x9c, 0xb3, 0x5a, 0x68, 0xc0, 0xa8, 0x43, 0x6e, 0xf2,
+	0x12, 0x76, 0x06, 0x78, 0x84, 0x11, 0x06, 0x0e, 0xba, 0x45, 0x16, 0x1a, 0x6b, 0xdb, 0xda, 0xdd,
+	0x65, 0xeb, 0x4e, 0x96, 0x9a, 0x6f, 0xcc, 0x35, 0x9a, 0xa6, 0x2a, 0xcc, 0xf7, 0x53, 0x3c, 0x83,
+	0x6a, 0x8f, 0x0c, 0x8e, 0xcd, 0x79, 0x06, 0x4d, 0xf7, 0x07, 0x78, 0x14, 0xef, 0x21, 0x73, 0x86,
+	0x79, 0x81, 0x2f, 0xef, 0xaf, 0xa2, 0x85, 0x06, 0x8c, 0x7e, 0x43, 0x0c, 0x27, 0x14, 0x99, 0xe3,
+	0x85, 0xc1, 0x6e, 0x18, 0xb0, 0x28, 0xf4, 0xf7, 0x6d, 0x86, 0x81, 0x73, 0xa6, 0xae, 0xc3, 0xed,
+	0x2c, 0x35, 0xe7, 0xda, 0xc0, 0x5c, 0x0d, 0x75, 0xc9, 0xed, 0xb1, 0x37, 0x46, 0xde, 0x2d, 0xbf,
+	0x8e, 0xec, 0xf1, 0x18, 0x23, 0x59, 0x2c, 0xd0, 0x95, 0x35, 0x56, 0xf6, 0x8c, 0xed, 0x2c, 0x35,
+	0x2f, 0xb4, 0x83, 0x0b, 0xb5, 0xbd, 0xdf, 0x74, 0xa2, 0x8b, 0x38, 0xf1, 0xeb, 0x37, 0x44, 0xdb,
+	0x95, 0x41, 0xe3, 0x75, 0xb1, 0x9c, 0x42, 0x55, 0x0d, 0xd4, 0xe4, 0x0a, 0x57, 0xae, 0x4e, 0x6f,
+	0xe0, 0xca, 0xf5, 0xd4, 0x64, 0xba, 0x4b, 0xae, 0xb9, 0xe8, 0x84, 0xa3, 0x71, 0x24, 0x8a, 0xb0,
+	0x9c, 0x5a, 0x86, 0xee, 0x7a, 0x96, 0x9a, 0xb3, 0x4a, 0x98, 0x85, 0xea, 0x4e, 0xca, 0x11, 0x9a,
+	0x71, 0x22, 0x97, 0x31, 0x0b, 0xd1, 0x07, 0x64, 0xa3, 0xbe, 0x0e, 0xd9, 0x5e, 0x37, 0xb3, 0xd4,
+	0xac, 0xab, 0xa0, 0x0e, 0x70, 0xba, 0xc8, 0xa5, 0x87, 0xc9, 0xd8, 0xf7, 0x1c, 0x9b, 0xe1, 0xb4,
+	0xbb, 0x0a, 0x7a, 0x4d, 0x05, 0x75, 0x80, 0xd3, 0xc7, 0xb5, 0x36, 0x4a, 0x0a, 0x7a, 0x4d, 0x05,
+	0x75, 0x80, 0x8e, 0xc9, 0x76, 0x1e, 0xd8, 0x39, 0x8d, 0x4e, 0xb5, 0xe5, 0xb7, 0xb2, 0xd4, 0x7c,
+	0xad, 0x2d, 0xbc, 0xd6, 0x82, 0x9e, 0x91, 0x37, 0xcb, 0x31, 0x9c, 0x37, 0xa9, 0x6c, 0xd6, 0x6f,
+	0x67, 0xa9, 0x79, 0x19, 0x73, 0xb8, 0x8c, 0x51, 0xef, 0xcf, 0x36, 0xd1, 0x45, 0x09, 0xe5, 0xed,
+	0x02, 0xe5, 0xe3, 0x66, 0x2f, 0x4c, 0x82, 0x4a, 0xb3, 0x2a, 0xe3, 0x50, 0x91, 0x78, 0xaf, 0xc6,
+	0xe9, 0x93, 0xe8, 0x24, 0xc1, 0x98, 0xa9, 0x4a, 0xa9, 0xcb, 0x5e, 0x5d, 0xd7, 0xc1, 0x0c, 0x42,
+	0x3f, 0x20, 0x6b, 0x0a, 0x13, 0xc5, 0x5b, 0x3e, 0x53, 0x75, 0xeb, 0x5a, 0x96, 0x9a, 0x55, 0x05,
+	0x54, 0x45, 0x4e, 0x14, 0xef, 0x6a, 0x40, 0x07, 0xbd, 0xd3, 0xfc, 0x51, 0x2a, 0x88, 0x15, 0x05,
+	0x54, 0x45, 0xfe, 0xbc, 0x14, 0x80, 0xe8, 0x6e, 0x32, 0xbd, 0xc4, 0xf3, 0x32, 0x07, 0xa1, 0x18,
+	0xf2, 0x57, 0x6b, 0x24, 0xd7, 0x2a, 0x73, 0x49, 0x97, 0xaf, 0xd6, 0x29, 0x06, 0xf9, 0x88, 0x07,
+	0xd0, 0x2d, 0x97, 0xf8, 0xa5, 0xa2, 0xdf, 0x96, 0x71, 0xa8, 0x48, 0x3c, 0xdf, 0x44, 0x39, 0xde,
+	0xc7, 0x60, 0xc0, 0x86, 0x07, 0x18, 0x9d, 0xe6, 0x6f, 0x51, 0x91, 0x6f, 0x33, 0x4a, 0x98, 0x85,
+	0x2c, 0x7c, 0xfe, 0xb2, 0xdb, 0x7a, 0xf1, 0xb2, 0xdb, 0x7a, 0xf5, 0xb2, 0xab, 0xfd, 0x38, 0xe9,
+	0x6a, 0xbf, 0x4e, 0xba, 0xda, 0xb3, 0x49, 0x57, 0x7b, 0x3e, 0xe9, 0x6a, 0x7f, 0x4d, 0xba, 0xda,
+	0xdf, 0x93, 0x6e, 0xeb, 0xd5, 0xa4, 0xab, 0xfd, 0x74, 0xde, 0x6d, 0x3d, 0x3f, 0xef, 0xb6, 0x5e,
+	0x9c, 0x77, 0x5b, 0xdf, 0xf6, 0x07, 0x1e, 0x1b, 0x26, 0x87, 0x3b, 0x4e, 0x38, 0xea, 0x0f, 0x22,
+	0xfb, 0xc8, 0x0e, 0xec, 0xbe, 0x1f, 0x1e, 0x7b, 0xfd, 0xd3, 0xfb, 0xfd, 0xa6, 0x7f, 0x20, 0x0e,
+	0x3b, 0xe2, 0xff, 0x85, 0xfb, 0xff, 0x0d, 0x00, 0x27, 0xb7, 0x29, 0x8a, 0xa0, 0x10, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -1189,6 +1198,9 @@ func (this *Ingester) Equal(that interface{}) bool {
 	if !this.Store.Equal(&that1.Store) {
 		return false
 	}
+	if this.TotalBlockCacheHits != that1.TotalBlockCacheHits {
+		return false
+	}
 	return true
 }
 func (this *Store) Equal(that interface{}) bool {
@@ -1407,13 +1419,14 @@ func (this *Ingester) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&stats.Ingester{")
 	s = append(s, "TotalReached: "+fmt.Sprintf("%#v", this.TotalReached)+",\n")
 	s = append(s, "TotalChunksMatched: "+fmt.Sprintf("%#v", this.TotalChunksMatched)+",\n")
 	s = append(s, "TotalBatches: "+fmt.Sprintf("%#v", this.TotalBatches)+",\n")
 	s = append(s, "TotalLinesSent: "+fmt.Sprintf("%#v", this.TotalLinesSent)+",\n")
 	s = append(s, "Store: "+strings.Replace(this.Store.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "TotalBlockCacheHits: "+fmt.Sprintf("%#v", this.TotalBlockCacheHits)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1829,6 +1842,11 @@ func (m *Ingester) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.TotalBlockCacheHits != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.TotalBlockCacheHits))
+		i--
+		dAtA[i] = 0x30
+	}
 	{
 		size, err := m.Store.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -2215,6 +2233,9 @@ func (m *Ingester) Size() (n int) {
 	}
 	l = m.Store.Size()
 	n += 1 + l + sovStats(uint64(l))
+	if m.TotalBlockCacheHits != 0 {
+		n += 1 + sovStats(uint64(m.TotalBlockCacheHits))
+	}
 	return n
 }
 
@@ -2409,6 +2430,7 @@ func (this *Ingester) String() string {
 		`TotalBatches:` + fmt.Sprintf("%v", this.TotalBatches) + `,`,
 		`TotalLinesSent:` + fmt.Sprintf("%v", this.TotalLinesSent) + `,`,
 		`Store:` + strings.Replace(strings.Replace(this.Store.String(), "Store", "Store", 1), `&`, ``, 1) + `,`,
+		`TotalBlockCacheHits:` + fmt.Sprintf("%v", this.TotalBlockCacheHits) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3607,6 +3629,25 @@ func (m *Ingester) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalBlockCacheHits", wireType)
+			}
+			m.TotalBlockCacheHits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalBlockCacheHits |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
@@ -39,6 +39,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/analytics"
 	"github.com/grafana/loki/v3/pkg/bloomgateway"
+	"github.com/grafana/loki/v3/pkg/chunkenc"
 	"github.com/grafana/loki/v3/pkg/compactor"
 	compactorclient "github.com/grafana/loki/v3/pkg/compactor/client"
 	"github.com/grafana/loki/v3/pkg/compactor/client/grpc"
@@ -66,6 +67,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/zstddict"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/bloomshipper"
@@ -128,6 +130,7 @@ const (
 	Write                    string = "write"
 	Backend                  string = "backend"
 	Analytics                string = "analytics"
+	ZstdDictionaries         string = "zstd-dictionaries"
 	InitCodec                string = "init-codec"
 )
 
@@ -315,6 +318,7 @@ func (t *Loki) initTenantConfigs() (_ services.Service, err error) {
 }
 
 func (t *Loki) initDistributor() (services.Service, error) {
+	t.Cfg.Distributor.KafkaConfig = t.Cfg.KafkaConfig
 	if t.Cfg.Pattern.Enabled {
 		patternTee, err := pattern.NewTee(t.Cfg.Pattern, t.PatternRingClient, t.Cfg.MetricsNamespace, prometheus.DefaultRegisterer, util_log.Logger)
 		if err != nil {
@@ -575,6 +579,7 @@ func (t *Loki) initQuerier() (services.Service, error) {
 func (t *Loki) initIngester() (_ services.Service, err error) {
 	logger := log.With(util_log.Logger, "component", "ingester")
 	t.Cfg.Ingester.LifecyclerConfig.ListenPort = t.Cfg.Server.GRPCListenPort
+	t.Cfg.Ingester.KafkaConfig = t.Cfg.KafkaConfig
 
 	if t.Cfg.Ingester.ShutdownMarkerPath == "" && t.Cfg.Common.PathPrefix != "" {
 		t.Cfg.Ingester.ShutdownMarkerPath = t.Cfg.Common.PathPrefix
@@ -595,6 +600,7 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	logproto.RegisterPusherServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterQuerierServer(t.Server.GRPC, t.Ingester)
 	logproto.RegisterStreamDataServer(t.Server.GRPC, t.Ingester)
+	logproto.RegisterIngesterServer(t.Server.GRPC, t.Ingester)
 
 	httpMiddleware := middleware.Merge(
 		serverutil.RecoveryHTTPMiddleware,
@@ -608,6 +614,9 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	t.Server.HTTP.Methods("POST", "GET").Path("/ingester/shutdown").Handler(
 		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.ShutdownHandler)),
 	)
+	t.Server.HTTP.Methods("GET").Path("/ingester/wal/status").Handler(
+		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.WALStatusHandler)),
+	)
 	return t.Ingester, nil
 }
 
@@ -707,6 +716,21 @@ func (t *Loki) initStore() (services.Service, error) {
 	}), nil
 }
 
+func (t *Loki) initZstdDictionaries() (services.Service, error) {
+	cfg := t.Cfg.StorageConfig.ZstdDictionaries
+	if cfg.Store == "" {
+		return nil, nil
+	}
+
+	objectClient, err := storage.NewObjectClient(cfg.Store, t.Cfg.StorageConfig, t.ClientMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd dictionaries store object client: %w", err)
+	}
+
+	t.zstdDictionaryStore = zstddict.NewStore(objectClient, cfg.StoreKeyPrefix)
+	return zstddict.NewClient(cfg, t.zstdDictionaryStore, &chunkenc.ZstdDict, prometheus.DefaultRegisterer, util_log.Logger), nil
+}
+
 func (t *Loki) initBloomStore() (services.Service, error) {
 	if !config.UsingObjectStorageIndex(t.Cfg.SchemaConfig.Configs) {
 		return nil, errors.New("not using shipper index type")
@@ -789,8 +813,9 @@ func (t *Loki) updateConfigForShipperStore() {
 		t.Cfg.StorageConfig.TSDBShipperConfig.Mode = indexshipper.ModeWriteOnly
 		t.Cfg.StorageConfig.TSDBShipperConfig.IngesterDBRetainPeriod = shipperQuerierIndexUpdateDelay(t.Cfg.StorageConfig.IndexCacheValidity, t.Cfg.StorageConfig.TSDBShipperConfig.ResyncInterval)
 
-	case t.Cfg.isModuleEnabled(Querier), t.Cfg.isModuleEnabled(Ruler), t.Cfg.isModuleEnabled(Read), t.Cfg.isModuleEnabled(Backend), t.isModuleActive(IndexGateway), t.Cfg.isModuleEnabled(BloomCompactor):
-		// We do not want query to do any updates to index
+	case t.Cfg.isModuleEnabled(Querier), t.Cfg.isModuleEnabled(Ruler), t.Cfg.isModuleEnabled(Read), t.Cfg.isModuleEnabled(Backend), t.isModuleActive(IndexGateway), t.Cfg.isModuleEnabled(BloomCompactor), t.Cfg.isModuleEnabled(Compactor) && t.Cfg.CompactorConfig.DeleteRequestDryRun.Enabled:
+		// We do not want query to do any updates to index.
+		// The compactor only reads the store to preview delete requests.
 		t.Cfg.StorageConfig.BoltDBShipperConfig.Mode = indexshipper.ModeReadOnly
 		t.Cfg.StorageConfig.TSDBShipperConfig.Mode = indexshipper.ModeReadOnly
 
@@ -1278,6 +1303,7 @@ func (t *Loki) initMemberlistKV() (services.Service, error) {
 	t.Cfg.MemberlistKV.Codecs = []codec.Codec{
 		ring.GetCodec(),
 		analytics.JSONCodec,
+		distributor.GetReplicaDescCodec(),
 	}
 
 	dnsProviderReg := prometheus.WrapRegistererWithPrefix(
@@ -1293,6 +1319,7 @@ func (t *Loki) initMemberlistKV() (services.Service, error) {
 
 	t.Cfg.CompactorConfig.CompactorRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Distributor.DistributorRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
+	t.Cfg.Distributor.HATrackerConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.IndexGateway.Ring.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
 	t.Cfg.QueryScheduler.SchedulerRing.KVStore.MemberlistKV = t.MemberlistKV.GetMemberlistKV
@@ -1347,7 +1374,15 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		}
 	}
 
-	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, objectClients, deleteRequestStoreClient, t.Cfg.SchemaConfig, t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
+	var zstdDictionaryStore compactor.ZstdDictionaryStore
+	if t.Cfg.CompactorConfig.ZstdDictionaries.TrainingEnabled {
+		if t.zstdDictionaryStore == nil {
+			return nil, fmt.Errorf("store.zstd-dictionaries.store should be configured when zstd dictionaries training is enabled")
+		}
+		zstdDictionaryStore = t.zstdDictionaryStore
+	}
+
+	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, objectClients, deleteRequestStoreClient, zstdDictionaryStore, t.Cfg.SchemaConfig, t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
 	if err != nil {
 		return nil, err
 	}
@@ -1361,6 +1396,9 @@ func (t *Loki) initCompactor() (services.Service, error) {
 	}
 
 	if t.Cfg.CompactorConfig.RetentionEnabled {
+		if t.Cfg.CompactorConfig.DeleteRequestDryRun.Enabled {
+			t.compactor.DeleteRequestsHandler.SetPreviewer(deletion.NewDeletePreviewer(t.Store, t.Cfg.CompactorConfig.DeleteRequestDryRun.MaxChunks))
+		}
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("PUT", "POST").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.AddDeleteRequestHandler))
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.GetAllDeleteRequestsHandler))
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("DELETE").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.CancelDeleteRequestHandler))
@@ -1368,6 +1406,14 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		grpc.RegisterCompactorServer(t.Server.GRPC, t.compactor.DeleteRequestsGRPCHandler)
 	}
 
+	if t.Cfg.CompactorConfig.SchemaMigration.Enabled {
+		t.Server.HTTP.Path("/compactor/schema_migration").Methods("GET").Handler(http.HandlerFunc(t.compactor.SchemaMigrationHandler))
+	}
+
+	if t.compactor.JobQueue != nil {
+		grpc.RegisterJobQueueServer(t.Server.GRPC, t.compactor.JobQueue)
+	}
+
 	return t.compactor, nil
 }
 
@@ -70,6 +70,9 @@ func (m *PushRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_PushRequest proto.InternalMessageInfo
 
 type PushResponse struct {
+	// partial_success is set when some of the entries of the request were
+	// rejected while others were accepted.
+	PartialSuccess *PushPartialSuccess `protobuf:"bytes,1,opt,name=partial_success,json=partialSuccess,proto3" json:"partial_success,omitempty"`
 }
 
 func (m *PushResponse) Reset()      { *m = PushResponse{} }
@@ -104,6 +107,173 @@ func (m *PushResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PushResponse proto.InternalMessageInfo
 
+func (m *PushResponse) GetPartialSuccess() *PushPartialSuccess {
+	if m != nil {
+		return m.PartialSuccess
+	}
+	return nil
+}
+
+type PushPartialSuccess struct {
+	// rejected_entries is the total number of entries rejected.
+	RejectedEntries int64 `protobuf:"varint,1,opt,name=rejected_entries,json=rejectedEntries,proto3" json:"rejected_entries"`
+	// streams holds the results of the streams with rejected entries. Streams
+	// whose entries were all accepted are not listed.
+	Streams []StreamPushResult `protobuf:"bytes,2,rep,name=streams,proto3" json:"streams"`
+}
+
+func (m *PushPartialSuccess) Reset()      { *m = PushPartialSuccess{} }
+func (*PushPartialSuccess) ProtoMessage() {}
+func (*PushPartialSuccess) Descriptor() ([]byte, []int) {
+	return fileDescriptor_35ec442956852c9e, []int{2}
+}
+func (m *PushPartialSuccess) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PushPartialSuccess) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PushPartialSuccess.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PushPartialSuccess) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PushPartialSuccess.Merge(m, src)
+}
+func (m *PushPartialSuccess) XXX_Size() int {
+	return m.Size()
+}
+func (m *PushPartialSuccess) XXX_DiscardUnknown() {
+	xxx_messageInfo_PushPartialSuccess.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PushPartialSuccess proto.InternalMessageInfo
+
+func (m *PushPartialSuccess) GetRejectedEntries() int64 {
+	if m != nil {
+		return m.RejectedEntries
+	}
+	return 0
+}
+
+func (m *PushPartialSuccess) GetStreams() []StreamPushResult {
+	if m != nil {
+		return m.Streams
+	}
+	return nil
+}
+
+type StreamPushResult struct {
+	// index is the position of the stream in the push request.
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index"`
+	// labels are the labels of the stream as sent in the push request.
+	Labels          string `protobuf:"bytes,2,opt,name=labels,proto3" json:"labels"`
+	AcceptedEntries int64  `protobuf:"varint,3,opt,name=accepted_entries,json=acceptedEntries,proto3" json:"accepted_entries"`
+	RejectedEntries int64  `protobuf:"varint,4,opt,name=rejected_entries,json=rejectedEntries,proto3" json:"rejected_entries"`
+	// reason is the discard reason of the rejected entries, e.g. per_stream_rate_limit.
+	Reason string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error  string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	// retryable is true when pushing the stream again later may succeed.
+	Retryable bool `protobuf:"varint,7,opt,name=retryable,proto3" json:"retryable"`
+	// rejected_offsets are the offsets of the rejected entries in the stream of
+	// the push request, in increasing order. Empty when all the entries were
+	// rejected, or when the rejected entries are not known.
+	RejectedOffsets []int32 `protobuf:"varint,8,rep,packed,name=rejected_offsets,json=rejectedOffsets,proto3" json:"rejected_offsets,omitempty"`
+}
+
+func (m *StreamPushResult) Reset()      { *m = StreamPushResult{} }
+func (*StreamPushResult) ProtoMessage() {}
+func (*StreamPushResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_35ec442956852c9e, []int{3}
+}
+func (m *StreamPushResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StreamPushResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StreamPushResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StreamPushResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPushResult.Merge(m, src)
+}
+func (m *StreamPushResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *StreamPushResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPushResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPushResult proto.InternalMessageInfo
+
+func (m *StreamPushResult) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *StreamPushResult) GetLabels() string {
+	if m != nil {
+		return m.Labels
+	}
+	return ""
+}
+
+func (m *StreamPushResult) GetAcceptedEntries() int64 {
+	if m != nil {
+		return m.AcceptedEntries
+	}
+	return 0
+}
+
+func (m *StreamPushResult) GetRejectedEntries() int64 {
+	if m != nil {
+		return m.RejectedEntries
+	}
+	return 0
+}
+
+func (m *StreamPushResult) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *StreamPushResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *StreamPushResult) GetRetryable() bool {
+	if m != nil {
+		return m.Retryable
+	}
+	return false
+}
+
+func (m *StreamPushResult) GetRejectedOffsets() []int32 {
+	if m != nil {
+		return m.RejectedOffsets
+	}
+	return nil
+}
+
 type StreamAdapter struct {
 	Labels  string         `protobuf:"bytes,1,opt,name=labels,proto3" json:"labels"`
 	Entries []EntryAdapter `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries"`
@@ -114,7 +284,7 @@ type StreamAdapter struct {
 func (m *StreamAdapter) Reset()      { *m = StreamAdapter{} }
 func (*StreamAdapter) ProtoMessage() {}
 func (*StreamAdapter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_35ec442956852c9e, []int{2}
+	return fileDescriptor_35ec442956852c9e, []int{4}
 }
 func (m *StreamAdapter) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -172,7 +342,7 @@ type LabelPairAdapter struct {
 func (m *LabelPairAdapter) Reset()      { *m = LabelPairAdapter{} }
 func (*LabelPairAdapter) ProtoMessage() {}
 func (*LabelPairAdapter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_35ec442956852c9e, []int{3}
+	return fileDescriptor_35ec442956852c9e, []int{5}
 }
 func (m *LabelPairAdapter) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -228,7 +398,7 @@ type EntryAdapter struct {
 func (m *EntryAdapter) Reset()      { *m = EntryAdapter{} }
 func (*EntryAdapter) ProtoMessage() {}
 func (*EntryAdapter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_35ec442956852c9e, []int{4}
+	return fileDescriptor_35ec442956852c9e, []int{6}
 }
 func (m *EntryAdapter) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -288,6 +458,8 @@ func (m *EntryAdapter) GetParsed() []LabelPairAdapter {
 func init() {
 	proto.RegisterType((*PushRequest)(nil), "logproto.PushRequest")
 	proto.RegisterType((*PushResponse)(nil), "logproto.PushResponse")
+	proto.RegisterType((*PushPartialSuccess)(nil), "logproto.PushPartialSuccess")
+	proto.RegisterType((*StreamPushResult)(nil), "logproto.StreamPushResult")
 	proto.RegisterType((*StreamAdapter)(nil), "logproto.StreamAdapter")
 	proto.RegisterType((*LabelPairAdapter)(nil), "logproto.LabelPairAdapter")
 	proto.RegisterType((*EntryAdapter)(nil), "logproto.EntryAdapter")
@@ -296,40 +468,55 @@ func init() {
 func init() { proto.RegisterFile("pkg/push/push.proto", fileDescriptor_35ec442956852c9e) }
 
 var fileDescriptor_35ec442956852c9e = []byte{
-	// 527 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0xf5, 0x26, 0x6e, 0xda, 0x6e, 0x4a, 0xa9, 0x96, 0xb6, 0x18, 0xab, 0x5a, 0x47, 0x16, 0x87,
-	0x1c, 0xc0, 0x96, 0xc2, 0x81, 0x0b, 0x97, 0x58, 0x42, 0xea, 0xa1, 0x48, 0x95, 0x41, 0x20, 0x71,
-	0xdb, 0x34, 0x5b, 0xdb, 0xaa, 0xed, 0x35, 0xbb, 0x6b, 0xa4, 0xde, 0xf8, 0x84, 0xf2, 0x17, 0x7c,
-	0x01, 0xdf, 0xd0, 0x63, 0x8e, 0x15, 0x07, 0x43, 0x9c, 0x0b, 0xca, 0xa9, 0x9f, 0x80, 0xbc, 0xb6,
-	0x49, 0x28, 0x48, 0x5c, 0x36, 0x6f, 0x66, 0x67, 0xde, 0x7b, 0x99, 0x1d, 0xc3, 0x07, 0xd9, 0x45,
-	0xe0, 0x66, 0xb9, 0x08, 0xd5, 0xe1, 0x64, 0x9c, 0x49, 0x86, 0xb6, 0x62, 0x16, 0x28, 0x64, 0xee,
-	0x07, 0x2c, 0x60, 0x0a, 0xba, 0x15, 0xaa, 0xef, 0x4d, 0x2b, 0x60, 0x2c, 0x88, 0xa9, 0xab, 0xa2,
-	0x49, 0x7e, 0xee, 0xca, 0x28, 0xa1, 0x42, 0x92, 0x24, 0xab, 0x0b, 0xec, 0x77, 0xb0, 0x7f, 0x9a,
-	0x8b, 0xd0, 0xa7, 0x1f, 0x72, 0x2a, 0x24, 0x3a, 0x86, 0x9b, 0x42, 0x72, 0x4a, 0x12, 0x61, 0x80,
-	0x41, 0x77, 0xd8, 0x1f, 0x3d, 0x74, 0x5a, 0x05, 0xe7, 0xb5, 0xba, 0x18, 0x4f, 0x49, 0x26, 0x29,
-	0xf7, 0x0e, 0xbe, 0x15, 0x56, 0xaf, 0x4e, 0x2d, 0x0b, 0xab, 0xed, 0xf2, 0x5b, 0x60, 0xef, 0xc2,
-	0x9d, 0x9a, 0x58, 0x64, 0x2c, 0x15, 0xd4, 0xfe, 0x0c, 0xe0, 0xbd, 0x3f, 0x18, 0x90, 0x0d, 0x7b,
-	0x31, 0x99, 0xd0, 0xb8, 0x92, 0x02, 0xc3, 0x6d, 0x0f, 0x2e, 0x0b, 0xab, 0xc9, 0xf8, 0xcd, 0x2f,
-	0x1a, 0xc3, 0x4d, 0x9a, 0x4a, 0x1e, 0x51, 0x61, 0x74, 0x94, 0x9f, 0xc3, 0x95, 0x9f, 0x97, 0xa9,
-	0xe4, 0x97, 0xad, 0x9d, 0xfb, 0xd7, 0x85, 0xa5, 0x55, 0x46, 0x9a, 0x72, 0xbf, 0x05, 0xe8, 0x11,
-	0xd4, 0x43, 0x22, 0x42, 0xa3, 0x3b, 0x00, 0x43, 0xdd, 0xdb, 0x58, 0x16, 0x16, 0x78, 0xea, 0xab,
-	0x94, 0xfd, 0x02, 0xee, 0x9d, 0x54, 0x3a, 0xa7, 0x24, 0xe2, 0xad, 0x2b, 0x04, 0xf5, 0x94, 0x24,
-	0xb4, 0xf6, 0xe4, 0x2b, 0x8c, 0xf6, 0xe1, 0xc6, 0x47, 0x12, 0xe7, 0xd4, 0xe8, 0xa8, 0x64, 0x1d,
-	0xd8, 0x5f, 0x3b, 0x70, 0x67, 0xdd, 0x03, 0x3a, 0x86, 0xdb, 0xbf, 0xc7, 0xab, 0xfa, 0xfb, 0x23,
-	0xd3, 0xa9, 0x1f, 0xc0, 0x69, 0x1f, 0xc0, 0x79, 0xd3, 0x56, 0x78, 0xbb, 0x8d, 0xe5, 0x8e, 0x14,
-	0x57, 0xdf, 0x2d, 0xe0, 0xaf, 0x9a, 0xd1, 0x11, 0xd4, 0xe3, 0x28, 0x6d, 0xf4, 0xbc, 0xad, 0x65,
-	0x61, 0xa9, 0xd8, 0x57, 0x27, 0xca, 0x20, 0x12, 0x92, 0xe7, 0x67, 0x32, 0xe7, 0x74, 0xfa, 0x8a,
-	0x4a, 0x32, 0x25, 0x92, 0x18, 0x5d, 0x35, 0x1f, 0x73, 0x35, 0x9f, 0xbb, 0x7f, 0xcd, 0x7b, 0xdc,
-	0x08, 0x1e, 0xfd, 0xdd, 0xfd, 0x84, 0x25, 0x91, 0xa4, 0x49, 0x26, 0x2f, 0xfd, 0x7f, 0x70, 0xa3,
-	0x13, 0xd8, 0xcb, 0x08, 0x17, 0x74, 0x6a, 0xe8, 0xff, 0x55, 0x31, 0x1a, 0x95, 0xbd, 0xba, 0x63,
-	0x8d, 0xb9, 0xe1, 0x18, 0x8d, 0x61, 0xaf, 0x5a, 0x0d, 0xca, 0xd1, 0x73, 0xa8, 0x57, 0x08, 0x1d,
-	0xac, 0xf8, 0xd6, 0xb6, 0xd1, 0x3c, 0xbc, 0x9b, 0x6e, 0x76, 0x49, 0xf3, 0xde, 0xce, 0xe6, 0x58,
-	0xbb, 0x99, 0x63, 0xed, 0x76, 0x8e, 0xc1, 0xa7, 0x12, 0x83, 0x2f, 0x25, 0x06, 0xd7, 0x25, 0x06,
-	0xb3, 0x12, 0x83, 0x1f, 0x25, 0x06, 0x3f, 0x4b, 0xac, 0xdd, 0x96, 0x18, 0x5c, 0x2d, 0xb0, 0x36,
-	0x5b, 0x60, 0xed, 0x66, 0x81, 0xb5, 0xf7, 0x83, 0x20, 0x92, 0x61, 0x3e, 0x71, 0xce, 0x58, 0xe2,
-	0x06, 0x9c, 0x9c, 0x93, 0x94, 0xb8, 0x31, 0xbb, 0x88, 0xdc, 0xf6, 0xd3, 0x9a, 0xf4, 0x94, 0xda,
-	0xb3, 0x5f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x7e, 0xaa, 0x57, 0xd3, 0x6d, 0x03, 0x00, 0x00,
+	// 764 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xcd, 0x6e, 0xdb, 0x46,
+	0x10, 0xe6, 0x5a, 0x3f, 0x96, 0xd6, 0x3f, 0x12, 0xd6, 0x3f, 0xa5, 0x05, 0x97, 0x2b, 0x10, 0x3d,
+	0xa8, 0xa8, 0x2b, 0x01, 0xee, 0xa1, 0x97, 0x02, 0x85, 0x09, 0x18, 0x70, 0x01, 0x17, 0x35, 0xe8,
+	0x22, 0x01, 0x72, 0x31, 0x56, 0xd2, 0x8a, 0x62, 0x4c, 0x89, 0xcc, 0xee, 0x32, 0x88, 0x6f, 0x79,
+	0x04, 0xe7, 0x9e, 0x07, 0x08, 0xf2, 0x00, 0x79, 0x06, 0x1f, 0x7d, 0x34, 0x72, 0x60, 0x62, 0xf9,
+	0x12, 0xf0, 0xe4, 0x47, 0x08, 0xb8, 0x4b, 0x5a, 0x34, 0xed, 0x20, 0xc8, 0x45, 0x9c, 0xfd, 0x66,
+	0x66, 0xbf, 0xf9, 0xdb, 0x11, 0x5c, 0x0b, 0x4e, 0x9d, 0x5e, 0x10, 0xf2, 0xb1, 0xfc, 0xe9, 0x06,
+	0xcc, 0x17, 0x3e, 0xaa, 0x79, 0xbe, 0x23, 0xa5, 0xd6, 0xba, 0xe3, 0x3b, 0xbe, 0x14, 0x7b, 0x89,
+	0xa4, 0xf4, 0x2d, 0xec, 0xf8, 0xbe, 0xe3, 0xd1, 0x9e, 0x3c, 0xf5, 0xc3, 0x51, 0x4f, 0xb8, 0x13,
+	0xca, 0x05, 0x99, 0x04, 0xca, 0xc0, 0x7c, 0x0a, 0x97, 0x8e, 0x42, 0x3e, 0xb6, 0xe9, 0x8b, 0x90,
+	0x72, 0x81, 0x0e, 0xe0, 0x22, 0x17, 0x8c, 0x92, 0x09, 0xd7, 0x41, 0xbb, 0xd4, 0x59, 0xda, 0xfd,
+	0xa9, 0x9b, 0x31, 0x74, 0x8f, 0xa5, 0x62, 0x6f, 0x48, 0x02, 0x41, 0x99, 0xb5, 0xf1, 0x31, 0xc2,
+	0x55, 0x05, 0xc5, 0x11, 0xce, 0xbc, 0xec, 0x4c, 0x30, 0x05, 0x5c, 0x56, 0x17, 0xf3, 0xc0, 0x9f,
+	0x72, 0x8a, 0x86, 0xb0, 0x11, 0x10, 0x26, 0x5c, 0xe2, 0x9d, 0xf0, 0x70, 0x30, 0xa0, 0x3c, 0x61,
+	0x00, 0x9d, 0xa5, 0xdd, 0xed, 0x39, 0x43, 0xe2, 0x70, 0xa4, 0x8c, 0x8e, 0x95, 0x8d, 0xf5, 0x73,
+	0x1c, 0xe1, 0xad, 0x82, 0xe3, 0x8e, 0x3f, 0x71, 0x05, 0x9d, 0x04, 0xe2, 0xcc, 0x5e, 0x0d, 0xee,
+	0x99, 0x9b, 0x6f, 0x01, 0x44, 0x0f, 0x6f, 0x41, 0x7f, 0xc3, 0x26, 0xa3, 0xcf, 0xe9, 0x40, 0xd0,
+	0xe1, 0x09, 0x9d, 0x0a, 0xe6, 0x52, 0xc5, 0x5e, 0xb2, 0xd6, 0xe3, 0x08, 0x3f, 0xd0, 0xd9, 0x8d,
+	0x0c, 0xd9, 0x57, 0x00, 0xda, 0x9f, 0xd7, 0x65, 0x41, 0xd6, 0xa5, 0x55, 0xac, 0x4b, 0x9a, 0x6c,
+	0xe8, 0x09, 0xab, 0x71, 0x11, 0x61, 0xed, 0xd1, 0xa2, 0xbc, 0x2f, 0xc1, 0x66, 0xd1, 0x1c, 0x61,
+	0x58, 0x71, 0xa7, 0x43, 0xfa, 0x4a, 0x46, 0x54, 0xb1, 0xea, 0x71, 0x84, 0x15, 0x60, 0xab, 0x0f,
+	0x32, 0x61, 0xd5, 0x23, 0x7d, 0xea, 0x25, 0xdc, 0xa0, 0x53, 0xb7, 0x60, 0x1c, 0xe1, 0x14, 0xb1,
+	0xd3, 0x6f, 0x92, 0x21, 0x19, 0x0c, 0x68, 0x90, 0xcf, 0xb0, 0x34, 0xcf, 0xb0, 0xa8, 0xb3, 0x1b,
+	0x19, 0x92, 0x65, 0xf8, 0x58, 0x89, 0xca, 0x3f, 0x52, 0xa2, 0x1d, 0x58, 0x65, 0x94, 0x70, 0x7f,
+	0xaa, 0x57, 0x64, 0x94, 0xa9, 0x5b, 0x82, 0xe4, 0x1a, 0x96, 0xda, 0xa0, 0x5f, 0x61, 0x85, 0x32,
+	0xe6, 0x33, 0xbd, 0x2a, 0x8d, 0xd7, 0xe2, 0x08, 0x37, 0x24, 0x90, 0xb3, 0x55, 0x16, 0xe8, 0x37,
+	0x58, 0x67, 0x54, 0xb0, 0x33, 0xd2, 0xf7, 0xa8, 0xbe, 0xd8, 0x06, 0x9d, 0x9a, 0xb5, 0x12, 0x47,
+	0x78, 0x0e, 0xda, 0x73, 0x11, 0xfd, 0x93, 0x4b, 0xc3, 0x1f, 0x8d, 0x38, 0x15, 0x5c, 0xaf, 0xb5,
+	0x4b, 0x9d, 0x8a, 0x65, 0xc4, 0x11, 0x6e, 0x15, 0x75, 0x39, 0xb6, 0xbb, 0x84, 0xfe, 0x53, 0x2a,
+	0xf3, 0x0d, 0x80, 0x2b, 0xf7, 0x66, 0x3e, 0xd7, 0x08, 0xf0, 0xcd, 0x46, 0xec, 0xc1, 0xc5, 0xac,
+	0x7c, 0x6a, 0x52, 0x36, 0xe7, 0x93, 0x92, 0x94, 0xea, 0x2c, 0x7b, 0x40, 0x77, 0x53, 0x92, 0x55,
+	0x34, 0x13, 0xd0, 0x16, 0x2c, 0x8f, 0x09, 0x1f, 0xcb, 0xfe, 0x95, 0xad, 0x4a, 0x1c, 0x61, 0xf0,
+	0xbb, 0x2d, 0x21, 0xf3, 0x2f, 0xd8, 0x3c, 0x4c, 0x78, 0x8e, 0x88, 0xcb, 0xb2, 0xa8, 0x10, 0x2c,
+	0x4f, 0xc9, 0x84, 0xaa, 0x98, 0x6c, 0x29, 0xa3, 0x75, 0x58, 0x79, 0x49, 0xbc, 0x90, 0xaa, 0x89,
+	0xb1, 0xd5, 0xc1, 0xfc, 0xb0, 0x00, 0x97, 0xf3, 0x31, 0xa0, 0x03, 0x58, 0xbf, 0x5b, 0x08, 0xe9,
+	0x73, 0x6c, 0x75, 0xd5, 0xca, 0xe8, 0x66, 0x2b, 0xa3, 0xfb, 0x7f, 0x66, 0x61, 0xad, 0xa6, 0x21,
+	0x2f, 0x08, 0x7e, 0xfe, 0x09, 0x03, 0x7b, 0xee, 0x8c, 0xb6, 0x61, 0xd9, 0x73, 0xa7, 0x29, 0x9f,
+	0x55, 0x8b, 0x23, 0x2c, 0xcf, 0xb6, 0xfc, 0x45, 0x01, 0x44, 0x5c, 0xb0, 0x70, 0x20, 0x42, 0x46,
+	0x87, 0xff, 0x52, 0x41, 0x86, 0x44, 0x10, 0xbd, 0x54, 0x7c, 0x49, 0xc5, 0xd4, 0xac, 0x5f, 0x52,
+	0xc2, 0xed, 0x87, 0xde, 0xb9, 0xce, 0x3d, 0x72, 0x37, 0x3a, 0x84, 0xd5, 0x80, 0x30, 0x4e, 0x87,
+	0x7a, 0xf9, 0xbb, 0x2c, 0x7a, 0xca, 0xd2, 0x54, 0x1e, 0xf9, 0x69, 0x55, 0xc8, 0xee, 0x1e, 0xac,
+	0x26, 0x0f, 0x96, 0x32, 0xf4, 0x27, 0x2c, 0x27, 0x12, 0xda, 0xb8, 0xbf, 0xb5, 0xd2, 0xfd, 0xd9,
+	0xda, 0x2c, 0xc2, 0x6a, 0xfb, 0x99, 0x9a, 0xf5, 0xe4, 0xf2, 0xda, 0xd0, 0xae, 0xae, 0x0d, 0xed,
+	0xf6, 0xda, 0x00, 0xaf, 0x67, 0x06, 0x78, 0x37, 0x33, 0xc0, 0xc5, 0xcc, 0x00, 0x97, 0x33, 0x03,
+	0x7c, 0x9e, 0x19, 0xe0, 0xcb, 0xcc, 0xd0, 0x6e, 0x67, 0x06, 0x38, 0xbf, 0x31, 0xb4, 0xcb, 0x1b,
+	0x43, 0xbb, 0xba, 0x31, 0xb4, 0x67, 0x6d, 0xc7, 0x15, 0xe3, 0xb0, 0xdf, 0x1d, 0xf8, 0x93, 0x9e,
+	0xc3, 0xc8, 0x88, 0x4c, 0x49, 0xcf, 0xf3, 0x4f, 0xdd, 0x5e, 0xf6, 0x67, 0xd0, 0xaf, 0x4a, 0xb6,
+	0x3f, 0xbe, 0x0e, 0x00, 0x5c, 0xc0, 0x0e, 0x99, 0x1f, 0x06, 0x00, 0x00,
 }
 
 func (this *PushRequest) Equal(that interface{}) bool {
@@ -380,6 +567,91 @@ func (this *PushResponse) Equal(that interface{}) bool {
 	} else if this == nil {
 		return false
 	}
+	if !this.PartialSuccess.Equal(that1.PartialSuccess) {
+		return false
+	}
+	return true
+}
+func (this *PushPartialSuccess) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*PushPartialSuccess)
+	if !ok {
+		that2, ok := that.(PushPartialSuccess)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.RejectedEntries != that1.RejectedEntries {
+		return false
+	}
+	if len(this.Streams) != len(that1.Streams) {
+		return false
+	}
+	for i := range this.Streams {
+		if !this.Streams[i].Equal(&that1.Streams[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *StreamPushResult) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*StreamPushResult)
+	if !ok {
+		that2, ok := that.(StreamPushResult)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Index != that1.Index {
+		return false
+	}
+	if this.Labels != that1.Labels {
+		return false
+	}
+	if this.AcceptedEntries != that1.AcceptedEntries {
+		return false
+	}
+	if this.RejectedEntries != that1.RejectedEntries {
+		return false
+	}
+	if this.Reason != that1.Reason {
+		return false
+	}
+	if this.Error != that1.Error {
+		return false
+	}
+	if this.Retryable != that1.Retryable {
+		return false
+	}
+	if len(this.RejectedOffsets) != len(that1.RejectedOffsets) {
+		return false
+	}
+	for i := range this.RejectedOffsets {
+		if this.RejectedOffsets[i] != that1.RejectedOffsets[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *StreamAdapter) Equal(that interface{}) bool {
@@ -501,8 +773,45 @@ func (this *PushResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 4)
+	s := make([]string, 0, 5)
 	s = append(s, "&push.PushResponse{")
+	if this.PartialSuccess != nil {
+		s = append(s, "PartialSuccess: "+fmt.Sprintf("%#v", this.PartialSuccess)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *PushPartialSuccess) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&push.PushPartialSuccess{")
+	s = append(s, "RejectedEntries: "+fmt.Sprintf("%#v", this.RejectedEntries)+",\n")
+	if this.Streams != nil {
+		vs := make([]*StreamPushResult, len(this.Streams))
+		for i := range vs {
+			vs[i] = &this.Streams[i]
+		}
+		s = append(s, "Streams: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *StreamPushResult) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 12)
+	s = append(s, "&push.StreamPushResult{")
+	s = append(s, "Index: "+fmt.Sprintf("%#v", this.Index)+",\n")
+	s = append(s, "Labels: "+fmt.Sprintf("%#v", this.Labels)+",\n")
+	s = append(s, "AcceptedEntries: "+fmt.Sprintf("%#v", this.AcceptedEntries)+",\n")
+	s = append(s, "RejectedEntries: "+fmt.Sprintf("%#v", this.RejectedEntries)+",\n")
+	s = append(s, "Reason: "+fmt.Sprintf("%#v", this.Reason)+",\n")
+	s = append(s, "Error: "+fmt.Sprintf("%#v", this.Error)+",\n")
+	s = append(s, "Retryable: "+fmt.Sprintf("%#v", this.Retryable)+",\n")
+	s = append(s, "RejectedOffsets: "+fmt.Sprintf("%#v", this.RejectedOffsets)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -706,10 +1015,22 @@ func (m *PushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.PartialSuccess != nil {
+		{
+			size, err := m.PartialSuccess.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintPush(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *StreamAdapter) Marshal() (dAtA []byte, err error) {
+func (m *PushPartialSuccess) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -719,25 +1040,20 @@ func (m *StreamAdapter) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *StreamAdapter) MarshalTo(dAtA []byte) (int, error) {
+func (m *PushPartialSuccess) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *StreamAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *PushPartialSuccess) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.Hash != 0 {
-		i = encodeVarintPush(dAtA, i, uint64(m.Hash))
-		i--
-		dAtA[i] = 0x18
-	}
-	if len(m.Entries) > 0 {
-		for iNdEx := len(m.Entries) - 1; iNdEx >= 0; iNdEx-- {
+	if len(m.Streams) > 0 {
+		for iNdEx := len(m.Streams) - 1; iNdEx >= 0; iNdEx-- {
 			{
-				size, err := m.Entries[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				size, err := m.Streams[iNdEx].MarshalToSizedBuffer(dAtA[:i])
 				if err != nil {
 					return 0, err
 				}
@@ -748,17 +1064,15 @@ func (m *StreamAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 			dAtA[i] = 0x12
 		}
 	}
-	if len(m.Labels) > 0 {
-		i -= len(m.Labels)
-		copy(dAtA[i:], m.Labels)
-		i = encodeVarintPush(dAtA, i, uint64(len(m.Labels)))
+	if m.RejectedEntries != 0 {
+		i = encodeVarintPush(dAtA, i, uint64(m.RejectedEntries))
 		i--
-		dAtA[i] = 0xa
+		dAtA[i] = 0x8
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *LabelPairAdapter) Marshal() (dAtA []byte, err error) {
+func (m *StreamPushResult) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -768,34 +1082,85 @@ func (m *LabelPairAdapter) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *LabelPairAdapter) MarshalTo(dAtA []byte) (int, error) {
+func (m *StreamPushResult) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *LabelPairAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *StreamPushResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Value) > 0 {
-		i -= len(m.Value)
-		copy(dAtA[i:], m.Value)
-		i = encodeVarintPush(dAtA, i, uint64(len(m.Value)))
+	if len(m.RejectedOffsets) > 0 {
+		dAtA2 := make([]byte, len(m.RejectedOffsets)*10)
+		var j1 int
+		for _, num1 := range m.RejectedOffsets {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintPush(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0x42
+	}
+	if m.Retryable {
+		i--
+		if m.Retryable {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Reason) > 0 {
+		i -= len(m.Reason)
+		copy(dAtA[i:], m.Reason)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Reason)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.RejectedEntries != 0 {
+		i = encodeVarintPush(dAtA, i, uint64(m.RejectedEntries))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.AcceptedEntries != 0 {
+		i = encodeVarintPush(dAtA, i, uint64(m.AcceptedEntries))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Labels) > 0 {
+		i -= len(m.Labels)
+		copy(dAtA[i:], m.Labels)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Labels)))
 		i--
 		dAtA[i] = 0x12
 	}
-	if len(m.Name) > 0 {
-		i -= len(m.Name)
-		copy(dAtA[i:], m.Name)
-		i = encodeVarintPush(dAtA, i, uint64(len(m.Name)))
+	if m.Index != 0 {
+		i = encodeVarintPush(dAtA, i, uint64(m.Index))
 		i--
-		dAtA[i] = 0xa
+		dAtA[i] = 0x8
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *EntryAdapter) Marshal() (dAtA []byte, err error) {
+func (m *StreamAdapter) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -805,21 +1170,107 @@ func (m *EntryAdapter) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *EntryAdapter) MarshalTo(dAtA []byte) (int, error) {
+func (m *StreamAdapter) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *StreamAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Parsed) > 0 {
-		for iNdEx := len(m.Parsed) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Parsed[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
+	if m.Hash != 0 {
+		i = encodeVarintPush(dAtA, i, uint64(m.Hash))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Entries) > 0 {
+		for iNdEx := len(m.Entries) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Entries[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintPush(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Labels) > 0 {
+		i -= len(m.Labels)
+		copy(dAtA[i:], m.Labels)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Labels)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *LabelPairAdapter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LabelPairAdapter) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LabelPairAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *EntryAdapter) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *EntryAdapter) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Parsed) > 0 {
+		for iNdEx := len(m.Parsed) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Parsed[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
 					return 0, err
 				}
 				i -= size
@@ -850,12 +1301,12 @@ func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x12
 	}
-	n1, err1 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
-	if err1 != nil {
-		return 0, err1
+	n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
+	if err3 != nil {
+		return 0, err3
 	}
-	i -= n1
-	i = encodeVarintPush(dAtA, i, uint64(n1))
+	i -= n3
+	i = encodeVarintPush(dAtA, i, uint64(n3))
 	i--
 	dAtA[i] = 0xa
 	return len(dAtA) - i, nil
@@ -893,6 +1344,68 @@ func (m *PushResponse) Size() (n int) {
 	}
 	var l int
 	_ = l
+	if m.PartialSuccess != nil {
+		l = m.PartialSuccess.Size()
+		n += 1 + l + sovPush(uint64(l))
+	}
+	return n
+}
+
+func (m *PushPartialSuccess) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RejectedEntries != 0 {
+		n += 1 + sovPush(uint64(m.RejectedEntries))
+	}
+	if len(m.Streams) > 0 {
+		for _, e := range m.Streams {
+			l = e.Size()
+			n += 1 + l + sovPush(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *StreamPushResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Index != 0 {
+		n += 1 + sovPush(uint64(m.Index))
+	}
+	l = len(m.Labels)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
+	if m.AcceptedEntries != 0 {
+		n += 1 + sovPush(uint64(m.AcceptedEntries))
+	}
+	if m.RejectedEntries != 0 {
+		n += 1 + sovPush(uint64(m.RejectedEntries))
+	}
+	l = len(m.Reason)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
+	if m.Retryable {
+		n += 2
+	}
+	if len(m.RejectedOffsets) > 0 {
+		l = 0
+		for _, e := range m.RejectedOffsets {
+			l += sovPush(uint64(e))
+		}
+		n += 1 + sovPush(uint64(l)) + l
+	}
 	return n
 }
 
@@ -983,6 +1496,40 @@ func (this *PushResponse) String() string {
 		return "nil"
 	}
 	s := strings.Join([]string{`&PushResponse{`,
+		`PartialSuccess:` + strings.Replace(this.PartialSuccess.String(), "PushPartialSuccess", "PushPartialSuccess", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *PushPartialSuccess) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForStreams := "[]StreamPushResult{"
+	for _, f := range this.Streams {
+		repeatedStringForStreams += strings.Replace(strings.Replace(f.String(), "StreamPushResult", "StreamPushResult", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForStreams += "}"
+	s := strings.Join([]string{`&PushPartialSuccess{`,
+		`RejectedEntries:` + fmt.Sprintf("%v", this.RejectedEntries) + `,`,
+		`Streams:` + repeatedStringForStreams + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *StreamPushResult) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&StreamPushResult{`,
+		`Index:` + fmt.Sprintf("%v", this.Index) + `,`,
+		`Labels:` + fmt.Sprintf("%v", this.Labels) + `,`,
+		`AcceptedEntries:` + fmt.Sprintf("%v", this.AcceptedEntries) + `,`,
+		`RejectedEntries:` + fmt.Sprintf("%v", this.RejectedEntries) + `,`,
+		`Reason:` + fmt.Sprintf("%v", this.Reason) + `,`,
+		`Error:` + fmt.Sprintf("%v", this.Error) + `,`,
+		`Retryable:` + fmt.Sprintf("%v", this.Retryable) + `,`,
+		`RejectedOffsets:` + fmt.Sprintf("%v", this.RejectedOffsets) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1162,6 +1709,450 @@ func (m *PushResponse) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: PushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartialSuccess", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.PartialSuccess == nil {
+				m.PartialSuccess = &PushPartialSuccess{}
+			}
+			if err := m.PartialSuccess.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPush(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPush
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthPush
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushPartialSuccess) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPush
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushPartialSuccess: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushPartialSuccess: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedEntries", wireType)
+			}
+			m.RejectedEntries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RejectedEntries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Streams", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Streams = append(m.Streams, StreamPushResult{})
+			if err := m.Streams[len(m.Streams)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPush(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPush
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthPush
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StreamPushResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPush
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StreamPushResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StreamPushResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Labels", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Labels = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AcceptedEntries", wireType)
+			}
+			m.AcceptedEntries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AcceptedEntries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedEntries", wireType)
+			}
+			m.RejectedEntries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RejectedEntries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Reason = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Retryable", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Retryable = bool(v != 0)
+		case 8:
+			if wireType == 0 {
+				var v int32
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowPush
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int32(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.RejectedOffsets = append(m.RejectedOffsets, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowPush
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthPush
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthPush
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.RejectedOffsets) == 0 {
+					m.RejectedOffsets = make([]int32, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int32
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowPush
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int32(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.RejectedOffsets = append(m.RejectedOffsets, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field RejectedOffsets", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPush(dAtA[iNdEx:])
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/storage/detected"
 	index_stats "github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
@@ -397,6 +398,23 @@ func (q *IngesterQuerier) DetectedLabel(ctx context.Context, req *logproto.Detec
 	return &logproto.LabelToValuesResponse{Labels: mergedResult}, nil
 }
 
+// DetectedFields returns the fields detected by all the ingesters, merged.
+func (q *IngesterQuerier) DetectedFields(ctx context.Context, req *logproto.DetectedFieldsRequest) ([]*logproto.DetectedField, error) {
+	resps, err := q.forAllIngesters(ctx, func(ctx context.Context, client logproto.QuerierClient) (interface{}, error) {
+		return client.GetDetectedFields(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []*logproto.DetectedField
+	for _, resp := range resps {
+		fields = append(fields, resp.response.(*logproto.DetectedFieldsResponse).Fields...)
+	}
+
+	return detected.MergeFields(fields, req.FieldLimit)
+}
+
 func convertMatchersToString(matchers []*labels.Matcher) string {
 	out := strings.Builder{}
 	out.WriteRune('{')
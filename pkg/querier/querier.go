@@ -7,11 +7,9 @@ import (
 	"net/http"
 	"regexp"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/axiomhq/hyperloglog"
-	"github.com/dustin/go-humanize"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/httpgrpc"
@@ -31,12 +29,12 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
-	logql_log "github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	querier_limits "github.com/grafana/loki/v3/pkg/querier/limits"
 	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/detected"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
@@ -263,6 +261,10 @@ func (q *SingleTenantQuerier) deletesForUser(ctx context.Context, startT, endT t
 
 	var deletes []*logproto.Delete
 	for _, del := range d {
+		// Redacted lines are not filtered out, they are only rewritten by the compactor.
+		if del.Redaction != nil {
+			continue
+		}
 		if del.StartTime.UnixNano() <= end && del.EndTime.UnixNano() >= start {
 			deletes = append(deletes, &logproto.Delete{
 				Selector: del.Query,
@@ -993,6 +995,10 @@ func containsAllIDTypes(values []string) bool {
 	return true
 }
 
+// DetectedFields returns the fields detected in the most recent lines of the
+// streams matching the request. The ingesters detect the fields of their
+// in-memory streams themselves, while the lines of the store are parsed here,
+// and both are merged.
 func (q *SingleTenantQuerier) DetectedFields(ctx context.Context, req *logproto.DetectedFieldsRequest) (*logproto.DetectedFieldsResponse, error) {
 	expr, err := syntax.ParseLogSelector(req.Query, true)
 	if err != nil {
@@ -1011,25 +1017,79 @@ func (q *SingleTenantQuerier) DetectedFields(ctx context.Context, req *logproto.
 		},
 	}
 
-	iters, err := q.SelectLogs(ctx, params)
+	params.Start, params.End, err = q.validateQueryRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(params.Start, params.End)
+
+	var ingesterFields []*logproto.DetectedField
+	if !q.cfg.QueryStoreOnly && ingesterQueryInterval != nil {
+		g.Go(func() error {
+			var err error
+			timeFramedReq := *req
+			timeFramedReq.Start = ingesterQueryInterval.start
+			timeFramedReq.End = ingesterQueryInterval.end
+
+			ingesterFields, err = q.ingesterQuerier.DetectedFields(ctx, &timeFramedReq)
+			return err
+		})
+	}
+
+	var storeFields []*logproto.DetectedField
+	if !q.cfg.QueryIngesterOnly && storeQueryInterval != nil {
+		g.Go(func() error {
+			var err error
+			storeFields, err = q.storeDetectedFields(ctx, req, params, storeQueryInterval)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	fields, err := detected.MergeFields(append(ingesterFields, storeFields...), req.FieldLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logproto.DetectedFieldsResponse{
+		Fields:     fields,
+		FieldLimit: req.GetFieldLimit(),
+	}, nil
+}
+
+// storeDetectedFields parses the most recent lines of the store matching the
+// request within the interval.
+func (q *SingleTenantQuerier) storeDetectedFields(ctx context.Context, req *logproto.DetectedFieldsRequest, params logql.SelectLogParams, interval *interval) ([]*logproto.DetectedField, error) {
+	var err error
+	params.QueryRequest.Deletes, err = q.deletesForUser(ctx, params.Start, params.End)
+	if err != nil {
+		level.Error(spanlogger.FromContext(ctx)).Log("msg", "failed loading deletes for user", "err", err)
+	}
+	params.Start = interval.start
+	params.End = interval.end
+
+	it, err := q.store.SelectLogs(ctx, params)
 	if err != nil {
 		return nil, err
 	}
+	defer listutil.LogErrorWithContext(ctx, "closing iterator", it.Close)
 
 	// TODO(twhitney): converting from a step to a duration should be abstracted and reused,
 	// doing this in a few places now.
-	streams, err := streamsForFieldDetection(iters, req.LineLimit, time.Duration(req.Step))
+	streams, err := streamsForFieldDetection(it, req.LineLimit, time.Duration(req.Step))
 	if err != nil {
 		return nil, err
 	}
 
 	detectedFields := parseDetectedFields(ctx, req.FieldLimit, streams)
 
-	//TODO: detected field needs to contain the sketch
-	// make sure response to frontend is GRPC
-	//only want cardinality in JSON
-	fields := make([]*logproto.DetectedField, len(detectedFields))
-	fieldCount := 0
+	fields := make([]*logproto.DetectedField, 0, len(detectedFields))
 	for k, v := range detectedFields {
 		sketch, err := v.sketch.MarshalBinary()
 		if err != nil {
@@ -1037,21 +1097,14 @@ func (q *SingleTenantQuerier) DetectedFields(ctx context.Context, req *logproto.
 			continue
 		}
 
-		fields[fieldCount] = &logproto.DetectedField{
+		fields = append(fields, &logproto.DetectedField{
 			Label:       k,
 			Type:        v.fieldType,
 			Cardinality: v.Estimate(),
 			Sketch:      sketch,
-		}
-
-		fieldCount++
+		})
 	}
-
-	//TODO: detected fields response needs to include the sketch
-	return &logproto.DetectedFieldsResponse{
-		Fields:     fields,
-		FieldLimit: req.GetFieldLimit(),
-	}, nil
+	return fields, nil
 }
 
 type parsedFields struct {
@@ -1081,34 +1134,10 @@ func (p *parsedFields) Marshal() ([]byte, error) {
 }
 
 func (p *parsedFields) DetermineType(value string) {
-	p.fieldType = determineType(value)
+	p.fieldType = detected.DetermineType(value)
 	p.isTypeDetected = true
 }
 
-func determineType(value string) logproto.DetectedFieldType {
-	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
-		return logproto.DetectedFieldInt
-	}
-
-	if _, err := strconv.ParseFloat(value, 64); err == nil {
-		return logproto.DetectedFieldFloat
-	}
-
-	if _, err := strconv.ParseBool(value); err == nil {
-		return logproto.DetectedFieldBoolean
-	}
-
-	if _, err := time.ParseDuration(value); err == nil {
-		return logproto.DetectedFieldDuration
-	}
-
-	if _, err := humanize.ParseBytes(value); err == nil {
-		return logproto.DetectedFieldBytes
-	}
-
-	return logproto.DetectedFieldString
-}
-
 func parseDetectedFields(ctx context.Context, limit uint32, streams logqlmodel.Streams) map[string]*parsedFields {
 	detectedFields := make(map[string]*parsedFields, limit)
 	fieldCount := uint32(0)
@@ -1119,8 +1148,8 @@ func parseDetectedFields(ctx context.Context, limit uint32, streams logqlmodel.S
 			"msg", fmt.Sprintf("looking for detected fields in stream %d with %d lines", stream.Hash, len(stream.Entries)))
 
 		for _, entry := range stream.Entries {
-			detected := parseLine(entry.Line)
-			for k, vals := range detected {
+			fields := detected.ParseLine(entry.Line)
+			for k, vals := range fields {
 				df, ok := detectedFields[k]
 				if !ok && fieldCount < limit {
 					df = newParsedFields()
@@ -1151,41 +1180,6 @@ func parseDetectedFields(ctx context.Context, limit uint32, streams logqlmodel.S
 	return detectedFields
 }
 
-func parseLine(line string) map[string][]string {
-	logFmtParser := logql_log.NewLogfmtParser(true, false)
-	jsonParser := logql_log.NewJSONParser()
-
-	lbls := logql_log.NewBaseLabelsBuilder().ForLabels(labels.EmptyLabels(), 0)
-	_, logfmtSuccess := logFmtParser.Process(0, []byte(line), lbls)
-	if !logfmtSuccess || lbls.HasErr() {
-		lbls.Reset()
-		_, jsonSuccess := jsonParser.Process(0, []byte(line), lbls)
-		if !jsonSuccess || lbls.HasErr() {
-			return map[string][]string{}
-		}
-	}
-
-	parsedLabels := map[string]map[string]struct{}{}
-	for _, lbl := range lbls.LabelsResult().Labels() {
-		if values, ok := parsedLabels[lbl.Name]; ok {
-			values[lbl.Value] = struct{}{}
-		} else {
-			parsedLabels[lbl.Name] = map[string]struct{}{lbl.Value: {}}
-		}
-	}
-
-	result := make(map[string][]string, len(parsedLabels))
-	for lbl, values := range parsedLabels {
-		vals := make([]string, 0, len(values))
-		for v := range values {
-			vals = append(vals, v)
-		}
-		result[lbl] = vals
-	}
-
-	return result
-}
-
 // readStreams reads the streams from the iterator and returns them sorted.
 // If categorizeLabels is true, the stream labels contains just the stream labels and entries inside each stream have their
 // structuredMetadata and parsed fields populated with structured metadata labels plus the parsed labels respectively.
@@ -120,6 +120,15 @@ func (c *querierClientMock) GetVolume(ctx context.Context, in *logproto.VolumeRe
 	return res.(*logproto.VolumeResponse), args.Error(1)
 }
 
+func (c *querierClientMock) GetDetectedFields(ctx context.Context, in *logproto.DetectedFieldsRequest, opts ...grpc.CallOption) (*logproto.DetectedFieldsResponse, error) {
+	args := c.Called(ctx, in, opts)
+	res := args.Get(0)
+	if res == nil {
+		return (*logproto.DetectedFieldsResponse)(nil), args.Error(1)
+	}
+	return res.(*logproto.DetectedFieldsResponse), args.Error(1)
+}
+
 func (c *querierClientMock) Context() context.Context {
 	return context.Background()
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/axiomhq/hyperloglog"
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/httpgrpc"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
@@ -1350,6 +1352,69 @@ func TestQuerier_SelectSamplesWithDeletes(t *testing.T) {
 	require.Equal(t, "test", delGetter.user)
 }
 
+func TestQuerier_DetectedFields(t *testing.T) {
+	store := newStoreMock()
+	store.On("SelectLogs", mock.Anything, mock.Anything).Return(iter.NewStreamIterator(logproto.Stream{
+		Labels: `{type="test"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: time.Unix(1, 0), Line: "level=info duration=10ms"},
+			{Timestamp: time.Unix(2, 0), Line: "level=warn duration=20ms"},
+		},
+	}), nil)
+
+	sketch := func(values ...string) []byte {
+		s := hyperloglog.New()
+		for _, v := range values {
+			s.Insert([]byte(v))
+		}
+		b, err := s.MarshalBinary()
+		require.NoError(t, err)
+		return b
+	}
+	ingesterClient := newQuerierClientMock()
+	ingesterClient.On("GetDetectedFields", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.DetectedFieldsResponse{
+		Fields: []*logproto.DetectedField{
+			{Label: "level", Type: logproto.DetectedFieldString, Cardinality: 2, Sketch: sketch("info", "error")},
+			{Label: "trace_id", Type: logproto.DetectedFieldString, Cardinality: 1, Sketch: sketch("abc")},
+		},
+	}, nil)
+
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	q, err := newQuerier(
+		mockQuerierConfig(),
+		mockIngesterClientConfig(),
+		newIngesterClientMockFactory(ingesterClient),
+		mockReadRingWithOneActiveIngester(),
+		&mockDeleteGettter{}, store, limits)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	resp, err := q.DetectedFields(ctx, &logproto.DetectedFieldsRequest{
+		Query:      `{type="test"}`,
+		Start:      time.Unix(0, 0),
+		End:        time.Unix(10, 0),
+		LineLimit:  100,
+		FieldLimit: 10,
+	})
+	require.NoError(t, err)
+
+	fields := map[string]*logproto.DetectedField{}
+	for _, f := range resp.Fields {
+		fields[f.Label] = f
+	}
+	require.Len(t, fields, 3)
+	// The fields of the ingesters are merged with the fields of the store.
+	require.Equal(t, uint64(3), fields["level"].Cardinality)
+	require.Equal(t, uint64(1), fields["trace_id"].Cardinality)
+	require.Equal(t, logproto.DetectedFieldDuration, fields["duration"].Type)
+	require.Equal(t, uint64(2), fields["duration"].Cardinality)
+
+	ingesterClient.AssertCalled(t, "GetDetectedFields", mock.Anything, mock.Anything, mock.Anything)
+	store.AssertCalled(t, "SelectLogs", mock.Anything, mock.Anything)
+}
+
 func newQuerier(cfg Config, clientCfg client.Config, clientFactory ring_client.PoolFactory, ring ring.ReadRing, dg *mockDeleteGettter, store storage.Store, limits *validation.Overrides) (*SingleTenantQuerier, error) {
 	iq, err := newIngesterQuerier(clientCfg, ring, cfg.ExtraQueryDelay, clientFactory, constants.Loki)
 	if err != nil {
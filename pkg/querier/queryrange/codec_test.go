@@ -1760,6 +1760,7 @@ var (
 				"pipelineWrapperFilteredLines": 2
 			},
 			"totalBatches": 6,
+			"totalBlockCacheHits": 0,
 			"totalChunksMatched": 7,
 			"totalLinesSent": 9,
 			"totalReached": 10
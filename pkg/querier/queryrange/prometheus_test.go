@@ -40,6 +40,7 @@ var emptyStats = `"stats": {
 			}
 		},
 		"totalBatches": 0,
+		"totalBlockCacheHits": 0,
 		"totalChunksMatched": 0,
 		"totalLinesSent": 0,
 		"totalReached": 0
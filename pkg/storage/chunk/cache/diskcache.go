@@ -0,0 +1,468 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	diskCacheMagic     = uint32(0x4C4B4443) // "LKDC"
+	diskCacheHeaderLen = 8                  // magic + crc32
+	diskCacheTmpSuffix = ".tmp"
+
+	corruptedReason = "corrupted"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// DiskCacheConfig represents the config of the local disk cache.
+type DiskCacheConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	Path      string        `yaml:"path"`
+	MaxSizeMB int64         `yaml:"max_size_mb"`
+	TTL       time.Duration `yaml:"ttl"`
+
+	// PurgeInterval tell how often should we remove keys that are expired.
+	// by default it takes `defaultPurgeInterval`
+	PurgeInterval time.Duration `yaml:"-"`
+}
+
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, description+"Experimental: Whether the local disk cache is enabled. It sits between the embedded cache and memcached or redis, and is kept across restarts.")
+	f.StringVar(&cfg.Path, prefix+"path", "", description+"Experimental: Directory where the disk cache stores its entries. Each cache must use its own directory.")
+	f.Int64Var(&cfg.MaxSizeMB, prefix+"max-size-mb", 10*1024, description+"Experimental: Maximum size of the disk cache in MB. The least recently used entries are evicted when it's exceeded.")
+	f.DurationVar(&cfg.TTL, prefix+"ttl", 0, description+"Experimental: The time to live for entries in the disk cache, from when they're stored, before they get purged. 0 to use the default validity of the cache.")
+}
+
+func (cfg *DiskCacheConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+func (cfg *DiskCacheConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Path == "" {
+		return errors.New("the disk cache path must be set when the disk cache is enabled")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return errors.New("the disk cache max size must be positive when the disk cache is enabled")
+	}
+	if cfg.TTL < 0 {
+		return errors.New("the disk cache ttl must not be negative")
+	}
+	return nil
+}
+
+// DiskCache is a cache storing its entries as files in a local directory, so that
+// they survive restarts. The files are evicted in LRU order when the cache exceeds its
+// maximum size, and a checksum of each file is validated when it's read.
+//
+// Each entry is stored in a file named after the hash of its key, made of:
+//
+//	magic (be32) | crc32 of the rest of the file (be32) | stored at (be64, unix nanoseconds) | key length (uvarint) | key | value
+//
+// The LRU index is kept in memory and rebuilt from the modification times of the
+// files at startup, which are updated when the entries are read. The entries are
+// expired when read after their TTL, and the entries not used for longer than
+// their TTL are purged periodically.
+type DiskCache struct {
+	cacheType stats.CacheType
+	path      string
+	ttl       time.Duration
+	logger    log.Logger
+	done      chan struct{}
+
+	lock          sync.Mutex
+	maxSizeBytes  int64
+	currSizeBytes int64
+	entries       map[string]*list.Element
+	lru           *list.List
+
+	entriesAddedNew prometheus.Counter
+	entriesEvicted  *prometheus.CounterVec
+	entriesCurrent  prometheus.Gauge
+	diskBytes       prometheus.Gauge
+}
+
+type diskCacheEntry struct {
+	name     string // name of the file, relative to the cache directory
+	size     int64
+	lastUsed time.Time
+}
+
+// NewDiskCache returns a new DiskCache, loading the entries already stored in the directory.
+func NewDiskCache(name string, cfg DiskCacheConfig, reg prometheus.Registerer, logger log.Logger, cacheType stats.CacheType) (*DiskCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cfg.Path, 0o750); err != nil {
+		return nil, errors.Wrap(err, "creating disk cache directory")
+	}
+	if cfg.PurgeInterval == 0 {
+		cfg.PurgeInterval = defaultPurgeInterval
+	}
+
+	c := &DiskCache{
+		cacheType:    cacheType,
+		path:         cfg.Path,
+		ttl:          cfg.TTL,
+		logger:       log.With(logger, "cache", name),
+		done:         make(chan struct{}),
+		maxSizeBytes: cfg.MaxSizeMB * 1e6,
+		entries:      make(map[string]*list.Element),
+		lru:          list.New(),
+
+		entriesAddedNew: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "added_new_total",
+			Help:        "The total number of new entries added to the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		entriesEvicted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "evicted_total",
+			Help:        "The total number of evicted entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, []string{"reason"}),
+
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "entries",
+			Help:        "Current number of entries in the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+
+		diskBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "disk_bytes",
+			Help:        "The current cache size on disk in bytes",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, errors.Wrap(err, "loading disk cache")
+	}
+	if c.ttl > 0 {
+		go c.runPruneJob(cfg.PurgeInterval)
+	}
+	return c, nil
+}
+
+// load rebuilds the LRU index from the files of the cache directory.
+func (c *DiskCache) load() error {
+	type file struct {
+		diskCacheEntry
+		modTime time.Time
+	}
+	var files []file
+	err := filepath.WalkDir(c.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, diskCacheTmpSuffix) {
+			// leftover of an interrupted write.
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(c.path, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{diskCacheEntry{name: name, size: info.Size(), lastUsed: info.ModTime()}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Push the least recently used files first, so that they end up at the back.
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, f := range files {
+		entry := f.diskCacheEntry
+		c.entries[entry.name] = c.lru.PushFront(&entry)
+		c.currSizeBytes += entry.size
+		c.entriesCurrent.Inc()
+	}
+	c.evict(0)
+	c.pruneExpired(time.Now())
+	c.diskBytes.Set(float64(c.currSizeBytes))
+
+	level.Info(c.logger).Log("msg", "loaded disk cache", "entries", len(c.entries), "bytes", c.currSizeBytes)
+	return nil
+}
+
+// fileName returns the name of the file of the given key, relative to the cache directory.
+func fileName(key string) string {
+	h := fmt.Sprintf("%016x", xxhash.Sum64String(key))
+	return filepath.Join(h[:2], h)
+}
+
+// Fetch implements Cache.
+func (c *DiskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	for _, key := range keys {
+		buf, ok := c.get(key)
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+	return
+}
+
+func (c *DiskCache) get(key string) ([]byte, bool) {
+	name := fileName(key)
+
+	now := time.Now()
+	c.lock.Lock()
+	element, ok := c.entries[name]
+	if ok {
+		c.lru.MoveToFront(element)
+		element.Value.(*diskCacheEntry).lastUsed = now
+	}
+	c.lock.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	path := filepath.Join(c.path, name)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		// evicted concurrently.
+		return nil, false
+	}
+	storedKey, storedAt, value, err := decodeDiskCacheEntry(b)
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "removing corrupted disk cache entry", "file", path, "err", err)
+		c.removeFile(name, corruptedReason)
+		return nil, false
+	}
+	if storedKey != key {
+		// hash collision, the entry will be replaced when this key is stored.
+		return nil, false
+	}
+	if c.ttl > 0 && now.Sub(storedAt) > c.ttl {
+		c.removeFile(name, expiredReason)
+		return nil, false
+	}
+
+	_ = os.Chtimes(path, now, now)
+	return value, true
+}
+
+// Store implements Cache.
+func (c *DiskCache) Store(_ context.Context, keys []string, bufs [][]byte) error {
+	var err error
+	for i := range keys {
+		if storeErr := c.put(keys[i], bufs[i]); storeErr != nil {
+			err = storeErr
+		}
+	}
+	return err
+}
+
+func (c *DiskCache) put(key string, value []byte) error {
+	now := time.Now()
+	b := encodeDiskCacheEntry(key, now, value)
+	size := int64(len(b))
+	if size > c.maxSizeBytes {
+		c.entriesEvicted.WithLabelValues(tooBigReason).Inc()
+		return nil
+	}
+
+	// Write to a temporary file first so that readers never see a partial entry.
+	name := fileName(key)
+	path := filepath.Join(c.path, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errors.Wrap(err, "creating disk cache directory")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+diskCacheTmpSuffix)
+	if err != nil {
+		return errors.Wrap(err, "creating disk cache entry")
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "writing disk cache entry")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "writing disk cache entry")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "writing disk cache entry")
+	}
+
+	if element, ok := c.entries[name]; ok {
+		c.remove(element, replacedReason)
+	} else {
+		c.entriesAddedNew.Inc()
+	}
+	c.evict(size)
+	c.entries[name] = c.lru.PushFront(&diskCacheEntry{name: name, size: size, lastUsed: now})
+	c.currSizeBytes += size
+	c.entriesCurrent.Inc()
+	c.diskBytes.Set(float64(c.currSizeBytes))
+	return nil
+}
+
+// evict removes the least recently used entries until the given size fits in the cache.
+// Must hold lock.
+func (c *DiskCache) evict(size int64) {
+	for c.currSizeBytes+size > c.maxSizeBytes {
+		element := c.lru.Back()
+		if element == nil {
+			return
+		}
+		c.removeElementFile(element, fullReason)
+	}
+}
+
+func (c *DiskCache) runPruneJob(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.lock.Lock()
+			c.pruneExpired(time.Now())
+			c.diskBytes.Set(float64(c.currSizeBytes))
+			c.lock.Unlock()
+		}
+	}
+}
+
+// pruneExpired removes the entries not used for longer than the TTL, which are
+// expired since they were stored before they were last used. Must hold lock.
+func (c *DiskCache) pruneExpired(now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	for {
+		element := c.lru.Back()
+		if element == nil || now.Sub(element.Value.(*diskCacheEntry).lastUsed) <= c.ttl {
+			return
+		}
+		c.removeElementFile(element, expiredReason)
+	}
+}
+
+// removeElementFile removes an entry from the LRU index and its file. Must hold lock.
+func (c *DiskCache) removeElementFile(element *list.Element, reason string) {
+	entry := c.remove(element, reason)
+	if err := os.Remove(filepath.Join(c.path, entry.name)); err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "failed to remove disk cache entry", "file", entry.name, "err", err)
+	}
+}
+
+// remove removes an entry from the LRU index. Must hold lock.
+func (c *DiskCache) remove(element *list.Element, reason string) *diskCacheEntry {
+	entry := c.lru.Remove(element).(*diskCacheEntry)
+	delete(c.entries, entry.name)
+	c.currSizeBytes -= entry.size
+	c.entriesCurrent.Dec()
+	c.entriesEvicted.WithLabelValues(reason).Inc()
+	return entry
+}
+
+// removeFile removes an entry from the LRU index and its file.
+func (c *DiskCache) removeFile(name, reason string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.entries[name]
+	if !ok {
+		return
+	}
+	c.remove(element, reason)
+	c.diskBytes.Set(float64(c.currSizeBytes))
+	_ = os.Remove(filepath.Join(c.path, name))
+}
+
+// Stop implements Cache. The entries are kept on disk.
+func (c *DiskCache) Stop() {
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+}
+
+func (c *DiskCache) GetCacheType() stats.CacheType {
+	return c.cacheType
+}
+
+func encodeDiskCacheEntry(key string, storedAt time.Time, value []byte) []byte {
+	b := make([]byte, diskCacheHeaderLen, diskCacheHeaderLen+8+binary.MaxVarintLen64+len(key)+len(value))
+	b = binary.BigEndian.AppendUint64(b, uint64(storedAt.UnixNano()))
+	b = binary.AppendUvarint(b, uint64(len(key)))
+	b = append(b, key...)
+	b = append(b, value...)
+	binary.BigEndian.PutUint32(b[0:4], diskCacheMagic)
+	binary.BigEndian.PutUint32(b[4:8], crc32.Checksum(b[diskCacheHeaderLen:], castagnoliTable))
+	return b
+}
+
+func decodeDiskCacheEntry(b []byte) (string, time.Time, []byte, error) {
+	if len(b) < diskCacheHeaderLen+8 {
+		return "", time.Time{}, nil, errors.New("entry too short")
+	}
+	if binary.BigEndian.Uint32(b[0:4]) != diskCacheMagic {
+		return "", time.Time{}, nil, errors.New("invalid magic number")
+	}
+	if crc32.Checksum(b[diskCacheHeaderLen:], castagnoliTable) != binary.BigEndian.Uint32(b[4:8]) {
+		return "", time.Time{}, nil, errors.New("invalid checksum")
+	}
+	b = b[diskCacheHeaderLen:]
+	storedAt := time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+	b = b[8:]
+	keyLen, n := binary.Uvarint(b)
+	if n <= 0 || uint64(len(b)-n) < keyLen {
+		return "", time.Time{}, nil, errors.New("invalid key length")
+	}
+	b = b[n:]
+	return string(b[:keyLen]), storedAt, b[keyLen:], nil
+}
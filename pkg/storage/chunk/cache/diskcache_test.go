@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DiskCacheConfig{Enabled: true, Path: dir, MaxSizeMB: 1}
+	c, err := NewDiskCache("test", cfg, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 10 entries of ~0.1MB fill the cache.
+	value := make([]byte, 99*1000)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Store(ctx, []string{fmt.Sprint(i)}, [][]byte{value}))
+	}
+	require.Equal(t, 10.0, testutil.ToFloat64(c.entriesCurrent))
+	require.Equal(t, 10.0, testutil.ToFloat64(c.entriesAddedNew))
+
+	// Reading the first entry makes it the most recently used one.
+	found, _, _, err := c.Fetch(ctx, []string{"0"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"0"}, found)
+
+	require.NoError(t, c.Store(ctx, []string{"10"}, [][]byte{value}))
+	_, _, missing, err := c.Fetch(ctx, []string{"0", "1", "2", "10"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1"}, missing)
+	require.Equal(t, 1.0, testutil.ToFloat64(c.entriesEvicted.WithLabelValues(fullReason)))
+	require.LessOrEqual(t, testutil.ToFloat64(c.diskBytes), 1e6)
+
+	// Entries bigger than the cache are not stored.
+	require.NoError(t, c.Store(ctx, []string{"big"}, [][]byte{make([]byte, 2e6)}))
+	_, _, missing, err = c.Fetch(ctx, []string{"big"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"big"}, missing)
+	require.Equal(t, 1.0, testutil.ToFloat64(c.entriesEvicted.WithLabelValues(tooBigReason)))
+
+	// Replacing an entry doesn't change the number of entries.
+	require.NoError(t, c.Store(ctx, []string{"10"}, [][]byte{[]byte("new value")}))
+	_, bufs, _, err := c.Fetch(ctx, []string{"10"})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("new value")}, bufs)
+	require.Equal(t, 10.0, testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheSurvivesRestarts(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DiskCacheConfig{Enabled: true, Path: dir, MaxSizeMB: 1}
+	c, err := NewDiskCache("test", cfg, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	value := make([]byte, 99*1000)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Store(ctx, []string{fmt.Sprint(i)}, [][]byte{value}))
+		// make sure the modification times of the entries differ.
+		now := time.Now().Add(time.Duration(i-10) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, fileName(fmt.Sprint(i))), now, now))
+	}
+	c.Stop()
+	// A leftover of an interrupted write is removed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "leftover"+diskCacheTmpSuffix), []byte("partial"), 0o640))
+
+	c, err = NewDiskCache("test", cfg, prometheus.NewRegistry(), log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	require.Equal(t, 10.0, testutil.ToFloat64(c.entriesCurrent))
+	require.NoFileExists(t, filepath.Join(dir, "leftover"+diskCacheTmpSuffix))
+
+	found, bufs, _, err := c.Fetch(ctx, []string{"5"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"5"}, found)
+	require.Equal(t, [][]byte{value}, bufs)
+
+	// The oldest entry is evicted first.
+	require.NoError(t, c.Store(ctx, []string{"10"}, [][]byte{value}))
+	_, _, missing, err := c.Fetch(ctx, []string{"0", "1", "5", "10"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"0"}, missing)
+}
+
+func TestDiskCacheCorruptedEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache("test", DiskCacheConfig{Enabled: true, Path: dir, MaxSizeMB: 1}, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, c.Store(ctx, []string{"foo", "bar"}, [][]byte{[]byte("foo value"), []byte("bar value")}))
+
+	path := filepath.Join(dir, fileName("foo"))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	b[len(b)-1]++
+	require.NoError(t, os.WriteFile(path, b, 0o640))
+
+	found, bufs, missing, err := c.Fetch(ctx, []string{"foo", "bar"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar"}, found)
+	require.Equal(t, [][]byte{[]byte("bar value")}, bufs)
+	require.Equal(t, []string{"foo"}, missing)
+
+	require.NoFileExists(t, path)
+	require.Equal(t, 1.0, testutil.ToFloat64(c.entriesEvicted.WithLabelValues(corruptedReason)))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheTTL(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DiskCacheConfig{Enabled: true, Path: dir, MaxSizeMB: 1, TTL: time.Hour}
+	c, err := NewDiskCache("test", cfg, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, c.Store(ctx, []string{"fresh", "stale", "unused"}, [][]byte{[]byte("fresh value"), []byte("stale value"), []byte("unused value")}))
+	c.Stop()
+
+	// An entry stored before its TTL, but recently read.
+	stale := filepath.Join(dir, fileName("stale"))
+	require.NoError(t, os.WriteFile(stale, encodeDiskCacheEntry("stale", time.Now().Add(-2*time.Hour), []byte("stale value")), 0o640))
+	// An entry not used since before its TTL is purged when the cache is loaded.
+	unused := filepath.Join(dir, fileName("unused"))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(unused, old, old))
+
+	c, err = NewDiskCache("test", cfg, prometheus.NewRegistry(), log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	defer c.Stop()
+	require.NoFileExists(t, unused)
+	require.Equal(t, 2.0, testutil.ToFloat64(c.entriesCurrent))
+
+	// The entry stored before its TTL is expired when read.
+	found, bufs, missing, err := c.Fetch(ctx, []string{"fresh", "stale"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"fresh"}, found)
+	require.Equal(t, [][]byte{[]byte("fresh value")}, bufs)
+	require.Equal(t, []string{"stale"}, missing)
+	require.NoFileExists(t, stale)
+	require.Equal(t, 2.0, testutil.ToFloat64(c.entriesEvicted.WithLabelValues(expiredReason)))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.entriesCurrent))
+}
+
+func TestDiskCacheConfigValidate(t *testing.T) {
+	cfg := Config{DiskCache: DiskCacheConfig{Enabled: true, Path: t.TempDir(), MaxSizeMB: 1}}
+	require.NoError(t, cfg.Validate())
+
+	cfg.DiskCache.TTL = -time.Minute
+	require.Error(t, cfg.Validate())
+
+	cfg.DiskCache = DiskCacheConfig{Enabled: true}
+	require.Error(t, cfg.Validate())
+}
+
+func TestDiskCacheTiers(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c, err := New(Config{
+		Prefix:        "chunks.",
+		EmbeddedCache: EmbeddedCacheConfig{Enabled: true, MaxSizeMB: 1},
+		DiskCache:     DiskCacheConfig{Enabled: true, Path: t.TempDir(), MaxSizeMB: 1},
+		Background:    BackgroundConfig{WriteBackGoroutines: 1, WriteBackBuffer: 10},
+	}, reg, log.NewNopLogger(), "test", "loki")
+	require.NoError(t, err)
+	defer c.Stop()
+	require.Len(t, c.(*instrumentedCache).Cache.(tiered), 2)
+
+	_, err = New(Config{DiskCache: DiskCacheConfig{Enabled: true}}, prometheus.NewRegistry(), log.NewNopLogger(), "test", "loki")
+	require.Error(t, err)
+}
@@ -37,7 +37,7 @@ func (cfg *Config) Validate() error {
 		return errors.New("no cache configured")
 	}
 
-	return nil
+	return cfg.CacheConfig.Validate()
 }
 
 type Limits interface {
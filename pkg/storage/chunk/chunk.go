@@ -46,6 +46,11 @@ type Chunk struct {
 	Encoding Encoding `json:"encoding"`
 	Data     Data     `json:"-"`
 
+	// IndexedStructuredMetadata holds the distinct values of the structured metadata keys
+	// recorded in the index for the chunk, by key. It is only set by the ingesters when
+	// flushing the chunks, and a key without values still records that the chunk has none.
+	IndexedStructuredMetadata map[string][]string `json:"-"`
+
 	// The encoded version of the chunk, held so we don't need to re-encode it
 	encoded []byte
 }
@@ -81,13 +86,39 @@ func NewChunk(userID string, fp model.Fingerprint, metric labels.Labels, c Data,
 //
 // v12+, fingerprint is now a prefix to support better read and write request parallelization:
 // `<user>/<fprint>/<start>:<end>:<checksum>`
+//
+// v12+ chunks with a storage class are prefixed with it:
+// `<user>/<storage class>/<fprint>/<start>:<end>:<checksum>`
 func ParseExternalKey(userID, externalKey string) (Chunk, error) {
-	if strings.Count(externalKey, "/") == 2 { // v12+
+	switch strings.Count(externalKey, "/") {
+	case 2: // v12+
 		return parseNewerExternalKey(userID, externalKey)
+	case 3: // v12+ with a storage class
+		return parseStorageClassExternalKey(userID, externalKey)
 	}
 	return parseNewExternalKey(userID, externalKey)
 }
 
+// v12+ with a storage class
+func parseStorageClassExternalKey(userID, key string) (Chunk, error) {
+	userIdx := strings.Index(key, "/")
+	if userIdx == -1 || userIdx+1 >= len(key) {
+		return Chunk{}, errInvalidChunkID(key)
+	}
+	classIdx := strings.Index(key[userIdx+1:], "/")
+	if classIdx <= 0 {
+		return Chunk{}, errors.Wrap(errInvalidChunkID(key), "decoding storage class")
+	}
+	storageClass := key[userIdx+1 : userIdx+1+classIdx]
+
+	c, err := parseNewerExternalKey(userID, key[:userIdx+1]+key[userIdx+classIdx+2:])
+	if err != nil {
+		return Chunk{}, err
+	}
+	c.StorageClass = storageClass
+	return c, nil
+}
+
 // post-checksum
 func parseNewExternalKey(userID, key string) (Chunk, error) {
 	userIdx := strings.Index(key, "/")
@@ -361,5 +392,6 @@ func (c *Chunk) Decode(decodeContext *DecodeContext, input []byte) error {
 
 func equalByKey(a, b Chunk) bool {
 	return a.UserID == b.UserID && a.Fingerprint == b.Fingerprint &&
-		a.From == b.From && a.Through == b.Through && a.Checksum == b.Checksum
+		a.From == b.From && a.Through == b.Through && a.Checksum == b.Checksum &&
+		a.StorageClass == b.StorageClass
 }
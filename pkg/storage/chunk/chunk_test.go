@@ -99,6 +99,13 @@ func TestChunkCodec(t *testing.T) {
 			err:   ErrWrongMetadata,
 			f:     func(c *Chunk, _ []byte) { c.UserID = "foo" },
 		},
+
+		// Metadata test should fail
+		{
+			chunk: dummy,
+			err:   ErrWrongMetadata,
+			f:     func(c *Chunk, _ []byte) { c.StorageClass = "cold" },
+		},
 	} {
 		t.Run(fmt.Sprintf("[%d]", i), func(t *testing.T) {
 			err := c.chunk.Encode()
@@ -151,7 +158,19 @@ func TestParseExternalKey(t *testing.T) {
 			},
 		}},
 
+		{key: userID + "/cold/2/270d8f00:270d8f00:f84c5745", chunk: Chunk{
+			ChunkRef: logproto.ChunkRef{
+				UserID:       userID,
+				Fingerprint:  uint64(2),
+				From:         model.Time(655200000),
+				Through:      model.Time(655200000),
+				Checksum:     4165752645,
+				StorageClass: "cold",
+			},
+		}},
+
 		{key: "invalidUserID/2:270d8f00:270d8f00:f84c5745", chunk: Chunk{}, err: ErrWrongMetadata},
+		{key: "invalidUserID/cold/2/270d8f00:270d8f00:f84c5745", chunk: Chunk{}, err: ErrWrongMetadata},
 	} {
 		chunk, err := ParseExternalKey(userID, c.key)
 		require.Equal(t, c.err, errors.Cause(err))
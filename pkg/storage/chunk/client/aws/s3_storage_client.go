@@ -38,11 +38,18 @@ import (
 
 const (
 	SignatureVersionV4 = "v4"
+
+	// S3 Object Lock retention modes, which protect the objects from being overwritten or deleted until their retention date.
+	// https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-lock.html
+	ObjectLockModeGovernance = s3.ObjectLockModeGovernance
+	ObjectLockModeCompliance = s3.ObjectLockModeCompliance
 )
 
 var (
 	supportedSignatureVersions     = []string{SignatureVersionV4}
+	supportedObjectLockModes       = []string{ObjectLockModeGovernance, ObjectLockModeCompliance}
 	errUnsupportedSignatureVersion = errors.New("unsupported signature version")
+	errInvalidObjectLockPeriod     = errors.New("object lock retention period must be greater than 0 when an object lock mode is set")
 )
 
 var s3RequestDuration = instrument.NewHistogramCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -76,11 +83,38 @@ type S3Config struct {
 	SignatureVersion string              `yaml:"signature_version"`
 	StorageClass     string              `yaml:"storage_class"`
 	SSEConfig        bucket_s3.SSEConfig `yaml:"sse"`
+	ObjectLock       ObjectLockConfig    `yaml:"object_lock" doc:"description=Configures the S3 Object Lock retention of the objects written. The bucket must have Object Lock enabled."`
 	BackoffConfig    backoff.Config      `yaml:"backoff_config" doc:"description=Configures back off when S3 get Object."`
 
 	Inject InjectRequestMiddleware `yaml:"-"`
 }
 
+// ObjectLockConfig stores the S3 Object Lock retention applied to the objects written.
+type ObjectLockConfig struct {
+	Mode            string        `yaml:"mode"`
+	RetentionPeriod time.Duration `yaml:"retention_period"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *ObjectLockConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Mode, prefix+"mode", "", fmt.Sprintf("The S3 Object Lock retention mode of the objects written. Supported values are: %s. Object Lock is disabled when empty.", strings.Join(supportedObjectLockModes, ", ")))
+	f.DurationVar(&cfg.RetentionPeriod, prefix+"retention-period", 0, "The period after their upload during which the objects cannot be overwritten or deleted. Required when an Object Lock mode is set.")
+}
+
+// Validate config and returns error on failure
+func (cfg *ObjectLockConfig) Validate() error {
+	if cfg.Mode == "" {
+		return nil
+	}
+	if !util.StringsContain(supportedObjectLockModes, cfg.Mode) {
+		return fmt.Errorf("unsupported S3 object lock mode: %s. Supported values: %s", cfg.Mode, strings.Join(supportedObjectLockModes, ", "))
+	}
+	if cfg.RetentionPeriod <= 0 {
+		return errInvalidObjectLockPeriod
+	}
+	return nil
+}
+
 // HTTPConfig stores the http.Transport configuration
 type HTTPConfig struct {
 	Timeout               time.Duration `yaml:"timeout"`
@@ -110,6 +144,7 @@ func (cfg *S3Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.BoolVar(&cfg.Insecure, prefix+"s3.insecure", false, "Disable https on s3 connection.")
 
 	cfg.SSEConfig.RegisterFlagsWithPrefix(prefix+"s3.sse.", f)
+	cfg.ObjectLock.RegisterFlagsWithPrefix(prefix+"s3.object-lock.", f)
 
 	f.DurationVar(&cfg.HTTPConfig.IdleConnTimeout, prefix+"s3.http.idle-conn-timeout", 90*time.Second, "The maximum amount of time an idle connection will be held open.")
 	f.DurationVar(&cfg.HTTPConfig.Timeout, prefix+"s3.http.timeout", 0, "Timeout specifies a time limit for requests made by s3 Client.")
@@ -130,6 +165,10 @@ func (cfg *S3Config) Validate() error {
 		return errUnsupportedSignatureVersion
 	}
 
+	if err := cfg.ObjectLock.Validate(); err != nil {
+		return err
+	}
+
 	return storageawscommon.ValidateStorageClass(cfg.StorageClass)
 }
 
@@ -396,6 +435,12 @@ func (a *S3ObjectClient) PutObject(ctx context.Context, objectKey string, object
 			putObjectInput.SSEKMSEncryptionContext = a.sseConfig.KMSEncryptionContext
 		}
 
+		// S3 requires the Content-MD5 of the objects written with a retention, which the SDK computes for the seekable bodies.
+		if a.cfg.ObjectLock.Mode != "" {
+			putObjectInput.ObjectLockMode = aws.String(a.cfg.ObjectLock.Mode)
+			putObjectInput.ObjectLockRetainUntilDate = aws.Time(time.Now().Add(a.cfg.ObjectLock.RetentionPeriod))
+		}
+
 		_, err := a.S3.PutObjectWithContext(ctx, putObjectInput)
 		return err
 	})
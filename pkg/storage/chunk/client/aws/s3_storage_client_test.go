@@ -21,6 +21,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hedging"
+	storageawscommon "github.com/grafana/loki/v3/pkg/storage/common/aws"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -220,3 +221,69 @@ func TestCommonPrefixes(t *testing.T) {
 	require.Equal(t, nil, err)
 	require.Equal(t, 1, len(CommonPrefixes))
 }
+
+func TestPutObjectStorageClassAndObjectLock(t *testing.T) {
+	var headers http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+	}))
+	defer ts.Close()
+
+	cfg := S3Config{
+		Endpoint:         ts.URL,
+		BucketNames:      "buck-o",
+		S3ForcePathStyle: true,
+		Insecure:         true,
+		AccessKeyID:      "key",
+		SecretAccessKey:  flagext.SecretWithValue("secret"),
+		StorageClass:     storageawscommon.StorageClassStandardInfrequentAccess,
+	}
+
+	for _, tc := range []struct {
+		name       string
+		objectLock ObjectLockConfig
+	}{
+		{name: "without object lock"},
+		{name: "with object lock", objectLock: ObjectLockConfig{Mode: ObjectLockModeCompliance, RetentionPeriod: 24 * time.Hour}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.ObjectLock = tc.objectLock
+			client, err := NewS3ObjectClient(cfg, hedging.Config{})
+			require.NoError(t, err)
+
+			start := time.Now()
+			require.NoError(t, client.PutObject(context.Background(), "key", strings.NewReader("object")))
+			require.Equal(t, storageawscommon.StorageClassStandardInfrequentAccess, headers.Get("X-Amz-Storage-Class"))
+			require.Equal(t, "qM/eYzG9WesqyW+JEcS2Zg==", headers.Get("Content-Md5"))
+
+			if tc.objectLock.Mode == "" {
+				require.Empty(t, headers.Get("X-Amz-Object-Lock-Mode"))
+				require.Empty(t, headers.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+				return
+			}
+			require.Equal(t, ObjectLockModeCompliance, headers.Get("X-Amz-Object-Lock-Mode"))
+			retainUntil, err := time.Parse(time.RFC3339, headers.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+			require.NoError(t, err)
+			require.WithinRange(t, retainUntil, start.Add(24*time.Hour).Truncate(time.Second), time.Now().Add(24*time.Hour))
+		})
+	}
+}
+
+func TestObjectLockConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		cfg         ObjectLockConfig
+		expectedErr string
+	}{
+		{cfg: ObjectLockConfig{}},
+		{cfg: ObjectLockConfig{Mode: ObjectLockModeGovernance, RetentionPeriod: time.Hour}},
+		{cfg: ObjectLockConfig{Mode: "FOREVER", RetentionPeriod: time.Hour}, expectedErr: "unsupported S3 object lock mode: FOREVER"},
+		{cfg: ObjectLockConfig{Mode: ObjectLockModeCompliance}, expectedErr: errInvalidObjectLockPeriod.Error()},
+	} {
+		err := tc.cfg.Validate()
+		if tc.expectedErr == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorContains(t, err, tc.expectedErr)
+	}
+}
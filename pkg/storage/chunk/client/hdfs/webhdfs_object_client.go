@@ -0,0 +1,346 @@
+package hdfs
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/instrument"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	webHDFSPrefix = "/webhdfs/v1"
+
+	fileNotFoundException = "FileNotFoundException"
+	fileTypeDirectory     = "DIRECTORY"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+var hdfsRequestDuration = instrument.NewHistogramCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: constants.Loki,
+	Name:      "hdfs_request_duration_seconds",
+	Help:      "Time spent doing HDFS requests.",
+	Buckets:   prometheus.ExponentialBuckets(0.005, 4, 6),
+}, []string{"operation", "status_code"}))
+
+func init() {
+	hdfsRequestDuration.Register()
+}
+
+// Config for the HDFS object client, which uses the WebHDFS REST API of the NameNode.
+type Config struct {
+	Endpoint  string        `yaml:"endpoint"`
+	User      string        `yaml:"user"`
+	Directory string        `yaml:"directory"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("", f)
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Endpoint, prefix+"hdfs.endpoint", "", "URL of the WebHDFS REST API of the HDFS NameNode, for example http://namenode:9870.")
+	f.StringVar(&cfg.User, prefix+"hdfs.user", "", "Name of the HDFS user performing the requests, with the simple authentication of WebHDFS.")
+	f.StringVar(&cfg.Directory, prefix+"hdfs.directory", "/loki", "HDFS directory to store the objects in.")
+	f.DurationVar(&cfg.Timeout, prefix+"hdfs.timeout", 0, "Timeout of the requests to HDFS, including the reading of the response body. 0 means no timeout.")
+}
+
+// Validate config and returns error on failure
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return errors.Wrap(err, "invalid HDFS endpoint")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid HDFS endpoint %s: the scheme must be http or https", cfg.Endpoint)
+	}
+	return nil
+}
+
+// RemoteException is the error returned by the WebHDFS REST API.
+type RemoteException struct {
+	StatusCode    int    `json:"-"`
+	Exception     string `json:"exception"`
+	JavaClassName string `json:"javaClassName"`
+	Message       string `json:"message"`
+}
+
+func (e *RemoteException) Error() string {
+	return fmt.Sprintf("HDFS %s (status %d): %s", e.Exception, e.StatusCode, e.Message)
+}
+
+type fileStatus struct {
+	PathSuffix       string `json:"pathSuffix"`
+	Type             string `json:"type"`
+	Length           int64  `json:"length"`
+	ModificationTime int64  `json:"modificationTime"`
+	ChildrenNum      int    `json:"childrenNum"`
+}
+
+// ObjectClient stores the objects as files of a HDFS directory, through the WebHDFS REST API.
+type ObjectClient struct {
+	cfg      Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewObjectClient makes a new HDFS backed ObjectClient.
+func NewObjectClient(cfg Config) (*ObjectClient, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("the HDFS endpoint is required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid HDFS endpoint")
+	}
+
+	return &ObjectClient{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// the NameNode redirects the writes to a DataNode, which must receive the content of the file.
+			CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+				if req.Method == http.MethodPut {
+					return http.ErrUseLastResponse
+				}
+				return nil
+			},
+		},
+	}, nil
+}
+
+// url returns the WebHDFS URL of an operation on the file of the object key.
+func (c *ObjectClient) url(objectKey, op string, params url.Values) string {
+	u := *c.endpoint
+	u.Path = path.Join(u.Path, webHDFSPrefix, "/", c.cfg.Directory, objectKey)
+
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("op", op)
+	if c.cfg.User != "" {
+		query.Set("user.name", c.cfg.User)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// do sends the request and returns the response when its status is the expected one, or the remote exception otherwise.
+func (c *ObjectClient) do(ctx context.Context, method, rawURL string, body io.Reader, size int64, expectedStatus int) (*http.Response, error) {
+	if body != nil && size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == expectedStatus {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var remoteErr struct {
+		RemoteException *RemoteException `json:"RemoteException"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&remoteErr); err != nil || remoteErr.RemoteException == nil {
+		return nil, &RemoteException{StatusCode: resp.StatusCode, Message: fmt.Sprintf("unexpected response status for %s %s", method, req.URL.Path)}
+	}
+	remoteErr.RemoteException.StatusCode = resp.StatusCode
+	return nil, remoteErr.RemoteException
+}
+
+// doJSON sends the request and decodes the JSON response into v.
+func (c *ObjectClient) doJSON(ctx context.Context, method, rawURL string, v interface{}) error {
+	resp, err := c.do(ctx, method, rawURL, nil, 0, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *ObjectClient) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	err := instrument.CollectedRequest(ctx, "HDFS.ObjectExists", hdfsRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var status struct {
+			FileStatus fileStatus `json:"FileStatus"`
+		}
+		return c.doJSON(ctx, http.MethodGet, c.url(objectKey, "GETFILESTATUS", nil), &status)
+	})
+	if err != nil {
+		if c.IsObjectNotFoundErr(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PutObject creates the file of the object, overwriting any previous version. The NameNode redirects the creation
+// to the DataNode receiving the content.
+func (c *ObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	return instrument.CollectedRequest(ctx, "HDFS.PutObject", hdfsRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		resp, err := c.do(ctx, http.MethodPut, c.url(objectKey, "CREATE", url.Values{"overwrite": {"true"}}), nil, 0, http.StatusTemporaryRedirect)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		location, err := resp.Location()
+		if err != nil {
+			return errors.Wrapf(err, "failed to create HDFS file of object %s: invalid DataNode location", objectKey)
+		}
+
+		size, err := object.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := object.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		resp, err = c.do(ctx, http.MethodPut, location.String(), object, size, http.StatusCreated)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+}
+
+// GetObject reads the file of the object, from the DataNode the NameNode redirects to.
+func (c *ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	var resp *http.Response
+	err := instrument.CollectedRequest(ctx, "HDFS.GetObject", hdfsRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var err error
+		resp, err = c.do(ctx, http.MethodGet, c.url(objectKey, "OPEN", nil), nil, 0, http.StatusOK)
+		return err
+	})
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to get HDFS object [ %s ]", objectKey)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// List objects with the given prefix, which is a directory of HDFS or a file, as the filesystem object client does.
+func (c *ObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
+	if delimiter != "" && delimiter != "/" {
+		return nil, nil, fmt.Errorf("unsupported delimiter: %q", delimiter)
+	}
+
+	var storageObjects []client.StorageObject
+	var commonPrefixes []client.StorageCommonPrefix
+
+	err := instrument.CollectedRequest(ctx, "HDFS.List", hdfsRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		dir := strings.TrimSuffix(prefix, "/")
+		dirs := []string{dir}
+		for len(dirs) > 0 {
+			dir, dirs = dirs[0], dirs[1:]
+
+			var statuses struct {
+				FileStatuses struct {
+					FileStatus []fileStatus `json:"FileStatus"`
+				} `json:"FileStatuses"`
+			}
+			if err := c.doJSON(ctx, http.MethodGet, c.url(dir, "LISTSTATUS", nil), &statuses); err != nil {
+				if c.IsObjectNotFoundErr(err) {
+					continue
+				}
+				return err
+			}
+
+			for _, status := range statuses.FileStatuses.FileStatus {
+				// the status of a file has no path suffix when listing the file itself.
+				key := dir
+				if status.PathSuffix != "" {
+					key = path.Join(dir, status.PathSuffix)
+				}
+
+				if status.Type != fileTypeDirectory {
+					storageObjects = append(storageObjects, client.StorageObject{Key: key, ModifiedAt: time.UnixMilli(status.ModificationTime)})
+					continue
+				}
+				if delimiter == "" {
+					dirs = append(dirs, key)
+					continue
+				}
+				if status.ChildrenNum > 0 {
+					commonPrefixes = append(commonPrefixes, client.StorageCommonPrefix(key+delimiter))
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return storageObjects, commonPrefixes, nil
+}
+
+func (c *ObjectClient) DeleteObject(ctx context.Context, objectKey string) error {
+	return instrument.CollectedRequest(ctx, "HDFS.DeleteObject", hdfsRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var deleted struct {
+			Boolean bool `json:"boolean"`
+		}
+		if err := c.doJSON(ctx, http.MethodDelete, c.url(objectKey, "DELETE", nil), &deleted); err != nil {
+			return err
+		}
+		// HDFS reports the deletion of a missing file as unsuccessful instead of an error.
+		if !deleted.Boolean {
+			return errors.Wrapf(errObjectNotFound, "failed to delete HDFS object [ %s ]", objectKey)
+		}
+		return nil
+	})
+}
+
+func (c *ObjectClient) IsObjectNotFoundErr(err error) bool {
+	if errors.Is(err, errObjectNotFound) {
+		return true
+	}
+	var remoteErr *RemoteException
+	return errors.As(err, &remoteErr) && remoteErr.Exception == fileNotFoundException
+}
+
+// IsRetryableErr returns true for the errors of the NameNode and DataNodes.
+func (c *ObjectClient) IsRetryableErr(err error) bool {
+	var remoteErr *RemoteException
+	return errors.As(err, &remoteErr) && remoteErr.StatusCode >= http.StatusInternalServerError
+}
+
+func (c *ObjectClient) Stop() {
+	c.client.CloseIdleConnections()
+}
+
+// KeyEncoder implements client.KeyEncodingObjectClient, as HDFS does not allow the colons of the chunk keys in file names.
+func (c *ObjectClient) KeyEncoder() client.KeyEncoder {
+	return client.FSEncoder
+}
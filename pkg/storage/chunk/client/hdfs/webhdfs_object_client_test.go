@@ -0,0 +1,216 @@
+package hdfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+// fakeWebHDFS is a stand-in of the WebHDFS REST API of a NameNode, which redirects the writes and reads to a DataNode
+// served by the same server.
+type fakeWebHDFS struct {
+	t    *testing.T
+	user string
+
+	mtx   sync.Mutex
+	files map[string][]byte
+}
+
+func newFakeWebHDFS(t *testing.T, user string) *httptest.Server {
+	f := &fakeWebHDFS{t: t, user: user, files: map[string][]byte{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(webHDFSPrefix+"/", f.serveNameNode)
+	mux.HandleFunc("/datanode/", f.serveDataNode)
+	return httptest.NewServer(mux)
+}
+
+func (f *fakeWebHDFS) remoteException(w http.ResponseWriter, status int, exception, message string) {
+	w.WriteHeader(status)
+	require.NoError(f.t, json.NewEncoder(w).Encode(map[string]RemoteException{"RemoteException": {Exception: exception, Message: message}}))
+}
+
+// statuses returns the status of the file at the path, or of the children of the directory at the path.
+func (f *fakeWebHDFS) statuses(p string) ([]fileStatus, bool) {
+	if _, ok := f.files[p]; ok {
+		return []fileStatus{{Type: "FILE", Length: int64(len(f.files[p])), ModificationTime: 1000}}, true
+	}
+
+	children := map[string]fileStatus{}
+	for name, data := range f.files {
+		if !strings.HasPrefix(name, p+"/") {
+			continue
+		}
+		child, _, isDir := strings.Cut(strings.TrimPrefix(name, p+"/"), "/")
+		status := children[child]
+		status.PathSuffix = child
+		if isDir {
+			status.Type = fileTypeDirectory
+			status.ChildrenNum++
+		} else {
+			status.Type = "FILE"
+			status.Length = int64(len(data))
+			status.ModificationTime = 1000
+		}
+		children[child] = status
+	}
+	if len(children) == 0 {
+		return nil, false
+	}
+
+	statuses := make([]fileStatus, 0, len(children))
+	for _, status := range children {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].PathSuffix < statuses[j].PathSuffix })
+	return statuses, true
+}
+
+func (f *fakeWebHDFS) serveNameNode(w http.ResponseWriter, r *http.Request) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if r.URL.Query().Get("user.name") != f.user {
+		f.remoteException(w, http.StatusForbidden, "SecurityException", "unexpected user")
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, webHDFSPrefix)
+	switch op := r.URL.Query().Get("op"); op {
+	case "CREATE":
+		require.Equal(f.t, http.MethodPut, r.Method)
+		require.Equal(f.t, "true", r.URL.Query().Get("overwrite"))
+		http.Redirect(w, r, "/datanode"+p, http.StatusTemporaryRedirect)
+	case "OPEN":
+		if _, ok := f.files[p]; !ok {
+			f.remoteException(w, http.StatusNotFound, fileNotFoundException, "File does not exist: "+p)
+			return
+		}
+		http.Redirect(w, r, "/datanode"+p, http.StatusTemporaryRedirect)
+	case "GETFILESTATUS", "LISTSTATUS":
+		statuses, ok := f.statuses(p)
+		if !ok {
+			f.remoteException(w, http.StatusNotFound, fileNotFoundException, "File does not exist: "+p)
+			return
+		}
+		if op == "GETFILESTATUS" {
+			require.NoError(f.t, json.NewEncoder(w).Encode(map[string]interface{}{"FileStatus": statuses[0]}))
+			return
+		}
+		require.NoError(f.t, json.NewEncoder(w).Encode(map[string]interface{}{"FileStatuses": map[string]interface{}{"FileStatus": statuses}}))
+	case "DELETE":
+		_, ok := f.files[p]
+		delete(f.files, p)
+		require.NoError(f.t, json.NewEncoder(w).Encode(map[string]bool{"boolean": ok}))
+	default:
+		f.remoteException(w, http.StatusBadRequest, "IllegalArgumentException", "unsupported operation "+op)
+	}
+}
+
+func (f *fakeWebHDFS) serveDataNode(w http.ResponseWriter, r *http.Request) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	p := strings.TrimPrefix(r.URL.Path, "/datanode")
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		require.NoError(f.t, err)
+		require.Equal(f.t, int64(len(data)), r.ContentLength)
+		f.files[p] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		_, _ = w.Write(f.files[p])
+	}
+}
+
+func TestObjectClient(t *testing.T) {
+	ts := newFakeWebHDFS(t, "loki")
+	defer ts.Close()
+
+	c, err := NewObjectClient(Config{Endpoint: ts.URL, User: "loki", Directory: "/loki"})
+	require.NoError(t, err)
+	defer c.Stop()
+	ctx := context.Background()
+
+	for _, key := range []string{"index/table_1/file-1", "index/table_1/file-2", "index/table_2/file-1", "chunks/fake/chunk", "empty"} {
+		data := []byte(key)
+		if key == "empty" {
+			data = nil
+		}
+		require.NoError(t, c.PutObject(ctx, key, bytes.NewReader(data)))
+	}
+	// the objects are overwritten.
+	require.NoError(t, c.PutObject(ctx, "chunks/fake/chunk", strings.NewReader("new content")))
+
+	exists, err := c.ObjectExists(ctx, "chunks/fake/chunk")
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = c.ObjectExists(ctx, "chunks/fake/missing")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	r, size, err := c.GetObject(ctx, "chunks/fake/chunk")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "new content", string(data))
+	require.Equal(t, int64(len(data)), size)
+
+	_, _, err = c.GetObject(ctx, "chunks/fake/missing")
+	require.Error(t, err)
+	require.True(t, c.IsObjectNotFoundErr(err))
+	require.False(t, c.IsRetryableErr(err))
+
+	for _, tc := range []struct {
+		prefix, delimiter string
+		expectedObjects   []string
+		expectedPrefixes  []client.StorageCommonPrefix
+	}{
+		{prefix: "", delimiter: "/", expectedObjects: []string{"empty"}, expectedPrefixes: []client.StorageCommonPrefix{"chunks/", "index/"}},
+		{prefix: "index/", delimiter: "/", expectedPrefixes: []client.StorageCommonPrefix{"index/table_1/", "index/table_2/"}},
+		{prefix: "index/", delimiter: "", expectedObjects: []string{"index/table_1/file-1", "index/table_1/file-2", "index/table_2/file-1"}},
+		{prefix: "index/table_2/file-1", delimiter: "/", expectedObjects: []string{"index/table_2/file-1"}},
+		{prefix: "missing/", delimiter: "/"},
+	} {
+		t.Run(fmt.Sprintf("list %q with delimiter %q", tc.prefix, tc.delimiter), func(t *testing.T) {
+			objects, prefixes, err := c.List(ctx, tc.prefix, tc.delimiter)
+			require.NoError(t, err)
+
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+				require.Equal(t, time.UnixMilli(1000), object.ModifiedAt)
+			}
+			require.Equal(t, tc.expectedObjects, keys)
+			require.Equal(t, tc.expectedPrefixes, prefixes)
+		})
+	}
+
+	require.NoError(t, c.DeleteObject(ctx, "index/table_2/file-1"))
+	err = c.DeleteObject(ctx, "index/table_2/file-1")
+	require.True(t, c.IsObjectNotFoundErr(err))
+	_, prefixes, err := c.List(ctx, "index/", "/")
+	require.NoError(t, err)
+	require.Equal(t, []client.StorageCommonPrefix{"index/table_1/"}, prefixes)
+
+	// the requests of another user are rejected.
+	c, err = NewObjectClient(Config{Endpoint: ts.URL, User: "other", Directory: "/loki"})
+	require.NoError(t, err)
+	_, err = c.ObjectExists(ctx, "chunks/fake/chunk")
+	require.ErrorContains(t, err, "SecurityException")
+	require.False(t, c.IsObjectNotFoundErr(err))
+}
@@ -65,6 +65,11 @@ func NewFSObjectClient(cfg FSConfig) (*FSObjectClient, error) {
 // Stop implements ObjectClient
 func (FSObjectClient) Stop() {}
 
+// KeyEncoder implements client.KeyEncodingObjectClient
+func (*FSObjectClient) KeyEncoder() client.KeyEncoder {
+	return client.FSEncoder
+}
+
 func (f *FSObjectClient) ObjectExists(_ context.Context, objectKey string) (bool, error) {
 	fullPath := filepath.Join(f.cfg.Directory, filepath.FromSlash(objectKey))
 	_, err := os.Lstat(fullPath)
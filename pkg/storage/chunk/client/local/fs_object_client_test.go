@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 )
 
@@ -203,3 +205,13 @@ func TestFSObjectClient_DeleteObject(t *testing.T) {
 	require.Len(t, commonPrefixes, 0)
 	require.Len(t, files, len(foldersWithFiles["folder2/"]))*/
 }
+
+func TestFSObjectClient_KeyEncoder(t *testing.T) {
+	fsObjectClient, err := NewFSObjectClient(FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	// the chunk keys are encoded, including when the objects are stored under a prefix.
+	require.NotNil(t, client.KeyEncoderFor(fsObjectClient))
+	require.NotNil(t, client.KeyEncoderFor(client.NewPrefixedObjectClient(fsObjectClient, "prefix/")))
+	require.Nil(t, client.KeyEncoderFor(testutils.NewInMemoryObjectClient()))
+}
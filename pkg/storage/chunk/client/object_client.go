@@ -76,6 +76,24 @@ var FSEncoder = func(schema config.SchemaConfig, chk chunk.Chunk) string {
 	return base64Encoder(key)
 }
 
+// KeyEncodingObjectClient is implemented by the object clients which store the objects as files,
+// whose names can't contain the colons of the chunk keys.
+type KeyEncodingObjectClient interface {
+	ObjectClient
+	KeyEncoder() KeyEncoder
+}
+
+// KeyEncoderFor returns the encoder of the chunk keys stored with the object client, or nil if they are stored as is.
+func KeyEncoderFor(objectClient ObjectClient) KeyEncoder {
+	if prefixed, ok := objectClient.(PrefixedObjectClient); ok {
+		objectClient = prefixed.GetDownstream()
+	}
+	if encoding, ok := objectClient.(KeyEncodingObjectClient); ok {
+		return encoding.KeyEncoder()
+	}
+	return nil
+}
+
 const defaultMaxParallel = 150
 
 // client is used to store chunks in object store backends
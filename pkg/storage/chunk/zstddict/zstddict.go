@@ -0,0 +1,254 @@
+// Package zstddict stores the zstd dictionaries the chunks of the tenants are compressed with.
+//
+// Dictionaries are immutable and identified by the id written in the zstd frames of the
+// chunks compressed with them, so they are kept as long as chunks may reference them.
+// The objects are laid out as follows:
+//
+//	<prefix>zstd_dictionaries/<id>                        the dictionary
+//	<prefix>zstd_dictionaries/tenants/<tenant>/<version>  the id of a version of the dictionary of the tenant
+//
+// Versions are the creation time of the dictionaries, the latest version being the one to
+// compress new chunks with.
+package zstddict
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	dictionariesDir = "zstd_dictionaries/"
+	tenantsDir      = dictionariesDir + "tenants/"
+
+	// Dictionary ids below 32768 and above 2^31 are reserved by the zstd format.
+	minID = 1 << 15
+	maxID = 1 << 31
+)
+
+type Config struct {
+	Store           string        `yaml:"store"`
+	StoreKeyPrefix  string        `yaml:"store_key_prefix"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Store, prefix+"store", "", "Experimental: Store holding the zstd dictionaries used by the zstd-dict chunk encoding. The dictionaries are trained by the compactor and fetched by the components reading and writing chunks. Dictionaries are disabled when empty.")
+	f.StringVar(&cfg.StoreKeyPrefix, prefix+"store.key-prefix", "index/", "Path prefix for storing the zstd dictionaries.")
+	f.DurationVar(&cfg.RefreshInterval, prefix+"refresh-interval", 5*time.Minute, "Interval at which to look for new versions of the zstd dictionaries of the tenants.")
+}
+
+// Validate verifies the config does not contain inappropriate values
+func (cfg *Config) Validate() error {
+	if cfg.Store == "" {
+		return nil
+	}
+	if cfg.RefreshInterval <= 0 {
+		return errors.New("the refresh interval must be positive")
+	}
+	return config.ValidatePathPrefix(cfg.StoreKeyPrefix)
+}
+
+// DictionaryID returns the id of the dictionary of a tenant created at the given time.
+func DictionaryID(tenant string, version time.Time) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tenant))
+	_, _ = h.Write([]byte(strconv.FormatInt(version.UnixNano(), 10)))
+	return minID + h.Sum32()%(maxID-minID)
+}
+
+// Store reads and writes the dictionaries from the object store.
+type Store struct {
+	client client.ObjectClient
+	prefix string
+}
+
+func NewStore(objectClient client.ObjectClient, prefix string) *Store {
+	return &Store{client: objectClient, prefix: prefix}
+}
+
+func (s *Store) dictionaryKey(id uint32) string {
+	return s.prefix + dictionariesDir + strconv.FormatUint(uint64(id), 10)
+}
+
+func (s *Store) tenantDir(tenant string) string {
+	return s.prefix + tenantsDir + tenant + "/"
+}
+
+// PutDictionary stores a new version of the dictionary of a tenant. The id of the dictionary must
+// come from DictionaryID.
+func (s *Store) PutDictionary(ctx context.Context, tenant string, version time.Time, dict []byte) error {
+	d, err := zstd.InspectDictionary(dict)
+	if err != nil {
+		return errors.Wrap(err, "invalid zstd dictionary")
+	}
+	if d.ID() != DictionaryID(tenant, version) {
+		return fmt.Errorf("unexpected id %d for the dictionary of tenant %s created at %s", d.ID(), tenant, version)
+	}
+
+	exists, err := s.client.ObjectExists(ctx, s.dictionaryKey(d.ID()))
+	if err != nil && !s.client.IsObjectNotFoundErr(err) {
+		return err
+	}
+	if exists {
+		// Chunks may already be compressed with the other dictionary.
+		return fmt.Errorf("a zstd dictionary with id %d already exists", d.ID())
+	}
+
+	if err := s.client.PutObject(ctx, s.dictionaryKey(d.ID()), bytes.NewReader(dict)); err != nil {
+		return err
+	}
+	versionKey := s.tenantDir(tenant) + fmt.Sprintf("%020d", version.UnixNano())
+	return s.client.PutObject(ctx, versionKey, strings.NewReader(strconv.FormatUint(uint64(d.ID()), 10)))
+}
+
+// GetDictionary returns the dictionary with the given id.
+func (s *Store) GetDictionary(ctx context.Context, id uint32) ([]byte, error) {
+	return s.get(ctx, s.dictionaryKey(id))
+}
+
+// LatestDictionaries returns the id of the latest dictionary of each tenant.
+func (s *Store) LatestDictionaries(ctx context.Context) (map[string]uint32, error) {
+	_, tenants, err := s.client.List(ctx, s.prefix+tenantsDir, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uint32, len(tenants))
+	for _, t := range tenants {
+		tenant := path.Base(string(t))
+		versions, _, err := s.client.List(ctx, s.tenantDir(tenant), "/")
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Key < versions[j].Key })
+
+		b, err := s.get(ctx, versions[len(versions)-1].Key)
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.ParseUint(string(b), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid zstd dictionary id for tenant %s", tenant)
+		}
+		ids[tenant] = uint32(id)
+	}
+	return ids, nil
+}
+
+func (s *Store) get(ctx context.Context, key string) ([]byte, error) {
+	r, _, err := s.client.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// Client makes the dictionaries of the store available to the EncZstdDict chunk encoding.
+// It fetches the dictionaries of the chunks being read and refreshes the latest dictionary
+// of each tenant, used to compress new chunks.
+type Client struct {
+	services.Service
+
+	cfg    Config
+	store  *Store
+	pool   *chunkenc.ZstdDictPool
+	logger log.Logger
+
+	fetchedDictionaries prometheus.Counter
+	refreshFailures     prometheus.Counter
+}
+
+func NewClient(cfg Config, store *Store, pool *chunkenc.ZstdDictPool, r prometheus.Registerer, logger log.Logger) *Client {
+	c := &Client{
+		cfg:    cfg,
+		store:  store,
+		pool:   pool,
+		logger: logger,
+		fetchedDictionaries: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "zstd_dictionaries_fetched_total",
+			Help:      "Total number of zstd dictionaries fetched from the store.",
+		}),
+		refreshFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "zstd_dictionaries_refresh_failures_total",
+			Help:      "Total number of failures to refresh the zstd dictionaries of the tenants.",
+		}),
+	}
+	pool.SetFetcher(c)
+	c.Service = services.NewTimerService(cfg.RefreshInterval, c.iteration, c.iteration, nil)
+	return c
+}
+
+// FetchZstdDictionary implements chunkenc.ZstdDictionaryFetcher.
+func (c *Client) FetchZstdDictionary(id uint32) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	b, err := c.store.GetDictionary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.fetchedDictionaries.Inc()
+	return b, nil
+}
+
+func (c *Client) iteration(ctx context.Context) error {
+	if err := c.refresh(ctx); err != nil {
+		c.refreshFailures.Inc()
+		level.Error(c.logger).Log("msg", "failed to refresh zstd dictionaries", "err", err)
+	}
+	return nil
+}
+
+// refresh registers the latest dictionary of each tenant to compress its new chunks with.
+func (c *Client) refresh(ctx context.Context) error {
+	latest, err := c.store.LatestDictionaries(ctx)
+	if err != nil {
+		return err
+	}
+
+	for tenant, id := range latest {
+		if c.pool.TenantDictionary(tenant) == id {
+			continue
+		}
+		dict, err := c.store.GetDictionary(ctx, id)
+		if err != nil {
+			return errors.Wrapf(err, "fetching zstd dictionary %d of tenant %s", id, tenant)
+		}
+		c.fetchedDictionaries.Inc()
+		if _, err := c.pool.Register(dict); err != nil {
+			return errors.Wrapf(err, "registering zstd dictionary %d of tenant %s", id, tenant)
+		}
+		c.pool.SetTenantDictionary(tenant, id)
+		level.Info(c.logger).Log("msg", "using new zstd dictionary", "tenant", tenant, "id", id)
+	}
+	return nil
+}
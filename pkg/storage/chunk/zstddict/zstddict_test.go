@@ -0,0 +1,98 @@
+package zstddict
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/chunkenc/testdata"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+)
+
+func trainDictionary(t *testing.T, tenant string, version time.Time) []byte {
+	t.Helper()
+	var samples [][]byte
+	for i := 0; i+10 <= len(testdata.LogsBytes); i += 10 {
+		samples = append(samples, bytes.Join(testdata.LogsBytes[i:i+10], nil))
+	}
+	dict, err := chunkenc.TrainZstdDictionary(samples, DictionaryID(tenant, version), 4*1024)
+	require.NoError(t, err)
+	return dict
+}
+
+func TestDictionaryID(t *testing.T) {
+	now := time.Now()
+	for _, tenant := range []string{"", "a", "b", "fake"} {
+		id := DictionaryID(tenant, now)
+		require.GreaterOrEqual(t, id, uint32(minID))
+		require.Less(t, id, uint32(maxID))
+		require.Equal(t, id, DictionaryID(tenant, now))
+		require.NotEqual(t, id, DictionaryID(tenant, now.Add(time.Second)))
+	}
+}
+
+func TestStore(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	store := NewStore(objectClient, "index/")
+	ctx := context.Background()
+
+	latest, err := store.LatestDictionaries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, latest)
+
+	v1, v2 := time.Unix(100, 0), time.Unix(200, 0)
+	dictA1, dictA2, dictB := trainDictionary(t, "a", v1), trainDictionary(t, "a", v2), trainDictionary(t, "b", v1)
+	require.NoError(t, store.PutDictionary(ctx, "a", v2, dictA2))
+	require.NoError(t, store.PutDictionary(ctx, "a", v1, dictA1))
+	require.NoError(t, store.PutDictionary(ctx, "b", v1, dictB))
+
+	// The id must match the tenant and version, and can't be reused.
+	require.Error(t, store.PutDictionary(ctx, "b", v2, dictB))
+	require.Error(t, store.PutDictionary(ctx, "b", v1, dictB))
+
+	latest, err = store.LatestDictionaries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint32{"a": DictionaryID("a", v2), "b": DictionaryID("b", v1)}, latest)
+
+	// Older versions remain readable.
+	got, err := store.GetDictionary(ctx, DictionaryID("a", v1))
+	require.NoError(t, err)
+	require.Equal(t, dictA1, got)
+}
+
+func TestClient(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	store := NewStore(objectClient, "index/")
+	ctx := context.Background()
+
+	version := time.Now()
+	dict := trainDictionary(t, "fake", version)
+	require.NoError(t, store.PutDictionary(ctx, "fake", version, dict))
+	other := time.Unix(100, 0)
+	require.NoError(t, store.PutDictionary(ctx, "other", other, trainDictionary(t, "other", other)))
+
+	pool := &chunkenc.ZstdDictPool{}
+	client := NewClient(Config{RefreshInterval: time.Hour}, store, pool, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(ctx, client))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(ctx, client))
+	})
+
+	// The latest dictionaries of the tenants are registered at startup.
+	require.Equal(t, DictionaryID("fake", version), pool.TenantDictionary("fake"))
+	require.Equal(t, DictionaryID("other", other), pool.TenantDictionary("other"))
+	require.Zero(t, pool.TenantDictionary("unknown"))
+
+	got, err := client.FetchZstdDictionary(DictionaryID("fake", version))
+	require.NoError(t, err)
+	require.Equal(t, dict, got)
+}
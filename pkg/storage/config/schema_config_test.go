@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/grafana/loki/v3/pkg/chunkenc"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/storage/types"
@@ -485,6 +486,18 @@ func TestPeriodConfig_Validate(t *testing.T) {
 				ChunkTables: PeriodicTableConfig{Period: 0},
 			},
 		},
+		{
+			desc: "v14",
+			in: PeriodConfig{
+				Schema:    "v14",
+				RowShards: 16,
+				IndexTables: IndexPeriodicTableConfig{
+					PathPrefix:          "index/",
+					PeriodicTableConfig: PeriodicTableConfig{Period: 0},
+				},
+				ChunkTables: PeriodicTableConfig{Period: 0},
+			},
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			if tc.err == "" {
@@ -672,6 +685,27 @@ func TestVersionAsInt(t *testing.T) {
 	}
 }
 
+func TestPeriodConfig_ChunkFormat(t *testing.T) {
+	for _, tc := range []struct {
+		schema      string
+		chunkFormat byte
+		headFormat  chunkenc.HeadBlockFmt
+	}{
+		{schema: "v11", chunkFormat: chunkenc.ChunkFormatV3, headFormat: chunkenc.UnorderedHeadBlockFmt},
+		{schema: "v12", chunkFormat: chunkenc.ChunkFormatV3, headFormat: chunkenc.UnorderedHeadBlockFmt},
+		{schema: "v13", chunkFormat: chunkenc.ChunkFormatV4, headFormat: chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt},
+		{schema: "v14", chunkFormat: chunkenc.ChunkFormatV5, headFormat: chunkenc.UnorderedWithStructuredMetadataColumnsHeadBlockFmt},
+	} {
+		t.Run(tc.schema, func(t *testing.T) {
+			cfg := PeriodConfig{Schema: tc.schema}
+			chunkFormat, headFormat, err := cfg.ChunkFormat()
+			require.NoError(t, err)
+			require.Equal(t, tc.chunkFormat, chunkFormat)
+			require.Equal(t, tc.headFormat, headFormat)
+		})
+	}
+}
+
 func TestUnmarshalPeriodConfig(t *testing.T) {
 	input := `
 from: "2020-07-31"
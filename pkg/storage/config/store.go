@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -43,5 +44,14 @@ func (cfg *ChunkStoreConfig) RegisterFlags(f *flag.FlagSet) {
 }
 
 func (cfg *ChunkStoreConfig) Validate() error {
+	if err := cfg.ChunkCacheConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid chunk cache config: %w", err)
+	}
+	if err := cfg.ChunkCacheConfigL2.Validate(); err != nil {
+		return fmt.Errorf("invalid L2 chunk cache config: %w", err)
+	}
+	if err := cfg.WriteDedupeCacheConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid write dedupe cache config: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,28 @@
+package detected
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func Test_DetermineType(t *testing.T) {
+	for value, expected := range map[string]logproto.DetectedFieldType{
+		"42":    logproto.DetectedFieldInt,
+		"4.2":   logproto.DetectedFieldFloat,
+		"true":  logproto.DetectedFieldBoolean,
+		"1m30s": logproto.DetectedFieldDuration,
+		"12MB":  logproto.DetectedFieldBytes,
+		"foo":   logproto.DetectedFieldString,
+	} {
+		require.Equal(t, expected, DetermineType(value), value)
+	}
+}
+
+func Test_ParseLine(t *testing.T) {
+	require.Equal(t, map[string][]string{"foo": {"bar"}, "n": {"1"}}, ParseLine(`foo=bar n=1`))
+	require.Equal(t, map[string][]string{"foo": {"bar"}, "nested_n": {"1"}}, ParseLine(`{"foo":"bar","nested":{"n":1}}`))
+	require.Empty(t, ParseLine(`not a structured line`))
+}
@@ -25,11 +25,13 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/congestion"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/gcp"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/grpc"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hdfs"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hedging"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/ibmcloud"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/openstack"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/zstddict"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
@@ -69,6 +71,7 @@ type StoreLimits interface {
 	stores.StoreLimits
 	indexgateway.Limits
 	CardinalityLimit(string) int
+	IndexStructuredMetadataKeys(string) []string
 }
 
 // Storage configs defined as Named stores don't get any defaults as they do not
@@ -158,6 +161,18 @@ func (cfg *NamedCOSConfig) UnmarshalYAML(unmarshal func(interface{}) error) erro
 	return unmarshal((*ibmcloud.COSConfig)(cfg))
 }
 
+type NamedHDFSConfig hdfs.Config
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (cfg *NamedHDFSConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*hdfs.Config)(cfg))
+	return unmarshal((*hdfs.Config)(cfg))
+}
+
+func (cfg *NamedHDFSConfig) Validate() error {
+	return (*hdfs.Config)(cfg).Validate()
+}
+
 // NamedStores helps configure additional object stores from a given storage provider
 type NamedStores struct {
 	AWS          map[string]NamedAWSStorageConfig  `yaml:"aws"`
@@ -168,6 +183,7 @@ type NamedStores struct {
 	AlibabaCloud map[string]NamedOssConfig         `yaml:"alibabacloud"`
 	Swift        map[string]NamedSwiftConfig       `yaml:"swift"`
 	COS          map[string]NamedCOSConfig         `yaml:"cos"`
+	HDFS         map[string]NamedHDFSConfig        `yaml:"hdfs"`
 
 	// contains mapping from named store reference name to store type
 	storeType map[string]string `yaml:"-"`
@@ -181,7 +197,7 @@ func (ns *NamedStores) populateStoreType() error {
 		case types.StorageTypeAWS, types.StorageTypeAWSDynamo, types.StorageTypeS3,
 			types.StorageTypeGCP, types.StorageTypeGCPColumnKey, types.StorageTypeBigTable, types.StorageTypeBigTableHashed, types.StorageTypeGCS,
 			types.StorageTypeAzure, types.StorageTypeBOS, types.StorageTypeSwift, types.StorageTypeCassandra,
-			types.StorageTypeFileSystem, types.StorageTypeInMemory, types.StorageTypeGrpc:
+			types.StorageTypeFileSystem, types.StorageTypeHDFS, types.StorageTypeInMemory, types.StorageTypeGrpc:
 			return fmt.Errorf("named store %q should not match with the name of a predefined storage type", name)
 		}
 
@@ -239,6 +255,13 @@ func (ns *NamedStores) populateStoreType() error {
 		ns.storeType[name] = types.StorageTypeSwift
 	}
 
+	for name := range ns.HDFS {
+		if err := checkForDuplicates(name); err != nil {
+			return err
+		}
+		ns.storeType[name] = types.StorageTypeHDFS
+	}
+
 	return nil
 }
 
@@ -261,6 +284,12 @@ func (ns *NamedStores) Validate() error {
 		}
 	}
 
+	for name, hdfsCfg := range ns.HDFS {
+		if err := hdfsCfg.Validate(); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("invalid HDFS Storage config with name %s", name))
+		}
+	}
+
 	return ns.populateStoreType()
 }
 
@@ -281,6 +310,7 @@ type Config struct {
 	BoltDBConfig           local.BoltDBConfig        `yaml:"boltdb" doc:"description=Deprecated: Configures storing index in BoltDB. Required fields only required when boltdb is present in the configuration."`
 	FSConfig               local.FSConfig            `yaml:"filesystem" doc:"description=Configures storing the chunks on the local file system. Required fields only required when filesystem is present in the configuration."`
 	Swift                  openstack.SwiftConfig     `yaml:"swift"`
+	HDFSConfig             hdfs.Config               `yaml:"hdfs" doc:"description=Configures storing the chunks and index in HDFS, through the WebHDFS REST API of the NameNode. Required fields only required when hdfs is present in the configuration."`
 	GrpcConfig             grpc.Config               `yaml:"grpc_store" doc:"deprecated"`
 	Hedging                hedging.Config            `yaml:"hedging"`
 	NamedStores            NamedStores               `yaml:"named_stores"`
@@ -297,6 +327,7 @@ type Config struct {
 	BoltDBShipperConfig boltdb.IndexCfg           `yaml:"boltdb_shipper" doc:"description=Configures storing index in an Object Store (GCS/S3/Azure/Swift/COS/Filesystem) in the form of boltdb files. Required fields only required when boltdb-shipper is defined in config."`
 	TSDBShipperConfig   indexshipper.Config       `yaml:"tsdb_shipper" doc:"description=Configures storing index in an Object Store (GCS/S3/Azure/Swift/COS/Filesystem) in a prometheus TSDB-like format. Required fields only required when TSDB is defined in config."`
 	BloomShipperConfig  bloomshipperconfig.Config `yaml:"bloom_shipper" category:"experimental" doc:"description=Experimental: Configures the bloom shipper component, which contains the store abstraction to fetch bloom filters from and put them to object storage."`
+	ZstdDictionaries    zstddict.Config           `yaml:"zstd_dictionaries" category:"experimental" doc:"description=Experimental: Configures the store of the zstd dictionaries used by the zstd-dict chunk encoding."`
 
 	// Config for using AsyncStore when using async index stores like `boltdb-shipper`.
 	// It is required for getting chunk ids of recently flushed chunks from the ingesters.
@@ -316,6 +347,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.BoltDBConfig.RegisterFlags(f)
 	cfg.FSConfig.RegisterFlags(f)
 	cfg.Swift.RegisterFlags(f)
+	cfg.HDFSConfig.RegisterFlags(f)
 	cfg.GrpcConfig.RegisterFlags(f)
 	cfg.Hedging.RegisterFlagsWithPrefix("store.", f)
 	cfg.CongestionControl.RegisterFlagsWithPrefix("store.", f)
@@ -329,6 +361,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxChunkBatchSize, "store.max-chunk-batch-size", 50, "The maximum number of chunks to fetch per batch.")
 	cfg.TSDBShipperConfig.RegisterFlagsWithPrefix("tsdb.", f)
 	cfg.BloomShipperConfig.RegisterFlagsWithPrefix("bloom.", f)
+	cfg.ZstdDictionaries.RegisterFlagsWithPrefix("store.zstd-dictionaries.", f)
 }
 
 // Validate config and returns error on failure
@@ -348,6 +381,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.AWSStorageConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid AWS Storage config")
 	}
+	if err := cfg.HDFSConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid HDFS Storage config")
+	}
 	if err := cfg.BoltDBShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid boltdb-shipper config")
 	}
@@ -357,6 +393,12 @@ func (cfg *Config) Validate() error {
 	if err := cfg.BloomShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid bloom shipper config")
 	}
+	if err := cfg.ZstdDictionaries.Validate(); err != nil {
+		return errors.Wrap(err, "invalid zstd dictionaries config")
+	}
+	if err := cfg.IndexQueriesCacheConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid index queries cache config")
+	}
 
 	return cfg.NamedStores.Validate()
 }
@@ -476,7 +518,8 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, cc c
 
 	case util.StringsContain(types.SupportedStorageTypes, storeType):
 		switch storeType {
-		case types.StorageTypeFileSystem:
+		// HDFS does not allow the colons of the chunk keys in the file names, which are encoded like on the local file system.
+		case types.StorageTypeFileSystem, types.StorageTypeHDFS:
 			c, err := NewObjectClient(name, cfg, clientMetrics)
 			if err != nil {
 				return nil, err
@@ -724,7 +767,19 @@ func internalNewObjectClient(name string, cfg Config, clientMetrics ClientMetric
 		}
 		return ibmcloud.NewCOSObjectClient(cosCfg, cfg.Hedging)
 
+	case types.StorageTypeHDFS:
+		hdfsCfg := cfg.HDFSConfig
+		if namedStore != "" {
+			nsCfg, ok := cfg.NamedStores.HDFS[namedStore]
+			if !ok {
+				return nil, fmt.Errorf("Unrecognized named hdfs storage config %s", name)
+			}
+
+			hdfsCfg = (hdfs.Config)(nsCfg)
+		}
+		return hdfs.NewObjectClient(hdfsCfg)
+
 	default:
-		return nil, fmt.Errorf("Unrecognized storage client %v, choose one of: %v, %v, %v, %v, %v, %v, %v, %v, %v", name, types.StorageTypeAWS, types.StorageTypeS3, types.StorageTypeGCS, types.StorageTypeAzure, types.StorageTypeAlibabaCloud, types.StorageTypeSwift, types.StorageTypeBOS, types.StorageTypeCOS, types.StorageTypeFileSystem)
+		return nil, fmt.Errorf("Unrecognized storage client %v, choose one of: %v, %v, %v, %v, %v, %v, %v, %v, %v, %v", name, types.StorageTypeAWS, types.StorageTypeS3, types.StorageTypeGCS, types.StorageTypeAzure, types.StorageTypeAlibabaCloud, types.StorageTypeSwift, types.StorageTypeBOS, types.StorageTypeCOS, types.StorageTypeHDFS, types.StorageTypeFileSystem)
 	}
 }
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
@@ -13,8 +15,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/aws"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/cassandra"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hdfs"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	storageawscommon "github.com/grafana/loki/v3/pkg/storage/common/aws"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/boltdb"
@@ -229,6 +234,54 @@ func TestNamedStores_populateStoreType(t *testing.T) {
 	})
 }
 
+func TestNamedStores_perPeriodObjectStores(t *testing.T) {
+	hdfsServer := httptest.NewServer(http.NotFoundHandler())
+	defer hdfsServer.Close()
+
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+
+	var infrequentAccess, compliance NamedAWSStorageConfig
+	flagext.DefaultValues((*aws.StorageConfig)(&infrequentAccess))
+	flagext.DefaultValues((*aws.StorageConfig)(&compliance))
+	infrequentAccess.S3Config.StorageClass = storageawscommon.StorageClassStandardInfrequentAccess
+	compliance.S3Config.ObjectLock = aws.ObjectLockConfig{Mode: aws.ObjectLockModeCompliance, RetentionPeriod: 24 * time.Hour}
+
+	var hdfsCfg NamedHDFSConfig
+	flagext.DefaultValues((*hdfs.Config)(&hdfsCfg))
+	hdfsCfg.Endpoint = hdfsServer.URL
+
+	cfg.NamedStores = NamedStores{
+		AWS: map[string]NamedAWSStorageConfig{
+			"infrequent-access": infrequentAccess,
+			"compliance":        compliance,
+		},
+		HDFS: map[string]NamedHDFSConfig{
+			"hadoop": hdfsCfg,
+		},
+	}
+	require.NoError(t, cfg.NamedStores.Validate())
+
+	for name, expected := range map[string]string{"infrequent-access": types.StorageTypeAWS, "compliance": types.StorageTypeAWS, "hadoop": types.StorageTypeHDFS} {
+		storeType, ok := cfg.NamedStores.storeType[name]
+		require.True(t, ok)
+		require.Equal(t, expected, storeType)
+	}
+
+	objectClient, err := NewObjectClient("hadoop", cfg, cm)
+	require.NoError(t, err)
+	require.IsType(t, &hdfs.ObjectClient{}, objectClient)
+
+	// the object lock of a named store requires a retention period.
+	compliance.S3Config.ObjectLock.RetentionPeriod = 0
+	cfg.NamedStores.AWS["compliance"] = compliance
+	require.ErrorContains(t, cfg.NamedStores.Validate(), "invalid AWS Storage config with name compliance")
+
+	hdfsCfg.Endpoint = "hdfs://namenode:8020"
+	cfg.NamedStores = NamedStores{HDFS: map[string]NamedHDFSConfig{"hadoop": hdfsCfg}}
+	require.ErrorContains(t, cfg.NamedStores.Validate(), "invalid HDFS Storage config with name hadoop")
+}
+
 func TestNewObjectClient_prefixing(t *testing.T) {
 	t.Run("no prefix", func(t *testing.T) {
 		var cfg Config
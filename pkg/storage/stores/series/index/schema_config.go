@@ -54,7 +54,8 @@ func CreateSchema(cfg config.PeriodConfig) (SeriesStoreSchema, error) {
 			return newSeriesStoreSchema(buckets, v11Entries{v10}), nil
 		case "v12":
 			return newSeriesStoreSchema(buckets, v12Entries{v11Entries{v10}}), nil
-		case "v13":
+		case "v13", "v14":
+			// v14 only changes the chunk format.
 			return newSeriesStoreSchema(buckets, v13Entries{v12Entries{v11Entries{v10}}}), nil
 		}
 	}
@@ -4,6 +4,7 @@ package config
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"time"
 
 	"github.com/grafana/dskit/flagext"
@@ -41,6 +42,9 @@ func (c *Config) Validate() error {
 	if len(c.WorkingDirectory) == 0 {
 		return errors.New("at least one working directory must be specified")
 	}
+	if err := c.MetasCache.Validate(); err != nil {
+		return fmt.Errorf("invalid metas cache config: %w", err)
+	}
 	return nil
 }
 
@@ -125,3 +125,36 @@ func (f *fakeIdentifier) Path() string {
 func fakeIdentifierPathForBounds(path string, from, through model.Time) string {
 	return filepath.Join(path, fmt.Sprintf("%d-%d-*.tsdb", from, through))
 }
+
+func Test_syncStructuredMetadataSeries(t *testing.T) {
+	builder := NewBuilder(index.FormatV3)
+	chunks := buildChunkMetas(0, 2)
+
+	kept := mustParseLabels(`{foo="bar"}`)
+	builder.AddSeries(kept, model.Fingerprint(kept.Hash()), chunks)
+	for _, ls := range structuredMetadataSeries(kept, map[string][]string{"level": {"error", "info"}}) {
+		builder.AddSeries(ls, model.Fingerprint(kept.Hash()), chunks[:2])
+	}
+	removed := mustParseLabels(`{foo="baz"}`)
+	for _, ls := range structuredMetadataSeries(removed, map[string][]string{"level": {"error"}}) {
+		builder.AddSeries(ls, model.Fingerprint(removed.Hash()), chunks)
+	}
+	builder.FinalizeChunks()
+
+	// drop the first chunk of the stream, and its only chunk with the info level.
+	_, err := builder.DropChunk(kept.String(), chunks[0])
+	require.NoError(t, err)
+	builder.streams[`{__loki_structured_metadata__="level=info", foo="bar"}`].chunks = chunks[:1]
+
+	builder.syncStructuredMetadataSeries()
+
+	res := map[string]index.ChunkMetas{}
+	for id, s := range builder.streams {
+		res[id] = s.chunks
+	}
+	require.Equal(t, map[string]index.ChunkMetas{
+		`{foo="bar"}`: chunks[1:],
+		`{__loki_structured_metadata__="level", foo="bar"}`:       chunks[1:2],
+		`{__loki_structured_metadata__="level=error", foo="bar"}`: chunks[1:2],
+	}, res)
+}
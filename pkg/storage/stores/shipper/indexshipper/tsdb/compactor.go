@@ -66,6 +66,50 @@ func (i indexProcessor) OpenCompactedIndexFile(ctx context.Context, path, tableN
 	return newCompactedIndex(ctx, tableName, userID, workingDir, periodConfig, builder), nil
 }
 
+// The sizes of the chunks are not in the indexes the schema periods are migrated from, so the chunks
+// are assumed to be half full, like by the tsdb-map tool.
+const (
+	migratedChunkKB      = ((3 << 20) / 4) / 1024
+	migratedChunkEntries = 10000
+)
+
+// NewIndexBuilder returns an empty index of a user in a table, which the chunks of an index of another type are added to
+// for migrating a schema period to TSDB.
+func (i indexProcessor) NewIndexBuilder(ctx context.Context, tableName, userID, workingDir string, periodConfig config.PeriodConfig) (compactor.IndexBuilder, error) {
+	indexFormat, err := periodConfig.TSDBFormat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &migratedIndex{
+		compactedIndex: newCompactedIndex(ctx, tableName, userID, workingDir, periodConfig, NewBuilder(indexFormat)),
+	}, nil
+}
+
+type migratedIndex struct {
+	*compactedIndex
+}
+
+// AddChunk adds a chunk to the series of its labels, with the fingerprint of its chunk ID.
+func (m *migratedIndex) AddChunk(entry retention.ChunkEntry) error {
+	chk, err := chunk.ParseExternalKey(m.userID, string(entry.ChunkID))
+	if err != nil {
+		return err
+	}
+
+	// TSDB doesnt need the __name__="log" convention the old chunk store index used.
+	b := labels.NewBuilder(entry.Labels)
+	b.Del(labels.MetricName)
+	m.builder.AddSeries(withStorageClassLabel(b.Labels(), chk.StorageClass), model.Fingerprint(chk.Fingerprint), []tsdbindex.ChunkMeta{{
+		Checksum: chk.Checksum,
+		MinTime:  int64(chk.From),
+		MaxTime:  int64(chk.Through),
+		KB:       migratedChunkKB,
+		Entries:  migratedChunkEntries,
+	}})
+	return nil
+}
+
 type tableCompactor struct {
 	commonIndexSet          compactor.IndexSet
 	existingUserIndexSet    map[string]compactor.IndexSet
@@ -302,7 +346,12 @@ func (c *compactedIndex) ForEachChunk(ctx context.Context, callback retention.Ch
 		UserID: c.userID,
 	}
 	for seriesID, stream := range c.builder.streams {
+		// the structured metadata series share the chunks of their stream and are synced with it while building the index.
+		if stream.labels.Has(StructuredMetadataLabel) {
+			continue
+		}
 		logprotoChunkRef.Fingerprint = uint64(stream.fp)
+		logprotoChunkRef.StorageClass = stream.labels.Get(StorageClassLabel)
 		chunkEntry.SeriesID = getUnsafeBytes(seriesID)
 		chunkEntry.Labels = withoutTenantLabel(stream.labels)
 
@@ -376,7 +425,7 @@ func (c *compactedIndex) ToIndexFile() (shipperindex.Index, error) {
 		// TSDB doesnt need the __name__="log" convention the old chunk store index used.
 		b := labels.NewBuilder(chk.Metric)
 		b.Del(labels.MetricName)
-		ls := b.Labels()
+		ls := withStorageClassLabel(b.Labels(), chk.StorageClass)
 
 		approxKB := math.Round(float64(chk.Data.UncompressedSize()) / float64(1<<10))
 		err := c.builder.InsertChunk(ls.String(), tsdbindex.ChunkMeta{
@@ -391,6 +440,7 @@ func (c *compactedIndex) ToIndexFile() (shipperindex.Index, error) {
 		}
 	}
 	c.indexChunks = nil
+	c.builder.syncStructuredMetadataSeries()
 
 	id, err := c.builder.Build(c.ctx, c.workingDir, func(from, through model.Time, checksum uint32) Identifier {
 		id := SingleTenantTSDBIdentifier{
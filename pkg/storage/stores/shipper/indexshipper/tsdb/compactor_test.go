@@ -836,6 +836,54 @@ func TestCompactedIndex(t *testing.T) {
 
 }
 
+func TestMigratedIndex(t *testing.T) {
+	now := model.Now()
+	periodConfig := config.PeriodConfig{
+		IndexTables: config.IndexPeriodicTableConfig{
+			PeriodicTableConfig: config.PeriodicTableConfig{Period: config.ObjectStorageIndexRequiredPeriod}},
+		Schema: "v12",
+	}
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{periodConfig}}
+	tableName := periodConfig.IndexTables.TableFor(now)
+	userID := buildUserID(0)
+
+	builder, err := indexProcessor{}.NewIndexBuilder(context.Background(), tableName, userID, t.TempDir(), periodConfig)
+	require.NoError(t, err)
+
+	lbls := labels.FromStrings("foo", "bar")
+	var expected []string
+	for i, fp := range []uint64{1, 1, 2} {
+		ref := logproto.ChunkRef{UserID: userID, Fingerprint: fp, From: now.Add(time.Duration(i) * time.Minute), Through: now.Add(time.Duration(i+1) * time.Minute), Checksum: uint32(i)}
+		if fp == 2 {
+			ref.StorageClass = "cold"
+		}
+		chunkID := schemaCfg.ExternalKey(ref)
+		expected = append(expected, chunkID)
+
+		// the chunks of the boltdb-shipper index have the metric name label.
+		require.NoError(t, builder.AddChunk(retention.ChunkEntry{
+			ChunkRef: retention.ChunkRef{UserID: []byte(userID), ChunkID: []byte(chunkID), From: ref.From, Through: ref.Through},
+			Labels:   labels.NewBuilder(lbls).Set(labels.MetricName, "logs").Labels(),
+		}))
+	}
+
+	indexFile, err := builder.ToIndexFile()
+	require.NoError(t, err)
+	defer indexFile.Close()
+
+	compactedIndex, err := indexProcessor{}.OpenCompactedIndexFile(context.Background(), indexFile.Path(), tableName, userID, t.TempDir(), periodConfig, util_log.Logger)
+	require.NoError(t, err)
+
+	var found []string
+	err = compactedIndex.ForEachChunk(context.Background(), func(ce retention.ChunkEntry) (bool, error) {
+		require.Equal(t, lbls, labels.NewBuilder(ce.Labels).Del(StorageClassLabel).Labels())
+		found = append(found, string(ce.ChunkID))
+		return false, nil
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, found)
+}
+
 func TestIteratorContextCancelation(t *testing.T) {
 	tc := setupCompactedIndex(t)
 	compactedIndex := tc.buildCompactedIndex()
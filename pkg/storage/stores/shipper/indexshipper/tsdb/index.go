@@ -17,10 +17,11 @@ type Series struct {
 }
 
 type ChunkRef struct {
-	User        string
-	Fingerprint model.Fingerprint
-	Start, End  model.Time
-	Checksum    uint32
+	User         string
+	Fingerprint  model.Fingerprint
+	Start, End   model.Time
+	Checksum     uint32
+	StorageClass string
 }
 
 // Compares by (Fp, Start, End, checksum)
@@ -63,6 +63,7 @@ type VolumeAccumulator interface {
 
 type Limits interface {
 	VolumeMaxSeries(string) int
+	IndexStructuredMetadataKeys(string) []string
 }
 
 func NewIndexClient(idx Index, opts IndexClientOptions, l Limits) *IndexClient {
@@ -123,19 +124,28 @@ func (c *IndexClient) GetChunkRefs(ctx context.Context, userID string, from, thr
 	}
 
 	// TODO(owen-d): use a pool to reduce allocs here
-	chks, err := c.idx.GetChunkRefs(ctx, userID, from, through, nil, shard, matchers...)
+	chks, err := c.idx.GetChunkRefs(ctx, userID, from, through, nil, shard, withoutStructuredMetadataSeries(matchers)...)
 	if err != nil {
 		return nil, err
 	}
 
+	// prune the chunks with the structured metadata values recorded in the index
+	if metadataMatchers := StructuredMetadataMatchers(predicate.Plan().AST, c.limits.IndexStructuredMetadataKeys(userID)); len(metadataMatchers) > 0 {
+		chks, err = pruneChunkRefs(ctx, c.idx, userID, from, through, chks, shard, matchers, metadataMatchers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	refs := make([]logproto.ChunkRef, 0, len(chks))
 	for _, chk := range chks {
 		refs = append(refs, logproto.ChunkRef{
-			Fingerprint: uint64(chk.Fingerprint),
-			UserID:      chk.User,
-			From:        chk.Start,
-			Through:     chk.End,
-			Checksum:    chk.Checksum,
+			Fingerprint:  uint64(chk.Fingerprint),
+			UserID:       chk.User,
+			From:         chk.Start,
+			Through:      chk.End,
+			Checksum:     chk.Checksum,
+			StorageClass: chk.StorageClass,
 		})
 	}
 
@@ -148,7 +158,7 @@ func (c *IndexClient) GetSeries(ctx context.Context, userID string, from, throug
 		return nil, err
 	}
 
-	xs, err := c.idx.Series(ctx, userID, from, through, nil, shard, matchers...)
+	xs, err := c.idx.Series(ctx, userID, from, through, nil, shard, withoutStructuredMetadataSeries(matchers)...)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +176,7 @@ func (c *IndexClient) LabelValuesForMetricName(ctx context.Context, userID strin
 	if err != nil {
 		return nil, err
 	}
-	return c.idx.LabelValues(ctx, userID, from, through, labelName, matchers...)
+	return c.idx.LabelValues(ctx, userID, from, through, labelName, withoutStructuredMetadataSeries(matchers)...)
 }
 
 // tsdb no longer uses the __metric_name__="logs" hack, so we can ignore metric names!
@@ -179,6 +189,7 @@ func (c *IndexClient) Stats(ctx context.Context, userID string, from, through mo
 	if err != nil {
 		return nil, err
 	}
+	matchers = withoutStructuredMetadataSeries(matchers)
 
 	// split the query range to align with table intervals i.e. ObjectStorageIndexRequiredPeriod
 	// This is to avoid explicitly deduping chunks by leveraging the table intervals.
@@ -246,6 +257,7 @@ func (c *IndexClient) Volume(ctx context.Context, userID string, from, through m
 	if err != nil {
 		return nil, err
 	}
+	matchers = withoutStructuredMetadataSeries(matchers)
 
 	// Split by interval to query the index in parallel
 	var intervals []model.Interval
@@ -291,7 +303,7 @@ func (c *IndexClient) GetShards(ctx context.Context, userID string, from, throug
 		m[fp] = append(m[fp], chks...)
 		mtx.Unlock()
 		return false
-	}, predicate.Matchers...); err != nil {
+	}, withoutStructuredMetadataSeries(predicate.Matchers)...); err != nil {
 		return nil, err
 	}
 
@@ -345,5 +357,5 @@ func withoutNameLabel(matchers []*labels.Matcher) []*labels.Matcher {
 }
 
 func (c *IndexClient) HasForSeries(_, _ model.Time) (sharding.ForSeries, bool) {
-	return c.idx, true
+	return forSeriesWithoutStructuredMetadata{forSeries: c.idx}, true
 }
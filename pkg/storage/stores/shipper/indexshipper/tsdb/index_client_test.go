@@ -12,9 +12,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/storage/stores/index/seriesvolume"
 	shipperindex "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/index"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
 )
 
 type mockIndexShipperIndexIterator struct {
@@ -313,10 +317,94 @@ func TestIndexClient_Volume(t *testing.T) {
 	})
 }
 
+func TestIndexClient_StructuredMetadata(t *testing.T) {
+	ls := mustParseLabels(`{foo="bar"}`)
+	fp := model.Fingerprint(ls.Hash())
+	chunks := buildChunkMetas(0, 2)
+
+	b := NewBuilder(index.FormatV3)
+	b.AddSeries(ls, fp, chunks)
+	// the values of the first two chunks are recorded, the values of the last one aren't.
+	for i, values := range []map[string][]string{
+		{"level": {"error"}, "service_version": {}},
+		{"level": {"debug", "info"}, "service_version": {"1.0"}},
+	} {
+		for _, metadataLabels := range structuredMetadataSeries(ls, values) {
+			b.AddSeries(metadataLabels, fp, chunks[i:i+1])
+		}
+	}
+
+	dir := t.TempDir()
+	id, err := b.Build(context.Background(), dir, func(from, through model.Time, checksum uint32) Identifier {
+		return NewPrefixedIdentifier(SingleTenantTSDBIdentifier{TS: time.Now(), From: from, Through: through, Checksum: checksum}, dir, dir)
+	})
+	require.NoError(t, err)
+	idx, err := NewShippableTSDBFile(id)
+	require.NoError(t, err)
+
+	indexClient := NewIndexClient(idx, IndexClientOptions{}, &fakeLimits{indexStructuredMetadataKeys: []string{"level", "service_version"}})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		query     string
+		checksums []uint32
+	}{
+		{query: `{foo="bar"}`, checksums: []uint32{0, 1, 2}},
+		{query: `{foo="bar"} | level="error"`, checksums: []uint32{0, 2}},
+		{query: `{foo="bar"} |= "foo" | level=~"info|warn"`, checksums: []uint32{1, 2}},
+		{query: `{foo="bar"} | level="error" | service_version="1.0"`, checksums: []uint32{2}},
+		{query: `{foo="bar"} | level="error" or service_version="1.0"`, checksums: []uint32{0, 1, 2}},
+		{query: `{foo="bar"} | level=~"error|"`, checksums: []uint32{0, 1, 2}},
+		{query: `{foo="bar"} | level!="error"`, checksums: []uint32{0, 1, 2}},
+		{query: `{foo="bar"} | json | level="error"`, checksums: []uint32{0, 1, 2}},
+		{query: `{foo="bar"} | trace_id="1234"`, checksums: []uint32{0, 1, 2}},
+		{query: `sum(count_over_time({foo="bar"} | level="debug" [1m]))`, checksums: []uint32{1, 2}},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := syntax.ParseExpr(tc.query)
+			require.NoError(t, err)
+			matchers, err := syntax.ParseMatchers(`{foo="bar"}`, true)
+			require.NoError(t, err)
+
+			refs, err := indexClient.GetChunkRefs(ctx, "fake", 0, 10, chunk.NewPredicate(matchers, &plan.QueryPlan{AST: expr}))
+			require.NoError(t, err)
+			checksums := make([]uint32, 0, len(refs))
+			for _, ref := range refs {
+				require.Equal(t, uint64(fp), ref.Fingerprint)
+				checksums = append(checksums, ref.Checksum)
+			}
+			require.ElementsMatch(t, tc.checksums, checksums)
+		})
+	}
+
+	// the structured metadata series are hidden from the other queries.
+	series, err := indexClient.GetSeries(ctx, "fake", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []labels.Labels{ls}, series)
+
+	names, err := indexClient.LabelNamesForMetricName(ctx, "fake", 0, 10, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, names)
+
+	values, err := indexClient.LabelValuesForMetricName(ctx, "fake", 0, 10, "", StructuredMetadataLabel)
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	stats, err := indexClient.Stats(ctx, "fake", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), stats.Streams)
+	require.Equal(t, uint64(3), stats.Chunks)
+}
+
 type fakeLimits struct {
-	volumeMaxSeries int
+	volumeMaxSeries             int
+	indexStructuredMetadataKeys []string
 }
 
 func (f *fakeLimits) VolumeMaxSeries(_ string) int {
 	return f.volumeMaxSeries
 }
+
+func (f *fakeLimits) IndexStructuredMetadataKeys(_ string) []string {
+	return f.indexStructuredMetadataKeys
+}
@@ -219,14 +219,16 @@ func (i *TSDBIndex) GetChunkRefs(ctx context.Context, userID string, from, throu
 	res = res[:0]
 
 	if err := i.ForSeries(ctx, "", fpFilter, from, through, func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) (stop bool) {
+		storageClass := ls.Get(StorageClassLabel)
 		for _, chk := range chks {
 
 			res = append(res, ChunkRef{
-				User:        userID, // assumed to be the same, will be enforced by caller.
-				Fingerprint: fp,
-				Start:       chk.From(),
-				End:         chk.Through(),
-				Checksum:    chk.Checksum,
+				User:         userID, // assumed to be the same, will be enforced by caller.
+				Fingerprint:  fp,
+				Start:        chk.From(),
+				End:          chk.Through(),
+				Checksum:     chk.Checksum,
+				StorageClass: storageClass,
 			})
 		}
 		return false
@@ -243,12 +245,19 @@ func (i *TSDBIndex) Series(ctx context.Context, _ string, from, through model.Ti
 	}
 	res = res[:0]
 
+	// The series of a stream flushed with several storage classes share its fingerprint
+	// and only differ by the storage class label.
+	seen := make(map[model.Fingerprint]struct{})
 	if err := i.ForSeries(ctx, "", fpFilter, from, through, func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) (stop bool) {
 		if len(chks) == 0 {
 			return
 		}
+		if _, ok := seen[fp]; ok {
+			return
+		}
+		seen[fp] = struct{}{}
 		res = append(res, Series{
-			Labels:      ls.Copy(),
+			Labels:      withoutStorageClassLabel(ls.Copy()),
 			Fingerprint: fp,
 		})
 		return false
@@ -260,14 +269,33 @@ func (i *TSDBIndex) Series(ctx context.Context, _ string, from, through model.Ti
 }
 
 func (i *TSDBIndex) LabelNames(_ context.Context, _ string, _, _ model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	var (
+		res []string
+		err error
+	)
 	if len(matchers) == 0 {
-		return i.reader.LabelNames()
+		res, err = i.reader.LabelNames()
+	} else {
+		res, err = labelNamesWithMatchers(i.reader, matchers...)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return labelNamesWithMatchers(i.reader, matchers...)
+	// strip out the storage class and structured metadata labels if they exist
+	stripped := make([]string, 0, len(res))
+	for _, name := range res {
+		if name != StorageClassLabel && name != StructuredMetadataLabel {
+			stripped = append(stripped, name)
+		}
+	}
+	return stripped, nil
 }
 
 func (i *TSDBIndex) LabelValues(_ context.Context, _ string, _, _ model.Time, name string, matchers ...*labels.Matcher) ([]string, error) {
+	if name == StorageClassLabel || name == StructuredMetadataLabel {
+		return nil, nil
+	}
 	if len(matchers) == 0 {
 		return i.reader.LabelValues(name)
 	}
@@ -391,7 +419,7 @@ func (i *TSDBIndex) Volume(
 				if aggregateBySeries {
 					seriesLabels = seriesLabels[:0]
 					for _, l := range ls {
-						if _, ok := labelsToMatch[l.Name]; l.Name != TenantLabel && includeAll || ok {
+						if _, ok := labelsToMatch[l.Name]; l.Name != TenantLabel && l.Name != StorageClassLabel && includeAll || ok {
 							seriesLabels = append(seriesLabels, l)
 						}
 					}
@@ -401,11 +429,11 @@ func (i *TSDBIndex) Volume(
 					labelVolumes = make(map[string]uint64, len(ls))
 					for _, l := range ls {
 						if len(targetLabels) > 0 {
-							if _, ok := labelsToMatch[l.Name]; l.Name != TenantLabel && includeAll || ok {
+							if _, ok := labelsToMatch[l.Name]; l.Name != TenantLabel && l.Name != StorageClassLabel && includeAll || ok {
 								labelVolumes[l.Name] += stats.KB << 10
 							}
 						} else {
-							if l.Name != TenantLabel {
+							if l.Name != TenantLabel && l.Name != StorageClassLabel {
 								labelVolumes[l.Name] += stats.KB << 10
 							}
 						}
@@ -208,6 +208,70 @@ func TestSingleIdx(t *testing.T) {
 
 }
 
+func TestTSDBIndex_StorageClass(t *testing.T) {
+	ls := mustParseLabels(`{foo="bar"}`)
+	fp := model.Fingerprint(ls.Hash())
+	// The series of a stream with a storage class keeps the fingerprint of the stream.
+	head := NewHead("fake", NewMetrics(nil), log.NewNopLogger())
+	_, _ = head.Append(ls, ls.Hash(), index.ChunkMetas{{MinTime: 0, MaxTime: 3, Checksum: 0}})
+	_, _ = head.Append(withStorageClassLabel(ls, "cold"), ls.Hash(), index.ChunkMetas{{MinTime: 1, MaxTime: 4, Checksum: 1}})
+	tsdbIndex := NewTSDBIndex(head.Index())
+
+	refs, err := tsdbIndex.GetChunkRefs(context.Background(), "fake", 0, 5, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []ChunkRef{
+		{User: "fake", Fingerprint: fp, Start: 0, End: 3, Checksum: 0},
+		{User: "fake", Fingerprint: fp, Start: 1, End: 4, Checksum: 1, StorageClass: "cold"},
+	}, refs)
+
+	series, err := tsdbIndex.Series(context.Background(), "fake", 0, 5, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.NoError(t, err)
+	require.Equal(t, []Series{{Labels: ls, Fingerprint: fp}}, series)
+
+	names, err := tsdbIndex.LabelNames(context.Background(), "fake", 0, 5)
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, names)
+
+	values, err := tsdbIndex.LabelValues(context.Background(), "fake", 0, 5, StorageClassLabel)
+	require.NoError(t, err)
+	require.Empty(t, values)
+}
+
+func TestTSDBIndex_StorageClassChange(t *testing.T) {
+	// A stream flushed without a storage class, then to the cold and archive storage classes.
+	ls := mustParseLabels(`{foo="bar"}`)
+	fp := model.Fingerprint(ls.Hash())
+	other := mustParseLabels(`{foo="baz"}`)
+	b := NewBuilder(index.FormatV3)
+	b.AddSeries(ls, fp, index.ChunkMetas{{MinTime: 0, MaxTime: 3, Checksum: 0}})
+	b.AddSeries(withStorageClassLabel(ls, "cold"), fp, index.ChunkMetas{{MinTime: 4, MaxTime: 7, Checksum: 1}})
+	b.AddSeries(withStorageClassLabel(ls, "archive"), fp, index.ChunkMetas{{MinTime: 8, MaxTime: 11, Checksum: 2}})
+	b.AddSeries(other, model.Fingerprint(other.Hash()), index.ChunkMetas{{MinTime: 0, MaxTime: 11, Checksum: 3}})
+	dir := t.TempDir()
+	dst, err := b.Build(context.Background(), dir, func(from, through model.Time, checksum uint32) Identifier {
+		return NewPrefixedIdentifier(SingleTenantTSDBIdentifier{TS: time.Now(), From: from, Through: through, Checksum: checksum}, dir, dir)
+	})
+	require.NoError(t, err)
+	idx, err := NewShippableTSDBFile(dst)
+	require.NoError(t, err)
+
+	refs, err := idx.GetChunkRefs(context.Background(), "fake", 0, 12, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []ChunkRef{
+		{User: "fake", Fingerprint: fp, Start: 0, End: 3, Checksum: 0},
+		{User: "fake", Fingerprint: fp, Start: 4, End: 7, Checksum: 1, StorageClass: "cold"},
+		{User: "fake", Fingerprint: fp, Start: 8, End: 11, Checksum: 2, StorageClass: "archive"},
+	}, refs)
+
+	// The stream is returned once, without the storage class label.
+	series, err := idx.Series(context.Background(), "fake", 0, 12, nil, nil, labels.MustNewMatcher(labels.MatchRegexp, "foo", ".+"))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Series{
+		{Labels: ls, Fingerprint: fp},
+		{Labels: other, Fingerprint: model.Fingerprint(other.Hash())},
+	}, series)
+}
+
 func BenchmarkTSDBIndex_GetChunkRefs(b *testing.B) {
 	now := model.Now()
 	queryFrom, queryThrough := now.Add(3*time.Hour).Add(time.Millisecond), now.Add(5*time.Hour).Add(-time.Millisecond)
@@ -0,0 +1,30 @@
+package tsdb
+
+import (
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// StorageClassLabel is part of the reserved label namespace (__ prefix)
+// It records the storage class of the chunks of a series, which is part of their object store keys.
+// The chunks of a stream flushed with different storage classes therefore belong to different series.
+// The label is stripped from the series and labels returned by the index.
+const StorageClassLabel = "__loki_storage_class__"
+
+func withStorageClassLabel(ls labels.Labels, storageClass string) labels.Labels {
+	if storageClass == "" {
+		return ls
+	}
+	b := labels.NewBuilder(ls)
+	b.Set(StorageClassLabel, storageClass)
+	return b.Labels()
+}
+
+func withoutStorageClassLabel(ls labels.Labels) labels.Labels {
+	for i, l := range ls {
+		if l.Name == StorageClassLabel {
+			ls = append(ls[:i], ls[i+1:]...)
+			break
+		}
+	}
+	return ls
+}
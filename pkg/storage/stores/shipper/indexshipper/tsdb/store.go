@@ -35,6 +35,12 @@ type store struct {
 	stopOnce     sync.Once
 }
 
+// StoreLimits are the limits of the index shipper and of the index client of the store.
+type StoreLimits interface {
+	downloads.Limits
+	Limits
+}
+
 // NewStore creates a new tsdb index ReaderWriter.
 func NewStore(
 	name, prefix string,
@@ -42,7 +48,7 @@ func NewStore(
 	schemaCfg config.SchemaConfig,
 	_ *fetcher.Fetcher,
 	objectClient client.ObjectClient,
-	limits downloads.Limits,
+	limits StoreLimits,
 	tableRange config.TableRange,
 	reg prometheus.Registerer,
 	logger log.Logger,
@@ -64,7 +70,7 @@ func NewStore(
 }
 
 func (s *store) init(name, prefix string, indexShipperCfg indexshipper.Config, schemaCfg config.SchemaConfig, objectClient client.ObjectClient,
-	limits downloads.Limits, tableRange config.TableRange, reg prometheus.Registerer) error {
+	limits StoreLimits, tableRange config.TableRange, reg prometheus.Registerer) error {
 
 	var err error
 	s.indexShipper, err = indexshipper.NewIndexShipper(
@@ -161,9 +167,15 @@ func (s *store) IndexChunk(_ context.Context, _ model.Time, _ model.Time, chk ch
 			Entries:  uint32(chk.Data.Entries()),
 		},
 	}
-	if err := s.indexWriter.Append(chk.UserID, chk.Metric, chk.ChunkRef.Fingerprint, metas); err != nil {
+	ls := withStorageClassLabel(chk.Metric, chk.StorageClass)
+	if err := s.indexWriter.Append(chk.UserID, ls, chk.ChunkRef.Fingerprint, metas); err != nil {
 		return errors.Wrap(err, "writing index entry")
 	}
+	for _, metadataLabels := range structuredMetadataSeries(ls, chk.IndexedStructuredMetadata) {
+		if err := s.indexWriter.Append(chk.UserID, metadataLabels, chk.ChunkRef.Fingerprint, metas); err != nil {
+			return errors.Wrap(err, "writing structured metadata index entry")
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,245 @@
+package tsdb
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/sharding"
+)
+
+// StructuredMetadataLabel is part of the reserved label namespace (__ prefix)
+// It records the structured metadata values of the chunks as extra series of their stream,
+// with the same fingerprint and a subset of its chunks:
+//   - `<key>` for the chunks whose values of the key were recorded, which may be none.
+//   - `<key>=<value>` for the chunks with lines having the value.
+//
+// The series with the label are excluded from the queries, except for the postings lookups
+// pruning the chunks, and are kept in sync with their stream by the compactor.
+const StructuredMetadataLabel = "__loki_structured_metadata__"
+
+// structuredMetadataSeries returns the labels of the series recording the structured metadata values
+// of a chunk of the stream with the given labels.
+func structuredMetadataSeries(ls labels.Labels, values map[string][]string) []labels.Labels {
+	if len(values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	res := make([]labels.Labels, 0, len(keys))
+	b := labels.NewBuilder(ls)
+	for _, key := range keys {
+		b.Set(StructuredMetadataLabel, key)
+		res = append(res, b.Labels())
+		for _, value := range values[key] {
+			b.Set(StructuredMetadataLabel, key+"="+value)
+			res = append(res, b.Labels())
+		}
+	}
+	return res
+}
+
+func withoutStructuredMetadataLabel(ls labels.Labels) labels.Labels {
+	for i, l := range ls {
+		if l.Name == StructuredMetadataLabel {
+			ls = append(ls[:i:i], ls[i+1:]...)
+			break
+		}
+	}
+	return ls
+}
+
+// withoutStructuredMetadataSeries returns a copy of the matchers excluding the series recording
+// structured metadata values.
+func withoutStructuredMetadataSeries(matchers []*labels.Matcher) []*labels.Matcher {
+	cpy := make([]*labels.Matcher, len(matchers)+1)
+	copy(cpy, matchers)
+	cpy[len(matchers)] = labels.MustNewMatcher(labels.MatchEqual, StructuredMetadataLabel, "")
+	return cpy
+}
+
+// StructuredMetadataMatchers returns the matchers of the label filters of the query on the given
+// structured metadata keys, which can be looked up in the index to prune the chunks.
+// Only the filters of a single log selector preceding any other stage are returned, since parsers
+// and formatters can change the labels, and the filters matching the empty value are skipped as they
+// match the lines without the key.
+func StructuredMetadataMatchers(expr syntax.Expr, keys []string) []*labels.Matcher {
+	if expr == nil || len(keys) == 0 {
+		return nil
+	}
+
+	var (
+		pipelines []*syntax.PipelineExpr
+		selectors int
+	)
+	expr.Walk(func(e syntax.Expr) {
+		switch e := e.(type) {
+		case *syntax.PipelineExpr:
+			pipelines = append(pipelines, e)
+		case *syntax.MatchersExpr:
+			selectors++
+		}
+	})
+	if len(pipelines) != 1 || selectors != 1 {
+		return nil
+	}
+
+	var matchers []*labels.Matcher
+stages:
+	for _, stage := range pipelines[0].MultiStages {
+		switch stage := stage.(type) {
+		case *syntax.LineFilterExpr:
+		case *syntax.LabelFilterExpr:
+			matchers = appendStructuredMetadataMatchers(matchers, stage.LabelFilterer, keys)
+		default:
+			break stages
+		}
+	}
+	return matchers
+}
+
+func appendStructuredMetadataMatchers(matchers []*labels.Matcher, f log.LabelFilterer, keys []string) []*labels.Matcher {
+	var m *labels.Matcher
+	switch f := f.(type) {
+	case *log.BinaryLabelFilter:
+		if !f.And {
+			return matchers
+		}
+		matchers = appendStructuredMetadataMatchers(matchers, f.Left, keys)
+		return appendStructuredMetadataMatchers(matchers, f.Right, keys)
+	case *log.StringLabelFilter:
+		m = f.Matcher
+	case *log.LineFilterLabelFilter:
+		m = f.Matcher
+	default:
+		return matchers
+	}
+
+	if (m.Type != labels.MatchEqual && m.Type != labels.MatchRegexp) || m.Matches("") {
+		return matchers
+	}
+	for _, key := range keys {
+		if m.Name == key {
+			return append(matchers, m)
+		}
+	}
+	return matchers
+}
+
+// structuredMetadataPostingsMatcher converts a matcher on a structured metadata key to the matcher
+// of the series recording its values.
+func structuredMetadataPostingsMatcher(m *labels.Matcher) (*labels.Matcher, error) {
+	if m.Type == labels.MatchEqual {
+		return labels.NewMatcher(labels.MatchEqual, StructuredMetadataLabel, m.Name+"="+m.Value)
+	}
+	return labels.NewMatcher(labels.MatchRegexp, StructuredMetadataLabel, regexp.QuoteMeta(m.Name+"=")+"(?:"+m.Value+")")
+}
+
+// pruneChunkRefs drops the chunks whose structured metadata values were recorded in the index for the
+// key of a matcher, but none of them matches it.
+func pruneChunkRefs(ctx context.Context, idx Index, userID string, from, through model.Time, refs []ChunkRef, fpFilter index.FingerprintFilter, matchers []*labels.Matcher, metadataMatchers []*labels.Matcher) ([]ChunkRef, error) {
+	for _, m := range metadataMatchers {
+		if len(refs) == 0 {
+			break
+		}
+
+		recorded, err := idx.GetChunkRefs(ctx, userID, from, through, nil, fpFilter, append(matchers[:len(matchers):len(matchers)], labels.MustNewMatcher(labels.MatchEqual, StructuredMetadataLabel, m.Name))...)
+		if err != nil {
+			return nil, err
+		}
+		if len(recorded) == 0 {
+			continue
+		}
+
+		postingsMatcher, err := structuredMetadataPostingsMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		matching, err := idx.GetChunkRefs(ctx, userID, from, through, nil, fpFilter, append(matchers[:len(matchers):len(matchers)], postingsMatcher)...)
+		if err != nil {
+			return nil, err
+		}
+
+		pruned := make(map[ChunkRef]struct{}, len(recorded))
+		for _, ref := range recorded {
+			pruned[ref] = struct{}{}
+		}
+		for _, ref := range matching {
+			delete(pruned, ref)
+		}
+
+		kept := refs[:0]
+		for _, ref := range refs {
+			if _, ok := pruned[ref]; !ok {
+				kept = append(kept, ref)
+			}
+		}
+		refs = kept
+	}
+	return refs, nil
+}
+
+// syncStructuredMetadataSeries drops the chunks of the structured metadata series which are no longer
+// part of their stream, and the series whose stream was removed.
+func (b *Builder) syncStructuredMetadataSeries() {
+	type chunkKey struct {
+		minTime, maxTime int64
+		checksum         uint32
+	}
+	streamChunks := map[string]map[chunkKey]struct{}{}
+
+	for id, s := range b.streams {
+		if !s.labels.Has(StructuredMetadataLabel) {
+			continue
+		}
+
+		streamID := withoutStructuredMetadataLabel(s.labels).String()
+		chunks, ok := streamChunks[streamID]
+		if !ok {
+			if stream, ok := b.streams[streamID]; ok {
+				chunks = make(map[chunkKey]struct{}, len(stream.chunks))
+				for _, chk := range stream.chunks {
+					chunks[chunkKey{minTime: chk.MinTime, maxTime: chk.MaxTime, checksum: chk.Checksum}] = struct{}{}
+				}
+			}
+			streamChunks[streamID] = chunks
+		}
+		if len(chunks) == 0 {
+			delete(b.streams, id)
+			continue
+		}
+
+		kept := s.chunks[:0]
+		for _, chk := range s.chunks {
+			if _, ok := chunks[chunkKey{minTime: chk.MinTime, maxTime: chk.MaxTime, checksum: chk.Checksum}]; ok {
+				kept = append(kept, chk)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.streams, id)
+			continue
+		}
+		s.chunks = kept
+	}
+}
+
+// forSeriesWithoutStructuredMetadata excludes the series recording structured metadata values from
+// the series iterated by the wrapped ForSeries.
+type forSeriesWithoutStructuredMetadata struct {
+	forSeries sharding.ForSeries
+}
+
+func (f forSeriesWithoutStructuredMetadata) ForSeries(ctx context.Context, userID string, fpFilter index.FingerprintFilter, from model.Time, through model.Time, fn func(labels.Labels, model.Fingerprint, []index.ChunkMeta) (stop bool), matchers ...*labels.Matcher) error {
+	return f.forSeries.ForSeries(ctx, userID, fpFilter, from, through, fn, withoutStructuredMetadataSeries(matchers)...)
+}
@@ -27,6 +27,7 @@ var SupportedStorageTypes = []string{
 	StorageTypeBOS,
 	StorageTypeCOS,
 	StorageTypeGCS,
+	StorageTypeHDFS,
 	StorageTypeS3,
 	StorageTypeSwift,
 }
@@ -61,6 +62,7 @@ const (
 	StorageTypeGCPColumnKey   = "gcp-columnkey"
 	StorageTypeGCS            = "gcs"
 	StorageTypeGrpc           = "grpc-store"
+	StorageTypeHDFS           = "hdfs"
 	StorageTypeLocal          = "local"
 	StorageTypeS3             = "s3"
 	StorageTypeSwift          = "swift"
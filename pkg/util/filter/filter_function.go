@@ -7,3 +7,6 @@ import (
 )
 
 type Func func(ts time.Time, s string, structuredMetadata ...labels.Label) bool
+
+// RewriteFunc returns the line to write in place of the given one, and whether it differs from it.
+type RewriteFunc func(ts time.Time, s string, structuredMetadata ...labels.Label) (string, bool)
@@ -83,6 +83,7 @@ var queryTests = []struct {
 						}
 					},
 					"totalBatches": 0,
+					"totalBlockCacheHits": 0,
 					"totalChunksMatched": 0,
 					"totalLinesSent": 0,
 					"totalReached": 0
@@ -52,6 +52,7 @@ const emptyStats = `{
 			}
 		},
 		"totalBatches": 0,
+		"totalBlockCacheHits": 0,
 		"totalChunksMatched": 0,
 		"totalLinesSent": 0,
 		"totalReached": 0
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -62,6 +63,17 @@ const (
 	defaultBloomCompactorMaxBlockSize = "200MB"
 )
 
+// Types of the metric streams.
+const (
+	MetricStreamCount     = "count"
+	MetricStreamBytes     = "bytes"
+	MetricStreamHistogram = "histogram"
+)
+
+// storageClassRegexp matches the storage class names, which are used as a
+// segment of the chunk keys.
+var storageClassRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 // Limits describe all the limits for users; can be used to describe global default
 // limits via flags, or per-user limits via yaml config.
 // NOTE: we use custom `model.Duration` instead of standard `time.Duration` because,
@@ -83,6 +95,14 @@ type Limits struct {
 	DiscoverServiceName         []string         `yaml:"discover_service_name" json:"discover_service_name"`
 	DiscoverLogLevels           bool             `yaml:"discover_log_levels" json:"discover_log_levels"`
 
+	LabelCardinalityDemotionThreshold int            `yaml:"label_cardinality_demotion_threshold" json:"label_cardinality_demotion_threshold"`
+	LabelCardinalityDemotionWindow    model.Duration `yaml:"label_cardinality_demotion_window" json:"label_cardinality_demotion_window"`
+
+	HATrackerEnabled bool   `yaml:"ha_tracker_enabled" json:"ha_tracker_enabled"`
+	HAClusterLabel   string `yaml:"ha_cluster_label" json:"ha_cluster_label"`
+	HAReplicaLabel   string `yaml:"ha_replica_label" json:"ha_replica_label"`
+	HAMaxClusters    int    `yaml:"ha_max_clusters" json:"ha_max_clusters"`
+
 	// Ingester enforced limits.
 	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
 	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
@@ -90,6 +110,8 @@ type Limits struct {
 	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
 	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
 
+	StreamLimitPolicies []StreamLimitPolicy `yaml:"stream_limit_policies,omitempty" json:"stream_limit_policies,omitempty" doc:"description=Ingestion limits applied to the subset of a tenant's streams matching a selector. Streams matching several policies are subject to all of them.\nExample:\n stream_limit_policies:\n   - name: batch\n     selector: '{namespace=\"batch\"}'\n     max_global_streams: 1000\n     ingestion_rate: 2MB\n     ingestion_burst: 4MB\n     max_label_values_per_label: 100\nThe 'ingestion_rate' and 'ingestion_burst' are enforced by distributors, following the configured ingestion rate strategy. The 'max_global_streams' is enforced by ingesters in the same way as 'max_global_streams_per_user', while 'max_label_values_per_label' is enforced by each ingester individually. A value of 0 disables the respective limit."`
+
 	// Querier enforced limits.
 	MaxChunksPerQuery          int              `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
 	MaxQuerySeries             int              `yaml:"max_query_series" json:"max_query_series"`
@@ -178,6 +200,12 @@ type Limits struct {
 	RetentionPeriod model.Duration    `yaml:"retention_period" json:"retention_period"`
 	StreamRetention []StreamRetention `yaml:"retention_stream,omitempty" json:"retention_stream,omitempty" doc:"description=Per-stream retention to apply, if the retention is enable on the compactor side.\nExample:\n retention_stream:\n - selector: '{namespace=\"dev\"}'\n priority: 1\n period: 24h\n- selector: '{container=\"nginx\"}'\n priority: 1\n period: 744h\nSelector is a Prometheus labels matchers that will apply the 'period' retention only if the stream is matching. In case multiple stream are matching, the highest priority will be picked. If no rule is matched the 'retention_period' is used."`
 
+	// Per tenant storage classes applied by the ingesters when flushing chunks.
+	StorageClassStream []StorageClassRule `yaml:"storage_class_stream,omitempty" json:"storage_class_stream,omitempty" category:"experimental" doc:"description=Experimental: Per-stream storage classes applied to the chunks flushed by the ingesters. The storage class prefixes the object store key of the chunks of the matching streams, '<tenant>/<storage_class>/<fingerprint>/...', so that object store lifecycle rules can be applied to them, and is recorded in the index to route the reads. It is only applied to the periods using schema v12 or later.\nExample:\n storage_class_stream:\n - selector: '{env=\"dev\"}'\n   priority: 1\n   storage_class: cold\n   chunk_encoding: zstd\n - selector: '{level=\"debug\"}'\n   priority: 2\n   storage_class: short-ttl\nIn case multiple rules are matching, the highest priority will be picked. The chunks of the streams not matching any rule are stored without a storage class."`
+
+	// Per tenant metrics aggregated by the ingesters on push.
+	MetricStreams []MetricStreamRule `yaml:"metric_streams,omitempty" json:"metric_streams,omitempty" category:"experimental" doc:"description=Experimental: Metrics aggregated by the ingesters from the log lines of the streams matching a log selector when they are pushed, and remote-written to a Prometheus-compatible endpoint. Requires the ingester 'metric_streams' to be enabled.\nExample:\n metric_streams:\n - name: nginx_lines_total\n   selector: '{app=\"nginx\"}'\n   type: count\n - name: nginx_bytes_total\n   selector: '{app=\"nginx\"}'\n   type: bytes\n   labels: [namespace]\nThe 'count' and 'bytes' metrics are counters of the log lines and of their bytes, the 'histogram' metrics observe the value of the 'unwrap' label. The selector can be followed by a pipeline, which filters the log lines and extracts the labels kept on the metrics and unwrapped by the histograms."`
+
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
@@ -210,6 +238,7 @@ type Limits struct {
 	AllowStructuredMetadata           bool                  `yaml:"allow_structured_metadata,omitempty" json:"allow_structured_metadata,omitempty" doc:"description=Allow user to send structured metadata in push payload."`
 	MaxStructuredMetadataSize         flagext.ByteSize      `yaml:"max_structured_metadata_size" json:"max_structured_metadata_size" doc:"description=Maximum size accepted for structured metadata per log line."`
 	MaxStructuredMetadataEntriesCount int                   `yaml:"max_structured_metadata_entries_count" json:"max_structured_metadata_entries_count" doc:"description=Maximum number of structured metadata entries per log line."`
+	IndexStructuredMetadataKeys       []string              `yaml:"index_structured_metadata_keys,omitempty" json:"index_structured_metadata_keys,omitempty" category:"experimental"`
 	OTLPConfig                        push.OTLPConfig       `yaml:"otlp_config" json:"otlp_config" doc:"description=OTLP log ingestion configurations"`
 	GlobalOTLPConfig                  push.GlobalOTLPConfig `yaml:"-" json:"-"`
 }
@@ -221,6 +250,70 @@ type StreamRetention struct {
 	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
 }
 
+// StorageClassRule assigns a storage class, and optionally a chunk encoding,
+// to the chunks of the streams matching a selector.
+type StorageClassRule struct {
+	StorageClass  string            `yaml:"storage_class" json:"storage_class" doc:"description:Storage class of the chunks of the streams matching the selector. Must only contain letters, digits, '_' and '-'."`
+	ChunkEncoding string            `yaml:"chunk_encoding" json:"chunk_encoding" doc:"description:Encoding of the chunks of the streams matching the selector. Defaults to the ingester chunk encoding."`
+	Priority      int               `yaml:"priority" json:"priority" doc:"description:The larger the value, the higher the priority."`
+	Selector      string            `yaml:"selector" json:"selector" doc:"description:Stream selector expression."`
+	Matchers      []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Matches returns whether the given stream labels are selected by the rule.
+func (c *StorageClassRule) Matches(lbs labels.Labels) bool {
+	for _, m := range c.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// MetricStreamRule defines a metric aggregated by the ingesters from the log
+// lines of the streams matching a log selector.
+type MetricStreamRule struct {
+	Name     string                 `yaml:"name" json:"name" doc:"description:Name of the metric."`
+	Selector string                 `yaml:"selector" json:"selector" doc:"description:Log selector expression, optionally followed by a pipeline, selecting the log lines."`
+	Type     string                 `yaml:"type" json:"type" doc:"description:Type of the metric, one of 'count', 'bytes' or 'histogram'."`
+	Unwrap   string                 `yaml:"unwrap" json:"unwrap" doc:"description:Label holding the value observed by a 'histogram' metric."`
+	Buckets  []float64              `yaml:"buckets" json:"buckets" doc:"description:Upper bounds of the buckets of a 'histogram' metric. Defaults to the Prometheus default buckets."`
+	Labels   []string               `yaml:"labels" json:"labels" doc:"description:Labels of the log lines, including the labels extracted by the pipeline, kept on the metric. Defaults to the stream labels."`
+	Expr     syntax.LogSelectorExpr `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Matches returns whether the given stream labels are selected by the rule.
+func (r *MetricStreamRule) Matches(lbs labels.Labels) bool {
+	for _, m := range r.Expr.Matchers() {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// StreamLimitPolicy defines ingestion limits applied to the streams of a tenant
+// matching Selector.
+type StreamLimitPolicy struct {
+	Name                   string            `yaml:"name" json:"name" doc:"description:Name of the policy, used in error messages and metrics."`
+	Selector               string            `yaml:"selector" json:"selector" doc:"description:Stream selector expression."`
+	MaxGlobalStreams       int               `yaml:"max_global_streams" json:"max_global_streams" doc:"description:Maximum number of active streams matching the selector, across the cluster."`
+	IngestionRate          flagext.ByteSize  `yaml:"ingestion_rate" json:"ingestion_rate" doc:"description:Maximum ingestion rate in bytes per second for the streams matching the selector."`
+	IngestionBurst         flagext.ByteSize  `yaml:"ingestion_burst" json:"ingestion_burst" doc:"description:Burst size in bytes for the ingestion rate. Defaults to the ingestion rate."`
+	MaxLabelValuesPerLabel int               `yaml:"max_label_values_per_label" json:"max_label_values_per_label" doc:"description:Maximum number of distinct values per label name among the active streams matching the selector."`
+	Matchers               []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Matches returns whether the given stream labels are selected by the policy.
+func (p *StreamLimitPolicy) Matches(lbs labels.Labels) bool {
+	for _, m := range p.Matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
 // LimitError are errors that do not comply with the limits specified.
 type LimitError string
 
@@ -257,6 +350,15 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var((*dskit_flagext.StringSlice)(&l.DiscoverServiceName), "validation.discover-service-name", "If no service_name label exists, Loki maps a single label from the configured list to service_name. If none of the configured labels exist in the stream, label is set to unknown_service. Empty list disables setting the label.")
 	f.BoolVar(&l.DiscoverLogLevels, "validation.discover-log-levels", true, "Discover and add log levels during ingestion, if not present already. Levels would be added to Structured Metadata with name 'level' and one of the values from 'debug', 'info', 'warn', 'error', 'critical', 'fatal'.")
 
+	f.IntVar(&l.LabelCardinalityDemotionThreshold, "distributor.label-cardinality-demotion-threshold", 0, "Maximum number of distinct values a stream label can have within the `label_cardinality_demotion_window` before the distributor moves it from the stream labels to the structured metadata of the entries, instead of creating new streams. Each distributor tracks the label values it receives independently. The service_name label is never demoted. Requires structured metadata to be allowed. 0 to disable.")
+	_ = l.LabelCardinalityDemotionWindow.Set("1h")
+	f.Var(&l.LabelCardinalityDemotionWindow, "distributor.label-cardinality-demotion-window", "Time window over which the distinct values of each stream label are counted for `label_cardinality_demotion_threshold`. A demoted label stays demoted until a whole window goes by without exceeding the threshold.")
+
+	f.BoolVar(&l.HATrackerEnabled, "distributor.ha-tracker.enable-for-all-users", false, "Deduplicate the logs sent by the replicas of an HA cluster, accepting the logs of the elected replica only. Requires the HA tracker to be enabled.")
+	f.StringVar(&l.HAClusterLabel, "distributor.ha-tracker.cluster", "cluster", "Stream label identifying the HA cluster a stream comes from.")
+	f.StringVar(&l.HAReplicaLabel, "distributor.ha-tracker.replica", "__replica__", "Stream label identifying the replica of the HA cluster a stream comes from. This label is removed from the streams of the elected replica.")
+	f.IntVar(&l.HAMaxClusters, "distributor.ha-tracker.max-clusters", 0, "Maximum number of HA clusters tracked for the tenant. The streams of the clusters above the limit are rejected. A cluster is no longer tracked 30 minutes after the last push of its elected replica. 0 to disable.")
+
 	_ = l.RejectOldSamplesMaxAge.Set("7d")
 	f.Var(&l.RejectOldSamplesMaxAge, "validation.reject-old-samples.max-age", "Maximum accepted sample age before rejecting.")
 	_ = l.CreationGracePeriod.Set("10m")
@@ -385,6 +487,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxStructuredMetadataSize.Set(defaultMaxStructuredMetadataSize)
 	f.Var(&l.MaxStructuredMetadataSize, "limits.max-structured-metadata-size", "Maximum size accepted for structured metadata per entry. Default: 64 kb. Any log line exceeding this limit will be discarded. There is no limit when unset or set to 0.")
 	f.IntVar(&l.MaxStructuredMetadataEntriesCount, "limits.max-structured-metadata-entries-count", defaultMaxStructuredMetadataCount, "Maximum number of structured metadata entries per log line. Default: 128. Any log line exceeding this limit will be discarded. There is no limit when unset or set to 0.")
+	f.Var((*dskit_flagext.StringSliceCSV)(&l.IndexStructuredMetadataKeys), "limits.index-structured-metadata-keys", "Experimental: Comma separated list of structured metadata keys whose values the ingesters record in the TSDB index for each chunk, up to 64 distinct values per chunk. Queries with label filters on these keys before any parser or formatter skip the chunks without a matching value. Use keys with a low to medium cardinality, like 'level' or 'service_version'.")
 	f.BoolVar(&l.VolumeEnabled, "limits.volume-enabled", true, "Enable log volume endpoint.")
 }
 
@@ -438,6 +541,92 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if l.LabelCardinalityDemotionThreshold > 0 && l.LabelCardinalityDemotionWindow <= 0 {
+		return fmt.Errorf("label_cardinality_demotion_window must be > 0 when label_cardinality_demotion_threshold is set, was %s", l.LabelCardinalityDemotionWindow)
+	}
+
+	if l.StorageClassStream != nil {
+		for i, rule := range l.StorageClassStream {
+			if !storageClassRegexp.MatchString(rule.StorageClass) {
+				return fmt.Errorf("invalid storage class %q for selector %s: must only contain letters, digits, '_' and '-'", rule.StorageClass, rule.Selector)
+			}
+			matchers, err := syntax.ParseMatchers(rule.Selector, true)
+			if err != nil {
+				return fmt.Errorf("invalid labels matchers for storage class %s: %w", rule.StorageClass, err)
+			}
+			if rule.ChunkEncoding != "" {
+				if _, err := chunkenc.ParseEncoding(rule.ChunkEncoding); err != nil {
+					return fmt.Errorf("invalid chunk encoding for storage class %s: %w", rule.StorageClass, err)
+				}
+			}
+			// populate matchers during validation
+			l.StorageClassStream[i].Matchers = matchers
+		}
+	}
+
+	for _, key := range l.IndexStructuredMetadataKeys {
+		if !model.LabelName(key).IsValid() {
+			return fmt.Errorf("invalid indexed structured metadata key %q", key)
+		}
+	}
+
+	if l.MetricStreams != nil {
+		names := make(map[string]struct{}, len(l.MetricStreams))
+		for i, rule := range l.MetricStreams {
+			if !model.IsValidMetricName(model.LabelValue(rule.Name)) {
+				return fmt.Errorf("invalid metric stream name %q", rule.Name)
+			}
+			if _, ok := names[rule.Name]; ok {
+				return fmt.Errorf("duplicate metric stream name: %s", rule.Name)
+			}
+			names[rule.Name] = struct{}{}
+
+			expr, err := syntax.ParseLogSelector(rule.Selector, true)
+			if err != nil {
+				return fmt.Errorf("invalid log selector for metric stream %s: %w", rule.Name, err)
+			}
+			switch rule.Type {
+			case MetricStreamCount, MetricStreamBytes:
+			case MetricStreamHistogram:
+				if rule.Unwrap == "" {
+					return fmt.Errorf("histogram metric stream %s must have an unwrap label", rule.Name)
+				}
+				for j := 1; j < len(rule.Buckets); j++ {
+					if rule.Buckets[j] <= rule.Buckets[j-1] {
+						return fmt.Errorf("buckets of histogram metric stream %s must be in increasing order", rule.Name)
+					}
+				}
+			default:
+				return fmt.Errorf("invalid type %q for metric stream %s", rule.Type, rule.Name)
+			}
+			// populate expression during validation
+			l.MetricStreams[i].Expr = expr
+		}
+	}
+
+	if l.StreamLimitPolicies != nil {
+		names := make(map[string]struct{}, len(l.StreamLimitPolicies))
+		for i, policy := range l.StreamLimitPolicies {
+			if policy.Name == "" {
+				return fmt.Errorf("stream limit policy for selector %s must have a name", policy.Selector)
+			}
+			if _, ok := names[policy.Name]; ok {
+				return fmt.Errorf("duplicate stream limit policy name: %s", policy.Name)
+			}
+			names[policy.Name] = struct{}{}
+
+			matchers, err := syntax.ParseMatchers(policy.Selector, true)
+			if err != nil {
+				return fmt.Errorf("invalid labels matchers for policy %s: %w", policy.Name, err)
+			}
+			// populate matchers during validation
+			l.StreamLimitPolicies[i].Matchers = matchers
+			if policy.IngestionBurst.Val() == 0 {
+				l.StreamLimitPolicies[i].IngestionBurst = policy.IngestionRate
+			}
+		}
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
@@ -909,6 +1098,21 @@ func (o *Overrides) PerStreamRateLimit(userID string) RateLimit {
 	}
 }
 
+// StorageClassStream returns the per-stream storage classes for a given user.
+func (o *Overrides) StorageClassStream(userID string) []StorageClassRule {
+	return o.getOverridesForUser(userID).StorageClassStream
+}
+
+// MetricStreams returns the metrics aggregated by the ingesters for a given user.
+func (o *Overrides) MetricStreams(userID string) []MetricStreamRule {
+	return o.getOverridesForUser(userID).MetricStreams
+}
+
+// StreamLimitPolicies returns the selector-scoped ingestion limits for a given user.
+func (o *Overrides) StreamLimitPolicies(userID string) []StreamLimitPolicy {
+	return o.getOverridesForUser(userID).StreamLimitPolicies
+}
+
 func (o *Overrides) IncrementDuplicateTimestamps(userID string) bool {
 	return o.getOverridesForUser(userID).IncrementDuplicateTimestamp
 }
@@ -921,6 +1125,36 @@ func (o *Overrides) DiscoverLogLevels(userID string) bool {
 	return o.getOverridesForUser(userID).DiscoverLogLevels
 }
 
+// LabelCardinalityDemotionThreshold returns the number of distinct values above which a stream label is demoted to structured metadata.
+func (o *Overrides) LabelCardinalityDemotionThreshold(userID string) int {
+	return o.getOverridesForUser(userID).LabelCardinalityDemotionThreshold
+}
+
+// HATrackerEnabled returns whether the logs of the replicas of HA clusters are deduplicated.
+func (o *Overrides) HATrackerEnabled(userID string) bool {
+	return o.getOverridesForUser(userID).HATrackerEnabled
+}
+
+// HAClusterLabel returns the stream label identifying the HA cluster.
+func (o *Overrides) HAClusterLabel(userID string) string {
+	return o.getOverridesForUser(userID).HAClusterLabel
+}
+
+// HAReplicaLabel returns the stream label identifying the replica of the HA cluster.
+func (o *Overrides) HAReplicaLabel(userID string) string {
+	return o.getOverridesForUser(userID).HAReplicaLabel
+}
+
+// HAMaxClusters returns the maximum number of HA clusters tracked for the tenant.
+func (o *Overrides) HAMaxClusters(userID string) int {
+	return o.getOverridesForUser(userID).HAMaxClusters
+}
+
+// LabelCardinalityDemotionWindow returns the time window over which distinct stream label values are counted.
+func (o *Overrides) LabelCardinalityDemotionWindow(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).LabelCardinalityDemotionWindow)
+}
+
 // VolumeEnabled returns whether volume endpoints are enabled for a user.
 func (o *Overrides) VolumeEnabled(userID string) bool {
 	return o.getOverridesForUser(userID).VolumeEnabled
@@ -986,6 +1220,10 @@ func (o *Overrides) MaxStructuredMetadataCount(userID string) int {
 	return o.getOverridesForUser(userID).MaxStructuredMetadataEntriesCount
 }
 
+func (o *Overrides) IndexStructuredMetadataKeys(userID string) []string {
+	return o.getOverridesForUser(userID).IndexStructuredMetadataKeys
+}
+
 func (o *Overrides) OTLPConfig(userID string) push.OTLPConfig {
 	return o.getOverridesForUser(userID).OTLPConfig
 }
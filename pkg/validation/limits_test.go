@@ -341,6 +341,98 @@ func TestLimitsValidation(t *testing.T) {
 			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "unknown"},
 			expected: fmt.Errorf("invalid encoding: unknown, supported: %s", chunkenc.SupportedEncoding()),
 		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", LabelCardinalityDemotionThreshold: 10, LabelCardinalityDemotionWindow: model.Duration(time.Hour)},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", LabelCardinalityDemotionThreshold: 10},
+			expected: fmt.Errorf("label_cardinality_demotion_window must be > 0 when label_cardinality_demotion_threshold is set, was 0s"),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StreamLimitPolicies: []StreamLimitPolicy{
+				{Name: "batch", Selector: `{namespace="batch"}`},
+			}},
+			expected: nil,
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StreamLimitPolicies: []StreamLimitPolicy{
+				{Selector: `{namespace="batch"}`},
+			}},
+			expected: fmt.Errorf(`stream limit policy for selector {namespace="batch"} must have a name`),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StreamLimitPolicies: []StreamLimitPolicy{
+				{Name: "batch", Selector: `{namespace="batch"}`},
+				{Name: "batch", Selector: `{namespace="dev"}`},
+			}},
+			expected: fmt.Errorf("duplicate stream limit policy name: batch"),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StreamLimitPolicies: []StreamLimitPolicy{
+				{Name: "batch", Selector: `{namespace`},
+			}},
+			expected: fmt.Errorf("invalid labels matchers for policy batch"),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StorageClassStream: []StorageClassRule{
+				{StorageClass: "cold", ChunkEncoding: "zstd", Selector: `{env="dev"}`},
+				{StorageClass: "short-ttl", Selector: `{level="debug"}`},
+			}},
+			expected: nil,
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StorageClassStream: []StorageClassRule{
+				{StorageClass: "cold/archive", Selector: `{env="dev"}`},
+			}},
+			expected: fmt.Errorf(`invalid storage class "cold/archive"`),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", StorageClassStream: []StorageClassRule{
+				{StorageClass: "cold", ChunkEncoding: "unknown", Selector: `{env="dev"}`},
+			}},
+			expected: fmt.Errorf("invalid chunk encoding for storage class cold"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IndexStructuredMetadataKeys: []string{"level", "service_version"}},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IndexStructuredMetadataKeys: []string{"level", "service.version"}},
+			expected: fmt.Errorf(`invalid indexed structured metadata key "service.version"`),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", MetricStreams: []MetricStreamRule{
+				{Name: "lines_total", Selector: `{app="foo"} |= "error"`, Type: MetricStreamCount},
+				{Name: "duration_seconds", Selector: `{app="foo"} | logfmt`, Type: MetricStreamHistogram, Unwrap: "duration", Buckets: []float64{0.1, 1}},
+			}},
+			expected: nil,
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", MetricStreams: []MetricStreamRule{
+				{Name: "lines-total", Selector: `{app="foo"}`, Type: MetricStreamCount},
+			}},
+			expected: fmt.Errorf(`invalid metric stream name "lines-total"`),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", MetricStreams: []MetricStreamRule{
+				{Name: "lines_total", Selector: `{app="foo"}`, Type: MetricStreamCount},
+				{Name: "lines_total", Selector: `{app="bar"}`, Type: MetricStreamCount},
+			}},
+			expected: fmt.Errorf("duplicate metric stream name: lines_total"),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", MetricStreams: []MetricStreamRule{
+				{Name: "lines_total", Selector: `{app="foo"}`, Type: "gauge"},
+			}},
+			expected: fmt.Errorf(`invalid type "gauge" for metric stream lines_total`),
+		},
+		{
+			limits: Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", MetricStreams: []MetricStreamRule{
+				{Name: "duration_seconds", Selector: `{app="foo"}`, Type: MetricStreamHistogram},
+			}},
+			expected: fmt.Errorf("histogram metric stream duration_seconds must have an unwrap label"),
+		},
 	} {
 		desc := fmt.Sprintf("%s/%s", tc.limits.DeletionMode, tc.limits.BloomBlockEncoding)
 		t.Run(desc, func(t *testing.T) {
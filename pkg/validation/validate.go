@@ -29,6 +29,26 @@ const (
 	// because the limit of active streams has been reached.
 	StreamLimit         = "stream_limit"
 	StreamLimitErrorMsg = "Maximum active stream limit exceeded when trying to create stream %s, reduce the number of active streams (reduce labels or reduce label values), or contact your Loki administrator to see if the limit can be increased, user: '%s'"
+	// PolicyStreamLimit is a reason for discarding lines when we can't create a new stream
+	// because the limit of active streams for one of the matching stream limit policies has been reached.
+	PolicyStreamLimit         = "policy_stream_limit"
+	PolicyStreamLimitErrorMsg = "Maximum active stream limit exceeded for policy '%s' when trying to create stream %s, reduce the number of active streams matching '%s' or contact your Loki administrator to see if the limit can be increased, user: '%s'"
+	// PolicyRateLimited is a reason for discarding lines when the ingestion rate limit of
+	// one of the matching stream limit policies has been reached.
+	PolicyRateLimited         = "policy_rate_limited"
+	PolicyRateLimitedErrorMsg = "Ingestion rate limit exceeded for policy '%s' of user %s (limit: %d bytes/sec) while attempting to ingest '%d' lines totaling '%d' bytes for streams matching '%s', reduce log volume or contact your Loki administrator to see if the limit can be increased"
+	// TooManyHAClusters is a reason for discarding lines when they come from a new HA cluster
+	// while the maximum number of HA clusters of the tenant is already tracked.
+	TooManyHAClusters         = "too_many_ha_clusters"
+	TooManyHAClustersErrorMsg = "Maximum number of HA clusters exceeded when trying to track cluster '%s' (limit: %d), reduce the number of HA clusters or contact your Loki administrator to see if the limit can be increased, user: '%s'"
+	// HADuplicate is a reason for dropping lines when they come from a replica of an HA cluster
+	// other than the elected one, which sends the same lines.
+	HADuplicate         = "ha_duplicate"
+	HADuplicateErrorMsg = "Lines of replica '%s' of HA cluster '%s' dropped as duplicates, the elected replica is '%s'"
+	// PolicyLabelValuesLimit is a reason for discarding lines when we can't create a new stream
+	// because one of its labels would exceed the number of label values allowed by a matching stream limit policy.
+	PolicyLabelValuesLimit         = "policy_label_values_limit"
+	PolicyLabelValuesLimitErrorMsg = "Maximum number of values for label '%s' exceeded for policy '%s' when trying to create stream %s (limit: %d), reduce the number of values of the label or contact your Loki administrator to see if the limit can be increased, user: '%s'"
 	// StreamRateLimit is a reason for discarding lines when the streams own rate limit is hit
 	// rather than the overall ingestion rate limit.
 	StreamRateLimit = "per_stream_rate_limit"
@@ -122,6 +142,26 @@ var DiscardedSamples = promauto.NewCounterVec(
 	[]string{ReasonLabel, "tenant"},
 )
 
+// PolicyDiscardedSamples is a metric of the number of samples discarded by stream limit policies, by reason.
+var PolicyDiscardedSamples = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: constants.Loki,
+		Name:      "policy_discarded_samples_total",
+		Help:      "The total number of samples that were discarded by stream limit policies.",
+	},
+	[]string{ReasonLabel, "tenant", "policy"},
+)
+
+// PolicyDiscardedBytes is a metric of the total bytes discarded by stream limit policies, by reason.
+var PolicyDiscardedBytes = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: constants.Loki,
+		Name:      "policy_discarded_bytes_total",
+		Help:      "The total number of bytes that were discarded by stream limit policies.",
+	},
+	[]string{ReasonLabel, "tenant", "policy"},
+)
+
 var LineLengthHist = promauto.NewHistogram(prometheus.HistogramOpts{
 	Namespace: constants.Loki,
 	Name:      "bytes_per_line",
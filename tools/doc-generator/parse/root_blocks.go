@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
 	ingester_client "github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/kafka"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/loki/common"
 	frontend "github.com/grafana/loki/v3/pkg/lokifrontend"
@@ -37,6 +38,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/azure"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/baidubce"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/gcp"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hdfs"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/ibmcloud"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/openstack"
@@ -167,6 +169,11 @@ var (
 			StructType: []reflect.Type{reflect.TypeOf(analytics.Config{})},
 			Desc:       "Configuration for analytics.",
 		},
+		{
+			Name:       "kafka_config",
+			StructType: []reflect.Type{reflect.TypeOf(kafka.Config{})},
+			Desc:       "Configuration for the experimental Kafka write path, where distributors write the pushed streams to Kafka and ingesters consume them.",
+		},
 
 		{
 			Name:       "common",
@@ -262,6 +269,11 @@ When a memberlist config with atleast 1 join_members is defined, kvstore of type
 			StructType: []reflect.Type{reflect.TypeOf(ibmcloud.COSConfig{}), reflect.TypeOf(storage.NamedCOSConfig{})},
 			Desc:       "The cos_storage_config block configures the connection to IBM Cloud Object Storage (COS) backend.",
 		},
+		{
+			Name:       "hdfs_storage_config",
+			StructType: []reflect.Type{reflect.TypeOf(hdfs.Config{}), reflect.TypeOf(storage.NamedHDFSConfig{})},
+			Desc:       "The hdfs_storage_config block configures the connection to HDFS, through the WebHDFS REST API of the NameNode.",
+		},
 		{
 			Name:       "local_storage_config",
 			StructType: []reflect.Type{reflect.TypeOf(local.FSConfig{}), reflect.TypeOf(storage.NamedFSConfig{})},
@@ -271,7 +283,7 @@ When a memberlist config with atleast 1 join_members is defined, kvstore of type
 			Name:       "named_stores_config",
 			StructType: []reflect.Type{reflect.TypeOf(storage.NamedStores{})},
 			Desc: `Configures additional object stores for a given storage provider.
-Supported stores: aws, azure, bos, filesystem, gcs, swift.
+Supported stores: aws, azure, bos, filesystem, gcs, hdfs, swift.
 Example:
 storage_config:
   named_stores:
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	shipperstorage "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	tsdbindex "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+type issueKind string
+
+const (
+	// issueMissingChunk is a chunk referenced by an index which does not exist in the object store.
+	issueMissingChunk issueKind = "missing_chunk"
+	// issueCorruptChunk is a chunk referenced by an index which can't be decoded.
+	issueCorruptChunk issueKind = "corrupt_chunk"
+	// issueOrphanedChunk is a chunk of the object store which is not referenced by any index.
+	issueOrphanedChunk issueKind = "orphaned_chunk"
+
+	actionRemoveIndexReference = "remove_index_reference"
+	actionDeleteChunk          = "delete_chunk"
+)
+
+type options struct {
+	tableNumMin, tableNumMax int64
+	tenant                   string
+	decodeChunks             bool
+	findOrphans              bool
+	orphanMinAge             time.Duration
+	parallelism              int
+	workingDir               string
+}
+
+// issue is a problem found in the storage.
+type issue struct {
+	Kind   issueKind `json:"kind"`
+	Table  string    `json:"table,omitempty"`
+	Tenant string    `json:"tenant"`
+	Series string    `json:"series,omitempty"`
+	// Chunk is the key of the chunk in the object store.
+	Chunk string `json:"chunk"`
+	Error string `json:"error,omitempty"`
+}
+
+// repairAction is a step of the repair plan fixing an issue. The plan is only emitted, never applied.
+type repairAction struct {
+	Action string    `json:"action"`
+	Reason issueKind `json:"reason"`
+	Table  string    `json:"table,omitempty"`
+	Tenant string    `json:"tenant"`
+	Series string    `json:"series,omitempty"`
+	Chunk  string    `json:"chunk"`
+}
+
+type report struct {
+	Tables        int     `json:"tables"`
+	Indexes       int     `json:"indexes"`
+	Series        int     `json:"series"`
+	Chunks        int     `json:"chunks"`
+	ChunksDecoded int     `json:"chunks_decoded"`
+	ChunksListed  int     `json:"chunks_listed"`
+	Issues        []issue `json:"issues"`
+}
+
+// repairPlan returns the actions fixing the issues of the report: the references to missing or corrupt chunks
+// are removed from the indexes, and the corrupt and orphaned chunks are deleted.
+func (r *report) repairPlan() []repairAction {
+	plan := make([]repairAction, 0, len(r.Issues))
+	for _, i := range r.Issues {
+		action := repairAction{Reason: i.Kind, Table: i.Table, Tenant: i.Tenant, Series: i.Series, Chunk: i.Chunk}
+		switch i.Kind {
+		case issueMissingChunk:
+			action.Action = actionRemoveIndexReference
+			plan = append(plan, action)
+		case issueCorruptChunk:
+			action.Action = actionRemoveIndexReference
+			plan = append(plan, action)
+			action.Action = actionDeleteChunk
+			plan = append(plan, action)
+		case issueOrphanedChunk:
+			action.Action = actionDeleteChunk
+			plan = append(plan, action)
+		}
+	}
+	return plan
+}
+
+func (r *report) merge(other *report) {
+	r.Tables += other.Tables
+	r.Indexes += other.Indexes
+	r.Series += other.Series
+	r.Chunks += other.Chunks
+	r.ChunksDecoded += other.ChunksDecoded
+	r.ChunksListed += other.ChunksListed
+	r.Issues = append(r.Issues, other.Issues...)
+}
+
+// chunkReference is a chunk referenced by a series of an index.
+type chunkReference struct {
+	ref    logproto.ChunkRef
+	key    string
+	table  string
+	series string
+}
+
+// checker checks the integrity of the indexes and chunks of a TSDB schema period.
+type checker struct {
+	opts               options
+	schemaCfg          config.SchemaConfig
+	periodCfg          config.PeriodConfig
+	tableRange         config.TableRange
+	objectClient       client.ObjectClient
+	indexStorageClient shipperstorage.Client
+	chunkClient        client.Client
+	keyEncoder         client.KeyEncoder
+	now                func() model.Time
+
+	mtx    sync.Mutex
+	report report
+	// object keys of the chunks referenced by the indexes.
+	referenced map[string]struct{}
+	tenants    map[string]struct{}
+	// numbers of the checked tables.
+	minTableNum, maxTableNum int64
+}
+
+func newChecker(opts options, schemaCfg config.SchemaConfig, periodCfg config.PeriodConfig, tableRange config.TableRange, objectClient client.ObjectClient) *checker {
+	// the filesystem and HDFS object clients encode the chunk keys.
+	keyEncoder := client.KeyEncoderFor(objectClient)
+
+	return &checker{
+		opts:               opts,
+		schemaCfg:          schemaCfg,
+		periodCfg:          periodCfg,
+		tableRange:         tableRange,
+		objectClient:       objectClient,
+		indexStorageClient: shipperstorage.NewIndexStorageClient(objectClient, periodCfg.IndexTables.PathPrefix),
+		chunkClient:        client.NewClient(objectClient, keyEncoder, schemaCfg),
+		keyEncoder:         keyEncoder,
+		now:                model.Now,
+		referenced:         map[string]struct{}{},
+		tenants:            map[string]struct{}{},
+		minTableNum:        -1,
+	}
+}
+
+// check checks the tables of the period, then looks for the chunks of the checked tables which are not referenced by their index.
+func (c *checker) check(ctx context.Context) (*report, error) {
+	tableNames, err := c.indexStorageClient.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		if !strings.HasPrefix(tableName, c.periodCfg.IndexTables.Prefix) {
+			continue
+		}
+		tableNum, err := config.ExtractTableNumberFromName(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if c.opts.tableNumMin != 0 && tableNum < c.opts.tableNumMin {
+			continue
+		}
+		if c.opts.tableNumMax != 0 && tableNum > c.opts.tableNumMax {
+			continue
+		}
+		tableInRange, err := c.tableRange.TableInRange(tableName)
+		if err != nil {
+			return nil, err
+		}
+		if !tableInRange {
+			continue
+		}
+
+		if err := c.checkTable(ctx, tableName); err != nil {
+			return nil, fmt.Errorf("failed to check table %s: %w", tableName, err)
+		}
+		if c.minTableNum == -1 || tableNum < c.minTableNum {
+			c.minTableNum = tableNum
+		}
+		if tableNum > c.maxTableNum {
+			c.maxTableNum = tableNum
+		}
+		level.Info(util_log.Logger).Log("msg", "checked table", "table_name", tableName)
+	}
+
+	if c.opts.findOrphans && c.minTableNum != -1 {
+		if err := c.findOrphanedChunks(ctx); err != nil {
+			return nil, fmt.Errorf("failed to find orphaned chunks: %w", err)
+		}
+	}
+
+	return &c.report, nil
+}
+
+func (c *checker) checkTable(ctx context.Context, tableName string) error {
+	c.report.Tables++
+
+	dir, err := os.MkdirTemp(c.opts.workingDir, tableName)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	commonFiles, tenants, err := c.indexStorageClient.ListFiles(ctx, tableName, true)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range commonFiles {
+		path, err := c.download(dir, file.Name, func() (io.ReadCloser, error) {
+			return c.indexStorageClient.GetFile(ctx, tableName, file.Name)
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.checkIndex(ctx, tableName, "", path); err != nil {
+			return fmt.Errorf("failed to check index %s: %w", file.Name, err)
+		}
+	}
+
+	for _, tenant := range tenants {
+		if c.opts.tenant != "" && tenant != c.opts.tenant {
+			continue
+		}
+
+		files, err := c.indexStorageClient.ListUserFiles(ctx, tableName, tenant, true)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			path, err := c.download(filepath.Join(dir, tenant), file.Name, func() (io.ReadCloser, error) {
+				return c.indexStorageClient.GetUserFile(ctx, tableName, tenant, file.Name)
+			})
+			if err != nil {
+				return err
+			}
+			if err := c.checkIndex(ctx, tableName, tenant, path); err != nil {
+				return fmt.Errorf("failed to check index %s of tenant %s: %w", file.Name, tenant, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *checker) download(dir, fileName string, getReader func() (io.ReadCloser, error)) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	decompress := shipperstorage.IsCompressedFile(fileName)
+	dst := filepath.Join(dir, strings.TrimSuffix(fileName, ".gz"))
+	err := shipperstorage.DownloadFileFromStorage(dst, decompress, false, shipperstorage.LoggerWithFilename(util_log.Logger, fileName), getReader)
+	return dst, err
+}
+
+// checkIndex checks the chunks referenced by an index, which is a multi-tenant index when tenant is empty.
+func (c *checker) checkIndex(ctx context.Context, tableName, tenant, path string) error {
+	idx, err := tsdb.OpenShippableTSDB(path)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+	c.report.Indexes++
+
+	var refs []chunkReference
+	err = idx.(*tsdb.TSDBFile).Index.(*tsdb.TSDBIndex).ForSeries(ctx, "", nil, model.Earliest, model.Latest,
+		func(ls labels.Labels, fp model.Fingerprint, chks []tsdbindex.ChunkMeta) (stop bool) {
+			userID := tenant
+			if userID == "" {
+				userID = ls.Get(tsdb.TenantLabel)
+			}
+			// the structured metadata series repeat the chunks of their stream.
+			if (c.opts.tenant != "" && userID != c.opts.tenant) || ls.Has(tsdb.StructuredMetadataLabel) {
+				return false
+			}
+
+			c.report.Series++
+			storageClass := ls.Get(tsdb.StorageClassLabel)
+			series := labels.NewBuilder(ls).Del(tsdb.TenantLabel, tsdb.StorageClassLabel).Labels().String()
+			for _, chk := range chks {
+				ref := logproto.ChunkRef{
+					Fingerprint:  uint64(fp),
+					UserID:       userID,
+					From:         chk.From(),
+					Through:      chk.Through(),
+					Checksum:     chk.Checksum,
+					StorageClass: storageClass,
+				}
+				refs = append(refs, chunkReference{ref: ref, key: c.objectKey(ref), table: tableName, series: series})
+			}
+			return false
+		},
+		labels.MustNewMatcher(labels.MatchEqual, "", ""),
+	)
+	if err != nil {
+		return err
+	}
+
+	// the chunks spanning several tables, or indexed by several files of a table, are only checked once.
+	toCheck := refs[:0]
+	for _, r := range refs {
+		c.tenants[r.ref.UserID] = struct{}{}
+		if _, ok := c.referenced[r.key]; ok {
+			continue
+		}
+		c.referenced[r.key] = struct{}{}
+		toCheck = append(toCheck, r)
+	}
+	c.report.Chunks += len(toCheck)
+
+	return concurrency.ForEachJob(ctx, len(toCheck), c.opts.parallelism, func(ctx context.Context, i int) error {
+		return c.checkChunk(ctx, toCheck[i])
+	})
+}
+
+// checkChunk checks that a referenced chunk exists, and that it decodes when decoding the chunks is enabled.
+func (c *checker) checkChunk(ctx context.Context, r chunkReference) error {
+	exists, err := c.objectClient.ObjectExists(ctx, r.key)
+	if err != nil && !c.objectClient.IsObjectNotFoundErr(err) {
+		return fmt.Errorf("failed to check chunk %s: %w", r.key, err)
+	}
+	if !exists {
+		c.addIssue(issue{Kind: issueMissingChunk, Table: r.table, Tenant: r.ref.UserID, Series: r.series, Chunk: r.key})
+		return nil
+	}
+
+	if !c.opts.decodeChunks {
+		return nil
+	}
+	if err := c.decodeChunk(ctx, r.ref); err != nil {
+		c.addIssue(issue{Kind: issueCorruptChunk, Table: r.table, Tenant: r.ref.UserID, Series: r.series, Chunk: r.key, Error: err.Error()})
+		return nil
+	}
+
+	c.mtx.Lock()
+	c.report.ChunksDecoded++
+	c.mtx.Unlock()
+	return nil
+}
+
+// decodeChunk fetches a chunk, which verifies its checksum, and reads all its lines.
+func (c *checker) decodeChunk(ctx context.Context, ref logproto.ChunkRef) error {
+	chunks, err := c.chunkClient.GetChunks(ctx, []chunk.Chunk{{ChunkRef: ref}})
+	if err != nil {
+		return err
+	}
+
+	for _, chk := range chunks {
+		facade, ok := chk.Data.(*chunkenc.Facade)
+		if !ok {
+			return fmt.Errorf("unexpected chunk encoding %s", chk.Data.Encoding())
+		}
+
+		it, err := facade.LokiChunk().Iterator(ctx, chk.From.Time(), chk.Through.Time().Add(time.Nanosecond), logproto.FORWARD, log.NewNoopPipeline().ForStream(chk.Metric))
+		if err != nil {
+			return err
+		}
+		for it.Next() {
+		}
+		if err := it.Error(); err != nil {
+			it.Close()
+			return err
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOrphanedChunks lists the chunks of the tenants of the checked indexes, and reports the ones which are within the
+// checked tables but not referenced by their indexes. The chunks younger than the orphan min age are ignored, since
+// their index may not have been uploaded yet.
+func (c *checker) findOrphanedChunks(ctx context.Context) error {
+	period := int64(c.periodCfg.IndexTables.Period / time.Millisecond)
+	from := model.Time(c.minTableNum * period)
+	through := model.Time((c.maxTableNum + 1) * period)
+	if maxThrough := c.now().Add(-c.opts.orphanMinAge); through > maxThrough {
+		through = maxThrough
+	}
+
+	tenants := make([]string, 0, len(c.tenants))
+	for tenant := range c.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		objects, _, err := c.objectClient.List(ctx, tenant+"/", "")
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			chk, ok := c.parseObjectKey(tenant, object.Key)
+			if !ok {
+				continue
+			}
+			// only the chunks of this period, within the checked tables, are expected to be referenced by the checked indexes.
+			if chk.From < from || chk.Through >= through || chk.From < c.periodCfg.From.Time {
+				continue
+			}
+			if p, err := c.schemaCfg.SchemaForTime(chk.From); err != nil || p.From != c.periodCfg.From {
+				continue
+			}
+
+			c.report.ChunksListed++
+			if _, ok := c.referenced[object.Key]; ok {
+				continue
+			}
+			c.addIssue(issue{Kind: issueOrphanedChunk, Tenant: tenant, Chunk: object.Key})
+		}
+	}
+	return nil
+}
+
+// objectKey returns the key of the chunk in the object store.
+func (c *checker) objectKey(ref logproto.ChunkRef) string {
+	if c.keyEncoder != nil {
+		return c.keyEncoder(c.schemaCfg, chunk.Chunk{ChunkRef: ref})
+	}
+	return c.schemaCfg.ExternalKey(ref)
+}
+
+// parseObjectKey parses the key of an object of the store, which is not a chunk when false is returned.
+func (c *checker) parseObjectKey(tenant, key string) (chunk.Chunk, bool) {
+	externalKey := key
+	if c.keyEncoder != nil {
+		// the filesystem object client encodes the part of the key after the last slash.
+		split := strings.LastIndexByte(key, '/')
+		decoded, err := base64.StdEncoding.DecodeString(key[split+1:])
+		if err != nil {
+			return chunk.Chunk{}, false
+		}
+		externalKey = key[:split+1] + string(decoded)
+	}
+
+	chk, err := chunk.ParseExternalKey(tenant, externalKey)
+	if err != nil {
+		return chunk.Chunk{}, false
+	}
+	return chk, true
+}
+
+func (c *checker) addIssue(i issue) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.report.Issues = append(c.report.Issues, i)
+}
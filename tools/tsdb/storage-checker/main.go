@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/pkg/loki"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/util/cfg"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func exit(code int) {
+	util_log.Flush()
+	os.Exit(code)
+}
+
+// storage-checker verifies that every chunk referenced by the TSDB indexes exists in the object store, and optionally
+// that it decodes, and looks for the chunks which are not referenced by any index. It exits with code 2 if issues were found.
+//
+// The chunks marked for deletion by retention or delete requests are not referenced anymore until the compactor deletes
+// them after retention_delete_delay, so they are reported as orphaned chunks too.
+//
+// Usage: TABLE_NUM_MIN=19464 TABLE_NUM_MAX=19465 DECODE_CHUNKS=true FIND_ORPHANS=true REPAIR_PLAN=/tmp/repair-plan.json go run ./tools/tsdb/storage-checker --config.file /tmp/loki-config.yaml
+//
+// Other options: TENANT limits the check to a tenant, ORPHAN_MIN_AGE (default 24h) ignores the chunks flushed more recently,
+// PARALLELISM (default 16) is the number of chunks checked in parallel, REPORT writes the report as JSON to a file and
+// DIR is the directory the indexes are downloaded to.
+func main() {
+	lokiCfg := setup()
+	clientMetrics := storage.NewClientMetrics()
+
+	opts := options{
+		orphanMinAge: 24 * time.Hour,
+		parallelism:  16,
+		workingDir:   os.TempDir(),
+	}
+	opts.tableNumMin = int64(envInt("TABLE_NUM_MIN", 0))
+	opts.tableNumMax = int64(envInt("TABLE_NUM_MAX", 0))
+	opts.parallelism = envInt("PARALLELISM", opts.parallelism)
+	opts.tenant = os.Getenv("TENANT")
+	opts.decodeChunks = envBool("DECODE_CHUNKS")
+	opts.findOrphans = envBool("FIND_ORPHANS")
+	if got := os.Getenv("ORPHAN_MIN_AGE"); got != "" {
+		d, err := time.ParseDuration(got)
+		if err != nil {
+			log.Fatalf("invalid ORPHAN_MIN_AGE: %v", err)
+		}
+		opts.orphanMinAge = d
+	}
+	if got := os.Getenv("DIR"); got != "" {
+		opts.workingDir = got
+	}
+
+	var total report
+	failed := false
+	for i, periodCfg := range lokiCfg.SchemaConfig.Configs {
+		if periodCfg.IndexType != types.TSDBType {
+			level.Warn(util_log.Logger).Log("msg", "skipping schema period which does not use tsdb", "schema_start", periodCfg.From, "index_type", periodCfg.IndexType)
+			continue
+		}
+
+		periodEndTime := config.DayTime{Time: math.MaxInt64}
+		if i < len(lokiCfg.SchemaConfig.Configs)-1 {
+			periodEndTime = config.DayTime{Time: lokiCfg.SchemaConfig.Configs[i+1].From.Time.Add(-time.Millisecond)}
+		}
+		tableRange := periodCfg.GetIndexTableNumberRange(periodEndTime)
+
+		r, err := checkPeriod(context.Background(), opts, lokiCfg.SchemaConfig, periodCfg, tableRange, lokiCfg.StorageConfig, clientMetrics)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to check schema period", "schema_start", periodCfg.From, "err", err)
+			failed = true
+			continue
+		}
+		total.merge(r)
+	}
+
+	for _, i := range total.Issues {
+		fmt.Printf("%s\ttable=%s\ttenant=%s\tchunk=%s\tseries=%s\terror=%s\n", i.Kind, i.Table, i.Tenant, i.Chunk, i.Series, i.Error)
+	}
+	fmt.Printf("checked %d tables, %d indexes, %d series and %d chunks (%d decoded, %d listed in the object store), found %d issues\n",
+		total.Tables, total.Indexes, total.Series, total.Chunks, total.ChunksDecoded, total.ChunksListed, len(total.Issues))
+
+	if path := os.Getenv("REPORT"); path != "" {
+		if err := writeJSON(path, total); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to write report", "path", path, "err", err)
+			failed = true
+		}
+	}
+	if path := os.Getenv("REPAIR_PLAN"); path != "" {
+		if err := writeJSON(path, total.repairPlan()); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to write repair plan", "path", path, "err", err)
+			failed = true
+		}
+	}
+
+	switch {
+	case failed:
+		exit(1)
+	case len(total.Issues) > 0:
+		exit(2)
+	}
+	exit(0)
+}
+
+func checkPeriod(ctx context.Context, opts options, schemaCfg config.SchemaConfig, periodCfg config.PeriodConfig, tableRange config.TableRange, storageCfg storage.Config, clientMetrics storage.ClientMetrics) (*report, error) {
+	objectClient, err := storage.NewObjectClient(periodCfg.ObjectType, storageCfg, clientMetrics)
+	if err != nil {
+		return nil, err
+	}
+	defer objectClient.Stop()
+
+	return newChecker(opts, schemaCfg, periodCfg, tableRange, objectClient).check(ctx)
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o640)
+}
+
+func envInt(name string, defaultValue int) int {
+	got := os.Getenv(name)
+	if got == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(got)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", name, err)
+	}
+	return n
+}
+
+func envBool(name string) bool {
+	got := os.Getenv(name)
+	if got == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(got)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", name, err)
+	}
+	return b
+}
+
+func setup() loki.Config {
+	var c loki.ConfigWrapper
+	if err := cfg.DynamicUnmarshal(&c, os.Args[1:], flag.CommandLine); err != nil {
+		fmt.Fprintf(os.Stderr, "failed parsing config: %v\n", err)
+		os.Exit(1)
+	}
+
+	serverCfg := &c.Server
+	serverCfg.Log = util_log.InitLogger(serverCfg, prometheus.DefaultRegisterer, false)
+
+	if err := c.Validate(); err != nil {
+		level.Error(util_log.Logger).Log("msg", "validating config", "err", err.Error())
+		exit(1)
+	}
+
+	return c.Config
+}
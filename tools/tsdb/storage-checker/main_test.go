@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	shipperstorage "github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
+)
+
+const (
+	indexPrefix = "tsdb_prefix_"
+	userID      = "user1"
+)
+
+func newTestChunk(t *testing.T, stream labels.Labels, from model.Time) chunk.Chunk {
+	chk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, chk.Append(&logproto.Entry{
+			Timestamp: from.Add(time.Duration(i) * time.Second).Time(),
+			Line:      fmt.Sprintf("line %d", i),
+		}))
+	}
+	require.NoError(t, chk.Close())
+
+	c := chunk.NewChunk(userID, model.Fingerprint(stream.Hash()), stream, chunkenc.NewFacade(chk, 0, 0), from, from.Add(9*time.Second))
+	require.NoError(t, c.Encode())
+	return c
+}
+
+func TestChecker(t *testing.T) {
+	tempDir := t.TempDir()
+
+	now := model.Now()
+	pcfg := config.PeriodConfig{
+		From:       config.DayTime{Time: now.Add(-10 * 24 * time.Hour)},
+		IndexType:  "tsdb",
+		ObjectType: "filesystem",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexPrefix,
+				Period: 24 * time.Hour,
+			}},
+	}
+	schemaCfg := config.SchemaConfig{Configs: []config.PeriodConfig{pcfg}}
+
+	storageCfg := storage.Config{
+		FSConfig: local.FSConfig{
+			Directory: tempDir,
+		},
+	}
+	objClient, err := storage.NewObjectClient(pcfg.ObjectType, storageCfg, storage.NewClientMetrics())
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objClient, client.FSEncoder, schemaCfg)
+	indexStorageClient := shipperstorage.NewIndexStorageClient(objClient, pcfg.IndexTables.PathPrefix)
+
+	// the chunks of a table of 2 days ago.
+	tableFrom := now.Add(-2 * 24 * time.Hour)
+	tableName := pcfg.IndexTables.TableFor(tableFrom)
+	tableNum, err := config.ExtractTableNumberFromName(tableName)
+	require.NoError(t, err)
+	chunksFrom := model.Time(tableNum * int64(24*time.Hour/time.Millisecond)).Add(time.Hour)
+
+	var (
+		stream  = labels.FromStrings("app", "foo")
+		valid   = newTestChunk(t, stream, chunksFrom)
+		missing = newTestChunk(t, stream, chunksFrom.Add(time.Minute))
+		corrupt = newTestChunk(t, stream, chunksFrom.Add(2*time.Minute))
+		orphan  = newTestChunk(t, stream, chunksFrom.Add(3*time.Minute))
+		// recent chunks may not be indexed yet.
+		recent = newTestChunk(t, stream, now.Add(-time.Minute))
+	)
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{valid, orphan, recent}))
+	require.NoError(t, objClient.PutObject(context.Background(), client.FSEncoder(schemaCfg, corrupt), bytes.NewReader([]byte("corrupt"))))
+
+	// the index of the table references the valid, missing and corrupt chunks.
+	b := tsdb.NewBuilder(index.FormatV3)
+	var chunkMetas index.ChunkMetas
+	for _, c := range []chunk.Chunk{valid, missing, corrupt} {
+		chunkMetas = append(chunkMetas, index.ChunkMeta{
+			Checksum: c.Checksum,
+			MinTime:  int64(c.From),
+			MaxTime:  int64(c.Through),
+			KB:       1,
+			Entries:  10,
+		})
+	}
+	b.AddSeries(stream, model.Fingerprint(stream.Hash()), chunkMetas)
+
+	indexDir := t.TempDir()
+	id, err := b.Build(context.Background(), indexDir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tsdb.NewPrefixedIdentifier(tsdb.SingleTenantTSDBIdentifier{
+			TS:       time.Now(),
+			From:     from,
+			Through:  through,
+			Checksum: checksum,
+		}, indexDir, "")
+	})
+	require.NoError(t, err)
+	f, err := os.Open(id.Path())
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, indexStorageClient.PutUserFile(context.Background(), tableName, userID, filepath.Base(id.Path()), f))
+
+	opts := options{
+		decodeChunks: true,
+		findOrphans:  true,
+		orphanMinAge: time.Hour,
+		parallelism:  2,
+		workingDir:   t.TempDir(),
+	}
+	tableRange := pcfg.GetIndexTableNumberRange(config.DayTime{Time: now.Add(24 * time.Hour)})
+	r, err := newChecker(opts, schemaCfg, pcfg, tableRange, objClient).check(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, r.Tables)
+	require.Equal(t, 1, r.Indexes)
+	require.Equal(t, 1, r.Series)
+	require.Equal(t, 3, r.Chunks)
+	require.Equal(t, 1, r.ChunksDecoded)
+	require.Equal(t, 3, r.ChunksListed)
+
+	issues := map[issueKind]issue{}
+	for _, i := range r.Issues {
+		issues[i.Kind] = i
+	}
+	require.Len(t, issues, 3)
+	require.Equal(t, client.FSEncoder(schemaCfg, missing), issues[issueMissingChunk].Chunk)
+	require.Equal(t, tableName, issues[issueMissingChunk].Table)
+	require.Equal(t, `{app="foo"}`, issues[issueMissingChunk].Series)
+	require.Equal(t, client.FSEncoder(schemaCfg, corrupt), issues[issueCorruptChunk].Chunk)
+	require.NotEmpty(t, issues[issueCorruptChunk].Error)
+	require.Equal(t, client.FSEncoder(schemaCfg, orphan), issues[issueOrphanedChunk].Chunk)
+	require.Equal(t, userID, issues[issueOrphanedChunk].Tenant)
+
+	plan := r.repairPlan()
+	require.Len(t, plan, 4)
+	actions := map[string][]issueKind{}
+	for _, a := range plan {
+		actions[a.Action] = append(actions[a.Action], a.Reason)
+	}
+	require.ElementsMatch(t, []issueKind{issueMissingChunk, issueCorruptChunk}, actions[actionRemoveIndexReference])
+	require.ElementsMatch(t, []issueKind{issueCorruptChunk, issueOrphanedChunk}, actions[actionDeleteChunk])
+
+	// without decoding the chunks, the corrupt chunk is only checked for existence.
+	opts.decodeChunks = false
+	opts.findOrphans = false
+	r, err = newChecker(opts, schemaCfg, pcfg, tableRange, objClient).check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, r.Issues, 1)
+	require.Equal(t, issueMissingChunk, r.Issues[0].Kind)
+	require.Equal(t, 0, r.ChunksListed)
+}